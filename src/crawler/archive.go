@@ -0,0 +1,125 @@
+package crawler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"sync"
+
+	"golang.org/x/time/rate"
+
+	"github.com/lukemcguire/zombiecrawl/result"
+)
+
+// DefaultArchiveEndpoint is the Internet Archive's Wayback Machine
+// availability API.
+const DefaultArchiveEndpoint = "https://archive.org/wayback/available"
+
+// DefaultArchiveRate is how many availability lookups are made per second.
+// The API is a shared public service, so lookups are kept slow.
+const DefaultArchiveRate = 1.0
+
+// ArchiveLookup finds the nearest Wayback Machine snapshot of dead external
+// links so reports can suggest a replacement. Lookups are rate-limited and
+// each URL is looked up at most once. A nil *ArchiveLookup suggests nothing.
+// It is safe for concurrent use.
+type ArchiveLookup struct {
+	client   *http.Client
+	endpoint string
+	limiter  *rate.Limiter
+
+	mu    sync.Mutex
+	cache map[string]string // URL -> snapshot URL ("" if none)
+}
+
+// NewArchiveLookup creates a lookup against endpoint (DefaultArchiveEndpoint
+// if empty), making at most rps requests per second.
+func NewArchiveLookup(client *http.Client, endpoint string, rps float64) *ArchiveLookup {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	if endpoint == "" {
+		endpoint = DefaultArchiveEndpoint
+	}
+	if rps <= 0 {
+		rps = DefaultArchiveRate
+	}
+	return &ArchiveLookup{
+		client:   client,
+		endpoint: endpoint,
+		limiter:  rate.NewLimiter(rate.Limit(rps), 1),
+		cache:    make(map[string]string),
+	}
+}
+
+// availability is the subset of the availability API response used here.
+type availability struct {
+	ArchivedSnapshots struct {
+		Closest *struct {
+			Available bool   `json:"available"`
+			URL       string `json:"url"`
+			Status    string `json:"status"`
+		} `json:"closest"`
+	} `json:"archived_snapshots"`
+}
+
+// Snapshot returns the URL of the nearest archived copy of rawURL that was
+// captured with a 200 response, or "" if there is none.
+func (a *ArchiveLookup) Snapshot(ctx context.Context, rawURL string) (string, error) {
+	a.mu.Lock()
+	snapshot, ok := a.cache[rawURL]
+	a.mu.Unlock()
+	if ok {
+		return snapshot, nil
+	}
+
+	if err := a.limiter.Wait(ctx); err != nil {
+		return "", fmt.Errorf("archive lookup wait: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, a.endpoint+"?url="+url.QueryEscape(rawURL), nil)
+	if err != nil {
+		return "", fmt.Errorf("create archive lookup: %w", err)
+	}
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("archive lookup: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("archive lookup: unexpected status %s", resp.Status)
+	}
+	var avail availability
+	if err := json.NewDecoder(resp.Body).Decode(&avail); err != nil {
+		return "", fmt.Errorf("decode archive lookup: %w", err)
+	}
+	if closest := avail.ArchivedSnapshots.Closest; closest != nil && closest.Available && closest.Status == "200" {
+		snapshot = closest.URL
+	}
+
+	a.mu.Lock()
+	a.cache[rawURL] = snapshot
+	a.mu.Unlock()
+	return snapshot, nil
+}
+
+// suggest fills in ArchiveURL for broken external links that have a snapshot.
+// Failed lookups leave the link without a suggestion and are logged.
+func (a *ArchiveLookup) suggest(ctx context.Context, links []result.LinkResult, logger *slog.Logger) {
+	if a == nil {
+		return
+	}
+	for i := range links {
+		if !links[i].IsExternal || ctx.Err() != nil {
+			continue
+		}
+		snapshot, err := a.Snapshot(ctx, links[i].URL)
+		if err != nil {
+			logger.Warn("archive lookup failed", "url", links[i].URL, "host", hostFromURL(links[i].URL), "error", err)
+			continue
+		}
+		links[i].ArchiveURL = snapshot
+	}
+}
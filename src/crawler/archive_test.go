@@ -0,0 +1,129 @@
+package crawler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// newWaybackServer answers availability lookups: URLs containing "archived"
+// have a snapshot, URLs containing "errored" only have a 404 capture.
+func newWaybackServer(lookups *atomic.Int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lookups.Add(1)
+		target := r.URL.Query().Get("url")
+		switch {
+		case strings.Contains(target, "archived"):
+			_, _ = fmt.Fprintf(w, `{"url":%q,"archived_snapshots":{"closest":{"status":"200","available":true,"url":"http://web.archive.org/web/20200101000000/%s","timestamp":"20200101000000"}}}`, target, target)
+		case strings.Contains(target, "errored"):
+			_, _ = fmt.Fprintf(w, `{"archived_snapshots":{"closest":{"status":"404","available":true,"url":"http://web.archive.org/web/2020/%s"}}}`, target)
+		default:
+			_, _ = fmt.Fprint(w, `{"archived_snapshots":{}}`)
+		}
+	}))
+}
+
+func TestArchiveLookup_Snapshot(t *testing.T) {
+	var lookups atomic.Int32
+	wayback := newWaybackServer(&lookups)
+	defer wayback.Close()
+	a := NewArchiveLookup(wayback.Client(), wayback.URL, 1000)
+
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"http://gone.example/archived?a=1&b=2", "http://web.archive.org/web/20200101000000/http://gone.example/archived?a=1&b=2"},
+		{"http://gone.example/errored", ""},
+		{"http://gone.example/never", ""},
+	}
+	for _, tt := range tests {
+		got, err := a.Snapshot(context.Background(), tt.url)
+		if err != nil {
+			t.Fatalf("Snapshot(%q) error: %v", tt.url, err)
+		}
+		if got != tt.want {
+			t.Errorf("Snapshot(%q) = %q, want %q", tt.url, got, tt.want)
+		}
+	}
+
+	// Repeat lookups, including ones with no snapshot, are answered from the cache
+	for _, tt := range tests {
+		_, _ = a.Snapshot(context.Background(), tt.url)
+	}
+	if got := lookups.Load(); got != int32(len(tests)) {
+		t.Errorf("made %d lookups, want %d", got, len(tests))
+	}
+}
+
+func TestArchiveLookup_SnapshotError(t *testing.T) {
+	wayback := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "slow down", http.StatusTooManyRequests)
+	}))
+	defer wayback.Close()
+	a := NewArchiveLookup(wayback.Client(), wayback.URL, 1000)
+
+	if _, err := a.Snapshot(context.Background(), "http://gone.example/"); err == nil {
+		t.Error("expected an error for a 429 from the availability API")
+	}
+}
+
+func TestRun_SuggestsArchivedCopies(t *testing.T) {
+	var lookups atomic.Int32
+	wayback := newWaybackServer(&lookups)
+	defer wayback.Close()
+
+	dead := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.NotFound(w, r)
+	}))
+	defer dead.Close()
+	// Reach the dead host through "localhost" so it is external to the site
+	deadURL := strings.Replace(dead.URL, "127.0.0.1", "localhost", 1)
+
+	site := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = fmt.Fprintf(w, `<a href="%s/archived">ext</a><a href="/archived-internal">int</a>`, deadURL)
+	}))
+	defer site.Close()
+
+	c, err := New(Config{
+		StartURL:    site.URL,
+		Delay:       1,
+		RetryPolicy: RetryPolicy{MaxRetries: 0},
+		Archive:     NewArchiveLookup(wayback.Client(), wayback.URL, 1000),
+	}, nil)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	res, err := c.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run() error: %v", err)
+	}
+
+	if len(res.BrokenLinks) != 2 {
+		t.Fatalf("BrokenLinks = %+v, want 2", res.BrokenLinks)
+	}
+	for _, link := range res.BrokenLinks {
+		switch {
+		case link.IsExternal && !strings.HasPrefix(link.ArchiveURL, "http://web.archive.org/"):
+			t.Errorf("external %s ArchiveURL = %q, want a snapshot", link.URL, link.ArchiveURL)
+		case !link.IsExternal && link.ArchiveURL != "":
+			t.Errorf("internal %s ArchiveURL = %q, want none", link.URL, link.ArchiveURL)
+		}
+	}
+	if got := lookups.Load(); got != 1 {
+		t.Errorf("made %d lookups, want 1 (external links only)", got)
+	}
+}
+
+func TestArchiveLookup_NilSuggestsNothing(t *testing.T) {
+	var a *ArchiveLookup
+	a.suggest(context.Background(), nil, discardLogger)
+}
@@ -0,0 +1,134 @@
+package crawler
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/lukemcguire/zombiecrawl/result"
+	"golang.org/x/net/html"
+)
+
+// pageAuditor collects accessibility issues while a page is tokenized for
+// links: anchors without text, images without alt attributes, and anchors
+// whose visible text is a raw URL. A nil *pageAuditor ignores every call.
+type pageAuditor struct {
+	base   *url.URL
+	issues []result.AccessibilityIssue
+	seen   map[result.AccessibilityIssue]bool
+
+	inAnchor   bool
+	anchorHref string
+	anchorText strings.Builder
+	anchorAlt  bool // The anchor has an aria-label, title, or an image with alt text
+}
+
+// newPageAuditor returns an auditor resolving targets against base.
+func newPageAuditor(base *url.URL) *pageAuditor {
+	return &pageAuditor{base: base, seen: make(map[result.AccessibilityIssue]bool)}
+}
+
+// startAnchor begins collecting the text of an <a> element. An anchor left
+// open by missing markup is finished first.
+func (a *pageAuditor) startAnchor(token html.Token) {
+	if a == nil {
+		return
+	}
+	a.endAnchor()
+	href, hasHref := attr(token, "href")
+	if !hasHref {
+		// Named anchors are not links
+		return
+	}
+	a.inAnchor = true
+	a.anchorHref = href
+	a.anchorText.Reset()
+	label, _ := attr(token, "aria-label")
+	title, _ := attr(token, "title")
+	a.anchorAlt = strings.TrimSpace(label) != "" || strings.TrimSpace(title) != ""
+}
+
+// text records character data, which counts as link text inside an anchor.
+func (a *pageAuditor) text(data []byte) {
+	if a == nil || !a.inAnchor {
+		return
+	}
+	a.anchorText.Write(data)
+	a.anchorText.WriteByte(' ')
+}
+
+// image checks an <img> element for an alt attribute. An empty alt marks a
+// decorative image and is allowed; inside an anchor, alt text labels the link.
+func (a *pageAuditor) image(token html.Token) {
+	if a == nil {
+		return
+	}
+	alt, hasAlt := attr(token, "alt")
+	if !hasAlt {
+		src, _ := attr(token, "src")
+		a.add(result.AccessibilityMissingAlt, src)
+	}
+	if a.inAnchor && strings.TrimSpace(alt) != "" {
+		a.anchorAlt = true
+	}
+}
+
+// endAnchor finishes the current anchor, flagging it if it has no text or its
+// text is a URL.
+func (a *pageAuditor) endAnchor() {
+	if a == nil || !a.inAnchor {
+		return
+	}
+	a.inAnchor = false
+	text := strings.Join(strings.Fields(a.anchorText.String()), " ")
+	switch {
+	case text == "" && !a.anchorAlt:
+		a.add(result.AccessibilityEmptyAnchor, a.anchorHref)
+	case looksLikeURL(text):
+		a.add(result.AccessibilityURLText, a.anchorHref)
+	}
+}
+
+// add records an issue for the element pointing at ref, once per page.
+func (a *pageAuditor) add(kind result.AccessibilityKind, ref string) {
+	target := ref
+	if u, err := url.Parse(strings.TrimSpace(ref)); err == nil && ref != "" {
+		target = a.base.ResolveReference(u).String()
+	}
+	issue := result.AccessibilityIssue{Kind: kind, Target: target}
+	if a.seen[issue] {
+		return
+	}
+	a.seen[issue] = true
+	a.issues = append(a.issues, issue)
+}
+
+// results returns the issues found, attributed to sourcePage.
+func (a *pageAuditor) results(sourcePage string) []result.AccessibilityIssue {
+	if a == nil {
+		return nil
+	}
+	for i := range a.issues {
+		a.issues[i].SourcePage = sourcePage
+	}
+	return a.issues
+}
+
+// looksLikeURL reports whether link text is a bare URL rather than a
+// description of the destination.
+func looksLikeURL(text string) bool {
+	lower := strings.ToLower(text)
+	if strings.ContainsAny(lower, " \t") {
+		return false
+	}
+	return strings.HasPrefix(lower, "http://") || strings.HasPrefix(lower, "https://") || strings.HasPrefix(lower, "www.")
+}
+
+// attr returns the value of the named attribute of token and whether it is set.
+func attr(token html.Token, name string) (string, bool) {
+	for _, a := range token.Attr {
+		if a.Key == name {
+			return a.Val, true
+		}
+	}
+	return "", false
+}
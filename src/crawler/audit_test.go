@@ -0,0 +1,102 @@
+package crawler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/lukemcguire/zombiecrawl/result"
+)
+
+func TestPageAuditor(t *testing.T) {
+	page := `<html><body>
+<a href="/ok">About us</a>
+<a href="/icon"><img src="/i.png" alt="Home"></a>
+<a href="/labelled" aria-label="Search"></a>
+<a href="/empty"> &nbsp; </a>
+<a href="/bare"><img src="/bare.png"></a>
+<a href="https://example.org/x">https://example.org/x</a>
+<a href="/www">www.example.org</a>
+<a href="/sentence">see https://example.org for details</a>
+<a name="top"></a>
+<img src="/decorative.png" alt="">
+<img src="/photo.jpg">
+<img src="/photo.jpg">
+<a href="/unclosed">
+</body></html>`
+	base, _ := url.Parse("https://example.com/dir/")
+	audit := newPageAuditor(base)
+	if _, err := extractLinks(strings.NewReader(page), base, nil, audit, nil, nil); err != nil {
+		t.Fatalf("extractLinks() error: %v", err)
+	}
+
+	var got []string
+	for _, issue := range audit.results("https://example.com/dir/") {
+		if issue.SourcePage != "https://example.com/dir/" {
+			t.Errorf("issue %+v not attributed to the page", issue)
+		}
+		got = append(got, fmt.Sprintf("%s %s", issue.Kind, issue.Target))
+	}
+	want := []string{
+		"empty_anchor https://example.com/empty",
+		"missing_alt https://example.com/bare.png",
+		"empty_anchor https://example.com/bare",
+		"url_text https://example.org/x",
+		"url_text https://example.com/www",
+		"missing_alt https://example.com/photo.jpg",
+		"empty_anchor https://example.com/unclosed",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("issues:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestPageAuditor_Nil(t *testing.T) {
+	var audit *pageAuditor
+	audit.text([]byte("x"))
+	audit.endAnchor()
+	if issues := audit.results("https://example.com/"); issues != nil {
+		t.Errorf("nil auditor returned %v", issues)
+	}
+}
+
+func TestRun_Accessibility(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			_, _ = fmt.Fprint(w, `<html><a href="/">Home</a></html>`)
+			return
+		}
+		_, _ = fmt.Fprint(w, `<html><a href="/a"></a><img src="/logo.png"></html>`)
+	}))
+	defer ts.Close()
+
+	for _, enabled := range []bool{false, true} {
+		cfg := DefaultConfig(ts.URL)
+		cfg.Delay = 1
+		cfg.Accessibility = enabled
+		c, err := New(cfg, nil)
+		if err != nil {
+			t.Fatalf("New() error: %v", err)
+		}
+		res, err := c.Run(context.Background())
+		if err != nil {
+			t.Fatalf("Run() error: %v", err)
+		}
+		wantCount := 0
+		if enabled {
+			wantCount = 2
+		}
+		if len(res.Accessibility) != wantCount {
+			t.Errorf("enabled=%v: got %v, want %d issues", enabled, res.Accessibility, wantCount)
+		}
+		for _, issue := range res.Accessibility {
+			if issue.Kind != result.AccessibilityEmptyAnchor && issue.Kind != result.AccessibilityMissingAlt {
+				t.Errorf("unexpected issue %+v", issue)
+			}
+		}
+	}
+}
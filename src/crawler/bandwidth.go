@@ -0,0 +1,144 @@
+package crawler
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+
+	"golang.org/x/time/rate"
+)
+
+// bandwidthChunk is the most a throttled read returns at once, so bytes are
+// paid for in small steps rather than in one burst per read.
+const bandwidthChunk = 32 * 1024
+
+// Bandwidth limits how fast response bodies are read, in bytes per second,
+// across every crawler sharing it. A nil *Bandwidth places no limit. It is
+// safe for concurrent use.
+type Bandwidth struct {
+	limiter *rate.Limiter
+	burst   int
+
+	mu    sync.RWMutex
+	clock Clock // Times token refills and waits; nil is the system clock
+}
+
+// NewBandwidth returns a limit of bytesPerSecond. Limits below 1 are treated
+// as 1.
+func NewBandwidth(bytesPerSecond int64) *Bandwidth {
+	bytesPerSecond = max(bytesPerSecond, 1)
+	burst := int(min(bytesPerSecond, bandwidthChunk))
+	return &Bandwidth{limiter: rate.NewLimiter(rate.Limit(bytesPerSecond), burst), burst: burst}
+}
+
+// SetClock makes the limit refill and wait on c instead of the system
+// clock. New calls it with Config.Clock when one is set.
+func (b *Bandwidth) SetClock(c Clock) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.clock = c
+}
+
+// bytesPerSecond returns the limit, or 0 for no limit.
+func (b *Bandwidth) bytesPerSecond() int64 {
+	if b == nil {
+		return 0
+	}
+	return int64(b.limiter.Limit())
+}
+
+// reader returns r throttled to the limit until ctx is done.
+func (b *Bandwidth) reader(ctx context.Context, r io.Reader) io.Reader {
+	if b == nil {
+		return r
+	}
+	return &throttledReader{ctx: ctx, reader: r, bandwidth: b}
+}
+
+// wait blocks until n more bytes may be read or ctx is done.
+func (b *Bandwidth) wait(ctx context.Context, n int) error {
+	b.mu.RLock()
+	clock := clockOrSystem(b.clock)
+	b.mu.RUnlock()
+	return waitLimiter(ctx, b.limiter, clock, n)
+}
+
+// throttledReader waits for the bandwidth limit after each read.
+type throttledReader struct {
+	ctx       context.Context
+	reader    io.Reader
+	bandwidth *Bandwidth
+}
+
+// Read implements io.Reader.
+func (t *throttledReader) Read(p []byte) (int, error) {
+	if len(p) > t.bandwidth.burst {
+		p = p[:t.bandwidth.burst]
+	}
+	n, err := t.reader.Read(p)
+	if n > 0 {
+		if waitErr := t.bandwidth.wait(t.ctx, n); waitErr != nil {
+			return n, fmt.Errorf("bandwidth limit: %w", waitErr)
+		}
+	}
+	return n, err
+}
+
+// bandwidthUnits are the size suffixes ParseBandwidth and ParseSize accept, longest first
+// so "KiB" is not read as "B".
+var bandwidthUnits = []struct {
+	suffix     string
+	multiplier int64
+}{
+	{"kib", 1 << 10}, {"mib", 1 << 20}, {"gib", 1 << 30},
+	{"kb", 1e3}, {"mb", 1e6}, {"gb", 1e9},
+	{"k", 1e3}, {"m", 1e6}, {"g", 1e9},
+	{"b", 1},
+}
+
+// ParseBandwidth converts a rate such as "5MB/s", "512KiB/s", or "1000" (bytes
+// per second) into bytes per second. Decimal units (KB, MB, GB) are powers of
+// 1000 and binary units (KiB, MiB, GiB) powers of 1024.
+func ParseBandwidth(s string) (int64, error) {
+	bytesPerSecond, err := parseBytes(strings.TrimSuffix(strings.ToLower(strings.TrimSpace(s)), "/s"))
+	if err != nil {
+		return 0, fmt.Errorf("invalid bandwidth %q (want e.g. 5MB/s or 512KiB/s)", s)
+	}
+	if bytesPerSecond < 1 {
+		return 0, fmt.Errorf("bandwidth %q is below 1 byte per second", s)
+	}
+	return bytesPerSecond, nil
+}
+
+// ParseSize converts a size such as "10MB", "512KiB", or "1000" (bytes) into
+// bytes, with the units of ParseBandwidth.
+func ParseSize(s string) (int64, error) {
+	size, err := parseBytes(strings.ToLower(strings.TrimSpace(s)))
+	if err != nil || size < 1 {
+		return 0, fmt.Errorf("invalid size %q (want e.g. 10MB or 512KiB)", s)
+	}
+	return size, nil
+}
+
+// parseBytes converts a lowercase number of bytes with an optional unit
+// suffix into bytes.
+func parseBytes(value string) (int64, error) {
+	multiplier := int64(1)
+	for _, unit := range bandwidthUnits {
+		if number, ok := strings.CutSuffix(value, unit.suffix); ok {
+			value, multiplier = strings.TrimSpace(number), unit.multiplier
+			break
+		}
+	}
+	number, err := strconv.ParseFloat(value, 64)
+	if err != nil || number <= 0 {
+		return 0, fmt.Errorf("invalid byte count %q", value)
+	}
+	return int64(number * float64(multiplier)), nil
+}
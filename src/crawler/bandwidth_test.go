@@ -0,0 +1,115 @@
+package crawler
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseBandwidth(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    int64
+		wantErr bool
+	}{
+		{"1000", 1000, false},
+		{"5MB/s", 5_000_000, false},
+		{"512KiB/s", 512 * 1024, false},
+		{"1.5 mib", 1536 * 1024, false},
+		{"2G", 2_000_000_000, false},
+		{"100b/s", 100, false},
+		{"0", 0, true},
+		{"-1MB", 0, true},
+		{"fast", 0, true},
+		{"0.1", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseBandwidth(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseBandwidth(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+		}
+		if got != tt.want {
+			t.Errorf("ParseBandwidth(%q) = %d, want %d", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestParseSize(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    int64
+		wantErr bool
+	}{
+		{"1000", 1000, false},
+		{"10MiB", 10 << 20, false},
+		{"512 kb", 512_000, false},
+		{"0", 0, true},
+		{"5MB/s", 0, true},
+		{"lots", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseSize(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseSize(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+		}
+		if got != tt.want {
+			t.Errorf("ParseSize(%q) = %d, want %d", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestBandwidth_Reader(t *testing.T) {
+	var nilLimit *Bandwidth
+	src := strings.NewReader("x")
+	if got := nilLimit.reader(context.Background(), src); got != src {
+		t.Error("nil Bandwidth wrapped the reader")
+	}
+
+	// 12 KiB at 8 KiB/s: the first 8 KiB burst is free, the rest waits about
+	// half a second
+	limit := NewBandwidth(8 * 1024)
+	data := bytes.Repeat([]byte("z"), 12*1024)
+	started := time.Now()
+	got, err := io.ReadAll(limit.reader(context.Background(), bytes.NewReader(data)))
+	if err != nil || len(got) != len(data) {
+		t.Fatalf("read %d bytes, err %v; want %d", len(got), err, len(data))
+	}
+	if elapsed := time.Since(started); elapsed < 400*time.Millisecond {
+		t.Errorf("read 12 KiB at 8 KiB/s in %v, want about 0.5s", elapsed)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	slow := NewBandwidth(1)
+	if _, err := io.ReadAll(slow.reader(ctx, bytes.NewReader(data))); !errors.Is(err, context.Canceled) {
+		t.Errorf("cancelled read error = %v, want context.Canceled", err)
+	}
+}
+
+func TestCheckURL_Bandwidth(t *testing.T) {
+	page := "<html>" + strings.Repeat(" ", 16*1024) + `<a href="/next">next</a></html>`
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(page))
+	}))
+	defer ts.Close()
+
+	cfg := DefaultConfig(ts.URL)
+	cfg.Bandwidth = NewBandwidth(8 * 1024)
+	started := time.Now()
+	res := CheckURL(context.Background(), &http.Client{}, CrawlJob{URL: ts.URL}, cfg)
+	if res.Result != nil || len(res.Links) != 1 {
+		t.Fatalf("result %+v, links %v; want the page parsed", res.Result, res.Links)
+	}
+	if res.Bytes != int64(len(page)) {
+		t.Errorf("Bytes = %d, want %d", res.Bytes, len(page))
+	}
+	if elapsed := time.Since(started); elapsed < 500*time.Millisecond {
+		t.Errorf("read %d bytes at 8 KiB/s in %v, want about 1s", len(page), elapsed)
+	}
+}
@@ -0,0 +1,64 @@
+package crawler
+
+import (
+	"fmt"
+	"net/http"
+	"path"
+
+	"github.com/lukemcguire/zombiecrawl/result"
+)
+
+// validateBlockedHosts rejects malformed Config.BlockedHosts patterns.
+func validateBlockedHosts(patterns []string) error {
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("blocked host pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// blockedHost returns the first of patterns matching rawURL's host, or "" if
+// none does. Patterns use path.Match glob syntax against the lowercase
+// hostname.
+func blockedHost(patterns []string, rawURL string) string {
+	if len(patterns) == 0 {
+		return ""
+	}
+	for _, pattern := range patterns {
+		if matchHost(pattern, rawURL) {
+			return pattern
+		}
+	}
+	return ""
+}
+
+// blockingTransport refuses requests to blocked hosts, so they are never
+// contacted even when a redirect or a post-crawl check leads there.
+type blockingTransport struct {
+	base     http.RoundTripper
+	patterns []string
+}
+
+// blockHosts wraps base to refuse requests to hosts matching patterns. base
+// is returned unchanged if there are none.
+func blockHosts(base http.RoundTripper, patterns []string) http.RoundTripper {
+	if len(patterns) == 0 {
+		return base
+	}
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &blockingTransport{base: base, patterns: patterns}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *blockingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if pattern := blockedHost(t.patterns, req.URL.String()); pattern != "" {
+		if req.Body != nil {
+			_ = req.Body.Close()
+		}
+		return nil, fmt.Errorf("%w: %s matches %q", result.ErrBlockedHost, req.URL.Hostname(), pattern)
+	}
+	return t.base.RoundTrip(req)
+}
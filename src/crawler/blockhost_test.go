@@ -0,0 +1,92 @@
+package crawler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/lukemcguire/zombiecrawl/result"
+)
+
+func TestRun_BlockedHosts(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/":
+			_, _ = fmt.Fprint(w, `<a href="http://Tracker.invalid/pixel.gif">pixel</a>
+				<a href="http://tracker.invalid/pixel.gif?again">pixel</a>
+				<a href="/out">out</a>`)
+		case "/out":
+			http.Redirect(w, r, "http://api.paid.invalid/v1", http.StatusFound)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	cfg := DefaultConfig(ts.URL)
+	cfg.Delay = 1
+	cfg.LosslessEvents = true
+	cfg.BlockedHosts = []string{"tracker.invalid", "*.PAID.invalid"}
+	progressCh := make(chan CrawlEvent, 100)
+	c, err := New(cfg, progressCh)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	res, err := c.Run(context.Background())
+	close(progressCh)
+	if err != nil {
+		t.Fatalf("Run() error: %v", err)
+	}
+
+	want := []result.SkippedLink{
+		{URL: "http://tracker.invalid/pixel.gif", SourcePage: ts.URL + "/", Reason: `host matches blocked pattern "tracker.invalid"`},
+		{URL: "http://tracker.invalid/pixel.gif?again", SourcePage: ts.URL + "/", Reason: `host matches blocked pattern "tracker.invalid"`},
+	}
+	slices.SortFunc(res.Skipped, func(a, b result.SkippedLink) int { return len(a.URL) - len(b.URL) })
+	if !slices.Equal(res.Skipped, want) {
+		t.Errorf("Skipped = %+v, want %+v", res.Skipped, want)
+	}
+	// A redirect into a blocked host is refused rather than followed
+	if len(res.BrokenLinks) != 1 || res.BrokenLinks[0].URL != ts.URL+"/out" || res.BrokenLinks[0].ErrorCategory != result.CategoryBlockedHost {
+		t.Errorf("BrokenLinks = %+v, want /out as a blocked host", res.BrokenLinks)
+	}
+
+	skippedEvents := 0
+	for evt := range progressCh {
+		if evt.ErrorCategory == result.CategoryBlockedHost && evt.Checked == 0 {
+			skippedEvents++
+		}
+	}
+	if skippedEvents != 2 {
+		t.Errorf("got %d blocked host events, want 2", skippedEvents)
+	}
+}
+
+func TestNew_InvalidBlockedHost(t *testing.T) {
+	cfg := DefaultConfig("http://example.com")
+	cfg.BlockedHosts = []string{"[bad"}
+	if _, err := New(cfg, nil); err == nil {
+		t.Error("New() error = nil, want an invalid pattern error")
+	}
+}
+
+func TestBlockedHost(t *testing.T) {
+	patterns := []string{"*.doubleclick.net", "stats.example.com"}
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"https://ad.DoubleClick.net/pixel", "*.doubleclick.net"},
+		{"https://stats.example.com:8443/collect", "stats.example.com"},
+		{"https://doubleclick.net/", ""},
+		{"https://example.com/stats.example.com", ""},
+	}
+	for _, tt := range tests {
+		if got := blockedHost(patterns, tt.url); got != tt.want {
+			t.Errorf("blockedHost(%q) = %q, want %q", tt.url, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,214 @@
+package crawler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// ExternalCache remembers external link verdicts across runs so repeated
+// crawls do not re-check the same external URLs every time. Only healthy
+// verdicts younger than the TTL are reused; broken and expired entries are
+// always rechecked. It also keeps the ETag, Last-Modified date, and links of
+// internal pages, so a page the server reports as 304 Not Modified is not
+// downloaded and parsed again, unless the page is marked noarchive,
+// nosnippet, or none (see SetKeepNoArchive). Entries live in a
+// ResponseCache. A nil *ExternalCache caches nothing.
+type ExternalCache struct {
+	backend       ResponseCache
+	ttl           time.Duration
+	now           func() time.Time
+	keepNoArchive bool
+}
+
+// CacheEntry is the stored verdict for one external URL, or the validators
+// and links of one internal page.
+type CacheEntry struct {
+	StatusCode int       `json:"status_code,omitempty"` // HTTP status of the last check (0 if none was received)
+	Broken     bool      `json:"broken,omitempty"`      // Whether the last check failed
+	CheckedAt  time.Time `json:"checked_at"`            // When the last check ran
+
+	ETag         string   `json:"etag,omitempty"`          // Internal pages: ETag response header
+	LastModified string   `json:"last_modified,omitempty"` // Internal pages: Last-Modified response header
+	Links        []string `json:"links,omitempty"`         // Internal pages: links found on the page
+}
+
+// NewExternalCache creates a cache that keeps verdicts in store and reuses
+// healthy ones younger than ttl.
+func NewExternalCache(store ResponseCache, ttl time.Duration) *ExternalCache {
+	return &ExternalCache{backend: store, ttl: ttl, now: time.Now}
+}
+
+// LoadExternalCache creates a cache backed by the JSON file at path, treating
+// a missing file as empty. Entries older than ttl are ignored when looking up
+// URLs and dropped on Save.
+func LoadExternalCache(path string, ttl time.Duration) (*ExternalCache, error) {
+	cache := NewExternalCache(nil, ttl)
+	store, err := NewFileResponseCache(path, ttl)
+	if err != nil {
+		return nil, err
+	}
+	// Share the cache's clock so expiry on Save matches expiry on lookup
+	store.now = func() time.Time { return cache.now() }
+	cache.backend = store
+	return cache, nil
+}
+
+// SetKeepNoArchive sets whether the links of internal pages whose
+// X-Robots-Tag header or robots meta tag says noarchive, nosnippet, or none
+// are stored. By default such pages are not cached and are downloaded in
+// full on every run.
+func (c *ExternalCache) SetKeepNoArchive(keep bool) {
+	c.keepNoArchive = keep
+}
+
+// fresh reports whether rawURL passed a check within the TTL.
+func (c *ExternalCache) fresh(rawURL string) bool {
+	if c == nil {
+		return false
+	}
+	entry, ok := c.backend.Get(rawURL)
+	return ok && !entry.Broken && c.now().Sub(entry.CheckedAt) < c.ttl
+}
+
+// page returns the stored validators and links of an internal page, if the
+// page had a validator when it was last fetched.
+func (c *ExternalCache) page(rawURL string) (CacheEntry, bool) {
+	if c == nil {
+		return CacheEntry{}, false
+	}
+	entry, ok := c.backend.Get(rawURL)
+	return entry, ok && !entry.Broken && (entry.ETag != "" || entry.LastModified != "")
+}
+
+// store records the verdict of a completed external check, or the
+// validators and links of a working internal page that has validators.
+func (c *ExternalCache) store(res CrawlResult) {
+	if c == nil || res.Cached {
+		return
+	}
+	if !res.Job.IsExternal {
+		if res.Result != nil || res.Err != nil || (res.ETag == "" && res.LastModified == "") {
+			return
+		}
+		if res.NoArchive && !c.keepNoArchive {
+			return
+		}
+		c.backend.Put(res.Job.URL, CacheEntry{
+			StatusCode:   res.StatusCode,
+			CheckedAt:    c.now().UTC(),
+			ETag:         res.ETag,
+			LastModified: res.LastModified,
+			Links:        res.Links,
+		})
+		return
+	}
+	c.backend.Put(res.Job.URL, CacheEntry{
+		StatusCode: res.StatusCode,
+		Broken:     res.Result != nil,
+		CheckedAt:  c.now().UTC(),
+	})
+}
+
+// Save flushes the cache's verdicts to its store.
+func (c *ExternalCache) Save() error {
+	if c == nil {
+		return nil
+	}
+	return c.backend.Flush()
+}
+
+// FileResponseCache is a ResponseCache kept in memory and written to a JSON
+// file on Flush.
+type FileResponseCache struct {
+	mu      sync.Mutex
+	path    string
+	maxAge  time.Duration
+	entries map[string]CacheEntry
+	now     func() time.Time
+}
+
+// NewFileResponseCache reads the cache stored at path, treating a missing
+// file as empty. Entries older than maxAge are dropped on Flush; zero keeps
+// every entry.
+func NewFileResponseCache(path string, maxAge time.Duration) (*FileResponseCache, error) {
+	cache := &FileResponseCache{
+		path:    path,
+		maxAge:  maxAge,
+		entries: make(map[string]CacheEntry),
+		now:     time.Now,
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return cache, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read external cache: %w", err)
+	}
+	if err := json.Unmarshal(data, &cache.entries); err != nil {
+		return nil, fmt.Errorf("decode external cache %s: %w", path, err)
+	}
+	return cache, nil
+}
+
+// Get returns the stored entry for url.
+func (c *FileResponseCache) Get(url string) (CacheEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[url]
+	return entry, ok
+}
+
+// Put stores the entry for url.
+func (c *FileResponseCache) Put(url string, entry CacheEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[url] = entry
+}
+
+// Flush writes unexpired entries back to the cache file, creating its
+// directory if needed. The file is replaced atomically.
+func (c *FileResponseCache) Flush() error {
+	c.mu.Lock()
+	if c.maxAge > 0 {
+		now := c.now()
+		for rawURL, entry := range c.entries {
+			if now.Sub(entry.CheckedAt) >= c.maxAge {
+				delete(c.entries, rawURL)
+			}
+		}
+	}
+	data, err := json.Marshal(c.entries)
+	c.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("encode external cache: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(c.path), 0o755); err != nil {
+		return fmt.Errorf("create external cache directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(c.path), ".external-cache-*")
+	if err != nil {
+		return fmt.Errorf("create external cache: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return fmt.Errorf("write external cache: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return fmt.Errorf("close external cache: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), c.path); err != nil {
+		_ = os.Remove(tmp.Name())
+		return fmt.Errorf("replace external cache: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,271 @@
+package crawler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/lukemcguire/zombiecrawl/result"
+)
+
+func TestExternalCache_NilIsNoop(t *testing.T) {
+	var c *ExternalCache
+	if c.fresh("http://example.com/") {
+		t.Error("nil cache reported a fresh entry")
+	}
+	c.store(CrawlResult{Job: CrawlJob{URL: "http://example.com/", IsExternal: true}})
+	if err := c.Save(); err != nil {
+		t.Errorf("nil cache Save() error: %v", err)
+	}
+}
+
+func TestExternalCache_Fresh(t *testing.T) {
+	c, err := LoadExternalCache(filepath.Join(t.TempDir(), "missing.json"), time.Hour)
+	if err != nil {
+		t.Fatalf("LoadExternalCache() error: %v", err)
+	}
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	c.now = func() time.Time { return now }
+
+	ok := CrawlResult{Job: CrawlJob{URL: "http://ok.example/", IsExternal: true}, StatusCode: 200}
+	bad := CrawlResult{
+		Job:    CrawlJob{URL: "http://bad.example/", IsExternal: true},
+		Result: &result.LinkResult{StatusCode: 404},
+	}
+	internal := CrawlResult{Job: CrawlJob{URL: "http://site.example/"}, StatusCode: 200}
+	for _, res := range []CrawlResult{ok, bad, internal} {
+		c.store(res)
+	}
+
+	if !c.fresh("http://ok.example/") {
+		t.Error("healthy entry should be fresh")
+	}
+	if c.fresh("http://bad.example/") {
+		t.Error("broken entry should always be rechecked")
+	}
+	if c.fresh("http://site.example/") {
+		t.Error("internal URLs should not be cached")
+	}
+
+	now = now.Add(time.Hour)
+	if c.fresh("http://ok.example/") {
+		t.Error("entry should expire after the TTL")
+	}
+}
+
+func TestExternalCache_SaveLoadDropsExpired(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "cache.json")
+	c, err := LoadExternalCache(path, time.Hour)
+	if err != nil {
+		t.Fatalf("LoadExternalCache() error: %v", err)
+	}
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	c.now = func() time.Time { return now }
+	c.store(CrawlResult{Job: CrawlJob{URL: "http://old.example/", IsExternal: true}, StatusCode: 200})
+	now = now.Add(45 * time.Minute)
+	c.store(CrawlResult{Job: CrawlJob{URL: "http://new.example/", IsExternal: true}, StatusCode: 204})
+	now = now.Add(30 * time.Minute)
+
+	if err := c.Save(); err != nil {
+		t.Fatalf("Save() error: %v", err)
+	}
+
+	loaded, err := LoadExternalCache(path, time.Hour)
+	if err != nil {
+		t.Fatalf("reload error: %v", err)
+	}
+	if _, ok := loaded.backend.Get("http://old.example/"); ok {
+		t.Error("expired entry was saved")
+	}
+	if entry, _ := loaded.backend.Get("http://new.example/"); entry.StatusCode != 204 {
+		t.Errorf("new entry = %+v, want status 204", entry)
+	}
+}
+
+func TestRun_ExternalCacheSkipsRecentlyCheckedLinks(t *testing.T) {
+	var externalHits atomic.Int32
+	external := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/robots.txt" {
+			externalHits.Add(1)
+		}
+	}))
+	defer external.Close()
+	// Reach the external server through "localhost" so it is a different host from the site
+	externalURL := strings.Replace(external.URL, "127.0.0.1", "localhost", 1)
+
+	site := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprintf(w, `<a href="%s/lib.js">lib</a>`, externalURL)
+	}))
+	defer site.Close()
+
+	path := filepath.Join(t.TempDir(), "cache.json")
+	crawl := func() *result.Result {
+		t.Helper()
+		cache, err := LoadExternalCache(path, time.Hour)
+		if err != nil {
+			t.Fatalf("LoadExternalCache() error: %v", err)
+		}
+		c, err := New(Config{StartURL: site.URL, Delay: 1, ExternalCache: cache}, nil)
+		if err != nil {
+			t.Fatalf("New() error: %v", err)
+		}
+		res, err := c.Run(context.Background())
+		if err != nil {
+			t.Fatalf("Run() error: %v", err)
+		}
+		if err := cache.Save(); err != nil {
+			t.Fatalf("Save() error: %v", err)
+		}
+		return res
+	}
+
+	first := crawl()
+	second := crawl()
+
+	if got := externalHits.Load(); got != 1 {
+		t.Errorf("external server hit %d times, want 1", got)
+	}
+	if first.Stats.CacheHits != 0 || second.Stats.CacheHits != 1 {
+		t.Errorf("cache hits = %d then %d, want 0 then 1", first.Stats.CacheHits, second.Stats.CacheHits)
+	}
+	if second.Stats.TotalChecked != first.Stats.TotalChecked {
+		t.Errorf("cached run checked %d URLs, first run %d", second.Stats.TotalChecked, first.Stats.TotalChecked)
+	}
+}
+
+func TestRun_ExternalCacheRevalidatesPages(t *testing.T) {
+	const lastModified = "Mon, 02 Jan 2006 15:04:05 GMT"
+	var bodies atomic.Int32
+	var version atomic.Value
+	version.Store(`"v1"`)
+	site := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/":
+			etag := version.Load().(string)
+			w.Header().Set("ETag", etag)
+			if r.Header.Get("If-None-Match") == etag {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			bodies.Add(1)
+			_, _ = fmt.Fprint(w, `<a href="/about">about</a>`)
+		case "/about":
+			w.Header().Set("Last-Modified", lastModified)
+			if r.Header.Get("If-Modified-Since") == lastModified {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			bodies.Add(1)
+			_, _ = fmt.Fprint(w, `<a href="/missing">gone</a>`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer site.Close()
+
+	path := filepath.Join(t.TempDir(), "cache.json")
+	crawl := func() *result.Result {
+		t.Helper()
+		cache, err := LoadExternalCache(path, time.Hour)
+		if err != nil {
+			t.Fatalf("LoadExternalCache() error: %v", err)
+		}
+		c, err := New(Config{StartURL: site.URL, Delay: 1, ExternalCache: cache}, nil)
+		if err != nil {
+			t.Fatalf("New() error: %v", err)
+		}
+		res, err := c.Run(context.Background())
+		if err != nil {
+			t.Fatalf("Run() error: %v", err)
+		}
+		if err := cache.Save(); err != nil {
+			t.Fatalf("Save() error: %v", err)
+		}
+		return res
+	}
+
+	first := crawl()
+	second := crawl()
+	if got := bodies.Load(); got != 2 {
+		t.Errorf("pages downloaded %d times over two runs, want 2", got)
+	}
+	if first.Stats.NotModified != 0 || second.Stats.NotModified != 2 {
+		t.Errorf("not modified = %d then %d, want 0 then 2", first.Stats.NotModified, second.Stats.NotModified)
+	}
+	// Links from unchanged pages are still followed and checked
+	if len(second.BrokenLinks) != 1 || second.BrokenLinks[0].URL != site.URL+"/missing" {
+		t.Errorf("second run broken links = %+v, want /missing", second.BrokenLinks)
+	}
+
+	version.Store(`"v2"`)
+	third := crawl()
+	if got := bodies.Load(); got != 3 || third.Stats.NotModified != 1 {
+		t.Errorf("after a change: %d downloads, %d not modified; want 3 and 1", got, third.Stats.NotModified)
+	}
+}
+
+func TestRun_ExternalCacheStrictURLsDownloadsPages(t *testing.T) {
+	var backslashHits atomic.Int32
+	site := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/":
+			w.Header().Set("ETag", `"v1"`)
+			if r.Header.Get("If-None-Match") == `"v1"` {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			_, _ = fmt.Fprint(w, `<a href="/docs\intro">docs</a>`)
+		case "/docs/intro":
+			backslashHits.Add(1)
+			_, _ = fmt.Fprint(w, `<html></html>`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer site.Close()
+
+	path := filepath.Join(t.TempDir(), "cache.json")
+	for run := range 2 {
+		cache, err := LoadExternalCache(path, time.Hour)
+		if err != nil {
+			t.Fatalf("LoadExternalCache() error: %v", err)
+		}
+		c, err := New(Config{StartURL: site.URL, Delay: 1, ExternalCache: cache, StrictURLs: true}, nil)
+		if err != nil {
+			t.Fatalf("New() error: %v", err)
+		}
+		res, err := c.Run(context.Background())
+		if err != nil {
+			t.Fatalf("Run() error: %v", err)
+		}
+		if err := cache.Save(); err != nil {
+			t.Fatalf("Save() error: %v", err)
+		}
+		// Reusing the cached links would follow the backslash link unchecked
+		if len(res.BrokenLinks) != 1 || res.BrokenLinks[0].ErrorCategory != result.CategoryMalformedURL {
+			t.Errorf("run %d: broken links %v, want the suspicious link", run+1, res.BrokenLinks)
+		}
+	}
+	if got := backslashHits.Load(); got != 0 {
+		t.Errorf("backslash link fetched %d times, want never", got)
+	}
+}
+
+func TestExternalCache_SkipsNoArchivePages(t *testing.T) {
+	res := CrawlResult{Job: CrawlJob{URL: "https://example.com/private"}, ETag: `"v1"`, NoArchive: true, Links: []string{}}
+	for _, keep := range []bool{false, true} {
+		store := NewMemoryResponseCache()
+		cache := NewExternalCache(store, time.Hour)
+		cache.SetKeepNoArchive(keep)
+		cache.store(res)
+		if _, stored := cache.page(res.Job.URL); stored != keep {
+			t.Errorf("keep %v: page cached = %v, want %v", keep, stored, keep)
+		}
+	}
+}
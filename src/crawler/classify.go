@@ -0,0 +1,43 @@
+package crawler
+
+import (
+	"github.com/lukemcguire/zombiecrawl/urlutil"
+)
+
+// LinkClass is how Config.ClassifyLink treats a discovered link.
+type LinkClass int
+
+const (
+	// LinkDefault leaves the decision to the crawler: links on the start
+	// URL's site are internal, all others external.
+	LinkDefault LinkClass = iota
+	// LinkInternal crawls the link as a page of the site, following its
+	// links, even if it is on another host.
+	LinkInternal
+	// LinkExternal only checks that the link works, even if it is on the
+	// start URL's site.
+	LinkExternal
+	// LinkSkip never requests the link. It is reported in Result.Skipped.
+	LinkSkip
+	// LinkPriority is LinkInternal, crawled ahead of every link queued
+	// without priority.
+	LinkPriority
+)
+
+// skippedByClassifier is the Result.Skipped reason of LinkSkip links.
+const skippedByClassifier = "skipped by link classifier"
+
+// classify returns the class of link, found on sourcePage, under
+// Config.ClassifyLink, resolving LinkDefault by whether link is on
+// startHost's site.
+func (cfg Config) classify(link, sourcePage, startHost string) LinkClass {
+	if cfg.ClassifyLink != nil {
+		if class := cfg.ClassifyLink(link, sourcePage); class != LinkDefault {
+			return class
+		}
+	}
+	if urlutil.IsSameDomain(link, startHost) {
+		return LinkInternal
+	}
+	return LinkExternal
+}
@@ -0,0 +1,101 @@
+package crawler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/lukemcguire/zombiecrawl/result"
+)
+
+func TestRun_ClassifyLink(t *testing.T) {
+	var requested []string
+	cdn := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = append(requested, "cdn"+r.URL.Path)
+		if r.URL.Path == "/assets/index" {
+			_, _ = fmt.Fprint(w, `<a href="/assets/missing.css">css</a>`)
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	defer cdn.Close()
+	cdnURL := strings.Replace(cdn.URL, "127.0.0.1", "localhost", 1)
+
+	site := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = append(requested, r.URL.Path)
+		switch r.URL.Path {
+		case "/":
+			_, _ = fmt.Fprintf(w, `<a href="/shop">shop</a> <a href="/logout">logout</a>
+				<a href="/legacy">legacy</a> <a href="%s/assets/index">assets</a>`, cdnURL)
+		case "/shop":
+			_, _ = fmt.Fprint(w, `<p>shop</p>`)
+		case "/legacy":
+			_, _ = fmt.Fprint(w, `<a href="/unreached">more</a>`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer site.Close()
+
+	cfg := DefaultConfig(site.URL)
+	cfg.Delay = 1
+	cfg.Concurrency = 1
+	cfg.IgnoreRobots = true
+	cfg.ClassifyLink = func(url, sourcePage string) LinkClass {
+		switch {
+		case strings.HasPrefix(url, cdnURL):
+			return LinkPriority
+		case strings.HasSuffix(url, "/logout"):
+			return LinkSkip
+		case strings.HasSuffix(url, "/legacy"):
+			return LinkExternal
+		}
+		return LinkDefault
+	}
+	c, err := New(cfg, nil)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	res, err := c.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run() error: %v", err)
+	}
+
+	// The CDN page is crawled first and its links followed; /legacy is
+	// only checked, so its link is never reached
+	if len(requested) < 2 || requested[1] != "cdn/assets/index" {
+		t.Errorf("requested %v, want the CDN page right after the start page", requested)
+	}
+	for _, path := range []string{"cdn/assets/missing.css", "/shop", "/legacy"} {
+		if !slices.Contains(requested, path) {
+			t.Errorf("requested %v, want %s", requested, path)
+		}
+	}
+	for _, path := range []string{"/logout", "/unreached"} {
+		if slices.Contains(requested, path) {
+			t.Errorf("requested %v, want no %s", requested, path)
+		}
+	}
+	wantSkipped := []result.SkippedLink{{URL: site.URL + "/logout", SourcePage: site.URL + "/", Reason: skippedByClassifier}}
+	if !slices.Equal(res.Skipped, wantSkipped) {
+		t.Errorf("Skipped = %+v, want %+v", res.Skipped, wantSkipped)
+	}
+}
+
+func TestConfig_Classify(t *testing.T) {
+	cfg := Config{}
+	if got := cfg.classify("https://example.com/a", "", "example.com"); got != LinkInternal {
+		t.Errorf("classify() on the site = %v, want LinkInternal", got)
+	}
+	if got := cfg.classify("https://other.com/a", "", "example.com"); got != LinkExternal {
+		t.Errorf("classify() off the site = %v, want LinkExternal", got)
+	}
+	cfg.ClassifyLink = func(string, string) LinkClass { return LinkDefault }
+	if got := cfg.classify("https://other.com/a", "", "example.com"); got != LinkExternal {
+		t.Errorf("classify() with LinkDefault = %v, want LinkExternal", got)
+	}
+}
@@ -0,0 +1,116 @@
+package crawler
+
+import (
+	"context"
+	"time"
+)
+
+// DeterministicEpoch is the instant a deterministic crawl's clock is fixed at.
+var DeterministicEpoch = time.Unix(0, 0).UTC()
+
+// Clock is the crawler's source of time: retry backoff, the rate limiter and
+// bandwidth cap, the queue timeout, the --verify delay, robots.txt cache
+// expiry, the Throttle and CacheResults middleware, and reported timings,
+// including HAR entries and LogFetches durations, all go through it.
+// Embedders can supply one in Config.Clock to drive a crawl with simulated
+// time.
+type Clock interface {
+	Now() time.Time
+	Sleeper
+}
+
+// Sleeper waits out delays.
+type Sleeper interface {
+	// Sleep blocks for d, or until ctx is done, in which case it returns
+	// ctx's error.
+	Sleep(ctx context.Context, d time.Duration) error
+}
+
+// systemClock reads the wall clock and sleeps in real time.
+type systemClock struct{}
+
+// Now implements Clock.
+func (systemClock) Now() time.Time { return time.Now() }
+
+// Sleep implements Sleeper.
+func (systemClock) Sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// clockOrSystem returns c, or the system clock if c is nil.
+func clockOrSystem(c Clock) Clock {
+	if c == nil {
+		return systemClock{}
+	}
+	return c
+}
+
+// fixedClock reports the same instant forever, so every measured duration
+// is zero and reports do not vary between runs. It sleeps on the embedded
+// Clock.
+type fixedClock struct {
+	Clock
+	at time.Time
+}
+
+// Now implements Clock.
+func (c fixedClock) Now() time.Time { return c.at }
+
+// clock returns cfg.Clock, or the system clock if it is nil.
+func (cfg Config) clock() Clock {
+	return clockOrSystem(cfg.Clock)
+}
+
+// timingClock returns the clock reported timings are measured on: the
+// deterministic clock New installs, else cfg.Clock.
+func (cfg Config) timingClock() Clock {
+	if cfg.timings != nil {
+		return cfg.timings
+	}
+	return cfg.clock()
+}
+
+// now returns the current time for reported timings.
+func (cfg Config) now() time.Time {
+	return cfg.timingClock().Now()
+}
+
+// since returns the time elapsed since t on the clock now reads.
+func (cfg Config) since(t time.Time) time.Duration {
+	return cfg.now().Sub(t)
+}
+
+// clocksKey is the context key under which withClocks stores crawlClocks.
+type clocksKey struct{}
+
+// crawlClocks are the clocks of the crawl a check belongs to, for middleware,
+// which has no Config: clock waits and expires entries, and timings measures
+// reported durations.
+type crawlClocks struct {
+	clock   Clock
+	timings Clock
+}
+
+// withClocks returns ctx carrying cfg's clocks.
+func (cfg Config) withClocks(ctx context.Context) context.Context {
+	return context.WithValue(ctx, clocksKey{}, crawlClocks{clock: cfg.clock(), timings: cfg.timingClock()})
+}
+
+// clocksFrom returns the clocks withClocks stored in ctx, or the system clock
+// for both outside a crawl.
+func clocksFrom(ctx context.Context) crawlClocks {
+	if clocks, ok := ctx.Value(clocksKey{}).(crawlClocks); ok {
+		return clocks
+	}
+	return crawlClocks{clock: systemClock{}, timings: systemClock{}}
+}
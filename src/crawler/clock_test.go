@@ -0,0 +1,320 @@
+package crawler
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/lukemcguire/zombiecrawl/result"
+)
+
+// fakeClock is a Clock whose Sleep advances simulated time at once.
+type fakeClock struct {
+	mu    sync.Mutex
+	now   time.Time
+	slept []time.Duration
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Sleep(ctx context.Context, d time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	c.advance(d)
+	c.mu.Lock()
+	c.slept = append(c.slept, d)
+	c.mu.Unlock()
+	return nil
+}
+
+func (c *fakeClock) advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func (c *fakeClock) sleeps() []time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return slices.Clone(c.slept)
+}
+
+func TestConfigClock(t *testing.T) {
+	var cfg Config
+	before := time.Now()
+	if now := cfg.now(); now.Before(before) {
+		t.Errorf("now() = %v without a clock, want the wall clock", now)
+	}
+
+	cfg.timings = fixedClock{Clock: systemClock{}, at: DeterministicEpoch}
+	if now := cfg.now(); !now.Equal(DeterministicEpoch) {
+		t.Errorf("now() = %v, want the fixed instant", now)
+	}
+	if elapsed := cfg.since(DeterministicEpoch); elapsed != 0 {
+		t.Errorf("since() = %v on a fixed clock, want 0", elapsed)
+	}
+}
+
+func TestRun_Deterministic(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/":
+			_, _ = fmt.Fprint(w, `<a href="/z">z</a><a href="/gone-b">b</a><a href="/m">m</a><a href="/gone-a">a</a>`)
+		case "/m", "/z":
+			_, _ = fmt.Fprintf(w, `<a href="/gone%s">gone</a>`, r.URL.Path)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	crawl := func() []string {
+		cfg := DefaultConfig(ts.URL)
+		cfg.Delay = 1
+		cfg.Concurrency = 8
+		cfg.Deterministic = true
+		c, err := New(cfg, nil)
+		if err != nil {
+			t.Fatalf("New() error: %v", err)
+		}
+		if c.cfg.Concurrency != 1 {
+			t.Errorf("Concurrency = %d, want 1 in deterministic mode", c.cfg.Concurrency)
+		}
+		res, err := c.Run(context.Background())
+		if err != nil {
+			t.Fatalf("Run() error: %v", err)
+		}
+		if res.Stats.Duration != 0 || res.Stats.P95Latency != 0 {
+			t.Errorf("Duration = %v, P95Latency = %v; want 0 on the fixed clock", res.Stats.Duration, res.Stats.P95Latency)
+		}
+		var urls []string
+		for _, link := range res.BrokenLinks {
+			urls = append(urls, link.URL)
+		}
+		return urls
+	}
+
+	want := []string{ts.URL + "/gone-a", ts.URL + "/gone-b", ts.URL + "/gone/m", ts.URL + "/gone/z"}
+	for range 3 {
+		if got := crawl(); !slices.Equal(got, want) {
+			t.Fatalf("broken links = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestSystemClockSleep(t *testing.T) {
+	if err := (systemClock{}).Sleep(context.Background(), time.Millisecond); err != nil {
+		t.Errorf("Sleep() error: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := (systemClock{}).Sleep(ctx, time.Hour); err != context.Canceled {
+		t.Errorf("Sleep() error = %v after cancellation, want context.Canceled", err)
+	}
+}
+
+func TestCheckURLWithRetry_SimulatedBackoff(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+
+	clock := newFakeClock()
+	cfg := Config{RequestTimeout: time.Second, Clock: clock}
+	policy := RetryPolicy{MaxRetries: 3, BaseDelay: time.Minute, MaxDelay: 3 * time.Minute}
+	res := CheckURLWithRetry(context.Background(), &http.Client{}, CrawlJob{URL: ts.URL, IsExternal: true}, cfg, policy)
+
+	if res.Attempts != 4 {
+		t.Errorf("Attempts = %d, want 4", res.Attempts)
+	}
+	want := []time.Duration{time.Minute, 2 * time.Minute, 3 * time.Minute}
+	if got := clock.sleeps(); !slices.Equal(got, want) {
+		t.Errorf("backoff sleeps = %v, want %v", got, want)
+	}
+}
+
+func TestAdaptiveLimiter_SimulatedClock(t *testing.T) {
+	clock := newFakeClock()
+	limiter := NewAdaptiveLimiter(10, 200*time.Millisecond)
+	limiter.SetFixedRate(1.0/60, 1) // One request a minute
+	limiter.SetClock(clock)
+
+	for range 3 {
+		if err := limiter.Wait(context.Background()); err != nil {
+			t.Fatalf("Wait() error: %v", err)
+		}
+	}
+	want := []time.Duration{time.Minute, time.Minute}
+	if got := clock.sleeps(); !slices.Equal(got, want) {
+		t.Errorf("limiter sleeps = %v, want %v", got, want)
+	}
+}
+
+func TestAdaptiveLimiter_SimulatedClockDeadline(t *testing.T) {
+	// The simulated clock runs an hour ahead, so only it shows the deadline
+	// as 30 seconds away
+	clock := &fakeClock{now: time.Now().Add(time.Hour)}
+	limiter := NewAdaptiveLimiter(10, 200*time.Millisecond)
+	limiter.SetFixedRate(1.0/60, 1)
+	limiter.SetClock(clock)
+	if err := limiter.Wait(context.Background()); err != nil {
+		t.Fatalf("first Wait() error: %v", err)
+	}
+
+	ctx, cancel := context.WithDeadline(context.Background(), clock.Now().Add(30*time.Second))
+	defer cancel()
+	if err := limiter.Wait(ctx); err == nil {
+		t.Error("Wait() = nil, want an error for a minute's wait past the simulated deadline")
+	}
+	if got := clock.sleeps(); len(got) != 0 {
+		t.Errorf("limiter sleeps = %v, want none", got)
+	}
+}
+
+func TestRobotsChecker_SimulatedCacheExpiry(t *testing.T) {
+	var fetches atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+		_, _ = fmt.Fprint(w, "User-agent: *\nDisallow: /private\n")
+	}))
+	defer ts.Close()
+
+	clock := newFakeClock()
+	checker := NewRobotsChecker(&http.Client{})
+	checker.SetClock(clock)
+	for _, advance := range []time.Duration{0, 30 * time.Minute, 31 * time.Minute} {
+		clock.advance(advance)
+		if _, err := checker.Allowed(context.Background(), ts.URL+"/page", "bot"); err != nil {
+			t.Fatalf("Allowed() error: %v", err)
+		}
+	}
+	if got := fetches.Load(); got != 2 {
+		t.Errorf("robots.txt fetched %d times, want 2 (once more after the hour TTL)", got)
+	}
+}
+
+func TestBandwidth_SimulatedClock(t *testing.T) {
+	clock := newFakeClock()
+	bandwidth := NewBandwidth(1000)
+	bandwidth.SetClock(clock)
+
+	body := bandwidth.reader(context.Background(), strings.NewReader(strings.Repeat("x", 3000)))
+	if n, err := io.Copy(io.Discard, body); err != nil || n != 3000 {
+		t.Fatalf("read %d bytes, error %v; want 3000", n, err)
+	}
+	var slept time.Duration
+	for _, d := range clock.sleeps() {
+		slept += d
+	}
+	if slept != 2*time.Second {
+		t.Errorf("slept %v on the clock, want 2s for 3000 bytes at 1000/s after a full burst", slept)
+	}
+}
+
+func TestFrontier_QueuedOnClock(t *testing.T) {
+	clock := newFakeClock()
+	f := newFrontier(StrategyBFS)
+	f.clock = clock
+	f.Push(CrawlJob{URL: "a"})
+	if job, _ := f.Peek(); !job.Queued.Equal(clock.Now()) {
+		t.Errorf("Queued = %v, want the clock's %v", job.Queued, clock.Now())
+	}
+}
+
+func TestVerify_SimulatedDelay(t *testing.T) {
+	clock := newFakeClock()
+	c, err := New(Config{StartURL: "http://example.com", Verify: VerifyPolicy{Enabled: true, Delay: time.Hour}, Clock: clock}, nil)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	defer func() { _ = c.visited.Close() }()
+
+	broken := []result.LinkResult{{URL: "not a url", ErrorCategory: result.CategoryMalformedURL}}
+	if stillBroken, _ := c.verify(context.Background(), broken); len(stillBroken) != 1 {
+		t.Errorf("still broken = %v, want the malformed link", stillBroken)
+	}
+	if got := clock.sleeps(); !slices.Equal(got, []time.Duration{time.Hour}) {
+		t.Errorf("sleeps = %v, want the hour's delay on the clock", got)
+	}
+}
+
+func TestMiddleware_SimulatedClock(t *testing.T) {
+	clock := newFakeClock()
+	ctx := Config{Clock: clock}.withClocks(context.Background())
+	var fetches int
+	fetcher := chainFetcher(FetcherFunc(func(context.Context, CrawlJob) CrawlResult {
+		fetches++
+		return CrawlResult{StatusCode: http.StatusOK}
+	}), []Middleware{Throttle(1.0/60, 1), CacheResults(time.Hour)})
+
+	job := CrawlJob{URL: "http://ext.example/", IsExternal: true}
+	for _, advance := range []time.Duration{0, 30 * time.Minute, 31 * time.Minute} {
+		clock.advance(advance)
+		fetcher.Fetch(ctx, job)
+	}
+	if fetches != 2 {
+		t.Errorf("fetched %d times, want 2 (once more after the hour TTL)", fetches)
+	}
+	// Simulated time refilled the throttle between checks
+	if got := clock.sleeps(); len(got) != 0 {
+		t.Errorf("throttle sleeps = %v, want none", got)
+	}
+}
+
+func TestRun_DeterministicHARAndLogTimings(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(5 * time.Millisecond)
+		if r.URL.Path == "/" {
+			_, _ = fmt.Fprint(w, `<a href="/a">a</a>`)
+		}
+	}))
+	defer ts.Close()
+
+	var logs bytes.Buffer
+	rec := NewHARRecorder()
+	cfg := DefaultConfig(ts.URL)
+	cfg.Delay = 1
+	cfg.Deterministic = true
+	cfg.HAR = rec
+	cfg.Middleware = []Middleware{LogFetches(slog.New(slog.NewTextHandler(&logs, nil)))}
+	c, err := New(cfg, nil)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	if _, err := c.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error: %v", err)
+	}
+
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	if len(rec.entries) == 0 {
+		t.Fatal("no HAR entries recorded")
+	}
+	for _, entry := range rec.entries {
+		if !entry.StartedDateTime.Equal(DeterministicEpoch) || entry.Time != 0 {
+			t.Errorf("%s: started %v, time %v; want the fixed clock's", entry.Request.URL, entry.StartedDateTime, entry.Time)
+		}
+	}
+	if got := strings.Count(logs.String(), "duration=0s"); got != 2 {
+		t.Errorf("logged %d zero durations, want 2:\n%s", got, logs.String())
+	}
+}
@@ -0,0 +1,83 @@
+package crawler
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/lukemcguire/zombiecrawl/result"
+)
+
+// Compare crawls cfg.StartURL once as each preset, one after the other, and
+// reports which URLs only one identity reached and which each was blocked
+// from by robots.txt - for example what Googlebot can discover versus a
+// browser.
+func Compare(ctx context.Context, cfg Config, a, b Preset) (*result.Comparison, error) {
+	reachedA, blockedA, err := crawlAs(ctx, cfg, a)
+	if err != nil {
+		return nil, err
+	}
+	reachedB, blockedB, err := crawlAs(ctx, cfg, b)
+	if err != nil {
+		return nil, err
+	}
+
+	cmp := &result.Comparison{
+		Site:     cfg.Scrub.String(cfg.StartURL),
+		A:        a.Name,
+		B:        b.Name,
+		BlockedA: blockedA,
+		BlockedB: blockedB,
+	}
+	for _, u := range reachedA {
+		if _, found := slices.BinarySearch(reachedB, u); found {
+			cmp.Shared++
+		} else {
+			cmp.OnlyA = append(cmp.OnlyA, u)
+		}
+	}
+	for _, u := range reachedB {
+		if _, found := slices.BinarySearch(reachedA, u); !found {
+			cmp.OnlyB = append(cmp.OnlyB, u)
+		}
+	}
+	return cmp, nil
+}
+
+// crawlAs runs a crawl with preset applied and returns the sorted URLs it
+// checked and the sorted URLs robots.txt kept it from.
+func crawlAs(ctx context.Context, cfg Config, preset Preset) (reached, blocked []string, err error) {
+	preset.Apply(&cfg)
+	// The comparison is built from events, so none may be dropped
+	cfg.LosslessEvents = true
+
+	progressCh := make(chan CrawlEvent, 100)
+	c, err := New(cfg, progressCh)
+	if err != nil {
+		return nil, nil, fmt.Errorf("crawl as %s: %w", preset.Name, err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for evt := range progressCh {
+			switch {
+			case evt.ErrorCategory == result.CategoryRobotsBlocked:
+				blocked = append(blocked, evt.URL)
+			case evt.Checked > 0:
+				reached = append(reached, evt.URL)
+			}
+		}
+	}()
+
+	_, runErr := c.Run(ctx)
+	close(progressCh)
+	<-done
+	if runErr != nil {
+		return nil, nil, fmt.Errorf("crawl as %s: %w", preset.Name, runErr)
+	}
+
+	slices.Sort(reached)
+	slices.Sort(blocked)
+	return slices.Compact(reached), slices.Compact(blocked), nil
+}
@@ -0,0 +1,53 @@
+package crawler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestCompare(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/robots.txt":
+			_, _ = fmt.Fprint(w, "User-agent: Googlebot\nDisallow: /members\n")
+		case "/":
+			links := `<a href="/about">about</a><a href="/members">members</a>`
+			// Pages served only to search crawlers (cloaking)
+			if strings.Contains(r.UserAgent(), "Googlebot") {
+				links += `<a href="/seo-landing">landing</a>`
+			}
+			_, _ = fmt.Fprint(w, links)
+		default:
+			_, _ = fmt.Fprint(w, `<p>page</p>`)
+		}
+	}))
+	defer ts.Close()
+
+	googlebot, _ := LookupPreset("googlebot")
+	browser, _ := LookupPreset("browser")
+	cmp, err := Compare(context.Background(), Config{StartURL: ts.URL, Delay: 1}, googlebot, browser)
+	if err != nil {
+		t.Fatalf("Compare() error: %v", err)
+	}
+
+	if cmp.A != "googlebot" || cmp.B != "browser" {
+		t.Errorf("names = %q, %q", cmp.A, cmp.B)
+	}
+	if cmp.Shared != 2 {
+		t.Errorf("Shared = %d, want 2 (home and /about)", cmp.Shared)
+	}
+	if !slices.Equal(cmp.OnlyA, []string{ts.URL + "/seo-landing"}) {
+		t.Errorf("OnlyA = %v, want the cloaked landing page", cmp.OnlyA)
+	}
+	if !slices.Equal(cmp.OnlyB, []string{ts.URL + "/members"}) {
+		t.Errorf("OnlyB = %v, want the robots-blocked page", cmp.OnlyB)
+	}
+	if !slices.Equal(cmp.BlockedA, []string{ts.URL + "/members"}) || len(cmp.BlockedB) != 0 {
+		t.Errorf("BlockedA = %v, BlockedB = %v", cmp.BlockedA, cmp.BlockedB)
+	}
+}
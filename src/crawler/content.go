@@ -0,0 +1,154 @@
+package crawler
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+
+	"github.com/lukemcguire/zombiecrawl/result"
+)
+
+// ContentCheck asserts something about the HTML of crawled pages, such as a
+// required analytics snippet or leftover "Lorem ipsum" placeholder text.
+// Pages failing a check are reported in Result.ContentChecks; they are not
+// broken links.
+//
+// A check looks for Text, or for a match of Regexp if it is set, in the raw
+// HTML of each page matching Pattern. The page fails if it is missing, or if
+// it is present when Absent is set.
+type ContentCheck struct {
+	Name    string         // Identifies the check in reports
+	Pattern string         // Pages checked, in StatusExpectation.Pattern syntax (empty = every crawled page)
+	Text    string         // Text to look for
+	Regexp  *regexp.Regexp // Expression to look for instead of Text
+	Absent  bool           // The page must not contain Text or match Regexp
+}
+
+// requirement describes what a passing page does, e.g.
+// `does not contain "Lorem ipsum"`.
+func (check ContentCheck) requirement() string {
+	switch {
+	case check.Regexp != nil && check.Absent:
+		return fmt.Sprintf("does not match /%s/", check.Regexp)
+	case check.Regexp != nil:
+		return fmt.Sprintf("matches /%s/", check.Regexp)
+	case check.Absent:
+		return fmt.Sprintf("does not contain %q", check.Text)
+	default:
+		return fmt.Sprintf("contains %q", check.Text)
+	}
+}
+
+// passes reports whether page satisfies the check.
+func (check ContentCheck) passes(page []byte) bool {
+	var found bool
+	if check.Regexp != nil {
+		found = check.Regexp.Match(page)
+	} else {
+		found = bytes.Contains(page, []byte(check.Text))
+	}
+	return found != check.Absent
+}
+
+// contentChecks returns the checks of cfg.ContentChecks that apply to job's
+// page, or nil if none do.
+func (cfg Config) contentChecks(job CrawlJob) []ContentCheck {
+	var checks []ContentCheck
+	for _, check := range cfg.ContentChecks {
+		if check.Pattern == "" || urlPatternMatch(check.Pattern, job) {
+			checks = append(checks, check)
+		}
+	}
+	return checks
+}
+
+// checkContent runs checks against page, returning a failure for each check
+// the page does not pass.
+func checkContent(checks []ContentCheck, pageURL string, page []byte) []result.ContentFailure {
+	var failures []result.ContentFailure
+	for _, check := range checks {
+		if !check.passes(page) {
+			// A failing page does the opposite of what the check requires
+			failed := check
+			failed.Absent = !check.Absent
+			failures = append(failures, result.ContentFailure{
+				Check:  check.Name,
+				URL:    pageURL,
+				Reason: failed.requirement(),
+			})
+		}
+	}
+	return failures
+}
+
+// contentCheckFile is the JSON form of content checks read by
+// LoadContentChecks.
+type contentCheckFile struct {
+	Checks []struct {
+		Name        string `json:"name"`
+		Pattern     string `json:"pattern"`
+		Contains    string `json:"contains"`
+		NotContains string `json:"not_contains"`
+		Matches     string `json:"matches"`
+		NotMatches  string `json:"not_matches"`
+	} `json:"checks"`
+}
+
+// LoadContentChecks reads content checks from a JSON file such as
+//
+//	{"checks": [{"name": "analytics", "contains": "gtag('config'"},
+//	            {"name": "placeholder text", "not_contains": "Lorem ipsum"},
+//	            {"pattern": "/blog/", "matches": "<time datetime=\"\\d{4}-"}]}
+//
+// Each check sets exactly one of contains, not_contains, matches (a Go
+// regular expression), or not_matches. Checks without a name are named after
+// their condition.
+func LoadContentChecks(r io.Reader) ([]ContentCheck, error) {
+	var file contentCheckFile
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&file); err != nil {
+		return nil, fmt.Errorf("read content checks: %w", err)
+	}
+
+	checks := make([]ContentCheck, 0, len(file.Checks))
+	for i, entry := range file.Checks {
+		check := ContentCheck{Name: entry.Name, Pattern: entry.Pattern}
+		var conditions int
+		for _, condition := range []struct {
+			value  string
+			regexp bool
+			absent bool
+		}{
+			{entry.Contains, false, false},
+			{entry.NotContains, false, true},
+			{entry.Matches, true, false},
+			{entry.NotMatches, true, true},
+		} {
+			if condition.value == "" {
+				continue
+			}
+			conditions++
+			check.Absent = condition.absent
+			if !condition.regexp {
+				check.Text = condition.value
+				continue
+			}
+			re, err := regexp.Compile(condition.value)
+			if err != nil {
+				return nil, fmt.Errorf("content check %d: %w", i+1, err)
+			}
+			check.Regexp = re
+		}
+		if conditions != 1 {
+			return nil, fmt.Errorf("content check %d: set exactly one of contains, not_contains, matches, or not_matches", i+1)
+		}
+		if check.Name == "" {
+			check.Name = check.requirement()
+		}
+		checks = append(checks, check)
+	}
+	return checks, nil
+}
@@ -0,0 +1,118 @@
+package crawler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/lukemcguire/zombiecrawl/result"
+)
+
+func TestLoadContentChecks(t *testing.T) {
+	input := `{"checks": [
+		{"name": "analytics", "contains": "gtag("},
+		{"not_contains": "Lorem ipsum"},
+		{"pattern": "/blog/", "matches": "<time datetime=\"\\d{4}-"},
+		{"name": "debug", "not_matches": "(?i)console\\.log"}
+	]}`
+	checks, err := LoadContentChecks(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("LoadContentChecks() error: %v", err)
+	}
+	if len(checks) != 4 {
+		t.Fatalf("got %d checks, want 4", len(checks))
+	}
+	if c := checks[0]; c.Name != "analytics" || c.Text != "gtag(" || c.Absent || c.Regexp != nil {
+		t.Errorf("contains check = %+v", c)
+	}
+	if c := checks[1]; c.Name != `does not contain "Lorem ipsum"` || c.Text != "Lorem ipsum" || !c.Absent {
+		t.Errorf("not_contains check = %+v, want it named after its condition", c)
+	}
+	if c := checks[2]; c.Pattern != "/blog/" || c.Regexp == nil || c.Absent || !c.Regexp.MatchString(`<time datetime="2024-01-01">`) {
+		t.Errorf("matches check = %+v", c)
+	}
+	if c := checks[3]; c.Regexp == nil || !c.Absent {
+		t.Errorf("not_matches check = %+v", c)
+	}
+}
+
+func TestLoadContentChecks_Errors(t *testing.T) {
+	for name, input := range map[string]string{
+		"not json":        `checks:`,
+		"unknown field":   `{"checks": [{"contains": "a", "text": "b"}]}`,
+		"no condition":    `{"checks": [{"name": "a"}]}`,
+		"two conditions":  `{"checks": [{"contains": "a", "not_matches": "b"}]}`,
+		"bad expression":  `{"checks": [{"matches": "(unclosed"}]}`,
+		"bad negated exp": `{"checks": [{"not_matches": "[z-a]"}]}`,
+	} {
+		if _, err := LoadContentChecks(strings.NewReader(input)); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestCheckContent(t *testing.T) {
+	page := []byte(`<html><head><script>gtag('config')</script></head><body>Lorem ipsum</body></html>`)
+	checks := []ContentCheck{
+		{Name: "analytics", Text: "gtag("},
+		{Name: "placeholder", Text: "Lorem ipsum", Absent: true},
+		{Name: "footer", Regexp: regexp.MustCompile(`<footer\b`)},
+		{Name: "no todo", Regexp: regexp.MustCompile(`(?i)todo`), Absent: true},
+	}
+	want := []result.ContentFailure{
+		{Check: "placeholder", URL: "https://example.com/", Reason: `contains "Lorem ipsum"`},
+		{Check: "footer", URL: "https://example.com/", Reason: `does not match /<footer\b/`},
+	}
+	if got := checkContent(checks, "https://example.com/", page); !slices.Equal(got, want) {
+		t.Errorf("checkContent() = %+v, want %+v", got, want)
+	}
+}
+
+func TestRun_ContentChecks(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/":
+			_, _ = fmt.Fprint(w, `<script>gtag()</script><a href="/about">about</a><a href="/blog/draft">draft</a>`)
+		case "/about":
+			_, _ = fmt.Fprint(w, `<p>About us</p>`)
+		case "/blog/draft":
+			_, _ = fmt.Fprint(w, `<script>gtag()</script><p>Lorem ipsum dolor</p>`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	cfg := DefaultConfig(ts.URL)
+	cfg.Delay = 1
+	cfg.ContentChecks = []ContentCheck{
+		{Name: "analytics", Text: "gtag("},
+		{Name: "placeholder", Pattern: "/blog/", Text: "Lorem ipsum", Absent: true},
+	}
+	c, err := New(cfg, nil)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	res, err := c.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run() error: %v", err)
+	}
+
+	if len(res.BrokenLinks) != 0 {
+		t.Errorf("content failures should not be broken links, got %+v", res.BrokenLinks)
+	}
+	got := slices.Clone(res.ContentChecks)
+	slices.SortFunc(got, func(a, b result.ContentFailure) int { return strings.Compare(a.URL, b.URL) })
+	want := []result.ContentFailure{
+		{Check: "analytics", URL: ts.URL + "/about", Reason: `does not contain "gtag("`},
+		{Check: "placeholder", URL: ts.URL + "/blog/draft", Reason: `contains "Lorem ipsum"`},
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("ContentChecks = %+v, want %+v", got, want)
+	}
+}
@@ -1,14 +1,18 @@
 // Package crawler provides a concurrent web crawler for discovering broken links.
-// It implements BFS crawling with robots.txt compliance, rate limiting, and
-// progress event streaming.
+// It implements BFS (or DFS/random) crawling with robots.txt compliance, rate
+// limiting, and progress event streaming.
 package crawler
 
 import (
 	"context"
 	"fmt"
+	"log/slog"
 	"net/http"
 	"net/url"
+	"slices"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"golang.org/x/sync/errgroup"
@@ -17,29 +21,72 @@ import (
 	"github.com/lukemcguire/zombiecrawl/urlutil"
 )
 
-// Crawler coordinates BFS link checking with a concurrent worker pool.
+// Crawler coordinates link checking with a concurrent worker pool. URLs are
+// visited breadth-first by default; see Config.Strategy.
 type Crawler struct {
 	cfg           Config
 	client        *http.Client
+	fetcher       Fetcher // Config.Middleware around fetch
 	limiter       *AdaptiveLimiter
 	robotsChecker *RobotsChecker
-	visited       *VisitedTracker
+	userAgents    *userAgentSelector
+	hostLimiters  hostLimiters // Rate limits of Config.HostConfigs, on top of limiter
+	hostSlots     *hostSlots   // Config.MaxPerHost requests in flight per host
+	visited       VisitedStore
+	stats         *statsCollector
 	results       []result.LinkResult
+	hygiene       []result.HygieneWarning
+	accessibility []result.AccessibilityIssue
+	httpPages     []CrawlJob             // Working http:// internal pages, probed over https by CheckHTTPS
+	schemePages   map[string]schemeForms // Internal pages served without a redirect, by URL without its scheme, with CheckHTTPS
+	dedupSeen     map[string]bool        // URLs a dedup rule changed the key of
+	dedupFolded   map[string]int         // URLs folded into another, by dedup rule
+	slashPages    []CrawlJob             // Internal pages that answered, probed with a trailing slash by CheckTrailingSlash
+	feeds         []CrawlJob             // Feeds advertised by internal pages, from the first page advertising each, fetched by CheckFeeds
+	truncated     []result.TruncatedPage
+	skipped       []result.SkippedLink
+	streaming     []result.StreamingResource
+	content       []result.ContentFailure
+	seoPages      []seoPage
+	graph         *linkGraph // Internal link counts, with Config.SiteStructure
+	pagination    paginationGraph
+	inFlight      *inFlightTracker
+	queued        atomic.Int64 // Jobs in the frontier, for Config.OnProgress
+	visitedCount  atomic.Int64 // URLs newly marked visited, for Config.State
 	mu            sync.Mutex
 	total         int
 	progressCh    chan<- CrawlEvent
+	events        *eventPublisher
 }
 
 // New creates a Crawler with the given configuration.
 // The progressCh parameter is optional; pass nil to disable progress events.
-// Returns an error if the visited tracker cannot be initialized.
+// Returns an error if the visited tracker cannot be initialized, the strategy
+// is unknown, a host user agent pattern is invalid, or RobotsTxt cannot be
+// parsed.
 func New(cfg Config, progressCh chan<- CrawlEvent) (*Crawler, error) {
 	if cfg.Concurrency <= 0 {
 		cfg.Concurrency = 10
 	}
+	if cfg.Deterministic {
+		cfg.Concurrency = 1
+		cfg.timings = fixedClock{Clock: clockOrSystem(cfg.Clock), at: DeterministicEpoch}
+	}
+	if cfg.RunID == "" {
+		cfg.RunID = NewRunID()
+		if cfg.Deterministic {
+			cfg.RunID = DeterministicRunID
+		}
+	}
+	if cfg.Logger != nil {
+		cfg.Logger = cfg.Logger.With("run_id", cfg.RunID)
+	}
 	if cfg.RequestTimeout <= 0 {
 		cfg.RequestTimeout = 10 * time.Second
 	}
+	if cfg.SlowRequest <= 0 {
+		cfg.SlowRequest = DefaultSlowRequest
+	}
 	if cfg.Delay <= 0 {
 		cfg.Delay = 100
 	}
@@ -49,61 +96,136 @@ func New(cfg Config, progressCh chan<- CrawlEvent) (*Crawler, error) {
 	if cfg.RetryPolicy.MaxRetries < 0 {
 		cfg.RetryPolicy = DefaultRetryPolicy()
 	}
+	strategy, err := ParseStrategy(string(cfg.Strategy))
+	if err != nil {
+		return nil, err
+	}
+	cfg.Strategy = strategy
+	ipVersion, err := ParseIPVersion(string(cfg.IPVersion))
+	if err != nil {
+		return nil, err
+	}
+	cfg.IPVersion = ipVersion
+	if cfg.DNSServer, err = ParseDNSServer(cfg.DNSServer); err != nil {
+		return nil, err
+	}
+
+	userAgents, err := newUserAgentSelector(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("configure user agents: %w", err)
+	}
+	hostLimiters, err := newHostLimiters(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("configure hosts: %w", err)
+	}
+	if err := validateBlockedHosts(cfg.BlockedHosts); err != nil {
+		return nil, fmt.Errorf("configure hosts: %w", err)
+	}
 
 	// Convert delay (ms) to rate: 100ms delay = 10 req/sec
 	initialRPS := 1000 / cfg.Delay
 	// Target RTT of 200ms for adaptive rate limiting
-	limiter := NewAdaptiveLimiter(initialRPS, 200*time.Millisecond)
-
-	// If DisableAutoTune is set, fix the rate (disable adaptation)
-	if cfg.DisableAutoTune {
+	limiter := NewAdaptiveLimiterWithBounds(initialRPS, 200*time.Millisecond, cfg.MinRate, cfg.MaxRate)
+
+	switch {
+	case cfg.RatePerMinute > 0:
+		// An explicit rate is exact and fixed, including rates below 1 RPS
+		limiter.SetFixedRate(cfg.RatePerMinute/60, cfg.Burst)
+	case cfg.DisableAutoTune:
+		// If DisableAutoTune is set, fix the rate (disable adaptation)
 		limiter.SetRate(initialRPS)
+		limiter.SetBurst(cfg.Burst)
+	default:
+		limiter.SetBurst(cfg.Burst)
 	}
 
+	if cfg.Clock != nil {
+		limiter.SetClock(cfg.Clock)
+		cfg.Bandwidth.SetClock(cfg.Clock)
+	}
+
+	if cfg.Logger != nil && cfg.Scrub != nil {
+		cfg.Logger = slog.New(cfg.Scrub.Handler(cfg.Logger.Handler()))
+	}
+
+	cfg.Transport = traceRequests(blockHosts(configureTransport(cfg.Transport, cfg), cfg.BlockedHosts), cfg)
+
 	// Separate client for robots.txt with shorter timeout
-	robotsClient := &http.Client{Timeout: 5 * time.Second}
+	robotsClient := &http.Client{Transport: cfg.HAR.wrap(cfg.Transport, cfg.timingClock()), Timeout: 5 * time.Second}
+
+	robotsChecker := NewRobotsCheckerWithCacheSize(robotsClient, cfg.RobotsCacheSize)
+	robotsChecker.SetStrict(cfg.StrictRobots)
+	if cfg.RobotsTxt != nil {
+		if err := setRobotsOverride(robotsChecker, cfg.StartURL, cfg.RobotsTxt); err != nil {
+			return nil, err
+		}
+	}
+	if cfg.Clock != nil {
+		robotsChecker.SetClock(cfg.Clock)
+	}
 
 	// Create disk-backed visited tracker for production-scale crawls
-	visited, err := NewVisitedTracker()
-	if err != nil {
-		return nil, fmt.Errorf("create visited tracker: %w", err)
+	visited := cfg.Visited
+	if visited == nil {
+		tracker, err := NewVisitedTracker()
+		if err != nil {
+			return nil, fmt.Errorf("create visited tracker: %w", err)
+		}
+		visited = tracker
 	}
 
-	return &Crawler{
+	c := &Crawler{
 		cfg:           cfg,
-		client:        &http.Client{},
+		client:        &http.Client{Transport: cfg.HAR.wrap(cfg.Transport, cfg.timingClock())},
 		limiter:       limiter,
-		robotsChecker: NewRobotsChecker(robotsClient),
+		robotsChecker: robotsChecker,
+		userAgents:    userAgents,
+		hostLimiters:  hostLimiters,
+		hostSlots:     newHostSlots(cfg.MaxPerHost),
 		visited:       visited,
+		stats:         newStatsCollector(),
+		inFlight:      newInFlightTracker(),
 		progressCh:    progressCh,
-	}, nil
+	}
+	// Middleware times and waits on the crawl's clocks
+	chain := chainFetcher(FetcherFunc(c.fetch), cfg.Middleware)
+	c.fetcher = FetcherFunc(func(ctx context.Context, job CrawlJob) CrawlResult {
+		return chain.Fetch(c.cfg.withClocks(ctx), job)
+	})
+	return c, nil
+}
+
+// fetch checks job with retries. It ends every Config.Middleware chain.
+func (c *Crawler) fetch(ctx context.Context, job CrawlJob) CrawlResult {
+	return CheckURLWithRetry(ctx, c.client, job, c.cfg, c.cfg.retryPolicy(job.URL))
 }
 
 // Run executes the crawl starting from cfg.StartURL and returns broken link results.
 func (c *Crawler) Run(ctx context.Context) (*result.Result, error) {
-	start := time.Now()
+	start := c.cfg.now()
 
 	// Defensive check: ensure crawler was constructed via New()
 	if c.visited == nil {
 		return nil, fmt.Errorf("crawler not properly initialized: visited tracker is nil")
 	}
 
+	// Forward progress events without letting a slow consumer stall the
+	// crawl; closed last so cleanup errors are still delivered.
+	c.events = newEventPublisher(c.progressCh, c.cfg.RunID, c.cfg.LosslessEvents, c.cfg.Scrub)
+	defer c.events.close()
+
 	// Ensure visited tracker is cleaned up on exit
-	defer func() {
-		if closeErr := c.visited.Close(); closeErr != nil {
-			// Report cleanup error via progress channel if available
-			if c.progressCh != nil {
-				c.progressCh <- CrawlEvent{
-					Error: fmt.Sprintf("visited tracker cleanup: %v", closeErr),
-				}
-			}
-		}
-	}()
+	defer c.closeVisited()
+
+	if c.cfg.SiteStructure {
+		c.graph = newLinkGraph()
+	}
 
 	startURL, err := urlutil.Normalize(c.cfg.StartURL)
 	if err != nil {
 		return nil, fmt.Errorf("normalize start URL: %w", err)
 	}
+	startURL = c.cfg.stripSessionIDs(startURL)
 
 	// Ensure root path consistency: "http://host" and "http://host/" must dedup.
 	if parsedURL, parseErr := url.Parse(startURL); parseErr == nil && parsedURL.Path == "" {
@@ -111,17 +233,48 @@ func (c *Crawler) Run(ctx context.Context) (*result.Result, error) {
 		startURL = parsedURL.String()
 	}
 
-	jobs := make(chan CrawlJob, c.cfg.Concurrency*3)
-	results := make(chan CrawlResult, c.cfg.Concurrency*3)
+	// Check robots.txt for start URL before seeding the first job.
+	// Errors are treated as allow-all (fail-open) but logged.
+	startUserAgent := c.userAgents.For(startURL)
+	allowed, robotsErr := c.robotsAllowed(ctx, startURL, startUserAgent)
+	if robotsErr != nil {
+		c.cfg.logger().Warn("robots.txt check failed; allowing", "url", startURL, "host", hostFromURL(startURL), "error", robotsErr)
+	}
+	if !allowed {
+		return nil, fmt.Errorf("start URL %s is disallowed: %w", startURL, result.ErrRobotsBlocked)
+	}
+	c.cfg.logger().Info("crawl started", "url", startURL, "host", hostFromURL(startURL), "concurrency", c.cfg.Concurrency)
 
-	var pendingJobs sync.WaitGroup
+	// Jobs are handed to workers one at a time from the frontier, so a job is
+	// either queued in the frontier or in flight - never stranded in a buffer.
+	jobs := make(chan CrawlJob)
+	results := make(chan CrawlResult, c.cfg.Concurrency*3)
 
 	// Mark start URL as visited before enqueueing.
-	c.visited.Visit(startURL)
+	c.visitIfNew(startURL, hostFromURL(startURL))
 
 	// Use errgroup for structured goroutine management
 	errGroup, groupCtx := errgroup.WithContext(ctx)
 
+	// Report slow checks and cancel hung ones while the crawl runs
+	if c.events != nil || c.cfg.HardTimeout > 0 {
+		watchCtx, stopWatching := context.WithCancel(groupCtx)
+		watching := make(chan struct{})
+		go func() {
+			defer close(watching)
+			c.watchInFlight(watchCtx)
+		}()
+		// Stop before the event publisher closes
+		defer func() {
+			stopWatching()
+			<-watching
+		}()
+	}
+
+	// Report progress until Run returns, then once more with the totals
+	stopProgress := c.watchProgress(start)
+	defer stopProgress()
+
 	// Launch workers with errgroup
 	for range c.cfg.Concurrency {
 		errGroup.Go(func() error {
@@ -131,150 +284,118 @@ func (c *Crawler) Run(ctx context.Context) (*result.Result, error) {
 					if !ok {
 						return nil
 					}
+					// External links that passed recently need no request at all
+					if job.IsExternal && c.cfg.ExternalCache.fresh(job.URL) {
+						results <- CrawlResult{Job: job, Cached: true}
+						continue
+					}
+					// Waits before the request end with the crawl, or once the
+					// job has been queued longer than QueueTimeout
+					waitCtx, cancelWait := queueContext(groupCtx, job, c.cfg.QueueTimeout, c.cfg.clock())
 					// Wait for rate limiter before making request
-					if waitErr := c.limiter.Wait(groupCtx); waitErr != nil {
-						// Context cancelled while waiting - must still send result to unblock coordinator
+					waitErr := c.limiter.Wait(waitCtx)
+					if waitErr == nil {
+						waitErr = c.hostLimiters.wait(waitCtx, c.cfg, job.URL)
+					}
+					host := hostFromURL(job.URL)
+					if waitErr != nil {
+						waitErr = fmt.Errorf("rate limiter wait: %w", waitErr)
+					} else if acquireErr := c.hostSlots.acquire(waitCtx, host); acquireErr != nil {
+						waitErr = fmt.Errorf("host slot acquire: %w", acquireErr)
+					} else if acquireErr := c.cfg.Pool.acquire(waitCtx); acquireErr != nil {
+						// Waited for a shared request slot when crawling several sites at once
+						c.hostSlots.release(host)
+						waitErr = fmt.Errorf("worker pool acquire: %w", acquireErr)
+					}
+					cancelWait()
+					if waitErr != nil {
+						if groupCtx.Err() == nil {
+							results <- queuedTooLong(job, c.cfg)
+							continue
+						}
+						// Context cancelled while waiting - must still send result to unblock coordinator.
+						// Cancelling is not a failure: Run still returns what was checked.
 						results <- CrawlResult{Job: job}
-						return fmt.Errorf("rate limiter wait: %w", waitErr)
+						return nil
 					}
 					// Track RTT for adaptive rate limiting
-					reqStart := time.Now()
-					crawlResult := CheckURLWithRetry(groupCtx, c.client, job, c.cfg, c.cfg.RetryPolicy)
-					rtt := time.Since(reqStart)
+					reqStart := c.cfg.clock().Now()
+					c.stats.begin()
+					crawlResult := c.check(groupCtx, job)
+					c.stats.end()
+					c.cfg.Pool.release()
+					c.hostSlots.release(host)
+					if groupCtx.Err() == nil {
+						c.cfg.ExternalCache.store(crawlResult)
+					}
+					rtt := c.cfg.clock().Now().Sub(reqStart)
 					// Observe RTT for adaptive rate adjustment
 					c.limiter.ObserveRTT(rtt)
-					// Always send result - coordinator must receive it to call pendingJobs.Done()
+					// Throttling responses back off immediately, however fast they arrive
+					c.limiter.ObserveStatus(crawlResult.StatusCode)
+					// Always send result - coordinator counts it against inFlight
 					results <- crawlResult
 				case <-groupCtx.Done():
-					// Drain remaining jobs to ensure pendingJobs.Done() is called for each
-					// Use non-blocking receive to avoid blocking forever
-					for {
-						select {
-						case job, ok := <-jobs:
-							if !ok {
-								return nil
-							}
-							// Send synthetic result for unprocessed job
-							results <- CrawlResult{Job: job}
-						default:
-							// No more jobs to drain
-							return nil
-						}
-					}
+					return nil
 				}
 			}
 		})
 	}
 
-	// Check robots.txt for start URL before seeding the first job.
-	// Errors are treated as allow-all (fail-open) but we surface them via progress channel.
-	allowed, robotsErr := c.robotsChecker.Allowed(ctx, startURL, c.cfg.UserAgent)
-	if robotsErr != nil && c.progressCh != nil {
-		c.progressCh <- CrawlEvent{
-			URL:        startURL,
-			Error:      fmt.Sprintf("robots.txt check: %v", robotsErr),
-			IsExternal: false,
-		}
+	// Seed the first job.
+	queue := newFrontier(c.cfg.Strategy)
+	queue.clock = c.cfg.Clock
+	if c.cfg.Deterministic {
+		queue.seed(1)
 	}
-	if !allowed {
-		return nil, fmt.Errorf("start URL %s is disallowed by robots.txt", startURL)
+	queue.Push(CrawlJob{URL: startURL, SourcePage: "", IsExternal: false, Depth: 0, UserAgent: startUserAgent})
+	if c.cfg.Sitemap {
+		c.seedFromSitemaps(groupCtx, startURL, queue)
 	}
 
-	// Seed the first job.
-	pendingJobs.Add(1)
-	jobs <- CrawlJob{URL: startURL, SourcePage: "", IsExternal: false, Depth: 0}
-
-	// Close results channel when all work is done (managed via errgroup)
-	errGroup.Go(func() error {
-		pendingJobs.Wait()
-		close(results)
-		return nil
-	})
-
-	// Coordinator: read results, enqueue discovered links.
-	// Process all results until channel closes - workers always send results
-	// so we don't need special cancellation handling here.
-	for crawlResult := range results {
-		c.mu.Lock()
-		c.total++
-		c.mu.Unlock()
-
-		if crawlResult.Result != nil {
-			c.mu.Lock()
-			c.results = append(c.results, *crawlResult.Result)
-			c.mu.Unlock()
-		}
-
-		if c.progressCh != nil {
-			evt := CrawlEvent{
-				URL:        crawlResult.Job.URL,
-				IsExternal: crawlResult.Job.IsExternal,
-				Checked:    c.total,
+	// Coordinator: dispatch queued jobs to idle workers and process results,
+	// until nothing is queued or in flight. Workers always send a result for
+	// every job they receive, so inFlight always drains back to zero.
+	inFlight := 0
+	cancelled := groupCtx.Done()
+	for queue.Len() > 0 || inFlight > 0 {
+		c.queued.Store(int64(queue.Len()))
+		if groupCtx.Err() != nil {
+			// Cancelled: stop dispatching and only wait for in-flight results
+			if cancelled != nil {
+				c.recordState(startURL, queue)
 			}
-			if crawlResult.Result != nil {
-				evt.StatusCode = crawlResult.Result.StatusCode
-				evt.Error = crawlResult.Result.Error
-				c.mu.Lock()
-				evt.Broken = len(c.results)
-				c.mu.Unlock()
-			} else if crawlResult.Err != nil {
-				evt.Error = crawlResult.Err.Error()
+			queue.Clear()
+			cancelled = nil
+			if inFlight == 0 {
+				break
 			}
-			c.progressCh <- evt
 		}
 
-		// Enqueue discovered links from internal pages (skip if context cancelled)
-		if !crawlResult.Job.IsExternal && ctx.Err() == nil {
-			startHost := hostFromURL(startURL)
-			nextDepth := crawlResult.Job.Depth + 1
-			for _, link := range crawlResult.Links {
-				normalized, normErr := urlutil.Normalize(link)
-				if normErr != nil {
-					// Surface normalization errors via progress channel
-					if c.progressCh != nil {
-						c.progressCh <- CrawlEvent{
-							URL:        link,
-							Error:      fmt.Sprintf("normalize URL: %v", normErr),
-							IsExternal: false,
-						}
-					}
-					continue
-				}
-				if !c.visited.VisitIfNew(normalized) {
-					continue
-				}
-				isExternal := !urlutil.IsSameDomain(normalized, startHost)
-				// Depth limit applies only to same-domain pages; external links are validated regardless
-				if !isExternal && c.cfg.MaxDepth > 0 && nextDepth > c.cfg.MaxDepth {
-					continue
-				}
-				// Check robots.txt before enqueueing.
-				// Errors are treated as allow-all (fail-open) but we surface them via progress channel.
-				allowed, robotsErr := c.robotsChecker.Allowed(ctx, normalized, c.cfg.UserAgent)
-				if robotsErr != nil && c.progressCh != nil {
-					c.progressCh <- CrawlEvent{
-						URL:        normalized,
-						Error:      fmt.Sprintf("robots.txt check: %v", robotsErr),
-						IsExternal: false,
-					}
-				}
-				if !allowed {
-					continue // Skip disallowed URLs
-				}
-				pendingJobs.Add(1)
-				jobs <- CrawlJob{
-					URL:        normalized,
-					SourcePage: crawlResult.Job.URL,
-					IsExternal: isExternal,
-					Depth:      nextDepth,
-				}
-			}
+		var dispatch chan CrawlJob
+		next, hasNext := queue.Peek()
+		if hasNext {
+			dispatch = jobs
 		}
 
-		pendingJobs.Done()
+		select {
+		case dispatch <- next:
+			queue.Pop()
+			inFlight++
+		case crawlResult := <-results:
+			inFlight--
+			c.handleResult(groupCtx, startURL, crawlResult, queue)
+		case <-cancelled:
+			// Loop around to clear the queue
+		}
 	}
 
+	if cancelled != nil {
+		// Finished without cancellation: the queue is empty
+		c.recordState(startURL, queue)
+	}
 	close(jobs)
+	c.queued.Store(0)
 
 	// Wait for all goroutines to complete
 	if waitErr := errGroup.Wait(); waitErr != nil {
@@ -284,17 +405,414 @@ func (c *Crawler) Run(ctx context.Context) (*result.Result, error) {
 	c.mu.Lock()
 	brokenLinks := make([]result.LinkResult, len(c.results))
 	copy(brokenLinks, c.results)
+	hygiene := slices.Clone(c.hygiene)
+	accessibility := slices.Clone(c.accessibility)
+	truncated := slices.Clone(c.truncated)
+	skipped := slices.Clone(c.skipped)
+	streaming := slices.Clone(c.streaming)
+	content := slices.Clone(c.content)
+	seoPages := slices.Clone(c.seoPages)
 	totalChecked := c.total
 	c.mu.Unlock()
 
-	return &result.Result{
-		BrokenLinks: brokenLinks,
-		Stats: result.CrawlStats{
-			TotalChecked: totalChecked,
-			BrokenCount:  len(brokenLinks),
-			Duration:     time.Since(start),
-		},
-	}, nil
+	// Re-check broken links so transient failures are reported as flaky
+	var flakyLinks []result.LinkResult
+	if c.cfg.Verify.Enabled && len(brokenLinks) > 0 && ctx.Err() == nil {
+		brokenLinks, flakyLinks = c.verify(ctx, brokenLinks)
+	}
+	if c.cfg.Verify.Enabled {
+		for _, link := range brokenLinks {
+			c.confirmBroken(link)
+		}
+	}
+
+	// Declared checks are not re-verified: verification only repeats GETs
+	if len(c.cfg.SyntheticChecks) > 0 && ctx.Err() == nil {
+		brokenLinks = append(brokenLinks, c.runSyntheticChecks(ctx, hostFromURL(startURL))...)
+		c.mu.Lock()
+		totalChecked = c.total
+		c.mu.Unlock()
+	}
+
+	// Suggest archived copies for dead external links
+	c.cfg.Archive.suggest(ctx, brokenLinks, c.cfg.logger())
+
+	// Flag pages crawled over both schemes, and probe the other http pages
+	// over https
+	if c.cfg.CheckHTTPS && ctx.Err() == nil {
+		duplicates := c.schemeDuplicates()
+		probe := slices.DeleteFunc(slices.Clone(c.httpPages), func(page CrawlJob) bool {
+			return slices.ContainsFunc(duplicates, func(warning result.HygieneWarning) bool { return warning.URL == page.URL })
+		})
+		hygiene = append(hygiene, duplicates...)
+		hygiene = append(hygiene, c.checkHTTPS(ctx, probe)...)
+	}
+
+	// Flag pages whose trailing slash form behaves differently
+	if c.cfg.CheckTrailingSlash && ctx.Err() == nil {
+		hygiene = append(hygiene, c.checkTrailingSlash(ctx, c.slashPages)...)
+	}
+
+	// Fetch advertised feeds and check their entries
+	var feeds []result.Feed
+	if c.cfg.CheckFeeds && ctx.Err() == nil {
+		feeds = c.checkFeeds(ctx, c.feeds, hostFromURL(startURL))
+		c.mu.Lock()
+		totalChecked = c.total
+		c.mu.Unlock()
+	}
+
+	var seoIssues []result.SEOIssue
+	if c.cfg.SEO {
+		seoIssues = seoReport(seoPages)
+		if ctx.Err() == nil {
+			seoIssues = append(seoIssues, c.checkHreflang(ctx, seoPages)...)
+			seoIssues = append(seoIssues, c.checkSocial(ctx, seoPages, hostFromURL(startURL))...)
+		}
+	}
+
+	stats := result.CrawlStats{
+		TotalChecked: totalChecked,
+		BrokenCount:  len(brokenLinks),
+		FlakyCount:   len(flakyLinks),
+		Duration:     c.cfg.since(start),
+	}
+	c.stats.fill(&stats)
+	stats.PaginationChains, stats.PaginatedPages = c.pagination.counts()
+	stats.DedupFolded = c.dedupStats()
+	c.cfg.logger().Info("crawl finished", "url", startURL, "checked", stats.TotalChecked, "broken", stats.BrokenCount, "duration", stats.Duration)
+	stats.RobotsCacheHits, stats.RobotsCacheMisses = c.robotsChecker.CacheStats()
+	stats.EventsDelivered, stats.EventsDropped = c.events.counts()
+
+	res := &result.Result{
+		RunID:         c.cfg.RunID,
+		BrokenLinks:   brokenLinks,
+		Flaky:         flakyLinks,
+		Stats:         stats,
+		Hosts:         c.stats.hostSummaries(),
+		Hygiene:       hygiene,
+		Accessibility: accessibility,
+		Structure:     c.graph.report(c.cfg.MaxOutboundLinks),
+		SEO:           seoIssues,
+		Feeds:         feeds,
+		ContentChecks: content,
+		Truncated:     truncated,
+		Skipped:       skipped,
+		Streaming:     streaming,
+	}
+	c.cfg.Scrub.Result(res)
+	// Plugins get the result even when the crawl was interrupted
+	c.pluginsFinish(context.WithoutCancel(ctx), res)
+	stopProgress()
+	c.reportProgress(Progress{
+		Checked: stats.TotalChecked,
+		Broken:  stats.BrokenCount,
+		Elapsed: stats.Duration,
+		Done:    true,
+	})
+	return res, nil
+}
+
+// closeVisited closes the visited tracker the crawler created, logging
+// cleanup errors. Stores supplied in
+// Config.Visited belong to the caller and stay open.
+func (c *Crawler) closeVisited() {
+	if c.cfg.Visited != nil {
+		return
+	}
+	if closeErr := c.visited.Close(); closeErr != nil {
+		c.cfg.logger().Warn("visited tracker cleanup failed", "error", closeErr)
+	}
+}
+
+// recordCheck counts a request made after the crawl, such as a synthetic
+// check, like a crawled link and publishes its progress event.
+func (c *Crawler) recordCheck(res CrawlResult) {
+	c.mu.Lock()
+	c.total++
+	checked := c.total
+	c.mu.Unlock()
+	c.stats.record(res)
+	logResult(c.cfg.logger(), res)
+	evt := CrawlEvent{URL: res.Job.URL, IsExternal: res.Job.IsExternal, Checked: checked, StatusCode: res.StatusCode}
+	if res.Result != nil {
+		evt.Error = res.Result.Error
+	}
+	c.events.publish(evt)
+}
+
+// handleResult records a worker result, publishes its progress event, and
+// queues the links it discovered.
+func (c *Crawler) handleResult(ctx context.Context, startURL string, crawlResult CrawlResult, queue *frontier) {
+	c.mu.Lock()
+	c.total++
+	c.mu.Unlock()
+	c.stats.record(crawlResult)
+	logResult(c.cfg.logger(), crawlResult)
+
+	if len(crawlResult.Warnings) > 0 || len(crawlResult.Accessibility) > 0 || len(crawlResult.Content) > 0 {
+		c.mu.Lock()
+		c.hygiene = append(c.hygiene, crawlResult.Warnings...)
+		c.accessibility = append(c.accessibility, crawlResult.Accessibility...)
+		c.content = append(c.content, crawlResult.Content...)
+		c.mu.Unlock()
+	}
+	if crawlResult.Streaming != "" {
+		c.recordStreaming(crawlResult)
+	}
+	if c.cfg.CheckHTTPS && crawlResult.Result == nil && !crawlResult.Job.IsExternal &&
+		crawlResult.Err == nil && !crawlResult.Cached {
+		if strings.HasPrefix(crawlResult.Job.URL, "http://") {
+			c.httpPages = append(c.httpPages, crawlResult.Job)
+		}
+		if !crawlResult.Redirected && crawlResult.StatusCode >= 200 && crawlResult.StatusCode < 300 {
+			c.recordScheme(crawlResult.Job)
+		}
+	}
+	if c.cfg.CheckTrailingSlash && !crawlResult.Job.IsExternal && !crawlResult.Cached &&
+		(crawlResult.Result == nil && crawlResult.Err == nil || crawlResult.StatusCode != 0) &&
+		probesTrailingSlash(crawlResult.Job.URL) {
+		c.slashPages = append(c.slashPages, crawlResult.Job)
+	}
+	var title string
+	if crawlResult.Meta != nil {
+		title = crawlResult.Meta.Title
+		if c.cfg.CheckFeeds && !crawlResult.Job.IsExternal {
+			for _, feed := range crawlResult.Meta.Feeds {
+				if !slices.ContainsFunc(c.feeds, func(job CrawlJob) bool { return job.URL == feed }) {
+					c.feeds = append(c.feeds, CrawlJob{URL: feed, SourcePage: crawlResult.Job.URL, UserAgent: c.userAgents.For(feed)})
+				}
+			}
+		}
+		if c.cfg.SEO && !crawlResult.Job.IsExternal {
+			c.mu.Lock()
+			c.seoPages = append(c.seoPages, seoPage{url: crawlResult.Job.URL, meta: *crawlResult.Meta})
+			c.mu.Unlock()
+		}
+	}
+	broken := slices.Clone(crawlResult.Malformed)
+	if crawlResult.Result != nil {
+		broken = append([]result.LinkResult{*crawlResult.Result}, broken...)
+	}
+	for i, link := range broken {
+		switch link.SourcePage {
+		case crawlResult.Job.SourcePage:
+			link.SourceTitle = crawlResult.Job.SourceTitle
+		case crawlResult.Job.URL:
+			link.SourceTitle = title
+		}
+		broken[i] = link
+		c.mu.Lock()
+		c.results = append(c.results, link)
+		c.mu.Unlock()
+		if c.cfg.Results != nil {
+			if sinkErr := c.cfg.Results.Add(c.cfg.Scrub.Link(link)); sinkErr != nil {
+				c.cfg.logger().Error("result sink failed", "url", link.URL, "host", hostFromURL(link.URL), "error", sinkErr)
+			}
+		}
+		if !c.cfg.Verify.Enabled {
+			c.confirmBroken(link)
+		}
+	}
+
+	evt := CrawlEvent{
+		URL:           crawlResult.Job.URL,
+		StatusCode:    crawlResult.StatusCode,
+		IsExternal:    crawlResult.Job.IsExternal,
+		Checked:       c.total,
+		Depth:         crawlResult.Job.Depth,
+		Attempt:       crawlResult.Attempts,
+		ContentLength: crawlResult.Bytes,
+		Duration:      crawlResult.Duration,
+		BrokenLinks:   broken,
+	}
+	c.mu.Lock()
+	evt.Broken = len(c.results)
+	c.mu.Unlock()
+	if crawlResult.Result != nil {
+		evt.StatusCode = crawlResult.Result.StatusCode
+		evt.Error = crawlResult.Result.Error
+		evt.ErrorCategory = crawlResult.Result.ErrorCategory
+	} else if crawlResult.Err != nil {
+		evt.Error = crawlResult.Err.Error()
+		evt.ErrorCategory = result.ClassifyError(crawlResult.Err, crawlResult.StatusCode, false)
+	}
+	c.events.publish(evt)
+
+	// Enqueue discovered links from internal pages (skip if context cancelled)
+	if crawlResult.Job.IsExternal || ctx.Err() != nil {
+		return
+	}
+	startHost := hostFromURL(startURL)
+	links := crawlResult.Links
+	if crawlResult.Result == nil && crawlResult.Err == nil && !crawlResult.Cached {
+		links = c.pluginLinks(ctx, crawlResult.Job.URL, links)
+		c.graph.recordPage(crawlResult.Job, links, startHost)
+	}
+	nextDepth := crawlResult.Job.Depth + 1
+	var next, prev string
+	if crawlResult.Meta != nil {
+		next, prev = crawlResult.Meta.Next, crawlResult.Meta.Prev
+		c.pagination.record(crawlResult.Job.URL, next, prev)
+	}
+	if c.cfg.Deterministic {
+		links = slices.Sorted(slices.Values(links))
+	}
+	queued := 0
+	for i, link := range links {
+		if c.cfg.MaxLinksPerPage > 0 && queued == c.cfg.MaxLinksPerPage {
+			// Leave the rest unvisited so other pages can still queue them
+			c.truncate(crawlResult.Job, len(links), queued, len(links)-i)
+			break
+		}
+		normalized, normErr := urlutil.Normalize(link)
+		if normErr != nil {
+			c.cfg.logger().Warn("skipping link that cannot be normalized", "url", link, "source_page", crawlResult.Job.URL, "error", normErr)
+			continue
+		}
+		if !c.visitIfNew(normalized, startHost) {
+			continue
+		}
+		class := c.cfg.classify(normalized, crawlResult.Job.URL, startHost)
+		if class == LinkSkip {
+			c.cfg.logger().Info("skipping link by classifier", "url", normalized, "host", hostFromURL(normalized), "source_page", crawlResult.Job.URL)
+			c.recordSkipped(crawlResult.Job.URL, normalized, skippedByClassifier)
+			continue
+		}
+		isExternal := class == LinkExternal
+		if pattern := blockedHost(c.cfg.BlockedHosts, normalized); pattern != "" {
+			c.skip(crawlResult.Job.URL, normalized, pattern, isExternal)
+			continue
+		}
+		// Pages of a paginated archive share a depth, so MaxDepth cannot cut
+		// the archive short
+		depth := nextDepth
+		if normalized == next || normalized == prev {
+			depth = crawlResult.Job.Depth
+		}
+		// Depth limit applies only to same-domain pages; external links are validated regardless
+		if !isExternal && c.cfg.MaxDepth > 0 && depth > c.cfg.MaxDepth {
+			continue
+		}
+		// Check robots.txt before enqueueing.
+		// Errors are treated as allow-all (fail-open) but logged.
+		userAgent := c.userAgents.For(normalized)
+		allowed := true
+		if c.checksRobots(normalized, isExternal) {
+			var robotsErr error
+			allowed, robotsErr = c.robotsAllowed(ctx, normalized, userAgent)
+			if robotsErr != nil {
+				c.cfg.logger().Warn("robots.txt check failed; allowing", "url", normalized, "host", hostFromURL(normalized), "depth", depth, "error", robotsErr)
+			}
+		}
+		if !allowed {
+			// Skip disallowed URLs, but let subscribers know why
+			c.cfg.logger().Info("skipping link disallowed by robots.txt", "url", normalized, "host", hostFromURL(normalized), "depth", depth, "category", result.CategoryRobotsBlocked)
+			c.events.publish(CrawlEvent{
+				URL:           normalized,
+				Error:         result.ErrRobotsBlocked.Error(),
+				ErrorCategory: result.CategoryRobotsBlocked,
+				IsExternal:    isExternal,
+			})
+			continue
+		}
+		job := CrawlJob{
+			URL:         normalized,
+			SourcePage:  crawlResult.Job.URL,
+			SourceTitle: title,
+			IsExternal:  isExternal,
+			Depth:       depth,
+			UserAgent:   userAgent,
+			Priority:    class == LinkPriority,
+		}
+		if !c.pluginsEnqueue(ctx, job) {
+			continue
+		}
+		queue.Push(job)
+		queued++
+	}
+}
+
+// truncate records that job's page had links left over when it used up
+// Config.MaxLinksPerPage.
+func (c *Crawler) truncate(job CrawlJob, links, queued, skipped int) {
+	jobLogger(c.cfg.logger(), job).Warn("page exceeded the link budget; skipping the rest of its links",
+		"links", links, "queued", queued, "skipped", skipped)
+	c.mu.Lock()
+	c.truncated = append(c.truncated, result.TruncatedPage{URL: job.URL, Links: links, Queued: queued})
+	c.mu.Unlock()
+}
+
+// recordStreaming records that the response to crawlResult's job streamed
+// and was read only in part.
+func (c *Crawler) recordStreaming(crawlResult CrawlResult) {
+	jobLogger(c.cfg.logger(), crawlResult.Job).Info("response is a stream; read in part",
+		"reason", crawlResult.Streaming, "bytes", crawlResult.Bytes)
+	c.mu.Lock()
+	c.streaming = append(c.streaming, result.StreamingResource{
+		URL:        crawlResult.Job.URL,
+		SourcePage: crawlResult.Job.SourcePage,
+		Reason:     crawlResult.Streaming,
+		Bytes:      crawlResult.Bytes,
+	})
+	c.mu.Unlock()
+}
+
+// skip records that a link found on sourcePage is never requested because
+// its host matches the blocked host pattern.
+func (c *Crawler) skip(sourcePage, rawURL, pattern string, isExternal bool) {
+	reason := fmt.Sprintf("host matches blocked pattern %q", pattern)
+	c.cfg.logger().Info("skipping link to blocked host", "url", rawURL, "host", hostFromURL(rawURL), "source_page", sourcePage, "pattern", pattern, "category", result.CategoryBlockedHost)
+	c.events.publish(CrawlEvent{
+		URL:           rawURL,
+		Error:         result.ErrBlockedHost.Error(),
+		ErrorCategory: result.CategoryBlockedHost,
+		IsExternal:    isExternal,
+	})
+	c.recordSkipped(sourcePage, rawURL, reason)
+}
+
+// confirmBroken passes a broken link that will be reported to
+// Config.OnBrokenLink.
+func (c *Crawler) confirmBroken(link result.LinkResult) {
+	if c.cfg.OnBrokenLink != nil {
+		c.cfg.OnBrokenLink(c.cfg.Scrub.Link(link))
+	}
+}
+
+// recordSkipped adds a link found on sourcePage that is never requested to
+// Result.Skipped.
+func (c *Crawler) recordSkipped(sourcePage, rawURL, reason string) {
+	c.mu.Lock()
+	c.skipped = append(c.skipped, result.SkippedLink{URL: rawURL, SourcePage: sourcePage, Reason: reason})
+	c.mu.Unlock()
+}
+
+// checksRobots reports whether robots.txt is consulted for a link. A host
+// config's Robots setting decides for its hosts. Otherwise internal links
+// are unless Config.IgnoreRobots is set; external links only with
+// Config.RespectExternalRobots.
+func (c *Crawler) checksRobots(rawURL string, isExternal bool) bool {
+	if hc := c.cfg.hostConfig(rawURL); hc != nil && hc.Robots != RobotsInherit {
+		return hc.Robots == RobotsRespect
+	}
+	if c.cfg.IgnoreRobots {
+		return false
+	}
+	return !isExternal || c.cfg.RespectExternalRobots
+}
+
+// robotsAllowed checks rawURL against robots.txt as Config.RobotsAgent, or as
+// userAgent if no robots agent is configured. URLs for which robots.txt is
+// ignored, by Config.IgnoreRobots or a host config, are always allowed.
+func (c *Crawler) robotsAllowed(ctx context.Context, rawURL, userAgent string) (bool, error) {
+	if !c.checksRobots(rawURL, false) {
+		return true, nil
+	}
+	if c.cfg.RobotsAgent != "" {
+		userAgent = c.cfg.RobotsAgent
+	}
+	return c.robotsChecker.Allowed(ctx, rawURL, userAgent)
 }
 
 // hostFromURL extracts the hostname (without port) from a URL string.
@@ -307,6 +825,20 @@ func hostFromURL(rawURL string) string {
 	return parsedURL.Hostname()
 }
 
+// setRobotsOverride makes robotsChecker use body as the robots.txt of the
+// start URL's host.
+func setRobotsOverride(robotsChecker *RobotsChecker, startURL string, body []byte) error {
+	normalized, err := urlutil.Normalize(startURL)
+	if err != nil {
+		return fmt.Errorf("normalize start URL: %w", err)
+	}
+	parsedURL, err := url.Parse(normalized)
+	if err != nil {
+		return fmt.Errorf("parse start URL: %w", err)
+	}
+	return robotsChecker.SetOverride(parsedURL.Host, body)
+}
+
 // GetConfig returns a copy of the crawler's configuration.
 // This is primarily useful for testing and debugging.
 func (c *Crawler) GetConfig() Config {
@@ -314,3 +846,19 @@ func (c *Crawler) GetConfig() Config {
 	defer c.mu.Unlock()
 	return c.cfg
 }
+
+// SetRate fixes the request rate at rps requests per second while the crawl
+// runs, disabling auto-tuning. Non-positive rates are ignored.
+func (c *Crawler) SetRate(rps float64) {
+	c.limiter.SetFixedRate(rps, c.cfg.Burst)
+}
+
+// Rate returns the current request rate in requests per second.
+func (c *Crawler) Rate() float64 {
+	return c.limiter.CurrentRPS()
+}
+
+// RunID returns the crawl's Config.RunID, as set by New.
+func (c *Crawler) RunID() string {
+	return c.cfg.RunID
+}
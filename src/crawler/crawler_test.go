@@ -1,16 +1,22 @@
 package crawler_test
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
+	"slices"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/lukemcguire/zombiecrawl/crawler"
+	"github.com/lukemcguire/zombiecrawl/result"
 )
 
 // newTestServer creates an httptest server with a multi-page site for integration testing.
@@ -106,6 +112,17 @@ func TestCrawlerIntegration(t *testing.T) {
 	if result.Stats.TotalChecked != 5 {
 		t.Errorf("expected 5 URLs checked, got %d", result.Stats.TotalChecked)
 	}
+
+	if result.Stats.InternalChecked != 4 || result.Stats.ExternalChecked != 1 {
+		t.Errorf("expected 4 internal + 1 external, got %d + %d",
+			result.Stats.InternalChecked, result.Stats.ExternalChecked)
+	}
+	if result.Stats.BytesDownloaded == 0 {
+		t.Error("expected BytesDownloaded > 0 for crawled pages")
+	}
+	if result.Stats.PeakConcurrency < 1 || result.Stats.PeakConcurrency > 2 {
+		t.Errorf("expected PeakConcurrency within [1, 2], got %d", result.Stats.PeakConcurrency)
+	}
 }
 
 // TestCrawlerDeduplication verifies that cyclic link graphs are handled
@@ -205,6 +222,29 @@ func TestCrawlerCancellation(t *testing.T) {
 	}
 }
 
+// TestCrawlerCancellation_DuringRateWait verifies that cancelling a crawl
+// while workers wait on the rate limiter still returns what was checked.
+func TestCrawlerCancellation_DuringRateWait(t *testing.T) {
+	ts := newTestServer()
+	defer ts.Close()
+
+	cfg := crawler.DefaultConfig(ts.URL)
+	cfg.RatePerMinute = 6 // The second request waits ten seconds
+	c := mustNewCrawler(t, cfg, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	time.AfterFunc(200*time.Millisecond, cancel)
+
+	res, err := c.Run(ctx)
+	if err != nil {
+		t.Fatalf("Run() error after cancellation: %v", err)
+	}
+	if res == nil || res.Stats.TotalChecked < 1 {
+		t.Errorf("Run() = %+v, want the start page checked", res)
+	}
+}
+
 // newDepthTestServer creates a server with a deep link hierarchy:
 // / -> /depth1 -> /depth2 -> /depth3
 // Each page also links to an external URL for validation testing.
@@ -370,3 +410,236 @@ func TestCrawlerAdaptiveRateLimiterEnabled(t *testing.T) {
 		t.Error("DisableAutoTune should be false by default")
 	}
 }
+
+// TestCrawlerRobotsBlocked verifies that robots.txt-disallowed links are
+// skipped with a CategoryRobotsBlocked progress event, and that a disallowed
+// start URL surfaces result.ErrRobotsBlocked.
+func TestCrawlerRobotsBlocked(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/robots.txt", func(w http.ResponseWriter, r *http.Request) {
+		if _, err := fmt.Fprint(w, "User-agent: *\nDisallow: /private\n"); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if _, err := fmt.Fprint(w, `<html><body><a href="/private/page">Private</a></body></html>`); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	progressCh := make(chan crawler.CrawlEvent, 100)
+	c := mustNewCrawler(t, crawler.Config{
+		StartURL:       ts.URL,
+		Concurrency:    2,
+		RequestTimeout: 5 * time.Second,
+	}, progressCh)
+
+	res, err := c.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run() returned error: %v", err)
+	}
+	close(progressCh)
+
+	if len(res.BrokenLinks) != 0 {
+		t.Errorf("expected robots-blocked links not to be reported broken, got %d", len(res.BrokenLinks))
+	}
+
+	var blocked bool
+	for evt := range progressCh {
+		if strings.HasSuffix(evt.URL, "/private/page") && evt.ErrorCategory == result.CategoryRobotsBlocked {
+			blocked = true
+		}
+	}
+	if !blocked {
+		t.Error("expected a CategoryRobotsBlocked event for /private/page")
+	}
+
+	blockedStart := mustNewCrawler(t, crawler.Config{
+		StartURL:       ts.URL + "/private/page",
+		Concurrency:    2,
+		RequestTimeout: 5 * time.Second,
+	}, nil)
+	if _, err := blockedStart.Run(context.Background()); !errors.Is(err, result.ErrRobotsBlocked) {
+		t.Errorf("expected ErrRobotsBlocked for disallowed start URL, got %v", err)
+	}
+}
+
+// TestCrawlerRobotsTxtOverride verifies that Config.RobotsTxt replaces the
+// start host's robots.txt, which is then never fetched.
+func TestCrawlerRobotsTxtOverride(t *testing.T) {
+	var robotsFetched atomic.Bool
+	mux := http.NewServeMux()
+	mux.HandleFunc("/robots.txt", func(w http.ResponseWriter, r *http.Request) {
+		robotsFetched.Store(true)
+		_, _ = fmt.Fprint(w, "User-agent: *\nDisallow: /private\n")
+	})
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprint(w, `<html><body><a href="/private/page">Private</a><a href="/drafts/page">Draft</a></body></html>`)
+	})
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	progressCh := make(chan crawler.CrawlEvent, 100)
+	c := mustNewCrawler(t, crawler.Config{
+		StartURL:       ts.URL,
+		Concurrency:    2,
+		RequestTimeout: 5 * time.Second,
+		RobotsTxt:      []byte("User-agent: *\nDisallow: /drafts\n"),
+	}, progressCh)
+	if _, err := c.Run(context.Background()); err != nil {
+		t.Fatalf("Run() returned error: %v", err)
+	}
+	close(progressCh)
+
+	blocked := map[string]bool{}
+	for evt := range progressCh {
+		if evt.ErrorCategory == result.CategoryRobotsBlocked {
+			blocked[strings.TrimPrefix(evt.URL, ts.URL)] = true
+		}
+	}
+	if !blocked["/drafts/page"] || blocked["/private/page"] {
+		t.Errorf("robots-blocked URLs = %v, want only /drafts/page from the local rules", blocked)
+	}
+	if robotsFetched.Load() {
+		t.Error("expected the site's robots.txt not to be fetched")
+	}
+}
+
+// TestCrawlerStrategies verifies that every strategy checks the same set of
+// URLs and that unknown strategies are rejected.
+func TestCrawlerStrategies(t *testing.T) {
+	ts := newDepthTestServer()
+	defer ts.Close()
+
+	for _, strategy := range []crawler.Strategy{crawler.StrategyBFS, crawler.StrategyDFS, crawler.StrategyRandom} {
+		t.Run(string(strategy), func(t *testing.T) {
+			c := mustNewCrawler(t, crawler.Config{
+				StartURL:       ts.URL,
+				Concurrency:    1,
+				RequestTimeout: 5 * time.Second,
+				Strategy:       strategy,
+			}, nil)
+			res, err := c.Run(context.Background())
+			if err != nil {
+				t.Fatalf("Run() returned error: %v", err)
+			}
+			if res.Stats.TotalChecked != 8 {
+				t.Errorf("expected 8 URLs checked, got %d", res.Stats.TotalChecked)
+			}
+		})
+	}
+
+	if _, err := crawler.New(crawler.Config{StartURL: ts.URL, Strategy: "sideways"}, nil); err == nil {
+		t.Error("expected error for unknown strategy")
+	}
+}
+
+// TestCrawlerRespectExternalRobots verifies that external hosts' robots.txt
+// is ignored by default and honored with RespectExternalRobots.
+func TestCrawlerRespectExternalRobots(t *testing.T) {
+	var externalChecks int
+	var mu sync.Mutex
+	external := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			_, _ = fmt.Fprint(w, "User-agent: *\nDisallow: /\n")
+			return
+		}
+		mu.Lock()
+		externalChecks++
+		mu.Unlock()
+	}))
+	defer external.Close()
+	// Reach the external server through "localhost" so it is a different host from the site
+	externalURL := strings.Replace(external.URL, "127.0.0.1", "localhost", 1)
+
+	site := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprintf(w, `<a href="%s/widget">widget</a>`, externalURL)
+	}))
+	defer site.Close()
+
+	for _, respect := range []bool{false, true} {
+		t.Run(fmt.Sprintf("respect=%v", respect), func(t *testing.T) {
+			mu.Lock()
+			externalChecks = 0
+			mu.Unlock()
+
+			c := mustNewCrawler(t, crawler.Config{
+				StartURL:              site.URL,
+				RequestTimeout:        5 * time.Second,
+				RespectExternalRobots: respect,
+			}, nil)
+			res, err := c.Run(context.Background())
+			if err != nil {
+				t.Fatalf("Run() returned error: %v", err)
+			}
+
+			wantChecks, wantTotal := 1, 2
+			if respect {
+				wantChecks, wantTotal = 0, 1
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			if externalChecks != wantChecks {
+				t.Errorf("external link requested %d times, want %d", externalChecks, wantChecks)
+			}
+			if res.Stats.TotalChecked != wantTotal {
+				t.Errorf("TotalChecked = %d, want %d", res.Stats.TotalChecked, wantTotal)
+			}
+		})
+	}
+}
+
+func TestRun_ScrubsReportedURLs(t *testing.T) {
+	var requested []string
+	var mu sync.Mutex
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requested = append(requested, r.URL.RequestURI())
+		mu.Unlock()
+		if r.URL.Path == "/" {
+			_, _ = fmt.Fprint(w, `<a href="/download?token=s3cret&file=report">report</a>`)
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	defer ts.Close()
+
+	var stream, logs bytes.Buffer
+	progressCh := make(chan crawler.CrawlEvent, 100)
+	cfg := crawler.DefaultConfig(ts.URL)
+	cfg.Delay = 1
+	cfg.LosslessEvents = true
+	cfg.Scrub = result.NewScrubber([]string{"token"})
+	cfg.Results = crawler.NewJSONResultSink(&stream)
+	cfg.Logger = slog.New(slog.NewJSONHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	c, err := crawler.New(cfg, progressCh)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	res, err := c.Run(context.Background())
+	close(progressCh)
+	if err != nil {
+		t.Fatalf("Run() error: %v", err)
+	}
+
+	want := ts.URL + "/download?token=REDACTED&file=report"
+	if len(res.BrokenLinks) != 1 || res.BrokenLinks[0].URL != want {
+		t.Errorf("BrokenLinks = %+v, want %s", res.BrokenLinks, want)
+	}
+	var events strings.Builder
+	for evt := range progressCh {
+		events.WriteString(evt.URL + "\n")
+	}
+	for name, out := range map[string]string{"stream": stream.String(), "logs": logs.String(), "events": events.String()} {
+		if strings.Contains(out, "s3cret") || !strings.Contains(out, "token=REDACTED") {
+			t.Errorf("%s = %s, want the token redacted", name, out)
+		}
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if !slices.Contains(requested, "/download?token=s3cret&file=report") {
+		t.Errorf("requested %v, want the unredacted URL requested", requested)
+	}
+}
@@ -0,0 +1,47 @@
+package crawler
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/lukemcguire/zombiecrawl/result"
+)
+
+// queueContext returns a context for the waits a job goes through before its
+// request is made (rate limiting, shared worker slots). It is cancelled with
+// ctx and, when timeout is positive, once the job has been queued for longer
+// than timeout as measured on clock, which stamped job.Queued.
+func queueContext(ctx context.Context, job CrawlJob, timeout time.Duration, clock Clock) (context.Context, context.CancelFunc) {
+	if timeout <= 0 || job.Queued.IsZero() {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, job.Queued.Add(timeout).Sub(clock.Now()))
+}
+
+// queuedTooLong returns the result for a job that was not started within
+// cfg.QueueTimeout of being queued.
+func queuedTooLong(job CrawlJob, cfg Config) CrawlResult {
+	err := fmt.Errorf("%w: not checked within %s", result.ErrQueuedTooLong, cfg.QueueTimeout)
+	return CrawlResult{
+		Job: job,
+		Result: &result.LinkResult{
+			URL:           job.URL,
+			SourcePage:    job.SourcePage,
+			IsExternal:    job.IsExternal,
+			Error:         err.Error(),
+			ErrorCategory: result.CategoryQueueTimeout,
+		},
+		Err: fmt.Errorf("wait to check %s: %w", job.URL, err),
+	}
+}
+
+// waitRetry sleeps for backoff on sleeper before a retry. It returns false
+// without waiting out the backoff if ctx is cancelled, or if ctx's deadline
+// falls before the backoff ends, since the retry could not run anyway.
+func waitRetry(ctx context.Context, sleeper Sleeper, backoff time.Duration) bool {
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < backoff {
+		return false
+	}
+	return sleeper.Sleep(ctx, backoff) == nil
+}
@@ -0,0 +1,140 @@
+package crawler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/lukemcguire/zombiecrawl/result"
+)
+
+func TestQueueContext(t *testing.T) {
+	// Queued is stamped on the crawl's clock, which need not be the wall clock
+	clock := newFakeClock()
+	queued := clock.Now().Add(-time.Minute)
+	before := time.Now()
+	ctx, cancel := queueContext(context.Background(), CrawlJob{Queued: queued}, 2*time.Minute, clock)
+	defer cancel()
+	deadline, ok := ctx.Deadline()
+	if !ok || deadline.Before(before.Add(time.Minute)) || deadline.After(time.Now().Add(time.Minute)) {
+		t.Errorf("deadline = %v, %v; want a minute from now, two minutes after queueing", deadline, ok)
+	}
+
+	ctx, cancel = queueContext(context.Background(), CrawlJob{Queued: queued}, 0, clock)
+	defer cancel()
+	if _, ok := ctx.Deadline(); ok {
+		t.Error("deadline set without a queue timeout")
+	}
+}
+
+func TestWaitRetry(t *testing.T) {
+	if !waitRetry(context.Background(), systemClock{}, time.Millisecond) {
+		t.Error("waitRetry() = false, want true after the backoff")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if waitRetry(ctx, systemClock{}, time.Minute) {
+		t.Error("waitRetry() = true for a backoff past the deadline")
+	}
+	if elapsed := time.Since(start); elapsed > 40*time.Millisecond {
+		t.Errorf("waited %s for a retry that could not run", elapsed)
+	}
+
+	cancelled, cancelNow := context.WithCancel(context.Background())
+	cancelNow()
+	if waitRetry(cancelled, systemClock{}, time.Minute) {
+		t.Error("waitRetry() = true after cancellation")
+	}
+}
+
+func TestCheckURLWithRetry_StopsBeforeDeadline(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+
+	cfg := Config{
+		RequestTimeout: time.Second,
+		RetryPolicy:    RetryPolicy{MaxRetries: 2, BaseDelay: time.Second, MaxDelay: time.Second},
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	res := CheckURLWithRetry(ctx, &http.Client{}, CrawlJob{URL: ts.URL, IsExternal: true}, cfg, cfg.RetryPolicy)
+	if ctx.Err() != nil {
+		t.Fatal("waited out a backoff longer than the crawl deadline")
+	}
+	if res.Attempts != 1 || res.Result == nil || res.Result.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("got %d attempts, result %+v; want the first attempt's 503", res.Attempts, res.Result)
+	}
+}
+
+func TestCheckURL_CrawlCancellationEndsRequest(t *testing.T) {
+	release := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/" {
+			http.Redirect(w, r, "/slow", http.StatusFound)
+			return
+		}
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer ts.Close()
+	defer close(release)
+
+	cfg := Config{RequestTimeout: time.Minute}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	res := CheckURL(ctx, &http.Client{}, CrawlJob{URL: ts.URL + "/", IsExternal: true}, cfg)
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("request ran %s after the crawl context ended", elapsed)
+	}
+	if res.Err == nil {
+		t.Error("expected an error once the crawl context ended mid-redirect")
+	}
+}
+
+func TestRun_QueueTimeout(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/" {
+			_, _ = fmt.Fprint(w, `<a href="/a">a</a><a href="/b">b</a><a href="/c">c</a>`)
+			return
+		}
+		_, _ = fmt.Fprint(w, `<html></html>`)
+	}))
+	defer ts.Close()
+
+	cfg := DefaultConfig(ts.URL)
+	cfg.RatePerMinute = 6 // One request every ten seconds after the first
+	cfg.QueueTimeout = 200 * time.Millisecond
+	c, err := New(cfg, nil)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+
+	start := time.Now()
+	res, err := c.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run() error: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("crawl took %s; queued jobs should give up after the queue timeout", elapsed)
+	}
+	if len(res.BrokenLinks) != 3 {
+		t.Fatalf("got %d broken links, want the three queued pages: %v", len(res.BrokenLinks), res.BrokenLinks)
+	}
+	for _, link := range res.BrokenLinks {
+		if link.ErrorCategory != result.CategoryQueueTimeout {
+			t.Errorf("link %s category = %s, want %s", link.URL, link.ErrorCategory, result.CategoryQueueTimeout)
+		}
+	}
+}
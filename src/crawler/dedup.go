@@ -0,0 +1,142 @@
+package crawler
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/lukemcguire/zombiecrawl/result"
+	"github.com/lukemcguire/zombiecrawl/urlutil"
+)
+
+// DedupRule makes URLs matching Pattern that differ only in the query
+// parameters Params count as one page, so parameters that do not change
+// the content, such as print=1 or ref=, do not make the crawl request the
+// same page again and again. Only the first URL found is crawled. Empty
+// Params ignores the whole query string. Pattern uses
+// StatusExpectation.Pattern syntax.
+type DedupRule struct {
+	Pattern string
+	Params  []string
+}
+
+// String formats r as "pattern=param,param", or "pattern=*" without
+// Params, the form accepted by ParseDedupRule.
+func (r DedupRule) String() string {
+	if len(r.Params) == 0 {
+		return r.Pattern + "=*"
+	}
+	return r.Pattern + "=" + strings.Join(r.Params, ",")
+}
+
+// ParseDedupRule parses a "pattern=param,param" rule such as
+// "/blog/*=print,ref", or "pattern=*" to ignore the whole query string.
+// The pattern is everything before the last "=", since URL patterns may
+// contain one.
+func ParseDedupRule(value string) (DedupRule, error) {
+	i := strings.LastIndex(value, "=")
+	if i <= 0 || i == len(value)-1 {
+		return DedupRule{}, fmt.Errorf("%q: expected pattern=param[,param] or pattern=*", value)
+	}
+	rule := DedupRule{Pattern: value[:i]}
+	if strings.TrimSpace(value[i+1:]) == "*" {
+		return rule, nil
+	}
+	for field := range strings.SplitSeq(value[i+1:], ",") {
+		param := strings.TrimSpace(field)
+		if param == "" || param == "*" {
+			return DedupRule{}, fmt.Errorf("%q: %q is not a query parameter name", value, field)
+		}
+		rule.Params = append(rule.Params, param)
+	}
+	return rule, nil
+}
+
+// dedupIgnoreQuery labels Config.DedupIgnoreQuery in
+// CrawlStats.DedupFolded.
+const dedupIgnoreQuery = "all query strings"
+
+// withoutParams returns rawURL without the query parameters params, or
+// without its query string if params is empty.
+func withoutParams(rawURL string, params []string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.RawQuery == "" {
+		return rawURL
+	}
+	if len(params) == 0 {
+		u.RawQuery = ""
+		return u.String()
+	}
+	query := u.Query()
+	for _, param := range params {
+		query.Del(param)
+	}
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+// dedupKey returns the key the normalized URL is deduplicated under, and
+// the label of the rule that changed it, or "" if none did. The first of
+// cfg.DedupRules matching it applies, else DedupIgnoreQuery for internal
+// URLs; then FoldSchemes.
+func (c *Crawler) dedupKey(normalized, startHost string) (string, string) {
+	internal := urlutil.IsSameDomain(normalized, startHost)
+	key, rule := normalized, ""
+	job := CrawlJob{URL: normalized, IsExternal: !internal}
+	if i := slices.IndexFunc(c.cfg.DedupRules, func(r DedupRule) bool { return urlPatternMatch(r.Pattern, job) }); i >= 0 {
+		key = withoutParams(normalized, c.cfg.DedupRules[i].Params)
+		rule = c.cfg.DedupRules[i].String()
+	} else if c.cfg.DedupIgnoreQuery && internal {
+		key = withoutParams(normalized, nil)
+		rule = dedupIgnoreQuery
+	}
+	if key == normalized {
+		rule = ""
+	}
+	return c.visitKey(key, startHost), rule
+}
+
+// visitIfNew marks normalized visited, as VisitIfNew does, under its
+// dedupKey, and reports whether it was new. A URL that a dedup rule
+// folds into one already visited is counted against the rule, once.
+func (c *Crawler) visitIfNew(normalized, startHost string) bool {
+	key, rule := c.dedupKey(normalized, startHost)
+	isNew := c.visited.VisitIfNew(key)
+	if isNew {
+		c.visitedCount.Add(1)
+	}
+	if rule == "" {
+		return isNew
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.dedupSeen == nil {
+		c.dedupSeen = make(map[string]bool)
+		c.dedupFolded = make(map[string]int)
+	}
+	if !isNew && !c.dedupSeen[normalized] {
+		c.dedupFolded[rule]++
+	}
+	c.dedupSeen[normalized] = true
+	return isNew
+}
+
+// dedupStats returns how many URLs each dedup rule folded, in the order of
+// the rules, for CrawlStats.DedupFolded.
+func (c *Crawler) dedupStats() []result.DedupFold {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	rules := make([]string, 0, len(c.cfg.DedupRules)+1)
+	for _, rule := range c.cfg.DedupRules {
+		rules = append(rules, rule.String())
+	}
+	rules = append(rules, dedupIgnoreQuery)
+	var folds []result.DedupFold
+	for _, rule := range rules {
+		if n := c.dedupFolded[rule]; n > 0 {
+			folds = append(folds, result.DedupFold{Rule: rule, Folded: n})
+		}
+	}
+	return folds
+}
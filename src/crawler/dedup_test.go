@@ -0,0 +1,131 @@
+package crawler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+func TestParseDedupRule(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{"/blog/*=print,ref", "/blog/*=print,ref", false},
+		{"/search=*", "/search=*", false},
+		{"https://example.com/?a=b=utm_source", "https://example.com/?a=b=utm_source", false},
+		{"/blog/* = print , ref", "/blog/* =print,ref", false},
+		{"=print", "", true},
+		{"/blog/=", "", true},
+		{"/blog/=print,", "", true},
+		{"/blog", "", true},
+	}
+	for _, tt := range tests {
+		rule, err := ParseDedupRule(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseDedupRule(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if err == nil && rule.String() != tt.want {
+			t.Errorf("ParseDedupRule(%q) = %q, want %q", tt.in, rule.String(), tt.want)
+		}
+	}
+}
+
+func TestWithoutParams(t *testing.T) {
+	tests := []struct {
+		in     string
+		params []string
+		want   string
+	}{
+		{"https://example.com/a?print=1&id=2", []string{"print"}, "https://example.com/a?id=2"},
+		{"https://example.com/a?print=1&ref=x", []string{"print", "ref"}, "https://example.com/a"},
+		{"https://example.com/a?print=1&id=2", nil, "https://example.com/a"},
+		{"https://example.com/a", []string{"print"}, "https://example.com/a"},
+	}
+	for _, tt := range tests {
+		if got := withoutParams(tt.in, tt.params); got != tt.want {
+			t.Errorf("withoutParams(%q, %v) = %q, want %q", tt.in, tt.params, got, tt.want)
+		}
+	}
+}
+
+// dedupSite serves a home page linking to one article under several query
+// strings, and to a listing whose query selects different content. It
+// records the request URIs it serves.
+func dedupSite(t *testing.T) (*httptest.Server, func() map[string]int) {
+	t.Helper()
+	var mu sync.Mutex
+	requests := make(map[string]int)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests[r.URL.RequestURI()]++
+		mu.Unlock()
+		if r.URL.Path == "/" {
+			_, _ = fmt.Fprint(w, `<a href="/post">a</a><a href="/post?print=1">b</a><a href="/post?ref=x">c</a>`+
+				`<a href="/post?ref=y">d</a><a href="/post?ref=x">again</a><a href="/list?page=2">e</a>`)
+			return
+		}
+		_, _ = fmt.Fprint(w, `<html></html>`)
+	}))
+	t.Cleanup(ts.Close)
+	return ts, func() map[string]int {
+		mu.Lock()
+		defer mu.Unlock()
+		return requests
+	}
+}
+
+func TestRun_DedupRules(t *testing.T) {
+	ts, requests := dedupSite(t)
+	cfg := DefaultConfig(ts.URL)
+	cfg.Delay = 1
+	cfg.DedupRules = []DedupRule{{Pattern: "/post", Params: []string{"print", "ref"}}}
+	c, err := New(cfg, nil)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	res, err := c.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run() error: %v", err)
+	}
+
+	got := requests()
+	if got["/post"] != 1 || got["/post?print=1"]+got["/post?ref=x"]+got["/post?ref=y"] != 0 {
+		t.Errorf("requests = %v, want /post once and its variants never", got)
+	}
+	if got["/list?page=2"] != 1 {
+		t.Errorf("requests = %v, want /list?page=2 crawled", got)
+	}
+	if fmt.Sprint(res.Stats.DedupFolded) != "[{/post=print,ref 3}]" {
+		t.Errorf("DedupFolded = %v, want 3 URLs folded by the rule", res.Stats.DedupFolded)
+	}
+}
+
+func TestRun_DedupIgnoreQuery(t *testing.T) {
+	ts, requests := dedupSite(t)
+	cfg := DefaultConfig(ts.URL)
+	cfg.Delay = 1
+	cfg.DedupIgnoreQuery = true
+	c, err := New(cfg, nil)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	res, err := c.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run() error: %v", err)
+	}
+
+	got := requests()
+	delete(got, "/robots.txt")
+	if len(got) != 3 || got["/post"] != 1 || got["/list?page=2"] != 1 {
+		t.Errorf("requests = %v, want /, /post, and /list", got)
+	}
+	if fmt.Sprint(res.Stats.DedupFolded) != "[{all query strings 3}]" {
+		t.Errorf("DedupFolded = %v, want 3 URLs folded", res.Stats.DedupFolded)
+	}
+}
@@ -1,14 +1,173 @@
 package crawler
 
-import "github.com/lukemcguire/zombiecrawl/result"
+import (
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/lukemcguire/zombiecrawl/result"
+)
 
 // CrawlEvent reports progress for a single checked URL.
 type CrawlEvent struct {
-	URL           string
-	StatusCode    int
-	Error         string
-	ErrorCategory result.ErrorCategory
-	Checked       int
-	Broken        int
-	IsExternal    bool
+	RunID         string               `json:"run_id,omitempty"` // Config.RunID of the crawl
+	URL           string               `json:"url,omitempty"`
+	StatusCode    int                  `json:"status_code,omitempty"`
+	Error         string               `json:"error,omitempty"`
+	ErrorCategory result.ErrorCategory `json:"error_type,omitempty"`
+	Checked       int                  `json:"checked"`
+	Broken        int                  `json:"broken"`
+	IsExternal    bool                 `json:"is_external"`
+
+	// Set on the event of a check: the job's depth, the requests made
+	// including retries, the response body bytes read, and the wall time
+	// of the final attempt. ErrorCategory is set when the check failed.
+	Depth         int           `json:"depth"`
+	Attempt       int           `json:"attempt,omitempty"`
+	ContentLength int64         `json:"content_length,omitempty"`
+	Duration      time.Duration `json:"duration,omitempty"`
+
+	// BrokenLinks is set on the event of a check that found broken links:
+	// the checked link itself if it is broken, and malformed links on its
+	// page. Broken already counts them.
+	BrokenLinks []result.LinkResult `json:"broken_links,omitempty"`
+
+	// Slow is set on periodic status events, which have no URL: the checks
+	// running longer than Config.SlowRequest, longest first. It is empty,
+	// not nil, on the event after the last slow check finishes.
+	Slow []InFlightRequest `json:"slow,omitempty"`
+}
+
+// eventQueueSize is how many undelivered events are held while the consumer
+// is busy before the oldest are dropped.
+const eventQueueSize = 256
+
+// eventPublisher decouples the crawl from the progress channel. Events are
+// queued and forwarded by a goroutine, so a slow consumer never stalls
+// fetching; when the queue is full the oldest event is dropped. Every event
+// carries the cumulative Checked and Broken counts, so a consumer that misses
+// some still sees current totals. A nil *eventPublisher discards events.
+type eventPublisher struct {
+	out      chan<- CrawlEvent
+	runID    string           // Set on every event
+	lossless bool             // Block instead of dropping (Config.LosslessEvents)
+	scrub    *result.Scrubber // Redacts event URLs (Config.Scrub)
+
+	mu      sync.Mutex
+	queue   []CrawlEvent
+	wake    chan struct{}
+	stop    chan struct{}
+	stopped chan struct{}
+
+	published atomic.Int64
+	dropped   atomic.Int64
+}
+
+// newEventPublisher starts forwarding to out, or returns nil if out is nil.
+// Call close to flush the queue and stop forwarding.
+func newEventPublisher(out chan<- CrawlEvent, runID string, lossless bool, scrub *result.Scrubber) *eventPublisher {
+	if out == nil {
+		return nil
+	}
+	p := &eventPublisher{
+		out:      out,
+		runID:    runID,
+		lossless: lossless,
+		scrub:    scrub,
+		wake:     make(chan struct{}, 1),
+		stop:     make(chan struct{}),
+		stopped:  make(chan struct{}),
+	}
+	go p.forward()
+	return p
+}
+
+// publish queues evt without blocking, dropping the oldest queued event if
+// the queue is full. In lossless mode it sends directly and may block.
+func (p *eventPublisher) publish(evt CrawlEvent) {
+	if p == nil {
+		return
+	}
+	p.published.Add(1)
+	evt.RunID = p.runID
+	if p.scrub != nil {
+		evt.URL = p.scrub.String(evt.URL)
+		evt.Error = p.scrub.String(evt.Error)
+		evt.Slow = slices.Clone(evt.Slow)
+		for i := range evt.Slow {
+			evt.Slow[i].URL = p.scrub.String(evt.Slow[i].URL)
+		}
+		evt.BrokenLinks = slices.Clone(evt.BrokenLinks)
+		for i := range evt.BrokenLinks {
+			evt.BrokenLinks[i] = p.scrub.Link(evt.BrokenLinks[i])
+		}
+	}
+	if p.lossless {
+		p.out <- evt
+		return
+	}
+
+	p.mu.Lock()
+	if len(p.queue) >= eventQueueSize {
+		p.queue = p.queue[1:]
+		p.dropped.Add(1)
+	}
+	p.queue = append(p.queue, evt)
+	p.mu.Unlock()
+
+	select {
+	case p.wake <- struct{}{}:
+	default:
+	}
+}
+
+// forward sends queued events to the consumer until close is called, then
+// flushes whatever is left.
+func (p *eventPublisher) forward() {
+	defer close(p.stopped)
+	for {
+		select {
+		case <-p.wake:
+			p.flush()
+		case <-p.stop:
+			p.flush()
+			return
+		}
+	}
+}
+
+// flush sends every queued event, oldest first.
+func (p *eventPublisher) flush() {
+	for {
+		p.mu.Lock()
+		if len(p.queue) == 0 {
+			p.mu.Unlock()
+			return
+		}
+		evt := p.queue[0]
+		p.queue = p.queue[1:]
+		p.mu.Unlock()
+		p.out <- evt
+	}
+}
+
+// close delivers the remaining events and stops forwarding. No events may be
+// published afterwards.
+func (p *eventPublisher) close() {
+	if p == nil {
+		return
+	}
+	close(p.stop)
+	<-p.stopped
+}
+
+// counts returns how many events have been or will be delivered, and how
+// many were dropped because the consumer fell behind.
+func (p *eventPublisher) counts() (delivered, dropped int64) {
+	if p == nil {
+		return 0, 0
+	}
+	dropped = p.dropped.Load()
+	return p.published.Load() - dropped, dropped
 }
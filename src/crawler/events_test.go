@@ -0,0 +1,135 @@
+package crawler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/lukemcguire/zombiecrawl/result"
+)
+
+func TestEventPublisher_DropsOldest(t *testing.T) {
+	out := make(chan CrawlEvent)
+	p := &eventPublisher{out: out, wake: make(chan struct{}, 1)}
+	total := eventQueueSize + 5
+	for i := range total {
+		p.publish(CrawlEvent{Checked: i})
+	}
+
+	if got := len(p.queue); got != eventQueueSize {
+		t.Fatalf("queue length = %d, want %d", got, eventQueueSize)
+	}
+	if got := p.queue[0].Checked; got != 5 {
+		t.Errorf("oldest queued event = %d, want 5", got)
+	}
+	delivered, dropped := p.counts()
+	if delivered != eventQueueSize || dropped != 5 {
+		t.Errorf("counts() = (%d, %d), want (%d, 5)", delivered, dropped, eventQueueSize)
+	}
+}
+
+func TestEventPublisher_NeverBlocks(t *testing.T) {
+	out := make(chan CrawlEvent, 1)
+	p := newEventPublisher(out, "", false, nil)
+	for i := range eventQueueSize * 4 {
+		p.publish(CrawlEvent{Checked: i + 1})
+	}
+
+	// Drain concurrently so close can flush the remainder.
+	done := make(chan []CrawlEvent)
+	go func() {
+		var got []CrawlEvent
+		for evt := range out {
+			got = append(got, evt)
+		}
+		done <- got
+	}()
+	p.close()
+	close(out)
+	got := <-done
+
+	delivered, dropped := p.counts()
+	if int64(len(got)) != delivered {
+		t.Errorf("received %d events, counts() reported %d delivered", len(got), delivered)
+	}
+	if delivered+dropped != eventQueueSize*4 {
+		t.Errorf("delivered+dropped = %d, want %d", delivered+dropped, eventQueueSize*4)
+	}
+	if last := got[len(got)-1].Checked; last != eventQueueSize*4 {
+		t.Errorf("last event = %d, want the newest (%d)", last, eventQueueSize*4)
+	}
+}
+
+func TestEventPublisher_Lossless(t *testing.T) {
+	out := make(chan CrawlEvent, 3)
+	p := newEventPublisher(out, "", true, nil)
+	for i := range 3 {
+		p.publish(CrawlEvent{Checked: i})
+	}
+	p.close()
+
+	if got := len(out); got != 3 {
+		t.Errorf("got %d events, want 3", got)
+	}
+	if _, dropped := p.counts(); dropped != 0 {
+		t.Errorf("dropped = %d, want 0", dropped)
+	}
+}
+
+func TestEventPublisher_Nil(t *testing.T) {
+	p := newEventPublisher(nil, "", false, nil)
+	if p != nil {
+		t.Fatal("expected nil publisher for a nil channel")
+	}
+	p.publish(CrawlEvent{})
+	p.close()
+	if delivered, dropped := p.counts(); delivered != 0 || dropped != 0 {
+		t.Errorf("counts() = (%d, %d), want zeros", delivered, dropped)
+	}
+}
+
+func TestRun_EventsDescribeChecks(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = fmt.Fprint(w, `<a href="/missing">missing</a>`)
+	}))
+	defer ts.Close()
+
+	cfg := DefaultConfig(ts.URL)
+	cfg.Delay = 1
+	cfg.LosslessEvents = true
+	cfg.RetryPolicy = RetryPolicy{MaxRetries: 0}
+	progressCh := make(chan CrawlEvent, 100)
+	c, err := New(cfg, progressCh)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	if _, err := c.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error: %v", err)
+	}
+	close(progressCh)
+
+	events := make(map[string]CrawlEvent)
+	for evt := range progressCh {
+		events[evt.URL] = evt
+	}
+	start := events[ts.URL+"/"]
+	if start.StatusCode != http.StatusOK || start.Depth != 0 || start.Attempt != 1 || start.ContentLength == 0 || start.ErrorCategory != "" {
+		t.Errorf("start page event = %+v, want a successful depth 0 check with a body", start)
+	}
+	missing := events[ts.URL+"/missing"]
+	if missing.StatusCode != http.StatusNotFound || missing.Depth != 1 || missing.Attempt != 1 || missing.ErrorCategory != result.Category4xx {
+		t.Errorf("missing page event = %+v, want a depth 1 4xx check", missing)
+	}
+	if len(start.BrokenLinks) != 0 {
+		t.Errorf("start page event BrokenLinks = %+v, want none", start.BrokenLinks)
+	}
+	if len(missing.BrokenLinks) != 1 || missing.BrokenLinks[0].URL != ts.URL+"/missing" || missing.BrokenLinks[0].SourcePage != ts.URL+"/" {
+		t.Errorf("missing page event BrokenLinks = %+v, want the missing link found on the start page", missing.BrokenLinks)
+	}
+}
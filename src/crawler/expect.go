@@ -0,0 +1,113 @@
+package crawler
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/lukemcguire/zombiecrawl/result"
+)
+
+// StatusExpectation asserts the final status of URLs matching Pattern, turning
+// the crawl into a check of routing rules such as "retired pages answer 410"
+// or "the API demands credentials". Matching URLs that answer with any other
+// status are reported as broken, and ones answering with an expected error
+// status are not.
+//
+// Pattern uses robots.txt syntax: "*" matches any sequence of characters, a
+// trailing "$" anchors the end, and otherwise it is a prefix match. A pattern
+// starting with "/" matches the path and query of links on the crawled site,
+// e.g. "/gone/"; any other pattern matches whole URLs, e.g.
+// "https://api.example.com/*".
+type StatusExpectation struct {
+	Pattern  string
+	Statuses []int
+}
+
+// String formats e as "pattern=status,status", the form accepted by
+// ParseStatusExpectation.
+func (e StatusExpectation) String() string {
+	statuses := make([]string, len(e.Statuses))
+	for i, status := range e.Statuses {
+		statuses[i] = strconv.Itoa(status)
+	}
+	return e.Pattern + "=" + strings.Join(statuses, ",")
+}
+
+// ParseStatusExpectation parses a "pattern=status,status" assertion such as
+// "/gone/=410" or "/api/=401,403". The pattern is everything before the last
+// "=", since URL patterns may contain one.
+func ParseStatusExpectation(value string) (StatusExpectation, error) {
+	i := strings.LastIndex(value, "=")
+	if i <= 0 || i == len(value)-1 {
+		return StatusExpectation{}, fmt.Errorf("%q: expected pattern=status[,status]", value)
+	}
+	expectation := StatusExpectation{Pattern: value[:i]}
+	for field := range strings.SplitSeq(value[i+1:], ",") {
+		status, err := strconv.Atoi(strings.TrimSpace(field))
+		if err != nil || status < 100 || status > 599 {
+			return StatusExpectation{}, fmt.Errorf("%q: %q is not an HTTP status", value, field)
+		}
+		expectation.Statuses = append(expectation.Statuses, status)
+	}
+	return expectation, nil
+}
+
+// expectedStatuses returns the statuses of the first of
+// cfg.ExpectedStatuses matching job's URL, or nil if none does.
+func (cfg Config) expectedStatuses(job CrawlJob) []int {
+	for _, expectation := range cfg.ExpectedStatuses {
+		if urlPatternMatch(expectation.Pattern, job) {
+			return expectation.Statuses
+		}
+	}
+	return nil
+}
+
+// urlPatternMatch reports whether job's URL matches pattern, in
+// StatusExpectation.Pattern syntax.
+func urlPatternMatch(pattern string, job CrawlJob) bool {
+	if !strings.HasPrefix(pattern, "/") {
+		return robotsPatternMatch(pattern, job.URL)
+	}
+	if job.IsExternal {
+		return false
+	}
+	u, err := url.Parse(job.URL)
+	return err == nil && robotsPatternMatch(pattern, u.RequestURI())
+}
+
+// statusExpected reports whether status is one of expected, or any 2xx
+// status if expected is empty.
+func statusExpected(expected []int, status int) bool {
+	if len(expected) == 0 {
+		return status >= 200 && status < 300
+	}
+	return slices.Contains(expected, status)
+}
+
+// unexpectedStatusFailed records a broken link for a response whose status
+// is not one of expected. Error statuses keep their usual category; other
+// statuses are CategoryUnexpectedStatus.
+func unexpectedStatusFailed(res *CrawlResult, resp *http.Response, expected []int) {
+	statusFailed(res, resp, false)
+	res.Result.Error = unexpectedStatus(expected, resp.StatusCode)
+	if resp.StatusCode < 400 {
+		res.Result.ErrorCategory = result.CategoryUnexpectedStatus
+	}
+}
+
+// unexpectedStatus describes a status that is not one of expected.
+func unexpectedStatus(expected []int, status int) string {
+	if len(expected) == 0 {
+		return fmt.Sprintf("unexpected status %d (want 2xx)", status)
+	}
+	want := make([]string, len(expected))
+	for i, code := range expected {
+		want[i] = fmt.Sprint(code)
+	}
+	return fmt.Sprintf("unexpected status %d (want %s)", status, strings.Join(want, " or "))
+}
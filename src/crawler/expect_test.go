@@ -0,0 +1,154 @@
+package crawler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/lukemcguire/zombiecrawl/result"
+)
+
+func TestStatusExpected(t *testing.T) {
+	tests := []struct {
+		expected []int
+		status   int
+		want     bool
+	}{
+		{nil, 200, true},
+		{nil, 204, true},
+		{nil, 301, false},
+		{nil, 404, false},
+		{[]int{201}, 200, false},
+		{[]int{201}, 201, true},
+		{[]int{401, 403}, 403, true},
+	}
+	for _, tt := range tests {
+		if got := statusExpected(tt.expected, tt.status); got != tt.want {
+			t.Errorf("statusExpected(%v, %d) = %v, want %v", tt.expected, tt.status, got, tt.want)
+		}
+	}
+}
+
+func TestConfig_ExpectedStatuses(t *testing.T) {
+	cfg := Config{ExpectedStatuses: []StatusExpectation{
+		{Pattern: "/gone/", Statuses: []int{410}},
+		{Pattern: "/api/*/private$", Statuses: []int{401, 403}},
+		{Pattern: "/*?legacy=", Statuses: []int{301}},
+		{Pattern: "https://api.example.com/*", Statuses: []int{401}},
+	}}
+	tests := []struct {
+		url        string
+		isExternal bool
+		want       []int
+	}{
+		{"https://example.com/gone/old-page", false, []int{410}},
+		{"https://example.com/gone", false, nil}, // Prefix includes the slash
+		{"https://example.com/api/v1/private", false, []int{401, 403}},
+		{"https://example.com/api/v1/private/key", false, nil},
+		{"https://example.com/page?legacy=1", false, []int{301}},
+		{"https://other.example/gone/page", true, nil}, // Path patterns are for the crawled site
+		{"https://api.example.com/v1", true, []int{401}},
+		{"https://example.com/about", false, nil},
+	}
+	for _, tt := range tests {
+		job := CrawlJob{URL: tt.url, IsExternal: tt.isExternal}
+		if got := cfg.expectedStatuses(job); !slices.Equal(got, tt.want) {
+			t.Errorf("expectedStatuses(%q) = %v, want %v", tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestUnexpectedStatus(t *testing.T) {
+	if got, want := unexpectedStatus(nil, 301), "unexpected status 301 (want 2xx)"; got != want {
+		t.Errorf("unexpectedStatus() = %q, want %q", got, want)
+	}
+	if got, want := unexpectedStatus([]int{401, 403}, 200), "unexpected status 200 (want 401 or 403)"; got != want {
+		t.Errorf("unexpectedStatus() = %q, want %q", got, want)
+	}
+}
+
+func TestRun_ExpectedStatuses(t *testing.T) {
+	var external *httptest.Server
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/":
+			_, _ = fmt.Fprintf(w, `<a href="/gone/old">old</a><a href="/gone/stale">stale</a>
+				<a href="/api/users">api</a><a href="/missing">missing</a><a href="%s/v1">external api</a>`, external.URL)
+		case "/gone/old":
+			http.Error(w, `<a href="/never-crawled">x</a>`, http.StatusGone)
+		case "/gone/stale":
+			http.NotFound(w, r)
+		case "/api/users":
+			_, _ = fmt.Fprint(w, `[]`) // Should have demanded credentials
+		case "/robots.txt", "/missing":
+			http.NotFound(w, r)
+		default:
+			t.Errorf("unexpected request for %s", r.URL)
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+	external = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer external.Close()
+
+	cfg := DefaultConfig(ts.URL)
+	cfg.Delay = 1
+	cfg.ExpectedStatuses = []StatusExpectation{
+		{Pattern: "/gone/", Statuses: []int{http.StatusGone}},
+		{Pattern: "/api/", Statuses: []int{http.StatusUnauthorized}},
+		{Pattern: external.URL + "/", Statuses: []int{http.StatusUnauthorized}},
+	}
+	c, err := New(cfg, nil)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	res, err := c.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run() error: %v", err)
+	}
+
+	broken := make(map[string]result.LinkResult)
+	for _, link := range res.BrokenLinks {
+		broken[link.URL] = link
+	}
+	if len(broken) != 3 {
+		t.Fatalf("broken links = %+v, want /gone/stale, /api/users, and /missing", res.BrokenLinks)
+	}
+	if stale := broken[ts.URL+"/gone/stale"]; stale.ErrorCategory != result.Category4xx || stale.Error != "unexpected status 404 (want 410)" {
+		t.Errorf("/gone/stale = %+v, want an unexpected 404", stale)
+	}
+	if api := broken[ts.URL+"/api/users"]; api.ErrorCategory != result.CategoryUnexpectedStatus || api.StatusCode != http.StatusOK {
+		t.Errorf("/api/users = %+v, want an unexpected 200", api)
+	}
+	// URLs without an expectation are judged as usual
+	if missing := broken[ts.URL+"/missing"]; missing.ErrorCategory != result.Category4xx || missing.Error != "" {
+		t.Errorf("/missing = %+v, want an ordinary 404", missing)
+	}
+}
+
+func TestParseStatusExpectation(t *testing.T) {
+	for value, want := range map[string]StatusExpectation{
+		"/gone/=410":       {Pattern: "/gone/", Statuses: []int{410}},
+		"/api/=401, 403":   {Pattern: "/api/", Statuses: []int{401, 403}},
+		"/*?legacy=1$=301": {Pattern: "/*?legacy=1$", Statuses: []int{301}},
+	} {
+		got, err := ParseStatusExpectation(value)
+		if err != nil || got.Pattern != want.Pattern || !slices.Equal(got.Statuses, want.Statuses) {
+			t.Errorf("ParseStatusExpectation(%q) = %v, %v; want %v", value, got, err, want)
+		}
+		if err == nil && got.String() != strings.ReplaceAll(value, " ", "") {
+			t.Errorf("String() = %q, want %q", got.String(), value)
+		}
+	}
+	for _, value := range []string{"", "/gone/", "=410", "/gone/=", "/gone/=gone", "/gone/=410,", "https://a.test/=2"} {
+		if _, err := ParseStatusExpectation(value); err == nil {
+			t.Errorf("ParseStatusExpectation(%q) expected error", value)
+		}
+	}
+}
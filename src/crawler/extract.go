@@ -13,58 +13,109 @@ import (
 // It resolves relative URLs against the baseURL, filters non-HTTP schemes,
 // normalizes each URL, and returns a deduplicated list of absolute URLs.
 func ExtractLinks(body io.Reader, baseURL *url.URL) ([]string, error) {
+	return extractLinks(body, baseURL, nil, nil, nil, nil)
+}
+
+// extractLinks implements ExtractLinks. If visit is non-nil it is called with
+// the raw href and resolved URL of each HTTP(S) link the first time it is seen.
+// If audit is non-nil it is fed the anchors, text, and images of the page,
+// and if meta is non-nil it is fed the page's title and meta tags. If
+// fragments is non-nil it is fed every element and every resolved href,
+// including ones that repeat a link already seen.
+func extractLinks(body io.Reader, baseURL *url.URL, visit func(href string, resolved *url.URL), audit *pageAuditor, meta *metaCollector, fragments *fragmentChecker) ([]string, error) {
 	tokenizer := html.NewTokenizer(body)
 	seen := make(map[string]bool)
 	var links []string
 	var errs []error
 
+	// addLink resolves, filters, normalizes, and records one href. Only
+	// anchors can point to a fragment of the page.
+	addLink := func(raw string, anchor bool) {
+		href := urlutil.Sanitize(raw)
+		if href == "" {
+			// Empty href points to current page
+			href = baseURL.String()
+		}
+
+		// Resolve relative URL against base
+		hrefURL, err := url.Parse(href)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("parse href %q: %w", href, err))
+			return
+		}
+		resolved := baseURL.ResolveReference(hrefURL)
+		if anchor {
+			fragments.link(raw, resolved)
+		}
+
+		resolvedStr := resolved.String()
+
+		// Filter non-HTTP schemes
+		if !urlutil.IsHTTPScheme(resolvedStr) {
+			return
+		}
+
+		// Normalize the URL
+		normalized, err := urlutil.Normalize(resolvedStr)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("normalize URL %q: %w", resolvedStr, err))
+			return
+		}
+
+		// Deduplicate
+		if !seen[normalized] {
+			seen[normalized] = true
+			links = append(links, normalized)
+			if visit != nil {
+				visit(raw, resolved)
+			}
+		}
+	}
+
 	for {
 		tokenType := tokenizer.Next()
 		switch tokenType {
 		case html.ErrorToken:
 			// End of document or error
+			audit.endAnchor()
 			if len(errs) > 0 {
 				return links, fmt.Errorf("encountered %d parse errors (first: %w)", len(errs), errs[0])
 			}
 			return links, nil
+		case html.TextToken:
+			text := tokenizer.Text()
+			audit.text(text)
+			meta.text(text)
+		case html.EndTagToken:
+			name, _ := tokenizer.TagName()
+			if string(name) == "a" {
+				audit.endAnchor()
+			}
+			meta.end(string(name))
 		case html.StartTagToken, html.SelfClosingTagToken:
 			token := tokenizer.Token()
-			if token.Data == "a" {
-				for _, attr := range token.Attr {
-					if attr.Key == "href" {
-						href := attr.Val
-						if href == "" {
-							// Empty href points to current page
-							href = baseURL.String()
-						}
-
-						// Resolve relative URL against base
-						hrefURL, err := url.Parse(href)
-						if err != nil {
-							errs = append(errs, fmt.Errorf("parse href %q: %w", href, err))
-							continue
-						}
-						resolved := baseURL.ResolveReference(hrefURL)
-
-						resolvedStr := resolved.String()
-
-						// Filter non-HTTP schemes
-						if !urlutil.IsHTTPScheme(resolvedStr) {
-							continue
-						}
-
-						// Normalize the URL
-						normalized, err := urlutil.Normalize(resolvedStr)
-						if err != nil {
-							errs = append(errs, fmt.Errorf("normalize URL %q: %w", resolvedStr, err))
-							continue
-						}
-
-						// Deduplicate
-						if !seen[normalized] {
-							seen[normalized] = true
-							links = append(links, normalized)
-						}
+			meta.start(token)
+			fragments.element(token)
+			switch token.Data {
+			case "a":
+				audit.startAnchor(token)
+				if token.Type == html.SelfClosingTagToken {
+					audit.endAnchor()
+				}
+			case "img":
+				audit.image(token)
+			}
+			switch token.Data {
+			case "a":
+				if href, ok := attr(token, "href"); ok {
+					addLink(href, true)
+				}
+			case "link":
+				// Pagination links are crawl edges even when the page only
+				// renders its next/previous anchors with JavaScript
+				if rel, _ := attr(token, "rel"); hasRel(rel, "next") || hasRel(rel, "prev") || hasRel(rel, "previous") {
+					if href, ok := attr(token, "href"); ok {
+						addLink(href, false)
 					}
 				}
 			}
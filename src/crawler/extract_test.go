@@ -39,6 +39,11 @@ func TestExtractLinks(t *testing.T) {
 			html:     `<a href="">Empty</a>`,
 			expected: []string{"https://example.com"},
 		},
+		{
+			name:     "follows pagination link elements",
+			html:     `<link rel="next" href="/page/2"><link rel="Prev" href="/page/0"><link rel="stylesheet" href="/style.css">`,
+			expected: []string{"https://example.com/page/2", "https://example.com/page/0"},
+		},
 		{
 			name: "extracts multiple links",
 			html: `<a href="/page1">Page 1</a>
@@ -0,0 +1,213 @@
+package crawler
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/lukemcguire/zombiecrawl/result"
+	"github.com/lukemcguire/zombiecrawl/urlutil"
+)
+
+// maxFeedBytes caps the decoded size of a single feed.
+const maxFeedBytes = 10 << 20
+
+// feedDocument decodes RSS 2.0 <rss>, RSS 1.0 <rdf:RDF>, and Atom <feed>
+// documents; only the fields matching the root element are populated.
+type feedDocument struct {
+	XMLName xml.Name
+	Channel struct {
+		Items []feedItem `xml:"item"`
+	} `xml:"channel"` // RSS 2.0
+	Items   []feedItem  `xml:"item"`  // RSS 1.0
+	Entries []atomEntry `xml:"entry"` // Atom
+}
+
+// feedItem is an RSS <item>.
+type feedItem struct {
+	Link string `xml:"link"`
+}
+
+// atomEntry is an Atom <entry>.
+type atomEntry struct {
+	Links []struct {
+		Rel  string `xml:"rel,attr"`
+		Href string `xml:"href,attr"`
+	} `xml:"link"`
+}
+
+// entryLinks returns the link of each entry in doc, resolved against the
+// feed's URL and normalized, in order and without duplicates. Atom entries
+// link to their page with rel="alternate", the default.
+func (doc *feedDocument) entryLinks(feedURL *url.URL) []string {
+	var raw []string
+	for _, item := range slices.Concat(doc.Channel.Items, doc.Items) {
+		raw = append(raw, item.Link)
+	}
+	for _, entry := range doc.Entries {
+		for _, link := range entry.Links {
+			if link.Rel == "" || strings.EqualFold(link.Rel, "alternate") {
+				raw = append(raw, link.Href)
+				break
+			}
+		}
+	}
+
+	var links []string
+	for _, href := range raw {
+		ref, err := url.Parse(urlutil.Sanitize(href))
+		if err != nil || strings.TrimSpace(href) == "" {
+			continue
+		}
+		resolved := feedURL.ResolveReference(ref).String()
+		if !urlutil.IsHTTPScheme(resolved) {
+			continue
+		}
+		normalized, err := urlutil.Normalize(resolved)
+		if err == nil && !slices.Contains(links, normalized) {
+			links = append(links, normalized)
+		}
+	}
+	return links
+}
+
+// fetchFeed downloads and decodes the feed at job.URL, returning the links
+// of its entries.
+func (c *Crawler) fetchFeed(ctx context.Context, job CrawlJob) ([]string, error) {
+	reqCtx, cancel := context.WithTimeout(ctx, c.cfg.RequestTimeout)
+	defer cancel()
+
+	req, err := newRequest(reqCtx, http.MethodGet, job, c.cfg)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetch feed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode >= 400 {
+		return nil, fmt.Errorf("fetch feed: status %d", resp.StatusCode)
+	}
+
+	var doc feedDocument
+	body := c.cfg.Bandwidth.reader(reqCtx, resp.Body)
+	if err := xml.NewDecoder(io.LimitReader(body, maxFeedBytes)).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("parse feed: %w", err)
+	}
+	if root := doc.XMLName.Local; root != "rss" && root != "RDF" && root != "feed" {
+		return nil, fmt.Errorf("parse feed: unexpected root element <%s>", root)
+	}
+	return doc.entryLinks(resp.Request.URL), nil
+}
+
+// feedFetch is the outcome of fetching one feed.
+type feedFetch struct {
+	entries []string
+	err     error
+}
+
+// checkFeeds fetches each feed in feeds, then checks the link of every entry
+// like a crawled link, counting it in the crawl's totals. Each feed's
+// SourcePage is the page advertising it. If ctx is cancelled, unfetched
+// feeds and unchecked entries are left out.
+func (c *Crawler) checkFeeds(ctx context.Context, feeds []CrawlJob, startHost string) []result.Feed {
+	fetched := make([]*feedFetch, len(feeds))
+	c.runProbes(ctx, feeds, func(i int, feed CrawlJob) {
+		entries, err := c.fetchFeed(ctx, feed)
+		if ctx.Err() == nil {
+			fetched[i] = &feedFetch{entries: entries, err: err}
+		}
+	})
+
+	// An entry listed by several feeds is checked once
+	var entries []CrawlJob
+	for i, fetch := range fetched {
+		if fetch == nil {
+			continue
+		}
+		for _, entry := range fetch.entries {
+			if !slices.ContainsFunc(entries, func(job CrawlJob) bool { return job.URL == entry }) {
+				entries = append(entries, CrawlJob{
+					URL:        entry,
+					SourcePage: feeds[i].URL,
+					IsExternal: !urlutil.IsSameDomain(entry, startHost),
+					UserAgent:  c.userAgents.For(entry),
+				})
+			}
+		}
+	}
+	checked := make(map[string]*CrawlResult, len(entries))
+	results := make([]*CrawlResult, len(entries))
+	c.runProbes(ctx, entries, func(i int, entry CrawlJob) {
+		res := CheckURL(ctx, c.client, entry, c.cfg)
+		if ctx.Err() == nil {
+			results[i] = &res
+		}
+	})
+	for i, res := range results {
+		if res != nil {
+			c.recordCheck(*res)
+			checked[entries[i].URL] = res
+		}
+	}
+
+	var reports []result.Feed
+	for i, fetch := range fetched {
+		if fetch == nil {
+			continue
+		}
+		report := result.Feed{URL: feeds[i].URL, SourcePage: feeds[i].SourcePage}
+		if fetch.err != nil {
+			report.Error = fetch.err.Error()
+			reports = append(reports, report)
+			continue
+		}
+		for _, entry := range fetch.entries {
+			res, ok := checked[entry]
+			if !ok {
+				continue
+			}
+			report.Entries++
+			if res.Result != nil {
+				dead := *res.Result
+				dead.SourcePage = feeds[i].URL
+				report.DeadEntries = append(report.DeadEntries, dead)
+			}
+		}
+		reports = append(reports, report)
+	}
+	return reports
+}
+
+// runProbes calls probe for each job under the crawl's concurrency and rate
+// limits, and waits for them to return. If ctx is cancelled, unstarted jobs
+// are skipped.
+func (c *Crawler) runProbes(ctx context.Context, jobs []CrawlJob, probe func(i int, job CrawlJob)) {
+	slots := make(chan struct{}, c.cfg.Concurrency)
+	var wg sync.WaitGroup
+	for i, job := range jobs {
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		wg.Go(func() {
+			defer func() { <-slots }()
+			if c.limiter.Wait(ctx) != nil || c.hostLimiters.wait(ctx, c.cfg, job.URL) != nil {
+				return
+			}
+			probe(i, job)
+		})
+	}
+	wg.Wait()
+}
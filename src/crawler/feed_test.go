@@ -0,0 +1,118 @@
+package crawler
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/lukemcguire/zombiecrawl/result"
+)
+
+func TestFeedEntryLinks(t *testing.T) {
+	tests := []struct {
+		name string
+		feed string
+		want []string
+	}{
+		{
+			name: "rss 2.0",
+			feed: `<rss version="2.0"><channel><link>https://example.com/</link>
+				<item><link>https://example.com/post-1</link></item>
+				<item><link> /post-2 </link></item>
+				<item><title>no link</title></item>
+				<item><link>https://example.com/post-1</link></item></channel></rss>`,
+			want: []string{"https://example.com/post-1", "https://example.com/post-2"},
+		},
+		{
+			name: "rss 1.0",
+			feed: `<rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#" xmlns="http://purl.org/rss/1.0/">
+				<channel><link>https://example.com/</link></channel>
+				<item><link>https://example.com/post-1</link></item></rdf:RDF>`,
+			want: []string{"https://example.com/post-1"},
+		},
+		{
+			name: "atom",
+			feed: `<feed xmlns="http://www.w3.org/2005/Atom"><link rel="self" href="/atom.xml"/>
+				<entry><link rel="edit" href="/api/1"/><link href="/post-1"/></entry>
+				<entry><link rel="alternate" href="post-2"/></entry>
+				<entry><link rel="enclosure" href="/audio.mp3"/></entry>
+				<entry><link href="mailto:author@example.com"/></entry></feed>`,
+			want: []string{"https://example.com/post-1", "https://example.com/blog/post-2"},
+		},
+	}
+	feedURL, _ := url.Parse("https://example.com/blog/feed.xml")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var doc feedDocument
+			if err := xml.NewDecoder(strings.NewReader(tt.feed)).Decode(&doc); err != nil {
+				t.Fatalf("Decode() error: %v", err)
+			}
+			if got := doc.entryLinks(feedURL); !slices.Equal(got, tt.want) {
+				t.Errorf("entryLinks() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRun_CheckFeeds(t *testing.T) {
+	var ts *httptest.Server
+	ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/":
+			_, _ = fmt.Fprint(w, `<link rel="alternate" type="application/rss+xml" href="/feed.xml">
+				<link rel="alternate" type="application/atom+xml" href="/atom.xml"><a href="/post-1">post</a>`)
+		case "/about":
+			_, _ = fmt.Fprint(w, `<link rel="alternate" type="application/rss+xml" href="/feed.xml">`)
+		case "/post-1":
+			_, _ = fmt.Fprint(w, `<a href="/about">about</a>`)
+		case "/feed.xml":
+			_, _ = fmt.Fprintf(w, `<rss><channel><item><link>%[1]s/post-1</link></item><item><link>%[1]s/removed</link></item></channel></rss>`, ts.URL)
+		case "/atom.xml":
+			_, _ = fmt.Fprint(w, `<html><body>not a feed</body></html>`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	cfg := DefaultConfig(ts.URL)
+	cfg.Delay = 1
+	cfg.CheckFeeds = true
+	c, err := New(cfg, nil)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	res, err := c.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run() error: %v", err)
+	}
+
+	if len(res.BrokenLinks) != 0 {
+		t.Errorf("broken links = %+v, want dead feed entries reported apart", res.BrokenLinks)
+	}
+	if len(res.Feeds) != 2 {
+		t.Fatalf("feeds = %+v, want feed.xml and atom.xml", res.Feeds)
+	}
+	rss, atom := res.Feeds[0], res.Feeds[1]
+	if rss.URL != ts.URL+"/feed.xml" || rss.SourcePage != ts.URL+"/" || rss.Entries != 2 || rss.Error != "" {
+		t.Errorf("rss feed = %+v, want 2 entries checked, advertised on the start page", rss)
+	}
+	wantDead := []result.LinkResult{{URL: ts.URL + "/removed", StatusCode: http.StatusNotFound, SourcePage: ts.URL + "/feed.xml"}}
+	if len(rss.DeadEntries) != 1 || rss.DeadEntries[0].URL != wantDead[0].URL || rss.DeadEntries[0].StatusCode != wantDead[0].StatusCode ||
+		rss.DeadEntries[0].SourcePage != wantDead[0].SourcePage {
+		t.Errorf("dead entries = %+v, want %+v", rss.DeadEntries, wantDead)
+	}
+	if !strings.Contains(atom.Error, "unexpected root element <html>") || atom.Entries != 0 {
+		t.Errorf("atom feed = %+v, want a parse error", atom)
+	}
+	// 3 crawled pages and 2 feed entries
+	if res.Stats.TotalChecked != 5 {
+		t.Errorf("TotalChecked = %d, want 5", res.Stats.TotalChecked)
+	}
+}
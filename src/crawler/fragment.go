@@ -0,0 +1,100 @@
+package crawler
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/lukemcguire/zombiecrawl/result"
+	"golang.org/x/net/html"
+)
+
+// fragmentChecker collects the element ids of a page and its links to
+// fragments of the same page while it is tokenized, so links to ids that do
+// not exist can be flagged without another request. A nil *fragmentChecker
+// ignores every call.
+type fragmentChecker struct {
+	base  *url.URL
+	ids   map[string]bool
+	links []fragmentLink
+	seen  map[string]bool
+}
+
+// fragmentLink is a link from a page to a fragment of itself.
+type fragmentLink struct {
+	href     string
+	resolved string
+	fragment string
+}
+
+// newFragmentChecker returns a checker for the page at base.
+func newFragmentChecker(base *url.URL) *fragmentChecker {
+	return &fragmentChecker{base: base, ids: make(map[string]bool), seen: make(map[string]bool)}
+}
+
+// element records the id of a start tag, and the name of an <a>, which
+// fragments may also target.
+func (f *fragmentChecker) element(token html.Token) {
+	if f == nil {
+		return
+	}
+	if id, ok := attr(token, "id"); ok {
+		f.ids[id] = true
+	}
+	if token.Data == "a" {
+		if name, ok := attr(token, "name"); ok {
+			f.ids[name] = true
+		}
+	}
+}
+
+// link records href if it resolves to a fragment of the page itself.
+func (f *fragmentChecker) link(href string, resolved *url.URL) {
+	if f == nil || !checksFragment(resolved.Fragment) {
+		return
+	}
+	page := *resolved
+	page.Fragment, page.RawFragment = "", ""
+	base := *f.base
+	base.Fragment, base.RawFragment = "", ""
+	if page.String() != base.String() || f.seen[resolved.Fragment] {
+		return
+	}
+	f.seen[resolved.Fragment] = true
+	f.links = append(f.links, fragmentLink{href: href, resolved: resolved.String(), fragment: resolved.Fragment})
+}
+
+// results returns a warning for each fragment link whose target is missing,
+// attributed to sourcePage. It must be called once the whole page has been
+// tokenized, since links may precede their targets.
+func (f *fragmentChecker) results(sourcePage string) []result.HygieneWarning {
+	if f == nil {
+		return nil
+	}
+	var warnings []result.HygieneWarning
+	for _, link := range f.links {
+		if f.ids[link.fragment] {
+			continue
+		}
+		warnings = append(warnings, result.HygieneWarning{
+			Kind:       result.HygieneMissingFragment,
+			URL:        link.resolved,
+			Href:       link.href,
+			SourcePage: sourcePage,
+		})
+	}
+	return warnings
+}
+
+// checksFragment reports whether fragment should name an element. Empty
+// fragments and "top" scroll to the top of the page, "#/path" and "#!path"
+// are client-side routes, and ":~:" starts a text fragment.
+func checksFragment(fragment string) bool {
+	switch {
+	case fragment == "", strings.EqualFold(fragment, "top"):
+		return false
+	case strings.HasPrefix(fragment, "/"), strings.HasPrefix(fragment, "!"), strings.HasPrefix(fragment, ":~:"):
+		return false
+	default:
+		return true
+	}
+}
@@ -0,0 +1,96 @@
+package crawler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/lukemcguire/zombiecrawl/result"
+)
+
+func TestFragmentChecker(t *testing.T) {
+	page := `<html><body>
+<a href="#pricing">Pricing</a>
+<a href="#missing">Broken</a>
+<a href="#missing">Broken again</a>
+<a href="/docs/page#faq">Same page, absolute path</a>
+<a href="other#nowhere">Other page</a>
+<a href="#">Top</a>
+<a href="#top">Top</a>
+<a href="#/settings">Client-side route</a>
+<a href="#!inbox">Hashbang route</a>
+<a href="#:~:text=hello">Text fragment</a>
+<a href="#caf%C3%A9">Encoded</a>
+<a href="#later">Target after the link</a>
+<section id="pricing"></section>
+<a name="faq"></a>
+<h2 id="café">Café</h2>
+<div id="later"></div>
+</body></html>`
+	base, _ := url.Parse("https://example.com/docs/page")
+	fragments := newFragmentChecker(base)
+	if _, err := extractLinks(strings.NewReader(page), base, nil, nil, nil, fragments); err != nil {
+		t.Fatalf("extractLinks() error: %v", err)
+	}
+
+	warnings := fragments.results(base.String())
+	if len(warnings) != 1 {
+		t.Fatalf("warnings = %+v, want only #missing", warnings)
+	}
+	want := result.HygieneWarning{
+		Kind:       result.HygieneMissingFragment,
+		URL:        "https://example.com/docs/page#missing",
+		Href:       "#missing",
+		SourcePage: "https://example.com/docs/page",
+	}
+	if warnings[0] != want {
+		t.Errorf("warning = %+v, want %+v", warnings[0], want)
+	}
+}
+
+func TestFragmentChecker_Nil(t *testing.T) {
+	var fragments *fragmentChecker
+	target, _ := url.Parse("https://example.com/#x")
+	fragments.link("#x", target)
+	if got := fragments.results("https://example.com/"); got != nil {
+		t.Errorf("results() = %+v, want nil", got)
+	}
+}
+
+func TestRun_CheckFragments(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/":
+			_, _ = fmt.Fprint(w, `<a href="#intro">intro</a><a href="#gone">gone</a><a href="/about#team">team</a><p id="intro"></p>`)
+		case "/about":
+			_, _ = fmt.Fprint(w, `<h2 id="staff">Staff</h2>`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	cfg := DefaultConfig(ts.URL)
+	cfg.Delay = 1
+	cfg.CheckFragments = true
+	c, err := New(cfg, nil)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	res, err := c.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run() error: %v", err)
+	}
+
+	// Fragments of other pages are not checked, so /about#team is not flagged
+	if len(res.Hygiene) != 1 || res.Hygiene[0].Kind != result.HygieneMissingFragment || res.Hygiene[0].URL != ts.URL+"/#gone" {
+		t.Errorf("Hygiene = %+v, want only #gone", res.Hygiene)
+	}
+	if len(res.BrokenLinks) != 0 {
+		t.Errorf("missing fragments should not be broken links, got %+v", res.BrokenLinks)
+	}
+}
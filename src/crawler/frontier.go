@@ -0,0 +1,150 @@
+package crawler
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"slices"
+)
+
+// Strategy selects the order in which discovered URLs are crawled.
+type Strategy string
+
+const (
+	// StrategyBFS crawls level by level (the default).
+	StrategyBFS Strategy = "bfs"
+	// StrategyDFS follows each branch as deep as allowed before backtracking,
+	// which audits one deep section of a site quickly when combined with MaxDepth.
+	StrategyDFS Strategy = "dfs"
+	// StrategyRandom picks the next URL uniformly at random, spreading load
+	// across the site for load-testing-like coverage.
+	StrategyRandom Strategy = "random"
+)
+
+// ParseStrategy converts a user-supplied strategy name into a Strategy.
+// An empty name selects StrategyBFS.
+func ParseStrategy(name string) (Strategy, error) {
+	switch Strategy(name) {
+	case "", StrategyBFS:
+		return StrategyBFS, nil
+	case StrategyDFS, StrategyRandom:
+		return Strategy(name), nil
+	default:
+		return "", fmt.Errorf("unknown crawl strategy %q (want bfs, dfs, or random)", name)
+	}
+}
+
+// frontier holds discovered jobs that have not been dispatched to workers yet.
+// It is owned by the coordinator goroutine and is not safe for concurrent use.
+type frontier struct {
+	strategy Strategy
+	priority []CrawlJob // Jobs with Priority, dispatched first in queue order
+	jobs     []CrawlJob
+	head     int        // index of the next BFS job; jobs before head are consumed
+	rng      *rand.Rand // StrategyRandom's source; nil uses the global source
+	clock    Clock      // Stamps CrawlJob.Queued; nil is the system clock
+}
+
+// newFrontier creates an empty frontier using the given strategy.
+func newFrontier(strategy Strategy) *frontier {
+	return &frontier{strategy: strategy}
+}
+
+// seed makes StrategyRandom pick jobs in a repeatable order.
+func (f *frontier) seed(seed uint64) {
+	f.rng = rand.New(rand.NewPCG(seed, seed))
+}
+
+// Len returns the number of queued jobs.
+func (f *frontier) Len() int {
+	return len(f.priority) + len(f.jobs) - f.head
+}
+
+// Push adds a job to the frontier, stamping when it was queued.
+func (f *frontier) Push(job CrawlJob) {
+	if job.Queued.IsZero() {
+		job.Queued = clockOrSystem(f.clock).Now()
+	}
+	if job.Priority {
+		f.priority = append(f.priority, job)
+		return
+	}
+	f.jobs = append(f.jobs, job)
+}
+
+// Peek returns the job Pop would return next without removing it.
+// Jobs with priority come first, in the order they were queued. For
+// StrategyRandom the choice is made here and kept until Pop.
+func (f *frontier) Peek() (CrawlJob, bool) {
+	if len(f.priority) > 0 {
+		return f.priority[0], true
+	}
+	if f.Len() == 0 {
+		return CrawlJob{}, false
+	}
+	switch f.strategy {
+	case StrategyDFS:
+		return f.jobs[len(f.jobs)-1], true
+	case StrategyRandom:
+		// Move a random job to the tail so Pop can remove it cheaply
+		last := len(f.jobs) - 1
+		var pick int
+		if f.rng != nil {
+			pick = f.head + f.rng.IntN(f.Len())
+		} else {
+			pick = f.head + rand.IntN(f.Len())
+		}
+		f.jobs[pick], f.jobs[last] = f.jobs[last], f.jobs[pick]
+		return f.jobs[last], true
+	default:
+		return f.jobs[f.head], true
+	}
+}
+
+// Pop removes the job most recently returned by Peek.
+func (f *frontier) Pop() {
+	if len(f.priority) > 0 {
+		f.priority[0] = CrawlJob{}
+		f.priority = f.priority[1:]
+		return
+	}
+	if f.Len() == 0 {
+		return
+	}
+	switch f.strategy {
+	case StrategyDFS, StrategyRandom:
+		f.jobs[len(f.jobs)-1] = CrawlJob{}
+		f.jobs = f.jobs[:len(f.jobs)-1]
+	default:
+		f.jobs[f.head] = CrawlJob{}
+		f.head++
+		// Compact once the consumed prefix dominates the slice
+		if f.head > 1024 && f.head*2 > len(f.jobs) {
+			f.jobs = append(f.jobs[:0], f.jobs[f.head:]...)
+			f.head = 0
+		}
+	}
+	if f.Len() == 0 {
+		f.jobs = f.jobs[:0]
+		f.head = 0
+	}
+}
+
+// pending returns the queued jobs in the order they would be dispatched.
+// StrategyRandom jobs are returned in the order they were queued, since
+// their dispatch order is only chosen by Peek.
+func (f *frontier) pending() []CrawlJob {
+	queued := slices.Concat(f.priority, f.jobs[f.head:])
+	if f.strategy == StrategyDFS {
+		slices.Reverse(queued[len(f.priority):])
+	}
+	return queued
+}
+
+// Clear drops all queued jobs and returns how many were dropped.
+func (f *frontier) Clear() int {
+	dropped := f.Len()
+	f.priority = nil
+	f.jobs = nil
+	f.head = 0
+	return dropped
+}
@@ -0,0 +1,171 @@
+package crawler
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+)
+
+func TestParseStrategy(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    Strategy
+		wantErr bool
+	}{
+		{"", StrategyBFS, false},
+		{"bfs", StrategyBFS, false},
+		{"dfs", StrategyDFS, false},
+		{"random", StrategyRandom, false},
+		{"sideways", "", true},
+	}
+	for _, tt := range tests {
+		got, err := ParseStrategy(tt.name)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseStrategy(%q) error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+		if got != tt.want {
+			t.Errorf("ParseStrategy(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+// drain pops every job from the frontier and returns their URLs in order.
+func drain(f *frontier) []string {
+	var order []string
+	for {
+		job, ok := f.Peek()
+		if !ok {
+			return order
+		}
+		f.Pop()
+		order = append(order, job.URL)
+	}
+}
+
+func TestFrontier_BFSOrder(t *testing.T) {
+	f := newFrontier(StrategyBFS)
+	for _, u := range []string{"a", "b", "c"} {
+		f.Push(CrawlJob{URL: u})
+	}
+	if got := drain(f); len(got) != 3 || got[0] != "a" || got[1] != "b" || got[2] != "c" {
+		t.Errorf("BFS order = %v, want [a b c]", got)
+	}
+}
+
+func TestFrontier_DFSOrder(t *testing.T) {
+	f := newFrontier(StrategyDFS)
+	for _, u := range []string{"a", "b", "c"} {
+		f.Push(CrawlJob{URL: u})
+	}
+	if got := drain(f); len(got) != 3 || got[0] != "c" || got[1] != "b" || got[2] != "a" {
+		t.Errorf("DFS order = %v, want [c b a]", got)
+	}
+}
+
+func TestFrontier_PriorityFirst(t *testing.T) {
+	for _, strategy := range []Strategy{StrategyBFS, StrategyDFS} {
+		f := newFrontier(strategy)
+		f.Push(CrawlJob{URL: "a"})
+		f.Push(CrawlJob{URL: "p1", Priority: true})
+		f.Push(CrawlJob{URL: "b"})
+		f.Push(CrawlJob{URL: "p2", Priority: true})
+		if f.Len() != 4 {
+			t.Errorf("%s: Len() = %d, want 4", strategy, f.Len())
+		}
+		if got := drain(f); !slices.Equal(got[:2], []string{"p1", "p2"}) || len(got) != 4 {
+			t.Errorf("%s: order = %v, want p1 and p2 first", strategy, got)
+		}
+	}
+}
+
+func TestFrontier_Pending(t *testing.T) {
+	for strategy, want := range map[Strategy][]string{
+		StrategyBFS: {"p", "b", "c"},
+		StrategyDFS: {"p", "b", "a"},
+	} {
+		f := newFrontier(strategy)
+		for _, u := range []string{"a", "b", "c"} {
+			f.Push(CrawlJob{URL: u})
+		}
+		f.Push(CrawlJob{URL: "p", Priority: true})
+		// Dispatch the priority job and the next one, then queue p again
+		f.Peek()
+		f.Pop()
+		f.Peek()
+		f.Pop()
+		f.Push(CrawlJob{URL: "p", Priority: true})
+
+		var got []string
+		for _, job := range f.pending() {
+			got = append(got, job.URL)
+		}
+		if !slices.Equal(got, want) {
+			t.Errorf("%s: pending() = %v, want %v", strategy, got, want)
+		}
+		if drained := drain(f); !slices.Equal(drained, want) {
+			t.Errorf("%s: dispatch order %v differs from pending() %v", strategy, drained, want)
+		}
+	}
+}
+
+func TestFrontier_RandomReturnsEveryJob(t *testing.T) {
+	f := newFrontier(StrategyRandom)
+	want := map[string]bool{}
+	for _, u := range []string{"a", "b", "c", "d", "e"} {
+		f.Push(CrawlJob{URL: u})
+		want[u] = true
+	}
+	got := drain(f)
+	if len(got) != len(want) {
+		t.Fatalf("random drain returned %d jobs, want %d", len(got), len(want))
+	}
+	for _, u := range got {
+		if !want[u] {
+			t.Errorf("unexpected or duplicate job %q", u)
+		}
+		delete(want, u)
+	}
+}
+
+func TestFrontier_BFSCompaction(t *testing.T) {
+	f := newFrontier(StrategyBFS)
+	for i := range 3000 {
+		f.Push(CrawlJob{Depth: i})
+	}
+	for i := range 2500 {
+		job, _ := f.Peek()
+		if job.Depth != i {
+			t.Fatalf("job %d out of order: got depth %d", i, job.Depth)
+		}
+		f.Pop()
+	}
+	if f.Len() != 500 {
+		t.Errorf("Len() = %d, want 500", f.Len())
+	}
+	if job, _ := f.Peek(); job.Depth != 2500 {
+		t.Errorf("next job depth = %d, want 2500", job.Depth)
+	}
+	if dropped := f.Clear(); dropped != 500 || f.Len() != 0 {
+		t.Errorf("Clear() dropped %d (len %d), want 500 (len 0)", dropped, f.Len())
+	}
+}
+
+func TestFrontier_SeededRandomIsRepeatable(t *testing.T) {
+	order := func() []string {
+		f := newFrontier(StrategyRandom)
+		f.seed(7)
+		for i := range 20 {
+			f.Push(CrawlJob{URL: fmt.Sprintf("https://example.com/%d", i)})
+		}
+		var urls []string
+		for f.Len() > 0 {
+			job, _ := f.Peek()
+			f.Pop()
+			urls = append(urls, job.URL)
+		}
+		return urls
+	}
+	if first, second := order(), order(); !slices.Equal(first, second) {
+		t.Errorf("seeded random order changed between runs:\n%v\n%v", first, second)
+	}
+}
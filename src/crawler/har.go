@@ -0,0 +1,351 @@
+package crawler
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/lukemcguire/zombiecrawl/result"
+)
+
+// HARRecorder captures every request and response made during a crawl and
+// writes them in HTTP Archive (HAR) 1.2 format, for inspection in browser
+// devtools or HAR analyzers. Response bodies read by the crawler are stored
+// so the archive can be replayed with LoadReplay, except bodies of responses
+// marked noarchive, nosnippet, or none (see SetKeepNoArchive). URLs are
+// recorded in full unless SetScrubber redacts them.
+// A nil *HARRecorder records nothing. It is safe for concurrent use.
+type HARRecorder struct {
+	mu            sync.Mutex
+	entries       []harEntry
+	keepNoArchive bool
+	scrub         *result.Scrubber
+}
+
+// NewHARRecorder creates an empty recorder.
+func NewHARRecorder() *HARRecorder {
+	return &HARRecorder{}
+}
+
+// SetKeepNoArchive sets whether bodies of responses whose X-Robots-Tag
+// header or robots meta tag says noarchive, nosnippet, or none are stored.
+// By default they are left out of the archive, with a comment saying why.
+// Call it before the crawl starts.
+func (r *HARRecorder) SetKeepNoArchive(keep bool) {
+	r.keepNoArchive = keep
+}
+
+// SetScrubber makes the recorder redact the parameters scrub names from
+// every entry: request URLs and query strings, header values such as
+// Referer and Location, redirect targets, transport errors, and text
+// bodies. Base64 bodies are left as they are. Requests for redacted URLs
+// no longer match when the archive is replayed. Call it before the crawl
+// starts.
+func (r *HARRecorder) SetScrubber(scrub *result.Scrubber) {
+	r.scrub = scrub
+}
+
+// harLog is the top-level HAR document.
+type harLog struct {
+	Log struct {
+		Version string     `json:"version"`
+		Creator harCreator `json:"creator"`
+		Entries []harEntry `json:"entries"`
+	} `json:"log"`
+}
+
+type harCreator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type harEntry struct {
+	StartedDateTime time.Time   `json:"startedDateTime"`
+	Time            float64     `json:"time"`
+	Request         harRequest  `json:"request"`
+	Response        harResponse `json:"response"`
+	Cache           struct{}    `json:"cache"`
+	Timings         harTimings  `json:"timings"`
+	Error           string      `json:"_error,omitempty"` // Transport error, if no response was received
+}
+
+type harRequest struct {
+	Method      string         `json:"method"`
+	URL         string         `json:"url"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []harNameValue `json:"cookies"`
+	Headers     []harNameValue `json:"headers"`
+	QueryString []harNameValue `json:"queryString"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+type harResponse struct {
+	Status      int            `json:"status"`
+	StatusText  string         `json:"statusText"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []harNameValue `json:"cookies"`
+	Headers     []harNameValue `json:"headers"`
+	Content     harContent     `json:"content"`
+	RedirectURL string         `json:"redirectURL"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int64          `json:"bodySize"`
+}
+
+type harContent struct {
+	Size     int64  `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text,omitempty"`
+	Encoding string `json:"encoding,omitempty"` // "base64" when Text is not UTF-8
+	Comment  string `json:"comment,omitempty"`  // Why Text was left out, if it was
+}
+
+type harNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// harTimings splits the entry time. Phases the recorder cannot observe are -1,
+// as the format specifies.
+type harTimings struct {
+	Blocked float64 `json:"blocked"`
+	DNS     float64 `json:"dns"`
+	Connect float64 `json:"connect"`
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+}
+
+// wrap returns a RoundTripper that records through r, timing entries on
+// clock, or base itself if r is nil or base already records through r. A
+// nil base uses http.DefaultTransport.
+func (r *HARRecorder) wrap(base http.RoundTripper, clock Clock) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if r == nil {
+		return base
+	}
+	if t, ok := base.(*harTransport); ok && t.recorder == r {
+		return base
+	}
+	return &harTransport{base: base, recorder: r, clock: clock}
+}
+
+// add appends a completed entry, redacted by the recorder's Scrubber.
+func (r *HARRecorder) add(entry harEntry) {
+	if r.scrub != nil {
+		r.redact(&entry)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries = append(r.entries, entry)
+}
+
+// Len returns the number of recorded entries.
+func (r *HARRecorder) Len() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.entries)
+}
+
+// Write encodes the recorded entries as a HAR 1.2 document, ordered by start time.
+func (r *HARRecorder) Write(w io.Writer) error {
+	r.mu.Lock()
+	entries := slices.Clone(r.entries)
+	r.mu.Unlock()
+	slices.SortStableFunc(entries, func(a, b harEntry) int { return a.StartedDateTime.Compare(b.StartedDateTime) })
+
+	var doc harLog
+	doc.Log.Version = "1.2"
+	doc.Log.Creator = harCreator{Name: "zombiecrawl", Version: "1.0"}
+	doc.Log.Entries = entries
+	if doc.Log.Entries == nil {
+		doc.Log.Entries = []harEntry{}
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return fmt.Errorf("write har: %w", err)
+	}
+	return nil
+}
+
+// WriteFile writes the HAR document to path.
+func (r *HARRecorder) WriteFile(path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("create har file: %w", err)
+	}
+	if err := r.Write(file); err != nil {
+		_ = file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("close har file: %w", err)
+	}
+	return nil
+}
+
+// harTransport records each round trip. Entries for successful round trips
+// are completed when the response body is closed, so receive time and body
+// size are known.
+type harTransport struct {
+	base     http.RoundTripper
+	recorder *HARRecorder
+	clock    Clock
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *harTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	started := t.clock.Now()
+	resp, err := t.base.RoundTrip(req)
+	waited := t.clock.Now().Sub(started)
+
+	entry := harEntry{
+		StartedDateTime: started.UTC(),
+		Request: harRequest{
+			Method:      req.Method,
+			URL:         req.URL.String(),
+			HTTPVersion: req.Proto,
+			Cookies:     []harNameValue{},
+			Headers:     harHeaders(req.Header),
+			QueryString: harQuery(req.URL.Query()),
+			HeadersSize: -1,
+			BodySize:    -1,
+		},
+		Response: harResponse{
+			Cookies:     []harNameValue{},
+			Headers:     []harNameValue{},
+			HeadersSize: -1,
+			BodySize:    -1,
+			Content:     harContent{Size: -1},
+		},
+		Timings: harTimings{Blocked: -1, DNS: -1, Connect: -1, Send: 0, Wait: millis(waited), Receive: 0},
+		Time:    millis(waited),
+	}
+	if entry.Request.HTTPVersion == "" {
+		entry.Request.HTTPVersion = "HTTP/1.1"
+	}
+
+	if err != nil {
+		entry.Error = err.Error()
+		t.recorder.add(entry)
+		return nil, err
+	}
+
+	entry.Response.Status = resp.StatusCode
+	entry.Response.StatusText = http.StatusText(resp.StatusCode)
+	entry.Response.HTTPVersion = resp.Proto
+	entry.Response.Headers = harHeaders(resp.Header)
+	entry.Response.RedirectURL = resp.Header.Get("Location")
+	entry.Response.Content.MimeType = resp.Header.Get("Content-Type")
+	resp.Body = &harBody{ReadCloser: resp.Body, entry: entry, header: resp.Header, recorder: t.recorder, clock: t.clock, received: started.Add(waited)}
+	return resp, nil
+}
+
+// harBody keeps the body bytes read and completes the entry on Close.
+type harBody struct {
+	io.ReadCloser
+	entry    harEntry
+	header   http.Header
+	recorder *HARRecorder
+	clock    Clock
+	received time.Time
+	body     bytes.Buffer
+	once     sync.Once
+}
+
+// Read implements io.Reader.
+func (b *harBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.body.Write(p[:n])
+	return n, err
+}
+
+// Close implements io.Closer.
+func (b *harBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(func() {
+		receive := b.clock.Now().Sub(b.received)
+		b.entry.Timings.Receive = millis(receive)
+		b.entry.Time += millis(receive)
+		size := int64(b.body.Len())
+		b.entry.Response.BodySize = size
+		b.entry.Response.Content.Size = size
+		directive := ""
+		if !b.recorder.keepNoArchive {
+			directive = noArchive(b.header, b.body.Bytes())
+		}
+		switch {
+		case directive != "":
+			b.entry.Response.Content.Comment = "body omitted: response is marked " + directive
+		case utf8.Valid(b.body.Bytes()):
+			b.entry.Response.Content.Text = b.body.String()
+		default:
+			b.entry.Response.Content.Text = base64.StdEncoding.EncodeToString(b.body.Bytes())
+			b.entry.Response.Content.Encoding = "base64"
+		}
+		b.recorder.add(b.entry)
+	})
+	return err
+}
+
+// redact applies the recorder's Scrubber to entry in place.
+func (r *HARRecorder) redact(entry *harEntry) {
+	entry.Request.URL = r.scrub.String(entry.Request.URL)
+	if parsed, err := url.Parse(entry.Request.URL); err == nil {
+		entry.Request.QueryString = harQuery(parsed.Query())
+	}
+	for _, headers := range [][]harNameValue{entry.Request.Headers, entry.Response.Headers} {
+		for i := range headers {
+			headers[i].Value = r.scrub.String(headers[i].Value)
+		}
+	}
+	entry.Response.RedirectURL = r.scrub.String(entry.Response.RedirectURL)
+	entry.Error = r.scrub.String(entry.Error)
+	if entry.Response.Content.Encoding == "" {
+		entry.Response.Content.Text = r.scrub.String(entry.Response.Content.Text)
+	}
+}
+
+// harHeaders converts headers to HAR name/value pairs in a stable order.
+func harHeaders(h http.Header) []harNameValue {
+	pairs := make([]harNameValue, 0, len(h))
+	for name, values := range h {
+		for _, value := range values {
+			pairs = append(pairs, harNameValue{Name: name, Value: value})
+		}
+	}
+	slices.SortStableFunc(pairs, func(a, b harNameValue) int { return strings.Compare(a.Name, b.Name) })
+	return pairs
+}
+
+// harQuery converts query parameters to HAR name/value pairs.
+func harQuery(query url.Values) []harNameValue {
+	pairs := []harNameValue{}
+	for name, values := range query {
+		for _, value := range values {
+			pairs = append(pairs, harNameValue{Name: name, Value: value})
+		}
+	}
+	slices.SortStableFunc(pairs, func(a, b harNameValue) int { return strings.Compare(a.Name, b.Name) })
+	return pairs
+}
+
+// millis converts a duration to fractional milliseconds.
+func millis(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
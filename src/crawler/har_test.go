@@ -0,0 +1,168 @@
+package crawler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/lukemcguire/zombiecrawl/result"
+)
+
+func TestHARRecorder_NilWrapIsBase(t *testing.T) {
+	var r *HARRecorder
+	if got := r.wrap(nil, systemClock{}); got != http.DefaultTransport {
+		t.Errorf("nil recorder wrap(nil) = %T, want http.DefaultTransport", got)
+	}
+}
+
+func TestHARRecorder_WrapOnce(t *testing.T) {
+	r := NewHARRecorder()
+	wrapped := r.wrap(nil, systemClock{})
+	if again := r.wrap(wrapped, systemClock{}); again != wrapped {
+		t.Error("wrapping a recording transport twice should not record twice")
+	}
+}
+
+const harTestPage = "<html><body>moved</body></html>"
+
+func TestCheckURLRecordsHAR(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/old":
+			http.Redirect(w, r, "/new?ref=old", http.StatusMovedPermanently)
+		default:
+			w.Header().Set("Content-Type", "text/html")
+			_, _ = w.Write([]byte(harTestPage))
+		}
+	}))
+	defer ts.Close()
+
+	rec := NewHARRecorder()
+	cfg := DefaultConfig(ts.URL)
+	cfg.HAR = rec
+	CheckURL(context.Background(), &http.Client{}, CrawlJob{URL: ts.URL + "/old"}, cfg)
+
+	var buf bytes.Buffer
+	if err := rec.Write(&buf); err != nil {
+		t.Fatalf("Write() error: %v", err)
+	}
+	var doc harLog
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("invalid HAR JSON: %v", err)
+	}
+	if doc.Log.Version != "1.2" {
+		t.Errorf("version = %q, want 1.2", doc.Log.Version)
+	}
+	if len(doc.Log.Entries) != 2 {
+		t.Fatalf("got %d entries, want 2 (redirect and final)", len(doc.Log.Entries))
+	}
+
+	first, last := doc.Log.Entries[0], doc.Log.Entries[1]
+	if first.Response.Status != http.StatusMovedPermanently || first.Response.RedirectURL != "/new?ref=old" {
+		t.Errorf("first entry = %d -> %q, want 301 -> /new?ref=old", first.Response.Status, first.Response.RedirectURL)
+	}
+	if last.Request.Method != http.MethodGet || last.Response.Status != http.StatusOK {
+		t.Errorf("last entry = %s %d, want GET 200", last.Request.Method, last.Response.Status)
+	}
+	if len(last.Request.QueryString) != 1 || last.Request.QueryString[0] != (harNameValue{Name: "ref", Value: "old"}) {
+		t.Errorf("queryString = %v, want ref=old", last.Request.QueryString)
+	}
+	if last.Response.Content.Size != int64(len(harTestPage)) || last.Response.Content.MimeType != "text/html" {
+		t.Errorf("content = %+v, want %d bytes of text/html", last.Response.Content, len(harTestPage))
+	}
+	if last.Timings.Wait < 0 || last.Time < last.Timings.Wait {
+		t.Errorf("timings = %+v, time = %v", last.Timings, last.Time)
+	}
+}
+
+func TestHARRecorder_Scrubber(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/old":
+			http.Redirect(w, r, "/new?token=secret&ref=old", http.StatusMovedPermanently)
+		default:
+			w.Header().Set("Content-Type", "text/html")
+			w.Header().Set("Content-Location", "/new?token=secret")
+			_, _ = w.Write([]byte(`<a href="/next?ref=a&amp;token=secret">next</a>`))
+		}
+	}))
+	defer ts.Close()
+
+	rec := NewHARRecorder()
+	rec.SetScrubber(result.NewScrubber([]string{"token"}))
+	cfg := DefaultConfig(ts.URL)
+	cfg.HAR = rec
+	CheckURL(context.Background(), &http.Client{}, CrawlJob{URL: ts.URL + "/old"}, cfg)
+
+	var buf bytes.Buffer
+	if err := rec.Write(&buf); err != nil {
+		t.Fatalf("Write() error: %v", err)
+	}
+	if strings.Contains(buf.String(), "secret") {
+		t.Errorf("HAR contains the scrubbed token:\n%s", buf.String())
+	}
+	var doc harLog
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("invalid HAR JSON: %v", err)
+	}
+	last := doc.Log.Entries[len(doc.Log.Entries)-1]
+	if !strings.HasSuffix(last.Request.URL, "/new?token=REDACTED&ref=old") {
+		t.Errorf("request URL = %q, want the token redacted", last.Request.URL)
+	}
+	if !slices.Contains(last.Request.QueryString, harNameValue{Name: "token", Value: result.Redacted}) {
+		t.Errorf("queryString = %v, want token redacted", last.Request.QueryString)
+	}
+}
+
+func TestHARRecorder_OmitsNoArchiveBodies(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte(`<head><meta name="robots" content="noarchive"></head><body>private</body>`))
+	}))
+	defer ts.Close()
+
+	for _, keep := range []bool{false, true} {
+		rec := NewHARRecorder()
+		rec.SetKeepNoArchive(keep)
+		cfg := DefaultConfig(ts.URL)
+		cfg.HAR = rec
+		CheckURL(context.Background(), &http.Client{}, CrawlJob{URL: ts.URL + "/"}, cfg)
+
+		rec.mu.Lock()
+		content := rec.entries[0].Response.Content
+		rec.mu.Unlock()
+		if stored := content.Text != ""; stored != keep {
+			t.Errorf("keep %v: content = %+v, want body stored %v", keep, content, keep)
+		}
+		if !keep && content.Comment != "body omitted: response is marked noarchive" {
+			t.Errorf("comment = %q, want the omission explained", content.Comment)
+		}
+	}
+}
+
+type failingTransport struct{}
+
+func (failingTransport) RoundTrip(*http.Request) (*http.Response, error) {
+	return nil, errors.New("connection refused")
+}
+
+func TestHARRecorder_RecordsTransportErrors(t *testing.T) {
+	rec := NewHARRecorder()
+	client := &http.Client{Transport: rec.wrap(failingTransport{}, systemClock{})}
+	if _, err := client.Get("http://unreachable.example/"); err == nil {
+		t.Fatal("expected transport error")
+	}
+	if rec.Len() != 1 {
+		t.Fatalf("Len() = %d, want 1", rec.Len())
+	}
+	entry := rec.entries[0]
+	if entry.Response.Status != 0 || entry.Error != "connection refused" {
+		t.Errorf("entry = status %d error %q, want 0 and the transport error", entry.Response.Status, entry.Error)
+	}
+}
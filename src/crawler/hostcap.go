@@ -0,0 +1,55 @@
+package crawler
+
+import (
+	"context"
+	"sync"
+)
+
+// hostSlots caps the requests in flight to any one host, so a crawl with
+// many workers still hits each server with at most limit at a time. A nil
+// *hostSlots places no limit.
+type hostSlots struct {
+	limit int
+	mu    sync.Mutex
+	hosts map[string]chan struct{}
+}
+
+// newHostSlots returns slots allowing limit requests per host, or nil if
+// limit is not positive.
+func newHostSlots(limit int) *hostSlots {
+	if limit <= 0 {
+		return nil
+	}
+	return &hostSlots{limit: limit, hosts: make(map[string]chan struct{})}
+}
+
+// acquire blocks until a slot for host is free or ctx is done.
+func (s *hostSlots) acquire(ctx context.Context, host string) error {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	slots, ok := s.hosts[host]
+	if !ok {
+		slots = make(chan struct{}, s.limit)
+		s.hosts[host] = slots
+	}
+	s.mu.Unlock()
+	select {
+	case slots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// release returns a slot for host taken by acquire.
+func (s *hostSlots) release(host string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	slots := s.hosts[host]
+	s.mu.Unlock()
+	<-slots
+}
@@ -0,0 +1,85 @@
+package crawler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestHostSlots_AcquireHonoursContext(t *testing.T) {
+	slots := newHostSlots(1)
+	if err := slots.acquire(context.Background(), "a.example"); err != nil {
+		t.Fatalf("first acquire returned %v", err)
+	}
+	// Other hosts have slots of their own
+	if err := slots.acquire(context.Background(), "b.example"); err != nil {
+		t.Fatalf("acquire for another host returned %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := slots.acquire(ctx, "a.example"); err == nil {
+		t.Fatal("expected second acquire for a full host to fail when ctx expires")
+	}
+
+	slots.release("a.example")
+	if err := slots.acquire(context.Background(), "a.example"); err != nil {
+		t.Fatalf("acquire after release returned %v", err)
+	}
+
+	var unlimited *hostSlots
+	if newHostSlots(0) != nil || unlimited.acquire(context.Background(), "a.example") != nil {
+		t.Error("a zero limit should give nil slots that never block")
+	}
+	unlimited.release("a.example")
+}
+
+func TestRun_MaxPerHost(t *testing.T) {
+	var inFlight, peak atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			http.NotFound(w, r)
+			return
+		}
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		if r.URL.Path == "/" {
+			for i := range 12 {
+				_, _ = fmt.Fprintf(w, `<a href="/%d">%d</a>`, i, i)
+			}
+		}
+	}))
+	defer ts.Close()
+
+	cfg := DefaultConfig(ts.URL)
+	cfg.Concurrency = 8
+	cfg.MaxPerHost = 2
+	cfg.Delay = 1
+	cfg.DisableAutoTune = true
+	c, err := New(cfg, nil)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	res, err := c.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run() error: %v", err)
+	}
+
+	if res.Stats.TotalChecked != 13 {
+		t.Errorf("TotalChecked = %d, want 13", res.Stats.TotalChecked)
+	}
+	if got := peak.Load(); got > 2 {
+		t.Errorf("peak requests in flight = %d, want at most MaxPerHost 2", got)
+	}
+}
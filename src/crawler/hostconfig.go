@@ -0,0 +1,218 @@
+package crawler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+	"time"
+)
+
+// RobotsOverride changes whether robots.txt is consulted for the hosts of
+// a HostConfig.
+type RobotsOverride string
+
+const (
+	// RobotsInherit keeps the crawl-wide robots.txt behavior (the default).
+	RobotsInherit RobotsOverride = ""
+	// RobotsRespect consults robots.txt, even for external links.
+	RobotsRespect RobotsOverride = "respect"
+	// RobotsIgnore skips robots.txt.
+	RobotsIgnore RobotsOverride = "ignore"
+)
+
+// ParseRobotsOverride converts a user-supplied robots setting into a
+// RobotsOverride. An empty name selects RobotsInherit.
+func ParseRobotsOverride(name string) (RobotsOverride, error) {
+	switch RobotsOverride(name) {
+	case RobotsInherit, RobotsRespect, RobotsIgnore:
+		return RobotsOverride(name), nil
+	default:
+		return "", fmt.Errorf("unknown robots setting %q (want respect or ignore)", name)
+	}
+}
+
+// HostConfig overrides crawl settings for hosts matching Pattern, for crawls
+// that touch both your own site and strict third-party services. Pattern
+// uses path.Match glob syntax against the lowercase hostname, like
+// HostUserAgent.Pattern.
+type HostConfig struct {
+	Pattern       string
+	RatePerMinute float64           // Fixed rate for matching hosts, applied on top of the crawl's rate (0 = crawl rate only)
+	Headers       map[string]string // Extra request headers, e.g. API keys; they replace headers the crawl would send
+	Username      string            // HTTP basic auth user (empty = no basic auth)
+	Password      string            // HTTP basic auth password
+	RetryPolicy   *RetryPolicy      // Retry policy for matching hosts (nil = Config.RetryPolicy)
+	Robots        RobotsOverride    // Whether robots.txt is consulted for matching hosts
+}
+
+// hostConfig returns the first of cfg.HostConfigs matching rawURL's host, or
+// nil if none does.
+func (cfg Config) hostConfig(rawURL string) *HostConfig {
+	if i := cfg.hostConfigIndex(rawURL); i >= 0 {
+		return &cfg.HostConfigs[i]
+	}
+	return nil
+}
+
+// hostConfigIndex returns the index of the first of cfg.HostConfigs matching
+// rawURL's host, or -1 if none does.
+func (cfg Config) hostConfigIndex(rawURL string) int {
+	if len(cfg.HostConfigs) == 0 {
+		return -1
+	}
+	host := strings.ToLower(hostFromURL(rawURL))
+	for i, hc := range cfg.HostConfigs {
+		if matched, _ := path.Match(strings.ToLower(hc.Pattern), host); matched {
+			return i
+		}
+	}
+	return -1
+}
+
+// retryPolicy returns the retry policy for rawURL: its host's override, else
+// Config.RetryPolicy.
+func (cfg Config) retryPolicy(rawURL string) RetryPolicy {
+	if hc := cfg.hostConfig(rawURL); hc != nil && hc.RetryPolicy != nil {
+		return *hc.RetryPolicy
+	}
+	return cfg.RetryPolicy
+}
+
+// applyHostHeaders sets the headers and basic auth credentials configured
+// for req's host.
+func (cfg Config) applyHostHeaders(req *http.Request) {
+	hc := cfg.hostConfig(req.URL.String())
+	if hc == nil {
+		return
+	}
+	for name, value := range hc.Headers {
+		req.Header.Set(name, value)
+	}
+	if hc.Username != "" {
+		req.SetBasicAuth(hc.Username, hc.Password)
+	}
+}
+
+// hostLimiters holds the separate rate limiters of HostConfigs that set
+// RatePerMinute, indexed like Config.HostConfigs.
+type hostLimiters []*AdaptiveLimiter
+
+// newHostLimiters validates cfg's host patterns and creates a fixed-rate
+// limiter for each host config with a rate.
+func newHostLimiters(cfg Config) (hostLimiters, error) {
+	limiters := make(hostLimiters, len(cfg.HostConfigs))
+	for i, hc := range cfg.HostConfigs {
+		if _, err := path.Match(hc.Pattern, ""); err != nil {
+			return nil, fmt.Errorf("host config pattern %q: %w", hc.Pattern, err)
+		}
+		if hc.RatePerMinute <= 0 {
+			continue
+		}
+		limiter := NewAdaptiveLimiter(1, 200*time.Millisecond)
+		limiter.SetFixedRate(hc.RatePerMinute/60, 1)
+		if cfg.Clock != nil {
+			limiter.SetClock(cfg.Clock)
+		}
+		limiters[i] = limiter
+	}
+	return limiters, nil
+}
+
+// wait blocks until the rate limit of rawURL's host config allows a
+// request. Hosts without a configured rate return immediately.
+func (l hostLimiters) wait(ctx context.Context, cfg Config, rawURL string) error {
+	if i := cfg.hostConfigIndex(rawURL); i >= 0 && l[i] != nil {
+		return l[i].Wait(ctx)
+	}
+	return nil
+}
+
+// hostConfigFile is the JSON form of host configs read by LoadHostConfigs.
+type hostConfigFile struct {
+	Hosts []struct {
+		Host          string            `json:"host"`
+		RatePerMinute float64           `json:"rate_per_minute"`
+		Headers       map[string]string `json:"headers"`
+		BasicAuth     *struct {
+			Username string `json:"username"`
+			Password string `json:"password"`
+		} `json:"basic_auth"`
+		Retries    *int   `json:"retries"`
+		RetryDelay string `json:"retry_delay"`
+		Robots     string `json:"robots"`
+	} `json:"hosts"`
+}
+
+// LoadHostConfigs reads host configs from a JSON file such as
+//
+//	{"hosts": [{"host": "api.example.com", "rate_per_minute": 30,
+//	            "headers": {"X-Api-Key": "$API_KEY"},
+//	            "basic_auth": {"username": "ci", "password": "${API_PASSWORD}"},
+//	            "retries": 5, "retry_delay": "10s", "robots": "ignore"}]}
+//
+// Sections keep their order, so the first one matching a host applies.
+// Header values and credentials may reference environment variables, so
+// secrets need not be stored in the file. A section that sets retries or
+// retry_delay replaces the crawl's retry policy with base, overriding the
+// fields it sets.
+func LoadHostConfigs(r io.Reader, base RetryPolicy) ([]HostConfig, error) {
+	var file hostConfigFile
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&file); err != nil {
+		return nil, fmt.Errorf("read host config: %w", err)
+	}
+
+	configs := make([]HostConfig, 0, len(file.Hosts))
+	for i, section := range file.Hosts {
+		if section.Host == "" {
+			return nil, fmt.Errorf("host config section %d: missing host", i+1)
+		}
+		hc := HostConfig{Pattern: section.Host, RatePerMinute: section.RatePerMinute}
+		if section.RatePerMinute < 0 {
+			return nil, fmt.Errorf("host config %q: rate_per_minute must not be negative", section.Host)
+		}
+		for name, value := range section.Headers {
+			if hc.Headers == nil {
+				hc.Headers = make(map[string]string, len(section.Headers))
+			}
+			hc.Headers[name] = os.ExpandEnv(value)
+		}
+		if section.BasicAuth != nil {
+			hc.Username = os.ExpandEnv(section.BasicAuth.Username)
+			hc.Password = os.ExpandEnv(section.BasicAuth.Password)
+		}
+		if section.Retries != nil || section.RetryDelay != "" {
+			policy := base
+			if section.Retries != nil {
+				if *section.Retries < 0 {
+					return nil, fmt.Errorf("host config %q: retries must not be negative", section.Host)
+				}
+				policy.MaxRetries = *section.Retries
+			}
+			if section.RetryDelay != "" {
+				delay, err := time.ParseDuration(section.RetryDelay)
+				if err != nil {
+					return nil, fmt.Errorf("host config %q: retry_delay: %w", section.Host, err)
+				}
+				policy.BaseDelay = delay
+			}
+			hc.RetryPolicy = &policy
+		}
+		robots, err := ParseRobotsOverride(section.Robots)
+		if err != nil {
+			return nil, fmt.Errorf("host config %q: %w", section.Host, err)
+		}
+		hc.Robots = robots
+		if _, err := path.Match(hc.Pattern, ""); err != nil {
+			return nil, fmt.Errorf("host config pattern %q: %w", hc.Pattern, err)
+		}
+		configs = append(configs, hc)
+	}
+	return configs, nil
+}
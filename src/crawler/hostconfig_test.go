@@ -0,0 +1,169 @@
+package crawler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLoadHostConfigs(t *testing.T) {
+	t.Setenv("TEST_API_KEY", "s3cret")
+	input := `{"hosts": [
+		{"host": "api.example.com", "rate_per_minute": 30, "headers": {"X-Api-Key": "$TEST_API_KEY"},
+		 "basic_auth": {"username": "ci", "password": "${TEST_API_KEY}"}, "retries": 5, "robots": "ignore"},
+		{"host": "*.example.com", "retry_delay": "10s"},
+		{"host": "cdn.example.net"}
+	]}`
+	base := RetryPolicy{MaxRetries: 2, BaseDelay: time.Second, MaxDelay: 30 * time.Second}
+	configs, err := LoadHostConfigs(strings.NewReader(input), base)
+	if err != nil {
+		t.Fatalf("LoadHostConfigs() error: %v", err)
+	}
+	if len(configs) != 3 {
+		t.Fatalf("got %d configs, want 3", len(configs))
+	}
+
+	api := configs[0]
+	if api.Pattern != "api.example.com" || api.RatePerMinute != 30 || api.Robots != RobotsIgnore {
+		t.Errorf("api config = %+v", api)
+	}
+	if api.Headers["X-Api-Key"] != "s3cret" || api.Username != "ci" || api.Password != "s3cret" {
+		t.Errorf("environment variables not expanded: %+v", api)
+	}
+	if want := (RetryPolicy{MaxRetries: 5, BaseDelay: time.Second, MaxDelay: 30 * time.Second}); api.RetryPolicy == nil || *api.RetryPolicy != want {
+		t.Errorf("api RetryPolicy = %v, want %v", api.RetryPolicy, want)
+	}
+	if want := (RetryPolicy{MaxRetries: 2, BaseDelay: 10 * time.Second, MaxDelay: 30 * time.Second}); configs[1].RetryPolicy == nil || *configs[1].RetryPolicy != want {
+		t.Errorf("wildcard RetryPolicy = %v, want %v", configs[1].RetryPolicy, want)
+	}
+	if configs[2].RetryPolicy != nil || configs[2].Robots != RobotsInherit {
+		t.Errorf("empty section should inherit everything, got %+v", configs[2])
+	}
+
+	cfg := Config{RetryPolicy: base, HostConfigs: configs}
+	for rawURL, want := range map[string]int{
+		"https://api.example.com/v1": 0, // First match wins over *.example.com
+		"https://www.example.com/":   1,
+		"https://example.org/":       -1,
+	} {
+		if got := cfg.hostConfigIndex(rawURL); got != want {
+			t.Errorf("hostConfigIndex(%q) = %d, want %d", rawURL, got, want)
+		}
+	}
+	if got := cfg.retryPolicy("https://example.org/"); got != base {
+		t.Errorf("retryPolicy() for an unmatched host = %v, want the crawl's", got)
+	}
+}
+
+func TestLoadHostConfigs_Errors(t *testing.T) {
+	for name, input := range map[string]string{
+		"not json":          `hosts:`,
+		"unknown field":     `{"hosts": [{"host": "a", "rate": 1}]}`,
+		"missing host":      `{"hosts": [{"retries": 1}]}`,
+		"bad pattern":       `{"hosts": [{"host": "[a"}]}`,
+		"bad retry delay":   `{"hosts": [{"host": "a", "retry_delay": "soon"}]}`,
+		"negative retries":  `{"hosts": [{"host": "a", "retries": -1}]}`,
+		"negative rate":     `{"hosts": [{"host": "a", "rate_per_minute": -5}]}`,
+		"bad robots choice": `{"hosts": [{"host": "a", "robots": "sometimes"}]}`,
+	} {
+		if _, err := LoadHostConfigs(strings.NewReader(input), DefaultRetryPolicy()); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestHostLimiters_OnlyLimitMatchingHosts(t *testing.T) {
+	clock := newFakeClock()
+	cfg := Config{
+		Clock:       clock,
+		HostConfigs: []HostConfig{{Pattern: "api.example.com", RatePerMinute: 6}, {Pattern: "*.example.com"}},
+	}
+	limiters, err := newHostLimiters(cfg)
+	if err != nil {
+		t.Fatalf("newHostLimiters() error: %v", err)
+	}
+	for range 3 {
+		for _, rawURL := range []string{"https://api.example.com/", "https://www.example.com/", "https://example.org/"} {
+			if err := limiters.wait(context.Background(), cfg, rawURL); err != nil {
+				t.Fatalf("wait(%s) error: %v", rawURL, err)
+			}
+		}
+	}
+	want := []time.Duration{10 * time.Second, 10 * time.Second}
+	if got := clock.sleeps(); !slices.Equal(got, want) {
+		t.Errorf("sleeps = %v, want %v", got, want)
+	}
+
+	if _, err := newHostLimiters(Config{HostConfigs: []HostConfig{{Pattern: "[bad"}}}); err == nil {
+		t.Error("expected error for an invalid pattern")
+	}
+}
+
+func TestRun_HostConfigs(t *testing.T) {
+	var mu sync.Mutex
+	var apiRequests []*http.Request
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		apiRequests = append(apiRequests, r)
+		mu.Unlock()
+		switch r.URL.Path {
+		case "/robots.txt":
+			_, _ = fmt.Fprint(w, "User-agent: *\nDisallow: /private\n")
+		default:
+			_, _ = fmt.Fprint(w, "ok")
+		}
+	}))
+	defer api.Close()
+	// The API is reached as localhost, so only it matches the host config
+	apiURL := strings.Replace(api.URL, "127.0.0.1", "localhost", 1)
+
+	site := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Api-Key") != "" {
+			t.Errorf("API key sent to the site: %s", r.URL)
+		}
+		_, _ = fmt.Fprintf(w, `<a href="%[1]s/v1/status">status</a><a href="%[1]s/private/admin">admin</a>`, apiURL)
+	}))
+	defer site.Close()
+
+	cfg := DefaultConfig(site.URL)
+	cfg.Delay = 1
+	cfg.IgnoreRobots = true
+	cfg.HostConfigs = []HostConfig{{
+		Pattern:  "localhost",
+		Headers:  map[string]string{"X-Api-Key": "s3cret", "User-Agent": "api-client/1.0"},
+		Username: "ci",
+		Password: "pw",
+		Robots:   RobotsRespect,
+	}}
+	c, err := New(cfg, nil)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	if _, err := c.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	var paths []string
+	for _, r := range apiRequests {
+		paths = append(paths, r.URL.Path)
+		if r.URL.Path == "/robots.txt" {
+			continue
+		}
+		user, pass, ok := r.BasicAuth()
+		if r.Header.Get("X-Api-Key") != "s3cret" || r.UserAgent() != "api-client/1.0" || !ok || user != "ci" || pass != "pw" {
+			t.Errorf("request to %s missing host config headers: %v", r.URL, r.Header)
+		}
+	}
+	// robots.txt is respected for the API despite IgnoreRobots
+	if slices.Contains(paths, "/private/admin") || !slices.Contains(paths, "/robots.txt") || !slices.Contains(paths, "/v1/status") {
+		t.Errorf("API requests = %v, want robots.txt and /v1/status only", paths)
+	}
+}
@@ -0,0 +1,110 @@
+package crawler
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/lukemcguire/zombiecrawl/result"
+)
+
+// hreflangTarget is what is known about the variant an hreflang alternate
+// points to.
+type hreflangTarget struct {
+	meta    *PageMeta // nil if the variant failed or is not HTML
+	problem string    // Why the variant failed, e.g. "status 404"
+}
+
+// checkHreflang validates the hreflang alternates of pages: each variant
+// must work and must list the page among its own alternates. Variants that
+// were crawled are judged from the crawl; the rest are requested once the
+// crawl has finished. If ctx is cancelled, unrequested variants are skipped.
+func (c *Crawler) checkHreflang(ctx context.Context, pages []seoPage) []result.SEOIssue {
+	pages = slices.Clone(pages)
+	slices.SortFunc(pages, func(a, b seoPage) int { return cmp.Compare(a.url, b.url) })
+
+	targets := make(map[string]hreflangTarget, len(pages))
+	for _, page := range pages {
+		targets[page.url] = hreflangTarget{meta: &page.meta}
+	}
+	var unknown []CrawlJob
+	for _, page := range pages {
+		for _, alt := range page.meta.Alternates {
+			if _, ok := targets[alt.URL]; ok || slices.ContainsFunc(unknown, func(job CrawlJob) bool { return job.URL == alt.URL }) {
+				continue
+			}
+			unknown = append(unknown, CrawlJob{URL: alt.URL, SourcePage: page.url, UserAgent: c.userAgents.For(alt.URL)})
+		}
+	}
+	for rawURL, target := range c.fetchHreflang(ctx, unknown) {
+		targets[rawURL] = target
+	}
+
+	var issues []result.SEOIssue
+	for _, page := range pages {
+		for _, alt := range page.meta.Alternates {
+			target, ok := targets[alt.URL]
+			switch {
+			case alt.URL == page.url || !ok:
+				// A page may list itself; unrequested variants are unknown
+			case target.problem != "":
+				issues = append(issues, result.SEOIssue{
+					Kind:   result.SEOHreflangBroken,
+					URL:    page.url,
+					Detail: fmt.Sprintf("hreflang %s -> %s: %s", alt.Lang, alt.URL, target.problem),
+				})
+			case target.meta != nil && !slices.ContainsFunc(target.meta.Alternates, func(back Alternate) bool { return back.URL == page.url }):
+				issues = append(issues, result.SEOIssue{
+					Kind:   result.SEOHreflangOneWay,
+					URL:    page.url,
+					Detail: fmt.Sprintf("hreflang %s -> %s does not link back", alt.Lang, alt.URL),
+				})
+			}
+		}
+	}
+	return issues
+}
+
+// fetchHreflang requests each variant in jobs as a page, so its own
+// alternates are parsed even on another host.
+func (c *Crawler) fetchHreflang(ctx context.Context, jobs []CrawlJob) map[string]hreflangTarget {
+	fetched := make([]*hreflangTarget, len(jobs))
+	slots := make(chan struct{}, c.cfg.Concurrency)
+	var wg sync.WaitGroup
+	for i, job := range jobs {
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		wg.Go(func() {
+			defer func() { <-slots }()
+			if c.limiter.Wait(ctx) != nil || c.hostLimiters.wait(ctx, c.cfg, job.URL) != nil {
+				return
+			}
+			res := CheckURL(ctx, c.client, job, c.cfg)
+			if ctx.Err() != nil {
+				return
+			}
+			target := &hreflangTarget{meta: res.Meta}
+			if res.Result != nil {
+				target.meta = nil
+				target.problem = cmp.Or(res.Result.Error, fmt.Sprintf("status %d", res.Result.StatusCode))
+			}
+			fetched[i] = target
+		})
+	}
+	wg.Wait()
+
+	targets := make(map[string]hreflangTarget, len(jobs))
+	for i, target := range fetched {
+		if target != nil {
+			targets[jobs[i].URL] = *target
+		}
+	}
+	return targets
+}
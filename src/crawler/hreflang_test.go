@@ -0,0 +1,82 @@
+package crawler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/lukemcguire/zombiecrawl/result"
+)
+
+func TestRun_Hreflang(t *testing.T) {
+	var foreign *httptest.Server
+	var mu sync.Mutex
+	requests := make(map[string]int)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests[r.URL.Path]++
+		mu.Unlock()
+		alternates := func(langs ...string) string {
+			var links string
+			for _, lang := range langs {
+				links += fmt.Sprintf(`<link rel="alternate" hreflang="%[1]s" href="/%[1]s">`, lang)
+			}
+			return links
+		}
+		switch r.URL.Path {
+		case "/":
+			_, _ = fmt.Fprintf(w, `<title>Home</title>%s<link rel="alternate" hreflang="ja" href="%s/ja">
+				<link rel="alternate" hreflang="x-default" href="/"><a href="/en">en</a>`,
+				alternates("en", "de", "fr", "es"), foreign.URL)
+		case "/en":
+			_, _ = fmt.Fprintf(w, `<title>English</title><link rel="alternate" hreflang="x-default" href="/">`)
+		case "/de":
+			_, _ = fmt.Fprintf(w, `<title>Deutsch</title><link rel="alternate" hreflang="x-default" href="/">`)
+		case "/fr":
+			_, _ = fmt.Fprint(w, `<title>Français</title>`+alternates("de"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+	foreign = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprintf(w, `<title>日本語</title><link rel="alternate" hreflang="x-default" href="%s/">`, ts.URL)
+	}))
+	defer foreign.Close()
+
+	cfg := DefaultConfig(ts.URL)
+	cfg.Delay = 1
+	cfg.SEO = true
+	c, err := New(cfg, nil)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	res, err := c.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run() error: %v", err)
+	}
+
+	var hreflang []result.SEOIssue
+	for _, issue := range res.SEO {
+		if issue.Kind == result.SEOHreflangBroken || issue.Kind == result.SEOHreflangOneWay {
+			hreflang = append(hreflang, issue)
+		}
+	}
+	want := []result.SEOIssue{
+		{Kind: result.SEOHreflangOneWay, URL: ts.URL + "/", Detail: fmt.Sprintf("hreflang fr -> %s/fr does not link back", ts.URL)},
+		{Kind: result.SEOHreflangBroken, URL: ts.URL + "/", Detail: fmt.Sprintf("hreflang es -> %s/es: status 404", ts.URL)},
+	}
+	if !slices.Equal(hreflang, want) {
+		t.Errorf("hreflang issues = %+v, want %+v", hreflang, want)
+	}
+	// Alternates already crawled are not requested again
+	mu.Lock()
+	defer mu.Unlock()
+	if requests["/en"] != 1 {
+		t.Errorf("/en requested %d times, want 1", requests["/en"])
+	}
+}
@@ -0,0 +1,28 @@
+package crawler
+
+import (
+	"net"
+	"net/url"
+	"strings"
+
+	"github.com/lukemcguire/zombiecrawl/result"
+)
+
+// lintLink returns the link hygiene problems of a link on page whose raw href
+// attribute resolved to target. These are warnings, not broken links: the
+// link may work today but is fragile or insecure.
+func lintLink(page *url.URL, href string, target *url.URL) []result.HygieneKind {
+	var kinds []result.HygieneKind
+	if page.Scheme == "https" && target.Scheme == "http" {
+		kinds = append(kinds, result.HygieneMixedContent)
+	}
+	if strings.HasPrefix(strings.TrimSpace(href), "//") {
+		kinds = append(kinds, result.HygieneProtocolRelative)
+	}
+	// Relative links inherit the page's host, so only links that name an
+	// address themselves are flagged.
+	if ref, err := url.Parse(strings.TrimSpace(href)); err == nil && ref.Host != "" && net.ParseIP(target.Hostname()) != nil {
+		kinds = append(kinds, result.HygieneIPAddress)
+	}
+	return kinds
+}
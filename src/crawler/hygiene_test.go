@@ -0,0 +1,86 @@
+package crawler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"testing"
+
+	"github.com/lukemcguire/zombiecrawl/result"
+)
+
+func TestLintLink(t *testing.T) {
+	tests := []struct {
+		page string
+		href string
+		want []result.HygieneKind
+	}{
+		{"https://example.com/", "/about", nil},
+		{"https://example.com/", "https://other.example/", nil},
+		{"https://example.com/", "http://other.example/", []result.HygieneKind{result.HygieneMixedContent}},
+		{"http://example.com/", "http://other.example/", nil},
+		{"https://example.com/", "//cdn.example/lib.js", []result.HygieneKind{result.HygieneProtocolRelative}},
+		{"http://example.com/", " //cdn.example/lib.js", []result.HygieneKind{result.HygieneProtocolRelative}},
+		{"https://example.com/", "http://192.0.2.1/", []result.HygieneKind{result.HygieneMixedContent, result.HygieneIPAddress}},
+		{"https://example.com/", "https://[2001:db8::1]:8443/", []result.HygieneKind{result.HygieneIPAddress}},
+		{"https://192.0.2.1/", "/about", nil},
+	}
+	for _, tt := range tests {
+		page, _ := url.Parse(tt.page)
+		target := page.ResolveReference(mustParseURL(t, tt.href))
+		if got := lintLink(page, tt.href, target); !slices.Equal(got, tt.want) {
+			t.Errorf("lintLink(%q, %q) = %v, want %v", tt.page, tt.href, got, tt.want)
+		}
+	}
+}
+
+func mustParseURL(t *testing.T, raw string) *url.URL {
+	t.Helper()
+	u, err := url.Parse(raw)
+	if err != nil {
+		t.Fatalf("parse %q: %v", raw, err)
+	}
+	return u
+}
+
+func TestRun_LinkHygiene(t *testing.T) {
+	var ts *httptest.Server
+	ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			_, _ = fmt.Fprint(w, `<html></html>`)
+			return
+		}
+		// The server is reached by IP address, so the absolute link is flagged.
+		_, _ = fmt.Fprintf(w, `<a href="/ok">ok</a><a href="%s/abs">abs</a><a href="%s/abs">again</a>`, ts.URL, ts.URL)
+	}))
+	defer ts.Close()
+
+	for _, enabled := range []bool{false, true} {
+		cfg := DefaultConfig(ts.URL)
+		cfg.Delay = 1
+		cfg.LinkHygiene = enabled
+		c, err := New(cfg, nil)
+		if err != nil {
+			t.Fatalf("New() error: %v", err)
+		}
+		res, err := c.Run(context.Background())
+		if err != nil {
+			t.Fatalf("Run() error: %v", err)
+		}
+		if !enabled {
+			if len(res.Hygiene) != 0 {
+				t.Errorf("hygiene warnings without LinkHygiene: %v", res.Hygiene)
+			}
+			continue
+		}
+		if len(res.Hygiene) != 1 {
+			t.Fatalf("got %d warnings, want one for the absolute link: %v", len(res.Hygiene), res.Hygiene)
+		}
+		if warning := res.Hygiene[0]; warning.Kind != result.HygieneIPAddress || warning.Href != ts.URL+"/abs" {
+			t.Errorf("unexpected warning %+v", warning)
+		}
+	}
+}
@@ -0,0 +1,185 @@
+package crawler
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/lukemcguire/zombiecrawl/result"
+)
+
+// DefaultSlowRequest is how long a check runs before it is reported as slow
+// when Config.SlowRequest is unset.
+const DefaultSlowRequest = 30 * time.Second
+
+// inFlightCheckInterval is how often in-flight checks are inspected for
+// slow-request events and the hard timeout. Tests shorten it.
+var inFlightCheckInterval = time.Second
+
+// errHardTimeout is the cancellation cause of checks stopped by the
+// watchdog.
+var errHardTimeout = errors.New("hard timeout")
+
+// InFlightRequest is a check that has started but not finished.
+type InFlightRequest struct {
+	URL     string        `json:"url"`
+	Started time.Time     `json:"started"`
+	Elapsed time.Duration `json:"elapsed"`
+}
+
+// inFlightTracker records the checks workers are running, so slow ones can
+// be reported and hung ones cancelled. A check covers every attempt of a
+// job, including retry backoff.
+type inFlightTracker struct {
+	mu      sync.Mutex
+	nextID  uint64
+	entries map[uint64]*inFlightEntry
+}
+
+// inFlightEntry is one running check.
+type inFlightEntry struct {
+	url       string
+	started   time.Time
+	cancel    context.CancelCauseFunc
+	cancelled bool
+}
+
+// newInFlightTracker creates an empty tracker.
+func newInFlightTracker() *inFlightTracker {
+	return &inFlightTracker{entries: make(map[uint64]*inFlightEntry)}
+}
+
+// start records a check of url begun at now, which cancel stops. It returns
+// the ID to pass to finish.
+func (t *inFlightTracker) start(url string, now time.Time, cancel context.CancelCauseFunc) uint64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.nextID++
+	t.entries[t.nextID] = &inFlightEntry{url: url, started: now, cancel: cancel}
+	return t.nextID
+}
+
+// finish removes a check recorded by start.
+func (t *inFlightTracker) finish(id uint64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.entries, id)
+}
+
+// running returns the checks that have run for at least minAge at now,
+// longest-running first.
+func (t *inFlightTracker) running(now time.Time, minAge time.Duration) []InFlightRequest {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	requests := make([]InFlightRequest, 0, len(t.entries))
+	for _, entry := range t.entries {
+		if elapsed := now.Sub(entry.started); elapsed >= minAge {
+			requests = append(requests, InFlightRequest{URL: entry.url, Started: entry.started, Elapsed: elapsed})
+		}
+	}
+	slices.SortFunc(requests, func(a, b InFlightRequest) int {
+		return cmp.Or(a.Started.Compare(b.Started), cmp.Compare(a.URL, b.URL))
+	})
+	return requests
+}
+
+// count returns how many checks are running.
+func (t *inFlightTracker) count() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return len(t.entries)
+}
+
+// cancelOlderThan cancels checks that have run longer than limit at now
+// with errHardTimeout, and returns how many it cancelled.
+func (t *inFlightTracker) cancelOlderThan(now time.Time, limit time.Duration) int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	cancelled := 0
+	for _, entry := range t.entries {
+		if !entry.cancelled && now.Sub(entry.started) > limit {
+			entry.cancel(errHardTimeout)
+			entry.cancelled = true
+			cancelled++
+		}
+	}
+	return cancelled
+}
+
+// InFlight returns the checks currently running, longest-running first.
+func (c *Crawler) InFlight() []InFlightRequest {
+	return c.inFlight.running(c.cfg.now(), 0)
+}
+
+// check runs job under the in-flight tracker. A check still running after
+// Config.HardTimeout is cancelled by the watchdog and reported as a timeout.
+func (c *Crawler) check(ctx context.Context, job CrawlJob) CrawlResult {
+	checkCtx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+	id := c.inFlight.start(job.URL, c.cfg.now(), cancel)
+	res := c.fetcher.Fetch(checkCtx, job)
+	c.inFlight.finish(id)
+
+	if errors.Is(context.Cause(checkCtx), errHardTimeout) && ctx.Err() == nil {
+		jobLogger(c.cfg.logger(), job).Warn("check cancelled by the hard timeout", "timeout", c.cfg.HardTimeout)
+		err := fmt.Errorf("%w: cancelled after %s without finishing", errHardTimeout, c.cfg.HardTimeout)
+		res.Err = fmt.Errorf("check %s: %w", job.URL, err)
+		res.Links = nil
+		var attempts []result.Attempt
+		if res.Result != nil {
+			attempts = res.Result.Attempts
+		}
+		res.Result = &result.LinkResult{
+			URL:           job.URL,
+			StatusCode:    res.StatusCode,
+			SourcePage:    job.SourcePage,
+			IsExternal:    job.IsExternal,
+			Error:         err.Error(),
+			ErrorCategory: result.CategoryTimeout,
+			Attempts:      attempts,
+		}
+	}
+	return res
+}
+
+// watchInFlight reports slow checks as progress events and enforces
+// Config.HardTimeout until ctx is done. Each tick with slow checks publishes
+// an event listing them in Slow; once they have finished, or when watching
+// stops, one event with an empty Slow list follows.
+func (c *Crawler) watchInFlight(ctx context.Context) {
+	ticker := time.NewTicker(inFlightCheckInterval)
+	defer ticker.Stop()
+	reported := false
+	for {
+		select {
+		case <-ctx.Done():
+			if reported {
+				c.publishSlow([]InFlightRequest{})
+			}
+			return
+		case <-ticker.C:
+		}
+		now := c.cfg.now()
+		if c.cfg.HardTimeout > 0 {
+			c.inFlight.cancelOlderThan(now, c.cfg.HardTimeout)
+		}
+		slow := c.inFlight.running(now, c.cfg.SlowRequest)
+		if len(slow) == 0 && !reported {
+			continue
+		}
+		reported = len(slow) > 0
+		c.publishSlow(slow)
+	}
+}
+
+// publishSlow publishes an in-flight status event listing slow checks.
+func (c *Crawler) publishSlow(slow []InFlightRequest) {
+	c.mu.Lock()
+	evt := CrawlEvent{Checked: c.total, Broken: len(c.results), Slow: slow}
+	c.mu.Unlock()
+	c.events.publish(evt)
+}
@@ -0,0 +1,138 @@
+package crawler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lukemcguire/zombiecrawl/result"
+)
+
+func TestInFlightTracker(t *testing.T) {
+	tracker := newInFlightTracker()
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	ctxA, cancelA := context.WithCancelCause(context.Background())
+	ctxB, cancelB := context.WithCancelCause(context.Background())
+	defer cancelA(nil)
+	defer cancelB(nil)
+
+	b := tracker.start("https://example.com/b", start.Add(time.Second), cancelB)
+	tracker.start("https://example.com/a", start, cancelA)
+
+	now := start.Add(40 * time.Second)
+	running := tracker.running(now, 0)
+	if len(running) != 2 || running[0].URL != "https://example.com/a" || running[0].Elapsed != 40*time.Second {
+		t.Fatalf("running = %+v, want /a first with 40s elapsed", running)
+	}
+	if slow := tracker.running(now, 39500*time.Millisecond); len(slow) != 1 {
+		t.Errorf("got %d checks over 39.5s, want 1", len(slow))
+	}
+
+	if n := tracker.cancelOlderThan(now, 39500*time.Millisecond); n != 1 {
+		t.Errorf("cancelled %d checks, want 1", n)
+	}
+	if !errors.Is(context.Cause(ctxA), errHardTimeout) || ctxB.Err() != nil {
+		t.Errorf("cancellation causes = %v, %v; want only /a stopped by the watchdog", context.Cause(ctxA), ctxB.Err())
+	}
+	if n := tracker.cancelOlderThan(now, 39500*time.Millisecond); n != 0 {
+		t.Errorf("cancelled %d checks again, want 0", n)
+	}
+
+	tracker.finish(b)
+	if running := tracker.running(now, 0); len(running) != 1 {
+		t.Errorf("got %d checks after finish, want 1", len(running))
+	}
+}
+
+// shortenInFlightChecks makes the watchdog tick quickly for the test.
+func shortenInFlightChecks(t *testing.T) {
+	t.Helper()
+	saved := inFlightCheckInterval
+	inFlightCheckInterval = 10 * time.Millisecond
+	t.Cleanup(func() { inFlightCheckInterval = saved })
+}
+
+func TestRun_HardTimeout(t *testing.T) {
+	shortenInFlightChecks(t)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/hang" {
+			<-r.Context().Done()
+			return
+		}
+		_, _ = fmt.Fprint(w, `<a href="/hang">hang</a>`)
+	}))
+	defer ts.Close()
+
+	cfg := DefaultConfig(ts.URL)
+	cfg.Delay = 1
+	cfg.RequestTimeout = time.Minute
+	cfg.HardTimeout = 100 * time.Millisecond
+	c, err := New(cfg, nil)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	start := time.Now()
+	res, err := c.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run() error: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("crawl took %s; the watchdog should cancel the hung check", elapsed)
+	}
+	if len(res.BrokenLinks) != 1 {
+		t.Fatalf("got %d broken links, want the hung one: %v", len(res.BrokenLinks), res.BrokenLinks)
+	}
+	link := res.BrokenLinks[0]
+	if link.ErrorCategory != result.CategoryTimeout || !strings.Contains(link.Error, "hard timeout") {
+		t.Errorf("hung link = %+v, want a hard timeout", link)
+	}
+}
+
+func TestRun_SlowRequestEvents(t *testing.T) {
+	shortenInFlightChecks(t)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			time.Sleep(200 * time.Millisecond)
+		}
+		_, _ = fmt.Fprint(w, `<a href="/slow">slow</a>`)
+	}))
+	defer ts.Close()
+
+	cfg := DefaultConfig(ts.URL)
+	cfg.Delay = 1
+	cfg.SlowRequest = 50 * time.Millisecond
+	cfg.LosslessEvents = true
+	events := make(chan CrawlEvent, 1000)
+	c, err := New(cfg, events)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	if _, err := c.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error: %v", err)
+	}
+	close(events)
+
+	sawSlow, sawCleared := false, false
+	for evt := range events {
+		switch {
+		case len(evt.Slow) > 0:
+			if evt.URL != "" || evt.Slow[0].URL != ts.URL+"/slow" || evt.Slow[0].Elapsed < cfg.SlowRequest {
+				t.Errorf("unexpected slow event %+v", evt)
+			}
+			sawSlow = true
+		case evt.Slow != nil && sawSlow:
+			sawCleared = true
+		}
+	}
+	if !sawSlow || !sawCleared {
+		t.Errorf("saw slow event %v, cleared event %v; want both", sawSlow, sawCleared)
+	}
+	if inFlight := c.InFlight(); len(inFlight) != 0 {
+		t.Errorf("InFlight() = %v after the crawl, want none", inFlight)
+	}
+}
@@ -0,0 +1,38 @@
+package crawler
+
+import (
+	"log/slog"
+)
+
+// discardLogger stands in for a nil Config.Logger.
+var discardLogger = slog.New(slog.DiscardHandler)
+
+// logger returns cfg.Logger, or a logger that discards records if it is nil.
+func (cfg Config) logger() *slog.Logger {
+	if cfg.Logger == nil {
+		return discardLogger
+	}
+	return cfg.Logger
+}
+
+// jobLogger returns logger with the attributes identifying job: its url,
+// host, and depth.
+func jobLogger(logger *slog.Logger, job CrawlJob) *slog.Logger {
+	return logger.With("url", job.URL, "host", hostFromURL(job.URL), "depth", job.Depth)
+}
+
+// logResult records the outcome of a check: broken links at info level and
+// everything else at debug level.
+func logResult(logger *slog.Logger, res CrawlResult) {
+	logger = jobLogger(logger, res.Job)
+	attrs := []any{"attempt", res.Attempts, "status", res.StatusCode, "duration", res.Duration}
+	switch {
+	case res.Result != nil:
+		attrs = append(attrs, "category", res.Result.ErrorCategory, "error", res.Result.Error)
+		logger.Info("broken link", attrs...)
+	case res.Cached:
+		logger.Debug("link passed recently; not rechecked")
+	default:
+		logger.Debug("checked", attrs...)
+	}
+}
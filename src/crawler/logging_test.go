@@ -0,0 +1,108 @@
+package crawler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// logRecorder collects JSON log records for assertions.
+type logRecorder struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (r *logRecorder) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.buf.Write(p)
+}
+
+// records returns the logged records with the given message.
+func (r *logRecorder) records(t *testing.T, msg string) []map[string]any {
+	t.Helper()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var matched []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(r.buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var record map[string]any
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("log line %q is not JSON: %v", line, err)
+		}
+		if record["msg"] == msg {
+			matched = append(matched, record)
+		}
+	}
+	return matched
+}
+
+// newRecordingLogger returns a debug-level logger writing to a logRecorder.
+func newRecordingLogger() (*slog.Logger, *logRecorder) {
+	rec := &logRecorder{}
+	return slog.New(slog.NewJSONHandler(rec, &slog.HandlerOptions{Level: slog.LevelDebug})), rec
+}
+
+func TestConfigLogger_NilDiscards(t *testing.T) {
+	var cfg Config
+	if cfg.logger() == nil {
+		t.Fatal("logger() = nil, want a discarding logger")
+	}
+	cfg.logger().Error("dropped") // Must not panic
+}
+
+func TestRun_LogsStructuredRecords(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/robots.txt":
+			_, _ = fmt.Fprint(w, "User-agent: *\nDisallow: /private\n")
+		case "/":
+			_, _ = fmt.Fprint(w, `<a href="/ok">ok</a><a href="/gone">gone</a><a href="/private">private</a>`)
+		case "/ok":
+			_, _ = fmt.Fprint(w, `<html></html>`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	logger, rec := newRecordingLogger()
+	cfg := DefaultConfig(ts.URL)
+	cfg.Delay = 1
+	cfg.Logger = logger
+	c, err := New(cfg, nil)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	if _, err := c.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error: %v", err)
+	}
+
+	broken := rec.records(t, "broken link")
+	if len(broken) != 1 {
+		t.Fatalf("got %d broken link records, want 1", len(broken))
+	}
+	record := broken[0]
+	if record["level"] != "INFO" || record["url"] != ts.URL+"/gone" || record["host"] != "127.0.0.1" ||
+		record["depth"] != float64(1) || record["category"] != "4xx" || record["attempt"] != float64(1) {
+		t.Errorf("broken link record = %v", record)
+	}
+	if checked := rec.records(t, "checked"); len(checked) != 2 || checked[0]["level"] != "DEBUG" {
+		t.Errorf("checked records = %v, want one per working page at debug level", checked)
+	}
+	if skipped := rec.records(t, "skipping link disallowed by robots.txt"); len(skipped) != 1 {
+		t.Errorf("got %d robots.txt skip records, want 1", len(skipped))
+	}
+	if finished := rec.records(t, "crawl finished"); len(finished) != 1 || finished[0]["broken"] != float64(1) {
+		t.Errorf("crawl finished records = %v", finished)
+	}
+}
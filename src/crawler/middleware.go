@@ -0,0 +1,154 @@
+package crawler
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// Fetcher checks one crawl job. At the end of every chain is the crawler's
+// own fetcher, which requests the URL with retries as CheckURLWithRetry
+// does.
+type Fetcher interface {
+	Fetch(ctx context.Context, job CrawlJob) CrawlResult
+}
+
+// FetcherFunc adapts a function to a Fetcher.
+type FetcherFunc func(ctx context.Context, job CrawlJob) CrawlResult
+
+// Fetch implements Fetcher.
+func (f FetcherFunc) Fetch(ctx context.Context, job CrawlJob) CrawlResult {
+	return f(ctx, job)
+}
+
+// Middleware wraps a Fetcher with extra behavior, the way HTTP middleware
+// wraps a handler: it may change the job or context, answer without calling
+// next, or inspect and change the result. Config.Middleware composes them.
+type Middleware func(next Fetcher) Fetcher
+
+// chainFetcher wraps fetcher in middleware, the first outermost.
+func chainFetcher(fetcher Fetcher, middleware []Middleware) Fetcher {
+	for i := len(middleware) - 1; i >= 0; i-- {
+		fetcher = middleware[i](fetcher)
+	}
+	return fetcher
+}
+
+// matchHost reports whether rawURL's lowercase hostname matches pattern, a
+// path.Match glob. A malformed pattern matches nothing.
+func matchHost(pattern, rawURL string) bool {
+	matched, _ := path.Match(strings.ToLower(pattern), strings.ToLower(hostFromURL(rawURL)))
+	return matched
+}
+
+// headersKey is the context key of the headers added by WithHeaders.
+type headersKey struct{}
+
+// headersFrom returns the headers WithHeaders added to ctx, or nil.
+func headersFrom(ctx context.Context) http.Header {
+	header, _ := ctx.Value(headersKey{}).(http.Header)
+	return header
+}
+
+// WithHeaders returns middleware sending header with every request for jobs
+// whose host matches hostPattern, a glob such as "*.example.com" ("*"
+// matches every host). The headers are set after those of
+// Config.HostConfigs, replacing any of the same name.
+func WithHeaders(hostPattern string, header http.Header) Middleware {
+	return func(next Fetcher) Fetcher {
+		return FetcherFunc(func(ctx context.Context, job CrawlJob) CrawlResult {
+			if matchHost(hostPattern, job.URL) {
+				merged := headersFrom(ctx).Clone()
+				if merged == nil {
+					merged = make(http.Header, len(header))
+				}
+				for name, values := range header {
+					merged[http.CanonicalHeaderKey(name)] = values
+				}
+				ctx = context.WithValue(ctx, headersKey{}, merged)
+			}
+			return next.Fetch(ctx, job)
+		})
+	}
+}
+
+// WithBasicAuth returns middleware sending HTTP basic auth credentials with
+// every request for jobs whose host matches hostPattern, as WithHeaders
+// does.
+func WithBasicAuth(hostPattern, username, password string) Middleware {
+	req := &http.Request{Header: make(http.Header)}
+	req.SetBasicAuth(username, password)
+	return WithHeaders(hostPattern, http.Header{"Authorization": req.Header.Values("Authorization")})
+}
+
+// CacheResults returns middleware remembering external links that passed
+// for ttl, so they are not requested again meanwhile. The cache lives in
+// memory; share the middleware between crawlers, e.g. of several sites, to
+// check each external link once across them. Like Config.ExternalCache, it
+// never remembers broken links or internal pages.
+func CacheResults(ttl time.Duration) Middleware {
+	var mu sync.Mutex
+	passed := make(map[string]time.Time)
+	return func(next Fetcher) Fetcher {
+		return FetcherFunc(func(ctx context.Context, job CrawlJob) CrawlResult {
+			if !job.IsExternal {
+				return next.Fetch(ctx, job)
+			}
+			mu.Lock()
+			checked, ok := passed[job.URL]
+			mu.Unlock()
+			clock := clocksFrom(ctx).clock
+			if ok && clock.Now().Sub(checked) < ttl {
+				return CrawlResult{Job: job, Cached: true}
+			}
+			res := next.Fetch(ctx, job)
+			if res.Result == nil && res.Err == nil && !res.Cached {
+				mu.Lock()
+				passed[job.URL] = clock.Now()
+				mu.Unlock()
+			}
+			return res
+		})
+	}
+}
+
+// Throttle returns middleware limiting checks to perSecond on average, with
+// bursts of up to burst (at least 1). The limit is in addition to the
+// crawler's own rate limiting; share the middleware between crawlers to
+// hold them to one combined rate.
+func Throttle(perSecond float64, burst int) Middleware {
+	limiter := rate.NewLimiter(rate.Limit(perSecond), max(burst, 1))
+	return func(next Fetcher) Fetcher {
+		return FetcherFunc(func(ctx context.Context, job CrawlJob) CrawlResult {
+			if err := waitLimiter(ctx, limiter, clocksFrom(ctx).clock, 1); err != nil {
+				return CrawlResult{Job: job, Err: fmt.Errorf("throttle %s: %w", job.URL, err)}
+			}
+			return next.Fetch(ctx, job)
+		})
+	}
+}
+
+// LogFetches returns middleware logging each check to logger at info level
+// with its status, attempts, and duration, and the error of broken links.
+func LogFetches(logger *slog.Logger) Middleware {
+	return func(next Fetcher) Fetcher {
+		return FetcherFunc(func(ctx context.Context, job CrawlJob) CrawlResult {
+			clock := clocksFrom(ctx).timings
+			started := clock.Now()
+			res := next.Fetch(ctx, job)
+			attrs := []any{"status", res.StatusCode, "attempts", res.Attempts, "duration", clock.Now().Sub(started), "cached", res.Cached}
+			if res.Result != nil {
+				attrs = append(attrs, "error", res.Result.Error)
+			}
+			jobLogger(logger, job).Info("fetched", attrs...)
+			return res
+		})
+	}
+}
@@ -0,0 +1,130 @@
+package crawler
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestChainFetcher_Order(t *testing.T) {
+	var order []string
+	record := func(name string) Middleware {
+		return func(next Fetcher) Fetcher {
+			return FetcherFunc(func(ctx context.Context, job CrawlJob) CrawlResult {
+				order = append(order, name+" before")
+				res := next.Fetch(ctx, job)
+				order = append(order, name+" after")
+				return res
+			})
+		}
+	}
+	fetcher := chainFetcher(FetcherFunc(func(context.Context, CrawlJob) CrawlResult {
+		order = append(order, "fetch")
+		return CrawlResult{}
+	}), []Middleware{record("outer"), record("inner")})
+	fetcher.Fetch(context.Background(), CrawlJob{URL: "http://example.com/"})
+
+	want := []string{"outer before", "inner before", "fetch", "inner after", "outer after"}
+	if !slices.Equal(order, want) {
+		t.Errorf("order = %v, want %v", order, want)
+	}
+}
+
+func TestRun_Middleware(t *testing.T) {
+	var externalRequests atomic.Int32
+	external := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		externalRequests.Add(1)
+	}))
+	defer external.Close()
+	site := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, pass, ok := r.BasicAuth(); !ok || user != "crawler" || pass != "secret" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		_, _ = fmt.Fprintf(w, `<a href="%s/ext">external</a>`, strings.Replace(external.URL, "127.0.0.1", "localhost", 1))
+	}))
+	defer site.Close()
+
+	var logs bytes.Buffer
+	cache := CacheResults(time.Hour)
+	for range 2 {
+		cfg := DefaultConfig(site.URL)
+		cfg.Delay = 1
+		cfg.Middleware = []Middleware{
+			LogFetches(slog.New(slog.NewTextHandler(&logs, nil))),
+			cache,
+			WithBasicAuth("127.0.0.1", "crawler", "secret"),
+		}
+		c, err := New(cfg, nil)
+		if err != nil {
+			t.Fatalf("New() error: %v", err)
+		}
+		res, err := c.Run(context.Background())
+		if err != nil {
+			t.Fatalf("Run() error: %v", err)
+		}
+		if len(res.BrokenLinks) != 0 {
+			t.Fatalf("BrokenLinks = %+v, want none", res.BrokenLinks)
+		}
+	}
+
+	// The shared cache spares the second crawl the external request
+	if got := externalRequests.Load(); got != 1 {
+		t.Errorf("external link requested %d times, want 1", got)
+	}
+	if got := strings.Count(logs.String(), "msg=fetched"); got != 4 {
+		t.Errorf("logged %d fetches, want 4:\n%s", got, logs.String())
+	}
+	if !strings.Contains(logs.String(), "cached=true") {
+		t.Errorf("logs do not show the cached check:\n%s", logs.String())
+	}
+}
+
+func TestThrottle_Cancelled(t *testing.T) {
+	throttle := Throttle(0.001, 1)
+	calls := 0
+	fetcher := throttle(FetcherFunc(func(context.Context, CrawlJob) CrawlResult {
+		calls++
+		return CrawlResult{}
+	}))
+
+	job := CrawlJob{URL: "http://example.com/"}
+	if res := fetcher.Fetch(context.Background(), job); res.Err != nil {
+		t.Fatalf("first Fetch() error: %v", res.Err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	res := fetcher.Fetch(ctx, job)
+	if !errors.Is(res.Err, context.Canceled) {
+		t.Errorf("throttled Fetch() error = %v, want context.Canceled", res.Err)
+	}
+	if calls != 1 {
+		t.Errorf("next called %d times, want 1", calls)
+	}
+}
+
+func TestMatchHost(t *testing.T) {
+	tests := []struct {
+		pattern, url string
+		want         bool
+	}{
+		{"*", "https://example.com/", true},
+		{"*.Example.com", "https://WWW.example.com:8443/", true},
+		{"*.example.com", "https://example.com/", false},
+		{"[bad", "https://example.com/", false},
+	}
+	for _, tt := range tests {
+		if got := matchHost(tt.pattern, tt.url); got != tt.want {
+			t.Errorf("matchHost(%q, %q) = %v, want %v", tt.pattern, tt.url, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package crawler
+
+import (
+	"context"
+	"sync"
+
+	"github.com/lukemcguire/zombiecrawl/result"
+)
+
+// Pool is a fixed set of request slots shared by several crawlers, so a
+// multi-site run never has more than size requests in flight in total.
+// A nil *Pool places no limit.
+type Pool struct {
+	slots chan struct{}
+}
+
+// NewPool returns a pool with size request slots. Sizes below 1 are treated as 1.
+func NewPool(size int) *Pool {
+	if size < 1 {
+		size = 1
+	}
+	return &Pool{slots: make(chan struct{}, size)}
+}
+
+// acquire blocks until a slot is free or ctx is done.
+func (p *Pool) acquire(ctx context.Context) error {
+	if p == nil {
+		return nil
+	}
+	select {
+	case p.slots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// release returns a slot taken by acquire.
+func (p *Pool) release() {
+	if p == nil {
+		return
+	}
+	<-p.slots
+}
+
+// RunSites crawls each configuration concurrently, sharing pool between them,
+// and returns one SiteResult per configuration in the same order. A site that
+// fails to start or run records its error and does not stop the others.
+// The progressCh parameter is optional; events from all sites share it.
+func RunSites(ctx context.Context, cfgs []Config, pool *Pool, progressCh chan<- CrawlEvent) []result.SiteResult {
+	sites := make([]result.SiteResult, len(cfgs))
+
+	var wg sync.WaitGroup
+	for i, cfg := range cfgs {
+		sites[i].Site = cfg.Scrub.String(cfg.StartURL)
+		cfg.Pool = pool
+
+		wg.Go(func() {
+			c, err := New(cfg, progressCh)
+			if err != nil {
+				sites[i].Error = cfg.Scrub.String(err.Error())
+				return
+			}
+			res, err := c.Run(ctx)
+			if err != nil {
+				sites[i].Error = cfg.Scrub.String(err.Error())
+				return
+			}
+			sites[i].Result = res
+		})
+	}
+	wg.Wait()
+
+	return sites
+}
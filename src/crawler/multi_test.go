@@ -0,0 +1,98 @@
+package crawler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestPool_NilIsUnlimited(t *testing.T) {
+	var p *Pool
+	if err := p.acquire(context.Background()); err != nil {
+		t.Fatalf("nil pool acquire returned %v", err)
+	}
+	p.release()
+}
+
+func TestPool_AcquireHonoursContext(t *testing.T) {
+	p := NewPool(1)
+	if err := p.acquire(context.Background()); err != nil {
+		t.Fatalf("first acquire returned %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := p.acquire(ctx); err == nil {
+		t.Fatal("expected second acquire on a full pool to fail when ctx expires")
+	}
+
+	p.release()
+	if err := p.acquire(context.Background()); err != nil {
+		t.Fatalf("acquire after release returned %v", err)
+	}
+}
+
+func TestRunSites(t *testing.T) {
+	var inFlight, peak atomic.Int32
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		// robots.txt fetches are not link checks and do not take a pool slot
+		if r.URL.Path == "/robots.txt" {
+			http.NotFound(w, r)
+			return
+		}
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		switch r.URL.Path {
+		case "/":
+			_, _ = fmt.Fprint(w, `<a href="/a">a</a><a href="/b">b</a><a href="/missing">m</a>`)
+		case "/a", "/b":
+			_, _ = fmt.Fprint(w, `<p>ok</p>`)
+		default:
+			http.NotFound(w, r)
+		}
+	}
+	siteA := httptest.NewServer(http.HandlerFunc(handler))
+	defer siteA.Close()
+	siteB := httptest.NewServer(http.HandlerFunc(handler))
+	defer siteB.Close()
+
+	cfgs := []Config{
+		{StartURL: siteA.URL, Concurrency: 4, Delay: 1, DisableAutoTune: true},
+		{StartURL: "ftp://%zz", Concurrency: 4},
+		{StartURL: siteB.URL, Concurrency: 4, Delay: 1, DisableAutoTune: true},
+	}
+	sites := RunSites(context.Background(), cfgs, NewPool(2), nil)
+
+	if len(sites) != 3 {
+		t.Fatalf("expected 3 site results, got %d", len(sites))
+	}
+	for _, i := range []int{0, 2} {
+		if sites[i].Site != cfgs[i].StartURL {
+			t.Errorf("site %d = %q, want %q", i, sites[i].Site, cfgs[i].StartURL)
+		}
+		if sites[i].Result == nil {
+			t.Fatalf("site %d: expected result, got error %q", i, sites[i].Error)
+		}
+		if sites[i].Result.Stats.TotalChecked != 4 || sites[i].Result.Stats.BrokenCount != 1 {
+			t.Errorf("site %d: checked %d, broken %d; want 4 and 1", i,
+				sites[i].Result.Stats.TotalChecked, sites[i].Result.Stats.BrokenCount)
+		}
+	}
+	if sites[1].Error == "" || sites[1].Result != nil {
+		t.Errorf("expected invalid site to record an error, got %+v", sites[1])
+	}
+	if got := peak.Load(); got > 2 {
+		t.Errorf("peak requests in flight = %d, want at most the pool size 2", got)
+	}
+}
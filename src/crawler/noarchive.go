@@ -0,0 +1,79 @@
+package crawler
+
+import (
+	"bytes"
+	"mime"
+	"net/http"
+	"slices"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// noArchiveDirectives are the robots directives asking that a page not be
+// stored: "none" implies both noarchive and nosnippet.
+var noArchiveDirectives = []string{"noarchive", "nosnippet", "none"}
+
+// noArchive returns the robots directive forbidding storage of a response,
+// or "" if there is none. Directives come from X-Robots-Tag headers and,
+// for HTML bodies, <meta name="robots"> tags in the document head.
+// Directives scoped to a user agent ("googlebot: noarchive") are honored as
+// well, since a body withheld from one crawler should not be kept by any.
+func noArchive(header http.Header, body []byte) string {
+	if directive := headerNoArchive(header); directive != "" {
+		return directive
+	}
+	mediaType, _, _ := mime.ParseMediaType(header.Get("Content-Type"))
+	if mediaType != "text/html" && mediaType != "application/xhtml+xml" {
+		return ""
+	}
+	tokenizer := html.NewTokenizer(bytes.NewReader(body))
+	for {
+		switch tokenizer.Next() {
+		case html.ErrorToken:
+			return ""
+		case html.StartTagToken, html.SelfClosingTagToken:
+			token := tokenizer.Token()
+			if token.Data == "body" {
+				return ""
+			}
+			if name, _ := attr(token, "name"); token.Data == "meta" && strings.EqualFold(strings.TrimSpace(name), "robots") {
+				content, _ := attr(token, "content")
+				if directive := findNoArchive(content); directive != "" {
+					return directive
+				}
+			}
+		case html.EndTagToken:
+			if name, _ := tokenizer.TagName(); string(name) == "head" {
+				return ""
+			}
+		}
+	}
+}
+
+// headerNoArchive returns the directive of the X-Robots-Tag headers
+// forbidding storage of a response, or "" if there is none.
+func headerNoArchive(header http.Header) string {
+	for _, value := range header.Values("X-Robots-Tag") {
+		if directive := findNoArchive(value); directive != "" {
+			return directive
+		}
+	}
+	return ""
+}
+
+// findNoArchive returns the first directive of noArchiveDirectives in a
+// comma-separated robots directive list, or "".
+func findNoArchive(directives string) string {
+	for directive := range strings.SplitSeq(directives, ",") {
+		// Drop a user agent scope, e.g. "googlebot: noarchive"
+		if _, scoped, ok := strings.Cut(directive, ":"); ok {
+			directive = scoped
+		}
+		directive = strings.ToLower(strings.TrimSpace(directive))
+		if slices.Contains(noArchiveDirectives, directive) {
+			return directive
+		}
+	}
+	return ""
+}
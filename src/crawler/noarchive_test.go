@@ -0,0 +1,31 @@
+package crawler
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestNoArchive(t *testing.T) {
+	tests := []struct {
+		name   string
+		header http.Header
+		body   string
+		want   string
+	}{
+		{"header", http.Header{"X-Robots-Tag": {"noindex, NoArchive"}}, "", "noarchive"},
+		{"scoped header", http.Header{"X-Robots-Tag": {"unavailable_after: 25 Jun 2010", "googlebot: nosnippet"}}, "", "nosnippet"},
+		{"meta", http.Header{"Content-Type": {"text/html; charset=utf-8"}}, `<head><meta name="Robots" content="none"></head>`, "none"},
+		{"meta in body ignored", http.Header{"Content-Type": {"text/html"}}, `<body><meta name="robots" content="noarchive"></body>`, ""},
+		{"meta after head ignored", http.Header{"Content-Type": {"text/html"}}, `<head></head><meta name="robots" content="noarchive">`, ""},
+		{"other meta", http.Header{"Content-Type": {"text/html"}}, `<meta name="description" content="noarchive">`, ""},
+		{"not html", http.Header{"Content-Type": {"text/plain"}}, `<meta name="robots" content="noarchive">`, ""},
+		{"allowed", http.Header{"X-Robots-Tag": {"noindex, nofollow"}}, "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := noArchive(tt.header, []byte(tt.body)); got != tt.want {
+				t.Errorf("noArchive() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package crawler
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/lukemcguire/zombiecrawl/result"
+)
+
+// pageLimitReader reads at most limit bytes of a page body and then ends
+// it, noting in exceeded whether the body had more.
+type pageLimitReader struct {
+	reader   io.Reader
+	left     int64
+	exceeded bool
+}
+
+// newPageLimitReader returns reader limited to limit bytes, or nil if limit
+// is not positive.
+func newPageLimitReader(reader io.Reader, limit int64) *pageLimitReader {
+	if limit <= 0 {
+		return nil
+	}
+	return &pageLimitReader{reader: reader, left: limit}
+}
+
+// Read implements io.Reader.
+func (r *pageLimitReader) Read(p []byte) (int, error) {
+	if r.left <= 0 {
+		// At the limit: the body is too large if anything is left
+		var probe [1]byte
+		n, err := r.reader.Read(probe[:])
+		if n > 0 {
+			r.exceeded = true
+			return 0, io.EOF
+		}
+		return 0, err
+	}
+	if int64(len(p)) > r.left {
+		p = p[:r.left]
+	}
+	n, err := r.reader.Read(p)
+	r.left -= int64(n)
+	return n, err
+}
+
+// tooLarge reports whether r stopped a body over its limit. It is false
+// for a nil reader.
+func (r *pageLimitReader) tooLarge() bool {
+	return r != nil && r.exceeded
+}
+
+// tooLargeFailed records a page whose body is over limit bytes as broken,
+// with result.ErrContentTooLarge.
+func tooLargeFailed(res *CrawlResult, resp *http.Response, limit int64) {
+	res.Err = fmt.Errorf("fetch %s: %w: body over %s", res.Job.URL, result.ErrContentTooLarge, result.FormatBytes(limit))
+	res.Result = &result.LinkResult{
+		URL:           res.Job.URL,
+		StatusCode:    resp.StatusCode,
+		SourcePage:    res.Job.SourcePage,
+		IsExternal:    res.Job.IsExternal,
+		Error:         fmt.Sprintf("%s: body over %s", result.ErrContentTooLarge, result.FormatBytes(limit)),
+		ErrorCategory: result.ClassifyError(res.Err, resp.StatusCode, false),
+		Headers:       captureHeaders(resp.Header),
+	}
+	res.Links = []string{}
+}
@@ -0,0 +1,76 @@
+package crawler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/lukemcguire/zombiecrawl/result"
+)
+
+func TestPageLimitReader(t *testing.T) {
+	tests := []struct {
+		body      string
+		limit     int64
+		want      string
+		wantLarge bool
+	}{
+		{"hello", 10, "hello", false},
+		{"hello", 5, "hello", false},
+		{"hello!", 5, "hello", true},
+	}
+	for _, tt := range tests {
+		reader := newPageLimitReader(strings.NewReader(tt.body), tt.limit)
+		got, err := io.ReadAll(reader)
+		if err != nil {
+			t.Fatalf("ReadAll(%q) error: %v", tt.body, err)
+		}
+		if string(got) != tt.want || reader.tooLarge() != tt.wantLarge {
+			t.Errorf("limit %d on %q read %q, tooLarge %v; want %q, %v", tt.limit, tt.body, got, reader.tooLarge(), tt.want, tt.wantLarge)
+		}
+	}
+	if reader := newPageLimitReader(strings.NewReader("x"), 0); reader != nil || reader.tooLarge() {
+		t.Error("newPageLimitReader(0) should return a nil reader that is never too large")
+	}
+}
+
+func TestCheckURL_MaxPageBytes(t *testing.T) {
+	page := `<a href="/a">a</a>` + strings.Repeat("<p>filler</p>", 100)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		if r.URL.Path == "/chunked" {
+			// Flushing first sends the page without a Content-Length
+			w.(http.Flusher).Flush()
+		}
+		_, _ = fmt.Fprint(w, page)
+	}))
+	defer ts.Close()
+
+	for _, path := range []string{"/sized", "/chunked"} {
+		t.Run(path, func(t *testing.T) {
+			cfg := DefaultConfig(ts.URL)
+			cfg.MaxPageBytes = 512
+			res := CheckURL(context.Background(), &http.Client{}, CrawlJob{URL: ts.URL + path}, cfg)
+			if res.Result == nil || res.Result.ErrorCategory != result.CategoryTooLarge {
+				t.Fatalf("Result = %+v, want a too large page", res.Result)
+			}
+			if !errors.Is(res.Err, result.ErrContentTooLarge) {
+				t.Errorf("Err = %v, want ErrContentTooLarge", res.Err)
+			}
+			if len(res.Links) != 0 {
+				t.Errorf("Links = %v, want none from a page over the limit", res.Links)
+			}
+
+			cfg.MaxPageBytes = int64(len(page))
+			res = CheckURL(context.Background(), &http.Client{}, CrawlJob{URL: ts.URL + path}, cfg)
+			if res.Result != nil || len(res.Links) != 1 {
+				t.Errorf("page at the limit: Result = %+v, Links = %v; want it read", res.Result, res.Links)
+			}
+		})
+	}
+}
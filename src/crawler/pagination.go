@@ -0,0 +1,50 @@
+package crawler
+
+// paginationGraph groups pages joined by rel="next" and rel="prev" links
+// into chains, such as the pages of a blog archive. It is owned by the
+// coordinator goroutine. The zero value is an empty graph.
+type paginationGraph struct {
+	parent map[string]string // Union-find forest over paginated page URLs
+}
+
+// record joins page to its next and prev pages. Either may be empty.
+func (g *paginationGraph) record(page, next, prev string) {
+	for _, other := range []string{next, prev} {
+		if other == "" || other == page {
+			continue
+		}
+		if g.parent == nil {
+			g.parent = make(map[string]string)
+		}
+		if root, otherRoot := g.find(page), g.find(other); root != otherRoot {
+			g.parent[otherRoot] = root
+		}
+	}
+}
+
+// find returns the representative of url's chain, adding url as a chain of
+// its own if it is new.
+func (g *paginationGraph) find(url string) string {
+	parent, ok := g.parent[url]
+	if !ok {
+		g.parent[url] = url
+		return url
+	}
+	if parent == url {
+		return url
+	}
+	root := g.find(parent)
+	g.parent[url] = root
+	return root
+}
+
+// counts returns the number of pagination chains and the pages in them,
+// including linked pages that were not crawled.
+func (g *paginationGraph) counts() (chains, pages int) {
+	for url, parent := range g.parent {
+		if url == parent {
+			chains++
+		}
+	}
+	return chains, len(g.parent)
+}
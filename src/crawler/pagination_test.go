@@ -0,0 +1,81 @@
+package crawler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestPaginationGraph(t *testing.T) {
+	var g paginationGraph
+	if chains, pages := g.counts(); chains != 0 || pages != 0 {
+		t.Errorf("empty counts() = %d, %d, want 0, 0", chains, pages)
+	}
+
+	g.record("/a/1", "/a/2", "")
+	g.record("/a/2", "/a/3", "/a/1")
+	g.record("/a/3", "", "/a/2")
+	g.record("/b/2", "", "/b/1")
+	g.record("/b/1", "/b/2", "")
+	g.record("/solo", "", "")
+	g.record("/self", "/self", "")
+	// A link joining two chains merges them
+	g.record("/c/1", "/a/1", "")
+
+	if chains, pages := g.counts(); chains != 2 || pages != 6 {
+		t.Errorf("counts() = %d, %d, want 2 chains across 6 pages", chains, pages)
+	}
+}
+
+func TestRun_FollowsPaginationLinks(t *testing.T) {
+	const pages = 5
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/" {
+			_, _ = fmt.Fprint(w, `<a href="/archive/1">archive</a>`)
+			return
+		}
+		n, err := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/archive/"))
+		if err != nil || n < 1 || n > pages {
+			http.NotFound(w, r)
+			return
+		}
+		// Later pages are reachable only through <link rel="next">
+		var page strings.Builder
+		if n > 1 {
+			fmt.Fprintf(&page, `<link rel="prev" href="/archive/%d">`, n-1)
+		}
+		if n < pages {
+			fmt.Fprintf(&page, `<link rel="next" href="/archive/%d">`, n+1)
+		} else {
+			page.WriteString(`<a href="/missing">gone</a>`)
+		}
+		_, _ = fmt.Fprint(w, page.String())
+	}))
+	defer ts.Close()
+
+	cfg := DefaultConfig(ts.URL)
+	cfg.Delay = 1
+	cfg.MaxDepth = 1
+	c, err := New(cfg, nil)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	res, err := c.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run() error: %v", err)
+	}
+
+	if len(res.BrokenLinks) != 0 {
+		t.Errorf("broken links = %+v, want none past MaxDepth", res.BrokenLinks)
+	}
+	if res.Stats.InternalChecked != 1+pages {
+		t.Errorf("InternalChecked = %d, want the start page and all %d archive pages", res.Stats.InternalChecked, pages)
+	}
+	if res.Stats.PaginationChains != 1 || res.Stats.PaginatedPages != pages {
+		t.Errorf("pagination = %d chains across %d pages, want 1 across %d", res.Stats.PaginationChains, res.Stats.PaginatedPages, pages)
+	}
+}
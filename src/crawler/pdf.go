@@ -0,0 +1,88 @@
+package crawler
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+
+	"github.com/lukemcguire/zombiecrawl/result"
+)
+
+// pdfSniffBytes is how much of a PDF is read to find its header. PDF
+// readers accept the %PDF- header anywhere in the first 1024 bytes.
+const pdfSniffBytes = 1024
+
+// isPDF reports whether contentType is application/pdf.
+func isPDF(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err == nil && mediaType == "application/pdf"
+}
+
+// pdfProblem reads the start of body and describes why it is not a PDF, or
+// returns "" if it has a PDF header. Servers commonly send HTML error pages
+// with the content type of the file that was asked for.
+func pdfProblem(body io.Reader) (string, error) {
+	head, err := io.ReadAll(io.LimitReader(body, pdfSniffBytes))
+	if err != nil {
+		return "", err
+	}
+	switch {
+	case bytes.Contains(head, []byte("%PDF-")):
+		return "", nil
+	case len(head) == 0:
+		return "corrupt or masked PDF: empty body", nil
+	default:
+		return fmt.Sprintf("corrupt or masked PDF: no %%PDF header, content looks like %s", http.DetectContentType(head)), nil
+	}
+}
+
+// pdfFailed records a response served as a PDF without a PDF header.
+func pdfFailed(res *CrawlResult, resp *http.Response, problem string) {
+	statusFailed(res, resp, false)
+	res.Result.Error = problem
+	res.Result.ErrorCategory = result.CategoryCorruptPDF
+}
+
+// checkPDFBody validates the start of a PDF response body, recording a
+// broken link on res if it has no PDF header.
+func checkPDFBody(ctx context.Context, res *CrawlResult, resp *http.Response, cfg Config) {
+	body := &countingReader{reader: cfg.Bandwidth.reader(ctx, resp.Body)}
+	problem, err := pdfProblem(body)
+	res.Bytes += body.count
+	switch {
+	case err != nil:
+		fetchFailed(res, fmt.Errorf("read PDF: %w", err), false, cfg)
+	case problem != "":
+		pdfFailed(res, resp, problem)
+	}
+}
+
+// checkExternalPDF requests the start of an external link whose HEAD
+// response claims a PDF and validates its header. Only the first
+// pdfSniffBytes are asked for; servers ignoring the Range header are cut off
+// after them.
+func checkExternalPDF(ctx context.Context, client *http.Client, res *CrawlResult, cfg Config) {
+	req, err := newRequest(ctx, http.MethodGet, res.Job, cfg)
+	if err != nil {
+		fetchFailed(res, err, false, cfg)
+		return
+	}
+	req.Header.Set("Range", fmt.Sprintf("bytes=0-%d", pdfSniffBytes-1))
+	resp, err := client.Do(req)
+	if err != nil {
+		fetchFailed(res, err, false, cfg)
+		return
+	}
+	defer func() { _ = resp.Body.Close() }()
+	switch {
+	case resp.StatusCode >= 400:
+		statusFailed(res, resp, false)
+	case isPDF(resp.Header.Get("Content-Type")):
+		checkPDFBody(ctx, res, resp, cfg)
+	default:
+		pdfFailed(res, resp, fmt.Sprintf("corrupt or masked PDF: HEAD said application/pdf, GET answered %q", resp.Header.Get("Content-Type")))
+	}
+}
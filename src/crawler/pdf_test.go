@@ -0,0 +1,95 @@
+package crawler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lukemcguire/zombiecrawl/result"
+)
+
+func TestPDFProblem(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{name: "pdf", body: "%PDF-1.7\n%\xe2\xe3\xcf\xd3\n1 0 obj"},
+		{name: "junk before header", body: strings.Repeat(" ", 100) + "%PDF-1.4"},
+		{name: "html error page", body: "<!DOCTYPE html><html><body>Not found</body></html>", want: "corrupt or masked PDF: no %PDF header, content looks like text/html; charset=utf-8"},
+		{name: "header past the sniffed bytes", body: strings.Repeat("x", pdfSniffBytes) + "%PDF-1.4", want: "corrupt or masked PDF: no %PDF header, content looks like text/plain; charset=utf-8"},
+		{name: "empty", want: "corrupt or masked PDF: empty body"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := pdfProblem(strings.NewReader(tt.body))
+			if err != nil || got != tt.want {
+				t.Errorf("pdfProblem() = %q, %v, want %q", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckURL_CheckPDFs(t *testing.T) {
+	var mu sync.Mutex
+	var ranges []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/pdf")
+		if r.Method == http.MethodGet {
+			mu.Lock()
+			ranges = append(ranges, r.Header.Get("Range"))
+			mu.Unlock()
+		}
+		switch r.URL.Path {
+		case "/report.pdf":
+			_, _ = w.Write([]byte("%PDF-1.7\n"))
+		case "/masked.pdf":
+			_, _ = w.Write([]byte("<html><body>Session expired</body></html>"))
+		case "/html.pdf":
+			if r.Method == http.MethodGet {
+				w.Header().Set("Content-Type", "text/html")
+			}
+			_, _ = w.Write([]byte("<html></html>"))
+		}
+	}))
+	defer ts.Close()
+
+	client := &http.Client{Timeout: 5 * time.Second}
+	cfg := DefaultConfig(ts.URL)
+	cfg.CheckPDFs = true
+	for _, tt := range []struct {
+		path     string
+		external bool
+		wantErr  string
+	}{
+		{path: "/report.pdf"},
+		{path: "/report.pdf", external: true},
+		{path: "/masked.pdf", wantErr: "corrupt or masked PDF: no %PDF header"},
+		{path: "/masked.pdf", external: true, wantErr: "corrupt or masked PDF: no %PDF header"},
+		{path: "/html.pdf", external: true, wantErr: `GET answered "text/html"`},
+	} {
+		res := CheckURL(context.Background(), client, CrawlJob{URL: ts.URL + tt.path, IsExternal: tt.external}, cfg)
+		switch {
+		case tt.wantErr == "" && res.Result != nil:
+			t.Errorf("%s (external %v): unexpected failure %+v", tt.path, tt.external, res.Result)
+		case tt.wantErr != "" && (res.Result == nil || res.Result.ErrorCategory != result.CategoryCorruptPDF || !strings.Contains(res.Result.Error, tt.wantErr)):
+			t.Errorf("%s (external %v): result = %+v, want %s failure containing %q", tt.path, tt.external, res.Result, result.CategoryCorruptPDF, tt.wantErr)
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if want := "bytes=0-1023"; ranges[1] != want {
+		t.Errorf("external GET Range = %q, want %q", ranges[1], want)
+	}
+
+	// Without the option, PDFs are not downloaded past HEAD
+	cfg.CheckPDFs = false
+	if res := CheckURL(context.Background(), client, CrawlJob{URL: ts.URL + "/masked.pdf", IsExternal: true}, cfg); res.Result != nil {
+		t.Errorf("without CheckPDFs: result = %+v, want none", res.Result)
+	}
+}
@@ -0,0 +1,68 @@
+package crawler
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/lukemcguire/zombiecrawl/result"
+	"github.com/lukemcguire/zombiecrawl/urlutil"
+)
+
+// Plan fetches only the start URL, extracts its links, and reports which of
+// them a real crawl would check, without issuing validation requests for the
+// discovered links. Robots.txt is still consulted so excluded URLs are listed.
+func (c *Crawler) Plan(ctx context.Context) (*result.Plan, error) {
+	if c.visited == nil {
+		return nil, fmt.Errorf("crawler not properly initialized: visited tracker is nil")
+	}
+	c.events = newEventPublisher(c.progressCh, c.cfg.RunID, c.cfg.LosslessEvents, c.cfg.Scrub)
+	defer c.events.close()
+	defer c.closeVisited()
+
+	startURL, err := urlutil.Normalize(c.cfg.StartURL)
+	if err != nil {
+		return nil, fmt.Errorf("normalize start URL: %w", err)
+	}
+	startURL = c.cfg.stripSessionIDs(startURL)
+	if parsedURL, parseErr := url.Parse(startURL); parseErr == nil && parsedURL.Path == "" {
+		parsedURL.Path = "/"
+		startURL = parsedURL.String()
+	}
+
+	job := CrawlJob{URL: startURL, UserAgent: c.userAgents.For(startURL)}
+	seed := c.fetcher.Fetch(ctx, job)
+	if seed.Result != nil {
+		return nil, fmt.Errorf("fetch seed page %s: %s", startURL, seed.Result.Error)
+	}
+
+	plan := &result.Plan{Seed: startURL}
+	startHost := hostFromURL(startURL)
+	seen := map[string]bool{startURL: true}
+	for _, link := range seed.Links {
+		if seen[link] {
+			continue
+		}
+		seen[link] = true
+
+		class := c.cfg.classify(link, startURL, startHost)
+		if class == LinkSkip {
+			plan.Excluded = append(plan.Excluded, result.PlanExclusion{URL: link, Reason: skippedByClassifier})
+			continue
+		}
+		isExternal := class == LinkExternal
+		if c.checksRobots(link, isExternal) {
+			if allowed, _ := c.robotsAllowed(ctx, link, c.userAgents.For(link)); !allowed {
+				plan.Excluded = append(plan.Excluded, result.PlanExclusion{URL: link, Reason: result.ErrRobotsBlocked.Error()})
+				continue
+			}
+		}
+		if isExternal {
+			plan.External = append(plan.External, link)
+		} else {
+			plan.Internal = append(plan.Internal, link)
+		}
+	}
+	c.cfg.Scrub.Plan(plan)
+	return plan, nil
+}
@@ -0,0 +1,67 @@
+package crawler_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/lukemcguire/zombiecrawl/crawler"
+)
+
+// TestCrawlerPlan verifies that a dry run groups the seed page's links
+// without requesting any of them.
+func TestCrawlerPlan(t *testing.T) {
+	var pageHits int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/robots.txt", func(w http.ResponseWriter, r *http.Request) {
+		if _, err := fmt.Fprint(w, "User-agent: *\nDisallow: /admin\n"); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			atomic.AddInt32(&pageHits, 1)
+			return
+		}
+		if _, err := fmt.Fprint(w, `<html><body>
+			<a href="/docs">Docs</a>
+			<a href="/docs">Docs again</a>
+			<a href="/admin/panel">Admin</a>
+			<a href="mailto:someone@example.com">Mail</a>
+			<a href="http://other.invalid/page">External</a>
+		</body></html>`); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	c := mustNewCrawler(t, crawler.Config{
+		StartURL:       ts.URL,
+		Concurrency:    2,
+		RequestTimeout: 5 * time.Second,
+	}, nil)
+
+	plan, err := c.Plan(context.Background())
+	if err != nil {
+		t.Fatalf("Plan() returned error: %v", err)
+	}
+
+	if len(plan.Internal) != 1 || !strings.HasSuffix(plan.Internal[0], "/docs") {
+		t.Errorf("Internal = %v, want [.../docs]", plan.Internal)
+	}
+	if len(plan.External) != 1 || plan.External[0] != "http://other.invalid/page" {
+		t.Errorf("External = %v, want [http://other.invalid/page]", plan.External)
+	}
+	if len(plan.Excluded) != 1 || !strings.HasSuffix(plan.Excluded[0].URL, "/admin/panel") {
+		t.Errorf("Excluded = %v, want [.../admin/panel]", plan.Excluded)
+	}
+	if hits := atomic.LoadInt32(&pageHits); hits != 0 {
+		t.Errorf("expected no requests beyond the seed page, got %d", hits)
+	}
+}
@@ -0,0 +1,328 @@
+package crawler
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/lukemcguire/zombiecrawl/result"
+)
+
+// Plugin applies an organization's own policies to a crawl without changes
+// to the crawler. Config.Plugins are consulted in order; a plugin that
+// returns an error is logged and otherwise ignored, so a failing plugin
+// never stops a crawl.
+type Plugin interface {
+	// Links returns the links of page to follow, given those extracted
+	// from it. It may drop, add, or change links; they are normalized
+	// afterwards.
+	Links(ctx context.Context, page string, links []string) ([]string, error)
+
+	// Enqueue reports whether a discovered link should be queued. Declined
+	// links are never requested and are reported in Result.Skipped.
+	Enqueue(ctx context.Context, job CrawlJob) (bool, error)
+
+	// Finish receives the result of the crawl before Run returns it.
+	Finish(ctx context.Context, res *result.Result) error
+}
+
+// pluginLinks passes links found on page through every plugin in turn.
+func (c *Crawler) pluginLinks(ctx context.Context, page string, links []string) []string {
+	for _, plugin := range c.cfg.Plugins {
+		filtered, err := plugin.Links(ctx, page, links)
+		if err != nil {
+			c.cfg.logger().Error("plugin failed to filter links; keeping them", "url", page, "host", hostFromURL(page), "error", err)
+			continue
+		}
+		links = filtered
+	}
+	return links
+}
+
+// pluginsEnqueue reports whether every plugin lets job be queued, recording
+// it as skipped if one does not. Plugins that fail allow it.
+func (c *Crawler) pluginsEnqueue(ctx context.Context, job CrawlJob) bool {
+	for _, plugin := range c.cfg.Plugins {
+		ok, err := plugin.Enqueue(ctx, job)
+		if err != nil {
+			c.cfg.logger().Error("plugin failed to decide on link; allowing", "url", job.URL, "host", hostFromURL(job.URL), "depth", job.Depth, "error", err)
+			continue
+		}
+		if !ok {
+			c.cfg.logger().Info("skipping link declined by plugin", "url", job.URL, "host", hostFromURL(job.URL), "depth", job.Depth, "source_page", job.SourcePage)
+			c.recordSkipped(job.SourcePage, job.URL, "declined by plugin")
+			return false
+		}
+	}
+	return true
+}
+
+// pluginsFinish hands res to every plugin.
+func (c *Crawler) pluginsFinish(ctx context.Context, res *result.Result) {
+	for _, plugin := range c.cfg.Plugins {
+		if err := plugin.Finish(ctx, res); err != nil {
+			c.cfg.logger().Error("plugin failed to receive the result", "error", err)
+		}
+	}
+}
+
+// CommandPlugin is a Plugin run as an external program, in any language.
+// The crawler writes one JSON request per line to the program's standard
+// input and reads one JSON response per line from its standard output:
+//
+//	{"hook": "links", "page": "https://example.com/", "links": ["https://example.com/a"]}
+//	{"links": ["https://example.com/a"]}
+//
+//	{"hook": "enqueue", "job": {"url": "https://example.com/a", "source_page": "https://example.com/", "depth": 1, "external": false}}
+//	{"enqueue": false}
+//
+//	{"hook": "finish", "result": {...}}
+//	{}
+//
+// A response without "links" or "enqueue" keeps the default: the links
+// unchanged, the link queued. A response with "error" fails the hook. The
+// program must answer every request, including hooks it does not handle,
+// in order; it runs until its standard input is closed by Close. A program
+// that takes longer than 30 seconds to answer a request is killed, and every
+// later hook fails, so a stalled plugin cannot stall the crawl.
+type CommandPlugin struct {
+	name    string
+	cmd     *exec.Cmd
+	stdin   io.WriteCloser
+	timeout time.Duration
+
+	requests chan []byte // Request lines, written in order by writeRequests
+	lines    chan []byte // Response lines from readResponses, closed at the end of the output
+	readErr  error       // Why the output ended, set before lines is closed
+
+	mu      sync.Mutex // One request at a time
+	pending int        // Responses owed to requests whose caller gave up, discarded first
+	stopped error      // Why the plugin can no longer be called, once it cannot
+}
+
+// errPluginClosed fails hooks called after CommandPlugin.Close.
+var errPluginClosed = errors.New("plugin closed")
+
+// pluginTimeout is how long a CommandPlugin has to answer one request.
+const pluginTimeout = 30 * time.Second
+
+// pluginRequest is a request to a CommandPlugin.
+type pluginRequest struct {
+	Hook   string         `json:"hook"`
+	Page   string         `json:"page,omitempty"`
+	Links  []string       `json:"links,omitempty"`
+	Job    *pluginJob     `json:"job,omitempty"`
+	Result *result.Result `json:"result,omitempty"`
+}
+
+// pluginJob is the CrawlJob sent with an enqueue request.
+type pluginJob struct {
+	URL        string `json:"url"`
+	SourcePage string `json:"source_page,omitempty"`
+	Depth      int    `json:"depth"`
+	External   bool   `json:"external"`
+}
+
+// pluginResponse is a CommandPlugin's answer to a request.
+type pluginResponse struct {
+	Links   *[]string `json:"links"`
+	Enqueue *bool     `json:"enqueue"`
+	Error   string    `json:"error"`
+}
+
+// maxPluginResponse caps the length of one response line.
+const maxPluginResponse = 16 << 20
+
+// StartCommandPlugin starts cmd as a CommandPlugin. The caller may set up
+// cmd's environment, directory, and standard error beforehand; its standard
+// input and output belong to the plugin. Close stops it.
+func StartCommandPlugin(cmd *exec.Cmd) (*CommandPlugin, error) {
+	name := filepath.Base(cmd.Path)
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, fmt.Errorf("plugin %s: %w", name, err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("plugin %s: %w", name, err)
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("start plugin %s: %w", name, err)
+	}
+	p := &CommandPlugin{
+		name:     name,
+		cmd:      cmd,
+		stdin:    stdin,
+		timeout:  pluginTimeout,
+		requests: make(chan []byte),
+		lines:    make(chan []byte),
+	}
+	go p.writeRequests()
+	go p.readResponses(stdout)
+	return p, nil
+}
+
+// writeRequests writes request lines to the plugin until Close, so a
+// plugin that stops reading cannot block the caller. After a failed write
+// the rest are dropped; the plugin has exited, which readResponses reports.
+func (p *CommandPlugin) writeRequests() {
+	var err error
+	for line := range p.requests {
+		if err == nil {
+			_, err = p.stdin.Write(line)
+		}
+	}
+	_ = p.stdin.Close()
+}
+
+// readResponses delivers the plugin's output line by line, then records
+// the error that ended it.
+func (p *CommandPlugin) readResponses(stdout io.Reader) {
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 64<<10), maxPluginResponse)
+	for scanner.Scan() {
+		p.lines <- slices.Clone(scanner.Bytes())
+	}
+	p.readErr = scanner.Err()
+	if p.readErr == nil {
+		p.readErr = io.ErrUnexpectedEOF
+	}
+	close(p.lines)
+}
+
+// Links implements Plugin.
+func (p *CommandPlugin) Links(ctx context.Context, page string, links []string) ([]string, error) {
+	resp, err := p.call(ctx, pluginRequest{Hook: "links", Page: page, Links: links})
+	if err != nil || resp.Links == nil {
+		return links, err
+	}
+	return *resp.Links, nil
+}
+
+// Enqueue implements Plugin.
+func (p *CommandPlugin) Enqueue(ctx context.Context, job CrawlJob) (bool, error) {
+	resp, err := p.call(ctx, pluginRequest{Hook: "enqueue", Job: &pluginJob{
+		URL:        job.URL,
+		SourcePage: job.SourcePage,
+		Depth:      job.Depth,
+		External:   job.IsExternal,
+	}})
+	if err != nil || resp.Enqueue == nil {
+		return true, err
+	}
+	return *resp.Enqueue, nil
+}
+
+// Finish implements Plugin.
+func (p *CommandPlugin) Finish(ctx context.Context, res *result.Result) error {
+	_, err := p.call(ctx, pluginRequest{Hook: "finish", Result: res})
+	return err
+}
+
+// call sends req and reads the response to it. It gives up when ctx is
+// done, leaving the response to be discarded by the next call, and kills
+// the plugin if it does not answer within its timeout.
+func (p *CommandPlugin) call(ctx context.Context, req pluginRequest) (pluginResponse, error) {
+	var resp pluginResponse
+	if err := ctx.Err(); err != nil {
+		return resp, fmt.Errorf("plugin %s %s: %w", p.name, req.Hook, err)
+	}
+	line, err := json.Marshal(req)
+	if err != nil {
+		return resp, fmt.Errorf("plugin %s %s: encode request: %w", p.name, req.Hook, err)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.stopped != nil {
+		return resp, fmt.Errorf("plugin %s %s: %w", p.name, req.Hook, p.stopped)
+	}
+	timer := time.NewTimer(p.timeout)
+	defer timer.Stop()
+	// next returns the next response line, or why there is none
+	next := func() ([]byte, error) {
+		select {
+		case data, ok := <-p.lines:
+			if !ok {
+				return nil, p.readErr
+			}
+			return data, nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-timer.C:
+			p.kill()
+			return nil, p.stopped
+		}
+	}
+
+	for p.pending > 0 {
+		if _, err := next(); err != nil {
+			return resp, fmt.Errorf("plugin %s %s: read response: %w", p.name, req.Hook, err)
+		}
+		p.pending--
+	}
+	select {
+	case p.requests <- append(line, '\n'):
+	case <-ctx.Done():
+		return resp, fmt.Errorf("plugin %s %s: write request: %w", p.name, req.Hook, ctx.Err())
+	case <-timer.C:
+		p.kill()
+		return resp, fmt.Errorf("plugin %s %s: write request: %w", p.name, req.Hook, p.stopped)
+	}
+	data, err := next()
+	if err != nil {
+		if errors.Is(err, ctx.Err()) {
+			p.pending++
+		}
+		return resp, fmt.Errorf("plugin %s %s: read response: %w", p.name, req.Hook, err)
+	}
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return resp, fmt.Errorf("plugin %s %s: decode response: %w", p.name, req.Hook, err)
+	}
+	if resp.Error != "" {
+		return resp, fmt.Errorf("plugin %s %s: %s", p.name, req.Hook, resp.Error)
+	}
+	return resp, nil
+}
+
+// kill stops a plugin that did not answer in time. Must be called with
+// p.mu held.
+func (p *CommandPlugin) kill() {
+	p.stopped = fmt.Errorf("no response within %s; plugin killed", p.timeout)
+	_ = p.cmd.Process.Kill()
+}
+
+// Close closes the plugin's standard input and waits for it to exit. A
+// plugin still writing output after its timeout is killed.
+func (p *CommandPlugin) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if errors.Is(p.stopped, errPluginClosed) {
+		return fmt.Errorf("plugin %s: %w", p.name, errPluginClosed)
+	}
+	p.stopped = errPluginClosed
+	close(p.requests)
+
+	// Wait must not run before the output has been read to the end
+	timer := time.NewTimer(p.timeout)
+	defer timer.Stop()
+	for open := true; open; {
+		select {
+		case _, open = <-p.lines:
+		case <-timer.C:
+			_ = p.cmd.Process.Kill()
+		}
+	}
+	if err := p.cmd.Wait(); err != nil {
+		return fmt.Errorf("plugin %s: %w", p.name, err)
+	}
+	return nil
+}
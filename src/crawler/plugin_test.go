@@ -0,0 +1,216 @@
+package crawler
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lukemcguire/zombiecrawl/result"
+)
+
+// policyPlugin declines links containing "private", adds /extra to the
+// start page's links, and keeps the result.
+type policyPlugin struct {
+	start    string
+	finished *result.Result
+}
+
+func (p *policyPlugin) Links(_ context.Context, page string, links []string) ([]string, error) {
+	if page == p.start {
+		links = append(links, p.start+"extra")
+	}
+	return links, nil
+}
+
+func (p *policyPlugin) Enqueue(_ context.Context, job CrawlJob) (bool, error) {
+	return !strings.Contains(job.URL, "private"), nil
+}
+
+func (p *policyPlugin) Finish(_ context.Context, res *result.Result) error {
+	p.finished = res
+	return nil
+}
+
+// failingPlugin fails every hook.
+type failingPlugin struct{}
+
+func (failingPlugin) Links(context.Context, string, []string) ([]string, error) {
+	return nil, fmt.Errorf("links failed")
+}
+
+func (failingPlugin) Enqueue(context.Context, CrawlJob) (bool, error) {
+	return false, fmt.Errorf("enqueue failed")
+}
+
+func (failingPlugin) Finish(context.Context, *result.Result) error {
+	return fmt.Errorf("finish failed")
+}
+
+func newPluginSite(t *testing.T) *httptest.Server {
+	t.Helper()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/":
+			_, _ = fmt.Fprint(w, `<a href="/public">public</a> <a href="/private">private</a>`)
+		case "/public", "/private":
+			_, _ = fmt.Fprint(w, `<p>page</p>`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func TestRun_Plugins(t *testing.T) {
+	ts := newPluginSite(t)
+	plugin := &policyPlugin{start: ts.URL + "/"}
+	cfg := DefaultConfig(ts.URL)
+	cfg.Delay = 1
+	cfg.Plugins = []Plugin{failingPlugin{}, plugin}
+	c, err := New(cfg, nil)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	res, err := c.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run() error: %v", err)
+	}
+
+	// /, /public, and the /extra link the plugin added, which is broken
+	if res.Stats.TotalChecked != 3 {
+		t.Errorf("TotalChecked = %d, want 3", res.Stats.TotalChecked)
+	}
+	if len(res.BrokenLinks) != 1 || res.BrokenLinks[0].URL != ts.URL+"/extra" {
+		t.Errorf("BrokenLinks = %+v, want %s/extra", res.BrokenLinks, ts.URL)
+	}
+	want := []result.SkippedLink{{URL: ts.URL + "/private", SourcePage: ts.URL + "/", Reason: "declined by plugin"}}
+	if !slices.Equal(res.Skipped, want) {
+		t.Errorf("Skipped = %+v, want %+v", res.Skipped, want)
+	}
+	if plugin.finished != res {
+		t.Error("plugin did not receive the result")
+	}
+}
+
+func TestCommandPlugin(t *testing.T) {
+	ts := newPluginSite(t)
+	plugin := startHelperPlugin(t, "default")
+
+	cfg := DefaultConfig(ts.URL)
+	cfg.Delay = 1
+	cfg.Plugins = []Plugin{plugin}
+	c, err := New(cfg, nil)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	res, err := c.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run() error: %v", err)
+	}
+	if err := plugin.Close(); err != nil {
+		t.Fatalf("Close() error: %v", err)
+	}
+
+	if res.Stats.TotalChecked != 2 {
+		t.Errorf("TotalChecked = %d, want 2", res.Stats.TotalChecked)
+	}
+	if len(res.Skipped) != 1 || res.Skipped[0].URL != ts.URL+"/private" {
+		t.Errorf("Skipped = %+v, want %s/private", res.Skipped, ts.URL)
+	}
+	if _, err := plugin.Enqueue(context.Background(), CrawlJob{URL: ts.URL}); err == nil {
+		t.Error("Enqueue() after Close() error = nil, want an error")
+	}
+}
+
+// startHelperPlugin runs TestCommandPluginHelper as a plugin in mode.
+func startHelperPlugin(t *testing.T, mode string) *CommandPlugin {
+	t.Helper()
+	cmd := exec.Command(os.Args[0], "-test.run=^TestCommandPluginHelper$")
+	cmd.Env = append(os.Environ(), "ZOMBIECRAWL_PLUGIN_HELPER="+mode)
+	plugin, err := StartCommandPlugin(cmd)
+	if err != nil {
+		t.Fatalf("StartCommandPlugin() error: %v", err)
+	}
+	return plugin
+}
+
+func TestCommandPlugin_Timeout(t *testing.T) {
+	plugin := startHelperPlugin(t, "stall")
+	plugin.timeout = 100 * time.Millisecond
+
+	started := time.Now()
+	if _, err := plugin.Enqueue(context.Background(), CrawlJob{URL: "https://example.com/"}); err == nil {
+		t.Fatal("Enqueue() error = nil, want a timeout")
+	}
+	if elapsed := time.Since(started); elapsed > 5*time.Second {
+		t.Errorf("Enqueue() took %s, want it to give up after the timeout", elapsed)
+	}
+	if _, err := plugin.Enqueue(context.Background(), CrawlJob{URL: "https://example.com/"}); err == nil || !strings.Contains(err.Error(), "killed") {
+		t.Errorf("Enqueue() after timeout error = %v, want the plugin killed", err)
+	}
+	// The killed plugin exits with an error, but Close must not hang
+	_ = plugin.Close()
+}
+
+func TestCommandPlugin_Cancel(t *testing.T) {
+	plugin := startHelperPlugin(t, "slow")
+	defer func() { _ = plugin.Close() }()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := plugin.Enqueue(ctx, CrawlJob{URL: "https://example.com/private"}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Enqueue() with expiring context error = %v, want deadline exceeded", err)
+	}
+	// The answer to the abandoned request must not be taken for this one
+	ok, err := plugin.Enqueue(context.Background(), CrawlJob{URL: "https://example.com/public"})
+	if err != nil || !ok {
+		t.Errorf("Enqueue(public) = %v, %v; want true", ok, err)
+	}
+}
+
+// TestCommandPluginHelper is the plugin program of the CommandPlugin tests:
+// it declines links containing "private" and fails on a finish without a
+// result. In "slow" mode it answers each request after 200ms; in "stall"
+// mode it never answers.
+func TestCommandPluginHelper(t *testing.T) {
+	mode := os.Getenv("ZOMBIECRAWL_PLUGIN_HELPER")
+	if mode == "" {
+		t.Skip("run as a plugin by the CommandPlugin tests")
+	}
+	scanner := bufio.NewScanner(os.Stdin)
+	scanner.Buffer(nil, maxPluginResponse)
+	out := json.NewEncoder(os.Stdout)
+	for scanner.Scan() {
+		switch mode {
+		case "stall":
+			time.Sleep(time.Hour)
+		case "slow":
+			time.Sleep(200 * time.Millisecond)
+		}
+		var req pluginRequest
+		if err := json.Unmarshal(scanner.Bytes(), &req); err != nil {
+			_ = out.Encode(map[string]string{"error": err.Error()})
+			continue
+		}
+		switch {
+		case req.Hook == "enqueue":
+			_ = out.Encode(map[string]bool{"enqueue": !strings.Contains(req.Job.URL, "private")})
+		case req.Hook == "finish" && req.Result == nil:
+			_ = out.Encode(map[string]string{"error": "no result"})
+		default:
+			_ = out.Encode(struct{}{})
+		}
+	}
+	os.Exit(0)
+}
@@ -0,0 +1,68 @@
+package crawler
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Preset is a named crawler identity, so a crawl can simulate what a search
+// engine crawler or a browser would reach. The HTTP client keeps no cookie
+// jar, so every preset crawls without cookies, as search crawlers do.
+type Preset struct {
+	Name         string // Preset name used with ApplyPreset
+	UserAgent    string // User-Agent header sent with every request
+	RobotsAgent  string // Token matched against robots.txt groups
+	StrictRobots bool   // Evaluate robots.txt with Google's semantics
+	IgnoreRobots bool   // Skip robots.txt entirely
+}
+
+// presets lists the built-in identities by name.
+var presets = map[string]Preset{
+	"googlebot": {
+		Name:         "googlebot",
+		UserAgent:    "Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)",
+		RobotsAgent:  "Googlebot",
+		StrictRobots: true,
+	},
+	"bingbot": {
+		Name:        "bingbot",
+		UserAgent:   "Mozilla/5.0 (compatible; bingbot/2.0; +http://www.bing.com/bingbot.htm)",
+		RobotsAgent: "bingbot",
+	},
+	"browser": {
+		Name:         "browser",
+		UserAgent:    "Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/126.0 Safari/537.36",
+		IgnoreRobots: true,
+	},
+}
+
+// PresetNames returns the built-in preset names, sorted.
+func PresetNames() []string {
+	names := make([]string, 0, len(presets))
+	for name := range presets {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// LookupPreset returns the built-in preset with the given name (case-insensitive).
+func LookupPreset(name string) (Preset, error) {
+	preset, ok := presets[strings.ToLower(name)]
+	if !ok {
+		return Preset{}, fmt.Errorf("unknown preset %q (want one of %s)", name, strings.Join(PresetNames(), ", "))
+	}
+	return preset, nil
+}
+
+// Apply sets cfg's identity fields from the preset, replacing any user agent
+// rotation or per-host overrides so every request uses the preset identity.
+func (p Preset) Apply(cfg *Config) {
+	cfg.UserAgent = p.UserAgent
+	cfg.UserAgents = nil
+	cfg.HostUserAgents = nil
+	cfg.RobotsAgent = p.RobotsAgent
+	cfg.StrictRobots = p.StrictRobots
+	cfg.IgnoreRobots = p.IgnoreRobots
+}
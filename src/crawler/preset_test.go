@@ -0,0 +1,41 @@
+package crawler
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestLookupPreset(t *testing.T) {
+	preset, err := LookupPreset("GoogleBot")
+	if err != nil {
+		t.Fatalf("LookupPreset() error: %v", err)
+	}
+	if preset.Name != "googlebot" || preset.RobotsAgent != "Googlebot" || !preset.StrictRobots {
+		t.Errorf("googlebot preset = %+v", preset)
+	}
+
+	if _, err := LookupPreset("netscape"); err == nil {
+		t.Error("expected error for unknown preset")
+	}
+
+	if names := PresetNames(); !slices.IsSorted(names) || !slices.Contains(names, "browser") {
+		t.Errorf("PresetNames() = %v, want sorted list including browser", names)
+	}
+}
+
+func TestPreset_Apply(t *testing.T) {
+	cfg := Config{
+		UserAgent:      "custom/1.0",
+		UserAgents:     []string{"a", "b"},
+		HostUserAgents: []HostUserAgent{{Pattern: "*", UserAgent: "c"}},
+	}
+	preset, _ := LookupPreset("browser")
+	preset.Apply(&cfg)
+
+	if cfg.UserAgent != preset.UserAgent || cfg.UserAgents != nil || cfg.HostUserAgents != nil {
+		t.Errorf("Apply() left other identities in place: %+v", cfg)
+	}
+	if !cfg.IgnoreRobots || cfg.RobotsAgent != "" {
+		t.Errorf("browser preset should ignore robots, got IgnoreRobots=%v RobotsAgent=%q", cfg.IgnoreRobots, cfg.RobotsAgent)
+	}
+}
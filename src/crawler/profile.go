@@ -0,0 +1,86 @@
+package crawler
+
+import (
+	"fmt"
+	"time"
+)
+
+// Profile is a named politeness level. It bundles the settings that decide
+// how hard a crawl hits a server, so users pick a level instead of tuning
+// concurrency, delay, burst, and adaptive rate bounds against each other.
+type Profile string
+
+const (
+	// ProfileGentle suits small or shared hosts: two workers, at most two
+	// requests per second, one at a time to any host, and slow retries.
+	ProfileGentle Profile = "gentle"
+	// ProfileDefault matches the crawler's built-in defaults.
+	ProfileDefault Profile = "default"
+	// ProfileAggressive suits sites you own behind a CDN: many workers and
+	// an adaptive rate allowed to climb to 500 requests per second, with
+	// half the workers at most on any one host so external sites linked to
+	// are not hit with all of them.
+	ProfileAggressive Profile = "aggressive"
+)
+
+// ParseProfile converts a user-supplied profile name into a Profile. An
+// empty name selects ProfileDefault.
+func ParseProfile(name string) (Profile, error) {
+	switch Profile(name) {
+	case "", ProfileDefault:
+		return ProfileDefault, nil
+	case ProfileGentle, ProfileAggressive:
+		return Profile(name), nil
+	default:
+		return "", fmt.Errorf("unknown profile %q (want gentle, default, or aggressive)", name)
+	}
+}
+
+// ProfileSettings are the crawl settings a Profile chooses.
+type ProfileSettings struct {
+	Concurrency int           // Requests in flight at once
+	MaxPerHost  int           // Requests in flight to any one host (0 = no cap beyond Concurrency)
+	Delay       int           // Delay between requests in milliseconds, setting the starting rate
+	Burst       int           // Requests allowed back-to-back (0 = rate rounded up)
+	MinRate     float64       // Lowest adaptive rate in requests per second
+	MaxRate     float64       // Highest adaptive rate in requests per second
+	MaxRetries  int           // Retries for transient errors
+	RetryDelay  time.Duration // Base delay between retries
+}
+
+// Settings returns the settings p stands for. Unknown profiles get the
+// ProfileDefault settings.
+func (p Profile) Settings() ProfileSettings {
+	switch p {
+	case ProfileGentle:
+		return ProfileSettings{
+			Concurrency: 2,
+			MaxPerHost:  1,
+			Delay:       500,
+			Burst:       1,
+			MinRate:     0.5,
+			MaxRate:     2,
+			MaxRetries:  2,
+			RetryDelay:  5 * time.Second,
+		}
+	case ProfileAggressive:
+		return ProfileSettings{
+			Concurrency: 50,
+			MaxPerHost:  25,
+			Delay:       10,
+			MinRate:     20,
+			MaxRate:     500,
+			MaxRetries:  1,
+			RetryDelay:  500 * time.Millisecond,
+		}
+	default:
+		return ProfileSettings{
+			Concurrency: 10,
+			Delay:       100,
+			MinRate:     DefaultMinRate,
+			MaxRate:     DefaultMaxRate,
+			MaxRetries:  DefaultRetryPolicy().MaxRetries,
+			RetryDelay:  DefaultRetryPolicy().BaseDelay,
+		}
+	}
+}
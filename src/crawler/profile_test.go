@@ -0,0 +1,41 @@
+package crawler
+
+import (
+	"testing"
+)
+
+func TestParseProfile(t *testing.T) {
+	for name, want := range map[string]Profile{
+		"":           ProfileDefault,
+		"default":    ProfileDefault,
+		"gentle":     ProfileGentle,
+		"aggressive": ProfileAggressive,
+	} {
+		got, err := ParseProfile(name)
+		if err != nil || got != want {
+			t.Errorf("ParseProfile(%q) = %q, %v; want %q", name, got, err, want)
+		}
+	}
+	if _, err := ParseProfile("ludicrous"); err == nil {
+		t.Error("expected error for unknown profile")
+	}
+}
+
+func TestProfile_SettingsAreOrdered(t *testing.T) {
+	gentle, def, aggressive := ProfileGentle.Settings(), ProfileDefault.Settings(), ProfileAggressive.Settings()
+	if !(gentle.Concurrency < def.Concurrency && def.Concurrency < aggressive.Concurrency) {
+		t.Errorf("concurrency not increasing: %d, %d, %d", gentle.Concurrency, def.Concurrency, aggressive.Concurrency)
+	}
+	if !(gentle.MaxRate < def.MaxRate && def.MaxRate < aggressive.MaxRate) {
+		t.Errorf("max rate not increasing: %v, %v, %v", gentle.MaxRate, def.MaxRate, aggressive.MaxRate)
+	}
+	if gentle.MaxPerHost != 1 || aggressive.MaxPerHost >= aggressive.Concurrency {
+		t.Errorf("per-host caps = %d, %d; want gentle one at a time and aggressive below its concurrency", gentle.MaxPerHost, aggressive.MaxPerHost)
+	}
+	for _, settings := range []ProfileSettings{gentle, def, aggressive} {
+		// The starting rate set by Delay must fall within the adaptive bounds
+		if start := 1000 / float64(settings.Delay); start < settings.MinRate || start > settings.MaxRate {
+			t.Errorf("starting rate %v outside [%v, %v]", start, settings.MinRate, settings.MaxRate)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package crawler
+
+import (
+	"sync"
+	"time"
+)
+
+// DefaultProgressInterval is how often Config.OnProgress is called when
+// Config.ProgressInterval is unset.
+const DefaultProgressInterval = 5 * time.Second
+
+// Progress is a snapshot of a running crawl, passed to Config.OnProgress.
+type Progress struct {
+	Checked  int           // Links checked so far
+	Broken   int           // Broken links found so far
+	Queued   int           // Links waiting to be checked
+	InFlight int           // Checks running
+	Elapsed  time.Duration // Time since the crawl started
+	Rate     float64       // Checks per second since the crawl started
+	Done     bool          // Set on the last call, when Run finishes
+}
+
+// watchProgress calls Config.OnProgress every Config.ProgressInterval with
+// the crawl started at start, until the returned function is called. The
+// function waits for a call in progress and may be called more than once.
+func (c *Crawler) watchProgress(start time.Time) func() {
+	if c.cfg.OnProgress == nil {
+		return func() {}
+	}
+	interval := c.cfg.ProgressInterval
+	if interval <= 0 {
+		interval = DefaultProgressInterval
+	}
+	stop := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+			}
+			c.mu.Lock()
+			progress := Progress{Checked: c.total, Broken: len(c.results)}
+			c.mu.Unlock()
+			progress.Queued = int(c.queued.Load())
+			progress.InFlight = c.inFlight.count()
+			progress.Elapsed = c.cfg.since(start)
+			c.reportProgress(progress)
+		}
+	}()
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(stop)
+			<-stopped
+		})
+	}
+}
+
+// reportProgress fills in progress.Rate and passes it to Config.OnProgress.
+func (c *Crawler) reportProgress(progress Progress) {
+	if c.cfg.OnProgress == nil {
+		return
+	}
+	if seconds := progress.Elapsed.Seconds(); seconds > 0 {
+		progress.Rate = float64(progress.Checked) / seconds
+	}
+	c.cfg.OnProgress(progress)
+}
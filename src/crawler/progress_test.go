@@ -0,0 +1,58 @@
+package crawler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRun_OnProgress(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(20 * time.Millisecond)
+		switch r.URL.Path {
+		case "/":
+			_, _ = fmt.Fprint(w, `<a href="/a">a</a> <a href="/b">b</a> <a href="/missing">missing</a>`)
+		case "/a", "/b":
+			_, _ = fmt.Fprint(w, `<p>page</p>`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	var reports []Progress
+	cfg := DefaultConfig(ts.URL)
+	cfg.Delay = 1
+	cfg.Concurrency = 1
+	cfg.ProgressInterval = 5 * time.Millisecond
+	cfg.OnProgress = func(p Progress) { reports = append(reports, p) }
+	c, err := New(cfg, nil)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	res, err := c.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run() error: %v", err)
+	}
+
+	if len(reports) < 2 {
+		t.Fatalf("got %d progress reports, want periodic ones and a final one", len(reports))
+	}
+	sawQueued := false
+	for _, p := range reports[:len(reports)-1] {
+		if p.Done {
+			t.Errorf("periodic report %+v has Done set", p)
+		}
+		sawQueued = sawQueued || p.Queued > 0
+	}
+	if !sawQueued {
+		t.Errorf("no report saw queued links: %+v", reports)
+	}
+	last := reports[len(reports)-1]
+	if !last.Done || last.Checked != res.Stats.TotalChecked || last.Broken != 1 || last.Queued != 0 || last.Rate <= 0 {
+		t.Errorf("final report = %+v, want Done with %d checked, 1 broken, and a rate", last, res.Stats.TotalChecked)
+	}
+}
@@ -2,7 +2,9 @@ package crawler
 
 import (
 	"context"
+	"fmt"
 	"math"
+	"net/http"
 	"sync"
 	"time"
 
@@ -10,13 +12,13 @@ import (
 )
 
 const (
-	// minRateFloor is the minimum rate in requests per second.
+	// DefaultMinRate is the default minimum rate in requests per second.
 	// The adaptive limiter will never drop below this to avoid crawling too slowly.
-	minRateFloor = 5.0
+	DefaultMinRate = 5.0
 
-	// maxRateCeiling is the maximum rate in requests per second.
+	// DefaultMaxRate is the default maximum rate in requests per second.
 	// Prevents the limiter from becoming too aggressive.
-	maxRateCeiling = 100.0
+	DefaultMaxRate = 100.0
 
 	// emaAlpha is the smoothing factor for Exponential Moving Average.
 	// Lower values = more smoothing (slower to react to changes).
@@ -30,6 +32,18 @@ const (
 	// backoffFactor limits how much the rate can drop in a single step.
 	// This prevents a single bad RTT from crashing the rate.
 	backoffFactor = 0.5
+
+	// throttleCooldown is how many fast RTT observations after a 429/503
+	// recover at slowRecoveryFactor instead of recoveryFactor.
+	throttleCooldown = 20
+
+	// slowRecoveryFactor is the multiplier for rate increase while cooling
+	// down from a throttle response. 1.05 = 5% increase per good RTT.
+	slowRecoveryFactor = 1.05
+
+	// minRateChange is the smallest relative rate change applied to the
+	// limiter, so tiny adjustments don't churn the token bucket.
+	minRateChange = 0.01
 )
 
 // AdaptiveLimiter dynamically adjusts rate limiting based on server response times.
@@ -48,27 +62,96 @@ type AdaptiveLimiter struct {
 
 	// disabled indicates adaptive behavior is disabled (use fixed rate)
 	disabled bool
+
+	// burst is the configured bucket size; 0 follows the rate (rounded up)
+	burst int
+
+	// cooldown counts remaining slow-recovery observations after a throttle response
+	cooldown int
+
+	// minRate and maxRate bound the adaptive rate in requests per second
+	minRate float64
+	maxRate float64
+
+	// clock times token refills and waits; nil is the system clock
+	clock Clock
 }
 
 // NewAdaptiveLimiter creates an adaptive rate limiter with the given initial rate
-// and target RTT. The limiter will adjust its rate based on observed response times.
+// and target RTT. The limiter will adjust its rate based on observed response times,
+// staying within [DefaultMinRate, DefaultMaxRate].
 func NewAdaptiveLimiter(initialRPS int, targetRTT time.Duration) *AdaptiveLimiter {
-	// Clamp initial rate to valid bounds
-	clampedRPS := clampRateFloat(float64(initialRPS))
-
-	return &AdaptiveLimiter{
-		limiter:     rate.NewLimiter(rate.Limit(clampedRPS), int(clampedRPS)),
-		targetRTT:   targetRTT,
-		currentRate: clampedRPS,
-		emaRTT:      targetRTT, // Initialize EMA at target
-		disabled:    false,
+	return NewAdaptiveLimiterWithBounds(initialRPS, targetRTT, DefaultMinRate, DefaultMaxRate)
+}
+
+// NewAdaptiveLimiterWithBounds creates an adaptive rate limiter whose rate is
+// kept within [minRPS, maxRPS] requests per second. Non-positive bounds fall
+// back to DefaultMinRate and DefaultMaxRate; if maxRPS is below minRPS it is
+// raised to minRPS.
+func NewAdaptiveLimiterWithBounds(initialRPS int, targetRTT time.Duration, minRPS, maxRPS float64) *AdaptiveLimiter {
+	if minRPS <= 0 {
+		minRPS = DefaultMinRate
 	}
+	if maxRPS <= 0 {
+		maxRPS = DefaultMaxRate
+	}
+	maxRPS = math.Max(maxRPS, minRPS)
+
+	a := &AdaptiveLimiter{
+		targetRTT: targetRTT,
+		emaRTT:    targetRTT, // Initialize EMA at target
+		disabled:  false,
+		minRate:   minRPS,
+		maxRate:   maxRPS,
+	}
+	// Clamp initial rate to valid bounds
+	a.currentRate = a.clamp(float64(initialRPS))
+	a.limiter = rate.NewLimiter(rate.Limit(a.currentRate), a.burstFor(a.currentRate))
+	return a
 }
 
 // Wait blocks until the rate limiter allows the next request or the context is cancelled.
-// It is safe to call Wait from multiple goroutines concurrently.
+// It fails at once if ctx's deadline would pass first. It is safe to call
+// Wait from multiple goroutines concurrently.
 func (a *AdaptiveLimiter) Wait(ctx context.Context) error {
-	return a.limiter.Wait(ctx)
+	a.mu.RLock()
+	clock := clockOrSystem(a.clock)
+	a.mu.RUnlock()
+	return waitLimiter(ctx, a.limiter, clock, 1)
+}
+
+// waitLimiter is limiter.WaitN on clock: it blocks until limiter allows n
+// events or ctx is done, failing at once if ctx's deadline would pass first.
+func waitLimiter(ctx context.Context, limiter *rate.Limiter, clock Clock, n int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	now := clock.Now()
+	reservation := limiter.ReserveN(now, n)
+	if !reservation.OK() {
+		return fmt.Errorf("rate limiter wait: %d exceeds burst %d", n, limiter.Burst())
+	}
+	delay := reservation.DelayFrom(now)
+	if delay == 0 {
+		return nil
+	}
+	if deadline, ok := ctx.Deadline(); ok && deadline.Sub(now) < delay {
+		reservation.CancelAt(now)
+		return fmt.Errorf("rate limiter wait of %s would exceed context deadline", delay)
+	}
+	if err := clock.Sleep(ctx, delay); err != nil {
+		reservation.CancelAt(clock.Now())
+		return err
+	}
+	return nil
+}
+
+// SetClock makes the limiter refill tokens and wait on c instead of the
+// system clock.
+func (a *AdaptiveLimiter) SetClock(c Clock) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.clock = c
 }
 
 // ObserveRTT records a response time observation and adjusts the rate accordingly.
@@ -102,34 +185,99 @@ func (a *AdaptiveLimiter) ObserveRTT(rtt time.Duration) {
 		} else {
 			newRate = proposedRate
 		}
+	} else if a.cooldown > 0 {
+		// Recently throttled - recover cautiously even though responses are fast
+		a.cooldown--
+		newRate = a.currentRate * slowRecoveryFactor
 	} else {
 		// Server is faster than target - increase rate gradually (10% per good RTT)
 		newRate = a.currentRate * recoveryFactor
 	}
 
-	// Clamp to valid bounds
-	newRate = clampRateFloat(newRate)
+	a.applyRate(newRate)
+}
+
+// ObserveStatus records the HTTP status of a completed request. A 429 Too
+// Many Requests or 503 Service Unavailable halves the rate immediately and
+// starts a cooldown during which ObserveRTT recovers more slowly, so servers
+// that answer quickly with throttling responses are not hammered. Other
+// status codes are ignored; RTT remains the signal for healthy responses.
+func (a *AdaptiveLimiter) ObserveStatus(code int) {
+	if code != http.StatusTooManyRequests && code != http.StatusServiceUnavailable {
+		return
+	}
 
-	// Update rate if changed significantly (more than 0.1 RPS)
-	if math.Abs(newRate-a.currentRate) > 0.1 {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.disabled {
+		return
+	}
+	a.cooldown = throttleCooldown
+	a.applyRate(a.currentRate * backoffFactor)
+}
+
+// applyRate clamps newRate to the limiter's bounds and applies it if it
+// differs meaningfully from the current rate. Callers must hold a.mu.
+func (a *AdaptiveLimiter) applyRate(newRate float64) {
+	newRate = a.clamp(newRate)
+	if math.Abs(newRate-a.currentRate) > a.currentRate*minRateChange {
 		a.currentRate = newRate
 		a.limiter.SetLimit(rate.Limit(newRate))
-		a.limiter.SetBurst(int(math.Ceil(newRate)))
+		a.limiter.SetBurst(a.burstFor(newRate))
 	}
 }
 
 // SetRate manually overrides the current rate and disables adaptive behavior.
 // Use this when the user explicitly sets a rate via CLI flag.
-// The rate is clamped to the [minRateFloor, maxRateCeiling] range.
+// The rate is clamped to the limiter's [min, max] bounds.
 func (a *AdaptiveLimiter) SetRate(rps int) {
 	a.mu.Lock()
 	defer a.mu.Unlock()
 
-	clamped := clampRateFloat(float64(rps))
+	clamped := a.clamp(float64(rps))
 	a.currentRate = clamped
 	a.disabled = true // Manual override disables adaptation
 	a.limiter.SetLimit(rate.Limit(clamped))
-	a.limiter.SetBurst(int(math.Ceil(clamped)))
+	a.limiter.SetBurst(a.burstFor(clamped))
+}
+
+// SetFixedRate sets an exact, possibly fractional, rate in requests per second
+// and disables adaptive behavior. Unlike SetRate it is not clamped to the
+// adaptive floor, so polite rates below one request per second are possible.
+// Non-positive rates are ignored. burst sets how many requests may go out
+// back-to-back; 0 uses the rate rounded up.
+func (a *AdaptiveLimiter) SetFixedRate(rps float64, burst int) {
+	if rps <= 0 {
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	rps = math.Min(rps, a.maxRate)
+	a.currentRate = rps
+	a.disabled = true
+	a.burst = max(burst, 0)
+	a.limiter.SetLimit(rate.Limit(rps))
+	a.limiter.SetBurst(a.burstFor(rps))
+}
+
+// SetBurst sets how many requests may go out back-to-back, keeping the
+// current rate. 0 restores the default of the rate rounded up.
+func (a *AdaptiveLimiter) SetBurst(burst int) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.burst = max(burst, 0)
+	a.limiter.SetBurst(a.burstFor(a.currentRate))
+}
+
+// burstFor returns the bucket size to use at rps. Callers must hold a.mu.
+func (a *AdaptiveLimiter) burstFor(rps float64) int {
+	if a.burst > 0 {
+		return a.burst
+	}
+	return max(int(math.Ceil(rps)), 1)
 }
 
 // CurrentRate returns the current rate limit in requests per second.
@@ -139,6 +287,14 @@ func (a *AdaptiveLimiter) CurrentRate() int {
 	return int(math.Round(a.currentRate))
 }
 
+// CurrentRPS returns the current rate limit in requests per second without
+// rounding, for rates below one request per second.
+func (a *AdaptiveLimiter) CurrentRPS() float64 {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.currentRate
+}
+
 // EnableAdaptation re-enables adaptive rate limiting after a manual override.
 func (a *AdaptiveLimiter) EnableAdaptation() {
 	a.mu.Lock()
@@ -146,15 +302,9 @@ func (a *AdaptiveLimiter) EnableAdaptation() {
 	a.disabled = false
 }
 
-// clampRateFloat ensures the rate stays within valid bounds (float64 version).
-func clampRateFloat(rps float64) float64 {
-	if rps < minRateFloor {
-		return minRateFloor
-	}
-	if rps > maxRateCeiling {
-		return maxRateCeiling
-	}
-	return rps
+// clamp keeps rps within the limiter's bounds.
+func (a *AdaptiveLimiter) clamp(rps float64) float64 {
+	return min(max(rps, a.minRate), a.maxRate)
 }
 
 // TargetRTT returns the configured target RTT for testing/debugging.
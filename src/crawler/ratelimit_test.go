@@ -288,3 +288,157 @@ func TestAdaptiveLimiter_CurrentEMA(t *testing.T) {
 		t.Errorf("CurrentEMA() = %v, should not exceed observed values significantly", ema)
 	}
 }
+
+func TestAdaptiveLimiter_SetFixedRate(t *testing.T) {
+	limiter := NewAdaptiveLimiter(10, 200*time.Millisecond)
+
+	// 6 requests per minute is below the adaptive floor but must be honoured
+	limiter.SetFixedRate(0.1, 0)
+	if got := limiter.CurrentRPS(); got != 0.1 {
+		t.Errorf("CurrentRPS() = %v, want 0.1", got)
+	}
+	if got := limiter.limiter.Burst(); got != 1 {
+		t.Errorf("Burst() = %d, want 1 for a sub-1 RPS rate", got)
+	}
+
+	// Fixed rates are not adapted
+	limiter.ObserveRTT(10 * time.Millisecond)
+	if got := limiter.CurrentRPS(); got != 0.1 {
+		t.Errorf("CurrentRPS() after ObserveRTT = %v, want 0.1", got)
+	}
+
+	limiter.SetFixedRate(2.5, 4)
+	if got := limiter.limiter.Burst(); got != 4 {
+		t.Errorf("Burst() = %d, want configured 4", got)
+	}
+
+	// Non-positive rates are ignored
+	limiter.SetFixedRate(0, 0)
+	if got := limiter.CurrentRPS(); got != 2.5 {
+		t.Errorf("CurrentRPS() = %v, want unchanged 2.5", got)
+	}
+}
+
+func TestAdaptiveLimiter_SetBurstSurvivesAdaptation(t *testing.T) {
+	limiter := NewAdaptiveLimiter(10, 200*time.Millisecond)
+	limiter.SetBurst(3)
+
+	limiter.ObserveRTT(50 * time.Millisecond)
+	if got := limiter.limiter.Burst(); got != 3 {
+		t.Errorf("Burst() after adaptation = %d, want 3", got)
+	}
+
+	limiter.SetBurst(0)
+	if got := limiter.limiter.Burst(); got != limiter.CurrentRate() {
+		t.Errorf("Burst() = %d, want rate-derived %d", got, limiter.CurrentRate())
+	}
+}
+
+func TestNew_RatePerMinute(t *testing.T) {
+	c, err := New(Config{StartURL: "http://example.com", RatePerMinute: 30, Burst: 2}, nil)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	defer func() { _ = c.visited.Close() }()
+
+	if got := c.limiter.CurrentRPS(); got != 0.5 {
+		t.Errorf("CurrentRPS() = %v, want 0.5", got)
+	}
+	if got := c.limiter.limiter.Burst(); got != 2 {
+		t.Errorf("Burst() = %d, want 2", got)
+	}
+}
+
+func TestNewAdaptiveLimiterWithBounds(t *testing.T) {
+	tests := []struct {
+		name       string
+		initialRPS int
+		minRPS     float64
+		maxRPS     float64
+		wantRPS    float64
+	}{
+		{"below default floor", 2, 0.5, 10, 2},
+		{"above default ceiling", 400, 5, 500, 400},
+		{"clamped to custom floor", 1, 3, 10, 3},
+		{"clamped to custom ceiling", 50, 1, 20, 20},
+		{"zero bounds use defaults", 1, 0, 0, DefaultMinRate},
+		{"max below min raised to min", 1, 8, 4, 8},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limiter := NewAdaptiveLimiterWithBounds(tt.initialRPS, 200*time.Millisecond, tt.minRPS, tt.maxRPS)
+			if got := limiter.CurrentRPS(); got != tt.wantRPS {
+				t.Errorf("CurrentRPS() = %v, want %v", got, tt.wantRPS)
+			}
+		})
+	}
+}
+
+func TestAdaptiveLimiter_CustomBoundsApplyToAdaptation(t *testing.T) {
+	limiter := NewAdaptiveLimiterWithBounds(2, 200*time.Millisecond, 1, 3)
+
+	for range 20 {
+		limiter.ObserveRTT(5 * time.Second)
+	}
+	if got := limiter.CurrentRPS(); got != 1 {
+		t.Errorf("CurrentRPS() after slow responses = %v, want custom floor 1", got)
+	}
+
+	for range 50 {
+		limiter.ObserveRTT(time.Millisecond)
+	}
+	if got := limiter.CurrentRPS(); got != 3 {
+		t.Errorf("CurrentRPS() after fast responses = %v, want custom ceiling 3", got)
+	}
+}
+
+func TestAdaptiveLimiter_ObserveStatus(t *testing.T) {
+	tests := []struct {
+		name    string
+		code    int
+		wantRPS float64
+	}{
+		{"429 halves rate", 429, 20},
+		{"503 halves rate", 503, 20},
+		{"500 ignored", 500, 40},
+		{"200 ignored", 200, 40},
+		{"no response ignored", 0, 40},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limiter := NewAdaptiveLimiter(40, 200*time.Millisecond)
+			limiter.ObserveStatus(tt.code)
+			if got := limiter.CurrentRPS(); got != tt.wantRPS {
+				t.Errorf("CurrentRPS() = %v, want %v", got, tt.wantRPS)
+			}
+		})
+	}
+}
+
+func TestAdaptiveLimiter_ObserveStatus_SlowRecovery(t *testing.T) {
+	throttled := NewAdaptiveLimiter(40, 200*time.Millisecond)
+	throttled.ObserveStatus(429)
+	fresh := NewAdaptiveLimiter(20, 200*time.Millisecond)
+
+	// Same fast responses: the throttled limiter must recover more slowly
+	for range 5 {
+		throttled.ObserveRTT(50 * time.Millisecond)
+		fresh.ObserveRTT(50 * time.Millisecond)
+	}
+	if throttled.CurrentRPS() >= fresh.CurrentRPS() {
+		t.Errorf("throttled rate %v should recover slower than fresh rate %v",
+			throttled.CurrentRPS(), fresh.CurrentRPS())
+	}
+	if throttled.CurrentRPS() <= 20 {
+		t.Errorf("throttled rate %v should still recover", throttled.CurrentRPS())
+	}
+}
+
+func TestAdaptiveLimiter_ObserveStatus_FixedRateUnaffected(t *testing.T) {
+	limiter := NewAdaptiveLimiter(10, 200*time.Millisecond)
+	limiter.SetRate(30)
+	limiter.ObserveStatus(429)
+	if got := limiter.CurrentRate(); got != 30 {
+		t.Errorf("CurrentRate() = %d, want fixed 30", got)
+	}
+}
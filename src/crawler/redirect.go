@@ -0,0 +1,104 @@
+package crawler
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/lukemcguire/zombiecrawl/result"
+)
+
+// DefaultMaxRedirects is the number of redirect hops a check follows when
+// Config.MaxRedirects is unset.
+const DefaultMaxRedirects = 10
+
+// RedirectPolicy selects which redirects a check follows. A redirect that is
+// not followed is terminal: the 3xx response itself is the verdict.
+type RedirectPolicy string
+
+const (
+	// RedirectAlways follows every redirect up to the hop limit (the default).
+	RedirectAlways RedirectPolicy = "always"
+	// RedirectSameHost follows redirects that stay on the requested host and
+	// treats one that leaves it as terminal.
+	RedirectSameHost RedirectPolicy = "same-host"
+	// RedirectNever treats every 3xx response as terminal.
+	RedirectNever RedirectPolicy = "never"
+)
+
+// ParseRedirectPolicy converts a user-supplied policy name into a
+// RedirectPolicy. An empty name selects RedirectAlways.
+func ParseRedirectPolicy(name string) (RedirectPolicy, error) {
+	switch RedirectPolicy(name) {
+	case "", RedirectAlways:
+		return RedirectAlways, nil
+	case RedirectSameHost, RedirectNever:
+		return RedirectPolicy(name), nil
+	default:
+		return "", fmt.Errorf("unknown redirect policy %q (want always, same-host, or never)", name)
+	}
+}
+
+// redirectTracker enforces the redirect policy for a single check and records
+// whether the chain looped back on itself.
+type redirectTracker struct {
+	policy    RedirectPolicy
+	limit     int
+	chain     []string
+	loop      bool
+	downgrade string // First http URL an https request was redirected to
+}
+
+// newRedirectTracker returns a tracker for cfg's redirect settings.
+func newRedirectTracker(cfg Config) *redirectTracker {
+	limit := cfg.MaxRedirects
+	if limit <= 0 {
+		limit = DefaultMaxRedirects
+	}
+	return &redirectTracker{policy: cfg.FollowRedirects, limit: limit}
+}
+
+// reset forgets the current chain before a new request is made.
+func (t *redirectTracker) reset() {
+	t.chain = nil
+	t.loop = false
+	t.downgrade = ""
+}
+
+// check is an http.Client CheckRedirect function. Loops and redirects the
+// policy does not follow stop the chain at the last response; exceeding the
+// hop limit fails the request with result.ErrTooManyRedirects. Redirects from
+// https to http are recorded whether or not they are followed.
+func (t *redirectTracker) check(req *http.Request, via []*http.Request) error {
+	if t.downgrade == "" && req.URL.Scheme == "http" && via[len(via)-1].URL.Scheme == "https" {
+		t.downgrade = req.URL.String()
+	}
+
+	switch t.policy {
+	case RedirectNever:
+		return http.ErrUseLastResponse
+	case RedirectSameHost:
+		if !strings.EqualFold(req.URL.Hostname(), via[0].URL.Hostname()) {
+			return http.ErrUseLastResponse
+		}
+	}
+
+	target := req.URL.String()
+	for _, seen := range t.chain {
+		if seen == target {
+			t.loop = true
+			return http.ErrUseLastResponse
+		}
+	}
+	t.chain = append(t.chain, target)
+
+	if len(via) > t.limit {
+		return fmt.Errorf("stopped after %d redirects: %w", t.limit, result.ErrTooManyRedirects)
+	}
+	return nil
+}
+
+// isRedirect reports whether status is a 3xx redirection.
+func isRedirect(status int) bool {
+	return status >= 300 && status < 400
+}
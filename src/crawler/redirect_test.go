@@ -0,0 +1,135 @@
+package crawler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/lukemcguire/zombiecrawl/result"
+)
+
+func TestParseRedirectPolicy(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    RedirectPolicy
+		wantErr bool
+	}{
+		{"", RedirectAlways, false},
+		{"always", RedirectAlways, false},
+		{"same-host", RedirectSameHost, false},
+		{"never", RedirectNever, false},
+		{"sometimes", "", true},
+	}
+	for _, tt := range tests {
+		got, err := ParseRedirectPolicy(tt.name)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseRedirectPolicy(%q) error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+		if got != tt.want {
+			t.Errorf("ParseRedirectPolicy(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+// newRedirectServer serves /hop/N, which redirects to /hop/N-1 until /hop/0
+// returns a page, plus /loop (redirects to itself) and /away (redirects to
+// the other host).
+func newRedirectServer(t *testing.T, other string) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/hop/{n}", func(w http.ResponseWriter, r *http.Request) {
+		n, _ := strconv.Atoi(r.PathValue("n"))
+		if n == 0 {
+			_, _ = w.Write([]byte("<html></html>"))
+			return
+		}
+		http.Redirect(w, r, "/hop/"+strconv.Itoa(n-1), http.StatusFound)
+	})
+	mux.HandleFunc("/loop", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/loop", http.StatusFound)
+	})
+	mux.HandleFunc("/away", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, other+"/missing", http.StatusMovedPermanently)
+	})
+	ts := httptest.NewServer(mux)
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func TestCheckURL_RedirectLimit(t *testing.T) {
+	ts := newRedirectServer(t, "")
+	cfg := DefaultConfig(ts.URL)
+	cfg.MaxRedirects = 3
+
+	res := CheckURL(context.Background(), &http.Client{}, CrawlJob{URL: ts.URL + "/hop/3"}, cfg)
+	if res.Result != nil {
+		t.Fatalf("3 hops within a limit of 3 reported broken: %+v", res.Result)
+	}
+
+	res = CheckURL(context.Background(), &http.Client{}, CrawlJob{URL: ts.URL + "/hop/4"}, cfg)
+	if res.Result == nil {
+		t.Fatal("4 hops over a limit of 3 reported valid")
+	}
+	if res.Result.ErrorCategory != result.CategoryRedirectLimit {
+		t.Errorf("category = %q, want %q", res.Result.ErrorCategory, result.CategoryRedirectLimit)
+	}
+	if !errors.Is(res.Err, result.ErrTooManyRedirects) {
+		t.Errorf("Err = %v, want ErrTooManyRedirects", res.Err)
+	}
+}
+
+func TestCheckURL_RedirectLoopDistinctFromLimit(t *testing.T) {
+	ts := newRedirectServer(t, "")
+	res := CheckURL(context.Background(), &http.Client{}, CrawlJob{URL: ts.URL + "/loop"}, DefaultConfig(ts.URL))
+	if res.Result == nil || res.Result.ErrorCategory != result.CategoryRedirectLoop {
+		t.Fatalf("result = %+v, want a redirect loop", res.Result)
+	}
+}
+
+func TestCheckURL_RedirectNever(t *testing.T) {
+	ts := newRedirectServer(t, "")
+	cfg := DefaultConfig(ts.URL)
+	cfg.FollowRedirects = RedirectNever
+
+	for _, path := range []string{"/hop/20", "/loop"} {
+		res := CheckURL(context.Background(), &http.Client{}, CrawlJob{URL: ts.URL + path}, cfg)
+		if res.Result != nil {
+			t.Errorf("%s: terminal redirect reported broken: %+v", path, res.Result)
+		}
+		if res.StatusCode != http.StatusFound {
+			t.Errorf("%s: status = %d, want 302", path, res.StatusCode)
+		}
+		if len(res.Links) != 0 {
+			t.Errorf("%s: links extracted from a redirect: %v", path, res.Links)
+		}
+	}
+}
+
+func TestCheckURL_RedirectSameHost(t *testing.T) {
+	other := httptest.NewServer(http.NotFoundHandler())
+	defer other.Close()
+	// Same server, different host name, so the redirect leaves the host.
+	ts := newRedirectServer(t, strings.Replace(other.URL, "127.0.0.1", "localhost", 1))
+	cfg := DefaultConfig(ts.URL)
+	cfg.FollowRedirects = RedirectSameHost
+
+	res := CheckURL(context.Background(), &http.Client{}, CrawlJob{URL: ts.URL + "/hop/2"}, cfg)
+	if res.Result != nil || res.StatusCode != http.StatusOK {
+		t.Errorf("same-host chain: status %d, result %+v; want it followed to 200", res.StatusCode, res.Result)
+	}
+
+	res = CheckURL(context.Background(), &http.Client{}, CrawlJob{URL: ts.URL + "/away", IsExternal: true}, cfg)
+	if res.Result != nil || res.StatusCode != http.StatusMovedPermanently {
+		t.Errorf("cross-host redirect: status %d, result %+v; want a terminal 301", res.StatusCode, res.Result)
+	}
+
+	cfg.FollowRedirects = RedirectAlways
+	res = CheckURL(context.Background(), &http.Client{}, CrawlJob{URL: ts.URL + "/away", IsExternal: true}, cfg)
+	if res.Result == nil || res.StatusCode != http.StatusNotFound {
+		t.Errorf("always: status %d, want the cross-host 404 to be followed", res.StatusCode)
+	}
+}
@@ -0,0 +1,125 @@
+package crawler
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"slices"
+)
+
+// ErrNotReplayed is returned for requests that have no response in the
+// replay archive.
+var ErrNotReplayed = errors.New("not in replay archive")
+
+// ReplayTransport serves responses from a HAR archive instead of the network,
+// so extraction and classification can be re-run deterministically. Requests
+// are matched by method and URL; when a URL was fetched more than once the
+// latest response wins. A HEAD request with no recorded HEAD response is
+// answered from the GET response without its body.
+type ReplayTransport struct {
+	entries map[string]harEntry
+}
+
+// LoadReplay reads a HAR file written by HARRecorder (or any HAR 1.2
+// producer that stores response content).
+func LoadReplay(path string) (*ReplayTransport, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open replay archive: %w", err)
+	}
+	defer file.Close()
+	return ReadReplay(file)
+}
+
+// ReadReplay reads a HAR document from r.
+func ReadReplay(r io.Reader) (*ReplayTransport, error) {
+	var doc harLog
+	if err := json.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("decode replay archive: %w", err)
+	}
+	entries := slices.Clone(doc.Log.Entries)
+	slices.SortStableFunc(entries, func(a, b harEntry) int { return a.StartedDateTime.Compare(b.StartedDateTime) })
+
+	t := &ReplayTransport{entries: make(map[string]harEntry, len(entries))}
+	for _, entry := range entries {
+		t.entries[replayKey(entry.Request.Method, entry.Request.URL)] = entry
+	}
+	return t, nil
+}
+
+// Len returns the number of distinct requests the archive can answer.
+func (t *ReplayTransport) Len() int {
+	return len(t.entries)
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *ReplayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := req.Context().Err(); err != nil {
+		return nil, err
+	}
+	rawURL := req.URL.String()
+	entry, ok := t.entries[replayKey(req.Method, rawURL)]
+	withBody := true
+	if !ok && req.Method == http.MethodHead {
+		entry, ok = t.entries[replayKey(http.MethodGet, rawURL)]
+		withBody = false
+	}
+	if !ok {
+		return nil, fmt.Errorf("%s %s: %w", req.Method, rawURL, ErrNotReplayed)
+	}
+	if entry.Error != "" {
+		return nil, errors.New(entry.Error)
+	}
+
+	var body []byte
+	if withBody && req.Method != http.MethodHead {
+		decoded, err := replayBody(entry.Response.Content)
+		if err != nil {
+			return nil, fmt.Errorf("%s %s: %w", req.Method, rawURL, err)
+		}
+		body = decoded
+	}
+
+	header := make(http.Header, len(entry.Response.Headers))
+	for _, pair := range entry.Response.Headers {
+		header.Add(pair.Name, pair.Value)
+	}
+	proto := entry.Response.HTTPVersion
+	major, minor, ok := http.ParseHTTPVersion(proto)
+	if !ok {
+		proto, major, minor = "HTTP/1.1", 1, 1
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", entry.Response.Status, entry.Response.StatusText),
+		StatusCode:    entry.Response.Status,
+		Proto:         proto,
+		ProtoMajor:    major,
+		ProtoMinor:    minor,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+// replayBody decodes stored response content.
+func replayBody(content harContent) ([]byte, error) {
+	if content.Encoding == "base64" {
+		body, err := base64.StdEncoding.DecodeString(content.Text)
+		if err != nil {
+			return nil, fmt.Errorf("decode replayed body: %w", err)
+		}
+		return body, nil
+	}
+	return []byte(content.Text), nil
+}
+
+// replayKey identifies a request in the archive.
+func replayKey(method, rawURL string) string {
+	return method + " " + rawURL
+}
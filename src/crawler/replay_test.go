@@ -0,0 +1,128 @@
+package crawler
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+)
+
+// recordCrawl crawls a small site with a HAR recorder attached and returns the
+// start URL, the archive and the broken links found.
+func recordCrawl(t *testing.T) (string, []byte, []string) {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = fmt.Fprint(w, `<a href="/about">about</a><a href="/old">old</a>`)
+	})
+	mux.HandleFunc("/about", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprint(w, `<a href="/gone">gone</a>`)
+	})
+	mux.HandleFunc("/old", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/missing", http.StatusFound)
+	})
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	rec := NewHARRecorder()
+	res := runReplayTestCrawl(t, Config{StartURL: ts.URL, HAR: rec})
+
+	var buf bytes.Buffer
+	if err := rec.Write(&buf); err != nil {
+		t.Fatalf("Write() error: %v", err)
+	}
+	return ts.URL, buf.Bytes(), res
+}
+
+func runReplayTestCrawl(t *testing.T, cfg Config) []string {
+	t.Helper()
+	cfg.Concurrency = 2
+	cfg.Delay = 1
+	cfg.RetryPolicy = RetryPolicy{MaxRetries: 0}
+	c, err := New(cfg, nil)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	res, err := c.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run() error: %v", err)
+	}
+	var broken []string
+	for _, link := range res.BrokenLinks {
+		broken = append(broken, fmt.Sprintf("%s %d", link.URL, link.StatusCode))
+	}
+	slices.Sort(broken)
+	return broken
+}
+
+func TestReplay_ReproducesRecordedCrawl(t *testing.T) {
+	startURL, archive, recorded := recordCrawl(t)
+	if len(recorded) != 2 {
+		t.Fatalf("recorded crawl found %v, want /gone and /old", recorded)
+	}
+
+	// The recording server is closed, so any request reaching the network fails.
+	replay, err := ReadReplay(bytes.NewReader(archive))
+	if err != nil {
+		t.Fatalf("ReadReplay() error: %v", err)
+	}
+	replayed := runReplayTestCrawl(t, Config{StartURL: startURL, Transport: replay})
+
+	if fmt.Sprint(replayed) != fmt.Sprint(recorded) {
+		t.Errorf("replayed broken links = %v, want %v", replayed, recorded)
+	}
+}
+
+func TestReplayTransport_MissingRequest(t *testing.T) {
+	replay, err := ReadReplay(bytes.NewReader([]byte(`{"log":{"version":"1.2","entries":[]}}`)))
+	if err != nil {
+		t.Fatalf("ReadReplay() error: %v", err)
+	}
+	client := &http.Client{Transport: replay}
+	_, err = client.Get("http://example.com/")
+	if !errors.Is(err, ErrNotReplayed) {
+		t.Errorf("Get() error = %v, want ErrNotReplayed", err)
+	}
+}
+
+func TestReplayTransport_HeadFallsBackToGet(t *testing.T) {
+	archive := `{"log":{"version":"1.2","entries":[{
+		"startedDateTime":"2026-01-01T00:00:00Z",
+		"request":{"method":"GET","url":"http://example.com/img.png"},
+		"response":{"status":200,"statusText":"OK","httpVersion":"HTTP/1.1",
+			"headers":[{"name":"Content-Type","value":"image/png"}],
+			"content":{"size":3,"mimeType":"image/png","text":"iVBO","encoding":"base64"}}}]}}`
+	replay, err := ReadReplay(bytes.NewReader([]byte(archive)))
+	if err != nil {
+		t.Fatalf("ReadReplay() error: %v", err)
+	}
+	client := &http.Client{Transport: replay}
+
+	resp, err := client.Head("http://example.com/img.png")
+	if err != nil {
+		t.Fatalf("Head() error: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "image/png" {
+		t.Errorf("HEAD = %d %q, want 200 image/png", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+
+	resp, err = client.Get("http://example.com/img.png")
+	if err != nil {
+		t.Fatalf("Get() error: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if !bytes.Equal(body, []byte{0x89, 'P', 'N'}) {
+		t.Errorf("GET body = %q, want the decoded base64 content", body)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"net"
 	"net/http"
 	"time"
@@ -30,19 +31,48 @@ func DefaultRetryPolicy() RetryPolicy {
 
 // CheckURLWithRetry wraps CheckURL with exponential backoff retry logic.
 // It retries on transient failures (network errors, 5xx, 429) but not on
-// permanent failures (4xx except 429).
+// permanent failures (4xx except 429). The decision is delegated to
+// cfg.RetryClassifier when set. Backoff waits run on cfg.Clock and end with
+// ctx, and a retry is not attempted if ctx's deadline would pass before the
+// backoff does, or if the host has used up cfg.RetryBudget. Only GET and
+// HEAD requests are made, so any attempt is safe to repeat.
+//
+// A broken link's LinkResult.Attempts records every attempt made.
 func CheckURLWithRetry(ctx context.Context, client *http.Client, job CrawlJob, cfg Config, policy RetryPolicy) CrawlResult {
 	backoff := policy.BaseDelay
 	var lastResult CrawlResult
 	var attempts int
+	var overBudget bool
+	var history []result.Attempt
+	var waited time.Duration
+	// Every return hands back lastResult, whose Result shares this pointer
+	defer func() {
+		if lastResult.Result != nil {
+			lastResult.Result.Attempts = history
+		}
+	}()
 
 	for attempt := 0; attempt <= policy.MaxRetries; attempt++ {
 		attempts = attempt + 1
 
 		// Wait with backoff before retry (not on first attempt)
 		if attempt > 0 {
-			select {
-			case <-ctx.Done():
+			host := hostFromURL(job.URL)
+			if ok, firstRefusal := cfg.RetryBudget.take(host); !ok {
+				if firstRefusal {
+					cfg.logger().Warn("retry budget exhausted; failing further requests without retrying",
+						"host", host, "budget", cfg.RetryBudget.retriesPerHost())
+				}
+				overBudget = true
+				attempts--
+				break
+			}
+			if !waitRetry(ctx, clockOrSystem(cfg.Clock), backoff) {
+				if ctx.Err() == nil {
+					// The crawl ends before the retry could run
+					attempts--
+					break
+				}
 				// Context cancelled during wait
 				lastResult.Job = job
 				if lastResult.Result == nil {
@@ -55,14 +85,17 @@ func CheckURLWithRetry(ctx context.Context, client *http.Client, job CrawlJob, c
 					}
 				}
 				return lastResult
-			case <-time.After(backoff):
-				// Double backoff for next retry
-				backoff = min(backoff*2, policy.MaxDelay)
 			}
+			// Double backoff for next retry
+			waited = backoff
+			backoff = min(backoff*2, policy.MaxDelay)
 		}
 
 		// Attempt the request
-		lastResult = CheckURL(ctx, client, job, cfg)
+		started := cfg.now()
+		lastResult = CheckURL(withAttempt(ctx, attempts), client, job, cfg)
+		lastResult.Attempts = attempts
+		history = append(history, attemptRecord(started, waited, lastResult))
 
 		// Success: no error and status < 400
 		if lastResult.Result == nil && lastResult.Err == nil {
@@ -70,44 +103,67 @@ func CheckURLWithRetry(ctx context.Context, client *http.Client, job CrawlJob, c
 		}
 
 		// Check if we should retry
-		if !shouldRetry(lastResult) {
+		if !shouldRetry(lastResult, cfg) {
 			return lastResult
 		}
+		if attempt < policy.MaxRetries {
+			jobLogger(cfg.logger(), job).Debug("retrying", "attempt", attempts, "backoff", backoff,
+				"status", lastResult.StatusCode, "error", lastResult.Err)
+		}
 	}
 
 	// All retries exhausted - append retry info to error message
 	if lastResult.Result != nil && lastResult.Result.Error != "" {
-		lastResult.Result.Error = fmt.Sprintf("%s (after %d attempts)", lastResult.Result.Error, attempts)
+		if overBudget {
+			lastResult.Result.Error = fmt.Sprintf("%s (after %d attempts; host retry budget exhausted)", lastResult.Result.Error, attempts)
+		} else {
+			lastResult.Result.Error = fmt.Sprintf("%s (after %d attempts)", lastResult.Result.Error, attempts)
+		}
 	}
 
 	return lastResult
 }
 
-// shouldRetry determines if a failed request should be retried.
-// Returns true for:
-// - Network errors (timeout, connection refused, DNS failure)
-// - HTTP 429 (rate limited)
-// - HTTP 5xx (server errors)
-// Returns false for:
-// - HTTP 4xx except 429 (client errors)
-func shouldRetry(res CrawlResult) bool {
-	// Check for network errors (status code 0)
-	if res.Result != nil && res.Result.StatusCode == 0 {
-		return isRetryableNetworkError(res.Result.Error)
+// attemptRecord describes one attempt, started after waiting backoff.
+func attemptRecord(started time.Time, backoff time.Duration, res CrawlResult) result.Attempt {
+	attempt := result.Attempt{At: started, StatusCode: res.StatusCode, Backoff: backoff}
+	if res.Result != nil {
+		attempt.Error = res.Result.Error
 	}
+	return attempt
+}
 
-	// Check status codes
+// RetryClassifier decides whether a failed CrawlResult should be retried.
+// Implementations receive the wrapped error in CrawlResult.Err, so they can
+// use errors.Is/errors.As against net, url, and context error values.
+type RetryClassifier func(res CrawlResult) bool
+
+// DefaultRetryClassifier is the RetryClassifier used when Config.RetryClassifier is nil.
+// Returns true for:
+//   - Network errors (timeout, connection refused/reset, DNS failure)
+//   - HTTP 429 (rate limited)
+//   - HTTP 5xx (server errors) except 501 and 505
+//
+// Returns false for:
+//   - HTTP 4xx except 429 (client errors)
+//   - HTTP 501 Not Implemented and 505 HTTP Version Not Supported, which
+//     repeating the same request cannot change
+//   - Redirect loops, TLS failures, and other non-transient errors
+func DefaultRetryClassifier(res CrawlResult) bool {
 	status := 0
 	if res.Result != nil {
 		status = res.Result.StatusCode
 	}
 
 	// 429 Too Many Requests - retry
-	if status == 429 {
+	if status == http.StatusTooManyRequests {
 		return true
 	}
 
-	// 5xx server errors - retry
+	// 5xx server errors - retry, unless the server does not support the request
+	if status == http.StatusNotImplemented || status == http.StatusHTTPVersionNotSupported {
+		return false
+	}
 	if status >= 500 {
 		return true
 	}
@@ -117,84 +173,58 @@ func shouldRetry(res CrawlResult) bool {
 		return false
 	}
 
-	// Check CrawlResult.Err for network-level errors
-	if res.Err != nil {
-		return isRetryableError(res.Err)
-	}
-
-	return false
+	// Network-level errors are carried as wrapped values in CrawlResult.Err
+	return isRetryableError(res.Err)
 }
 
-// isRetryableNetworkError determines if an error string indicates a retryable condition.
-func isRetryableNetworkError(errMsg string) bool {
-	// These error patterns indicate transient issues
-	retryablePatterns := []string{
-		"timeout",
-		"deadline exceeded",
-		"connection refused",
-		"connection reset",
-		"no such host",
-		"DNS",
-		"temporary failure",
-	}
-
-	for _, pattern := range retryablePatterns {
-		if containsIgnoreCase(errMsg, pattern) {
-			return true
-		}
+// shouldRetry determines if a failed request should be retried, using the
+// configured RetryClassifier or DefaultRetryClassifier when none is set.
+func shouldRetry(res CrawlResult, cfg Config) bool {
+	if cfg.RetryClassifier != nil {
+		return cfg.RetryClassifier(res)
 	}
-	return false
+	return DefaultRetryClassifier(res)
 }
 
-// isRetryableError checks if an error type is retryable.
+// isRetryableError checks if an error value represents a transient network failure.
 func isRetryableError(err error) bool {
 	if err == nil {
 		return false
 	}
 
-	// Context deadline exceeded
+	// Caller cancelled the crawl - never retry
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	// Per-request deadline exceeded
 	if errors.Is(err, context.DeadlineExceeded) {
 		return true
 	}
 
-	// Network operation errors (covers timeout, connection refused)
-	var opErr *net.OpError
-	if errors.As(err, &opErr) {
+	// Connection closed by the server mid-response
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
 		return true
 	}
 
+	// Certificate and handshake failures won't fix themselves on retry
+	if result.IsTLSError(err) {
+		return false
+	}
+
 	// DNS errors
 	var dnsErr *net.DNSError
-	return errors.As(err, &dnsErr)
-}
-
-// containsIgnoreCase checks if s contains substr (case-insensitive).
-func containsIgnoreCase(s, substr string) bool {
-	return len(s) >= len(substr) && (s == substr ||
-		(len(s) > len(substr) && containsAt(s, substr, 0)) ||
-		containsIgnoreCase(s[1:], substr))
-}
+	if errors.As(err, &dnsErr) {
+		return true
+	}
 
-func containsAt(s, substr string, start int) bool {
-	for i := start; i <= len(s)-len(substr); i++ {
-		match := true
-		for j := 0; j < len(substr); j++ {
-			sc := s[i+j]
-			subc := substr[j]
-			if sc >= 'A' && sc <= 'Z' {
-				sc += 32
-			}
-			if subc >= 'A' && subc <= 'Z' {
-				subc += 32
-			}
-			if sc != subc {
-				match = false
-				break
-			}
-		}
-		if match {
-			return true
-		}
+	// Network operation errors (covers dial failures, connection refused/reset)
+	var opErr *net.OpError
+	if errors.As(err, &opErr) {
+		return true
 	}
-	return false
+
+	// Timeouts reported via the net.Error interface (e.g. http.Client.Timeout)
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
 }
@@ -2,9 +2,14 @@ package crawler
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"sync/atomic"
+	"syscall"
 	"testing"
 	"time"
 
@@ -159,6 +164,47 @@ func TestCheckURLWithRetry_ExhaustsRetries(t *testing.T) {
 	}
 }
 
+func TestCheckURLWithRetry_RecordsAttempts(t *testing.T) {
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	clock := newFakeClock()
+	cfg := Config{
+		RequestTimeout: 5 * time.Second,
+		RetryPolicy:    RetryPolicy{MaxRetries: 2, BaseDelay: time.Second, MaxDelay: time.Minute},
+		Clock:          clock,
+	}
+	job := CrawlJob{URL: server.URL, IsExternal: true}
+
+	res := CheckURLWithRetry(context.Background(), &http.Client{}, job, cfg, cfg.RetryPolicy)
+
+	if res.Result == nil {
+		t.Fatal("expected broken link result")
+	}
+	got := res.Result.Attempts
+	if len(got) != 3 {
+		t.Fatalf("Attempts = %+v, want 3", got)
+	}
+	for i, want := range []struct {
+		status  int
+		backoff time.Duration
+	}{{503, 0}, {500, time.Second}, {500, 2 * time.Second}} {
+		if got[i].StatusCode != want.status || got[i].Backoff != want.backoff {
+			t.Errorf("attempt %d = %+v, want status %d after %s", i+1, got[i], want.status, want.backoff)
+		}
+	}
+	if start := newFakeClock().Now(); !got[0].At.Equal(start) || !got[2].At.Equal(start.Add(3*time.Second)) {
+		t.Errorf("attempt times %v ... %v, want the clock's time at each request", got[0].At, got[2].At)
+	}
+}
+
 func TestCheckURLWithRetry_ContextCancellation(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		time.Sleep(100 * time.Millisecond) // Slow response
@@ -186,6 +232,9 @@ func TestCheckURLWithRetry_ContextCancellation(t *testing.T) {
 }
 
 func TestShouldRetry_NetworkErrors(t *testing.T) {
+	dnsErr := &net.DNSError{Err: "no such host", Name: "example.invalid"}
+	refusedErr := &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}
+
 	tests := []struct {
 		name        string
 		result      CrawlResult
@@ -194,32 +243,44 @@ func TestShouldRetry_NetworkErrors(t *testing.T) {
 		{
 			name: "timeout error",
 			result: CrawlResult{
-				Result: &result.LinkResult{
-					StatusCode: 0,
-					Error:      "context deadline exceeded",
-				},
+				Result: &result.LinkResult{StatusCode: 0},
+				Err:    fmt.Errorf("fetch: %w", &url.Error{Op: "Get", URL: "http://x", Err: context.DeadlineExceeded}),
 			},
 			shouldRetry: true,
 		},
 		{
 			name: "connection refused",
 			result: CrawlResult{
-				Result: &result.LinkResult{
-					StatusCode: 0,
-					Error:      "connection refused",
-				},
+				Result: &result.LinkResult{StatusCode: 0},
+				Err:    fmt.Errorf("fetch: %w", &url.Error{Op: "Get", URL: "http://x", Err: refusedErr}),
 			},
 			shouldRetry: true,
 		},
 		{
 			name: "DNS failure",
+			result: CrawlResult{
+				Result: &result.LinkResult{StatusCode: 0},
+				Err:    fmt.Errorf("fetch: %w", &url.Error{Op: "Get", URL: "http://x", Err: dnsErr}),
+			},
+			shouldRetry: true,
+		},
+		{
+			name: "context cancelled",
+			result: CrawlResult{
+				Result: &result.LinkResult{StatusCode: 0},
+				Err:    fmt.Errorf("fetch: %w", context.Canceled),
+			},
+			shouldRetry: false,
+		},
+		{
+			name: "error text alone is not retryable",
 			result: CrawlResult{
 				Result: &result.LinkResult{
 					StatusCode: 0,
-					Error:      "no such host",
+					Error:      "connection refused",
 				},
 			},
-			shouldRetry: true,
+			shouldRetry: false,
 		},
 		{
 			name: "500 server error",
@@ -230,6 +291,15 @@ func TestShouldRetry_NetworkErrors(t *testing.T) {
 			},
 			shouldRetry: true,
 		},
+		{
+			name: "501 not implemented",
+			result: CrawlResult{
+				Result: &result.LinkResult{
+					StatusCode: 501,
+				},
+			},
+			shouldRetry: false,
+		},
 		{
 			name: "429 rate limited",
 			result: CrawlResult{
@@ -268,10 +338,71 @@ func TestShouldRetry_NetworkErrors(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := shouldRetry(tt.result)
+			got := shouldRetry(tt.result, Config{})
 			if got != tt.shouldRetry {
 				t.Errorf("shouldRetry() = %v, want %v", got, tt.shouldRetry)
 			}
 		})
 	}
 }
+
+func TestCheckURLWithRetry_WrapsNetworkError(t *testing.T) {
+	// Grab a free port and close the listener so dials are refused
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	addr := listener.Addr().String()
+	if err := listener.Close(); err != nil {
+		t.Fatalf("close listener: %v", err)
+	}
+
+	cfg := Config{
+		RequestTimeout: 2 * time.Second,
+		RetryPolicy:    RetryPolicy{MaxRetries: 0},
+	}
+	job := CrawlJob{URL: "http://" + addr + "/", IsExternal: true}
+
+	res := CheckURLWithRetry(context.Background(), &http.Client{}, job, cfg, cfg.RetryPolicy)
+
+	if res.Result == nil {
+		t.Fatal("expected broken link result for refused connection")
+	}
+	if !errors.Is(res.Err, syscall.ECONNREFUSED) {
+		t.Errorf("expected Err to wrap ECONNREFUSED, got %v", res.Err)
+	}
+	if res.Result.ErrorCategory != result.CategoryConnectionRefused {
+		t.Errorf("expected category %v, got %v", result.CategoryConnectionRefused, res.Result.ErrorCategory)
+	}
+}
+
+func TestCheckURLWithRetry_CustomClassifier(t *testing.T) {
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempt := atomic.AddInt32(&attempts, 1)
+		if attempt < 2 {
+			w.WriteHeader(http.StatusNotFound) // 404 - not retried by default
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	cfg := Config{
+		RequestTimeout: 5 * time.Second,
+		RetryPolicy:    RetryPolicy{MaxRetries: 2, BaseDelay: 10 * time.Millisecond, MaxDelay: 100 * time.Millisecond},
+		RetryClassifier: func(res CrawlResult) bool {
+			return res.Result != nil && res.Result.StatusCode == http.StatusNotFound
+		},
+	}
+	job := CrawlJob{URL: server.URL, IsExternal: true}
+
+	res := CheckURLWithRetry(context.Background(), &http.Client{}, job, cfg, cfg.RetryPolicy)
+
+	if res.Result != nil {
+		t.Errorf("expected success after classifier-driven retry, got result: %+v", res.Result)
+	}
+	if attempts != 2 {
+		t.Errorf("expected 2 attempts, got %d", attempts)
+	}
+}
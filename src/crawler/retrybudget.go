@@ -0,0 +1,40 @@
+package crawler
+
+import "sync"
+
+// RetryBudget caps the retries spent on each host over a crawl, so one
+// flapping host cannot multiply the crawl's duration. Once a host has used
+// its budget, further failures on it are reported without retrying. Share
+// one between crawlers to cap their combined retries. A nil *RetryBudget
+// places no limit. It is safe for concurrent use.
+type RetryBudget struct {
+	perHost int
+	mu      sync.Mutex
+	spent   map[string]int // Retries taken per host, counting refusals past the budget
+}
+
+// NewRetryBudget returns a budget of perHost retries per host. Budgets below
+// 1 are treated as 1; use RetryPolicy.MaxRetries to disable retries.
+func NewRetryBudget(perHost int) *RetryBudget {
+	return &RetryBudget{perHost: max(perHost, 1), spent: make(map[string]int)}
+}
+
+// retriesPerHost returns the budget, or 0 for no limit.
+func (b *RetryBudget) retriesPerHost() int {
+	if b == nil {
+		return 0
+	}
+	return b.perHost
+}
+
+// take spends one retry on host. It reports whether the budget allowed it,
+// and whether this is the first retry refused on host.
+func (b *RetryBudget) take(host string) (ok, firstRefusal bool) {
+	if b == nil {
+		return true, false
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.spent[host]++
+	return b.spent[host] <= b.perHost, b.spent[host] == b.perHost+1
+}
@@ -0,0 +1,59 @@
+package crawler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRetryBudget_Take(t *testing.T) {
+	budget := NewRetryBudget(2)
+	for i, want := range []struct{ ok, firstRefusal bool }{{true, false}, {true, false}, {false, true}, {false, false}} {
+		if ok, firstRefusal := budget.take("flaky.example"); ok != want.ok || firstRefusal != want.firstRefusal {
+			t.Errorf("take #%d = %v, %v, want %v, %v", i+1, ok, firstRefusal, want.ok, want.firstRefusal)
+		}
+	}
+	if ok, _ := budget.take("other.example"); !ok {
+		t.Error("take on another host refused, want budgets kept per host")
+	}
+
+	var unlimited *RetryBudget
+	if ok, _ := unlimited.take("flaky.example"); !ok || unlimited.retriesPerHost() != 0 {
+		t.Error("nil budget should allow every retry")
+	}
+	if got := NewRetryBudget(0).retriesPerHost(); got != 1 {
+		t.Errorf("NewRetryBudget(0) allows %d retries, want 1", got)
+	}
+}
+
+func TestCheckURLWithRetry_HostRetryBudget(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	cfg := Config{
+		RequestTimeout: 5 * time.Second,
+		RetryPolicy:    RetryPolicy{MaxRetries: 2, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond},
+		RetryBudget:    NewRetryBudget(3),
+	}
+	client := &http.Client{}
+
+	// The first link spends 2 retries, the second the last one, and the
+	// third fails without retrying
+	for i, wantAttempts := range []int{3, 2, 1} {
+		job := CrawlJob{URL: server.URL + "/" + string(rune('a'+i)), IsExternal: true}
+		res := CheckURLWithRetry(context.Background(), client, job, cfg, cfg.RetryPolicy)
+		if res.Result == nil || res.Attempts != wantAttempts {
+			t.Errorf("link %d: attempts = %d, result = %+v, want a failure after %d attempts", i+1, res.Attempts, res.Result, wantAttempts)
+		}
+	}
+	if requests != 6 {
+		t.Errorf("server got %d requests, want 6", requests)
+	}
+}
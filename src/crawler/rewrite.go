@@ -0,0 +1,105 @@
+package crawler
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+
+	"github.com/lukemcguire/zombiecrawl/urlutil"
+)
+
+// RewriteRule rewrites links found during the crawl before they are queued,
+// for example to crawl a staging host whose pages link to production by
+// absolute URL:
+//
+//	RewriteRule{Find: regexp.MustCompile(`^https://www\.example\.com/`), Replace: "https://staging.example.com/"}
+//
+// Links are absolute and normalized when rules are applied. Replace may
+// refer to groups of Find as in regexp.Regexp.ReplaceAllString.
+type RewriteRule struct {
+	Find    *regexp.Regexp
+	Replace string
+}
+
+// rewrite applies cfg.Rewrites to link in order, each to the result of the
+// one before.
+func (cfg Config) rewrite(link string) string {
+	for _, rule := range cfg.Rewrites {
+		link = rule.Find.ReplaceAllString(link, rule.Replace)
+	}
+	return link
+}
+
+// stripSessionIDs returns the normalized URL without session IDs if
+// cfg.StripSessionIDs is set.
+func (cfg Config) stripSessionIDs(normalized string) string {
+	if !cfg.StripSessionIDs {
+		return normalized
+	}
+	return urlutil.StripSessionIDs(normalized)
+}
+
+// rewriteLinks returns links with cfg.Rewrites applied, normalized, with
+// session IDs stripped if cfg.StripSessionIDs is set, and without
+// duplicates. Links a rule turns into something other than an HTTP(S) URL
+// are dropped. links is returned as is if there is nothing to apply.
+func (cfg Config) rewriteLinks(links []string) []string {
+	if len(cfg.Rewrites) == 0 && !cfg.StripSessionIDs {
+		return links
+	}
+	rewritten := make([]string, 0, len(links))
+	for _, link := range links {
+		link = cfg.rewrite(link)
+		if !urlutil.IsHTTPScheme(link) {
+			cfg.logger().Warn("skipping link rewritten to a non-HTTP URL", "url", link)
+			continue
+		}
+		normalized, err := urlutil.Normalize(link)
+		if err != nil {
+			cfg.logger().Warn("skipping rewritten link that cannot be normalized", "url", link, "error", err)
+			continue
+		}
+		normalized = cfg.stripSessionIDs(normalized)
+		if !slices.Contains(rewritten, normalized) {
+			rewritten = append(rewritten, normalized)
+		}
+	}
+	return rewritten
+}
+
+// rewriteFile is the JSON form of rewrite rules read by LoadRewriteRules.
+type rewriteFile struct {
+	Rewrites []struct {
+		Find    string `json:"find"`
+		Replace string `json:"replace"`
+	} `json:"rewrites"`
+}
+
+// LoadRewriteRules reads rewrite rules from a JSON file such as
+//
+//	{"rewrites": [{"find": "^https://(www\\.)?example\\.com/", "replace": "https://staging.example.com/"}]}
+//
+// Each find is a Go regular expression.
+func LoadRewriteRules(r io.Reader) ([]RewriteRule, error) {
+	var file rewriteFile
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&file); err != nil {
+		return nil, fmt.Errorf("read rewrite rules: %w", err)
+	}
+
+	rules := make([]RewriteRule, 0, len(file.Rewrites))
+	for i, entry := range file.Rewrites {
+		if entry.Find == "" {
+			return nil, fmt.Errorf("rewrite rule %d: find is required", i+1)
+		}
+		find, err := regexp.Compile(entry.Find)
+		if err != nil {
+			return nil, fmt.Errorf("rewrite rule %d: %w", i+1, err)
+		}
+		rules = append(rules, RewriteRule{Find: find, Replace: entry.Replace})
+	}
+	return rules, nil
+}
@@ -0,0 +1,129 @@
+package crawler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestRun_RewritesLinks(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/":
+			_, _ = fmt.Fprint(w, `<a href="http://www.prod.invalid/about">about</a>
+				<a href="https://prod.invalid/gone">gone</a>`)
+		case "/about":
+			_, _ = fmt.Fprint(w, `<p>about</p>`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	cfg := DefaultConfig(ts.URL)
+	cfg.Delay = 1
+	cfg.Rewrites = []RewriteRule{{Find: regexp.MustCompile(`^https?://(www\.)?prod\.invalid/`), Replace: ts.URL + "/"}}
+	c, err := New(cfg, nil)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	res, err := c.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run() error: %v", err)
+	}
+
+	// Both links are checked on the test server as internal pages
+	if res.Stats.TotalChecked != 3 {
+		t.Errorf("TotalChecked = %d, want 3", res.Stats.TotalChecked)
+	}
+	if len(res.BrokenLinks) != 1 || res.BrokenLinks[0].URL != ts.URL+"/gone" || res.BrokenLinks[0].IsExternal {
+		t.Errorf("BrokenLinks = %+v, want internal %s/gone", res.BrokenLinks, ts.URL)
+	}
+}
+
+func TestConfig_RewriteLinks(t *testing.T) {
+	cfg := Config{Rewrites: []RewriteRule{
+		{Find: regexp.MustCompile(`^https://example\.com/(\w+)/`), Replace: "https://staging.example.com/$1/"},
+		{Find: regexp.MustCompile(`^https://staging\.example\.com/old/`), Replace: "https://staging.example.com/new/"},
+		{Find: regexp.MustCompile(`^https://drop\.example\.com/`), Replace: "mailto:"},
+	}}
+	links := []string{
+		"https://example.com/docs/a",
+		"https://staging.example.com/docs/a",
+		"https://example.com/old/b",
+		"https://drop.example.com/c",
+		"https://other.example.com/",
+	}
+	want := []string{
+		"https://staging.example.com/docs/a",
+		"https://staging.example.com/new/b",
+		"https://other.example.com/",
+	}
+	if got := cfg.rewriteLinks(links); !slices.Equal(got, want) {
+		t.Errorf("rewriteLinks() = %v, want %v", got, want)
+	}
+	if got := (Config{}).rewriteLinks(links); !slices.Equal(got, links) {
+		t.Errorf("rewriteLinks() without rules = %v, want links unchanged", got)
+	}
+}
+
+func TestRun_StripSessionIDs(t *testing.T) {
+	var mu sync.Mutex
+	var requested []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			http.NotFound(w, r)
+			return
+		}
+		mu.Lock()
+		requested = append(requested, r.URL.RequestURI())
+		session := len(requested)
+		mu.Unlock()
+		// Every page links to the shop under a fresh session, as legacy
+		// sites without cookies do
+		_, _ = fmt.Fprintf(w, `<a href="/shop;jsessionid=%[1]d">shop</a><a href="/shop?PHPSESSID=%[1]d&amp;page=2">next</a>`, session)
+	}))
+	defer ts.Close()
+
+	cfg := DefaultConfig(ts.URL + "/?gclid=abc")
+	cfg.Delay = 1
+	cfg.StripSessionIDs = true
+	c, err := New(cfg, nil)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	if _, err := c.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error: %v", err)
+	}
+
+	slices.Sort(requested)
+	if want := []string{"/", "/shop", "/shop?page=2"}; !slices.Equal(requested, want) {
+		t.Errorf("requested %v, want %v", requested, want)
+	}
+}
+
+func TestLoadRewriteRules(t *testing.T) {
+	rules, err := LoadRewriteRules(strings.NewReader(`{"rewrites": [{"find": "^https://example\\.com/", "replace": "https://staging.example.com/"}]}`))
+	if err != nil {
+		t.Fatalf("LoadRewriteRules() error: %v", err)
+	}
+	if len(rules) != 1 || rules[0].Find.String() != `^https://example\.com/` || rules[0].Replace != "https://staging.example.com/" {
+		t.Errorf("LoadRewriteRules() = %+v", rules)
+	}
+
+	for name, input := range map[string]string{
+		"bad regexp":    `{"rewrites": [{"find": "(", "replace": "x"}]}`,
+		"missing find":  `{"rewrites": [{"replace": "x"}]}`,
+		"unknown field": `{"rewrites": [{"find": "a", "with": "x"}]}`,
+	} {
+		if _, err := LoadRewriteRules(strings.NewReader(input)); err == nil {
+			t.Errorf("%s: LoadRewriteRules() error = nil, want an error", name)
+		}
+	}
+}
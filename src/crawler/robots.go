@@ -6,7 +6,6 @@ import (
 	"io"
 	"net/http"
 	"net/url"
-	"sync"
 	"time"
 
 	"github.com/temoto/robotstxt"
@@ -15,24 +14,100 @@ import (
 // cachedRobots stores parsed robots.txt data with fetch timestamp.
 type cachedRobots struct {
 	data      *robotstxt.RobotsData
+	strict    *googleRobots // Google-semantics rules, set in strict mode only
 	fetchedAt time.Time
 }
 
+// robotsOverride is a local robots.txt used for a host instead of the one
+// it serves.
+type robotsOverride struct {
+	body  []byte
+	entry *cachedRobots
+}
+
 // RobotsChecker fetches and caches robots.txt rules per host.
 type RobotsChecker struct {
-	client   *http.Client
-	cache    sync.Map // host string -> *cachedRobots
-	cacheTTL time.Duration
+	client    *http.Client
+	cache     *robotsCache
+	cacheTTL  time.Duration
+	strict    bool
+	clock     Clock                      // Times cache expiry; nil is the system clock
+	overrides map[string]*robotsOverride // Local robots.txt by host, never fetched or expired
 }
 
-// NewRobotsChecker creates a RobotsChecker with the given HTTP client.
+// NewRobotsChecker creates a RobotsChecker with the given HTTP client,
+// caching robots.txt for up to DefaultRobotsCacheSize hosts.
 func NewRobotsChecker(client *http.Client) *RobotsChecker {
+	return NewRobotsCheckerWithCacheSize(client, DefaultRobotsCacheSize)
+}
+
+// NewRobotsCheckerWithCacheSize creates a RobotsChecker that caches robots.txt
+// for at most maxEntries hosts, evicting the least recently used hosts beyond
+// that. Non-positive sizes use DefaultRobotsCacheSize.
+func NewRobotsCheckerWithCacheSize(client *http.Client, maxEntries int) *RobotsChecker {
 	return &RobotsChecker{
 		client:   client,
+		cache:    newRobotsCache(maxEntries),
 		cacheTTL: time.Hour, // 1-hour cache TTL
 	}
 }
 
+// SetClock makes cache expiry follow c instead of the system clock.
+func (r *RobotsChecker) SetClock(c Clock) {
+	r.clock = c
+}
+
+// now returns the current time on the checker's clock.
+func (r *RobotsChecker) now() time.Time {
+	return clockOrSystem(r.clock).Now()
+}
+
+// SetStrict switches rule evaluation to Google's robots.txt semantics
+// (longest-match precedence with Allow winning ties, "*" and "$" wildcards,
+// query strings included in matching); see googleRobots. When off, the
+// robotstxt library's defaults apply. Cached entries are dropped so the new
+// mode takes effect immediately.
+func (r *RobotsChecker) SetStrict(strict bool) {
+	r.strict = strict
+	r.cache.reset()
+	for _, override := range r.overrides {
+		override.entry = r.parseOverride(override.body)
+	}
+}
+
+// SetOverride makes the checker use body as the robots.txt of host instead
+// of fetching it, for testing rules before deploying them or checking them
+// without network access. host is matched against the Host of URLs,
+// including any port. Overrides must be set before the checker is used.
+func (r *RobotsChecker) SetOverride(host string, body []byte) error {
+	if _, err := robotstxt.FromBytes(body); err != nil {
+		return fmt.Errorf("parse robots.txt for host %s: %w", host, err)
+	}
+	if r.overrides == nil {
+		r.overrides = make(map[string]*robotsOverride)
+	}
+	r.overrides[host] = &robotsOverride{body: body, entry: r.parseOverride(body)}
+	return nil
+}
+
+// parseOverride parses a robots.txt that SetOverride has already checked
+// parses.
+func (r *RobotsChecker) parseOverride(body []byte) *cachedRobots {
+	data, _ := robotstxt.FromBytes(body)
+	entry := &cachedRobots{data: data}
+	if r.strict {
+		entry.strict = parseGoogleRobots(body)
+	}
+	return entry
+}
+
+// CacheStats returns how many robots.txt lookups were answered from the
+// cache (hits) and how many required a fetch (misses). Expired entries count
+// as hits on lookup and are then refetched.
+func (r *RobotsChecker) CacheStats() (hits, misses int64) {
+	return r.cache.hits.Load(), r.cache.misses.Load()
+}
+
 // Allowed checks if the given URL is allowed to be crawled by the user agent.
 // Returns true if allowed, false if disallowed by robots.txt.
 // Errors (network, parsing) result in allow-all behavior.
@@ -47,21 +122,23 @@ func (r *RobotsChecker) Allowed(ctx context.Context, rawURL, userAgent string) (
 	if host == "" {
 		return true, nil
 	}
+	if override, ok := r.overrides[host]; ok {
+		return override.entry.test(parsedURL, userAgent), nil
+	}
 
 	// Check cache for valid entry
-	if cached, ok := r.cache.Load(host); ok {
-		cachedEntry, ok := cached.(*cachedRobots)
-		if !ok || cachedEntry == nil {
+	if cachedEntry, ok := r.cache.get(host); ok {
+		if cachedEntry == nil {
 			// Invalid cache entry - treat as miss and refetch
-			r.cache.Delete(host)
-		} else if time.Since(cachedEntry.fetchedAt) < r.cacheTTL {
+			r.cache.remove(host)
+		} else if r.now().Sub(cachedEntry.fetchedAt) < r.cacheTTL {
 			// Cache hit and valid TTL
 			if cachedEntry.data == nil {
 				// Nil data means allow-all (404, 5xx, or fetch error)
 				return true, nil
 			}
 			// Check against robots.txt rules
-			return cachedEntry.data.TestAgent(parsedURL.Path, userAgent), nil
+			return cachedEntry.test(parsedURL, userAgent), nil
 		}
 	}
 
@@ -73,6 +150,9 @@ func (r *RobotsChecker) Allowed(ctx context.Context, rawURL, userAgent string) (
 		r.cacheNilEntry(host)
 		return true, fmt.Errorf("create robots.txt request for host %s: %w", host, err)
 	}
+	if userAgent != "" {
+		req.Header.Set("User-Agent", userAgent)
+	}
 
 	resp, err := r.client.Do(req)
 	if err != nil {
@@ -120,24 +200,66 @@ func (r *RobotsChecker) Allowed(ctx context.Context, rawURL, userAgent string) (
 	}
 
 	// Cache the parsed robots.txt
-	r.cache.Store(host, &cachedRobots{
+	entry := &cachedRobots{
 		data:      robots,
-		fetchedAt: time.Now(),
-	})
+		fetchedAt: r.now(),
+	}
+	if r.strict {
+		entry.strict = parseGoogleRobots(body)
+	}
+	r.cache.put(host, entry)
+
+	return entry.test(parsedURL, userAgent), nil
+}
 
-	return robots.TestAgent(parsedURL.Path, userAgent), nil
+// Sitemaps returns the sitemap URLs listed in the robots.txt for rawURL's
+// host, fetching robots.txt if it is not cached. It returns nil if the host
+// has no robots.txt or it lists no Sitemap: directives. Fetch errors are
+// returned alongside a nil result.
+func (r *RobotsChecker) Sitemaps(ctx context.Context, rawURL, userAgent string) ([]string, error) {
+	if _, err := r.Allowed(ctx, rawURL, userAgent); err != nil {
+		return nil, err
+	}
+	parsedURL, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("parse URL: %w", err)
+	}
+	if override, ok := r.overrides[parsedURL.Host]; ok {
+		return override.entry.data.Sitemaps, nil
+	}
+	cachedEntry, ok := r.cache.get(parsedURL.Host)
+	if !ok || cachedEntry == nil || cachedEntry.data == nil {
+		return nil, nil
+	}
+	return cachedEntry.data.Sitemaps, nil
+}
+
+// test applies the cached rules to u, using Google semantics when the entry
+// was parsed in strict mode. Entries must have non-nil data.
+func (c *cachedRobots) test(u *url.URL, userAgent string) bool {
+	if c.strict != nil {
+		target := u.EscapedPath()
+		if target == "" {
+			target = "/"
+		}
+		if u.RawQuery != "" {
+			target += "?" + u.RawQuery
+		}
+		return c.strict.allowed(target, userAgent)
+	}
+	return c.data.TestAgent(u.Path, userAgent)
 }
 
 // cacheNilEntry stores a nil entry to indicate allow-all for this host.
 func (r *RobotsChecker) cacheNilEntry(host string) {
-	r.cache.Store(host, &cachedRobots{
+	r.cache.put(host, &cachedRobots{
 		data:      nil,
-		fetchedAt: time.Now(),
+		fetchedAt: r.now(),
 	})
 }
 
 // ClearCache removes all cached robots.txt entries.
 // Useful for testing.
 func (r *RobotsChecker) ClearCache() {
-	r.cache = sync.Map{}
+	r.cache.reset()
 }
@@ -4,6 +4,8 @@ import (
 	"context"
 	"net/http"
 	"net/http/httptest"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -261,3 +263,112 @@ Disallow: /blocked/`)); err != nil {
 		t.Errorf("Expected 2 requests after ClearCache, got %d", requestCount)
 	}
 }
+
+func TestRobotsChecker_SendsUserAgent(t *testing.T) {
+	var gotUA string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotUA = r.Header.Get("User-Agent")
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	checker := NewRobotsChecker(&http.Client{Timeout: 5 * time.Second})
+	if _, err := checker.Allowed(context.Background(), server.URL+"/page", "robots-bot/1.0"); err != nil {
+		t.Fatalf("Allowed() error = %v", err)
+	}
+	if gotUA != "robots-bot/1.0" {
+		t.Errorf("robots.txt fetch User-Agent = %q, want %q", gotUA, "robots-bot/1.0")
+	}
+}
+
+func TestRobotsChecker_Sitemaps(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("User-agent: *\nDisallow: /private\nSitemap: https://example.com/a.xml\nSitemap: https://example.com/b.xml\n"))
+	}))
+	defer server.Close()
+	missing := httptest.NewServer(http.NotFoundHandler())
+	defer missing.Close()
+
+	checker := NewRobotsChecker(&http.Client{Timeout: 5 * time.Second})
+
+	sitemaps, err := checker.Sitemaps(context.Background(), server.URL+"/", "bot")
+	if err != nil {
+		t.Fatalf("Sitemaps() error = %v", err)
+	}
+	if len(sitemaps) != 2 || sitemaps[0] != "https://example.com/a.xml" || sitemaps[1] != "https://example.com/b.xml" {
+		t.Errorf("Sitemaps() = %v, want both directives in order", sitemaps)
+	}
+
+	sitemaps, err = checker.Sitemaps(context.Background(), missing.URL+"/", "bot")
+	if err != nil || sitemaps != nil {
+		t.Errorf("Sitemaps() without robots.txt = %v, %v; want nil, nil", sitemaps, err)
+	}
+}
+
+func TestRobotsChecker_StrictMode(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("User-agent: *\nDisallow: /page\nAllow: /page\nDisallow: /*?sessionid\n"))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		path   string
+		strict bool
+		want   bool
+	}{
+		// The library takes the first of two equally long rules
+		{"/page", false, false},
+		{"/page", true, true},
+		// The library matches the path only, ignoring the query string
+		{"/cart?sessionid=1", false, true},
+		{"/cart?sessionid=1", true, false},
+	}
+	for _, tt := range tests {
+		checker := NewRobotsChecker(&http.Client{Timeout: 5 * time.Second})
+		checker.SetStrict(tt.strict)
+		got, err := checker.Allowed(context.Background(), server.URL+tt.path, "bot")
+		if err != nil {
+			t.Fatalf("Allowed() error = %v", err)
+		}
+		if got != tt.want {
+			t.Errorf("strict=%v Allowed(%q) = %v, want %v", tt.strict, tt.path, got, tt.want)
+		}
+		// Cached entries must give the same answer
+		if again, _ := checker.Allowed(context.Background(), server.URL+tt.path, "bot"); again != got {
+			t.Errorf("strict=%v cached Allowed(%q) = %v, first answer %v", tt.strict, tt.path, again, got)
+		}
+	}
+}
+
+func TestRobotsChecker_SetOverride(t *testing.T) {
+	var fetches atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+		_, _ = w.Write([]byte("User-agent: *\nDisallow: /\n"))
+	}))
+	defer server.Close()
+	host := strings.TrimPrefix(server.URL, "http://")
+
+	checker := NewRobotsChecker(&http.Client{Timeout: 5 * time.Second})
+	if err := checker.SetOverride(host, []byte("User-agent: *\nDisallow: /page\nAllow: /page\nSitemap: https://example.com/local.xml\n")); err != nil {
+		t.Fatalf("SetOverride() error = %v", err)
+	}
+	if allowed, err := checker.Allowed(context.Background(), server.URL+"/page", "bot"); err != nil || allowed {
+		t.Errorf("Allowed(/page) = %v, %v; want disallowed by the local file", allowed, err)
+	}
+	if allowed, _ := checker.Allowed(context.Background(), server.URL+"/other", "bot"); !allowed {
+		t.Error("Allowed(/other) = false, want allowed by the local file")
+	}
+	if sitemaps, err := checker.Sitemaps(context.Background(), server.URL+"/", "bot"); err != nil || len(sitemaps) != 1 || sitemaps[0] != "https://example.com/local.xml" {
+		t.Errorf("Sitemaps() = %v, %v; want the local file's sitemap", sitemaps, err)
+	}
+
+	// Switching to strict mode reparses the local file: Allow wins the tie
+	checker.SetStrict(true)
+	if allowed, _ := checker.Allowed(context.Background(), server.URL+"/page", "bot"); !allowed {
+		t.Error("strict Allowed(/page) = false, want allowed")
+	}
+	if n := fetches.Load(); n != 0 {
+		t.Errorf("robots.txt fetched %d times, want never", n)
+	}
+}
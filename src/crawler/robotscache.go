@@ -0,0 +1,141 @@
+package crawler
+
+import (
+	"container/list"
+	"hash/maphash"
+	"sync"
+	"sync/atomic"
+)
+
+const (
+	// DefaultRobotsCacheSize is the default maximum number of hosts whose
+	// robots.txt is kept in memory.
+	DefaultRobotsCacheSize = 10_000
+
+	// robotsCacheShards splits the cache by host so concurrent workers
+	// checking different hosts rarely contend on the same lock.
+	robotsCacheShards = 16
+)
+
+// robotsCache is a size-bounded, host-sharded LRU of parsed robots.txt
+// entries. Each shard evicts its least recently used host once it holds its
+// share of the maximum. It is safe for concurrent use.
+type robotsCache struct {
+	seed   maphash.Seed
+	shards [robotsCacheShards]robotsShard
+	hits   atomic.Int64
+	misses atomic.Int64
+}
+
+// robotsShard is one independently locked LRU partition of robotsCache.
+type robotsShard struct {
+	mu      sync.Mutex
+	max     int
+	order   *list.List               // front = most recently used; values are *robotsItem
+	entries map[string]*list.Element // host -> element in order
+}
+
+// robotsItem is a cached host entry stored in a shard's LRU list.
+type robotsItem struct {
+	host  string
+	entry *cachedRobots
+}
+
+// newRobotsCache creates a cache holding at most maxEntries hosts in total.
+// Non-positive sizes use DefaultRobotsCacheSize. Because eviction is per
+// shard, each shard holds at most ceil(maxEntries/robotsCacheShards) hosts.
+func newRobotsCache(maxEntries int) *robotsCache {
+	if maxEntries <= 0 {
+		maxEntries = DefaultRobotsCacheSize
+	}
+	perShard := max((maxEntries+robotsCacheShards-1)/robotsCacheShards, 1)
+	c := &robotsCache{seed: maphash.MakeSeed()}
+	for i := range c.shards {
+		c.shards[i] = robotsShard{max: perShard, order: list.New(), entries: make(map[string]*list.Element)}
+	}
+	return c
+}
+
+// shard returns the shard responsible for host.
+func (c *robotsCache) shard(host string) *robotsShard {
+	return &c.shards[maphash.String(c.seed, host)%robotsCacheShards]
+}
+
+// get returns the entry for host and marks it recently used. Lookups are
+// counted as hits or misses; callers decide whether an entry has expired.
+func (c *robotsCache) get(host string) (*cachedRobots, bool) {
+	s := c.shard(host)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	elem, ok := s.entries[host]
+	if !ok {
+		c.misses.Add(1)
+		return nil, false
+	}
+	c.hits.Add(1)
+	s.order.MoveToFront(elem)
+	item, _ := elem.Value.(*robotsItem)
+	return item.entry, true
+}
+
+// put stores entry for host, evicting the shard's least recently used host
+// if it is full.
+func (c *robotsCache) put(host string, entry *cachedRobots) {
+	s := c.shard(host)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if elem, ok := s.entries[host]; ok {
+		if item, ok := elem.Value.(*robotsItem); ok {
+			item.entry = entry
+		}
+		s.order.MoveToFront(elem)
+		return
+	}
+	s.entries[host] = s.order.PushFront(&robotsItem{host: host, entry: entry})
+	for s.order.Len() > s.max {
+		oldest := s.order.Back()
+		s.order.Remove(oldest)
+		if item, ok := oldest.Value.(*robotsItem); ok {
+			delete(s.entries, item.host)
+		}
+	}
+}
+
+// remove drops the entry for host, if any.
+func (c *robotsCache) remove(host string) {
+	s := c.shard(host)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if elem, ok := s.entries[host]; ok {
+		s.order.Remove(elem)
+		delete(s.entries, host)
+	}
+}
+
+// len returns the number of cached hosts.
+func (c *robotsCache) len() int {
+	n := 0
+	for i := range c.shards {
+		s := &c.shards[i]
+		s.mu.Lock()
+		n += s.order.Len()
+		s.mu.Unlock()
+	}
+	return n
+}
+
+// reset removes every entry and zeroes the hit/miss counters.
+func (c *robotsCache) reset() {
+	for i := range c.shards {
+		s := &c.shards[i]
+		s.mu.Lock()
+		s.order.Init()
+		clear(s.entries)
+		s.mu.Unlock()
+	}
+	c.hits.Store(0)
+	c.misses.Store(0)
+}
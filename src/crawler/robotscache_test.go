@@ -0,0 +1,106 @@
+package crawler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRobotsCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	c := newRobotsCache(robotsCacheShards) // one entry per shard
+
+	// Find two hosts that land in the same shard
+	first := "host-0.example"
+	second := ""
+	for i := 1; second == ""; i++ {
+		candidate := fmt.Sprintf("host-%d.example", i)
+		if c.shard(candidate) == c.shard(first) {
+			second = candidate
+		}
+	}
+
+	c.put(first, &cachedRobots{})
+	c.put(second, &cachedRobots{})
+
+	if _, ok := c.get(first); ok {
+		t.Errorf("%s should have been evicted by %s", first, second)
+	}
+	if _, ok := c.get(second); !ok {
+		t.Errorf("%s should still be cached", second)
+	}
+	if got := c.len(); got != 1 {
+		t.Errorf("len() = %d, want 1", got)
+	}
+}
+
+func TestRobotsCache_GetRefreshesRecency(t *testing.T) {
+	c := newRobotsCache(2 * robotsCacheShards) // two entries per shard
+
+	hosts := []string{"a.example"}
+	for i := 0; len(hosts) < 3; i++ {
+		candidate := fmt.Sprintf("h%d.example", i)
+		if c.shard(candidate) == c.shard(hosts[0]) {
+			hosts = append(hosts, candidate)
+		}
+	}
+
+	c.put(hosts[0], &cachedRobots{})
+	c.put(hosts[1], &cachedRobots{})
+	c.get(hosts[0]) // hosts[1] is now least recently used
+	c.put(hosts[2], &cachedRobots{})
+
+	if _, ok := c.get(hosts[0]); !ok {
+		t.Errorf("recently used %s was evicted", hosts[0])
+	}
+	if _, ok := c.get(hosts[1]); ok {
+		t.Errorf("least recently used %s was kept", hosts[1])
+	}
+}
+
+func TestRobotsCache_Bounded(t *testing.T) {
+	c := newRobotsCache(100)
+	var wg sync.WaitGroup
+	for w := range 8 {
+		wg.Go(func() {
+			for i := range 1000 {
+				host := fmt.Sprintf("w%d-%d.example", w, i)
+				c.put(host, &cachedRobots{})
+				c.get(host)
+			}
+		})
+	}
+	wg.Wait()
+
+	// Each shard holds ceil(100/16) = 7 entries at most
+	if got := c.len(); got > 7*robotsCacheShards {
+		t.Errorf("len() = %d, want at most %d", got, 7*robotsCacheShards)
+	}
+}
+
+func TestRobotsChecker_CacheStats(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("User-agent: *\nDisallow:\n"))
+	}))
+	defer server.Close()
+
+	checker := NewRobotsCheckerWithCacheSize(&http.Client{Timeout: 5 * time.Second}, 10)
+	for range 3 {
+		if _, err := checker.Allowed(context.Background(), server.URL+"/page", "bot"); err != nil {
+			t.Fatalf("Allowed() error: %v", err)
+		}
+	}
+
+	hits, misses := checker.CacheStats()
+	if hits != 2 || misses != 1 {
+		t.Errorf("CacheStats() = %d hits, %d misses; want 2 and 1", hits, misses)
+	}
+
+	checker.ClearCache()
+	if hits, misses := checker.CacheStats(); hits != 0 || misses != 0 {
+		t.Errorf("CacheStats() after ClearCache = %d, %d; want 0, 0", hits, misses)
+	}
+}
@@ -0,0 +1,169 @@
+package crawler
+
+import (
+	"bufio"
+	"bytes"
+	"slices"
+	"strings"
+)
+
+// googleRobots holds robots.txt rules parsed with Google's semantics, used
+// by the strict-compat mode of RobotsChecker:
+//
+//   - The group whose user-agent line is the longest match for the crawler's
+//     product token applies; groups naming the same agent are merged, and
+//     "*" groups apply only when no named group matches.
+//   - Among the rules matching a URL, the one with the longest pattern wins;
+//     on a tie Allow wins, as the least restrictive rule.
+//   - Patterns support "*" (any sequence) and a trailing "$" (end of URL),
+//     and are matched against the path plus query string.
+//
+// Lines that are not user-agent, allow, or disallow are ignored. The status
+// handling in RobotsChecker (404 and 5xx allow all) is unchanged.
+type googleRobots struct {
+	groups []googleGroup
+}
+
+// googleGroup is a set of rules shared by one or more user-agent lines.
+type googleGroup struct {
+	agents []string // lowercase user-agent values
+	rules  []googleRule
+}
+
+// googleRule is a single Allow or Disallow line.
+type googleRule struct {
+	allow   bool
+	pattern string
+}
+
+// parseGoogleRobots parses a robots.txt body. It never fails: malformed
+// lines are skipped, as Google's parser does.
+func parseGoogleRobots(body []byte) *googleRobots {
+	robots := &googleRobots{}
+	var current *googleGroup
+	lastWasAgent := false
+
+	scanner := bufio.NewScanner(bytes.NewReader(body))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = line[:i]
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+
+		switch key {
+		case "user-agent":
+			// Consecutive user-agent lines share a group
+			if current == nil || !lastWasAgent {
+				robots.groups = append(robots.groups, googleGroup{})
+				current = &robots.groups[len(robots.groups)-1]
+			}
+			current.agents = append(current.agents, strings.ToLower(value))
+			lastWasAgent = true
+		case "allow", "disallow":
+			lastWasAgent = false
+			// An empty pattern matches nothing; rules before any group are ignored
+			if current == nil || value == "" {
+				continue
+			}
+			current.rules = append(current.rules, googleRule{allow: key == "allow", pattern: value})
+		default:
+			lastWasAgent = false
+		}
+	}
+	return robots
+}
+
+// allowed reports whether target (path plus optional "?query") may be
+// fetched by userAgent.
+func (g *googleRobots) allowed(target, userAgent string) bool {
+	if target == "/robots.txt" {
+		return true
+	}
+
+	best := -1
+	allow := true
+	for _, rule := range g.rulesFor(userAgent) {
+		if !robotsPatternMatch(rule.pattern, target) {
+			continue
+		}
+		length := len(rule.pattern)
+		if length > best || (length == best && rule.allow) {
+			best = length
+			allow = rule.allow
+		}
+	}
+	return allow
+}
+
+// rulesFor returns the merged rules of the groups that apply to userAgent.
+func (g *googleRobots) rulesFor(userAgent string) []googleRule {
+	token := strings.ToLower(userAgent)
+	if i := strings.IndexAny(token, "/ "); i >= 0 {
+		token = token[:i]
+	}
+
+	longest := 0
+	var named, wildcard []googleRule
+	for _, group := range g.groups {
+		match, isWildcard := 0, false
+		for _, agent := range group.agents {
+			if agent == "*" {
+				isWildcard = true
+			} else if token != "" && strings.HasPrefix(token, agent) {
+				match = max(match, len(agent))
+			}
+		}
+		switch {
+		case match > longest:
+			longest = match
+			named = slices.Clone(group.rules)
+		case match > 0 && match == longest:
+			named = append(named, group.rules...)
+		}
+		if isWildcard {
+			wildcard = append(wildcard, group.rules...)
+		}
+	}
+	if longest > 0 {
+		return named
+	}
+	return wildcard
+}
+
+// robotsPatternMatch reports whether a robots.txt path pattern matches
+// target. "*" matches any sequence of characters and a trailing "$" anchors
+// the pattern to the end of target; otherwise the pattern is a prefix match.
+func robotsPatternMatch(pattern, target string) bool {
+	anchored := strings.HasSuffix(pattern, "$")
+	if anchored {
+		pattern = pattern[:len(pattern)-1]
+	}
+
+	parts := strings.Split(pattern, "*")
+	if !strings.HasPrefix(target, parts[0]) {
+		return false
+	}
+	pos := len(parts[0])
+	if len(parts) == 1 {
+		return !anchored || pos == len(target)
+	}
+
+	for i, part := range parts[1:] {
+		if anchored && i == len(parts)-2 {
+			// The final segment must end the target, after everything matched so far
+			return len(target)-pos >= len(part) && strings.HasSuffix(target, part)
+		}
+		idx := strings.Index(target[pos:], part)
+		if idx < 0 {
+			return false
+		}
+		pos += idx + len(part)
+	}
+	return true
+}
@@ -0,0 +1,110 @@
+package crawler
+
+import "testing"
+
+func TestRobotsPatternMatch(t *testing.T) {
+	tests := []struct {
+		pattern string
+		target  string
+		want    bool
+	}{
+		{"/", "/anything", true},
+		{"/fish", "/fish.html", true},
+		{"/fish", "/Fish.asp", false},
+		{"/fish$", "/fish", true},
+		{"/fish$", "/fish/", false},
+		{"/*.php", "/index.php", true},
+		{"/*.php", "/folder/filename.php?parameters", true},
+		{"/*.php", "/windows.PHP", false},
+		{"/*.php$", "/filename.php", true},
+		{"/*.php$", "/filename.php?parameters", false},
+		{"/*.php$", "/filename.php5", false},
+		{"/fish*.php", "/fishheads/catfish.php?parameters", true},
+		{"/fish*.php", "/Fish.PHP", false},
+		{"/*?sessionid", "/cart?sessionid=42", true},
+		{"/a*b*c$", "/a-b-b-c", true},
+		{"/a*b*c$", "/a-b-c-d", false},
+		{"/a*bc$", "/abc", true},
+	}
+	for _, tt := range tests {
+		if got := robotsPatternMatch(tt.pattern, tt.target); got != tt.want {
+			t.Errorf("robotsPatternMatch(%q, %q) = %v, want %v", tt.pattern, tt.target, got, tt.want)
+		}
+	}
+}
+
+func TestGoogleRobots_Precedence(t *testing.T) {
+	robots := parseGoogleRobots([]byte(`
+User-agent: *
+Disallow: /
+Allow: /public
+Disallow: /public/secret
+Allow: /page
+Disallow: /page
+Disallow: /*.pdf$
+Allow: /docs/*.pdf$
+Disallow: /*?sessionid
+`))
+
+	tests := []struct {
+		target string
+		want   bool
+	}{
+		{"/private", false},
+		{"/public/page", true},
+		{"/public/secret/x", false},
+		{"/page", true},              // equal length: Allow wins
+		{"/public/report.pdf", true}, // "/public" and "/*.pdf$" tie at 7 characters: Allow wins
+		{"/docs/manual.pdf", true},
+		{"/public/list?sessionid=1", false},
+		{"/robots.txt", true},
+	}
+	for _, tt := range tests {
+		if got := robots.allowed(tt.target, "zombiecrawl/1.0"); got != tt.want {
+			t.Errorf("allowed(%q) = %v, want %v", tt.target, got, tt.want)
+		}
+	}
+}
+
+func TestGoogleRobots_GroupSelection(t *testing.T) {
+	robots := parseGoogleRobots([]byte(`
+User-agent: *
+Disallow: /
+
+User-agent: zombie
+Disallow: /zombie-only
+
+User-agent: ZombieCrawl
+User-agent: other
+Disallow: /named
+
+# groups naming the same agent are merged
+User-agent: zombiecrawl
+Disallow: /merged
+`))
+
+	tests := []struct {
+		agent  string
+		target string
+		want   bool
+	}{
+		{"zombiecrawl/1.0 (+https://example.com)", "/named", false},
+		{"zombiecrawl/1.0", "/merged", false},
+		{"zombiecrawl/1.0", "/zombie-only", true}, // longer agent match wins
+		{"zombiecrawl/1.0", "/other", true},
+		{"zombie/2", "/zombie-only", false},
+		{"somebot/1.0", "/anything", false}, // falls back to *
+	}
+	for _, tt := range tests {
+		if got := robots.allowed(tt.target, tt.agent); got != tt.want {
+			t.Errorf("allowed(%q, %q) = %v, want %v", tt.target, tt.agent, got, tt.want)
+		}
+	}
+}
+
+func TestGoogleRobots_NoGroupsAllowsAll(t *testing.T) {
+	robots := parseGoogleRobots([]byte("Disallow: /orphan-rule\nSitemap: https://example.com/s.xml\n"))
+	if !robots.allowed("/orphan-rule", "bot") {
+		t.Error("rules outside any group should be ignored")
+	}
+}
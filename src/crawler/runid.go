@@ -0,0 +1,48 @@
+package crawler
+
+import (
+	"crypto/rand"
+	"time"
+)
+
+// crockford is the Crockford base32 alphabet ULIDs are written in.
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// DeterministicRunID is the run ID of deterministic crawls: the ULID of
+// DeterministicEpoch with no random bits, so their reports stay identical.
+var DeterministicRunID = ulid(DeterministicEpoch, [10]byte{})
+
+// NewRunID returns a new run ID: a ULID, 26 characters that sort by the
+// millisecond the ID was made, so IDs of later crawls sort after earlier
+// ones.
+func NewRunID() string {
+	var entropy [10]byte
+	_, _ = rand.Read(entropy[:]) // Never fails
+	return ulid(time.Now(), entropy)
+}
+
+// ulid encodes t in milliseconds as the 48-bit timestamp and entropy as the
+// 80 random bits of a ULID.
+func ulid(t time.Time, entropy [10]byte) string {
+	var id [16]byte
+	ms := uint64(t.UnixMilli())
+	for i := range 6 {
+		id[i] = byte(ms >> (40 - 8*i))
+	}
+	copy(id[6:], entropy[:])
+
+	// 26 characters of 5 bits hold 130 bits: the 128 bits of id behind
+	// two zero bits
+	var out [26]byte
+	for i := range out {
+		var digit byte
+		for bit := 5 * i; bit < 5*i+5; bit++ {
+			digit <<= 1
+			if pos := bit - 2; pos >= 0 {
+				digit |= id[pos/8] >> (7 - pos%8) & 1
+			}
+		}
+		out[i] = crockford[digit]
+	}
+	return string(out[:])
+}
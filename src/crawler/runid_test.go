@@ -0,0 +1,96 @@
+package crawler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestULID(t *testing.T) {
+	// The example from the ULID specification's timestamp, with all random
+	// bits set
+	at := time.UnixMilli(1469918176385)
+	entropy := [10]byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}
+	if got, want := ulid(at, entropy), "01ARYZ6S41ZZZZZZZZZZZZZZZZ"; got != want {
+		t.Errorf("ulid() = %q, want %q", got, want)
+	}
+	if DeterministicRunID != "00000000000000000000000000" {
+		t.Errorf("DeterministicRunID = %q, want all zeros", DeterministicRunID)
+	}
+}
+
+func TestNewRunID(t *testing.T) {
+	first := NewRunID()
+	time.Sleep(2 * time.Millisecond)
+	second := NewRunID()
+	if len(first) != 26 || strings.Trim(first, crockford) != "" {
+		t.Errorf("NewRunID() = %q, want 26 Crockford base32 characters", first)
+	}
+	if first >= second {
+		t.Errorf("NewRunID() = %q after %q, want later IDs to sort after earlier ones", second, first)
+	}
+}
+
+func TestRun_RunIDPropagates(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/" {
+			_, _ = fmt.Fprint(w, `<a href="/missing">missing</a>`)
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	defer ts.Close()
+
+	logger, rec := newRecordingLogger()
+	cfg := DefaultConfig(ts.URL)
+	cfg.Delay = 1
+	cfg.Logger = logger
+	cfg.RunID = "01J0000000000000000000000A"
+	progressCh := make(chan CrawlEvent, 100)
+	c, err := New(cfg, progressCh)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	res, err := c.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run() error: %v", err)
+	}
+	close(progressCh)
+
+	if res.RunID != cfg.RunID || c.RunID() != cfg.RunID {
+		t.Errorf("Result.RunID = %q, RunID() = %q, want %q", res.RunID, c.RunID(), cfg.RunID)
+	}
+	for evt := range progressCh {
+		if evt.RunID != cfg.RunID {
+			t.Errorf("event for %q has RunID %q, want %q", evt.URL, evt.RunID, cfg.RunID)
+		}
+	}
+	records := rec.records(t, "crawl finished")
+	if len(records) != 1 || records[0]["run_id"] != cfg.RunID {
+		t.Errorf("crawl finished records = %v, want one with run_id %q", records, cfg.RunID)
+	}
+}
+
+func TestNew_RunIDDefaults(t *testing.T) {
+	c, err := New(DefaultConfig("http://example.com/"), nil)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	if len(c.RunID()) != 26 {
+		t.Errorf("RunID() = %q, want a new ULID", c.RunID())
+	}
+
+	cfg := DefaultConfig("http://example.com/")
+	cfg.Deterministic = true
+	c, err = New(cfg, nil)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	if c.RunID() != DeterministicRunID {
+		t.Errorf("deterministic RunID() = %q, want %q", c.RunID(), DeterministicRunID)
+	}
+}
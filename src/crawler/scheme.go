@@ -0,0 +1,72 @@
+package crawler
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/lukemcguire/zombiecrawl/result"
+	"github.com/lukemcguire/zombiecrawl/urlutil"
+)
+
+// schemeForms are the http:// and https:// forms of an internal page, each
+// set if that form was crawled and answered without a redirect.
+type schemeForms struct {
+	http, https *CrawlJob
+}
+
+// schemeless returns rawURL without its scheme, e.g. "//example.com/a".
+func schemeless(rawURL string) string {
+	_, rest, _ := strings.Cut(rawURL, ":")
+	return rest
+}
+
+// recordScheme notes that job's page answered without a redirect, for
+// schemeDuplicates.
+func (c *Crawler) recordScheme(job CrawlJob) {
+	key := schemeless(job.URL)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.schemePages == nil {
+		c.schemePages = make(map[string]schemeForms)
+	}
+	forms := c.schemePages[key]
+	switch {
+	case strings.HasPrefix(job.URL, "http://"):
+		forms.http = &job
+	case strings.HasPrefix(job.URL, "https://"):
+		forms.https = &job
+	}
+	c.schemePages[key] = forms
+}
+
+// schemeDuplicates returns a warning, sorted by URL, for each page crawled
+// over both http and https where neither form redirects to the other, so
+// the site serves the same page twice with no canonical scheme.
+func (c *Crawler) schemeDuplicates() []result.HygieneWarning {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var warnings []result.HygieneWarning
+	for _, forms := range c.schemePages {
+		if forms.http == nil || forms.https == nil {
+			continue
+		}
+		warnings = append(warnings, result.HygieneWarning{
+			Kind:       result.HygieneSchemeDuplicate,
+			URL:        forms.http.URL,
+			Target:     forms.https.URL,
+			SourcePage: forms.http.SourcePage,
+		})
+	}
+	slices.SortFunc(warnings, func(a, b result.HygieneWarning) int { return strings.Compare(a.URL, b.URL) })
+	return warnings
+}
+
+// visitKey returns the key the normalized URL is deduplicated under. With
+// FoldSchemes, internal URLs are keyed by their https form, so the http
+// and https forms of a page count as one.
+func (c *Crawler) visitKey(normalized, startHost string) string {
+	if c.cfg.FoldSchemes && strings.HasPrefix(normalized, "http://") && urlutil.IsSameDomain(normalized, startHost) {
+		return httpsURL(normalized)
+	}
+	return normalized
+}
@@ -0,0 +1,110 @@
+package crawler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/lukemcguire/zombiecrawl/result"
+)
+
+// mixedSchemeSite serves a home page linking to /dup and /canon over both
+// schemes. /dup is served over both without a redirect; http /canon
+// redirects to https.
+func mixedSchemeSite(t *testing.T) (plain, secure *httptest.Server) {
+	t.Helper()
+	var plainURL string
+	plain = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		secureForm := "https" + plainURL[len("http"):]
+		switch r.URL.Path {
+		case "/":
+			_, _ = fmt.Fprintf(w, `<a href="/dup">a</a><a href="%[1]s/dup">b</a><a href="/canon">c</a><a href="%[1]s/canon">d</a>`, secureForm)
+		case "/canon":
+			http.Redirect(w, r, secureForm+"/canon", http.StatusMovedPermanently)
+		default:
+			_, _ = fmt.Fprint(w, `<html></html>`)
+		}
+	}))
+	plainURL = plain.URL
+	secure = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprint(w, `<html></html>`)
+	}))
+	t.Cleanup(plain.Close)
+	t.Cleanup(secure.Close)
+	return plain, secure
+}
+
+func TestRun_SchemeDuplicates(t *testing.T) {
+	plain, secure := mixedSchemeSite(t)
+	cfg := DefaultConfig(plain.URL)
+	cfg.Delay = 1
+	cfg.CheckHTTPS = true
+	cfg.Transport = schemeTransport{plain: plain, secure: secure}
+	c, err := New(cfg, nil)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	res, err := c.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run() error: %v", err)
+	}
+
+	var duplicates []result.HygieneWarning
+	for _, warning := range res.Hygiene {
+		switch {
+		case warning.Kind == result.HygieneSchemeDuplicate:
+			duplicates = append(duplicates, warning)
+		case warning.URL == plain.URL+"/dup":
+			t.Errorf("duplicate page also warned as %s", warning.Kind)
+		}
+	}
+	if len(duplicates) != 1 {
+		t.Fatalf("scheme duplicates = %+v, want one for /dup", duplicates)
+	}
+	if got := duplicates[0]; got.URL != plain.URL+"/dup" || got.Target != httpsURL(plain.URL)+"/dup" {
+		t.Errorf("duplicate = %s -> %s, want the http and https forms of /dup", got.URL, got.Target)
+	}
+}
+
+func TestRun_FoldSchemes(t *testing.T) {
+	plain, secure := mixedSchemeSite(t)
+	cfg := DefaultConfig(plain.URL)
+	cfg.Delay = 1
+	cfg.CheckHTTPS = true
+	cfg.FoldSchemes = true
+	cfg.Transport = schemeTransport{plain: plain, secure: secure}
+	c, err := New(cfg, nil)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	res, err := c.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run() error: %v", err)
+	}
+
+	// The home page, /dup, and /canon, each once
+	if res.Stats.TotalChecked != 3 {
+		t.Errorf("TotalChecked = %d, want 3 with the https forms folded", res.Stats.TotalChecked)
+	}
+	for _, warning := range res.Hygiene {
+		if warning.Kind == result.HygieneSchemeDuplicate {
+			t.Errorf("unexpected duplicate with FoldSchemes: %+v", warning)
+		}
+	}
+}
+
+func TestVisitKey(t *testing.T) {
+	c := &Crawler{cfg: Config{FoldSchemes: true}}
+	if got := c.visitKey("http://example.com/a", "example.com"); got != "https://example.com/a" {
+		t.Errorf("visitKey(internal http) = %q, want the https form", got)
+	}
+	if got := c.visitKey("http://other.example/a", "example.com"); got != "http://other.example/a" {
+		t.Errorf("visitKey(external) = %q, want it unchanged", got)
+	}
+	c.cfg.FoldSchemes = false
+	if got := c.visitKey("http://example.com/a", "example.com"); got != "http://example.com/a" {
+		t.Errorf("visitKey without FoldSchemes = %q, want it unchanged", got)
+	}
+}
@@ -0,0 +1,273 @@
+package crawler
+
+import (
+	"cmp"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/lukemcguire/zombiecrawl/result"
+	"github.com/lukemcguire/zombiecrawl/urlutil"
+	"golang.org/x/net/html"
+)
+
+// maxDescriptionLength is the meta description length, in characters, past
+// which search engines typically truncate the snippet.
+const maxDescriptionLength = 160
+
+// PageMeta is the title, meta description, language variants, pagination
+// links, feeds, social preview URLs, and storage directive of a crawled HTML
+// page. The title and description have runs of whitespace collapsed to
+// single spaces.
+type PageMeta struct {
+	Title       string
+	Description string
+	Alternates  []Alternate // <link rel="alternate" hreflang> variants, in page order
+	Next        string      // <link rel="next"> target, normalized (empty = none)
+	Prev        string      // <link rel="prev"> target, normalized (empty = none)
+	Feeds       []string    // RSS and Atom feeds advertised with <link rel="alternate">, normalized
+	Social      []SocialURL // OpenGraph and Twitter card URLs, in page order
+	NoArchive   string      // noarchive, nosnippet, or none directive of <meta name="robots"> (empty if absent)
+}
+
+// Alternate is a language or regional variant of a page, declared with
+// <link rel="alternate" hreflang="de" href="...">.
+type Alternate struct {
+	Lang string // The hreflang value, e.g. "de", "en-GB", or "x-default"
+	URL  string // The variant, resolved and normalized
+}
+
+// SocialURL is a URL declared for link previews on social sites, such as
+// <meta property="og:image" content="...">.
+type SocialURL struct {
+	Property string // "og:image", "og:url", or "twitter:image"
+	URL      string // The URL, resolved and normalized
+}
+
+// socialProperties are the meta properties holding social preview URLs.
+var socialProperties = []string{"og:image", "og:url", "twitter:image"}
+
+// metaCollector records a page's title, meta description, hreflang
+// alternates, pagination links, feeds, and social preview URLs while it is
+// tokenized for links. Only the
+// first <title> outside inline SVG counts, as browsers show. A nil
+// *metaCollector ignores every call.
+type metaCollector struct {
+	base      *url.URL
+	meta      PageMeta
+	title     strings.Builder
+	inTitle   bool
+	seenTitle bool
+	seenDesc  bool
+	svgDepth  int
+}
+
+// newMetaCollector returns a collector resolving links against base.
+func newMetaCollector(base *url.URL) *metaCollector {
+	return &metaCollector{base: base}
+}
+
+// start handles a start or self-closing tag.
+func (m *metaCollector) start(token html.Token) {
+	if m == nil {
+		return
+	}
+	switch token.Data {
+	case "svg":
+		if token.Type == html.StartTagToken {
+			m.svgDepth++
+		}
+	case "title":
+		if m.svgDepth == 0 && !m.seenTitle && token.Type == html.StartTagToken {
+			m.inTitle = true
+			m.seenTitle = true
+		}
+	case "meta":
+		if name, _ := attr(token, "name"); !m.seenDesc && strings.EqualFold(strings.TrimSpace(name), "description") {
+			content, _ := attr(token, "content")
+			m.meta.Description = strings.Join(strings.Fields(content), " ")
+			m.seenDesc = true
+		}
+		if name, _ := attr(token, "name"); m.meta.NoArchive == "" && strings.EqualFold(strings.TrimSpace(name), "robots") {
+			content, _ := attr(token, "content")
+			m.meta.NoArchive = findNoArchive(content)
+		}
+		m.social(token)
+	case "link":
+		m.alternate(token)
+		m.pagination(token)
+		m.feed(token)
+	}
+}
+
+// alternate records a <link rel="alternate" hreflang> variant.
+func (m *metaCollector) alternate(token html.Token) {
+	rel, _ := attr(token, "rel")
+	lang, hasLang := attr(token, "hreflang")
+	href, _ := attr(token, "href")
+	if !hasLang || !hasRel(rel, "alternate") {
+		return
+	}
+	if target := m.resolve(href); target != "" {
+		m.meta.Alternates = append(m.meta.Alternates, Alternate{Lang: strings.TrimSpace(lang), URL: target})
+	}
+}
+
+// pagination records the first <link rel="next"> and <link rel="prev">
+// targets.
+func (m *metaCollector) pagination(token html.Token) {
+	rel, _ := attr(token, "rel")
+	href, _ := attr(token, "href")
+	target := m.resolve(href)
+	if target == "" {
+		return
+	}
+	if hasRel(rel, "next") && m.meta.Next == "" {
+		m.meta.Next = target
+	}
+	if (hasRel(rel, "prev") || hasRel(rel, "previous")) && m.meta.Prev == "" {
+		m.meta.Prev = target
+	}
+}
+
+// social records a <meta> tag holding a social preview URL. OpenGraph uses
+// the property attribute and Twitter cards the name attribute, but pages mix
+// them up, so either is accepted.
+func (m *metaCollector) social(token html.Token) {
+	property, ok := attr(token, "property")
+	if !ok {
+		property, _ = attr(token, "name")
+	}
+	property = strings.ToLower(strings.TrimSpace(property))
+	if !slices.Contains(socialProperties, property) {
+		return
+	}
+	content, _ := attr(token, "content")
+	if target := m.resolve(content); target != "" {
+		m.meta.Social = append(m.meta.Social, SocialURL{Property: property, URL: target})
+	}
+}
+
+// feed records a <link rel="alternate"> advertising an RSS or Atom feed.
+func (m *metaCollector) feed(token html.Token) {
+	rel, _ := attr(token, "rel")
+	mediaType, _ := attr(token, "type")
+	href, _ := attr(token, "href")
+	if !hasRel(rel, "alternate") || !isFeedType(mediaType) {
+		return
+	}
+	if target := m.resolve(href); target != "" && !slices.Contains(m.meta.Feeds, target) {
+		m.meta.Feeds = append(m.meta.Feeds, target)
+	}
+}
+
+// isFeedType reports whether mediaType is that of an RSS or Atom feed.
+func isFeedType(mediaType string) bool {
+	switch strings.ToLower(strings.TrimSpace(mediaType)) {
+	case "application/rss+xml", "application/atom+xml", "application/rdf+xml":
+		return true
+	default:
+		return false
+	}
+}
+
+// resolve returns href resolved against the page and normalized, or "" if
+// it is empty or not an HTTP(S) URL.
+func (m *metaCollector) resolve(href string) string {
+	target, err := url.Parse(urlutil.Sanitize(href))
+	if err != nil || strings.TrimSpace(href) == "" {
+		return ""
+	}
+	resolved := m.base.ResolveReference(target).String()
+	if !urlutil.IsHTTPScheme(resolved) {
+		return ""
+	}
+	normalized, err := urlutil.Normalize(resolved)
+	if err != nil {
+		return ""
+	}
+	return normalized
+}
+
+// hasRel reports whether the space-separated rel attribute
+// includes want, ignoring case.
+func hasRel(rel, want string) bool {
+	return slices.ContainsFunc(strings.Fields(rel), func(r string) bool { return strings.EqualFold(r, want) })
+}
+
+// end handles the end tag of the named element.
+func (m *metaCollector) end(name string) {
+	if m == nil {
+		return
+	}
+	switch name {
+	case "svg":
+		m.svgDepth = max(m.svgDepth-1, 0)
+	case "title":
+		m.inTitle = false
+	}
+}
+
+// text records character data, which is the title inside <title>.
+func (m *metaCollector) text(data []byte) {
+	if m == nil || !m.inTitle {
+		return
+	}
+	m.title.Write(data)
+}
+
+// result returns the collected metadata.
+func (m *metaCollector) result() *PageMeta {
+	if m == nil {
+		return nil
+	}
+	meta := m.meta
+	meta.Title = strings.Join(strings.Fields(m.title.String()), " ")
+	return &meta
+}
+
+// seoPage is the metadata of one crawled internal page.
+type seoPage struct {
+	url  string
+	meta PageMeta
+}
+
+// seoReport returns the SEO issues among pages: missing titles, titles
+// shared by several pages, and over-length meta descriptions. Issues are
+// ordered by page URL, with each duplicated title's pages together.
+func seoReport(pages []seoPage) []result.SEOIssue {
+	pages = slices.Clone(pages)
+	slices.SortFunc(pages, func(a, b seoPage) int { return cmp.Compare(a.url, b.url) })
+
+	byTitle := make(map[string][]string)
+	for _, page := range pages {
+		if page.meta.Title != "" {
+			byTitle[page.meta.Title] = append(byTitle[page.meta.Title], page.url)
+		}
+	}
+
+	var issues []result.SEOIssue
+	reported := make(map[string]bool)
+	for _, page := range pages {
+		title := page.meta.Title
+		switch {
+		case title == "":
+			issues = append(issues, result.SEOIssue{Kind: result.SEOMissingTitle, URL: page.url})
+		case len(byTitle[title]) > 1 && !reported[title]:
+			reported[title] = true
+			for _, duplicate := range byTitle[title] {
+				issues = append(issues, result.SEOIssue{Kind: result.SEODuplicateTitle, URL: duplicate, Detail: title})
+			}
+		}
+		if length := utf8.RuneCountInString(page.meta.Description); length > maxDescriptionLength {
+			issues = append(issues, result.SEOIssue{
+				Kind:   result.SEOLongDescription,
+				URL:    page.url,
+				Detail: fmt.Sprintf("%d characters, over %d", length, maxDescriptionLength),
+			})
+		}
+	}
+	return issues
+}
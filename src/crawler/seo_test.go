@@ -0,0 +1,160 @@
+package crawler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/lukemcguire/zombiecrawl/result"
+)
+
+func TestMetaCollector(t *testing.T) {
+	tests := []struct {
+		name string
+		page string
+		want PageMeta
+	}{
+		{
+			name: "title and description",
+			page: `<html><head><title>  About
+				Us &amp; Co </title><meta name="Description" content=" Who  we are "></head></html>`,
+			want: PageMeta{Title: "About Us & Co", Description: "Who we are"},
+		},
+		{
+			name: "first title wins",
+			page: `<title>First</title><title>Second</title><meta name="description" content="one"><meta name="description" content="two">`,
+			want: PageMeta{Title: "First", Description: "one"},
+		},
+		{
+			name: "svg titles ignored",
+			page: `<body><svg><title>Icon</title><g><title>Shape</title></g></svg><p>text</p></body>`,
+			want: PageMeta{},
+		},
+		{
+			name: "hreflang alternates",
+			page: `<link rel="alternate" hreflang="de" href="/de/"><link rel="Alternate" hreflang="x-default" href="https://example.com/#top">
+				<link rel="alternate" href="/feed.xml"><link rel="stylesheet" hreflang="fr" href="/fr.css"><link rel="alternate" hreflang="fr" href="mailto:x@example.com">`,
+			want: PageMeta{Alternates: []Alternate{{Lang: "de", URL: "https://example.com/de"}, {Lang: "x-default", URL: "https://example.com/"}}},
+		},
+		{
+			name: "pagination links",
+			page: `<link rel="prev" href="/blog/page/1/"><link rel="next" href="/blog/page/3"><link rel="next" href="/blog/page/4">
+				<link rel="previous" href="/blog/page/0">`,
+			want: PageMeta{Next: "https://example.com/blog/page/3", Prev: "https://example.com/blog/page/1"},
+		},
+		{
+			name: "feeds",
+			page: `<link rel="alternate" type="application/rss+xml" href="/feed.xml"><link rel="alternate" type="Application/Atom+XML " href="https://example.com/atom.xml">
+				<link rel="alternate" type="application/rss+xml" href="/feed.xml"><link rel="alternate" type="text/html" href="/print"><link rel="feed" type="application/rss+xml" href="/other.xml">`,
+			want: PageMeta{Feeds: []string{"https://example.com/feed.xml", "https://example.com/atom.xml"}},
+		},
+		{
+			name: "social preview URLs",
+			page: `<meta property="og:image" content="/img/card.png"><meta name="twitter:image" content="https://cdn.example.net/card.png">
+				<meta property="og:url" content="https://example.com/post/"><meta property="og:title" content="/not-a-url"><meta name="twitter:image" content="">`,
+			want: PageMeta{Social: []SocialURL{
+				{Property: "og:image", URL: "https://example.com/img/card.png"},
+				{Property: "twitter:image", URL: "https://cdn.example.net/card.png"},
+				{Property: "og:url", URL: "https://example.com/post"},
+			}},
+		},
+		{
+			name: "robots storage directive",
+			page: `<meta name="robots" content="noindex"><meta name="ROBOTS" content="index, nosnippet">`,
+			want: PageMeta{NoArchive: "nosnippet"},
+		},
+		{
+			name: "other meta tags ignored",
+			page: `<meta property="og:description" content="social"><meta name="keywords" content="a,b">`,
+			want: PageMeta{},
+		},
+	}
+	base, _ := url.Parse("https://example.com/")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			meta := newMetaCollector(base)
+			if _, err := extractLinks(strings.NewReader(tt.page), base, nil, nil, meta, nil); err != nil {
+				t.Fatalf("extractLinks() error: %v", err)
+			}
+			if got := meta.result(); got.Title != tt.want.Title || got.Description != tt.want.Description || got.Next != tt.want.Next || got.Prev != tt.want.Prev || got.NoArchive != tt.want.NoArchive ||
+				!slices.Equal(got.Alternates, tt.want.Alternates) || !slices.Equal(got.Feeds, tt.want.Feeds) || !slices.Equal(got.Social, tt.want.Social) {
+				t.Errorf("result() = %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMetaCollector_Nil(t *testing.T) {
+	var meta *metaCollector
+	meta.text([]byte("x"))
+	meta.end("title")
+	if got := meta.result(); got != nil {
+		t.Errorf("result() = %+v, want nil", got)
+	}
+}
+
+func TestSEOReport(t *testing.T) {
+	long := strings.Repeat("é", maxDescriptionLength+1)
+	pages := []seoPage{
+		{url: "https://example.com/c", meta: PageMeta{Title: "Home"}},
+		{url: "https://example.com/b", meta: PageMeta{Description: long}},
+		{url: "https://example.com/a", meta: PageMeta{Title: "Home", Description: strings.Repeat("x", maxDescriptionLength)}},
+		{url: "https://example.com/d", meta: PageMeta{Title: "Unique"}},
+	}
+	want := []result.SEOIssue{
+		{Kind: result.SEODuplicateTitle, URL: "https://example.com/a", Detail: "Home"},
+		{Kind: result.SEODuplicateTitle, URL: "https://example.com/c", Detail: "Home"},
+		{Kind: result.SEOMissingTitle, URL: "https://example.com/b"},
+		{Kind: result.SEOLongDescription, URL: "https://example.com/b", Detail: "161 characters, over 160"},
+	}
+	if got := seoReport(pages); !slices.Equal(got, want) {
+		t.Errorf("seoReport() = %+v, want %+v", got, want)
+	}
+}
+
+func TestRun_SEOAndSourceTitles(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/":
+			_, _ = fmt.Fprint(w, `<title>Home</title><a href="/about">about</a><a href="/copy">copy</a><a href="/untitled">untitled</a>`)
+		case "/about":
+			_, _ = fmt.Fprint(w, `<title>About us</title><a href="/missing">gone</a>`)
+		case "/copy":
+			_, _ = fmt.Fprint(w, `<title>Home</title>`)
+		case "/untitled":
+			_, _ = fmt.Fprint(w, `<p>no title</p>`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	cfg := DefaultConfig(ts.URL)
+	cfg.Delay = 1
+	cfg.SEO = true
+	c, err := New(cfg, nil)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	res, err := c.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run() error: %v", err)
+	}
+
+	if len(res.BrokenLinks) != 1 || res.BrokenLinks[0].SourceTitle != "About us" {
+		t.Errorf("broken links = %+v, want /missing with its source page's title", res.BrokenLinks)
+	}
+	want := []result.SEOIssue{
+		{Kind: result.SEODuplicateTitle, URL: ts.URL + "/", Detail: "Home"},
+		{Kind: result.SEODuplicateTitle, URL: ts.URL + "/copy", Detail: "Home"},
+		{Kind: result.SEOMissingTitle, URL: ts.URL + "/untitled"},
+	}
+	if !slices.Equal(res.SEO, want) {
+		t.Errorf("SEO = %+v, want %+v", res.SEO, want)
+	}
+}
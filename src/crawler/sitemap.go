@@ -0,0 +1,192 @@
+package crawler
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/lukemcguire/zombiecrawl/result"
+	"github.com/lukemcguire/zombiecrawl/urlutil"
+)
+
+const (
+	// maxSitemapDepth bounds how many levels of sitemap index files are followed.
+	maxSitemapDepth = 3
+
+	// maxSitemapURLs caps the URLs taken from all sitemaps of one crawl. The
+	// sitemap protocol allows 50,000 per file; this keeps pathological index
+	// files from flooding the frontier.
+	maxSitemapURLs = 50_000
+
+	// maxSitemapBytes caps the decoded size of a single sitemap file (the
+	// protocol limit is 50 MB uncompressed).
+	maxSitemapBytes = 50 << 20
+)
+
+// sitemapDocument decodes both <urlset> and <sitemapindex> documents; only
+// the fields matching the root element are populated.
+type sitemapDocument struct {
+	XMLName  xml.Name
+	URLs     []sitemapLoc `xml:"url"`
+	Sitemaps []sitemapLoc `xml:"sitemap"`
+}
+
+// sitemapLoc is a <url> or <sitemap> entry.
+type sitemapLoc struct {
+	Loc string `xml:"loc"`
+}
+
+// sitemapPage is a page URL listed in a sitemap.
+type sitemapPage struct {
+	URL     string // The listed page
+	Sitemap string // The sitemap file that listed it
+}
+
+// sitemapSources returns the sitemaps to load for startURL: the Sitemap:
+// directives from robots.txt, or /sitemap.xml if there are none.
+func (c *Crawler) sitemapSources(ctx context.Context, startURL, userAgent string) []string {
+	sitemaps, err := c.robotsChecker.Sitemaps(ctx, startURL, userAgent)
+	if err != nil {
+		c.cfg.logger().Warn("reading sitemaps from robots.txt failed", "url", startURL, "host", hostFromURL(startURL), "error", err)
+	}
+	if len(sitemaps) > 0 {
+		return sitemaps
+	}
+	parsedURL, err := url.Parse(startURL)
+	if err != nil {
+		return nil
+	}
+	return []string{(&url.URL{Scheme: parsedURL.Scheme, Host: parsedURL.Host, Path: "/sitemap.xml"}).String()}
+}
+
+// loadSitemaps fetches each sitemap (following sitemap index files) and
+// returns the page URLs they list, in order and without duplicates. Sitemaps
+// that cannot be fetched or parsed are logged and skipped.
+func (c *Crawler) loadSitemaps(ctx context.Context, sitemapURLs []string, userAgent string) []sitemapPage {
+	var pages []sitemapPage
+	seen := make(map[string]bool)
+	var load func(sitemapURL string, depth int)
+	load = func(sitemapURL string, depth int) {
+		if depth > maxSitemapDepth || seen[sitemapURL] || len(pages) >= maxSitemapURLs {
+			return
+		}
+		seen[sitemapURL] = true
+
+		doc, err := fetchSitemap(ctx, c.client, sitemapURL, userAgent, c.cfg)
+		if err != nil {
+			c.cfg.logger().Warn("skipping sitemap", "url", sitemapURL, "host", hostFromURL(sitemapURL), "error", err)
+			return
+		}
+		for _, entry := range doc.URLs {
+			loc := strings.TrimSpace(entry.Loc)
+			if loc == "" || seen[loc] || len(pages) >= maxSitemapURLs {
+				continue
+			}
+			seen[loc] = true
+			pages = append(pages, sitemapPage{URL: loc, Sitemap: sitemapURL})
+		}
+		for _, entry := range doc.Sitemaps {
+			load(strings.TrimSpace(entry.Loc), depth+1)
+		}
+	}
+	for _, sitemapURL := range sitemapURLs {
+		load(sitemapURL, 1)
+	}
+	return pages
+}
+
+// fetchSitemap downloads and decodes one sitemap file. Files whose path ends
+// in .gz are decompressed.
+func fetchSitemap(ctx context.Context, client *http.Client, sitemapURL, userAgent string, cfg Config) (*sitemapDocument, error) {
+	reqCtx, cancel := context.WithTimeout(ctx, cfg.RequestTimeout)
+	defer cancel()
+
+	req, err := newRequest(reqCtx, http.MethodGet, CrawlJob{URL: sitemapURL, UserAgent: userAgent}, cfg)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetch %s: %w", sitemapURL, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode >= 400 {
+		return nil, fmt.Errorf("fetch %s: status %d", sitemapURL, resp.StatusCode)
+	}
+
+	body := cfg.Bandwidth.reader(reqCtx, resp.Body)
+	if strings.HasSuffix(resp.Request.URL.Path, ".gz") {
+		gz, gzErr := gzip.NewReader(body)
+		if gzErr != nil {
+			return nil, fmt.Errorf("decompress %s: %w", sitemapURL, gzErr)
+		}
+		defer func() { _ = gz.Close() }()
+		body = gz
+	}
+
+	var doc sitemapDocument
+	if err := xml.NewDecoder(io.LimitReader(body, maxSitemapBytes)).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", sitemapURL, err)
+	}
+	if doc.XMLName.Local != "urlset" && doc.XMLName.Local != "sitemapindex" {
+		return nil, fmt.Errorf("parse %s: unexpected root element <%s>", sitemapURL, doc.XMLName.Local)
+	}
+	return &doc, nil
+}
+
+// seedFromSitemaps queues the same-site pages listed in the start host's
+// sitemaps as depth-1 jobs, applying the same rewrite, dedup, depth, blocked
+// host, robots.txt, and plugin rules as links discovered on pages.
+func (c *Crawler) seedFromSitemaps(ctx context.Context, startURL string, queue *frontier) {
+	startHost := hostFromURL(startURL)
+	userAgent := c.userAgents.For(startURL)
+	for _, page := range c.loadSitemaps(ctx, c.sitemapSources(ctx, startURL, userAgent), userAgent) {
+		normalized, err := urlutil.Normalize(c.cfg.rewrite(page.URL))
+		if err != nil || !urlutil.IsSameDomain(normalized, startHost) {
+			// The sitemap protocol only allows URLs on the sitemap's own site
+			continue
+		}
+		normalized = c.cfg.stripSessionIDs(normalized)
+		if !c.visitIfNew(normalized, startHost) {
+			continue
+		}
+		class := c.cfg.classify(normalized, page.Sitemap, startHost)
+		if class == LinkSkip {
+			c.cfg.logger().Info("skipping sitemap page by classifier", "url", normalized, "host", hostFromURL(normalized), "source_page", page.Sitemap)
+			c.recordSkipped(page.Sitemap, normalized, skippedByClassifier)
+			continue
+		}
+		if pattern := blockedHost(c.cfg.BlockedHosts, normalized); pattern != "" {
+			c.skip(page.Sitemap, normalized, pattern, class == LinkExternal)
+			continue
+		}
+		pageUserAgent := c.userAgents.For(normalized)
+		if allowed, _ := c.robotsAllowed(ctx, normalized, pageUserAgent); !allowed {
+			c.cfg.logger().Info("skipping sitemap page disallowed by robots.txt", "url", normalized, "host", hostFromURL(normalized), "depth", 1, "category", result.CategoryRobotsBlocked)
+			c.events.publish(CrawlEvent{
+				URL:           normalized,
+				Error:         result.ErrRobotsBlocked.Error(),
+				ErrorCategory: result.CategoryRobotsBlocked,
+			})
+			continue
+		}
+		job := CrawlJob{
+			URL:        normalized,
+			SourcePage: page.Sitemap,
+			IsExternal: class == LinkExternal,
+			Depth:      1,
+			UserAgent:  pageUserAgent,
+			Priority:   class == LinkPriority,
+		}
+		if !c.pluginsEnqueue(ctx, job) {
+			continue
+		}
+		queue.Push(job)
+	}
+}
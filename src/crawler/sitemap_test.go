@@ -0,0 +1,144 @@
+package crawler
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+)
+
+// newSitemapServer serves a home page with no links and a sitemap tree that
+// lists an otherwise unreachable page. When robotsSitemap is false,
+// robots.txt has no Sitemap: line and the tree starts at /sitemap.xml.
+func newSitemapServer(t *testing.T, robotsSitemap bool) *httptest.Server {
+	t.Helper()
+	var ts *httptest.Server
+	mux := http.NewServeMux()
+	mux.HandleFunc("/robots.txt", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprint(w, "User-agent: *\nDisallow: /private\n")
+		if robotsSitemap {
+			_, _ = fmt.Fprintf(w, "Sitemap: %s/maps/index.xml\n", ts.URL)
+		}
+	})
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = fmt.Fprint(w, `<p>no links</p>`)
+	})
+	index := func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprintf(w, `<?xml version="1.0" encoding="UTF-8"?>
+<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <sitemap><loc>%[1]s/maps/pages.xml</loc></sitemap>
+  <sitemap><loc>%[1]s/maps/more.xml.gz</loc></sitemap>
+</sitemapindex>`, ts.URL)
+	}
+	if robotsSitemap {
+		mux.HandleFunc("/maps/index.xml", index)
+	} else {
+		mux.HandleFunc("/sitemap.xml", index)
+	}
+	mux.HandleFunc("/maps/pages.xml", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprintf(w, `<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <url><loc>%[1]s/orphan</loc></url>
+  <url><loc>%[1]s/private/page</loc></url>
+  <url><loc>https://elsewhere.example/page</loc></url>
+</urlset>`, ts.URL)
+	})
+	mux.HandleFunc("/maps/more.xml.gz", func(w http.ResponseWriter, r *http.Request) {
+		var buf bytes.Buffer
+		gz := gzip.NewWriter(&buf)
+		_, _ = fmt.Fprintf(gz, `<urlset><url><loc>%s/gone</loc></url></urlset>`, ts.URL)
+		_ = gz.Close()
+		_, _ = w.Write(buf.Bytes())
+	})
+	mux.HandleFunc("/orphan", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprint(w, `<p>only in the sitemap</p>`)
+	})
+	ts = httptest.NewServer(mux)
+	return ts
+}
+
+func TestRun_SitemapSeedsPages(t *testing.T) {
+	for _, robotsSitemap := range []bool{true, false} {
+		t.Run(fmt.Sprintf("robots_sitemap=%v", robotsSitemap), func(t *testing.T) {
+			ts := newSitemapServer(t, robotsSitemap)
+			defer ts.Close()
+
+			c, err := New(Config{StartURL: ts.URL, Delay: 1, Sitemap: true}, nil)
+			if err != nil {
+				t.Fatalf("New() error: %v", err)
+			}
+			res, err := c.Run(context.Background())
+			if err != nil {
+				t.Fatalf("Run() error: %v", err)
+			}
+
+			// Home, /orphan and /gone; /private is robots-blocked, elsewhere.example is off-site
+			if res.Stats.TotalChecked != 3 {
+				t.Errorf("TotalChecked = %d, want 3", res.Stats.TotalChecked)
+			}
+			if len(res.BrokenLinks) != 1 || res.BrokenLinks[0].URL != ts.URL+"/gone" {
+				t.Fatalf("BrokenLinks = %+v, want only /gone", res.BrokenLinks)
+			}
+			if got := res.BrokenLinks[0].SourcePage; got != ts.URL+"/maps/more.xml.gz" {
+				t.Errorf("SourcePage = %q, want the listing sitemap", got)
+			}
+		})
+	}
+}
+
+func TestRun_SitemapDisabledByDefault(t *testing.T) {
+	ts := newSitemapServer(t, true)
+	defer ts.Close()
+
+	c, err := New(Config{StartURL: ts.URL, Delay: 1}, nil)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	res, err := c.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run() error: %v", err)
+	}
+	if res.Stats.TotalChecked != 1 {
+		t.Errorf("TotalChecked = %d, want only the start page", res.Stats.TotalChecked)
+	}
+}
+
+func TestLoadSitemaps_SkipsBrokenSitemaps(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/good.xml":
+			_, _ = fmt.Fprint(w, `<urlset><url><loc>http://a.example/1</loc></url><url><loc>http://a.example/1</loc></url></urlset>`)
+		case "/html.xml":
+			_, _ = fmt.Fprint(w, `<html><body>not a sitemap</body></html>`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	logger, rec := newRecordingLogger()
+	c, err := New(Config{StartURL: ts.URL, Logger: logger}, nil)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	defer func() { _ = c.visited.Close() }()
+
+	pages := c.loadSitemaps(context.Background(), []string{ts.URL + "/missing.xml", ts.URL + "/html.xml", ts.URL + "/good.xml"}, "")
+	var urls []string
+	for _, page := range pages {
+		urls = append(urls, page.URL)
+	}
+	if !slices.Equal(urls, []string{"http://a.example/1"}) {
+		t.Errorf("pages = %v, want one deduplicated page", urls)
+	}
+	if got := len(rec.records(t, "skipping sitemap")); got != 2 {
+		t.Errorf("got %d skipped sitemap records, want 2", got)
+	}
+}
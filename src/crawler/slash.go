@@ -0,0 +1,159 @@
+package crawler
+
+import (
+	"cmp"
+	"context"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/lukemcguire/zombiecrawl/result"
+)
+
+// slashProbe is the response to one form of a URL, requested without
+// following redirects.
+type slashProbe struct {
+	status   int    // 0 if the request failed
+	location string // Absolute redirect target, for 3xx responses
+}
+
+// works reports whether the probe got a successful response.
+func (p slashProbe) works() bool {
+	return p.status >= 200 && p.status < 300
+}
+
+// redirectsTo reports whether the probe got a redirect to target.
+func (p slashProbe) redirectsTo(target string) bool {
+	return isRedirect(p.status) && p.location == target
+}
+
+// checkTrailingSlash requests every page in pages with and without a
+// trailing slash, since URL normalization treats the two as the same. It
+// returns a warning for each page where only one form works, and for each
+// page served at both without a redirect from one to the other. Pages where
+// both forms fail are left to the crawl's broken link report. If ctx is
+// cancelled, unprobed pages are skipped.
+func (c *Crawler) checkTrailingSlash(ctx context.Context, pages []CrawlJob) []result.HygieneWarning {
+	warnings := make([]*result.HygieneWarning, len(pages))
+	slots := make(chan struct{}, c.cfg.Concurrency)
+	var wg sync.WaitGroup
+	for i, page := range pages {
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		wg.Go(func() {
+			defer func() { <-slots }()
+			slashed := slashedURL(page.URL)
+			var bare, withSlash slashProbe
+			for _, probe := range []struct {
+				rawURL string
+				into   *slashProbe
+			}{{page.URL, &bare}, {slashed, &withSlash}} {
+				if c.limiter.Wait(ctx) != nil {
+					return
+				}
+				job := page
+				job.URL = probe.rawURL
+				*probe.into = c.probeNoRedirect(ctx, job)
+			}
+			if kind, ok := slashVerdict(page.URL, bare, slashed, withSlash); ok {
+				warnings[i] = &result.HygieneWarning{
+					Kind:       kind,
+					URL:        page.URL,
+					Target:     slashed,
+					SourcePage: page.SourcePage,
+				}
+			}
+		})
+	}
+	wg.Wait()
+
+	var found []result.HygieneWarning
+	for _, warning := range warnings {
+		if warning != nil {
+			found = append(found, *warning)
+		}
+	}
+	return found
+}
+
+// slashVerdict compares the responses to a URL without (bare) and with
+// (slashed) a trailing slash. A redirect from one form to the other is
+// consistent; so is a failure of both, or a probe that got no response.
+func slashVerdict(bareURL string, bare slashProbe, slashedURL string, slashed slashProbe) (result.HygieneKind, bool) {
+	switch {
+	case bare.status == 0 || slashed.status == 0:
+		return "", false
+	case bare.redirectsTo(slashedURL) || slashed.redirectsTo(bareURL):
+		return "", false
+	case bare.works() && slashed.works():
+		return result.HygieneSlashDuplicate, true
+	case bare.works() != slashed.works():
+		return result.HygieneSlashMismatch, true
+	default:
+		return "", false
+	}
+}
+
+// probeNoRedirect requests job.URL with HEAD, falling back to GET if HEAD is
+// not allowed, and returns the first response without following redirects.
+func (c *Crawler) probeNoRedirect(ctx context.Context, job CrawlJob) slashProbe {
+	ctx, cancel := context.WithTimeout(ctx, c.cfg.RequestTimeout)
+	defer cancel()
+	client := &http.Client{
+		Transport: c.cfg.HAR.wrap(cmp.Or(c.client.Transport, c.cfg.Transport), c.cfg.timingClock()),
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	var probe slashProbe
+	for _, method := range []string{http.MethodHead, http.MethodGet} {
+		req, err := newRequest(ctx, method, job, c.cfg)
+		if err != nil {
+			return slashProbe{}
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return slashProbe{}
+		}
+		_ = resp.Body.Close()
+		probe = slashProbe{status: resp.StatusCode}
+		if location, err := resp.Location(); err == nil {
+			probe.location = location.String()
+		}
+		if resp.StatusCode != http.StatusMethodNotAllowed {
+			break
+		}
+	}
+	return probe
+}
+
+// probesTrailingSlash reports whether rawURL is worth probing with and
+// without a trailing slash: its path is not the root and its last segment
+// does not look like a file name.
+func probesTrailingSlash(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Path == "" || u.Path == "/" || strings.HasSuffix(u.Path, "/") {
+		return false
+	}
+	return !strings.Contains(path.Base(u.Path), ".")
+}
+
+// slashedURL returns rawURL with a trailing slash added to its path.
+func slashedURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	u.Path += "/"
+	if u.RawPath != "" {
+		u.RawPath += "/"
+	}
+	return u.String()
+}
@@ -0,0 +1,116 @@
+package crawler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/lukemcguire/zombiecrawl/result"
+)
+
+func TestRun_CheckTrailingSlash(t *testing.T) {
+	var ts *httptest.Server
+	ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/":
+			_, _ = fmt.Fprint(w, `<a href="/redirects/">r</a><a href="/either">e</a><a href="/docs/">d</a><a href="/file.txt">f</a><a href="/gone">g</a>`)
+		case "/redirects":
+			http.Redirect(w, r, ts.URL+"/redirects/", http.StatusMovedPermanently)
+		case "/redirects/", "/either", "/either/", "/docs/", "/file.txt":
+			_, _ = fmt.Fprint(w, `<html></html>`)
+		default:
+			// "/docs" is reported broken although the page links "/docs/"
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	for _, enabled := range []bool{false, true} {
+		cfg := DefaultConfig(ts.URL)
+		cfg.Delay = 1
+		cfg.IgnoreRobots = true
+		cfg.CheckTrailingSlash = enabled
+		c, err := New(cfg, nil)
+		if err != nil {
+			t.Fatalf("New() error: %v", err)
+		}
+		res, err := c.Run(context.Background())
+		if err != nil {
+			t.Fatalf("Run() error: %v", err)
+		}
+		if !enabled {
+			if len(res.Hygiene) != 0 {
+				t.Errorf("disabled: Hygiene = %+v, want none", res.Hygiene)
+			}
+			continue
+		}
+		got := map[string]result.HygieneKind{}
+		for _, warning := range res.Hygiene {
+			if warning.Target != slashedURL(warning.URL) || warning.SourcePage != ts.URL+"/" {
+				t.Errorf("unexpected warning %+v", warning)
+			}
+			got[warning.URL] = warning.Kind
+		}
+		want := map[string]result.HygieneKind{
+			ts.URL + "/either": result.HygieneSlashDuplicate,
+			ts.URL + "/docs":   result.HygieneSlashMismatch,
+		}
+		if len(got) != len(want) || got[ts.URL+"/either"] != want[ts.URL+"/either"] || got[ts.URL+"/docs"] != want[ts.URL+"/docs"] {
+			t.Errorf("warnings = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestSlashVerdict(t *testing.T) {
+	const bareURL, slashed = "http://example.com/a", "http://example.com/a/"
+	ok := slashProbe{status: 200}
+	missing := slashProbe{status: 404}
+	tests := []struct {
+		name          string
+		bare, withEnd slashProbe
+		want          result.HygieneKind
+	}{
+		{"bare redirects", slashProbe{status: 301, location: slashed}, ok, ""},
+		{"slash redirects", ok, slashProbe{status: 308, location: bareURL}, ""},
+		{"both served", ok, ok, result.HygieneSlashDuplicate},
+		{"only bare", ok, missing, result.HygieneSlashMismatch},
+		{"only slash", missing, ok, result.HygieneSlashMismatch},
+		{"redirect elsewhere", slashProbe{status: 302, location: "http://example.com/login"}, ok, result.HygieneSlashMismatch},
+		{"both missing", missing, missing, ""},
+		{"no response", slashProbe{}, ok, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _ := slashVerdict(bareURL, tt.bare, slashed, tt.withEnd)
+			if got != tt.want {
+				t.Errorf("slashVerdict() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProbesTrailingSlash(t *testing.T) {
+	var probed []string
+	for _, rawURL := range []string{
+		"http://example.com",
+		"http://example.com/",
+		"http://example.com/docs",
+		"http://example.com/docs/intro?lang=en",
+		"http://example.com/report.pdf",
+		"http://example.com/docs/",
+	} {
+		if probesTrailingSlash(rawURL) {
+			probed = append(probed, rawURL)
+		}
+	}
+	want := []string{"http://example.com/docs", "http://example.com/docs/intro?lang=en"}
+	if !slices.Equal(probed, want) {
+		t.Errorf("probed %v, want %v", probed, want)
+	}
+	if got := slashedURL("http://example.com/docs/intro?lang=en"); got != "http://example.com/docs/intro/?lang=en" {
+		t.Errorf("slashedURL() = %q", got)
+	}
+}
@@ -0,0 +1,66 @@
+package crawler
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/lukemcguire/zombiecrawl/result"
+	"github.com/lukemcguire/zombiecrawl/urlutil"
+)
+
+// checkSocial requests the social preview URLs of pages, such as og:image,
+// once the crawl has finished, and reports each one that does not work. URLs
+// of pages crawled successfully are known to work and are not requested
+// again. If ctx is cancelled, unrequested URLs are skipped.
+func (c *Crawler) checkSocial(ctx context.Context, pages []seoPage, startHost string) []result.SEOIssue {
+	pages = slices.Clone(pages)
+	slices.SortFunc(pages, func(a, b seoPage) int { return cmp.Compare(a.url, b.url) })
+
+	crawled := make(map[string]bool, len(pages))
+	for _, page := range pages {
+		crawled[page.url] = true
+	}
+	var jobs []CrawlJob
+	for _, page := range pages {
+		for _, social := range page.meta.Social {
+			if crawled[social.URL] || slices.ContainsFunc(jobs, func(job CrawlJob) bool { return job.URL == social.URL }) {
+				continue
+			}
+			jobs = append(jobs, CrawlJob{
+				URL:        social.URL,
+				SourcePage: page.url,
+				IsExternal: !urlutil.IsSameDomain(social.URL, startHost),
+				UserAgent:  c.userAgents.For(social.URL),
+			})
+		}
+	}
+	problems := make([]string, len(jobs))
+	c.runProbes(ctx, jobs, func(i int, job CrawlJob) {
+		res := CheckURL(ctx, c.client, job, c.cfg)
+		if ctx.Err() == nil && res.Result != nil {
+			problems[i] = cmp.Or(res.Result.Error, fmt.Sprintf("status %d", res.Result.StatusCode))
+		}
+	})
+	broken := make(map[string]string)
+	for i, problem := range problems {
+		if problem != "" {
+			broken[jobs[i].URL] = problem
+		}
+	}
+
+	var issues []result.SEOIssue
+	for _, page := range pages {
+		for _, social := range page.meta.Social {
+			if problem, ok := broken[social.URL]; ok {
+				issues = append(issues, result.SEOIssue{
+					Kind:   result.SEOSocialBroken,
+					URL:    page.url,
+					Detail: fmt.Sprintf("%s -> %s: %s", social.Property, social.URL, problem),
+				})
+			}
+		}
+	}
+	return issues
+}
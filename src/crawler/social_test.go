@@ -0,0 +1,63 @@
+package crawler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/lukemcguire/zombiecrawl/result"
+)
+
+func TestRun_SocialPreviews(t *testing.T) {
+	var mu sync.Mutex
+	requests := make(map[string]int)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests[r.URL.Path]++
+		mu.Unlock()
+		switch r.URL.Path {
+		case "/":
+			_, _ = fmt.Fprint(w, `<title>Home</title><meta property="og:image" content="/card.png"><meta property="og:url" content="/">
+				<a href="/post">post</a>`)
+		case "/post":
+			_, _ = fmt.Fprint(w, `<title>Post</title><meta property="og:image" content="/card.png"><meta name="twitter:image" content="/old-card.png">
+				<meta property="og:url" content="/moved">`)
+		case "/card.png":
+			w.Header().Set("Content-Type", "image/png")
+			_, _ = w.Write([]byte("\x89PNG"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	cfg := DefaultConfig(ts.URL)
+	cfg.Delay = 1
+	cfg.SEO = true
+	c, err := New(cfg, nil)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	res, err := c.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run() error: %v", err)
+	}
+
+	post := ts.URL + "/post"
+	want := []result.SEOIssue{
+		{Kind: result.SEOSocialBroken, URL: post, Detail: "twitter:image -> " + ts.URL + "/old-card.png: status 404"},
+		{Kind: result.SEOSocialBroken, URL: post, Detail: "og:url -> " + ts.URL + "/moved: status 404"},
+	}
+	if !slices.Equal(res.SEO, want) {
+		t.Errorf("SEO = %+v, want %+v", res.SEO, want)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if requests["/card.png"] != 1 || requests["/"] != 1 {
+		t.Errorf("requests = %v, want the shared image requested once and the crawled page not again", requests)
+	}
+}
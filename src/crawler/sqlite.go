@@ -0,0 +1,204 @@
+package crawler
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	_ "modernc.org/sqlite" // Pure-Go driver registered as "sqlite"
+
+	"github.com/lukemcguire/zombiecrawl/result"
+)
+
+// sqliteSchema creates the tables SQLiteStore uses, if they do not exist.
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS visited (url TEXT PRIMARY KEY) WITHOUT ROWID;
+CREATE TABLE IF NOT EXISTS results (id INTEGER PRIMARY KEY, url TEXT NOT NULL, link TEXT NOT NULL);
+CREATE TABLE IF NOT EXISTS response_cache (
+	url TEXT PRIMARY KEY,
+	checked_at INTEGER NOT NULL,
+	entry TEXT NOT NULL
+) WITHOUT ROWID;
+`
+
+// SQLiteStore is a VisitedStore, ResultSink, and ResponseCache kept in one
+// SQLite database file. Unlike VisitedTracker it is exact and outlives the
+// process: the visited URLs, broken links, and cache entries one crawl
+// writes are there for the next, so a crawl using a store that already has
+// visited URLs skips them. Several processes may share the file. Writes go
+// straight to the database.
+type SQLiteStore struct {
+	db     *sql.DB
+	maxAge time.Duration
+	now    func() time.Time
+
+	mu  sync.Mutex
+	err error // First error from a method with no error result
+}
+
+// Compile-time interface checks.
+var (
+	_ VisitedStore  = (*SQLiteStore)(nil)
+	_ ResultSink    = (*SQLiteStore)(nil)
+	_ ResponseCache = (*SQLiteStore)(nil)
+)
+
+// OpenSQLiteStore opens the SQLite database at path, creating the file and
+// its tables if needed.
+func OpenSQLiteStore(path string) (*SQLiteStore, error) {
+	db, err := sql.Open("sqlite", path+"?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)&_pragma=synchronous(NORMAL)")
+	if err != nil {
+		return nil, fmt.Errorf("open sqlite store: %w", err)
+	}
+	// One connection serializes writers within the process; busy_timeout
+	// covers other processes sharing the file
+	db.SetMaxOpenConns(1)
+	if _, err := db.Exec(sqliteSchema); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("create sqlite store %s: %w", path, err)
+	}
+	return &SQLiteStore{db: db, now: time.Now}, nil
+}
+
+// SetCacheMaxAge drops cache entries older than maxAge on Flush. Zero, the
+// default, keeps every entry.
+func (s *SQLiteStore) SetCacheMaxAge(maxAge time.Duration) {
+	s.maxAge = maxAge
+}
+
+// Visit marks a URL as visited.
+func (s *SQLiteStore) Visit(url string) {
+	if _, err := s.db.Exec(`INSERT OR IGNORE INTO visited (url) VALUES (?)`, url); err != nil {
+		s.setErr(fmt.Errorf("record visited url: %w", err))
+	}
+}
+
+// VisitIfNew marks a URL as visited and reports whether it was new. If the
+// database cannot be written the URL is reported as new, so it is checked
+// rather than lost; Close returns the error.
+func (s *SQLiteStore) VisitIfNew(url string) bool {
+	res, err := s.db.Exec(`INSERT OR IGNORE INTO visited (url) VALUES (?)`, url)
+	var added int64
+	if err == nil {
+		added, err = res.RowsAffected()
+	}
+	if err != nil {
+		s.setErr(fmt.Errorf("record visited url: %w", err))
+		return true
+	}
+	return added == 1
+}
+
+// Add records a broken link.
+func (s *SQLiteStore) Add(link result.LinkResult) error {
+	data, err := json.Marshal(link)
+	if err != nil {
+		return fmt.Errorf("encode result: %w", err)
+	}
+	if _, err := s.db.Exec(`INSERT INTO results (url, link) VALUES (?, ?)`, link.URL, data); err != nil {
+		return fmt.Errorf("write result: %w", err)
+	}
+	return nil
+}
+
+// Links returns the broken links added to the store, oldest first.
+func (s *SQLiteStore) Links() ([]result.LinkResult, error) {
+	rows, err := s.db.Query(`SELECT link FROM results ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("read results: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var links []result.LinkResult
+	for rows.Next() {
+		var data []byte
+		if err := rows.Scan(&data); err != nil {
+			return nil, fmt.Errorf("read results: %w", err)
+		}
+		var link result.LinkResult
+		if err := json.Unmarshal(data, &link); err != nil {
+			return nil, fmt.Errorf("decode result: %w", err)
+		}
+		links = append(links, link)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("read results: %w", err)
+	}
+	return links, nil
+}
+
+// Get returns the stored entry for url. An entry that cannot be read is
+// reported as missing; Flush returns the error.
+func (s *SQLiteStore) Get(url string) (CacheEntry, bool) {
+	var data []byte
+	err := s.db.QueryRow(`SELECT entry FROM response_cache WHERE url = ?`, url).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return CacheEntry{}, false
+	}
+	if err != nil {
+		s.setErr(fmt.Errorf("read cache entry: %w", err))
+		return CacheEntry{}, false
+	}
+	var entry CacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		s.setErr(fmt.Errorf("decode cache entry for %s: %w", url, err))
+		return CacheEntry{}, false
+	}
+	return entry, true
+}
+
+// Put stores the entry for url, replacing any previous one.
+func (s *SQLiteStore) Put(url string, entry CacheEntry) {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		s.setErr(fmt.Errorf("encode cache entry: %w", err))
+		return
+	}
+	if _, err := s.db.Exec(`INSERT OR REPLACE INTO response_cache (url, checked_at, entry) VALUES (?, ?, ?)`,
+		url, entry.CheckedAt.UnixNano(), data); err != nil {
+		s.setErr(fmt.Errorf("write cache entry: %w", err))
+	}
+}
+
+// Flush drops cache entries older than the SetCacheMaxAge limit and returns
+// the first error Visit, Get, or Put met since the last Flush.
+func (s *SQLiteStore) Flush() error {
+	if s.maxAge > 0 {
+		cutoff := s.now().Add(-s.maxAge).UnixNano()
+		if _, err := s.db.Exec(`DELETE FROM response_cache WHERE checked_at <= ?`, cutoff); err != nil {
+			return fmt.Errorf("expire cache entries: %w", err)
+		}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	err := s.err
+	s.err = nil
+	return err
+}
+
+// Close closes the database, returning any error Flush has not reported.
+func (s *SQLiteStore) Close() error {
+	s.mu.Lock()
+	err := s.err
+	s.err = nil
+	s.mu.Unlock()
+	if closeErr := s.db.Close(); closeErr != nil {
+		err = errors.Join(err, closeErr)
+	}
+	if err != nil {
+		return fmt.Errorf("close sqlite store: %w", err)
+	}
+	return nil
+}
+
+// setErr records err if no earlier error is waiting to be returned.
+func (s *SQLiteStore) setErr(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err == nil {
+		s.err = err
+	}
+}
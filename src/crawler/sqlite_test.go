@@ -0,0 +1,142 @@
+package crawler
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/lukemcguire/zombiecrawl/result"
+)
+
+func TestSQLiteStore_Visited(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "crawl.db")
+	s, err := OpenSQLiteStore(path)
+	if err != nil {
+		t.Fatalf("OpenSQLiteStore() error: %v", err)
+	}
+	if !s.VisitIfNew("http://example.com/a") {
+		t.Error("first VisitIfNew should report a new URL")
+	}
+	if s.VisitIfNew("http://example.com/a") {
+		t.Error("second VisitIfNew should report a visited URL")
+	}
+	s.Visit("http://example.com/b")
+	if err := s.Close(); err != nil {
+		t.Fatalf("Close() error: %v", err)
+	}
+
+	// Visited URLs outlive the store
+	s, err = OpenSQLiteStore(path)
+	if err != nil {
+		t.Fatalf("reopen error: %v", err)
+	}
+	defer func() { _ = s.Close() }()
+	if s.VisitIfNew("http://example.com/b") {
+		t.Error("reopened store forgot a visited URL")
+	}
+}
+
+func TestSQLiteStore_Results(t *testing.T) {
+	s, err := OpenSQLiteStore(filepath.Join(t.TempDir(), "crawl.db"))
+	if err != nil {
+		t.Fatalf("OpenSQLiteStore() error: %v", err)
+	}
+	defer func() { _ = s.Close() }()
+
+	for _, link := range []result.LinkResult{
+		{URL: "http://example.com/b", StatusCode: 500},
+		{URL: "http://example.com/a", StatusCode: 404, SourcePage: "http://example.com/"},
+	} {
+		if err := s.Add(link); err != nil {
+			t.Fatalf("Add() error: %v", err)
+		}
+	}
+	links, err := s.Links()
+	if err != nil {
+		t.Fatalf("Links() error: %v", err)
+	}
+	if len(links) != 2 || links[0].URL != "http://example.com/b" || links[1].SourcePage != "http://example.com/" {
+		t.Errorf("Links() = %+v, want both links in the order added", links)
+	}
+}
+
+func TestSQLiteStore_Cache(t *testing.T) {
+	s, err := OpenSQLiteStore(filepath.Join(t.TempDir(), "crawl.db"))
+	if err != nil {
+		t.Fatalf("OpenSQLiteStore() error: %v", err)
+	}
+	defer func() { _ = s.Close() }()
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+	s.SetCacheMaxAge(time.Hour)
+
+	s.Put("http://old.example/", CacheEntry{StatusCode: 200, CheckedAt: now.Add(-2 * time.Hour)})
+	s.Put("http://new.example/", CacheEntry{StatusCode: 200, CheckedAt: now.Add(-time.Minute), Links: []string{"a"}})
+	s.Put("http://new.example/", CacheEntry{StatusCode: 304, CheckedAt: now, Links: []string{"b"}})
+
+	if entry, ok := s.Get("http://new.example/"); !ok || entry.StatusCode != 304 || len(entry.Links) != 1 || entry.Links[0] != "b" {
+		t.Errorf("Get() = %+v, %v, want the replacing entry", entry, ok)
+	}
+	if _, ok := s.Get("http://missing.example/"); ok {
+		t.Error("Get() found an entry never stored")
+	}
+	if err := s.Flush(); err != nil {
+		t.Fatalf("Flush() error: %v", err)
+	}
+	if _, ok := s.Get("http://old.example/"); ok {
+		t.Error("Flush() kept an entry older than the max age")
+	}
+	if _, ok := s.Get("http://new.example/"); !ok {
+		t.Error("Flush() dropped a fresh entry")
+	}
+}
+
+func TestSQLiteStore_NotADatabase(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "crawl.db")
+	if err := os.WriteFile(path, []byte("not a database, just some text that is long enough"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if s, err := OpenSQLiteStore(path); err == nil {
+		_ = s.Close()
+		t.Error("OpenSQLiteStore() accepted a file that is not a database")
+	}
+}
+
+func TestRun_SQLiteStore(t *testing.T) {
+	ts := newFlakyServer()
+	defer ts.Close()
+
+	store, err := OpenSQLiteStore(filepath.Join(t.TempDir(), "crawl.db"))
+	if err != nil {
+		t.Fatalf("OpenSQLiteStore() error: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	c, err := New(Config{
+		StartURL:    ts.URL,
+		Delay:       1,
+		RetryPolicy: RetryPolicy{MaxRetries: 0},
+		Visited:     store,
+		Results:     store,
+	}, nil)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	res, err := c.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run() error: %v", err)
+	}
+
+	links, err := store.Links()
+	if err != nil {
+		t.Fatalf("Links() error: %v", err)
+	}
+	if len(links) != len(res.BrokenLinks) || len(links) == 0 {
+		t.Errorf("store has %d links, want the %d broken links found", len(links), len(res.BrokenLinks))
+	}
+	if store.VisitIfNew(ts.URL + "/dead") {
+		t.Error("crawl should have recorded /dead in the store")
+	}
+}
@@ -0,0 +1,108 @@
+package crawler
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/lukemcguire/zombiecrawl/result"
+)
+
+// StateRecorder captures the crawl queue when Run stops dispatching, whether
+// the crawl finished or was cancelled, so it can be saved for inspection.
+// Jobs already handed to workers are not in the queue and are not recorded.
+// A nil *StateRecorder records nothing. It is safe for concurrent use.
+type StateRecorder struct {
+	mu    sync.Mutex
+	state *result.CrawlState
+}
+
+// NewStateRecorder creates an empty recorder.
+func NewStateRecorder() *StateRecorder {
+	return &StateRecorder{}
+}
+
+// State returns the recorded state, or nil if no crawl has stopped yet.
+func (r *StateRecorder) State() *result.CrawlState {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.state
+}
+
+// Write encodes the recorded state as JSON.
+func (r *StateRecorder) Write(w io.Writer) error {
+	state := r.State()
+	if state == nil {
+		return fmt.Errorf("write crawl state: no crawl has stopped")
+	}
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(state); err != nil {
+		return fmt.Errorf("write crawl state: %w", err)
+	}
+	return nil
+}
+
+// WriteFile writes the recorded state to path as JSON. The file is left
+// untouched if no crawl has stopped.
+func (r *StateRecorder) WriteFile(path string) error {
+	if r.State() == nil {
+		return fmt.Errorf("write crawl state: no crawl has stopped")
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("create state file: %w", err)
+	}
+	if err := r.Write(file); err != nil {
+		_ = file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("close state file: %w", err)
+	}
+	return nil
+}
+
+// record replaces the recorded state.
+func (r *StateRecorder) record(state *result.CrawlState) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.state = state
+}
+
+// recordState saves the jobs still in queue to Config.State.
+func (c *Crawler) recordState(startURL string, queue *frontier) {
+	if c.cfg.State == nil {
+		return
+	}
+	pending := queue.pending()
+	state := &result.CrawlState{
+		StartURL: startURL,
+		Strategy: string(c.cfg.Strategy),
+		SavedAt:  c.cfg.now(),
+		Visited:  int(c.visitedCount.Load()),
+		Frontier: make([]result.QueuedURL, len(pending)),
+	}
+	c.mu.Lock()
+	state.Checked = c.total
+	c.mu.Unlock()
+	for i, job := range pending {
+		state.Frontier[i] = result.QueuedURL{
+			URL:        job.URL,
+			SourcePage: job.SourcePage,
+			Depth:      job.Depth,
+			IsExternal: job.IsExternal,
+		}
+	}
+	c.cfg.Scrub.State(state)
+	c.cfg.State.record(state)
+}
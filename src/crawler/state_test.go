@@ -0,0 +1,93 @@
+package crawler
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/lukemcguire/zombiecrawl/result"
+)
+
+func TestRun_RecordsState(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/":
+			_, _ = fmt.Fprint(w, `<a href="/a">a</a><a href="/b">b</a><a href="/c">c</a>`)
+		case "/a":
+			// Stop the crawl once the first linked page is checked
+			cancel()
+			_, _ = fmt.Fprint(w, `<a href="/a/deeper">deeper</a>`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	cfg := DefaultConfig(ts.URL)
+	cfg.Concurrency = 1
+	cfg.Delay = 1
+	cfg.State = NewStateRecorder()
+	c, err := New(cfg, nil)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	if _, err := c.Run(ctx); err != nil {
+		t.Fatalf("Run() error: %v", err)
+	}
+
+	state := cfg.State.State()
+	if state == nil {
+		t.Fatal("State() = nil after Run")
+	}
+	if state.StartURL != ts.URL+"/" || state.Strategy != "bfs" || state.Visited != 4 {
+		t.Errorf("State() = %+v, want 4 URLs visited from %s/", state, ts.URL)
+	}
+	// /b and /c were still queued, apart from any dispatched before the
+	// cancellation was seen
+	for _, queued := range state.Frontier {
+		if queued.Depth != 1 || queued.SourcePage != ts.URL+"/" || (queued.URL != ts.URL+"/b" && queued.URL != ts.URL+"/c") {
+			t.Errorf("Frontier entry %+v, want /b or /c at depth 1", queued)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := cfg.State.Write(&buf); err != nil {
+		t.Fatalf("Write() error: %v", err)
+	}
+	saved, err := result.ReadState(&buf)
+	if err != nil {
+		t.Fatalf("ReadState() error: %v", err)
+	}
+	if saved.Visited != state.Visited || len(saved.Frontier) != len(state.Frontier) {
+		t.Errorf("saved state %+v does not round trip %+v", saved, state)
+	}
+}
+
+func TestStateRecorder_Nil(t *testing.T) {
+	var recorder *StateRecorder
+	recorder.record(&result.CrawlState{})
+	if recorder.State() != nil {
+		t.Error("nil recorder State() should be nil")
+	}
+	if err := NewStateRecorder().Write(&bytes.Buffer{}); err == nil {
+		t.Error("Write() before any crawl stopped should fail")
+	}
+
+	path := filepath.Join(t.TempDir(), "state.json")
+	if err := os.WriteFile(path, []byte("earlier"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := NewStateRecorder().WriteFile(path); err == nil {
+		t.Error("WriteFile() before any crawl stopped should fail")
+	}
+	if content, _ := os.ReadFile(path); string(content) != "earlier" {
+		t.Errorf("WriteFile() left %q, want the existing file untouched", content)
+	}
+}
@@ -0,0 +1,224 @@
+package crawler
+
+import (
+	"math"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lukemcguire/zombiecrawl/result"
+)
+
+// statsCollector accumulates per-result measurements for CrawlStats.
+// It is safe for concurrent use.
+type statsCollector struct {
+	mu         sync.Mutex
+	internal   int
+	external   int
+	retries    int
+	cacheHits  int
+	unchanged  int
+	bytes      int64
+	latencies  []time.Duration
+	byCategory map[result.ErrorCategory]int
+	hosts      map[string]*hostTally
+	depths     []result.DepthStats // Indexed by depth
+	brokenAt   map[[2]string]int   // Depth of each broken URL and source page, for forgive
+	inFlight   int
+	peak       int
+}
+
+// hostTally counts checks and failures for one external host.
+type hostTally struct {
+	links      int
+	broken     int
+	categories map[result.ErrorCategory]int
+}
+
+// newStatsCollector creates an empty statsCollector.
+func newStatsCollector() *statsCollector {
+	return &statsCollector{
+		byCategory: make(map[result.ErrorCategory]int),
+		hosts:      make(map[string]*hostTally),
+		brokenAt:   make(map[[2]string]int),
+	}
+}
+
+// begin marks a request as in flight and updates peak concurrency.
+func (s *statsCollector) begin() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.inFlight++
+	s.peak = max(s.peak, s.inFlight)
+}
+
+// end marks an in-flight request as finished.
+func (s *statsCollector) end() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.inFlight--
+}
+
+// record adds the measurements from a completed check.
+func (s *statsCollector) record(res CrawlResult) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if res.Job.IsExternal {
+		s.external++
+	} else {
+		s.internal++
+	}
+	if res.Cached {
+		s.cacheHits++
+	}
+	if res.NotModified {
+		s.unchanged++
+	}
+	if res.Attempts > 1 {
+		s.retries += res.Attempts - 1
+	}
+	s.bytes += res.Bytes
+	if res.Attempts > 0 {
+		s.latencies = append(s.latencies, res.Duration)
+	}
+	depth := max(res.Job.Depth, 0)
+	for len(s.depths) <= depth {
+		s.depths = append(s.depths, result.DepthStats{Depth: len(s.depths)})
+	}
+	s.depths[depth].Checked++
+	var tally *hostTally
+	if res.Job.IsExternal {
+		host := hostFromURL(res.Job.URL)
+		tally = s.hosts[host]
+		if tally == nil {
+			tally = &hostTally{categories: make(map[result.ErrorCategory]int)}
+			s.hosts[host] = tally
+		}
+		tally.links++
+	}
+	if res.Result != nil {
+		cat := res.Result.ErrorCategory
+		if cat == "" {
+			cat = result.CategoryUnknown
+		}
+		s.byCategory[cat]++
+		s.depths[depth].Broken++
+		s.brokenAt[[2]string{res.Result.URL, res.Result.SourcePage}] = depth
+		if tally != nil {
+			tally.broken++
+			tally.categories[cat]++
+		}
+	}
+	for _, link := range res.Malformed {
+		s.byCategory[link.ErrorCategory]++
+	}
+}
+
+// forgive removes a broken link from the category, depth, and host failure
+// counts after it recovered on re-verification.
+func (s *statsCollector) forgive(link result.LinkResult) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cat := link.ErrorCategory
+	if cat == "" {
+		cat = result.CategoryUnknown
+	}
+	if s.byCategory[cat]--; s.byCategory[cat] <= 0 {
+		delete(s.byCategory, cat)
+	}
+	if depth, ok := s.brokenAt[[2]string{link.URL, link.SourcePage}]; ok {
+		s.depths[depth].Broken--
+		delete(s.brokenAt, [2]string{link.URL, link.SourcePage})
+	}
+	if !link.IsExternal {
+		return
+	}
+	if tally := s.hosts[hostFromURL(link.URL)]; tally != nil {
+		tally.broken--
+		if tally.categories[cat]--; tally.categories[cat] <= 0 {
+			delete(tally.categories, cat)
+		}
+	}
+}
+
+// hostSummaries returns per-host totals for external hosts, ordered by
+// broken count (descending) and then host name.
+func (s *statsCollector) hostSummaries() []result.HostSummary {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	summaries := make([]result.HostSummary, 0, len(s.hosts))
+	for host, tally := range s.hosts {
+		summary := result.HostSummary{Host: host, Links: tally.links, Broken: tally.broken}
+		// Dominant category: most frequent, ties broken by name for stable output
+		best := 0
+		for cat, count := range tally.categories {
+			if count > best || (count == best && cat < summary.DominantCategory) {
+				best = count
+				summary.DominantCategory = cat
+			}
+		}
+		summaries = append(summaries, summary)
+	}
+	slices.SortFunc(summaries, func(a, b result.HostSummary) int {
+		if a.Broken != b.Broken {
+			return b.Broken - a.Broken
+		}
+		return strings.Compare(a.Host, b.Host)
+	})
+	return summaries
+}
+
+// fill copies the collected measurements into stats. TotalChecked and
+// Duration must already be set, since throughput is derived from them.
+func (s *statsCollector) fill(stats *result.CrawlStats) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stats.InternalChecked = s.internal
+	stats.ExternalChecked = s.external
+	stats.Retries = s.retries
+	stats.CacheHits = s.cacheHits
+	stats.NotModified = s.unchanged
+	stats.BytesDownloaded = s.bytes
+	stats.PeakConcurrency = s.peak
+	if len(s.byCategory) > 0 {
+		stats.ByCategory = make(map[result.ErrorCategory]int, len(s.byCategory))
+		for cat, count := range s.byCategory {
+			stats.ByCategory[cat] = count
+		}
+	}
+	if len(s.depths) > 0 {
+		stats.ByDepth = slices.Clone(s.depths)
+	}
+	if stats.Duration > 0 {
+		stats.PagesPerSecond = float64(stats.TotalChecked) / stats.Duration.Seconds()
+	}
+
+	if len(s.latencies) == 0 {
+		return
+	}
+	sorted := slices.Clone(s.latencies)
+	slices.Sort(sorted)
+	var total time.Duration
+	for _, latency := range sorted {
+		total += latency
+	}
+	stats.AvgLatency = total / time.Duration(len(sorted))
+	stats.P50Latency = percentile(sorted, 50)
+	stats.P95Latency = percentile(sorted, 95)
+	stats.P99Latency = percentile(sorted, 99)
+}
+
+// percentile returns the p-th percentile (nearest-rank) of sorted durations.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(float64(len(sorted))*p/100)) - 1
+	rank = min(max(rank, 0), len(sorted)-1)
+	return sorted[rank]
+}
@@ -0,0 +1,126 @@
+package crawler
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/lukemcguire/zombiecrawl/result"
+)
+
+func TestStatsCollector(t *testing.T) {
+	collector := newStatsCollector()
+
+	collector.begin()
+	collector.begin()
+	collector.end()
+	collector.end()
+
+	collector.record(CrawlResult{Job: CrawlJob{}, Attempts: 1, Bytes: 100, Duration: 10 * time.Millisecond})
+	collector.record(CrawlResult{
+		Job:      CrawlJob{IsExternal: true},
+		Attempts: 3,
+		Duration: 30 * time.Millisecond,
+		Result:   &result.LinkResult{ErrorCategory: result.Category5xx},
+	})
+	// Cancelled job with no attempts contributes counts but no latency
+	collector.record(CrawlResult{Job: CrawlJob{IsExternal: true}})
+
+	stats := result.CrawlStats{TotalChecked: 3, Duration: time.Second}
+	collector.fill(&stats)
+
+	if stats.InternalChecked != 1 || stats.ExternalChecked != 2 {
+		t.Errorf("internal/external = %d/%d, want 1/2", stats.InternalChecked, stats.ExternalChecked)
+	}
+	if stats.Retries != 2 {
+		t.Errorf("Retries = %d, want 2", stats.Retries)
+	}
+	if stats.BytesDownloaded != 100 {
+		t.Errorf("BytesDownloaded = %d, want 100", stats.BytesDownloaded)
+	}
+	if stats.AvgLatency != 20*time.Millisecond {
+		t.Errorf("AvgLatency = %v, want 20ms", stats.AvgLatency)
+	}
+	if stats.P99Latency != 30*time.Millisecond {
+		t.Errorf("P99Latency = %v, want 30ms", stats.P99Latency)
+	}
+	if stats.PeakConcurrency != 2 {
+		t.Errorf("PeakConcurrency = %d, want 2", stats.PeakConcurrency)
+	}
+	if stats.ByCategory[result.Category5xx] != 1 {
+		t.Errorf("ByCategory[5xx] = %d, want 1", stats.ByCategory[result.Category5xx])
+	}
+	if stats.PagesPerSecond != 3 {
+		t.Errorf("PagesPerSecond = %v, want 3", stats.PagesPerSecond)
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	sorted := []time.Duration{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+	tests := []struct {
+		p    float64
+		want time.Duration
+	}{
+		{50, 5},
+		{95, 10},
+		{99, 10},
+		{10, 1},
+	}
+	for _, tt := range tests {
+		if got := percentile(sorted, tt.p); got != tt.want {
+			t.Errorf("percentile(p%v) = %v, want %v", tt.p, got, tt.want)
+		}
+	}
+	if got := percentile(nil, 50); got != 0 {
+		t.Errorf("percentile(nil) = %v, want 0", got)
+	}
+}
+
+func TestStatsCollector_HostSummaries(t *testing.T) {
+	collector := newStatsCollector()
+	broken := func(cat result.ErrorCategory) *result.LinkResult { return &result.LinkResult{ErrorCategory: cat} }
+
+	collector.record(CrawlResult{Job: CrawlJob{URL: "https://cdn.old.com/a", IsExternal: true}, Result: broken(result.CategoryTimeout)})
+	collector.record(CrawlResult{Job: CrawlJob{URL: "https://cdn.old.com/b", IsExternal: true}, Result: broken(result.CategoryTimeout)})
+	collector.record(CrawlResult{Job: CrawlJob{URL: "https://cdn.old.com/c", IsExternal: true}, Result: broken(result.Category4xx)})
+	collector.record(CrawlResult{Job: CrawlJob{URL: "https://ok.com/", IsExternal: true}})
+	collector.record(CrawlResult{Job: CrawlJob{URL: "https://self.com/page"}, Result: broken(result.Category4xx)})
+
+	hosts := collector.hostSummaries()
+	if len(hosts) != 2 {
+		t.Fatalf("expected 2 external hosts, got %d: %+v", len(hosts), hosts)
+	}
+	if hosts[0].Host != "cdn.old.com" || hosts[0].Links != 3 || hosts[0].Broken != 3 {
+		t.Errorf("unexpected first host summary: %+v", hosts[0])
+	}
+	if hosts[0].DominantCategory != result.CategoryTimeout {
+		t.Errorf("DominantCategory = %v, want %v", hosts[0].DominantCategory, result.CategoryTimeout)
+	}
+	if hosts[1].Host != "ok.com" || hosts[1].Broken != 0 || hosts[1].DominantCategory != "" {
+		t.Errorf("unexpected second host summary: %+v", hosts[1])
+	}
+}
+
+func TestStatsCollector_ByDepth(t *testing.T) {
+	collector := newStatsCollector()
+	gone := &result.LinkResult{URL: "https://example.com/gone", SourcePage: "https://example.com/a", ErrorCategory: result.Category4xx}
+
+	collector.record(CrawlResult{Job: CrawlJob{URL: "https://example.com/"}})
+	collector.record(CrawlResult{Job: CrawlJob{URL: "https://example.com/a", Depth: 1}})
+	collector.record(CrawlResult{Job: CrawlJob{URL: "https://cdn.example/x", IsExternal: true, Depth: 1}})
+	collector.record(CrawlResult{Job: CrawlJob{URL: gone.URL, SourcePage: gone.SourcePage, Depth: 2}, Result: gone})
+
+	var stats result.CrawlStats
+	collector.fill(&stats)
+	want := []result.DepthStats{{Depth: 0, Checked: 1}, {Depth: 1, Checked: 2}, {Depth: 2, Checked: 1, Broken: 1}}
+	if !slices.Equal(stats.ByDepth, want) {
+		t.Errorf("ByDepth = %+v, want %+v", stats.ByDepth, want)
+	}
+
+	// A link that recovers on re-verification is no longer broken at its depth
+	collector.forgive(*gone)
+	collector.fill(&stats)
+	if stats.ByDepth[2].Broken != 0 || stats.ByDepth[2].Checked != 1 {
+		t.Errorf("after forgive, depth 2 = %+v, want 1 checked, 0 broken", stats.ByDepth[2])
+	}
+}
@@ -0,0 +1,202 @@
+package crawler
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"sync"
+
+	"github.com/lukemcguire/zombiecrawl/result"
+)
+
+// VisitedStore records which URLs a crawl has already queued, so each URL is
+// checked once. VisitedTracker (disk-backed bloom filter) is the default;
+// MemoryVisitedStore is exact but grows with the crawl, and SQLiteStore is
+// exact and kept in a database file. Implementations must be safe for
+// concurrent use; shared backends such as Redis let several crawlers split
+// one site.
+type VisitedStore interface {
+	// Visit marks a URL as visited.
+	Visit(url string)
+	// VisitIfNew atomically marks a URL as visited and reports whether it
+	// was new.
+	VisitIfNew(url string) bool
+	// Close releases the store's resources.
+	Close() error
+}
+
+// ResultSink receives each broken link as soon as the crawl finds it, before
+// any --verify pass. Implementations must be safe for concurrent use.
+type ResultSink interface {
+	Add(link result.LinkResult) error
+}
+
+// ResponseCache stores external link verdicts by URL for ExternalCache.
+// Implementations must be safe for concurrent use.
+type ResponseCache interface {
+	// Get returns the stored entry for url, if any.
+	Get(url string) (CacheEntry, bool)
+	// Put stores the entry for url, replacing any previous one.
+	Put(url string, entry CacheEntry)
+	// Flush persists pending writes. Write-through stores return nil.
+	Flush() error
+}
+
+// Compile-time interface checks.
+var (
+	_ VisitedStore  = (*VisitedTracker)(nil)
+	_ VisitedStore  = (*MemoryVisitedStore)(nil)
+	_ ResultSink    = (*MemoryResultSink)(nil)
+	_ ResultSink    = (*JSONResultSink)(nil)
+	_ ResultSink    = (*CSVResultSink)(nil)
+	_ ResponseCache = (*MemoryResponseCache)(nil)
+	_ ResponseCache = (*FileResponseCache)(nil)
+)
+
+// MemoryVisitedStore is an exact, in-memory VisitedStore.
+type MemoryVisitedStore struct {
+	mu   sync.Mutex
+	urls map[string]struct{}
+}
+
+// NewMemoryVisitedStore creates an empty in-memory visited store.
+func NewMemoryVisitedStore() *MemoryVisitedStore {
+	return &MemoryVisitedStore{urls: make(map[string]struct{})}
+}
+
+// Visit marks a URL as visited.
+func (s *MemoryVisitedStore) Visit(url string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.urls[url] = struct{}{}
+}
+
+// VisitIfNew marks a URL as visited and reports whether it was new.
+func (s *MemoryVisitedStore) VisitIfNew(url string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.urls[url]; ok {
+		return false
+	}
+	s.urls[url] = struct{}{}
+	return true
+}
+
+// Close implements VisitedStore; there is nothing to release.
+func (s *MemoryVisitedStore) Close() error {
+	return nil
+}
+
+// MemoryResultSink collects broken links in memory.
+type MemoryResultSink struct {
+	mu    sync.Mutex
+	links []result.LinkResult
+}
+
+// Add records a broken link.
+func (s *MemoryResultSink) Add(link result.LinkResult) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.links = append(s.links, link)
+	return nil
+}
+
+// Links returns the links added so far.
+func (s *MemoryResultSink) Links() []result.LinkResult {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.links)
+}
+
+// JSONResultSink writes each broken link to w as one line of JSON, so results
+// survive a crash mid-crawl.
+type JSONResultSink struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+// NewJSONResultSink creates a sink writing JSON lines to w.
+func NewJSONResultSink(w io.Writer) *JSONResultSink {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	return &JSONResultSink{enc: enc}
+}
+
+// Add writes a broken link.
+func (s *JSONResultSink) Add(link result.LinkResult) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.enc.Encode(link); err != nil {
+		return fmt.Errorf("write result: %w", err)
+	}
+	return nil
+}
+
+// CSVResultSink writes each broken link to w as a CSV row in the format of
+// result.WriteCSV, flushing every row so results survive a crash mid-crawl.
+type CSVResultSink struct {
+	mu sync.Mutex
+	cw *csv.Writer
+}
+
+// NewCSVResultSink writes the CSV header to w and returns a sink appending
+// rows to it.
+func NewCSVResultSink(w io.Writer) (*CSVResultSink, error) {
+	s := &CSVResultSink{cw: csv.NewWriter(w)}
+	if err := s.write(result.CSVHeader()); err != nil {
+		return nil, fmt.Errorf("write csv header: %w", err)
+	}
+	return s, nil
+}
+
+// Add writes a broken link.
+func (s *CSVResultSink) Add(link result.LinkResult) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.write(result.CSVRecord(link)); err != nil {
+		return fmt.Errorf("write result: %w", err)
+	}
+	return nil
+}
+
+// write writes and flushes one record.
+func (s *CSVResultSink) write(record []string) error {
+	if err := s.cw.Write(record); err != nil {
+		return err
+	}
+	s.cw.Flush()
+	return s.cw.Error()
+}
+
+// MemoryResponseCache is a ResponseCache that lasts for the process only.
+type MemoryResponseCache struct {
+	mu      sync.Mutex
+	entries map[string]CacheEntry
+}
+
+// NewMemoryResponseCache creates an empty in-memory response cache.
+func NewMemoryResponseCache() *MemoryResponseCache {
+	return &MemoryResponseCache{entries: make(map[string]CacheEntry)}
+}
+
+// Get returns the stored entry for url.
+func (c *MemoryResponseCache) Get(url string) (CacheEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[url]
+	return entry, ok
+}
+
+// Put stores the entry for url.
+func (c *MemoryResponseCache) Put(url string, entry CacheEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[url] = entry
+}
+
+// Flush implements ResponseCache; there is nothing to persist.
+func (c *MemoryResponseCache) Flush() error {
+	return nil
+}
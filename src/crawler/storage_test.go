@@ -0,0 +1,122 @@
+package crawler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/lukemcguire/zombiecrawl/result"
+)
+
+func TestMemoryVisitedStore(t *testing.T) {
+	s := NewMemoryVisitedStore()
+	if !s.VisitIfNew("http://example.com/a") {
+		t.Error("first VisitIfNew should report a new URL")
+	}
+	if s.VisitIfNew("http://example.com/a") {
+		t.Error("second VisitIfNew should report a visited URL")
+	}
+	s.Visit("http://example.com/b")
+	if s.VisitIfNew("http://example.com/b") {
+		t.Error("Visit should mark the URL as visited")
+	}
+}
+
+func TestJSONResultSink(t *testing.T) {
+	var buf bytes.Buffer
+	sink := NewJSONResultSink(&buf)
+	for _, link := range []result.LinkResult{
+		{URL: "http://example.com/a", StatusCode: 404},
+		{URL: "http://example.com/b?x=<1>", StatusCode: 500},
+	} {
+		if err := sink.Add(link); err != nil {
+			t.Fatalf("Add() error: %v", err)
+		}
+	}
+
+	dec := json.NewDecoder(&buf)
+	var got []result.LinkResult
+	for dec.More() {
+		var link result.LinkResult
+		if err := dec.Decode(&link); err != nil {
+			t.Fatalf("decode line: %v", err)
+		}
+		got = append(got, link)
+	}
+	if len(got) != 2 || got[1].URL != "http://example.com/b?x=<1>" || got[1].StatusCode != 500 {
+		t.Errorf("decoded %+v, want both links in order", got)
+	}
+}
+
+func TestCSVResultSink(t *testing.T) {
+	var buf bytes.Buffer
+	sink, err := NewCSVResultSink(&buf)
+	if err != nil {
+		t.Fatalf("NewCSVResultSink() error: %v", err)
+	}
+	if got := buf.String(); got != "url,status_code,error_type,source_page,is_external\n" {
+		t.Errorf("header = %q, want it written before any link", got)
+	}
+
+	link := result.LinkResult{URL: "http://example.com/a,b", StatusCode: 404, ErrorCategory: result.Category4xx, SourcePage: "http://example.com/"}
+	if err := sink.Add(link); err != nil {
+		t.Fatalf("Add() error: %v", err)
+	}
+	// Each row is flushed as it is added.
+	var want bytes.Buffer
+	if err := result.WriteCSV(&want, []result.LinkResult{link}); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != want.String() {
+		t.Errorf("streamed %q, want the same as WriteCSV: %q", buf.String(), want.String())
+	}
+}
+
+func TestExternalCache_MemoryBackend(t *testing.T) {
+	c := NewExternalCache(NewMemoryResponseCache(), time.Hour)
+	c.store(CrawlResult{Job: CrawlJob{URL: "http://ok.example/", IsExternal: true}, StatusCode: 200})
+	if !c.fresh("http://ok.example/") {
+		t.Error("healthy entry should be fresh")
+	}
+	if err := c.Save(); err != nil {
+		t.Errorf("Save() error: %v", err)
+	}
+}
+
+func TestRun_SuppliedStores(t *testing.T) {
+	ts := newFlakyServer()
+	defer ts.Close()
+
+	// A URL already in a shared visited store is left to whoever queued it
+	visited := NewMemoryVisitedStore()
+	visited.Visit(ts.URL + "/blip")
+	sink := &MemoryResultSink{}
+
+	c, err := New(Config{
+		StartURL:    ts.URL,
+		Delay:       1,
+		RetryPolicy: RetryPolicy{MaxRetries: 0},
+		Visited:     visited,
+		Results:     sink,
+	}, nil)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	res, err := c.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run() error: %v", err)
+	}
+
+	if res.Stats.TotalChecked != 2 {
+		t.Errorf("TotalChecked = %d, want 2 (start page and /dead)", res.Stats.TotalChecked)
+	}
+	links := sink.Links()
+	if len(links) != 1 || links[0].URL != ts.URL+"/dead" {
+		t.Errorf("sink links = %+v, want only /dead", links)
+	}
+	if visited.VisitIfNew(ts.URL + "/dead") {
+		t.Error("crawl should have recorded /dead in the supplied store")
+	}
+}
@@ -0,0 +1,105 @@
+package crawler
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// DefaultMaxStreamBytes is how much of a page of unknown length is read
+	// for links when Config.MaxStreamBytes is zero.
+	DefaultMaxStreamBytes = 10 << 20
+
+	// DefaultMaxStreamTime is how long a page of unknown length is read for
+	// links when Config.MaxStreamTime is zero.
+	DefaultMaxStreamTime = 5 * time.Second
+)
+
+// streamEventStream is the StreamingResource reason of responses whose
+// content type announces a stream of events.
+const streamEventStream = "event stream"
+
+// isEventStream reports whether contentType is a format that streams
+// without end: Server-Sent Events, or a multipart stream such as MJPEG.
+func isEventStream(contentType string) bool {
+	mediaType, _, _ := strings.Cut(strings.ToLower(contentType), ";")
+	switch strings.TrimSpace(mediaType) {
+	case "text/event-stream", "multipart/x-mixed-replace":
+		return true
+	}
+	return false
+}
+
+// streamLimits returns how many bytes of a page of unknown length to read,
+// and for how long. The time is kept inside the request deadline of ctx, so
+// a stream is cut short before it can time out as broken.
+func (cfg Config) streamLimits(ctx context.Context) (int64, time.Duration) {
+	maxBytes := cmp.Or(cfg.MaxStreamBytes, DefaultMaxStreamBytes)
+	maxTime := cmp.Or(cfg.MaxStreamTime, DefaultMaxStreamTime)
+	if deadline, ok := ctx.Deadline(); ok {
+		maxTime = min(maxTime, time.Until(deadline)*9/10)
+	}
+	return maxBytes, maxTime
+}
+
+// streamReader ends a response body early, as if it were complete, once
+// maxBytes have been read or maxTime has passed. Reason tells which, if
+// either did.
+type streamReader struct {
+	reader   io.Reader
+	left     int64
+	maxBytes int64
+	maxTime  time.Duration
+	timer    *time.Timer
+	expired  atomic.Bool
+	reason   string
+}
+
+// newStreamReader limits reading reader, a response body, to maxBytes and
+// maxTime. Once maxTime passes it calls cancel, which must cancel the
+// request's context to interrupt a read waiting for data. Call finish once
+// done reading.
+func newStreamReader(reader io.Reader, cancel context.CancelFunc, maxBytes int64, maxTime time.Duration) *streamReader {
+	s := &streamReader{reader: reader, left: maxBytes, maxBytes: maxBytes, maxTime: maxTime}
+	s.timer = time.AfterFunc(maxTime, func() {
+		s.expired.Store(true)
+		cancel()
+	})
+	return s
+}
+
+// Read implements io.Reader.
+func (s *streamReader) Read(p []byte) (int, error) {
+	if s.reason != "" {
+		return 0, io.EOF
+	}
+	if s.left <= 0 {
+		s.reason = fmt.Sprintf("still sending after %d bytes", s.maxBytes)
+		return 0, io.EOF
+	}
+	if int64(len(p)) > s.left {
+		p = p[:s.left]
+	}
+	n, err := s.reader.Read(p)
+	s.left -= int64(n)
+	if err != nil && err != io.EOF && s.expired.Load() {
+		s.reason = fmt.Sprintf("still sending after %s", s.maxTime.Round(time.Millisecond))
+		return n, io.EOF
+	}
+	return n, err
+}
+
+// finish stops the timer once reading is done and returns why the body was
+// cut short, or "" if it was not. A nil streamReader returns "".
+func (s *streamReader) finish() string {
+	if s == nil {
+		return ""
+	}
+	s.timer.Stop()
+	return s.reason
+}
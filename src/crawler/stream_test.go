@@ -0,0 +1,139 @@
+package crawler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestIsEventStream(t *testing.T) {
+	tests := map[string]bool{
+		"text/event-stream":                       true,
+		"Text/Event-Stream; charset=utf-8":        true,
+		"multipart/x-mixed-replace; boundary=xyz": true,
+		"text/html": false,
+		"":          false,
+	}
+	for contentType, want := range tests {
+		if got := isEventStream(contentType); got != want {
+			t.Errorf("isEventStream(%q) = %v, want %v", contentType, got, want)
+		}
+	}
+}
+
+// streamingServer serves an event stream at /events and, at /, a page of
+// unknown length that sends a link and then keeps sending chunks until the
+// client goes away.
+func streamingServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		flusher := w.(http.Flusher)
+		switch r.URL.Path {
+		case "/events":
+			w.Header().Set("Content-Type", "text/event-stream")
+			_, _ = fmt.Fprint(w, "data: hello\n\n")
+		case "/":
+			w.Header().Set("Content-Type", "text/html")
+			_, _ = fmt.Fprint(w, `<a href="/a">a</a> <a href="/events">events</a>`)
+		default:
+			_, _ = fmt.Fprint(w, "<p>page</p>")
+			return
+		}
+		for r.Context().Err() == nil {
+			flusher.Flush()
+			_, _ = fmt.Fprint(w, strings.Repeat("<p>more</p>", 100))
+			time.Sleep(5 * time.Millisecond)
+		}
+	}))
+}
+
+func TestCheckURL_Streaming(t *testing.T) {
+	ts := streamingServer()
+	defer ts.Close()
+
+	t.Run("event stream", func(t *testing.T) {
+		cfg := DefaultConfig(ts.URL)
+		res := CheckURL(context.Background(), &http.Client{}, CrawlJob{URL: ts.URL + "/events"}, cfg)
+		if res.Result != nil || res.Err != nil {
+			t.Fatalf("event stream reported broken: %+v, %v", res.Result, res.Err)
+		}
+		if res.Streaming != streamEventStream {
+			t.Errorf("Streaming = %q, want %q", res.Streaming, streamEventStream)
+		}
+	})
+
+	t.Run("read time", func(t *testing.T) {
+		cfg := DefaultConfig(ts.URL)
+		cfg.MaxStreamTime = 100 * time.Millisecond
+		started := time.Now()
+		res := CheckURL(context.Background(), &http.Client{}, CrawlJob{URL: ts.URL + "/"}, cfg)
+		if res.Result != nil || res.Err != nil {
+			t.Fatalf("stream reported broken: %+v, %v", res.Result, res.Err)
+		}
+		if elapsed := time.Since(started); elapsed > 2*time.Second {
+			t.Errorf("CheckURL took %s, want reading stopped after about 100ms", elapsed)
+		}
+		if res.Streaming != "still sending after 100ms" {
+			t.Errorf("Streaming = %q, want the read time reason", res.Streaming)
+		}
+		if !slices.Contains(res.Links, ts.URL+"/a") {
+			t.Errorf("Links = %v, want the link read before stopping", res.Links)
+		}
+	})
+
+	t.Run("size", func(t *testing.T) {
+		cfg := DefaultConfig(ts.URL)
+		cfg.MaxStreamBytes = 4096
+		res := CheckURL(context.Background(), &http.Client{}, CrawlJob{URL: ts.URL + "/"}, cfg)
+		if res.Result != nil || res.Err != nil {
+			t.Fatalf("stream reported broken: %+v, %v", res.Result, res.Err)
+		}
+		if res.Streaming != "still sending after 4096 bytes" || res.Bytes != 4096 {
+			t.Errorf("Streaming = %q after %d bytes, want the size reason after 4096", res.Streaming, res.Bytes)
+		}
+	})
+
+	t.Run("request deadline", func(t *testing.T) {
+		cfg := DefaultConfig(ts.URL)
+		cfg.RequestTimeout = 200 * time.Millisecond
+		res := CheckURL(context.Background(), &http.Client{}, CrawlJob{URL: ts.URL + "/"}, cfg)
+		if res.Result != nil || res.Err != nil {
+			t.Fatalf("stream timed out as broken: %+v, %v", res.Result, res.Err)
+		}
+		if !strings.HasPrefix(res.Streaming, "still sending after") {
+			t.Errorf("Streaming = %q, want reading stopped before the deadline", res.Streaming)
+		}
+	})
+}
+
+func TestRun_ReportsStreaming(t *testing.T) {
+	ts := streamingServer()
+	defer ts.Close()
+
+	cfg := DefaultConfig(ts.URL)
+	cfg.Delay = 1
+	cfg.MaxStreamTime = 100 * time.Millisecond
+	c, err := New(cfg, nil)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	res, err := c.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run() error: %v", err)
+	}
+	if len(res.BrokenLinks) != 0 {
+		t.Errorf("BrokenLinks = %+v, want none", res.BrokenLinks)
+	}
+	var urls []string
+	for _, resource := range res.Streaming {
+		urls = append(urls, strings.TrimPrefix(resource.URL, ts.URL))
+	}
+	slices.Sort(urls)
+	if fmt.Sprint(urls) != "[/ /events]" {
+		t.Errorf("Streaming = %+v, want / and /events", res.Streaming)
+	}
+}
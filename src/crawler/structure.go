@@ -0,0 +1,70 @@
+package crawler
+
+import (
+	"cmp"
+	"slices"
+
+	"github.com/lukemcguire/zombiecrawl/result"
+	"github.com/lukemcguire/zombiecrawl/urlutil"
+)
+
+// DefaultMaxOutboundLinks is the outbound link count above which a page is
+// reported when Config.MaxOutboundLinks is unset.
+const DefaultMaxOutboundLinks = 100
+
+// linkGraph counts links between the internal pages of a crawl. It is owned
+// by the coordinator goroutine. A nil *linkGraph records nothing.
+type linkGraph struct {
+	inbound  map[string]int // Distinct internal pages linking to each URL
+	outbound map[string]int // Distinct links on each page
+	pages    []CrawlJob     // Internal pages fetched successfully, in crawl order
+}
+
+// newLinkGraph returns an empty graph.
+func newLinkGraph() *linkGraph {
+	return &linkGraph{inbound: make(map[string]int), outbound: make(map[string]int)}
+}
+
+// recordPage adds the links found on a successfully fetched internal page.
+// links are normalized and distinct, as returned by ExtractLinks. Links from
+// a page to itself do not count as inbound.
+func (g *linkGraph) recordPage(page CrawlJob, links []string, startHost string) {
+	if g == nil {
+		return
+	}
+	g.pages = append(g.pages, page)
+	g.outbound[page.URL] = len(links)
+	for _, link := range links {
+		if link != page.URL && urlutil.IsSameDomain(link, startHost) {
+			g.inbound[link]++
+		}
+	}
+}
+
+// report summarizes the graph: pages nothing links to, other than the start
+// page, and pages with more than maxOutbound links, most links first.
+func (g *linkGraph) report(maxOutbound int) *result.SiteStructure {
+	if g == nil {
+		return nil
+	}
+	if maxOutbound <= 0 {
+		maxOutbound = DefaultMaxOutboundLinks
+	}
+	structure := &result.SiteStructure{
+		Pages:            len(g.pages),
+		Orphans:          []string{},
+		HeavyPages:       []result.PageLinks{},
+		MaxOutboundLinks: maxOutbound,
+	}
+	for _, page := range g.pages {
+		inbound, outbound := g.inbound[page.URL], g.outbound[page.URL]
+		if inbound == 0 && page.SourcePage != "" {
+			structure.Orphans = append(structure.Orphans, page.URL)
+		}
+		if outbound > maxOutbound {
+			structure.HeavyPages = append(structure.HeavyPages, result.PageLinks{URL: page.URL, Inbound: inbound, Outbound: outbound})
+		}
+	}
+	slices.SortStableFunc(structure.HeavyPages, func(a, b result.PageLinks) int { return cmp.Compare(b.Outbound, a.Outbound) })
+	return structure
+}
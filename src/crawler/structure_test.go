@@ -0,0 +1,107 @@
+package crawler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+)
+
+func TestLinkGraph_Report(t *testing.T) {
+	g := newLinkGraph()
+	g.recordPage(CrawlJob{URL: "https://example.com/"}, []string{
+		"https://example.com/",
+		"https://example.com/a",
+		"https://other.example/",
+	}, "example.com")
+	g.recordPage(CrawlJob{URL: "https://example.com/a", SourcePage: "https://example.com/"}, []string{
+		"https://example.com/a",
+	}, "example.com")
+	g.recordPage(CrawlJob{URL: "https://example.com/lost", SourcePage: "https://example.com/sitemap.xml"}, []string{
+		"https://example.com/",
+		"https://example.com/a",
+	}, "example.com")
+
+	got := g.report(2)
+	if got.Pages != 3 {
+		t.Errorf("Pages = %d, want 3", got.Pages)
+	}
+	// The start page is never an orphan; /a is linked from two pages
+	if !slices.Equal(got.Orphans, []string{"https://example.com/lost"}) {
+		t.Errorf("Orphans = %v, want only /lost", got.Orphans)
+	}
+	if len(got.HeavyPages) != 1 || got.HeavyPages[0].URL != "https://example.com/" {
+		t.Fatalf("HeavyPages = %+v, want only the start page", got.HeavyPages)
+	}
+	if heavy := got.HeavyPages[0]; heavy.Outbound != 3 || heavy.Inbound != 1 {
+		t.Errorf("start page links = %d out, %d in; want 3 out, 1 in", heavy.Outbound, heavy.Inbound)
+	}
+
+	if def := g.report(0); def.MaxOutboundLinks != DefaultMaxOutboundLinks || len(def.HeavyPages) != 0 {
+		t.Errorf("report(0) = %+v, want the default threshold and no heavy pages", def)
+	}
+}
+
+func TestLinkGraph_Nil(t *testing.T) {
+	var g *linkGraph
+	g.recordPage(CrawlJob{URL: "https://example.com/"}, []string{"https://example.com/a"}, "example.com")
+	if got := g.report(1); got != nil {
+		t.Errorf("nil graph reported %+v", got)
+	}
+}
+
+func TestRun_SiteStructure(t *testing.T) {
+	var ts *httptest.Server
+	mux := http.NewServeMux()
+	mux.HandleFunc("/sitemap.xml", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprintf(w, `<urlset><url><loc>%[1]s/orphan</loc></url><url><loc>%[1]s/b</loc></url></urlset>`, ts.URL)
+	})
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/":
+			_, _ = fmt.Fprint(w, `<a href="/a">A</a><a href="/b">B</a>`)
+		case "/a", "/b", "/orphan":
+			_, _ = fmt.Fprint(w, `<a href="/">Home</a>`)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+	ts = httptest.NewServer(mux)
+	defer ts.Close()
+
+	for _, enabled := range []bool{false, true} {
+		cfg := DefaultConfig(ts.URL)
+		cfg.Delay = 1
+		cfg.Sitemap = true
+		cfg.SiteStructure = enabled
+		cfg.MaxOutboundLinks = 1
+		c, err := New(cfg, nil)
+		if err != nil {
+			t.Fatalf("New() error: %v", err)
+		}
+		res, err := c.Run(context.Background())
+		if err != nil {
+			t.Fatalf("Run() error: %v", err)
+		}
+		if !enabled {
+			if res.Structure != nil {
+				t.Errorf("disabled: Structure = %+v, want nil", res.Structure)
+			}
+			continue
+		}
+		if res.Structure == nil {
+			t.Fatal("enabled: Structure is nil")
+		}
+		if res.Structure.Pages != 4 {
+			t.Errorf("Pages = %d, want 4", res.Structure.Pages)
+		}
+		if !slices.Equal(res.Structure.Orphans, []string{ts.URL + "/orphan"}) {
+			t.Errorf("Orphans = %v, want only /orphan", res.Structure.Orphans)
+		}
+		if len(res.Structure.HeavyPages) != 1 || res.Structure.HeavyPages[0].URL != ts.URL+"/" {
+			t.Errorf("HeavyPages = %+v, want only the start page", res.Structure.HeavyPages)
+		}
+	}
+}
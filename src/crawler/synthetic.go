@@ -0,0 +1,202 @@
+package crawler
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/lukemcguire/zombiecrawl/result"
+	"github.com/lukemcguire/zombiecrawl/urlutil"
+)
+
+// SyntheticCheck is a request declared in configuration rather than found on
+// a page, such as a health endpoint or a POST-only API. Synthetic checks run
+// once the crawl has finished, and failures are reported with the crawl's
+// broken links, found on SyntheticSource(Name).
+type SyntheticCheck struct {
+	Name         string            // Identifies the check in reports
+	URL          string            // Absolute http or https URL
+	Method       string            // HTTP method (empty = GET)
+	Headers      map[string]string // Request headers, replacing those the crawl would send
+	Body         string            // Request body (empty = none)
+	ExpectStatus []int             // Statuses that pass (empty = any 2xx)
+}
+
+// SyntheticSource is the source page reported for a synthetic check's
+// result, in place of the page a crawled link was found on.
+func SyntheticSource(name string) string {
+	return "synthetic check " + name
+}
+
+// syntheticFile is the JSON form of synthetic checks read by
+// LoadSyntheticChecks.
+type syntheticFile struct {
+	Checks []struct {
+		Name         string            `json:"name"`
+		URL          string            `json:"url"`
+		Method       string            `json:"method"`
+		Headers      map[string]string `json:"headers"`
+		Body         string            `json:"body"`
+		ExpectStatus []int             `json:"expect_status"`
+	} `json:"checks"`
+}
+
+// LoadSyntheticChecks reads synthetic checks from a JSON file such as
+//
+//	{"checks": [{"name": "health", "url": "https://example.com/healthz"},
+//	            {"name": "search", "url": "https://api.example.com/search",
+//	             "method": "POST", "headers": {"Content-Type": "application/json",
+//	             "Authorization": "Bearer $API_TOKEN"},
+//	             "body": "{\"q\": \"test\"}", "expect_status": [200, 201]}]}
+//
+// Header values may reference environment variables, so secrets need not be
+// stored in the file. Checks without a name are named after their URL.
+func LoadSyntheticChecks(r io.Reader) ([]SyntheticCheck, error) {
+	var file syntheticFile
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&file); err != nil {
+		return nil, fmt.Errorf("read synthetic checks: %w", err)
+	}
+
+	checks := make([]SyntheticCheck, 0, len(file.Checks))
+	for i, entry := range file.Checks {
+		parsed, err := url.Parse(entry.URL)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return nil, fmt.Errorf("synthetic check %d: url %q must be an absolute http or https URL", i+1, entry.URL)
+		}
+		check := SyntheticCheck{
+			Name:         entry.Name,
+			URL:          entry.URL,
+			Method:       strings.ToUpper(entry.Method),
+			Body:         entry.Body,
+			ExpectStatus: entry.ExpectStatus,
+		}
+		if check.Name == "" {
+			check.Name = entry.URL
+		}
+		for _, status := range check.ExpectStatus {
+			if status < 100 || status > 599 {
+				return nil, fmt.Errorf("synthetic check %q: expect_status %d is not an HTTP status", check.Name, status)
+			}
+		}
+		for name, value := range entry.Headers {
+			if check.Headers == nil {
+				check.Headers = make(map[string]string, len(entry.Headers))
+			}
+			check.Headers[name] = os.ExpandEnv(value)
+		}
+		checks = append(checks, check)
+	}
+	return checks, nil
+}
+
+// runSyntheticChecks runs Config.SyntheticChecks under the crawl's rate
+// limits, records them like crawled links, and returns the ones that
+// failed. If ctx is cancelled, unstarted checks are skipped.
+func (c *Crawler) runSyntheticChecks(ctx context.Context, startHost string) []result.LinkResult {
+	results := make([]CrawlResult, len(c.cfg.SyntheticChecks))
+	ran := make([]bool, len(c.cfg.SyntheticChecks))
+	slots := make(chan struct{}, c.cfg.Concurrency)
+	var wg sync.WaitGroup
+	for i, check := range c.cfg.SyntheticChecks {
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		wg.Go(func() {
+			defer func() { <-slots }()
+			if c.limiter.Wait(ctx) != nil || c.hostLimiters.wait(ctx, c.cfg, check.URL) != nil {
+				return
+			}
+			results[i] = c.checkSynthetic(ctx, check, startHost)
+			ran[i] = true
+		})
+	}
+	wg.Wait()
+
+	var failed []result.LinkResult
+	for i, res := range results {
+		if !ran[i] {
+			continue
+		}
+		c.recordCheck(res)
+		if res.Result != nil {
+			failed = append(failed, *res.Result)
+			if c.cfg.Results != nil {
+				if sinkErr := c.cfg.Results.Add(c.cfg.Scrub.Link(*res.Result)); sinkErr != nil {
+					c.cfg.logger().Error("result sink failed", "url", res.Job.URL, "host", hostFromURL(res.Job.URL), "error", sinkErr)
+				}
+			}
+			c.confirmBroken(*res.Result)
+		}
+	}
+	return failed
+}
+
+// checkSynthetic sends check's request, following redirects like a crawled
+// link, and judges the final status against check.ExpectStatus.
+func (c *Crawler) checkSynthetic(ctx context.Context, check SyntheticCheck, startHost string) (res CrawlResult) {
+	res.Job = CrawlJob{
+		URL:        check.URL,
+		SourcePage: SyntheticSource(check.Name),
+		IsExternal: !urlutil.IsSameDomain(check.URL, startHost),
+		UserAgent:  c.userAgents.For(check.URL),
+	}
+	res.Attempts = 1
+	start := c.cfg.now()
+	defer func() { res.Duration = c.cfg.since(start) }()
+
+	ctx, cancel := context.WithTimeout(ctx, c.cfg.RequestTimeout)
+	defer cancel()
+	req, err := newRequest(ctx, cmp.Or(check.Method, http.MethodGet), res.Job, c.cfg)
+	if err != nil {
+		fetchFailed(&res, err, false, c.cfg)
+		return res
+	}
+	if check.Body != "" {
+		req.Body = io.NopCloser(strings.NewReader(check.Body))
+		req.GetBody = func() (io.ReadCloser, error) { return io.NopCloser(strings.NewReader(check.Body)), nil }
+		req.ContentLength = int64(len(check.Body))
+	}
+	for name, value := range check.Headers {
+		req.Header.Set(name, value)
+	}
+
+	redirects := newRedirectTracker(c.cfg)
+	client := &http.Client{
+		Transport:     c.cfg.HAR.wrap(cmp.Or(c.client.Transport, c.cfg.Transport), c.cfg.timingClock()),
+		CheckRedirect: redirects.check,
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		fetchFailed(&res, err, redirects.loop, c.cfg)
+		return res
+	}
+	defer func() { _ = resp.Body.Close() }()
+	counter := &countingReader{reader: c.cfg.Bandwidth.reader(ctx, resp.Body)}
+	_, _ = io.Copy(io.Discard, counter)
+	res.Bytes = counter.count
+	res.StatusCode = resp.StatusCode
+
+	switch {
+	case redirects.loop:
+		statusFailed(&res, resp, true)
+	case statusExpected(check.ExpectStatus, resp.StatusCode):
+	case resp.StatusCode >= 400 && len(check.ExpectStatus) == 0:
+		statusFailed(&res, resp, false)
+	default:
+		unexpectedStatusFailed(&res, resp, check.ExpectStatus)
+	}
+	return res
+}
@@ -0,0 +1,126 @@
+package crawler
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/lukemcguire/zombiecrawl/result"
+)
+
+func TestLoadSyntheticChecks(t *testing.T) {
+	t.Setenv("TEST_API_TOKEN", "s3cret")
+	input := `{"checks": [
+		{"name": "search", "url": "https://api.example.com/search", "method": "post",
+		 "headers": {"Authorization": "Bearer $TEST_API_TOKEN"}, "body": "{\"q\": 1}", "expect_status": [200, 201]},
+		{"url": "https://example.com/healthz"}
+	]}`
+	checks, err := LoadSyntheticChecks(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("LoadSyntheticChecks() error: %v", err)
+	}
+	if len(checks) != 2 {
+		t.Fatalf("got %d checks, want 2", len(checks))
+	}
+
+	search := checks[0]
+	if search.Name != "search" || search.Method != http.MethodPost || search.Body != `{"q": 1}` || !slices.Equal(search.ExpectStatus, []int{200, 201}) {
+		t.Errorf("search check = %+v", search)
+	}
+	if got := search.Headers["Authorization"]; got != "Bearer s3cret" {
+		t.Errorf("Authorization = %q, want environment variable expanded", got)
+	}
+	if health := checks[1]; health.Name != "https://example.com/healthz" || health.Method != "" {
+		t.Errorf("unnamed check = %+v, want it named after its URL", health)
+	}
+}
+
+func TestLoadSyntheticChecks_Errors(t *testing.T) {
+	for name, input := range map[string]string{
+		"not json":       `checks:`,
+		"unknown field":  `{"checks": [{"url": "https://example.com/", "status": 200}]}`,
+		"missing url":    `{"checks": [{"name": "a"}]}`,
+		"relative url":   `{"checks": [{"url": "/healthz"}]}`,
+		"non-http url":   `{"checks": [{"url": "ftp://example.com/"}]}`,
+		"bad status":     `{"checks": [{"url": "https://example.com/", "expect_status": [2000]}]}`,
+		"status as text": `{"checks": [{"url": "https://example.com/", "expect_status": ["200"]}]}`,
+	} {
+		if _, err := LoadSyntheticChecks(strings.NewReader(input)); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestRun_SyntheticChecks(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/":
+			_, _ = fmt.Fprint(w, `<a href="/about">about</a>`)
+		case "/about":
+			_, _ = fmt.Fprint(w, "about")
+		case "/api/items":
+			body, _ := io.ReadAll(r.Body)
+			if r.Method != http.MethodPost || string(body) != `{"name": "x"}` || r.Header.Get("Content-Type") != "application/json" {
+				http.Error(w, "bad request", http.StatusBadRequest)
+				return
+			}
+			w.WriteHeader(http.StatusCreated)
+		case "/api/login":
+			_, _ = fmt.Fprint(w, "welcome") // Should have demanded credentials
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	cfg := DefaultConfig(ts.URL)
+	cfg.Delay = 1
+	cfg.SyntheticChecks = []SyntheticCheck{
+		{
+			Name:         "create item",
+			URL:          ts.URL + "/api/items",
+			Method:       http.MethodPost,
+			Headers:      map[string]string{"Content-Type": "application/json"},
+			Body:         `{"name": "x"}`,
+			ExpectStatus: []int{http.StatusCreated},
+		},
+		{Name: "login", URL: ts.URL + "/api/login", ExpectStatus: []int{http.StatusUnauthorized}},
+		{Name: "health", URL: ts.URL + "/healthz"},
+	}
+	c, err := New(cfg, nil)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	res, err := c.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run() error: %v", err)
+	}
+
+	// Two crawled pages plus three checks
+	if res.Stats.TotalChecked != 5 {
+		t.Errorf("TotalChecked = %d, want 5", res.Stats.TotalChecked)
+	}
+	broken := make(map[string]result.LinkResult)
+	for _, link := range res.BrokenLinks {
+		broken[link.SourcePage] = link
+	}
+	if len(broken) != 2 {
+		t.Fatalf("broken links = %+v, want login and health", res.BrokenLinks)
+	}
+	if _, ok := broken[SyntheticSource("create item")]; ok {
+		t.Error("create item check failed despite the expected 201")
+	}
+	login := broken[SyntheticSource("login")]
+	if login.ErrorCategory != result.CategoryUnexpectedStatus || login.StatusCode != http.StatusOK || login.Error != "unexpected status 200 (want 401)" {
+		t.Errorf("login = %+v, want unexpected 200", login)
+	}
+	health := broken[SyntheticSource("health")]
+	if health.ErrorCategory != result.Category4xx || health.StatusCode != http.StatusNotFound || health.URL != ts.URL+"/healthz" {
+		t.Errorf("health = %+v, want a 404", health)
+	}
+}
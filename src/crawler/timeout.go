@@ -0,0 +1,48 @@
+package crawler
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// TimeoutOverride gives URLs matching Pattern their own request timeout, so
+// known-slow pages such as exports or reports can take longer without
+// slowing failure detection everywhere else. Pattern uses
+// StatusExpectation.Pattern syntax.
+type TimeoutOverride struct {
+	Pattern string
+	Timeout time.Duration
+}
+
+// String formats o as "pattern=timeout", the form accepted by
+// ParseTimeoutOverride.
+func (o TimeoutOverride) String() string {
+	return o.Pattern + "=" + o.Timeout.String()
+}
+
+// ParseTimeoutOverride parses a "pattern=timeout" override such as
+// "/export/*=60s". The pattern is everything before the last "=", since URL
+// patterns may contain one.
+func ParseTimeoutOverride(value string) (TimeoutOverride, error) {
+	i := strings.LastIndex(value, "=")
+	if i <= 0 || i == len(value)-1 {
+		return TimeoutOverride{}, fmt.Errorf("%q: expected pattern=timeout", value)
+	}
+	timeout, err := time.ParseDuration(strings.TrimSpace(value[i+1:]))
+	if err != nil || timeout <= 0 {
+		return TimeoutOverride{}, fmt.Errorf("%q: %q is not a positive duration", value, value[i+1:])
+	}
+	return TimeoutOverride{Pattern: value[:i], Timeout: timeout}, nil
+}
+
+// requestTimeout returns the timeout of the first of cfg.TimeoutOverrides
+// matching job's URL, or cfg.RequestTimeout if none does.
+func (cfg Config) requestTimeout(job CrawlJob) time.Duration {
+	for _, override := range cfg.TimeoutOverrides {
+		if urlPatternMatch(override.Pattern, job) {
+			return override.Timeout
+		}
+	}
+	return cfg.RequestTimeout
+}
@@ -0,0 +1,61 @@
+package crawler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/lukemcguire/zombiecrawl/result"
+)
+
+func TestParseTimeoutOverride(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    TimeoutOverride
+		wantErr bool
+	}{
+		{value: "/export/*=60s", want: TimeoutOverride{Pattern: "/export/*", Timeout: time.Minute}},
+		{value: "https://reports.example.com/*?format=csv=1m30s", want: TimeoutOverride{Pattern: "https://reports.example.com/*?format=csv", Timeout: 90 * time.Second}},
+		{value: "/export/*", wantErr: true},
+		{value: "=60s", wantErr: true},
+		{value: "/export/*=", wantErr: true},
+		{value: "/export/*=60", wantErr: true},
+		{value: "/export/*=-1s", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseTimeoutOverride(tt.value)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseTimeoutOverride(%q) = %+v, %v, want %+v (error %v)", tt.value, got, err, tt.want, tt.wantErr)
+		}
+		if roundTrip, _ := ParseTimeoutOverride(got.String()); err == nil && roundTrip != got {
+			t.Errorf("ParseTimeoutOverride(%q) = %+v, want the override back", got.String(), roundTrip)
+		}
+	}
+}
+
+func TestCheckURL_TimeoutOverride(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	cfg := DefaultConfig(ts.URL)
+	cfg.RequestTimeout = 50 * time.Millisecond
+	cfg.TimeoutOverrides = []TimeoutOverride{{Pattern: "/export/*", Timeout: 5 * time.Second}}
+	client := &http.Client{}
+
+	if res := CheckURL(context.Background(), client, CrawlJob{URL: ts.URL + "/export/all.csv"}, cfg); res.Result != nil {
+		t.Errorf("overridden URL failed: %+v", res.Result)
+	}
+	res := CheckURL(context.Background(), client, CrawlJob{URL: ts.URL + "/page"}, cfg)
+	if res.Result == nil || res.Result.ErrorCategory != result.CategoryTimeout {
+		t.Errorf("other URL result = %+v, want a timeout after the global limit", res.Result)
+	}
+	// Path patterns only apply on the crawled site
+	if got := cfg.requestTimeout(CrawlJob{URL: "https://other.example/export/x", IsExternal: true}); got != cfg.RequestTimeout {
+		t.Errorf("requestTimeout() for an external link = %s, want %s", got, cfg.RequestTimeout)
+	}
+}
@@ -0,0 +1,83 @@
+package crawler
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// RequestTrace describes one HTTP request of a crawl to Config.OnRequest and
+// Config.OnResponse. Every request is traced, including redirect hops,
+// robots.txt and sitemap fetches, and post-crawl checks.
+type RequestTrace struct {
+	// Request is the request about to be sent. OnRequest may modify it,
+	// for example to add headers; the crawler's own request is not affected.
+	Request *http.Request
+
+	// Attempt counts the tries of a link check: 1 for the first request,
+	// 2 for the first retry, and so on. Requests that are never retried,
+	// such as robots.txt fetches, are always attempt 1.
+	Attempt int
+
+	Started time.Time // When the request was sent
+
+	// Set for OnResponse only. Duration runs until the response headers
+	// arrived or the request failed; the body is read afterwards.
+	Response *http.Response // Nil if the request failed
+	Err      error
+	Duration time.Duration
+}
+
+// attemptKey is the context key of the link check attempt a request is for.
+type attemptKey struct{}
+
+// withAttempt returns ctx recording that its requests are for attempt.
+func withAttempt(ctx context.Context, attempt int) context.Context {
+	return context.WithValue(ctx, attemptKey{}, attempt)
+}
+
+// attemptFrom returns the attempt recorded in ctx by withAttempt, or 1.
+func attemptFrom(ctx context.Context) int {
+	if attempt, ok := ctx.Value(attemptKey{}).(int); ok {
+		return attempt
+	}
+	return 1
+}
+
+// tracingTransport calls Config.OnRequest and Config.OnResponse around each
+// request it carries.
+type tracingTransport struct {
+	base http.RoundTripper
+	cfg  Config
+}
+
+// traceRequests wraps base to call cfg.OnRequest and cfg.OnResponse. base is
+// returned unchanged if neither is set.
+func traceRequests(base http.RoundTripper, cfg Config) http.RoundTripper {
+	if cfg.OnRequest == nil && cfg.OnResponse == nil {
+		return base
+	}
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &tracingTransport{base: base, cfg: cfg}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *tracingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	trace := &RequestTrace{
+		// A RoundTripper must not modify the request it is given
+		Request: req.Clone(req.Context()),
+		Attempt: attemptFrom(req.Context()),
+	}
+	if t.cfg.OnRequest != nil {
+		t.cfg.OnRequest(trace)
+	}
+	trace.Started = t.cfg.now()
+	resp, err := t.base.RoundTrip(trace.Request)
+	if t.cfg.OnResponse != nil {
+		trace.Response, trace.Err, trace.Duration = resp, err, t.cfg.since(trace.Started)
+		t.cfg.OnResponse(trace)
+	}
+	return resp, err
+}
@@ -0,0 +1,92 @@
+package crawler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRun_TracesRequests(t *testing.T) {
+	var flakyRequests atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Trace") != "on" {
+			http.Error(w, "missing trace header", http.StatusBadRequest)
+			return
+		}
+		switch r.URL.Path {
+		case "/":
+			_, _ = fmt.Fprint(w, `<a href="/flaky">flaky</a>`)
+		case "/flaky":
+			if flakyRequests.Add(1) == 1 {
+				http.Error(w, "try again", http.StatusServiceUnavailable)
+				return
+			}
+			_, _ = fmt.Fprint(w, `<p>ok</p>`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	var mu sync.Mutex
+	var traces []RequestTrace
+	cfg := DefaultConfig(ts.URL)
+	cfg.Delay = 1
+	cfg.RetryPolicy = RetryPolicy{MaxRetries: 1, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond}
+	cfg.OnRequest = func(trace *RequestTrace) {
+		trace.Request.Header.Set("X-Trace", "on")
+	}
+	cfg.OnResponse = func(trace *RequestTrace) {
+		mu.Lock()
+		defer mu.Unlock()
+		traces = append(traces, *trace)
+	}
+	c, err := New(cfg, nil)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	res, err := c.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run() error: %v", err)
+	}
+	if len(res.BrokenLinks) != 0 {
+		t.Errorf("BrokenLinks = %+v, want none", res.BrokenLinks)
+	}
+
+	type outcome struct {
+		attempt, status int
+	}
+	got := make(map[string][]outcome)
+	for _, trace := range traces {
+		if trace.Err != nil || trace.Response == nil {
+			t.Errorf("trace of %s: error %v", trace.Request.URL, trace.Err)
+			continue
+		}
+		if trace.Started.IsZero() || trace.Duration < 0 {
+			t.Errorf("trace of %s: Started %v, Duration %v", trace.Request.URL, trace.Started, trace.Duration)
+		}
+		got[trace.Request.URL.Path] = append(got[trace.Request.URL.Path], outcome{trace.Attempt, trace.Response.StatusCode})
+	}
+	want := map[string][]outcome{
+		"/robots.txt": {{1, http.StatusNotFound}},
+		"/":           {{1, http.StatusOK}},
+		"/flaky":      {{1, http.StatusServiceUnavailable}, {2, http.StatusOK}},
+	}
+	for path, outcomes := range want {
+		if fmt.Sprint(got[path]) != fmt.Sprint(outcomes) {
+			t.Errorf("traces of %s = %v, want %v", path, got[path], outcomes)
+		}
+	}
+}
+
+func TestTraceRequests_Unset(t *testing.T) {
+	base := http.DefaultTransport
+	if got := traceRequests(base, Config{}); got != base {
+		t.Errorf("traceRequests() without hooks = %T, want base transport", got)
+	}
+}
@@ -0,0 +1,144 @@
+package crawler
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// IPVersion selects which IP protocol version connections use.
+type IPVersion string
+
+const (
+	IPAuto IPVersion = "auto" // Either, as the resolver and Happy Eyeballs decide (the default)
+	IPv4   IPVersion = "4"    // IPv4 only
+	IPv6   IPVersion = "6"    // IPv6 only
+)
+
+// ParseIPVersion converts a user-supplied IP version into an IPVersion. An
+// empty name selects IPAuto.
+func ParseIPVersion(name string) (IPVersion, error) {
+	switch IPVersion(name) {
+	case "", IPAuto:
+		return IPAuto, nil
+	case IPv4, IPv6:
+		return IPVersion(name), nil
+	default:
+		return "", fmt.Errorf("unknown IP version %q (want 4, 6, or auto)", name)
+	}
+}
+
+// network returns the dial network for v, or "" to leave it unchanged.
+func (v IPVersion) network() string {
+	switch v {
+	case IPv4:
+		return "tcp4"
+	case IPv6:
+		return "tcp6"
+	default:
+		return ""
+	}
+}
+
+// ParseDNSServer validates a resolver address given as an IP address with
+// an optional port, defaulting to port 53, and returns it as host:port. An
+// empty address means the system resolver and is returned unchanged.
+func ParseDNSServer(addr string) (string, error) {
+	if addr == "" {
+		return "", nil
+	}
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		host, port = strings.Trim(addr, "[]"), "53"
+	}
+	if net.ParseIP(host) == nil {
+		return "", fmt.Errorf("DNS server %q is not an IP address", addr)
+	}
+	return net.JoinHostPort(host, port), nil
+}
+
+// HostOverride connects to Address instead of the resolved address of Host
+// when dialing Host on Port, like curl's --resolve. Requests keep Host in
+// their Host header and TLS server name.
+type HostOverride struct {
+	Host    string
+	Port    string
+	Address string // IP address to connect to
+}
+
+// overrideAddresses maps each overridden "host:port" to the address to dial.
+func overrideAddresses(overrides []HostOverride) map[string]string {
+	if len(overrides) == 0 {
+		return nil
+	}
+	addrs := make(map[string]string, len(overrides))
+	for _, override := range overrides {
+		key := net.JoinHostPort(strings.ToLower(override.Host), override.Port)
+		addrs[key] = net.JoinHostPort(override.Address, override.Port)
+	}
+	return addrs
+}
+
+// newResolver returns a resolver that sends every query to server instead
+// of the resolvers configured on the system.
+func newResolver(server string) *net.Resolver {
+	return &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			var dialer net.Dialer
+			return dialer.DialContext(ctx, network, server)
+		},
+	}
+}
+
+// configureTransport returns base with cfg's host overrides, IP version, DNS
+// server, and dial, TLS
+// handshake, and response header timeouts applied. Separate timeouts let a
+// host that cannot be reached fail differently from one that is slow to
+// respond. A nil base
+// means http.DefaultTransport. Other transports, such as a ReplayTransport,
+// make no network connections and are returned unchanged, as is base when
+// none of these settings is used.
+func configureTransport(base http.RoundTripper, cfg Config) http.RoundTripper {
+	network := cfg.IPVersion.network()
+	overrides := overrideAddresses(cfg.HostOverrides)
+	customDial := network != "" || cfg.DNSServer != "" || cfg.DialTimeout > 0 || overrides != nil
+	if !customDial && cfg.TLSHandshakeTimeout <= 0 && cfg.ResponseHeaderTimeout <= 0 {
+		return base
+	}
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	transport, ok := base.(*http.Transport)
+	if !ok {
+		return base
+	}
+	transport = transport.Clone()
+	if customDial {
+		// Same as http.DefaultTransport's dialer unless configured
+		dialer := &net.Dialer{Timeout: cmp.Or(cfg.DialTimeout, 30*time.Second), KeepAlive: 30 * time.Second}
+		if cfg.DNSServer != "" {
+			dialer.Resolver = newResolver(cfg.DNSServer)
+		}
+		transport.DialContext = func(ctx context.Context, dialNetwork, addr string) (net.Conn, error) {
+			if network != "" && dialNetwork == "tcp" {
+				dialNetwork = network
+			}
+			if override, ok := overrides[strings.ToLower(addr)]; ok {
+				addr = override
+			}
+			return dialer.DialContext(ctx, dialNetwork, addr)
+		}
+	}
+	if cfg.TLSHandshakeTimeout > 0 {
+		transport.TLSHandshakeTimeout = cfg.TLSHandshakeTimeout
+	}
+	if cfg.ResponseHeaderTimeout > 0 {
+		transport.ResponseHeaderTimeout = cfg.ResponseHeaderTimeout
+	}
+	return transport
+}
@@ -0,0 +1,228 @@
+package crawler
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/lukemcguire/zombiecrawl/result"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+func TestWithPhaseTimeouts(t *testing.T) {
+	if got := configureTransport(nil, Config{}); got != nil {
+		t.Errorf("no phase timeouts: got %v, want the base transport", got)
+	}
+
+	cfg := Config{DialTimeout: time.Second, TLSHandshakeTimeout: 2 * time.Second, ResponseHeaderTimeout: 3 * time.Second}
+	transport, ok := configureTransport(nil, cfg).(*http.Transport)
+	if !ok || transport == http.DefaultTransport {
+		t.Fatalf("got %T, want a copy of http.DefaultTransport", transport)
+	}
+	if transport.TLSHandshakeTimeout != 2*time.Second || transport.ResponseHeaderTimeout != 3*time.Second {
+		t.Errorf("timeouts = %v/%v, want 2s/3s", transport.TLSHandshakeTimeout, transport.ResponseHeaderTimeout)
+	}
+	if http.DefaultTransport.(*http.Transport).ResponseHeaderTimeout != 0 {
+		t.Error("http.DefaultTransport was modified")
+	}
+
+	replay := &ReplayTransport{}
+	if got := configureTransport(replay, cfg); got != replay {
+		t.Errorf("replay transport replaced by %T", got)
+	}
+}
+
+func TestCheckURL_PhaseTimeouts(t *testing.T) {
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(500 * time.Millisecond)
+	}))
+	defer slow.Close()
+
+	// Accepts connections but never completes a TLS handshake
+	silent, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer silent.Close()
+	go func() {
+		var conns []net.Conn
+		for {
+			conn, err := silent.Accept()
+			if err != nil {
+				for _, conn := range conns {
+					_ = conn.Close()
+				}
+				return
+			}
+			conns = append(conns, conn)
+		}
+	}()
+
+	cfg := Config{
+		RequestTimeout:        5 * time.Second,
+		TLSHandshakeTimeout:   100 * time.Millisecond,
+		ResponseHeaderTimeout: 100 * time.Millisecond,
+	}
+	client := &http.Client{Transport: configureTransport(nil, cfg)}
+
+	tests := []struct {
+		url  string
+		want result.ErrorCategory
+	}{
+		{slow.URL, result.CategoryTimeout},
+		{"https://" + silent.Addr().String() + "/", result.CategoryConnectTimeout},
+	}
+	for _, tt := range tests {
+		started := time.Now()
+		res := CheckURL(context.Background(), client, CrawlJob{URL: tt.url}, cfg)
+		if res.Result == nil || res.Result.ErrorCategory != tt.want {
+			t.Errorf("%s: result = %+v, want %q", tt.url, res.Result, tt.want)
+		}
+		if elapsed := time.Since(started); elapsed > 2*time.Second {
+			t.Errorf("%s: took %v, want the phase timeout to fire first", tt.url, elapsed)
+		}
+	}
+}
+
+func TestParseIPVersion(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    IPVersion
+		wantErr bool
+	}{
+		{"", IPAuto, false},
+		{"auto", IPAuto, false},
+		{"4", IPv4, false},
+		{"6", IPv6, false},
+		{"5", "", true},
+	}
+	for _, tt := range tests {
+		got, err := ParseIPVersion(tt.name)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseIPVersion(%q) error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+		if got != tt.want {
+			t.Errorf("ParseIPVersion(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestConfigureTransport_IPVersion(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer ts.Close()
+
+	// The test server listens on 127.0.0.1, so only IPv4 can reach it
+	for _, tt := range []struct {
+		version IPVersion
+		reached bool
+	}{{IPAuto, true}, {IPv4, true}, {IPv6, false}} {
+		cfg := Config{RequestTimeout: 5 * time.Second, IPVersion: tt.version}
+		client := &http.Client{Transport: configureTransport(nil, cfg)}
+		res := CheckURL(context.Background(), client, CrawlJob{URL: ts.URL}, cfg)
+		if reached := res.Result == nil; reached != tt.reached {
+			t.Errorf("IPVersion %q: reached = %v, want %v (result %+v)", tt.version, reached, tt.reached, res.Result)
+		}
+	}
+}
+
+func TestParseDNSServer(t *testing.T) {
+	tests := []struct {
+		addr    string
+		want    string
+		wantErr bool
+	}{
+		{"", "", false},
+		{"1.1.1.1", "1.1.1.1:53", false},
+		{"1.1.1.1:5353", "1.1.1.1:5353", false},
+		{"2606:4700:4700::1111", "[2606:4700:4700::1111]:53", false},
+		{"[::1]:53", "[::1]:53", false},
+		{"dns.example:53", "", true},
+	}
+	for _, tt := range tests {
+		got, err := ParseDNSServer(tt.addr)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseDNSServer(%q) error = %v, wantErr %v", tt.addr, err, tt.wantErr)
+		}
+		if got != tt.want {
+			t.Errorf("ParseDNSServer(%q) = %q, want %q", tt.addr, got, tt.want)
+		}
+	}
+}
+
+// newDNSServer answers A queries for every name with 127.0.0.1 and other
+// queries with no records, returning its address.
+func newDNSServer(t *testing.T) string {
+	t.Helper()
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	go func() {
+		buf := make([]byte, 512)
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			var query dnsmessage.Message
+			if query.Unpack(buf[:n]) != nil || len(query.Questions) == 0 {
+				continue
+			}
+			question := query.Questions[0]
+			reply := dnsmessage.Message{
+				Header:    dnsmessage.Header{ID: query.ID, Response: true, Authoritative: true},
+				Questions: query.Questions,
+			}
+			if question.Type == dnsmessage.TypeA {
+				reply.Answers = []dnsmessage.Resource{{
+					Header: dnsmessage.ResourceHeader{Name: question.Name, Type: dnsmessage.TypeA, Class: dnsmessage.ClassINET, TTL: 60},
+					Body:   &dnsmessage.AResource{A: [4]byte{127, 0, 0, 1}},
+				}}
+			}
+			packed, err := reply.Pack()
+			if err == nil {
+				_, _ = conn.WriteTo(packed, addr)
+			}
+		}
+	}()
+	return conn.LocalAddr().String()
+}
+
+func TestConfigureTransport_DNSServer(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer ts.Close()
+	_, port, _ := net.SplitHostPort(ts.Listener.Addr().String())
+
+	cfg := Config{RequestTimeout: 5 * time.Second, DNSServer: newDNSServer(t)}
+	client := &http.Client{Transport: configureTransport(nil, cfg)}
+	res := CheckURL(context.Background(), client, CrawlJob{URL: "http://zombie.invalid:" + port + "/"}, cfg)
+	if res.Result != nil {
+		t.Errorf("name not resolved through the configured server: %+v", res.Result)
+	}
+}
+
+func TestConfigureTransport_HostOverrides(t *testing.T) {
+	var port string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The request keeps the overridden host name
+		if r.Host != "staging.example.com:"+port {
+			http.Error(w, "unexpected host "+r.Host, http.StatusBadRequest)
+		}
+	}))
+	defer ts.Close()
+	_, port, _ = net.SplitHostPort(ts.Listener.Addr().String())
+
+	cfg := Config{
+		RequestTimeout: 5 * time.Second,
+		HostOverrides:  []HostOverride{{Host: "Staging.Example.com", Port: port, Address: "127.0.0.1"}},
+	}
+	client := &http.Client{Transport: configureTransport(nil, cfg)}
+	res := CheckURL(context.Background(), client, CrawlJob{URL: "http://staging.example.com:" + port + "/", IsExternal: true}, cfg)
+	if res.Result != nil || res.StatusCode != http.StatusOK {
+		t.Errorf("overridden host not reached: status %d, result %+v", res.StatusCode, res.Result)
+	}
+}
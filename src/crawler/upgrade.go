@@ -0,0 +1,73 @@
+package crawler
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/lukemcguire/zombiecrawl/result"
+)
+
+// checkHTTPS probes the https:// equivalent of each working http:// internal
+// page and returns a warning for every page that is also served over https,
+// meaning links to it should be updated. An https URL that redirects back to
+// http does not count as working. If ctx is cancelled, unprobed pages are
+// skipped.
+func (c *Crawler) checkHTTPS(ctx context.Context, pages []CrawlJob) []result.HygieneWarning {
+	available := make([]bool, len(pages))
+	slots := make(chan struct{}, c.cfg.Concurrency)
+	var wg sync.WaitGroup
+	for i, page := range pages {
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		wg.Go(func() {
+			defer func() { <-slots }()
+			if c.limiter.Wait(ctx) != nil {
+				return
+			}
+			// Checked as an external link: HEAD is enough, no body is needed.
+			job := CrawlJob{
+				URL:        httpsURL(page.URL),
+				SourcePage: page.SourcePage,
+				IsExternal: true,
+				UserAgent:  page.UserAgent,
+			}
+			res := CheckURL(ctx, c.client, job, c.cfg)
+			available[i] = res.Result == nil && res.Err == nil && !hasWarning(res, result.HygieneHTTPSDowngrade)
+		})
+	}
+	wg.Wait()
+
+	var warnings []result.HygieneWarning
+	for i, page := range pages {
+		if available[i] {
+			warnings = append(warnings, result.HygieneWarning{
+				Kind:       result.HygieneHTTPSAvailable,
+				URL:        page.URL,
+				Target:     httpsURL(page.URL),
+				SourcePage: page.SourcePage,
+			})
+		}
+	}
+	return warnings
+}
+
+// httpsURL returns rawURL with its http scheme replaced by https.
+func httpsURL(rawURL string) string {
+	return "https" + strings.TrimPrefix(rawURL, "http")
+}
+
+// hasWarning reports whether res carries a warning of the given kind.
+func hasWarning(res CrawlResult, kind result.HygieneKind) bool {
+	for _, warning := range res.Warnings {
+		if warning.Kind == kind {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,123 @@
+package crawler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/lukemcguire/zombiecrawl/result"
+)
+
+// schemeTransport sends https requests for the plain server's host to the TLS
+// server instead, so one site can be reached over both schemes.
+type schemeTransport struct {
+	plain, secure *httptest.Server
+}
+
+func (st schemeTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	plain, _ := url.Parse(st.plain.URL)
+	if req.URL.Scheme == "https" && req.URL.Host == plain.Host {
+		secure, _ := url.Parse(st.secure.URL)
+		req = req.Clone(req.Context())
+		req.URL.Host = secure.Host
+		return st.secure.Client().Transport.RoundTrip(req)
+	}
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestRun_CheckHTTPS(t *testing.T) {
+	plain := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/":
+			_, _ = fmt.Fprint(w, `<a href="/both">both</a><a href="/plain-only">plain</a><a href="/bounce">bounce</a>`)
+		default:
+			_, _ = fmt.Fprint(w, `<html></html>`)
+		}
+	}))
+	defer plain.Close()
+	secure := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/plain-only":
+			http.NotFound(w, r)
+		case "/bounce":
+			// Downgrades straight back to the http page.
+			http.Redirect(w, r, plain.URL+"/bounce", http.StatusMovedPermanently)
+		default:
+			_, _ = fmt.Fprint(w, `<html></html>`)
+		}
+	}))
+	defer secure.Close()
+
+	cfg := DefaultConfig(plain.URL)
+	cfg.Delay = 1
+	cfg.CheckHTTPS = true
+	cfg.Transport = schemeTransport{plain: plain, secure: secure}
+	c, err := New(cfg, nil)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	res, err := c.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run() error: %v", err)
+	}
+
+	upgradable := make(map[string]string)
+	for _, warning := range res.Hygiene {
+		if warning.Kind != result.HygieneHTTPSAvailable {
+			t.Errorf("unexpected warning %+v", warning)
+			continue
+		}
+		upgradable[warning.URL] = warning.Target
+	}
+	for _, path := range []string{"/", "/both"} {
+		if got, want := upgradable[plain.URL+path], "https"+plain.URL[len("http"):]+path; got != want {
+			t.Errorf("%s: target = %q, want %q", path, got, want)
+		}
+	}
+	if len(upgradable) != 2 {
+		t.Errorf("got %d upgradable pages, want 2: %v", len(upgradable), upgradable)
+	}
+}
+
+func TestCheckURL_HTTPSDowngrade(t *testing.T) {
+	plain := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer plain.Close()
+	secure := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, plain.URL+"/landing", http.StatusFound)
+	}))
+	defer secure.Close()
+
+	job := CrawlJob{URL: secure.URL + "/old", SourcePage: "https://example.com/", IsExternal: true}
+	for _, enabled := range []bool{false, true} {
+		cfg := DefaultConfig(secure.URL)
+		cfg.CheckHTTPS = enabled
+		res := CheckURL(context.Background(), secure.Client(), job, cfg)
+		if res.Result != nil {
+			t.Fatalf("downgrade reported broken: %+v", res.Result)
+		}
+		if !enabled {
+			if len(res.Warnings) != 0 {
+				t.Errorf("warnings without CheckHTTPS: %v", res.Warnings)
+			}
+			continue
+		}
+		want := result.HygieneWarning{
+			Kind:       result.HygieneHTTPSDowngrade,
+			URL:        job.URL,
+			Target:     plain.URL + "/landing",
+			SourcePage: job.SourcePage,
+		}
+		if len(res.Warnings) != 1 || res.Warnings[0] != want {
+			t.Errorf("warnings = %+v, want [%+v]", res.Warnings, want)
+		}
+	}
+}
+
+func TestHTTPSURL(t *testing.T) {
+	if got := httpsURL("http://example.com:8080/a?b=http://c"); got != "https://example.com:8080/a?b=http://c" {
+		t.Errorf("httpsURL() = %q", got)
+	}
+}
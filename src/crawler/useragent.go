@@ -0,0 +1,71 @@
+package crawler
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// HostUserAgent overrides the user agent for hosts matching Pattern.
+// Pattern uses path.Match glob syntax against the lowercase hostname,
+// e.g. "*.example.com" or "cdn.example.net".
+type HostUserAgent struct {
+	Pattern   string
+	UserAgent string
+}
+
+// userAgentSelector picks the user agent for each host. Host overrides take
+// precedence, then the rotation pool, then the single configured UserAgent.
+// A host keeps the same user agent for the whole crawl so robots.txt
+// matching and requests always agree on identity.
+type userAgentSelector struct {
+	fallback  string
+	pool      []string
+	overrides []HostUserAgent
+	next      atomic.Uint64
+	assigned  sync.Map // host string -> string
+}
+
+// newUserAgentSelector validates host patterns and builds a selector from cfg.
+func newUserAgentSelector(cfg Config) (*userAgentSelector, error) {
+	for _, override := range cfg.HostUserAgents {
+		if _, err := path.Match(override.Pattern, ""); err != nil {
+			return nil, fmt.Errorf("host user agent pattern %q: %w", override.Pattern, err)
+		}
+	}
+	return &userAgentSelector{
+		fallback:  cfg.UserAgent,
+		pool:      cfg.UserAgents,
+		overrides: cfg.HostUserAgents,
+	}, nil
+}
+
+// For returns the user agent to use for rawURL.
+func (s *userAgentSelector) For(rawURL string) string {
+	host := strings.ToLower(hostFromURL(rawURL))
+
+	for _, override := range s.overrides {
+		if matched, _ := path.Match(strings.ToLower(override.Pattern), host); matched {
+			return override.UserAgent
+		}
+	}
+
+	if len(s.pool) == 0 {
+		return s.fallback
+	}
+	if cached, ok := s.assigned.Load(host); ok {
+		if ua, ok := cached.(string); ok {
+			return ua
+		}
+	}
+	// Round-robin assignment on first sight; LoadOrStore keeps the first
+	// winner if two workers race on the same new host.
+	idx := (s.next.Add(1) - 1) % uint64(len(s.pool))
+	actual, _ := s.assigned.LoadOrStore(host, s.pool[idx])
+	if ua, ok := actual.(string); ok {
+		return ua
+	}
+	return s.pool[idx]
+}
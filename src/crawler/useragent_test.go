@@ -0,0 +1,71 @@
+package crawler
+
+import "testing"
+
+func TestUserAgentSelector_Fallback(t *testing.T) {
+	selector, err := newUserAgentSelector(Config{UserAgent: "default-bot"})
+	if err != nil {
+		t.Fatalf("newUserAgentSelector() error: %v", err)
+	}
+	if got := selector.For("https://example.com/page"); got != "default-bot" {
+		t.Errorf("For() = %q, want %q", got, "default-bot")
+	}
+}
+
+func TestUserAgentSelector_HostOverride(t *testing.T) {
+	selector, err := newUserAgentSelector(Config{
+		UserAgent:  "default-bot",
+		UserAgents: []string{"rotating-a", "rotating-b"},
+		HostUserAgents: []HostUserAgent{
+			{Pattern: "*.CDN.example.com", UserAgent: "cdn-bot"},
+			{Pattern: "api.example.com", UserAgent: "api-bot"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("newUserAgentSelector() error: %v", err)
+	}
+
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"https://img.cdn.example.com/a.png", "cdn-bot"},
+		{"https://api.example.com:8443/v1", "api-bot"},
+	}
+	for _, tt := range tests {
+		if got := selector.For(tt.url); got != tt.want {
+			t.Errorf("For(%q) = %q, want %q", tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestUserAgentSelector_RotationIsStickyPerHost(t *testing.T) {
+	selector, err := newUserAgentSelector(Config{
+		UserAgent:  "default-bot",
+		UserAgents: []string{"rotating-a", "rotating-b"},
+	})
+	if err != nil {
+		t.Fatalf("newUserAgentSelector() error: %v", err)
+	}
+
+	first := selector.For("https://one.example/")
+	second := selector.For("https://two.example/")
+	if first == second {
+		t.Errorf("expected different hosts to rotate, both got %q", first)
+	}
+	if again := selector.For("https://one.example/other"); again != first {
+		t.Errorf("expected host to keep %q, got %q", first, again)
+	}
+	if third := selector.For("https://three.example/"); third != first {
+		t.Errorf("expected rotation to wrap to %q, got %q", first, third)
+	}
+}
+
+func TestUserAgentSelector_InvalidPattern(t *testing.T) {
+	_, err := newUserAgentSelector(Config{
+		HostUserAgents: []HostUserAgent{{Pattern: "[invalid", UserAgent: "bot"}},
+	})
+	if err == nil {
+		t.Error("expected error for malformed pattern")
+	}
+}
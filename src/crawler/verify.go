@@ -0,0 +1,77 @@
+package crawler
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/lukemcguire/zombiecrawl/result"
+)
+
+// VerifyPolicy configures the optional re-check of broken links once the
+// crawl has finished. Links that succeed on the re-check are reported as
+// flaky rather than broken, filtering out transient blips.
+type VerifyPolicy struct {
+	Enabled     bool          // Run the verification pass
+	Delay       time.Duration // Wait before re-checking, giving blips time to clear
+	Concurrency int           // Parallel re-checks (default 2)
+}
+
+// defaultVerifyConcurrency keeps the verification pass gentle on hosts that
+// just failed; it is deliberately lower than the crawl concurrency.
+const defaultVerifyConcurrency = 2
+
+// verify re-checks each broken link once and splits them into links that are
+// still broken and links that recovered (flaky), both in their original order.
+// If ctx is cancelled, unchecked links are treated as still broken.
+func (c *Crawler) verify(ctx context.Context, broken []result.LinkResult) (stillBroken, flaky []result.LinkResult) {
+	if c.cfg.clock().Sleep(ctx, c.cfg.Verify.Delay) != nil {
+		return broken, nil
+	}
+
+	concurrency := c.cfg.Verify.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultVerifyConcurrency
+	}
+
+	recovered := make([]bool, len(broken))
+	slots := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, link := range broken {
+		if link.ErrorCategory == result.CategoryMalformedURL {
+			continue // Rejected as written; a re-check cannot fix it
+		}
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		wg.Go(func() {
+			defer func() { <-slots }()
+			if c.limiter.Wait(ctx) != nil {
+				return
+			}
+			job := CrawlJob{
+				URL:        link.URL,
+				SourcePage: link.SourcePage,
+				IsExternal: link.IsExternal,
+				UserAgent:  c.userAgents.For(link.URL),
+			}
+			res := CheckURL(ctx, c.client, job, c.cfg)
+			recovered[i] = res.Result == nil && ctx.Err() == nil
+		})
+	}
+	wg.Wait()
+
+	for i, link := range broken {
+		if recovered[i] {
+			flaky = append(flaky, link)
+			c.stats.forgive(link)
+		} else {
+			stillBroken = append(stillBroken, link)
+		}
+	}
+	return stillBroken, flaky
+}
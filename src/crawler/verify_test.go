@@ -0,0 +1,142 @@
+package crawler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/lukemcguire/zombiecrawl/result"
+)
+
+// newFlakyServer serves a page linking to /blip, which fails with 503 on its
+// first request only, and /dead, which always returns 404.
+func newFlakyServer() *httptest.Server {
+	var blipHits atomic.Int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = fmt.Fprint(w, `<a href="/blip">blip</a><a href="/dead">dead</a>`)
+	})
+	mux.HandleFunc("/blip", func(w http.ResponseWriter, r *http.Request) {
+		if blipHits.Add(1) == 1 {
+			http.Error(w, "busy", http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = fmt.Fprint(w, `<p>ok</p>`)
+	})
+	return httptest.NewServer(mux)
+}
+
+func TestRun_VerifyMarksRecoveredLinksFlaky(t *testing.T) {
+	ts := newFlakyServer()
+	defer ts.Close()
+
+	c, err := New(Config{
+		StartURL:    ts.URL,
+		Concurrency: 2,
+		Delay:       1,
+		RetryPolicy: RetryPolicy{MaxRetries: 0},
+		Verify:      VerifyPolicy{Enabled: true, Delay: 10 * time.Millisecond},
+	}, nil)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+
+	res, err := c.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run() error: %v", err)
+	}
+
+	if len(res.BrokenLinks) != 1 || res.BrokenLinks[0].URL != ts.URL+"/dead" {
+		t.Errorf("BrokenLinks = %+v, want only /dead", res.BrokenLinks)
+	}
+	if len(res.Flaky) != 1 || res.Flaky[0].URL != ts.URL+"/blip" {
+		t.Errorf("Flaky = %+v, want only /blip", res.Flaky)
+	}
+	if res.Stats.BrokenCount != 1 || res.Stats.FlakyCount != 1 {
+		t.Errorf("BrokenCount = %d, FlakyCount = %d; want 1 and 1", res.Stats.BrokenCount, res.Stats.FlakyCount)
+	}
+	if _, ok := res.Stats.ByCategory[result.Category5xx]; ok {
+		t.Errorf("ByCategory still counts the flaky 5xx: %v", res.Stats.ByCategory)
+	}
+}
+
+func TestRun_VerifyDisabledByDefault(t *testing.T) {
+	ts := newFlakyServer()
+	defer ts.Close()
+
+	c, err := New(Config{StartURL: ts.URL, Delay: 1, RetryPolicy: RetryPolicy{MaxRetries: 0}}, nil)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	res, err := c.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run() error: %v", err)
+	}
+	if len(res.BrokenLinks) != 2 || len(res.Flaky) != 0 {
+		t.Errorf("got %d broken and %d flaky, want 2 and 0", len(res.BrokenLinks), len(res.Flaky))
+	}
+}
+
+func TestVerify_CancelledDuringDelayKeepsLinksBroken(t *testing.T) {
+	c, err := New(Config{StartURL: "http://example.com", Verify: VerifyPolicy{Enabled: true, Delay: time.Hour}}, nil)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	defer func() { _ = c.visited.Close() }()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	broken := []result.LinkResult{{URL: "http://example.com/a"}, {URL: "http://example.com/b"}}
+
+	stillBroken, flaky := c.verify(ctx, broken)
+	if len(stillBroken) != 2 || len(flaky) != 0 {
+		t.Errorf("got %d still broken and %d flaky, want 2 and 0", len(stillBroken), len(flaky))
+	}
+}
+
+func TestRun_OnBrokenLink(t *testing.T) {
+	for _, verify := range []bool{false, true} {
+		ts := newFlakyServer()
+		var confirmed []string
+		c, err := New(Config{
+			StartURL:     ts.URL,
+			Concurrency:  2,
+			Delay:        1,
+			RetryPolicy:  RetryPolicy{MaxRetries: 0},
+			Verify:       VerifyPolicy{Enabled: verify, Delay: 10 * time.Millisecond},
+			OnBrokenLink: func(link result.LinkResult) { confirmed = append(confirmed, link.URL) },
+		}, nil)
+		if err != nil {
+			t.Fatalf("New() error: %v", err)
+		}
+		res, err := c.Run(context.Background())
+		ts.Close()
+		if err != nil {
+			t.Fatalf("Run() error: %v", err)
+		}
+
+		// With verification the recovered /blip is never reported
+		var want []string
+		for _, link := range res.BrokenLinks {
+			want = append(want, link.URL)
+		}
+		slices.Sort(confirmed)
+		slices.Sort(want)
+		wantCount := 2
+		if verify {
+			wantCount = 1
+		}
+		if !slices.Equal(confirmed, want) || len(want) != wantCount {
+			t.Errorf("verify %v: OnBrokenLink got %v, want %v", verify, confirmed, want)
+		}
+	}
+}
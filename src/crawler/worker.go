@@ -1,16 +1,23 @@
 package crawler
 
 import (
+	"bytes"
+	"cmp"
 	"context"
 	"errors"
 	"fmt"
+	"io"
+	"log/slog"
 	"net"
 	"net/http"
 	"net/url"
+	"slices"
 	"strings"
+	"syscall"
 	"time"
 
 	"github.com/lukemcguire/zombiecrawl/result"
+	"github.com/lukemcguire/zombiecrawl/urlutil"
 )
 
 // isBinaryContentType returns true if the content type indicates a binary file
@@ -83,10 +90,10 @@ func formatVerboseError(err error, job CrawlJob, cfg Config) string {
 				return fmt.Sprintf("DNS lookup failed for %s: %s", dnsErr.Name, dnsErr.Err)
 			}
 			if errors.As(urlErr.Err, &opErr) {
-				return formatVerboseOpError(opErr, job.URL, cfg)
+				return formatVerboseOpError(opErr, job, cfg)
 			}
 			if errors.Is(urlErr.Err, context.DeadlineExceeded) {
-				return fmt.Sprintf("Request timed out after %s (url: %s)", cfg.RequestTimeout, job.URL)
+				return fmt.Sprintf("Request timed out after %s (url: %s)", cfg.requestTimeout(job), job.URL)
 			}
 		}
 	}
@@ -98,27 +105,37 @@ func formatVerboseError(err error, job CrawlJob, cfg Config) string {
 
 	// Direct net.OpError
 	if errors.As(err, &opErr) {
-		return formatVerboseOpError(opErr, job.URL, cfg)
+		return formatVerboseOpError(opErr, job, cfg)
+	}
+
+	// Transport phase timeouts
+	if result.IsConnectTimeout(err) {
+		// http.DefaultTransport allows 10s unless configured
+		return fmt.Sprintf("TLS handshake timed out after %s (url: %s)", cmp.Or(cfg.TLSHandshakeTimeout, 10*time.Second), job.URL)
+	}
+	if strings.Contains(baseMsg, "timeout awaiting response headers") {
+		return fmt.Sprintf("No response headers within %s (url: %s)", cfg.ResponseHeaderTimeout, job.URL)
 	}
 
 	// Timeout via net.Error interface
 	if errors.As(err, &netErr) && netErr.Timeout() {
-		return fmt.Sprintf("Request timed out after %s (url: %s)", cfg.RequestTimeout, job.URL)
+		return fmt.Sprintf("Request timed out after %s (url: %s)", cfg.requestTimeout(job), job.URL)
 	}
 
 	// Context deadline exceeded
 	if errors.Is(err, context.DeadlineExceeded) {
-		return fmt.Sprintf("Request timed out after %s (url: %s)", cfg.RequestTimeout, job.URL)
+		return fmt.Sprintf("Request timed out after %s (url: %s)", cfg.requestTimeout(job), job.URL)
 	}
 
 	return baseMsg
 }
 
 // formatVerboseOpError formats net.OpError with detailed diagnostics.
-func formatVerboseOpError(opErr *net.OpError, urlStr string, cfg Config) string {
+func formatVerboseOpError(opErr *net.OpError, job CrawlJob, cfg Config) string {
 	if opErr == nil {
 		return ""
 	}
+	urlStr := job.URL
 
 	// Extract host:port from the address
 	addr := "unknown"
@@ -127,13 +144,17 @@ func formatVerboseOpError(opErr *net.OpError, urlStr string, cfg Config) string
 	}
 
 	// Check for connection refused
-	if opErr.Op == "dial" && strings.Contains(opErr.Error(), "connection refused") {
+	if errors.Is(opErr, syscall.ECONNREFUSED) {
 		return fmt.Sprintf("Connection refused to %s (url: %s)", addr, urlStr)
 	}
 
 	// Check for timeout
 	if opErr.Timeout() {
-		return fmt.Sprintf("Network operation '%s' timed out after %s (addr: %s, url: %s)", opErr.Op, cfg.RequestTimeout, addr, urlStr)
+		limit := cfg.requestTimeout(job)
+		if opErr.Op == "dial" && cfg.DialTimeout > 0 {
+			limit = min(limit, cfg.DialTimeout)
+		}
+		return fmt.Sprintf("Network operation '%s' timed out after %s (addr: %s, url: %s)", opErr.Op, limit, addr, urlStr)
 	}
 
 	// Generic network error with context
@@ -150,24 +171,317 @@ func getErrorMessage(err error, job CrawlJob, cfg Config) string {
 	}
 	return err.Error()
 }
+
+// Config holds the settings for a crawl.
 type Config struct {
-	StartURL        string        // The starting URL for the crawl
-	Concurrency     int           // Number of concurrent workers (default 17)
-	RequestTimeout  time.Duration // Per-request timeout (default 10s)
-	Delay           int           // Delay between requests in milliseconds (default 100)
-	UserAgent       string        // HTTP User-Agent header (default "zombiecrawl/1.0")
-	RetryPolicy     RetryPolicy   // Retry policy for failed requests
-	MaxDepth        int           // Maximum crawl depth (0 = unlimited)
-	DisableAutoTune bool          // Disable adaptive rate limiting (use fixed rate from Delay)
-	VerboseNetwork  bool          // Enable verbose network error diagnostics
+	StartURL         string              // The starting URL for the crawl
+	Concurrency      int                 // Number of concurrent workers (default 17)
+	MaxPerHost       int                 // Requests in flight to any one host at once (0 = only Concurrency limits them)
+	RequestTimeout   time.Duration       // Overall deadline per request, including redirects and reading the body (default 10s)
+	TimeoutOverrides []TimeoutOverride   // RequestTimeout for URLs matching a pattern, first match wins
+	QueueTimeout     time.Duration       // Report jobs not started this long after being queued as queued too long (0 = no limit)
+	SlowRequest      time.Duration       // Report checks running longer than this in progress events (0 = DefaultSlowRequest)
+	HardTimeout      time.Duration       // Cancel checks, including retries, still running after this (0 = no limit)
+	Delay            int                 // Delay between requests in milliseconds (default 100)
+	RatePerMinute    float64             // Fixed rate in requests per minute; overrides Delay and disables auto-tuning (0 = unset)
+	Burst            int                 // Requests allowed back-to-back (0 = rate rounded up)
+	MinRate          float64             // Lowest adaptive rate in requests per second (0 = DefaultMinRate)
+	MaxRate          float64             // Highest adaptive rate in requests per second (0 = DefaultMaxRate)
+	UserAgent        string              // HTTP User-Agent header (default "zombiecrawl/1.0")
+	UserAgents       []string            // Rotation pool; each host is assigned one round-robin (overrides UserAgent)
+	HostUserAgents   []HostUserAgent     // Per-host-pattern user agents, first match wins (overrides UserAgents)
+	HostConfigs      []HostConfig        // Per-host-pattern rate, header, auth, retry, and robots overrides, first match wins
+	BlockedHosts     []string            // Host patterns never requested; their links are reported in Result.Skipped
+	Rewrites         []RewriteRule       // Applied in order to links found on pages and in sitemaps before they are queued
+	SyntheticChecks  []SyntheticCheck    // Requests declared in configuration, checked after the crawl and reported with its links
+	ExpectedStatuses []StatusExpectation // Statuses asserted for URLs matching a pattern, first match wins
+	ContentChecks    []ContentCheck      // Text and patterns required or forbidden on crawled pages, reported in Result.ContentChecks
+	RetryPolicy      RetryPolicy         // Retry policy for failed requests
+	MaxRedirects     int                 // Redirect hops followed per check before it fails (0 = DefaultMaxRedirects)
+	FollowRedirects  RedirectPolicy      // Which redirects to follow: RedirectAlways (default), RedirectSameHost, or RedirectNever
+	MaxDepth         int                 // Maximum crawl depth (0 = unlimited)
+	MaxLinksPerPage  int                 // Links queued from any one page; the rest are reported in Result.Truncated (0 = unlimited)
+	Strategy         Strategy            // Crawl order: StrategyBFS (default), StrategyDFS, or StrategyRandom
+	RobotsCacheSize  int                 // Max hosts whose robots.txt is cached, least recently used evicted first (0 = DefaultRobotsCacheSize)
+	Sitemap          bool                // Also seed the crawl with pages from the site's sitemaps (robots.txt Sitemap: directives, else /sitemap.xml)
+	DisableAutoTune  bool                // Disable adaptive rate limiting (use fixed rate from Delay)
+	VerboseNetwork   bool                // Enable verbose network error diagnostics
+
+	Accept         string // HTTP Accept header sent with every check (empty = Go default)
+	AcceptLanguage string // HTTP Accept-Language header sent with every check (empty = none)
+	SendReferer    bool   // Send the source page as the Referer header (some servers require it)
+
+	// CheckHTTPS probes the https:// equivalent of every working http://
+	// internal page once the crawl has finished, and flags links that
+	// redirect from https to http. Pages crawled over both schemes, each
+	// served without redirecting to the other, are flagged as duplicates
+	// instead of probed. All are reported in Result.Hygiene.
+	CheckHTTPS bool
+
+	// FoldSchemes treats the http:// and https:// forms of an internal URL
+	// as one page, so only the form found first is crawled.
+	FoldSchemes bool
+
+	// DedupIgnoreQuery treats internal URLs that differ only in their
+	// query string as one page, and DedupRules do so for the URLs and query
+	// parameters they name, first match wins and ahead of DedupIgnoreQuery.
+	// Only the first URL found is crawled. How many URLs each folded is
+	// reported in CrawlStats.DedupFolded.
+	DedupIgnoreQuery bool
+	DedupRules       []DedupRule
+
+	// StripSessionIDs removes session ID and ad click ID parameters, such
+	// as PHPSESSID, ;jsessionid= path parameters, and gclid, from the start
+	// URL and from links found on pages and in sitemaps, so that pages are
+	// requested, deduplicated, and reported without them. See
+	// urlutil.IsSessionParam.
+	StripSessionIDs bool
+
+	// CheckTrailingSlash requests every internal page checked with and
+	// without a trailing slash once the crawl has finished, and flags pages
+	// where only one form works or both are served without a redirect.
+	// Both are reported in Result.Hygiene.
+	CheckTrailingSlash bool
+
+	// CheckFragments flags links from a page to a fragment of itself, such
+	// as href="#pricing", when no element on the page has that id. It needs
+	// no extra requests. Missing targets are reported in Result.Hygiene.
+	CheckFragments bool
+
+	// CheckFeeds fetches the RSS and Atom feeds advertised by crawled pages
+	// with <link rel="alternate"> once the crawl has finished, and checks
+	// that each parses and that the link of every entry works. Feeds and
+	// their dead entries are reported in Result.Feeds, apart from the broken
+	// links found on pages.
+	CheckFeeds bool
+
+	// CheckPDFs reads the first bytes of every link served as
+	// application/pdf and reports the ones without a %PDF header, such as
+	// HTML error pages sent with a PDF content type, as broken links in
+	// CategoryCorruptPDF. External PDFs get an extra ranged GET.
+	CheckPDFs bool
+
+	// SiteStructure counts links between internal pages and reports pages
+	// nothing links to and pages with more than MaxOutboundLinks links
+	// (0 = DefaultMaxOutboundLinks) in Result.Structure.
+	SiteStructure    bool
+	MaxOutboundLinks int
+
+	// Accessibility audits crawled pages for links without text, images
+	// without alt attributes, and links whose text is a raw URL, reported in
+	// Result.Accessibility.
+	Accessibility bool
+
+	// SEO reports crawled pages with a missing or duplicate title, a meta
+	// description too long for search results, hreflang alternates that are
+	// broken or do not link back, or broken og:image, og:url, or
+	// twitter:image URLs, in Result.SEO. Alternates and social URLs that
+	// were not crawled are requested once the crawl has finished.
+	SEO bool
+
+	// LinkHygiene flags links on crawled pages that work but are fragile or
+	// insecure: http links on https pages, protocol-relative URLs, and links
+	// to bare IP addresses. They are reported as warnings in Result.Hygiene.
+	LinkHygiene bool
+
+	// StrictURLs rejects links written with control characters, whitespace,
+	// backslashes, invalid or double percent-encoding, or credentials instead
+	// of guessing what they mean. They are not followed and are reported as
+	// broken with result.CategoryMalformedURL.
+	StrictURLs bool
+
+	// RespectExternalRobots also honors external hosts' robots.txt when
+	// validating their links. By default only internal links are checked.
+	RespectExternalRobots bool
+
+	// RobotsAgent is the user-agent token matched against robots.txt groups,
+	// e.g. "Googlebot". Empty uses the request user agent.
+	RobotsAgent string
+
+	// IgnoreRobots skips robots.txt entirely, as a browser would.
+	IgnoreRobots bool
+
+	// StrictRobots evaluates robots.txt with Google's semantics (longest
+	// match wins, Allow wins ties, "*" and "$" wildcards) instead of the
+	// parsing library's defaults.
+	StrictRobots bool
+
+	// RobotsTxt, if set, is used as the robots.txt of the start URL's host
+	// instead of fetching it, to test rules before deploying them.
+	RobotsTxt []byte
+
+	// Verify re-checks broken links after the crawl and reports the ones that
+	// recover as flaky. Disabled by default.
+	Verify VerifyPolicy
+
+	// RetryClassifier decides whether a failed check is retried.
+	// Nil uses DefaultRetryClassifier.
+	RetryClassifier RetryClassifier
+
+	// ExternalCache, when set, skips external URLs that passed a check
+	// within its TTL and records the verdicts of the ones that are checked.
+	ExternalCache *ExternalCache
+
+	// Pool, when set, caps requests in flight across every crawler sharing
+	// it. Concurrency still bounds this crawler's own workers.
+	Pool *Pool
+
+	// Visited tracks which URLs have been queued. Nil uses a disk-backed
+	// VisitedTracker, which the crawler closes when it finishes; a supplied
+	// store, such as MemoryVisitedStore or SQLiteStore, is left open for the
+	// caller.
+	Visited VisitedStore
+
+	// Results, when set, receives each broken link as it is found.
+	Results ResultSink
+
+	// OnBrokenLink, when set, is called with each broken link once it is
+	// confirmed, after retries: as it is found, or with Verify once the
+	// re-check still fails. Every link of Result.BrokenLinks is passed once,
+	// from one goroutine at a time; the crawl waits for each call.
+	OnBrokenLink func(result.LinkResult)
+
+	// OnProgress, when set, is called every ProgressInterval (0 =
+	// DefaultProgressInterval) with a snapshot of the crawl, and once more
+	// with Progress.Done set when Run finishes. Calls come from one
+	// goroutine at a time.
+	OnProgress       func(Progress)
+	ProgressInterval time.Duration
+
+	// Scrub, when set, redacts named query parameters, such as tokens, from
+	// the URLs the crawl reports: the result, links sent to Results and
+	// OnBrokenLink, progress events, log records, plans, comparisons, and
+	// the queue recorded in State. HAR recording is redacted separately by
+	// HARRecorder.SetScrubber. Requests still use the full URLs, and so do
+	// ExternalCache entries and what OnRequest, OnResponse, Middleware, and
+	// Plugins are given for each request.
+	Scrub *result.Scrubber
+
+	// Archive, when set, looks up Wayback Machine snapshots of broken
+	// external links once the crawl has finished.
+	Archive *ArchiveLookup
+
+	// LosslessEvents delivers every progress event even if a slow consumer
+	// stalls the crawl. By default the oldest undelivered events are dropped
+	// once a small queue fills, so the crawl never waits on its consumer.
+	LosslessEvents bool
+
+	// DialTimeout, TLSHandshakeTimeout, and ResponseHeaderTimeout limit the
+	// phases of a request within RequestTimeout, so that failing to connect
+	// (CategoryConnectTimeout) is told apart from a slow response
+	// (CategoryTimeout). Zero leaves a phase limited only by RequestTimeout
+	// (and Go's 10s TLS handshake default). New applies them to Transport.
+	DialTimeout           time.Duration
+	TLSHandshakeTimeout   time.Duration
+	ResponseHeaderTimeout time.Duration
+
+	// MaxStreamBytes and MaxStreamTime cap reading an internal page of
+	// unknown length, such as a long-poll or chunked stream that never
+	// ends, for links (0 = DefaultMaxStreamBytes, DefaultMaxStreamTime).
+	// Reading stops short of the request deadline, and a page cut short is
+	// reported in Result.Streaming with the links read so far followed,
+	// instead of timing out as broken. Event streams are never read.
+	MaxStreamBytes int64
+	MaxStreamTime  time.Duration
+
+	// MaxPageBytes caps the body of an internal page read for links.
+	// Larger pages are not parsed and are reported broken with
+	// result.CategoryTooLarge (0 = no limit).
+	MaxPageBytes int64
+
+	// IPVersion restricts connections to IPv4 or IPv6, for networks where
+	// the other is broken ("" = IPAuto). New applies it to Transport.
+	IPVersion IPVersion
+
+	// DNSServer sends every DNS query to this resolver ("ip:port") instead
+	// of the system's, which may filter domains ("" = system resolver).
+	// New validates it with ParseDNSServer and applies it to Transport.
+	DNSServer string
+
+	// Bandwidth throttles reading response bodies; share one between
+	// crawlers to cap their combined rate. Nil reads at full speed.
+	Bandwidth *Bandwidth
+
+	// RetryBudget caps the retries spent on each host; share one between
+	// crawlers to cap their combined retries. Nil retries every failure
+	// RetryPolicy allows.
+	RetryBudget *RetryBudget
+
+	// HostOverrides pin hosts to addresses, e.g. to crawl a staging host
+	// that is not in DNS yet. New applies them to Transport.
+	HostOverrides []HostOverride
+
+	// Transport carries every request of the crawl. Nil uses
+	// http.DefaultTransport; a ReplayTransport crawls from a HAR archive.
+	Transport http.RoundTripper
+
+	// HAR, when set, records every request and response of the crawl for
+	// export in HAR format.
+	HAR *HARRecorder
+
+	// State, when set, records the crawl queue when the crawl stops, for
+	// saving and inspecting later.
+	State *StateRecorder
+
+	// OnRequest and OnResponse, when set, are called around every request
+	// of the crawl, e.g. to record metrics, keep an audit log, or add
+	// headers. They are called from many goroutines at once and should
+	// return quickly, since the request waits for them.
+	OnRequest  func(*RequestTrace)
+	OnResponse func(*RequestTrace)
+
+	// ClassifyLink, when set, decides how each discovered link is crawled,
+	// e.g. to crawl a CDN host's pages as part of the site. Returning
+	// LinkDefault keeps the crawler's own decision. It is called from one
+	// goroutine at a time.
+	ClassifyLink func(url, sourcePage string) LinkClass
+
+	// Plugins filter the links of each page, decide which links are
+	// queued, and receive the result; see Plugin and CommandPlugin.
+	Plugins []Plugin
+
+	// Middleware wraps the check of every crawled link, the first
+	// outermost; see WithHeaders, WithBasicAuth, CacheResults, Throttle,
+	// and LogFetches.
+	Middleware []Middleware
+
+	// Deterministic makes repeated crawls of an unchanged site produce the
+	// same report: New forces a single worker, discovered links are queued
+	// in sorted order, StrategyRandom uses a fixed seed, and reported
+	// timings come from a clock fixed at DeterministicEpoch.
+	Deterministic bool
+
+	// Clock tells the time and waits out retry backoff and rate limits.
+	// Nil uses the system clock.
+	Clock Clock
+
+	// Logger receives structured records about the crawl: checks and
+	// retries at debug level, broken and skipped links at info, and
+	// problems the crawl works around (robots.txt, sitemaps, result sinks)
+	// at warn and error. Records about a URL carry url, host, and depth
+	// attributes. Nil discards them.
+	Logger *slog.Logger
+
+	// RunID identifies the crawl in log records, events, and its result,
+	// so they can be matched with each other and with stored runs. Crawls
+	// started together may share one. New sets a NewRunID if it is empty,
+	// or DeterministicRunID with Deterministic.
+	RunID string
+
+	timings Clock // Fixed clock for reported timings, with Deterministic
 }
 
 // CrawlJob represents a URL to be checked.
 type CrawlJob struct {
-	URL        string // The URL to check
-	SourcePage string // The page where this link was found
-	IsExternal bool   // Whether this is an external link (validate only, don't crawl)
-	Depth      int    // Current crawl depth (0 = start URL)
+	URL         string // The URL to check
+	SourcePage  string // The page where this link was found
+	SourceTitle string // The title of SourcePage, if known
+	IsExternal  bool   // Whether this is an external link (validate only, don't crawl)
+	Depth       int    // Current crawl depth (0 = start URL)
+	UserAgent   string // User agent selected for this URL's host
+	Priority    bool   // Crawled ahead of jobs without priority (LinkPriority)
+
+	Queued time.Time // When the job entered the crawl queue (set by the frontier)
 }
 
 // CrawlResult represents the result of checking a URL.
@@ -175,7 +489,123 @@ type CrawlResult struct {
 	Job    CrawlJob           // The original job
 	Links  []string           // Discovered links (internal pages only)
 	Result *result.LinkResult // Broken link info (if broken)
-	Err    error              // Any error that occurred
+
+	Warnings      []result.HygieneWarning     // Link hygiene problems on the page (with Config.LinkHygiene)
+	Malformed     []result.LinkResult         // Links rejected as suspicious (with Config.StrictURLs)
+	Accessibility []result.AccessibilityIssue // Markup problems on the page (with Config.Accessibility)
+	Content       []result.ContentFailure     // Config.ContentChecks the page failed
+	Meta          *PageMeta                   // Title, meta description, and head links (internal HTML pages only)
+	Err           error                       // Any error that occurred, wrapping the underlying net/url/context error
+
+	StatusCode int    // HTTP status of the final response (0 if none was received)
+	Redirected bool   // The final response came after following redirects
+	Cached     bool   // Verdict came from the external cache; no request was made
+	Streaming  string // Why the response was read only in part as a stream, if it was
+
+	// Internal pages: the response's validators, and whether the server
+	// answered 304 Not Modified, in which case Links came from the cache.
+	ETag         string
+	LastModified string
+	NotModified  bool
+	NoArchive    bool // The page asks not to be stored (noarchive, nosnippet, or none)
+
+	Attempts int           // Number of requests made, including retries (set by CheckURLWithRetry)
+	Bytes    int64         // Response body bytes read
+	Duration time.Duration // Wall time of the final attempt
+}
+
+// countingReader counts bytes read through it.
+type countingReader struct {
+	reader io.Reader
+	count  int64
+}
+
+// Read implements io.Reader.
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.reader.Read(p)
+	c.count += int64(n)
+	return n, err
+}
+
+// fetchFailed records a request-level failure on res. The original error is
+// kept wrapped in res.Err so retry and classification logic can inspect it
+// with errors.Is/errors.As instead of matching message text.
+func fetchFailed(res *CrawlResult, err error, isRedirectLoop bool, cfg Config) {
+	res.Err = fmt.Errorf("fetch %s: %w", res.Job.URL, err)
+	res.Result = &result.LinkResult{
+		URL:           res.Job.URL,
+		SourcePage:    res.Job.SourcePage,
+		IsExternal:    res.Job.IsExternal,
+		Error:         getErrorMessage(err, res.Job, cfg),
+		ErrorCategory: result.ClassifyError(err, 0, isRedirectLoop),
+	}
+}
+
+// statusFailed records a broken link for a response with an error status or
+// a detected redirect loop, capturing debug headers from the response.
+func statusFailed(res *CrawlResult, resp *http.Response, isRedirectLoop bool) {
+	errMsg := ""
+	if isRedirectLoop {
+		errMsg = "redirect loop detected"
+	}
+	res.Result = &result.LinkResult{
+		URL:           res.Job.URL,
+		StatusCode:    resp.StatusCode,
+		SourcePage:    res.Job.SourcePage,
+		IsExternal:    res.Job.IsExternal,
+		Error:         errMsg,
+		ErrorCategory: result.ClassifyError(nil, resp.StatusCode, isRedirectLoop),
+		Headers:       captureHeaders(resp.Header),
+	}
+}
+
+// debugHeaders lists the response headers kept on broken links. They identify
+// the server, CDN, or WAF that produced the failure without re-requesting the URL.
+var debugHeaders = []string{"Server", "Content-Type", "Location", "Retry-After", "CF-Ray"}
+
+// captureHeaders returns the debugHeaders present in h, or nil if none are set.
+func captureHeaders(h http.Header) map[string]string {
+	var captured map[string]string
+	for _, name := range debugHeaders {
+		if value := h.Get(name); value != "" {
+			if captured == nil {
+				captured = make(map[string]string, len(debugHeaders))
+			}
+			captured[http.CanonicalHeaderKey(name)] = value
+		}
+	}
+	return captured
+}
+
+// newRequest builds a request for job, applying the identity and content
+// negotiation headers from cfg so servers see the configured user agent and
+// language- or format-gated pages return the right variant.
+func newRequest(ctx context.Context, method string, job CrawlJob, cfg Config) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, job.URL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create %s request: %w", method, err)
+	}
+	userAgent := job.UserAgent
+	if userAgent == "" {
+		userAgent = cfg.UserAgent
+	}
+	if userAgent != "" {
+		req.Header.Set("User-Agent", userAgent)
+	}
+	if cfg.SendReferer && job.SourcePage != "" {
+		req.Header.Set("Referer", job.SourcePage)
+	}
+	if cfg.Accept != "" {
+		req.Header.Set("Accept", cfg.Accept)
+	}
+	if cfg.AcceptLanguage != "" {
+		req.Header.Set("Accept-Language", cfg.AcceptLanguage)
+	}
+	cfg.applyHostHeaders(req)
+	for name, values := range headersFrom(ctx) {
+		req.Header[name] = values
+	}
+	return req, nil
 }
 
 // CheckURL fetches a URL and returns the result.
@@ -183,37 +613,32 @@ type CrawlResult struct {
 // For internal links: GET request (need body for link extraction).
 func CheckURL(ctx context.Context, client *http.Client, job CrawlJob, cfg Config) (res CrawlResult) {
 	res.Job = job
+	started := cfg.now()
+	defer func() { res.Duration = cfg.since(started) }()
 
-	// Create per-request context with timeout
-	reqCtx, cancel := context.WithTimeout(ctx, cfg.RequestTimeout)
+	// The per-request deadline derives from the crawl context, so a single
+	// deadline covers every redirect hop and reading the body, and the
+	// request ends early when the crawl does.
+	reqCtx, cancel := context.WithTimeout(ctx, cfg.requestTimeout(job))
 	defer cancel()
 
-	// Track redirect loop detection
-	var isRedirectLoop bool
-	var visitedInChain []string
-
-	// Create per-request client with redirect loop detection
+	// Enforce the redirect policy and track loop detection
+	redirects := newRedirectTracker(cfg)
 	loopClient := &http.Client{
-		Timeout: cfg.RequestTimeout,
-		CheckRedirect: func(req *http.Request, via []*http.Request) error {
-			currentURL := req.URL.String()
-
-			// Check if we've seen this URL in the current chain
-			for _, visitedURL := range visitedInChain {
-				if visitedURL == currentURL {
-					isRedirectLoop = true
-					return http.ErrUseLastResponse
-				}
-			}
-			visitedInChain = append(visitedInChain, currentURL)
-
-			// Also limit total redirects (10 is Go default)
-			if len(via) >= 10 {
-				isRedirectLoop = true
-				return errors.New("stopped after 10 redirects")
+		Transport:     cfg.HAR.wrap(cmp.Or(client.Transport, cfg.Transport), cfg.timingClock()),
+		CheckRedirect: redirects.check,
+	}
+	if cfg.CheckHTTPS {
+		defer func() {
+			if redirects.downgrade != "" {
+				res.Warnings = append(res.Warnings, result.HygieneWarning{
+					Kind:       result.HygieneHTTPSDowngrade,
+					URL:        job.URL,
+					Target:     redirects.downgrade,
+					SourcePage: job.SourcePage,
+				})
 			}
-			return nil
-		},
+		}()
 	}
 
 	var resp *http.Response
@@ -221,28 +646,15 @@ func CheckURL(ctx context.Context, client *http.Client, job CrawlJob, cfg Config
 
 	if job.IsExternal {
 		// External link: try HEAD first
-		req, reqErr := http.NewRequestWithContext(reqCtx, http.MethodHead, job.URL, nil)
+		req, reqErr := newRequest(reqCtx, http.MethodHead, job, cfg)
 		if reqErr != nil {
-			res.Result = &result.LinkResult{
-				URL:           job.URL,
-				SourcePage:    job.SourcePage,
-				IsExternal:    true,
-				Error:         getErrorMessage(reqErr, job, cfg),
-				ErrorCategory: result.ClassifyError(reqErr, 0, false),
-			}
+			fetchFailed(&res, reqErr, false, cfg)
 			return
 		}
 
 		resp, err = loopClient.Do(req)
 		if err != nil {
-			cat := result.ClassifyError(err, 0, isRedirectLoop)
-			res.Result = &result.LinkResult{
-				URL:           job.URL,
-				SourcePage:    job.SourcePage,
-				IsExternal:    true,
-				Error:         getErrorMessage(err, job, cfg),
-				ErrorCategory: cat,
-			}
+			fetchFailed(&res, err, redirects.loop, cfg)
 			return
 		}
 		defer func() {
@@ -253,30 +665,16 @@ func CheckURL(ctx context.Context, client *http.Client, job CrawlJob, cfg Config
 
 		// If HEAD returns 405 Method Not Allowed, fall back to GET
 		if resp.StatusCode == http.StatusMethodNotAllowed {
-			getReq, getErr := http.NewRequestWithContext(reqCtx, http.MethodGet, job.URL, nil)
+			getReq, getErr := newRequest(reqCtx, http.MethodGet, job, cfg)
 			if getErr != nil {
-				res.Result = &result.LinkResult{
-					URL:           job.URL,
-					SourcePage:    job.SourcePage,
-					IsExternal:    true,
-					Error:         getErrorMessage(getErr, job, cfg),
-					ErrorCategory: result.ClassifyError(getErr, 0, false),
-				}
+				fetchFailed(&res, getErr, false, cfg)
 				return
 			}
 			// Reset loop detection for new request
-			isRedirectLoop = false
-			visitedInChain = nil
+			redirects.reset()
 			resp, err = loopClient.Do(getReq)
 			if err != nil {
-				cat := result.ClassifyError(err, 0, isRedirectLoop)
-				res.Result = &result.LinkResult{
-					URL:           job.URL,
-					SourcePage:    job.SourcePage,
-					IsExternal:    true,
-					Error:         getErrorMessage(err, job, cfg),
-					ErrorCategory: cat,
-				}
+				fetchFailed(&res, err, redirects.loop, cfg)
 				return
 			}
 			defer func() {
@@ -288,49 +686,60 @@ func CheckURL(ctx context.Context, client *http.Client, job CrawlJob, cfg Config
 
 		// Check status for external link
 		status := resp.StatusCode
-		if status >= 400 || isRedirectLoop {
-			errMsg := ""
-			if isRedirectLoop {
-				errMsg = "redirect loop detected"
-			}
-			res.Result = &result.LinkResult{
-				URL:           job.URL,
-				StatusCode:    status,
-				SourcePage:    job.SourcePage,
-				IsExternal:    true,
-				Error:         errMsg,
-				ErrorCategory: result.ClassifyError(nil, status, isRedirectLoop),
+		res.StatusCode = status
+		if expected := cfg.expectedStatuses(job); expected != nil && !redirects.loop {
+			if !slices.Contains(expected, status) {
+				unexpectedStatusFailed(&res, resp, expected)
 			}
 			return
 		}
+		if status >= 400 || redirects.loop {
+			statusFailed(&res, resp, redirects.loop)
+			return
+		}
+
+		if isEventStream(resp.Header.Get("Content-Type")) {
+			res.Streaming = streamEventStream
+		}
+
+		// A PDF must start with a PDF header, which HEAD cannot show
+		if cfg.CheckPDFs && isPDF(resp.Header.Get("Content-Type")) {
+			if resp.Request.Method == http.MethodGet {
+				checkPDFBody(reqCtx, &res, resp, cfg)
+			} else {
+				redirects.reset()
+				checkExternalPDF(reqCtx, loopClient, &res, cfg)
+			}
+		}
 
 		// External link is valid
 		return
 	}
 
-	// Internal link: GET request
-	req, reqErr := http.NewRequestWithContext(reqCtx, http.MethodGet, job.URL, nil)
+	// Internal link: GET request, which a streaming body may end early
+	readCtx, stopReading := context.WithCancel(reqCtx)
+	defer stopReading()
+	req, reqErr := newRequest(readCtx, http.MethodGet, job, cfg)
 	if reqErr != nil {
-		res.Result = &result.LinkResult{
-			URL:           job.URL,
-			SourcePage:    job.SourcePage,
-			IsExternal:    false,
-			Error:         getErrorMessage(reqErr, job, cfg),
-			ErrorCategory: result.ClassifyError(reqErr, 0, false),
-		}
+		fetchFailed(&res, reqErr, false, cfg)
 		return
 	}
+	// Revalidate pages seen on an earlier run. Audits and strict URL checks
+	// need the body, so pages are always downloaded when one is enabled.
+	cached, revalidate := cfg.ExternalCache.page(job.URL)
+	revalidate = revalidate && !cfg.StrictURLs && !cfg.LinkHygiene && !cfg.Accessibility && !cfg.SEO && !cfg.CheckFragments && !cfg.CheckFeeds && len(cfg.ContentChecks) == 0
+	if revalidate {
+		if cached.ETag != "" {
+			req.Header.Set("If-None-Match", cached.ETag)
+		}
+		if cached.LastModified != "" {
+			req.Header.Set("If-Modified-Since", cached.LastModified)
+		}
+	}
 
 	resp, err = loopClient.Do(req)
 	if err != nil {
-		cat := result.ClassifyError(err, 0, isRedirectLoop)
-		res.Result = &result.LinkResult{
-			URL:           job.URL,
-			SourcePage:    job.SourcePage,
-			IsExternal:    false,
-			Error:         getErrorMessage(err, job, cfg),
-			ErrorCategory: cat,
-		}
+		fetchFailed(&res, err, redirects.loop, cfg)
 		return
 	}
 	defer func() {
@@ -340,32 +749,149 @@ func CheckURL(ctx context.Context, client *http.Client, job CrawlJob, cfg Config
 	}()
 
 	status := resp.StatusCode
-	if status >= 400 || isRedirectLoop {
-		errMsg := ""
-		if isRedirectLoop {
-			errMsg = "redirect loop detected"
+	res.StatusCode = status
+	res.Redirected = len(redirects.chain) > 0
+	if expected := cfg.expectedStatuses(job); expected != nil && !redirects.loop {
+		if !slices.Contains(expected, status) && !(revalidate && status == http.StatusNotModified) {
+			unexpectedStatusFailed(&res, resp, expected)
+			return
 		}
-		res.Result = &result.LinkResult{
-			URL:           job.URL,
-			StatusCode:    status,
-			SourcePage:    job.SourcePage,
-			IsExternal:    false,
-			Error:         errMsg,
-			ErrorCategory: result.ClassifyError(nil, status, isRedirectLoop),
+		if status >= 400 {
+			// An expected error page has no links worth following
+			res.Links = []string{}
+			return
+		}
+	} else if status >= 400 || redirects.loop {
+		statusFailed(&res, resp, redirects.loop)
+		return
+	}
+
+	// Unchanged since the last run: reuse the links found then
+	if revalidate && status == http.StatusNotModified {
+		res.NotModified = true
+		res.ETag = cmp.Or(resp.Header.Get("ETag"), cached.ETag)
+		res.LastModified = cmp.Or(resp.Header.Get("Last-Modified"), cached.LastModified)
+		res.Links = slices.Clone(cached.Links)
+		if res.Links == nil {
+			res.Links = []string{}
 		}
 		return
 	}
+	res.ETag = resp.Header.Get("ETag")
+	res.LastModified = resp.Header.Get("Last-Modified")
+
+	// A redirect the policy did not follow is a valid, terminal response
+	if isRedirect(status) {
+		res.Links = []string{}
+		return
+	}
 
 	// Check if this is a binary content type - skip parsing if so
 	contentType := resp.Header.Get("Content-Type")
+	if cfg.CheckPDFs && isPDF(contentType) {
+		checkPDFBody(reqCtx, &res, resp, cfg)
+		if res.Result == nil {
+			res.Links = []string{}
+		}
+		return
+	}
 	if isBinaryContentType(contentType) {
 		// Binary files are valid but have no links to extract
 		res.Links = []string{}
 		return
 	}
+	if isEventStream(contentType) {
+		// Events carry no links, and never end
+		res.Streaming = streamEventStream
+		res.Links = []string{}
+		return
+	}
 
-	// Extract links from the response body
-	links, extractErr := ExtractLinks(resp.Body, resp.Request.URL)
+	if cfg.MaxPageBytes > 0 && resp.ContentLength > cfg.MaxPageBytes {
+		tooLargeFailed(&res, resp, cfg.MaxPageBytes)
+		return
+	}
+
+	// Extract links from the response body, keeping a copy for content checks
+	body := &countingReader{reader: cfg.Bandwidth.reader(reqCtx, resp.Body)}
+	limit := newPageLimitReader(body.reader, cfg.MaxPageBytes)
+	if limit != nil {
+		body.reader = limit
+	}
+	var stream *streamReader
+	if resp.ContentLength < 0 {
+		// Without a length the page may stream forever
+		maxBytes, maxTime := cfg.streamLimits(reqCtx)
+		stream = newStreamReader(body.reader, stopReading, maxBytes, maxTime)
+		body.reader = stream
+	}
+	contentChecks := cfg.contentChecks(job)
+	var page bytes.Buffer
+	if len(contentChecks) > 0 {
+		body.reader = io.TeeReader(body.reader, &page)
+	}
+	rejected := make(map[string]bool)
+	visit := func(href string, target *url.URL) {
+		if cfg.LinkHygiene {
+			for _, kind := range lintLink(resp.Request.URL, href, target) {
+				res.Warnings = append(res.Warnings, result.HygieneWarning{
+					Kind:       kind,
+					URL:        target.String(),
+					Href:       href,
+					SourcePage: job.URL,
+				})
+			}
+		}
+		if !cfg.StrictURLs {
+			return
+		}
+		if reason := urlutil.Suspicious(href); reason != "" {
+			res.Malformed = append(res.Malformed, result.LinkResult{
+				URL:           href,
+				SourcePage:    job.URL,
+				IsExternal:    !urlutil.IsSameDomain(target.String(), resp.Request.URL.Hostname()),
+				Error:         "suspicious URL: " + reason,
+				ErrorCategory: result.CategoryMalformedURL,
+			})
+			if normalized, err := urlutil.Normalize(target.String()); err == nil {
+				rejected[normalized] = true
+			}
+		}
+	}
+	var audit *pageAuditor
+	if cfg.Accessibility {
+		audit = newPageAuditor(resp.Request.URL)
+	}
+	var fragments *fragmentChecker
+	if cfg.CheckFragments {
+		fragments = newFragmentChecker(resp.Request.URL)
+	}
+	meta := newMetaCollector(resp.Request.URL)
+	links, extractErr := extractLinks(body, resp.Request.URL, visit, audit, meta, fragments)
+	res.Streaming = stream.finish()
+	if limit.tooLarge() {
+		// A page cut short is not checked for anything else
+		res.Bytes = body.count
+		tooLargeFailed(&res, resp, cfg.MaxPageBytes)
+		return
+	}
+	if len(rejected) > 0 {
+		links = slices.DeleteFunc(links, func(link string) bool { return rejected[link] })
+	}
+	res.Accessibility = audit.results(job.URL)
+	if extractErr == nil {
+		res.Content = checkContent(contentChecks, job.URL, page.Bytes())
+		res.Meta = meta.result()
+		if res.Meta.Next != "" {
+			res.Meta.Next = cfg.rewrite(res.Meta.Next)
+		}
+		if res.Meta.Prev != "" {
+			res.Meta.Prev = cfg.rewrite(res.Meta.Prev)
+		}
+		res.NoArchive = headerNoArchive(resp.Header) != "" || res.Meta.NoArchive != ""
+		res.Warnings = append(res.Warnings, fragments.results(job.URL)...)
+	}
+	res.Bytes = body.count
 	if extractErr != nil {
 		// Malformed HTML - create a broken link result with appropriate category
 		res.Err = fmt.Errorf("extract links from %s: %w", job.URL, extractErr)
@@ -381,10 +907,102 @@ func CheckURL(ctx context.Context, client *http.Client, job CrawlJob, cfg Config
 		return
 	}
 
-	res.Links = links
+	res.Links = cfg.rewriteLinks(links)
 	return
 }
 
+// Snapshot returns the settings of cfg recorded in output envelopes. Zero
+// values are reported as the defaults the crawler applies.
+func (cfg Config) Snapshot() result.ConfigSnapshot {
+	maxRedirects := cfg.MaxRedirects
+	if maxRedirects <= 0 {
+		maxRedirects = DefaultMaxRedirects
+	}
+	concurrency := cfg.Concurrency
+	if cfg.Deterministic {
+		concurrency = 1
+	}
+	var hostPatterns []string
+	for _, hc := range cfg.HostConfigs {
+		hostPatterns = append(hostPatterns, hc.Pattern)
+	}
+	var expectations []string
+	for _, expectation := range cfg.ExpectedStatuses {
+		expectations = append(expectations, expectation.String())
+	}
+	var timeoutOverrides []string
+	for _, override := range cfg.TimeoutOverrides {
+		timeoutOverrides = append(timeoutOverrides, override.String())
+	}
+	var dedupRules []string
+	for _, rule := range cfg.DedupRules {
+		dedupRules = append(dedupRules, rule.String())
+	}
+	var contentNames []string
+	for _, check := range cfg.ContentChecks {
+		contentNames = append(contentNames, check.Name)
+	}
+	var rewrites []string
+	for _, rule := range cfg.Rewrites {
+		rewrites = append(rewrites, rule.Find.String()+" => "+rule.Replace)
+	}
+	var checkNames []string
+	for _, check := range cfg.SyntheticChecks {
+		checkNames = append(checkNames, check.Name)
+	}
+	return result.ConfigSnapshot{
+		Concurrency:     concurrency,
+		MaxPerHost:      cfg.MaxPerHost,
+		RequestTimeout:  cfg.RequestTimeout,
+		TimeoutOverride: timeoutOverrides,
+		QueueTimeout:    cfg.QueueTimeout,
+		HardTimeout:     cfg.HardTimeout,
+		DialTimeout:     cfg.DialTimeout,
+		TLSTimeout:      cfg.TLSHandshakeTimeout,
+		HeaderTimeout:   cfg.ResponseHeaderTimeout,
+		IPVersion:       string(cmp.Or(cfg.IPVersion, IPAuto)),
+		DNSServer:       cfg.DNSServer,
+		MaxBandwidth:    cfg.Bandwidth.bytesPerSecond(),
+		HostRetryBudget: cfg.RetryBudget.retriesPerHost(),
+		Delay:           cfg.Delay,
+		RatePerMinute:   cfg.RatePerMinute,
+		UserAgent:       cfg.UserAgent,
+		HostConfigs:     hostPatterns,
+		BlockedHosts:    cfg.BlockedHosts,
+		Rewrites:        rewrites,
+		ScrubParams:     cfg.Scrub.Params(),
+		SyntheticChecks: checkNames,
+		ExpectStatus:    expectations,
+		ContentChecks:   contentNames,
+		MaxDepth:        cfg.MaxDepth,
+		MaxLinksPerPage: cfg.MaxLinksPerPage,
+		Strategy:        string(cmp.Or(cfg.Strategy, StrategyBFS)),
+		MaxRetries:      cfg.RetryPolicy.MaxRetries,
+		MaxRedirects:    maxRedirects,
+		FollowRedirects: string(cmp.Or(cfg.FollowRedirects, RedirectAlways)),
+		Sitemap:         cfg.Sitemap,
+		IgnoreRobots:    cfg.IgnoreRobots,
+		ExternalRobots:  cfg.RespectExternalRobots,
+		LocalRobots:     cfg.RobotsTxt != nil,
+		Verify:          cfg.Verify.Enabled,
+		LinkHygiene:     cfg.LinkHygiene,
+		StrictURLs:      cfg.StrictURLs,
+		Deterministic:   cfg.Deterministic,
+		Accessibility:   cfg.Accessibility,
+		SEO:             cfg.SEO,
+		SiteStructure:   cfg.SiteStructure,
+		CheckHTTPS:      cfg.CheckHTTPS,
+		TrailingSlash:   cfg.CheckTrailingSlash,
+		FoldSchemes:     cfg.FoldSchemes,
+		DedupQuery:      cfg.DedupIgnoreQuery,
+		DedupRules:      dedupRules,
+		StripSessions:   cfg.StripSessionIDs,
+		Fragments:       cfg.CheckFragments,
+		Feeds:           cfg.CheckFeeds,
+		PDFs:            cfg.CheckPDFs,
+	}
+}
+
 // DefaultConfig returns a Config with sensible defaults.
 func DefaultConfig(startURL string) Config {
 	return Config{
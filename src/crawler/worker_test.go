@@ -2,9 +2,13 @@ package crawler
 
 import (
 	"context"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"slices"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -101,9 +105,9 @@ func TestCheckURLMalformedHTML(t *testing.T) {
 	// in the URL parsing/normalization phase
 	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "text/html")
-		// Use a URL with a newline character which will fail normalization
-		// (url.Parse accepts it but the URL is invalid)
-		html := `<html><body><a href="http://example` + "\n" + `.com/page">Link</a></body></html>`
+		// Use a URL with a NUL character, which url.Parse rejects (newlines
+		// and tabs are stripped, as browsers do)
+		html := `<html><body><a href="http://example` + "\x00" + `.com/page">Link</a></body></html>`
 		if _, err := w.Write([]byte(html)); err != nil {
 			t.Errorf("failed to write response: %v", err)
 		}
@@ -180,10 +184,10 @@ func TestCheckURLVerboseNetwork(t *testing.T) {
 // TestConfigVerboseNetworkField tests that the Config struct has a VerboseNetwork field.
 func TestConfigVerboseNetworkField(t *testing.T) {
 	cfg := Config{
-		StartURL:        "https://example.com",
-		VerboseNetwork:  true,
-		Concurrency:     10,
-		RequestTimeout:  10 * time.Second,
+		StartURL:       "https://example.com",
+		VerboseNetwork: true,
+		Concurrency:    10,
+		RequestTimeout: 10 * time.Second,
 	}
 
 	if !cfg.VerboseNetwork {
@@ -196,3 +200,233 @@ func TestConfigVerboseNetworkField(t *testing.T) {
 		t.Error("Default VerboseNetwork should be false")
 	}
 }
+
+// TestCheckURLTLSFailure verifies that certificate verification failures are
+// classified as CategoryTLS and are not retried.
+func TestCheckURLTLSFailure(t *testing.T) {
+	var attempts int32
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	cfg := DefaultConfig(ts.URL)
+	cfg.RetryPolicy = RetryPolicy{MaxRetries: 2, BaseDelay: 10 * time.Millisecond, MaxDelay: 100 * time.Millisecond}
+	job := CrawlJob{URL: ts.URL, IsExternal: true}
+
+	// The default client does not trust the httptest certificate
+	res := CheckURLWithRetry(context.Background(), &http.Client{}, job, cfg, cfg.RetryPolicy)
+
+	if res.Result == nil {
+		t.Fatal("expected LinkResult for untrusted certificate, got nil")
+	}
+	if res.Result.ErrorCategory != result.CategoryTLS {
+		t.Errorf("ErrorCategory = %v, want %v", res.Result.ErrorCategory, result.CategoryTLS)
+	}
+	if shouldRetry(res, cfg) {
+		t.Error("TLS failures should not be retried")
+	}
+}
+
+// TestCheckURLCapturesDebugHeaders verifies that broken links keep selected
+// response headers so CDN/WAF failures can be diagnosed from the report.
+func TestCheckURLCapturesDebugHeaders(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Server", "cloudflare")
+		w.Header().Set("CF-Ray", "abc123-LHR")
+		w.Header().Set("Retry-After", "120")
+		w.Header().Set("X-Unrelated", "ignored")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+
+	cfg := DefaultConfig(ts.URL)
+	res := CheckURL(context.Background(), &http.Client{}, CrawlJob{URL: ts.URL, IsExternal: true}, cfg)
+
+	if res.Result == nil {
+		t.Fatal("expected LinkResult for 503, got nil")
+	}
+	want := map[string]string{"Server": "cloudflare", "Cf-Ray": "abc123-LHR", "Retry-After": "120"}
+	for name, value := range want {
+		if got := res.Result.Headers[name]; got != value {
+			t.Errorf("Headers[%q] = %q, want %q", name, got, value)
+		}
+	}
+	if _, ok := res.Result.Headers["X-Unrelated"]; ok {
+		t.Error("unexpected header X-Unrelated captured")
+	}
+}
+
+// TestCheckURLSendsNegotiationHeaders verifies that Accept and Accept-Language
+// from Config are applied to both HEAD and GET checks.
+func TestCheckURLSendsNegotiationHeaders(t *testing.T) {
+	var gotAccept, gotLanguage atomic.Value
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAccept.Store(r.Header.Get("Accept"))
+		gotLanguage.Store(r.Header.Get("Accept-Language"))
+		w.Header().Set("Content-Type", "text/html")
+	}))
+	defer ts.Close()
+
+	cfg := DefaultConfig(ts.URL)
+	cfg.Accept = "text/html"
+	cfg.AcceptLanguage = "de-DE,de;q=0.9"
+
+	for _, external := range []bool{true, false} {
+		res := CheckURL(context.Background(), &http.Client{}, CrawlJob{URL: ts.URL, IsExternal: external}, cfg)
+		if res.Result != nil {
+			t.Fatalf("unexpected broken result: %+v", res.Result)
+		}
+		if got := gotAccept.Load(); got != "text/html" {
+			t.Errorf("external=%v: Accept = %v, want %q", external, got, "text/html")
+		}
+		if got := gotLanguage.Load(); got != "de-DE,de;q=0.9" {
+			t.Errorf("external=%v: Accept-Language = %v, want %q", external, got, "de-DE,de;q=0.9")
+		}
+	}
+}
+
+// TestCheckURLSendsIdentityHeaders verifies that the job's user agent is sent
+// (falling back to Config.UserAgent) and that Referer is opt-in.
+func TestCheckURLSendsIdentityHeaders(t *testing.T) {
+	var gotUA, gotReferer atomic.Value
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotUA.Store(r.Header.Get("User-Agent"))
+		gotReferer.Store(r.Header.Get("Referer"))
+	}))
+	defer ts.Close()
+
+	cfg := DefaultConfig(ts.URL)
+	cfg.UserAgent = "config-bot/1.0"
+	job := CrawlJob{URL: ts.URL, SourcePage: "https://example.com/source", IsExternal: true}
+
+	CheckURL(context.Background(), &http.Client{}, job, cfg)
+	if got := gotUA.Load(); got != "config-bot/1.0" {
+		t.Errorf("User-Agent = %v, want %q", got, "config-bot/1.0")
+	}
+	if got := gotReferer.Load(); got != "" {
+		t.Errorf("Referer = %v, want empty when SendReferer is false", got)
+	}
+
+	cfg.SendReferer = true
+	job.UserAgent = "host-bot/2.0"
+	CheckURL(context.Background(), &http.Client{}, job, cfg)
+	if got := gotUA.Load(); got != "host-bot/2.0" {
+		t.Errorf("User-Agent = %v, want job user agent %q", got, "host-bot/2.0")
+	}
+	if got := gotReferer.Load(); got != job.SourcePage {
+		t.Errorf("Referer = %v, want %q", got, job.SourcePage)
+	}
+}
+
+func TestConfigSnapshot(t *testing.T) {
+	cfg := DefaultConfig("https://example.com/")
+	cfg.LinkHygiene = true
+	snap := cfg.Snapshot()
+
+	if snap.Concurrency != 17 || snap.RequestTimeout != 10*time.Second || snap.MaxRetries != cfg.RetryPolicy.MaxRetries {
+		t.Errorf("snapshot = %+v, want the config's settings", snap)
+	}
+	if snap.Strategy != "bfs" || snap.FollowRedirects != "always" || snap.MaxRedirects != DefaultMaxRedirects {
+		t.Errorf("snapshot = %+v, want defaults for unset fields", snap)
+	}
+	if !snap.LinkHygiene {
+		t.Error("LinkHygiene not recorded")
+	}
+}
+
+func TestRun_StrictURLs(t *testing.T) {
+	var backslashHits atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/":
+			_, _ = fmt.Fprint(w, `<a href="/ok">ok</a><a href="/docs\intro">docs</a><a href="/q?x=%2541">q</a>`)
+		case "/docs/intro":
+			backslashHits.Add(1)
+			_, _ = fmt.Fprint(w, `<html></html>`)
+		default:
+			_, _ = fmt.Fprint(w, `<html></html>`)
+		}
+	}))
+	defer ts.Close()
+
+	for _, strict := range []bool{false, true} {
+		backslashHits.Store(0)
+		cfg := DefaultConfig(ts.URL)
+		cfg.Delay = 1
+		cfg.StrictURLs = strict
+		c, err := New(cfg, nil)
+		if err != nil {
+			t.Fatalf("New() error: %v", err)
+		}
+		res, err := c.Run(context.Background())
+		if err != nil {
+			t.Fatalf("Run() error: %v", err)
+		}
+		if !strict {
+			if len(res.BrokenLinks) != 0 || backslashHits.Load() != 1 {
+				t.Errorf("lenient crawl: broken %v, backslash link fetched %d times", res.BrokenLinks, backslashHits.Load())
+			}
+			continue
+		}
+		if backslashHits.Load() != 0 {
+			t.Error("strict crawl followed the backslash link")
+		}
+		if len(res.BrokenLinks) != 2 {
+			t.Fatalf("got %d broken links, want the two suspicious ones: %v", len(res.BrokenLinks), res.BrokenLinks)
+		}
+		for _, link := range res.BrokenLinks {
+			if link.ErrorCategory != result.CategoryMalformedURL || link.SourcePage != ts.URL+"/" || link.IsExternal {
+				t.Errorf("unexpected broken link %+v", link)
+			}
+		}
+		if got := res.Stats.ByCategory[result.CategoryMalformedURL]; got != 2 {
+			t.Errorf("ByCategory[malformed_url] = %d, want 2", got)
+		}
+	}
+}
+
+func TestRun_MaxLinksPerPage(t *testing.T) {
+	var mu sync.Mutex
+	var fetched []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		fetched = append(fetched, r.URL.Path)
+		mu.Unlock()
+		switch r.URL.Path {
+		case "/":
+			_, _ = fmt.Fprint(w, `<a href="/a">a</a><a href="/b">b</a><a href="/c">c</a><a href="/d">d</a>`)
+		case "/a":
+			// "/" is already visited, so only "/c" counts against the budget
+			_, _ = fmt.Fprint(w, `<a href="/">home</a><a href="/c">c</a>`)
+		default:
+			_, _ = fmt.Fprint(w, `<html></html>`)
+		}
+	}))
+	defer ts.Close()
+
+	cfg := DefaultConfig(ts.URL)
+	cfg.Delay = 1
+	cfg.IgnoreRobots = true
+	cfg.Deterministic = true
+	cfg.MaxLinksPerPage = 2
+	c, err := New(cfg, nil)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	res, err := c.Run(context.Background())
+	if err != nil {
+		t.Fatalf("Run() error: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if want := []string{"/", "/a", "/b", "/c"}; !slices.Equal(fetched, want) {
+		t.Errorf("fetched %v, want %v", fetched, want)
+	}
+	want := []result.TruncatedPage{{URL: ts.URL + "/", Links: 4, Queued: 2}}
+	if !slices.Equal(res.Truncated, want) {
+		t.Errorf("Truncated = %+v, want %+v", res.Truncated, want)
+	}
+}
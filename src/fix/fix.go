@@ -0,0 +1,371 @@
+// Package fix rewrites obviously-fixable links in local Markdown and HTML
+// sources: http links whose https variant works are upgraded, permanent
+// redirects are replaced by their final destination, and dead links can be
+// swapped for an archived snapshot.
+package fix
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lukemcguire/zombiecrawl/crawler"
+)
+
+// Reason explains why a link is rewritten.
+type Reason string
+
+// Rewrite reasons.
+const (
+	ReasonHTTPS    Reason = "https available"
+	ReasonMoved    Reason = "moved permanently"
+	ReasonArchived Reason = "dead, archived copy"
+)
+
+// maxRedirects bounds how many permanent redirects are followed.
+const maxRedirects = 10
+
+// defaultConcurrency is how many links are checked at once.
+const defaultConcurrency = 4
+
+// linkPattern matches absolute http(s) URLs in Markdown and HTML. It stops at
+// whitespace, quotes, angle brackets and square brackets so that attribute
+// quoting is left intact. Parentheses are matched too, since URLs such as
+// https://en.wikipedia.org/wiki/Foo_(bar) contain them; trimLink drops the
+// unbalanced closing one of Markdown link syntax.
+var linkPattern = regexp.MustCompile(`https?://[^\s"'<>\[\]` + "`" + `]+`)
+
+// sourceExtensions are the file types that are scanned.
+var sourceExtensions = []string{".md", ".markdown", ".html", ".htm"}
+
+// IsHTML reports whether path is an HTML source, by its extension. Links in
+// HTML sources are written with character references such as &amp;.
+func IsHTML(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	return ext == ".html" || ext == ".htm"
+}
+
+// Fix is one proposed link rewrite.
+type Fix struct {
+	From   string // The link as written in the source
+	To     string // Its replacement
+	Reason Reason // Why it is rewritten
+}
+
+// Change is the rewritten content of one file.
+type Change struct {
+	Path   string // File path
+	Before []byte // Original content
+	After  []byte // Content with fixes applied
+	Fixes  []Fix  // Rewrites applied to this file, in order of first appearance
+}
+
+// Fixer checks links and proposes rewrites.
+type Fixer struct {
+	// Client makes the checks. Redirects are handled by the fixer; nil uses
+	// a client with a 10 second timeout.
+	Client *http.Client
+	// Archive, when set, substitutes archived snapshots for dead links.
+	Archive *crawler.ArchiveLookup
+	// UserAgent is sent with every check.
+	UserAgent string
+	// Concurrency bounds parallel checks (default 4).
+	Concurrency int
+}
+
+// Collect returns the Markdown and HTML files under paths. Directories are
+// walked recursively; files named explicitly are included whatever their
+// extension.
+func Collect(paths []string) ([]string, error) {
+	var files []string
+	for _, root := range paths {
+		info, err := os.Stat(root)
+		if err != nil {
+			return nil, fmt.Errorf("stat %s: %w", root, err)
+		}
+		if !info.IsDir() {
+			files = append(files, root)
+			continue
+		}
+		err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !d.IsDir() && slices.Contains(sourceExtensions, strings.ToLower(filepath.Ext(path))) {
+				files = append(files, path)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("walk %s: %w", root, err)
+		}
+	}
+	return files, nil
+}
+
+// Links returns the distinct absolute http(s) links in content, in order of
+// first appearance. With isHTML, character references are decoded, so
+// href="/?a=1&amp;b=2" yields the URL a browser would request.
+func Links(content []byte, isHTML bool) []string {
+	var links []string
+	seen := make(map[string]bool)
+	for _, match := range linkPattern.FindAll(content, -1) {
+		link := sourceLink(trimLink(string(match)), isHTML)
+		if !seen[link] {
+			seen[link] = true
+			links = append(links, link)
+		}
+	}
+	return links
+}
+
+// sourceLink returns the URL written as link, decoding character references
+// in HTML.
+func sourceLink(link string, isHTML bool) string {
+	if isHTML {
+		return html.UnescapeString(link)
+	}
+	return link
+}
+
+// trimLink drops trailing sentence punctuation picked up after a bare URL,
+// and, as Markdown autolinking does, trailing closing parentheses without an
+// opening one in the link.
+func trimLink(link string) string {
+	for {
+		link = strings.TrimRight(link, ".,;:!?*_")
+		if !strings.HasSuffix(link, ")") || strings.Count(link, ")") <= strings.Count(link, "(") {
+			return link
+		}
+		link = link[:len(link)-1]
+	}
+}
+
+// Rewrite replaces every whole occurrence of the fixed links in content.
+// Links that merely start with a fixed link are left alone. With isHTML,
+// links are matched as Links decodes them and replacements are escaped.
+func Rewrite(content []byte, fixes []Fix, isHTML bool) []byte {
+	replacements := make(map[string]string, len(fixes))
+	for _, f := range fixes {
+		replacements[f.From] = f.To
+	}
+	return linkPattern.ReplaceAllFunc(content, func(match []byte) []byte {
+		link := trimLink(string(match))
+		to, ok := replacements[sourceLink(link, isHTML)]
+		if !ok {
+			return match
+		}
+		if isHTML {
+			to = html.EscapeString(to)
+		}
+		return append([]byte(to), match[len(link):]...)
+	})
+}
+
+// Plan reads each file, checks its links, and returns the files that would
+// change. Each distinct link is checked once across all files.
+func (f *Fixer) Plan(ctx context.Context, files []string) ([]Change, error) {
+	contents := make([][]byte, len(files))
+	var all []string
+	seen := make(map[string]bool)
+	for i, path := range files {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("read %s: %w", path, err)
+		}
+		contents[i] = content
+		for _, link := range Links(content, IsHTML(path)) {
+			if !seen[link] {
+				seen[link] = true
+				all = append(all, link)
+			}
+		}
+	}
+
+	fixes := f.checkAll(ctx, all)
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var changes []Change
+	for i, path := range files {
+		var applied []Fix
+		for _, link := range Links(contents[i], IsHTML(path)) {
+			if fix, ok := fixes[link]; ok {
+				applied = append(applied, fix)
+			}
+		}
+		if len(applied) == 0 {
+			continue
+		}
+		changes = append(changes, Change{
+			Path:   path,
+			Before: contents[i],
+			After:  Rewrite(contents[i], applied, IsHTML(path)),
+			Fixes:  applied,
+		})
+	}
+	return changes, nil
+}
+
+// checkAll checks links concurrently and returns the fixes found, by link.
+func (f *Fixer) checkAll(ctx context.Context, links []string) map[string]Fix {
+	concurrency := f.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultConcurrency
+	}
+	var (
+		mu    sync.Mutex
+		wg    sync.WaitGroup
+		fixes = make(map[string]Fix)
+		slots = make(chan struct{}, concurrency)
+	)
+dispatch:
+	for _, link := range links {
+		// Once cancelled, stop starting checks and wait for the running ones
+		if ctx.Err() != nil {
+			break
+		}
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+			break dispatch
+		}
+		wg.Go(func() {
+			defer func() { <-slots }()
+			if fix, ok := f.Suggest(ctx, link); ok {
+				mu.Lock()
+				fixes[link] = fix
+				mu.Unlock()
+			}
+		})
+	}
+	wg.Wait()
+	return fixes
+}
+
+// Suggest checks one link and returns the rewrite to apply, if any. Permanent
+// redirects to a working page come first, then an https upgrade, then an
+// archived snapshot for links that are dead. Only a 4xx or 5xx response marks
+// a link dead; a network error may be passing and leaves it alone.
+func (f *Fixer) Suggest(ctx context.Context, link string) (Fix, bool) {
+	final, status, err := f.resolve(ctx, link)
+	if err == nil && final != link && succeeded(status) {
+		return Fix{From: link, To: final, Reason: ReasonMoved}, true
+	}
+
+	if strings.HasPrefix(link, "http://") {
+		secure := "https://" + strings.TrimPrefix(link, "http://")
+		if secureFinal, secureStatus, secureErr := f.resolve(ctx, secure); secureErr == nil && secureFinal == secure && succeeded(secureStatus) {
+			return Fix{From: link, To: secure, Reason: ReasonHTTPS}, true
+		}
+	}
+
+	dead := err == nil && status >= 400
+	if dead && f.Archive != nil && ctx.Err() == nil {
+		if snapshot, archiveErr := f.Archive.Snapshot(ctx, link); archiveErr == nil && snapshot != "" {
+			return Fix{From: link, To: snapshot, Reason: ReasonArchived}, true
+		}
+	}
+	return Fix{}, false
+}
+
+// succeeded reports whether status is a success.
+func succeeded(status int) bool {
+	return status >= 200 && status < 300
+}
+
+// resolve follows permanent redirects (301 and 308) from link and returns the
+// last URL reached and its status. Temporary redirects are not followed,
+// since their target is not a stable replacement.
+func (f *Fixer) resolve(ctx context.Context, link string) (string, int, error) {
+	client := f.client()
+	current := link
+	for range maxRedirects {
+		status, location, err := f.fetch(ctx, client, current)
+		if err != nil {
+			return current, 0, err
+		}
+		if (status != http.StatusMovedPermanently && status != http.StatusPermanentRedirect) || location == "" {
+			return current, status, nil
+		}
+		current = location
+	}
+	return current, 0, errors.New("too many redirects")
+}
+
+// fetch requests rawURL without following redirects and returns the status
+// and the absolute redirect target, if any.
+func (f *Fixer) fetch(ctx context.Context, client *http.Client, rawURL string) (int, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return 0, "", fmt.Errorf("create request: %w", err)
+	}
+	if f.UserAgent != "" {
+		req.Header.Set("User-Agent", f.UserAgent)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, "", err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	_, _ = io.CopyN(io.Discard, resp.Body, 64*1024)
+
+	location := ""
+	if loc, locErr := resp.Location(); locErr == nil {
+		location = loc.String()
+	}
+	return resp.StatusCode, location, nil
+}
+
+// client returns a copy of the configured client that does not follow
+// redirects.
+func (f *Fixer) client() *http.Client {
+	client := &http.Client{Timeout: 10 * time.Second}
+	if f.Client != nil {
+		copied := *f.Client
+		client = &copied
+	}
+	client.CheckRedirect = func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }
+	return client
+}
+
+// Diff renders a change as a unified-style preview: each rewritten line is
+// shown before and after, with its line number.
+func Diff(change Change) string {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "--- a/%s\n+++ b/%s\n", change.Path, change.Path)
+	before := strings.Split(string(change.Before), "\n")
+	after := strings.Split(string(change.After), "\n")
+	for i := range min(len(before), len(after)) {
+		if before[i] == after[i] {
+			continue
+		}
+		fmt.Fprintf(&buf, "@@ -%d +%d @@\n-%s\n+%s\n", i+1, i+1, before[i], after[i])
+	}
+	return buf.String()
+}
+
+// Apply writes the change's new content, keeping the file's permissions.
+func Apply(change Change) error {
+	info, err := os.Stat(change.Path)
+	if err != nil {
+		return fmt.Errorf("stat %s: %w", change.Path, err)
+	}
+	if err := os.WriteFile(change.Path, change.After, info.Mode().Perm()); err != nil {
+		return fmt.Errorf("write %s: %w", change.Path, err)
+	}
+	return nil
+}
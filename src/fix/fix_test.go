@@ -0,0 +1,213 @@
+package fix
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/lukemcguire/zombiecrawl/crawler"
+)
+
+func TestLinks(t *testing.T) {
+	content := []byte(`See [docs](http://a.example/docs), <https://b.example/x?y=1>.
+<a href="http://c.example/page">c</a> and http://d.example/end. Again http://a.example/docs`)
+	got := Links(content, false)
+	want := []string{"http://a.example/docs", "https://b.example/x?y=1", "http://c.example/page", "http://d.example/end"}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("Links() = %v, want %v", got, want)
+	}
+}
+
+func TestLinks_Parentheses(t *testing.T) {
+	content := []byte(`[Foo](https://en.wikipedia.org/wiki/Foo_(bar)) and https://en.wikipedia.org/wiki/Baz_(qux).
+(see https://a.example/x) or [y](https://a.example/y "title")`)
+	got := Links(content, false)
+	want := []string{"https://en.wikipedia.org/wiki/Foo_(bar)", "https://en.wikipedia.org/wiki/Baz_(qux)", "https://a.example/x", "https://a.example/y"}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("Links() = %v, want %v", got, want)
+	}
+
+	rewritten := Rewrite(content, []Fix{{From: "https://en.wikipedia.org/wiki/Foo_(bar)", To: "https://en.wikipedia.org/wiki/Foo_(baz)"}}, false)
+	if !strings.HasPrefix(string(rewritten), "[Foo](https://en.wikipedia.org/wiki/Foo_(baz)) and") {
+		t.Errorf("Rewrite() = %q, want the whole parenthesised link replaced", rewritten)
+	}
+}
+
+func TestLinksRewrite_HTMLCharacterReferences(t *testing.T) {
+	content := []byte(`<a href="http://a.example/?x=1&amp;y=2">a</a> <a href="http://a.example/?x=1&y=2">b</a>`)
+	if got := Links(content, true); !slices.Equal(got, []string{"http://a.example/?x=1&y=2"}) {
+		t.Errorf("Links(html) = %v, want the decoded URL once", got)
+	}
+	if got := Links(content, false); len(got) != 2 {
+		t.Errorf("Links(markdown) = %v, want both spellings as written", got)
+	}
+
+	got := Rewrite(content, []Fix{{From: "http://a.example/?x=1&y=2", To: "https://a.example/?x=1&y=2"}}, true)
+	want := `<a href="https://a.example/?x=1&amp;y=2">a</a> <a href="https://a.example/?x=1&amp;y=2">b</a>`
+	if string(got) != want {
+		t.Errorf("Rewrite(html) = %q, want %q", got, want)
+	}
+}
+
+func TestIsHTML(t *testing.T) {
+	for path, want := range map[string]bool{"a.html": true, "b/C.HTM": true, "c.md": false, "notes": false} {
+		if got := IsHTML(path); got != want {
+			t.Errorf("IsHTML(%q) = %v, want %v", path, got, want)
+		}
+	}
+}
+
+func TestRewrite_WholeLinksOnly(t *testing.T) {
+	content := []byte("[a](http://a.example/x) [b](http://a.example/xy) http://a.example/x.")
+	got := Rewrite(content, []Fix{{From: "http://a.example/x", To: "https://a.example/x"}}, false)
+	want := "[a](https://a.example/x) [b](http://a.example/xy) https://a.example/x."
+	if string(got) != want {
+		t.Errorf("Rewrite() = %q, want %q", got, want)
+	}
+}
+
+// newLinkServer serves /ok, a permanent redirect chain /old -> /older -> /ok,
+// a temporary redirect /temp, and 404 for anything else.
+func newLinkServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/ok":
+			_, _ = fmt.Fprint(w, "ok")
+		case "/old":
+			http.Redirect(w, r, "/older", http.StatusMovedPermanently)
+		case "/older":
+			http.Redirect(w, r, "/ok", http.StatusPermanentRedirect)
+		case "/temp":
+			http.Redirect(w, r, "/ok", http.StatusFound)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+}
+
+func TestSuggest(t *testing.T) {
+	ts := newLinkServer()
+	defer ts.Close()
+
+	wayback := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		target := r.URL.Query().Get("url")
+		_, _ = fmt.Fprintf(w, `{"archived_snapshots":{"closest":{"status":"200","available":true,"url":"http://web.archive.org/web/2020/%s"}}}`, target)
+	}))
+	defer wayback.Close()
+
+	// A server that is down gives a network error, not a dead link
+	down := httptest.NewServer(http.NotFoundHandler())
+	down.Close()
+
+	f := &Fixer{Archive: crawler.NewArchiveLookup(wayback.Client(), wayback.URL, 1000)}
+	tests := []struct {
+		link   string
+		to     string
+		reason Reason
+	}{
+		{ts.URL + "/ok", "", ""},
+		{ts.URL + "/old", ts.URL + "/ok", ReasonMoved},
+		{ts.URL + "/temp", "", ""},
+		{ts.URL + "/gone", "http://web.archive.org/web/2020/" + ts.URL + "/gone", ReasonArchived},
+		{down.URL + "/gone", "", ""},
+	}
+	for _, tt := range tests {
+		fix, ok := f.Suggest(context.Background(), tt.link)
+		if fix.To != tt.to || fix.Reason != tt.reason || ok != (tt.to != "") {
+			t.Errorf("Suggest(%s) = %+v, %v, want %q (%s)", tt.link, fix, ok, tt.to, tt.reason)
+		}
+	}
+}
+
+func TestSuggest_HTTPSUpgrade(t *testing.T) {
+	secure := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprint(w, "secure")
+	}))
+	defer secure.Close()
+	// The plain http address of the TLS server does not speak http, so only
+	// the upgrade can succeed.
+	link := "http://" + strings.TrimPrefix(secure.URL, "https://") + "/page"
+
+	f := &Fixer{Client: secure.Client()}
+	fix, ok := f.Suggest(context.Background(), link)
+	if !ok || fix.To != secure.URL+"/page" || fix.Reason != ReasonHTTPS {
+		t.Errorf("Suggest(%s) = %+v, %v, want https upgrade", link, fix, ok)
+	}
+}
+
+func TestCheckAll_StopsWhenCancelled(t *testing.T) {
+	var requests atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		http.NotFound(w, r)
+	}))
+	defer ts.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	links := []string{ts.URL + "/a", ts.URL + "/b", ts.URL + "/c"}
+	if fixes := (&Fixer{Concurrency: 1}).checkAll(ctx, links); len(fixes) != 0 {
+		t.Errorf("checkAll() = %v, want no fixes", fixes)
+	}
+	if n := requests.Load(); n != 0 {
+		t.Errorf("made %d requests after cancellation, want 0", n)
+	}
+}
+
+func TestPlanDiffApply(t *testing.T) {
+	ts := newLinkServer()
+	defer ts.Close()
+
+	dir := t.TempDir()
+	doc := filepath.Join(dir, "guide.md")
+	original := "# Guide\n\nRead [this](" + ts.URL + "/old).\nFine: " + ts.URL + "/ok\n"
+	if err := os.WriteFile(doc, []byte(original), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "notes.txt"), []byte(ts.URL+"/old"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	files, err := Collect([]string{dir})
+	if err != nil {
+		t.Fatalf("Collect() error: %v", err)
+	}
+	if len(files) != 1 || files[0] != doc {
+		t.Fatalf("Collect() = %v, want only the Markdown file", files)
+	}
+
+	changes, err := (&Fixer{}).Plan(context.Background(), files)
+	if err != nil {
+		t.Fatalf("Plan() error: %v", err)
+	}
+	if len(changes) != 1 || len(changes[0].Fixes) != 1 {
+		t.Fatalf("Plan() = %+v, want one fix", changes)
+	}
+
+	diff := Diff(changes[0])
+	wantDiff := "--- a/" + doc + "\n+++ b/" + doc + "\n@@ -3 +3 @@\n" +
+		"-Read [this](" + ts.URL + "/old).\n" +
+		"+Read [this](" + ts.URL + "/ok).\n"
+	if diff != wantDiff {
+		t.Errorf("Diff() =\n%s\nwant\n%s", diff, wantDiff)
+	}
+
+	// Planning is a dry run; only Apply writes
+	if content, _ := os.ReadFile(doc); string(content) != original {
+		t.Error("Plan() modified the file")
+	}
+	if err := Apply(changes[0]); err != nil {
+		t.Fatalf("Apply() error: %v", err)
+	}
+	content, _ := os.ReadFile(doc)
+	if !strings.Contains(string(content), "[this]("+ts.URL+"/ok)") {
+		t.Errorf("file after Apply():\n%s", content)
+	}
+}
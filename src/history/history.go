@@ -0,0 +1,191 @@
+// Package history records completed crawls in a local run database and
+// derives trend reports from them.
+//
+// The database is a SQLite file with a runs table and a broken table listing
+// the URLs each run found broken. It is opened with a pure-Go driver, so it
+// needs no cgo, and can be queried with the sqlite3 shell.
+package history
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"slices"
+	"time"
+
+	_ "modernc.org/sqlite" // Pure-Go driver registered as "sqlite"
+
+	"github.com/lukemcguire/zombiecrawl/result"
+)
+
+// schema creates the run database tables, if they do not exist. Stats are
+// kept as JSON so new CrawlStats fields need no migration.
+const schema = `
+CREATE TABLE IF NOT EXISTS runs (
+	id INTEGER PRIMARY KEY,
+	run_id TEXT NOT NULL,
+	site TEXT NOT NULL,
+	started_at INTEGER NOT NULL,
+	stats TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS broken (
+	run INTEGER NOT NULL REFERENCES runs (id),
+	url TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS broken_run ON broken (run);
+`
+
+// Run is one completed crawl as stored in the run database.
+type Run struct {
+	RunID     string            `json:"run_id,omitempty"` // The crawl's run ID, if it had one
+	Site      string            `json:"site"`             // The crawl's start URL
+	StartedAt time.Time         `json:"started_at"`       // When the crawl began
+	Stats     result.CrawlStats `json:"stats"`            // Aggregate statistics for the crawl
+	Broken    []string          `json:"broken"`           // URLs found broken, sorted
+}
+
+// NewRun builds a Run from a crawl result.
+func NewRun(site string, startedAt time.Time, res *result.Result) Run {
+	broken := make([]string, 0, len(res.BrokenLinks))
+	for _, link := range res.BrokenLinks {
+		broken = append(broken, link.URL)
+	}
+	slices.Sort(broken)
+	return Run{
+		RunID:     res.RunID,
+		Site:      site,
+		StartedAt: startedAt.UTC(),
+		Stats:     res.Stats,
+		Broken:    slices.Compact(broken),
+	}
+}
+
+// Append adds run to the database at path, creating the file if needed.
+func Append(path string, run Run) error {
+	stats, err := json.Marshal(run.Stats)
+	if err != nil {
+		return fmt.Errorf("encode run: %w", err)
+	}
+	db, err := open(path)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = db.Close() }()
+
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("write run database: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+	res, err := tx.Exec(`INSERT INTO runs (run_id, site, started_at, stats) VALUES (?, ?, ?, ?)`,
+		run.RunID, run.Site, run.StartedAt.UnixNano(), stats)
+	if err != nil {
+		return fmt.Errorf("write run database: %w", err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("write run database: %w", err)
+	}
+	for _, link := range run.Broken {
+		if _, err := tx.Exec(`INSERT INTO broken (run, url) VALUES (?, ?)`, id, link); err != nil {
+			return fmt.Errorf("write run database: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("write run database: %w", err)
+	}
+	return nil
+}
+
+// Load reads every run in the database at path, oldest first.
+// A missing database is reported as an error wrapping fs.ErrNotExist.
+func Load(path string) ([]Run, error) {
+	// Opening a missing file would create an empty database
+	if _, err := os.Stat(path); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("run database %s: %w", path, err)
+		}
+		return nil, fmt.Errorf("open run database: %w", err)
+	}
+	db, err := open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = db.Close() }()
+
+	rows, err := db.Query(`SELECT id, run_id, site, started_at, stats FROM runs ORDER BY started_at, id`)
+	if err != nil {
+		return nil, fmt.Errorf("read run database: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+	var runs []Run
+	byID := make(map[int64]int)
+	for rows.Next() {
+		var (
+			id, startedAt int64
+			stats         []byte
+			run           Run
+		)
+		if err := rows.Scan(&id, &run.RunID, &run.Site, &startedAt, &stats); err != nil {
+			return nil, fmt.Errorf("read run database: %w", err)
+		}
+		if err := json.Unmarshal(stats, &run.Stats); err != nil {
+			return nil, fmt.Errorf("%s: run %d: decode stats: %w", path, id, err)
+		}
+		run.StartedAt = time.Unix(0, startedAt).UTC()
+		byID[id] = len(runs)
+		runs = append(runs, run)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("read run database: %w", err)
+	}
+
+	broken, err := db.Query(`SELECT run, url FROM broken ORDER BY run, url`)
+	if err != nil {
+		return nil, fmt.Errorf("read run database: %w", err)
+	}
+	defer func() { _ = broken.Close() }()
+	for broken.Next() {
+		var (
+			id   int64
+			link string
+		)
+		if err := broken.Scan(&id, &link); err != nil {
+			return nil, fmt.Errorf("read run database: %w", err)
+		}
+		if i, ok := byID[id]; ok {
+			runs[i].Broken = append(runs[i].Broken, link)
+		}
+	}
+	if err := broken.Err(); err != nil {
+		return nil, fmt.Errorf("read run database: %w", err)
+	}
+	return runs, nil
+}
+
+// open opens the SQLite database at path, creating its tables if needed.
+func open(path string) (*sql.DB, error) {
+	db, err := sql.Open("sqlite", path+"?_pragma=busy_timeout(5000)")
+	if err != nil {
+		return nil, fmt.Errorf("open run database: %w", err)
+	}
+	if _, err := db.Exec(schema); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("open run database %s: %w", path, err)
+	}
+	return db, nil
+}
+
+// Latest returns the most recent run of site, if any. runs must be sorted
+// oldest first, as returned by Load.
+func Latest(runs []Run, site string) (Run, bool) {
+	for i := len(runs) - 1; i >= 0; i-- {
+		if runs[i].Site == site {
+			return runs[i], true
+		}
+	}
+	return Run{}, false
+}
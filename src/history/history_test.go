@@ -0,0 +1,96 @@
+package history
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/lukemcguire/zombiecrawl/result"
+)
+
+func TestNewRun_SortsAndDedupsBrokenURLs(t *testing.T) {
+	res := &result.Result{
+		RunID: "01J0000000000000000000000A",
+		BrokenLinks: []result.LinkResult{
+			{URL: "http://example.com/b", SourcePage: "http://example.com/"},
+			{URL: "http://example.com/a", SourcePage: "http://example.com/"},
+			{URL: "http://example.com/b", SourcePage: "http://example.com/other"},
+		},
+		Stats: result.CrawlStats{TotalChecked: 9, BrokenCount: 3},
+	}
+
+	run := NewRun("http://example.com/", time.Date(2026, 1, 2, 3, 4, 5, 0, time.Local), res)
+
+	if len(run.Broken) != 2 || run.Broken[0] != "http://example.com/a" || run.Broken[1] != "http://example.com/b" {
+		t.Errorf("Broken = %v, want sorted unique URLs", run.Broken)
+	}
+	if run.StartedAt.Location() != time.UTC {
+		t.Errorf("StartedAt location = %v, want UTC", run.StartedAt.Location())
+	}
+	if run.Stats.TotalChecked != 9 {
+		t.Errorf("Stats.TotalChecked = %d, want 9", run.Stats.TotalChecked)
+	}
+	if run.RunID != res.RunID {
+		t.Errorf("RunID = %q, want %q", run.RunID, res.RunID)
+	}
+}
+
+func TestAppendLoad_RoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "crawl.db")
+	later := Run{Site: "http://a.example/", StartedAt: time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC), Broken: []string{"x"}}
+	earlier := Run{Site: "http://a.example/", StartedAt: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+
+	// Appended out of order; Load returns oldest first
+	for _, run := range []Run{later, earlier} {
+		if err := Append(path, run); err != nil {
+			t.Fatalf("Append() error: %v", err)
+		}
+	}
+
+	runs, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if len(runs) != 2 {
+		t.Fatalf("Load() returned %d runs, want 2", len(runs))
+	}
+	if !runs[0].StartedAt.Equal(earlier.StartedAt) || !runs[1].StartedAt.Equal(later.StartedAt) {
+		t.Errorf("runs not sorted oldest first: %v, %v", runs[0].StartedAt, runs[1].StartedAt)
+	}
+	if len(runs[1].Broken) != 1 || runs[1].Broken[0] != "x" {
+		t.Errorf("Broken = %v, want [x]", runs[1].Broken)
+	}
+}
+
+func TestLoad_Errors(t *testing.T) {
+	dir := t.TempDir()
+
+	if _, err := Load(filepath.Join(dir, "missing.db")); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Load(missing) error = %v, want fs.ErrNotExist", err)
+	}
+
+	corrupt := filepath.Join(dir, "corrupt.db")
+	if err := os.WriteFile(corrupt, []byte("not a sqlite database, but a text file long enough to have a header"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(corrupt); err == nil {
+		t.Error("Load(corrupt) returned nil error")
+	}
+}
+
+func TestLatest(t *testing.T) {
+	runs := []Run{
+		{Site: "http://a.example/", Broken: []string{"old"}},
+		{Site: "http://b.example/"},
+		{Site: "http://a.example/", Broken: []string{"new"}},
+	}
+	if run, ok := Latest(runs, "http://a.example/"); !ok || run.Broken[0] != "new" {
+		t.Errorf("Latest(a) = %+v, %v, want the newest a run", run, ok)
+	}
+	if _, ok := Latest(runs, "http://c.example/"); ok {
+		t.Error("Latest(c) found a run for a site never crawled")
+	}
+}
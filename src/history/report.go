@@ -0,0 +1,148 @@
+package history
+
+import (
+	"encoding/csv"
+	"fmt"
+	"html/template"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// reportTimeFormat is how run times are shown in reports.
+const reportTimeFormat = "2006-01-02 15:04"
+
+// WriteText writes a plain-text trend table for each site to w.
+func WriteText(w io.Writer, trends []SiteTrend) error {
+	var b strings.Builder
+	writef := func(format string, a ...any) { _, _ = fmt.Fprintf(&b, format, a...) }
+
+	if len(trends) == 0 {
+		writef("No runs recorded.\n")
+	}
+	for i, trend := range trends {
+		if i > 0 {
+			writef("\n")
+		}
+		last := trend.Points[len(trend.Points)-1]
+		writef("== %s ==\n", trend.Site)
+		writef("Broken over time: %s (%d runs)\n", Sparkline(last.BrokenCounts), len(trend.Points))
+		writef("  %-16s  %7s  %6s  %5s  %5s\n", "Run", "Checked", "Broken", "New", "Fixed")
+		for _, point := range trend.Points {
+			writef("  %-16s  %7d  %6d  %5d  %5d\n", point.StartedAt.Format(reportTimeFormat),
+				point.Checked, point.Broken, len(point.NewlyBroken), len(point.Fixed))
+		}
+		writeLinks(writef, "Newly broken in latest run", last.NewlyBroken)
+		writeLinks(writef, "Fixed in latest run", last.Fixed)
+		writeLinks(writef, "Flaky", trend.Flaky)
+	}
+
+	if _, err := io.WriteString(w, b.String()); err != nil {
+		return fmt.Errorf("write report: %w", err)
+	}
+	return nil
+}
+
+// writeLinks writes a titled list of links, or nothing if links is empty.
+func writeLinks(writef func(format string, a ...any), title string, links []string) {
+	if len(links) == 0 {
+		return
+	}
+	writef("%s (%d):\n", title, len(links))
+	for _, link := range links {
+		writef("  %s\n", link)
+	}
+}
+
+// WriteCSV writes one row per run to w.
+// Column order: site, started_at, checked, broken, newly_broken, fixed, flaky
+// The flaky column repeats the site's flaky link count on every row.
+func WriteCSV(w io.Writer, trends []SiteTrend) error {
+	cw := csv.NewWriter(w)
+
+	header := []string{"site", "started_at", "checked", "broken", "newly_broken", "fixed", "flaky"}
+	if err := cw.Write(header); err != nil {
+		return fmt.Errorf("write csv header: %w", err)
+	}
+
+	for _, trend := range trends {
+		for _, point := range trend.Points {
+			record := []string{
+				trend.Site,
+				point.StartedAt.Format(time.RFC3339),
+				strconv.Itoa(point.Checked),
+				strconv.Itoa(point.Broken),
+				strconv.Itoa(len(point.NewlyBroken)),
+				strconv.Itoa(len(point.Fixed)),
+				strconv.Itoa(len(trend.Flaky)),
+			}
+			if err := cw.Write(record); err != nil {
+				return fmt.Errorf("write csv record for %s: %w", trend.Site, err)
+			}
+		}
+	}
+
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return fmt.Errorf("flush csv output: %w", err)
+	}
+	return nil
+}
+
+// htmlReport is the template for WriteHTML.
+var htmlReport = template.Must(template.New("report").Funcs(template.FuncMap{
+	"sparkline": Sparkline,
+	"last":      func(points []TrendPoint) TrendPoint { return points[len(points)-1] },
+	"when":      func(t time.Time) string { return t.Format(reportTimeFormat) },
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>zombiecrawl trend report</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; margin-bottom: 1em; }
+th, td { border: 1px solid #ccc; padding: 0.25em 0.75em; text-align: right; }
+th:first-child, td:first-child { text-align: left; }
+.spark { font-size: 1.5em; letter-spacing: 0.1em; }
+</style>
+</head>
+<body>
+<h1>zombiecrawl trend report</h1>
+{{- range .}}
+{{- $last := last .Points}}
+<h2>{{.Site}}</h2>
+<p class="spark" title="broken links per run">{{sparkline $last.BrokenCounts}}</p>
+<table>
+<tr><th>Run</th><th>Checked</th><th>Broken</th><th>New</th><th>Fixed</th></tr>
+{{- range .Points}}
+<tr><td>{{when .StartedAt}}</td><td>{{.Checked}}</td><td>{{.Broken}}</td><td>{{len .NewlyBroken}}</td><td>{{len .Fixed}}</td></tr>
+{{- end}}
+</table>
+{{- if $last.NewlyBroken}}
+<h3>Newly broken in latest run</h3>
+<ul>{{range $last.NewlyBroken}}<li>{{.}}</li>{{end}}</ul>
+{{- end}}
+{{- if $last.Fixed}}
+<h3>Fixed in latest run</h3>
+<ul>{{range $last.Fixed}}<li>{{.}}</li>{{end}}</ul>
+{{- end}}
+{{- if .Flaky}}
+<h3>Flaky</h3>
+<ul>{{range .Flaky}}<li>{{.}}</li>{{end}}</ul>
+{{- end}}
+{{- else}}
+<p>No runs recorded.</p>
+{{- end}}
+</body>
+</html>
+`))
+
+// WriteHTML writes a self-contained HTML trend report to w.
+func WriteHTML(w io.Writer, trends []SiteTrend) error {
+	if err := htmlReport.Execute(w, trends); err != nil {
+		return fmt.Errorf("write html report: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package history
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// sampleTrends returns trends for a site with one fix and one new breakage.
+func sampleTrends() []SiteTrend {
+	return Trends([]Run{
+		runAt("http://a.example/", 1, "http://a.example/old"),
+		runAt("http://a.example/", 2, "http://a.example/<new>"),
+	})
+}
+
+func TestWriteText(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteText(&buf, sampleTrends()); err != nil {
+		t.Fatalf("WriteText() error: %v", err)
+	}
+	got := buf.String()
+
+	for _, want := range []string{
+		"== http://a.example/ ==",
+		"Broken over time: ▁▁ (2 runs)",
+		"2026-01-02 00:00",
+		"Newly broken in latest run (1):\n  http://a.example/<new>\n",
+		"Fixed in latest run (1):\n  http://a.example/old\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("text report missing %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "Flaky") {
+		t.Errorf("text report lists flaky links when there are none:\n%s", got)
+	}
+
+	buf.Reset()
+	if err := WriteText(&buf, nil); err != nil {
+		t.Fatalf("WriteText(nil) error: %v", err)
+	}
+	if buf.String() != "No runs recorded.\n" {
+		t.Errorf("WriteText(nil) = %q", buf.String())
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, sampleTrends()); err != nil {
+		t.Fatalf("WriteCSV() error: %v", err)
+	}
+
+	want := "site,started_at,checked,broken,newly_broken,fixed,flaky\n" +
+		"http://a.example/,2026-01-01T00:00:00Z,0,1,0,0,0\n" +
+		"http://a.example/,2026-01-02T00:00:00Z,0,1,1,1,0\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestWriteHTML(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteHTML(&buf, sampleTrends()); err != nil {
+		t.Fatalf("WriteHTML() error: %v", err)
+	}
+	got := buf.String()
+
+	if !strings.Contains(got, "<h2>http://a.example/</h2>") {
+		t.Error("HTML report missing site heading")
+	}
+	if !strings.Contains(got, "http://a.example/&lt;new&gt;") {
+		t.Error("HTML report did not escape link text")
+	}
+	if strings.Contains(got, "<new>") {
+		t.Error("HTML report contains unescaped link text")
+	}
+}
@@ -0,0 +1,120 @@
+package history
+
+import (
+	"slices"
+	"time"
+)
+
+// SiteTrend summarizes the stored runs for one site.
+type SiteTrend struct {
+	Site   string       // The crawl's start URL
+	Points []TrendPoint // One point per run, oldest first
+	Flaky  []string     // Links that changed state at least twice: broke and recovered, or recovered and broke again
+}
+
+// TrendPoint compares one run against the previous run of the same site.
+type TrendPoint struct {
+	StartedAt    time.Time // When the run began
+	Checked      int       // URLs checked
+	Broken       int       // Broken links found
+	NewlyBroken  []string  // Broken now but not in the previous run
+	Fixed        []string  // Broken in the previous run but not now
+	BrokenCounts []int     // Broken counts up to and including this run, for sparklines
+}
+
+// minFlakyFlips is how many broken/healthy transitions mark a link as flaky.
+// One flip is an ordinary breakage or fix; two or more means it alternates.
+const minFlakyFlips = 2
+
+// Trends groups runs by site and computes per-run changes. Sites are returned
+// in the order their first run appears; runs must be sorted oldest first, as
+// returned by Load.
+func Trends(runs []Run) []SiteTrend {
+	var order []string
+	bySite := make(map[string][]Run)
+	for _, run := range runs {
+		if _, seen := bySite[run.Site]; !seen {
+			order = append(order, run.Site)
+		}
+		bySite[run.Site] = append(bySite[run.Site], run)
+	}
+
+	trends := make([]SiteTrend, 0, len(order))
+	for _, site := range order {
+		trends = append(trends, siteTrend(site, bySite[site]))
+	}
+	return trends
+}
+
+// siteTrend computes the trend for the runs of a single site.
+func siteTrend(site string, runs []Run) SiteTrend {
+	trend := SiteTrend{Site: site}
+
+	// flips counts transitions per link; a link absent from a run is healthy
+	flips := make(map[string]int)
+	var counts []int
+	var previous []string
+	for i, run := range runs {
+		counts = append(counts, len(run.Broken))
+		point := TrendPoint{
+			StartedAt:    run.StartedAt,
+			Checked:      run.Stats.TotalChecked,
+			Broken:       len(run.Broken),
+			BrokenCounts: slices.Clone(counts),
+		}
+		if i > 0 {
+			point.NewlyBroken = Difference(run.Broken, previous)
+			point.Fixed = Difference(previous, run.Broken)
+			for _, link := range point.NewlyBroken {
+				flips[link]++
+			}
+			for _, link := range point.Fixed {
+				flips[link]++
+			}
+		}
+		trend.Points = append(trend.Points, point)
+		previous = run.Broken
+	}
+
+	for link, n := range flips {
+		if n >= minFlakyFlips {
+			trend.Flaky = append(trend.Flaky, link)
+		}
+	}
+	slices.Sort(trend.Flaky)
+	return trend
+}
+
+// Difference returns the links in a that are not in b, in a's order. b must
+// be sorted, as Run.Broken is. The result is never nil, so it encodes as an
+// empty JSON array.
+func Difference(a, b []string) []string {
+	out := []string{}
+	for _, link := range a {
+		if _, found := slices.BinarySearch(b, link); !found {
+			out = append(out, link)
+		}
+	}
+	return out
+}
+
+// sparkBlocks are the glyphs used by Sparkline, lowest to highest.
+var sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// Sparkline renders values as a row of block characters scaled between the
+// smallest and largest value. A flat series renders at the lowest level.
+func Sparkline(values []int) string {
+	if len(values) == 0 {
+		return ""
+	}
+	lo, hi := slices.Min(values), slices.Max(values)
+	out := make([]rune, len(values))
+	for i, v := range values {
+		level := 0
+		if hi > lo {
+			level = (v - lo) * (len(sparkBlocks) - 1) / (hi - lo)
+		}
+		out[i] = sparkBlocks[level]
+	}
+	return string(out)
+}
@@ -0,0 +1,92 @@
+package history
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+// runAt builds a run for site on day d of January 2026.
+func runAt(site string, d int, broken ...string) Run {
+	return Run{Site: site, StartedAt: time.Date(2026, 1, d, 0, 0, 0, 0, time.UTC), Broken: broken}
+}
+
+func TestTrends(t *testing.T) {
+	runs := []Run{
+		runAt("a", 1, "/flaky", "/gone"),
+		runAt("b", 1),
+		runAt("a", 2, "/new"),
+		runAt("a", 3, "/flaky", "/new"),
+	}
+
+	trends := Trends(runs)
+
+	if len(trends) != 2 || trends[0].Site != "a" || trends[1].Site != "b" {
+		t.Fatalf("Trends() sites = %+v, want [a b] in first-seen order", trends)
+	}
+	a := trends[0]
+	if len(a.Points) != 3 {
+		t.Fatalf("site a has %d points, want 3", len(a.Points))
+	}
+
+	second := a.Points[1]
+	if !slices.Equal(second.NewlyBroken, []string{"/new"}) {
+		t.Errorf("run 2 NewlyBroken = %v, want [/new]", second.NewlyBroken)
+	}
+	if !slices.Equal(second.Fixed, []string{"/flaky", "/gone"}) {
+		t.Errorf("run 2 Fixed = %v, want [/flaky /gone]", second.Fixed)
+	}
+
+	third := a.Points[2]
+	if !slices.Equal(third.NewlyBroken, []string{"/flaky"}) || len(third.Fixed) != 0 {
+		t.Errorf("run 3 NewlyBroken = %v, Fixed = %v", third.NewlyBroken, third.Fixed)
+	}
+	if !slices.Equal(third.BrokenCounts, []int{2, 1, 2}) {
+		t.Errorf("run 3 BrokenCounts = %v, want [2 1 2]", third.BrokenCounts)
+	}
+
+	// /flaky broke, recovered, and broke again; /gone was simply fixed
+	if !slices.Equal(a.Flaky, []string{"/flaky"}) {
+		t.Errorf("Flaky = %v, want [/flaky]", a.Flaky)
+	}
+}
+
+func TestTrends_FlakyRecoveredAndBrokeAgain(t *testing.T) {
+	runs := []Run{
+		runAt("a", 1),
+		runAt("a", 2, "/blip", "/broke"),
+		runAt("a", 3, "/broke"),
+	}
+
+	// /blip was healthy, broke, and recovered; /broke only broke once
+	if flaky := Trends(runs)[0].Flaky; !slices.Equal(flaky, []string{"/blip"}) {
+		t.Errorf("Flaky = %v, want [/blip]", flaky)
+	}
+}
+
+func TestDifference(t *testing.T) {
+	if got := Difference([]string{"c", "a", "b"}, []string{"a", "d"}); !slices.Equal(got, []string{"c", "b"}) {
+		t.Errorf("Difference() = %v, want [c b]", got)
+	}
+	if got := Difference([]string{"a"}, []string{"a"}); got == nil || len(got) != 0 {
+		t.Errorf("Difference() = %#v, want an empty, non-nil slice", got)
+	}
+}
+
+func TestSparkline(t *testing.T) {
+	tests := []struct {
+		values []int
+		want   string
+	}{
+		{nil, ""},
+		{[]int{3, 3, 3}, "▁▁▁"},
+		{[]int{0, 7}, "▁█"},
+		{[]int{0, 1, 2, 3, 4, 5, 6, 7}, "▁▂▃▄▅▆▇█"},
+		{[]int{10, 0, 5}, "█▁▄"},
+	}
+	for _, tt := range tests {
+		if got := Sparkline(tt.values); got != tt.want {
+			t.Errorf("Sparkline(%v) = %q, want %q", tt.values, got, tt.want)
+		}
+	}
+}
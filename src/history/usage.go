@@ -0,0 +1,164 @@
+package history
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"maps"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+)
+
+// usageMonthFormat keys the monthly trend of a usage summary.
+const usageMonthFormat = "2006-01"
+
+// Usage summarizes every run in a run database across sites: how much the
+// tool has been used and what it keeps finding. It is computed locally and
+// never sent anywhere.
+type Usage struct {
+	Runs            int           `json:"runs"`             // Crawls recorded
+	Sites           int           `json:"sites"`            // Distinct start URLs crawled
+	First           time.Time     `json:"first"`            // When the earliest run began
+	Last            time.Time     `json:"last"`             // When the latest run began
+	Checked         int           `json:"checked"`          // URLs checked over all runs
+	Broken          int           `json:"broken"`           // Broken links found over all runs
+	AverageDuration time.Duration `json:"average_duration"` // Mean crawl duration
+	BrokenHosts     []HostCount   `json:"broken_hosts"`     // Hosts with the most broken links, most first
+	Months          []UsageMonth  `json:"months"`           // Runs per calendar month, oldest first
+}
+
+// HostCount is how often a host had broken links across runs.
+type HostCount struct {
+	Host   string `json:"host"`
+	Broken int    `json:"broken"` // Broken links on the host, counted once per run they were found in
+	Runs   int    `json:"runs"`   // Runs that found at least one
+}
+
+// UsageMonth is the runs of one calendar month (UTC).
+type UsageMonth struct {
+	Month           string        `json:"month"` // e.g. "2026-01"
+	Runs            int           `json:"runs"`
+	AverageDuration time.Duration `json:"average_duration"`
+	AverageBroken   float64       `json:"average_broken"` // Mean broken links per run
+}
+
+// Summarize computes the usage summary of runs, listing at most topHosts
+// broken hosts (0 = all). Ties between hosts are broken by name.
+func Summarize(runs []Run, topHosts int) Usage {
+	usage := Usage{Runs: len(runs), BrokenHosts: []HostCount{}, Months: []UsageMonth{}}
+	if len(runs) == 0 {
+		return usage
+	}
+
+	sites := make(map[string]bool)
+	hosts := make(map[string]*HostCount)
+	months := make(map[string]*monthTotals)
+	var totalDuration time.Duration
+	usage.First, usage.Last = runs[0].StartedAt, runs[0].StartedAt
+	for _, run := range runs {
+		sites[run.Site] = true
+		if run.StartedAt.Before(usage.First) {
+			usage.First = run.StartedAt
+		}
+		if run.StartedAt.After(usage.Last) {
+			usage.Last = run.StartedAt
+		}
+		usage.Checked += run.Stats.TotalChecked
+		usage.Broken += len(run.Broken)
+		totalDuration += run.Stats.Duration
+
+		inRun := make(map[string]bool)
+		for _, link := range run.Broken {
+			host := brokenHost(link)
+			if hosts[host] == nil {
+				hosts[host] = &HostCount{Host: host}
+			}
+			hosts[host].Broken++
+			if !inRun[host] {
+				inRun[host] = true
+				hosts[host].Runs++
+			}
+		}
+
+		key := run.StartedAt.UTC().Format(usageMonthFormat)
+		if months[key] == nil {
+			months[key] = &monthTotals{}
+		}
+		months[key].runs++
+		months[key].duration += run.Stats.Duration
+		months[key].broken += len(run.Broken)
+	}
+	usage.Sites = len(sites)
+	usage.AverageDuration = totalDuration / time.Duration(len(runs))
+
+	for _, host := range hosts {
+		usage.BrokenHosts = append(usage.BrokenHosts, *host)
+	}
+	slices.SortFunc(usage.BrokenHosts, func(a, b HostCount) int {
+		return cmp.Or(cmp.Compare(b.Broken, a.Broken), cmp.Compare(a.Host, b.Host))
+	})
+	if topHosts > 0 && len(usage.BrokenHosts) > topHosts {
+		usage.BrokenHosts = usage.BrokenHosts[:topHosts]
+	}
+
+	for _, key := range slices.Sorted(maps.Keys(months)) {
+		totals := months[key]
+		usage.Months = append(usage.Months, UsageMonth{
+			Month:           key,
+			Runs:            totals.runs,
+			AverageDuration: totals.duration / time.Duration(totals.runs),
+			AverageBroken:   float64(totals.broken) / float64(totals.runs),
+		})
+	}
+	return usage
+}
+
+// monthTotals accumulates the runs of one month.
+type monthTotals struct {
+	runs     int
+	duration time.Duration
+	broken   int
+}
+
+// brokenHost returns the lowercase host of a broken link, or the link itself
+// if it has none.
+func brokenHost(link string) string {
+	parsed, err := url.Parse(link)
+	if err != nil || parsed.Hostname() == "" {
+		return link
+	}
+	return strings.ToLower(parsed.Hostname())
+}
+
+// WriteUsage writes a plain-text usage summary to w.
+func WriteUsage(w io.Writer, usage Usage) error {
+	var b strings.Builder
+	writef := func(format string, a ...any) { _, _ = fmt.Fprintf(&b, format, a...) }
+
+	if usage.Runs == 0 {
+		writef("No runs recorded.\n")
+	} else {
+		writef("Crawls: %d of %d sites, %s to %s\n", usage.Runs, usage.Sites,
+			usage.First.Format(reportTimeFormat), usage.Last.Format(reportTimeFormat))
+		writef("Average duration: %s\n", usage.AverageDuration.Round(time.Millisecond))
+		writef("Checked %d URLs, found %d broken links\n", usage.Checked, usage.Broken)
+		if len(usage.BrokenHosts) > 0 {
+			writef("\nMost frequently broken hosts:\n")
+			for _, host := range usage.BrokenHosts {
+				writef("  %-40s  %5d broken in %d runs\n", host.Host, host.Broken, host.Runs)
+			}
+		}
+		writef("\nBy month:\n")
+		writef("  %-7s  %5s  %12s  %10s\n", "Month", "Runs", "Avg duration", "Avg broken")
+		for _, month := range usage.Months {
+			writef("  %-7s  %5d  %12s  %10.1f\n", month.Month, month.Runs, month.AverageDuration.Round(time.Millisecond), month.AverageBroken)
+		}
+	}
+
+	if _, err := io.WriteString(w, b.String()); err != nil {
+		return fmt.Errorf("write usage: %w", err)
+	}
+	return nil
+}
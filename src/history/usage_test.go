@@ -0,0 +1,82 @@
+package history
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lukemcguire/zombiecrawl/result"
+)
+
+func TestSummarize(t *testing.T) {
+	run := func(site string, month time.Month, duration time.Duration, broken ...string) Run {
+		return Run{
+			Site:      site,
+			StartedAt: time.Date(2026, month, 10, 0, 0, 0, 0, time.UTC),
+			Stats:     result.CrawlStats{TotalChecked: 10, Duration: duration},
+			Broken:    broken,
+		}
+	}
+	runs := []Run{
+		run("http://a.example/", 1, 2*time.Second, "http://cdn.example/x", "http://cdn.example/y", "http://A.example/gone"),
+		run("http://b.example/", 1, 4*time.Second, "http://b.example/gone"),
+		run("http://a.example/", 2, 6*time.Second, "http://cdn.example/x"),
+	}
+
+	usage := Summarize(runs, 2)
+	if usage.Runs != 3 || usage.Sites != 2 || usage.Checked != 30 || usage.Broken != 5 || usage.AverageDuration != 4*time.Second {
+		t.Errorf("totals = %+v", usage)
+	}
+	if !usage.First.Equal(runs[0].StartedAt) || !usage.Last.Equal(runs[2].StartedAt) {
+		t.Errorf("span = %v to %v", usage.First, usage.Last)
+	}
+	wantHosts := []HostCount{{Host: "cdn.example", Broken: 3, Runs: 2}, {Host: "a.example", Broken: 1, Runs: 1}}
+	if !slices.Equal(usage.BrokenHosts, wantHosts) {
+		t.Errorf("BrokenHosts = %+v, want %+v", usage.BrokenHosts, wantHosts)
+	}
+	wantMonths := []UsageMonth{
+		{Month: "2026-01", Runs: 2, AverageDuration: 3 * time.Second, AverageBroken: 2},
+		{Month: "2026-02", Runs: 1, AverageDuration: 6 * time.Second, AverageBroken: 1},
+	}
+	if !slices.Equal(usage.Months, wantMonths) {
+		t.Errorf("Months = %+v, want %+v", usage.Months, wantMonths)
+	}
+
+	empty := Summarize(nil, 10)
+	if empty.Runs != 0 || empty.BrokenHosts == nil || empty.Months == nil {
+		t.Errorf("Summarize(nil) = %+v, want zero totals and empty lists", empty)
+	}
+}
+
+func TestWriteUsage(t *testing.T) {
+	usage := Summarize([]Run{
+		{Site: "http://a.example/", StartedAt: time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC),
+			Stats: result.CrawlStats{TotalChecked: 7, Duration: 1500 * time.Millisecond}, Broken: []string{"http://cdn.example/x"}},
+	}, 10)
+	var buf bytes.Buffer
+	if err := WriteUsage(&buf, usage); err != nil {
+		t.Fatalf("WriteUsage() error: %v", err)
+	}
+	got := buf.String()
+	for _, want := range []string{
+		"Crawls: 1 of 1 sites, 2026-01-02 00:00 to 2026-01-02 00:00\n",
+		"Average duration: 1.5s\n",
+		"Checked 7 URLs, found 1 broken links\n",
+		"Most frequently broken hosts:\n  cdn.example",
+		"2026-01",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("usage missing %q:\n%s", want, got)
+		}
+	}
+
+	buf.Reset()
+	if err := WriteUsage(&buf, Summarize(nil, 10)); err != nil {
+		t.Fatalf("WriteUsage(empty) error: %v", err)
+	}
+	if buf.String() != "No runs recorded.\n" {
+		t.Errorf("WriteUsage(empty) = %q", buf.String())
+	}
+}
@@ -0,0 +1,127 @@
+package bench
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"time"
+
+	"github.com/lukemcguire/zombiecrawl/crawler"
+)
+
+// DefaultRate is the request rate Run allows when Options.Rate is unset. It
+// is high enough that the crawler's own limits, not the rate limiter,
+// decide the throughput.
+const DefaultRate = 10_000
+
+// Options configures a benchmark.
+type Options struct {
+	Site        SiteOptions
+	Concurrency int              // Crawler workers (0 = 10)
+	Runs        int              // Crawls of the site to time (0 = 1)
+	Rate        float64          // Requests per second allowed (0 = DefaultRate)
+	Strategy    crawler.Strategy // Crawl order ("" = breadth-first)
+}
+
+// RunStats is the outcome of one timed crawl.
+type RunStats struct {
+	Duration       time.Duration `json:"duration"`
+	Checked        int           `json:"checked"`
+	Broken         int           `json:"broken"`
+	PagesPerSecond float64       `json:"pages_per_second"`
+}
+
+// Report is the outcome of a benchmark.
+type Report struct {
+	Site        SiteOptions `json:"site"`
+	Concurrency int         `json:"concurrency"`
+	Runs        []RunStats  `json:"runs"`
+
+	// ExpectedBroken is how many broken links the site has; a run that found
+	// a different number is reported in Mismatches.
+	ExpectedBroken int `json:"expected_broken"`
+	Mismatches     int `json:"mismatches"`
+}
+
+// Run serves a synthetic site for opts.Site and crawls it opts.Runs times.
+func Run(ctx context.Context, opts Options) (Report, error) {
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = 10
+	}
+	opts.Runs = max(opts.Runs, 1)
+	if opts.Rate <= 0 {
+		opts.Rate = DefaultRate
+	}
+
+	site := NewSite(opts.Site)
+	defer site.Close()
+
+	report := Report{Site: opts.Site, Concurrency: opts.Concurrency, ExpectedBroken: site.Broken}
+	for range opts.Runs {
+		c, err := crawler.New(crawler.Config{
+			StartURL:      site.URL,
+			Concurrency:   opts.Concurrency,
+			Delay:         1,
+			RatePerMinute: opts.Rate * 60,
+			MaxRate:       opts.Rate,
+			Burst:         opts.Concurrency,
+			Strategy:      opts.Strategy,
+			IgnoreRobots:  true,
+		}, nil)
+		if err != nil {
+			return report, fmt.Errorf("create crawler: %w", err)
+		}
+		res, err := c.Run(ctx)
+		if err != nil {
+			return report, fmt.Errorf("crawl: %w", err)
+		}
+		stats := RunStats{
+			Duration:       res.Stats.Duration,
+			Checked:        res.Stats.TotalChecked,
+			Broken:         res.Stats.BrokenCount,
+			PagesPerSecond: res.Stats.PagesPerSecond,
+		}
+		if stats.Broken != site.Broken {
+			report.Mismatches++
+		}
+		report.Runs = append(report.Runs, stats)
+	}
+	return report, nil
+}
+
+// Median returns the run with the median duration.
+func (r Report) Median() RunStats {
+	if len(r.Runs) == 0 {
+		return RunStats{}
+	}
+	runs := slices.Clone(r.Runs)
+	slices.SortFunc(runs, func(a, b RunStats) int { return int(a.Duration - b.Duration) })
+	return runs[len(runs)/2]
+}
+
+// WriteText writes a human-readable summary of the report to w.
+func (r Report) WriteText(w io.Writer) error {
+	median := r.Median()
+	_, err := fmt.Fprintf(w,
+		"Site: %d pages, fanout %d, latency %s, error rate %.1f%%\n"+
+			"Concurrency: %d, runs: %d\n"+
+			"Median: %d URLs in %s (%.1f URLs/s)\n",
+		r.Site.Pages, r.Site.Fanout, r.Site.Latency, r.Site.ErrorRate*100,
+		r.Concurrency, len(r.Runs),
+		median.Checked, median.Duration.Round(time.Millisecond), median.PagesPerSecond)
+	if err != nil {
+		return err
+	}
+	for i, run := range r.Runs {
+		if _, err := fmt.Fprintf(w, "  run %d: %s, %.1f URLs/s, %d broken\n",
+			i+1, run.Duration.Round(time.Millisecond), run.PagesPerSecond, run.Broken); err != nil {
+			return err
+		}
+	}
+	if r.Mismatches > 0 {
+		_, err = fmt.Fprintf(w, "Warning: %d of %d runs did not find the site's %d broken links\n",
+			r.Mismatches, len(r.Runs), r.ExpectedBroken)
+	}
+	return err
+}
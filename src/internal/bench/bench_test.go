@@ -0,0 +1,51 @@
+package bench
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRun(t *testing.T) {
+	report, err := Run(context.Background(), Options{
+		Site: SiteOptions{Pages: 50, Fanout: 5, ErrorRate: 0.1, Seed: 3},
+		Runs: 2,
+	})
+	if err != nil {
+		t.Fatalf("Run() error: %v", err)
+	}
+	if len(report.Runs) != 2 {
+		t.Fatalf("got %d runs, want 2", len(report.Runs))
+	}
+	if report.Mismatches != 0 {
+		t.Errorf("runs found %+v broken, want %d each", report.Runs, report.ExpectedBroken)
+	}
+	// Every page plus every broken link
+	if want := 50 + report.ExpectedBroken; report.Runs[0].Checked != want {
+		t.Errorf("checked %d URLs, want %d", report.Runs[0].Checked, want)
+	}
+}
+
+func TestReport_WriteText(t *testing.T) {
+	report := Report{
+		Site:           SiteOptions{Pages: 10, Fanout: 2},
+		Concurrency:    4,
+		Runs:           []RunStats{{Duration: 3 * time.Second}, {Duration: time.Second, Checked: 12, PagesPerSecond: 12}, {Duration: 2 * time.Second}},
+		ExpectedBroken: 1,
+		Mismatches:     1,
+	}
+	if got := report.Median().Duration; got != 2*time.Second {
+		t.Errorf("Median() duration = %v, want 2s", got)
+	}
+	var buf bytes.Buffer
+	if err := report.WriteText(&buf); err != nil {
+		t.Fatalf("WriteText() error: %v", err)
+	}
+	for _, want := range []string{"Site: 10 pages, fanout 2", "Concurrency: 4, runs: 3", "run 2: 1s, 12.0 URLs/s", "1 of 3 runs"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("report missing %q:\n%s", want, buf.String())
+		}
+	}
+}
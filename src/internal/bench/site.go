@@ -0,0 +1,108 @@
+// Package bench measures crawl throughput against a synthetic in-process
+// site, so changes to the transport, frontier, or worker pool can be judged
+// by numbers rather than impressions. It backs the hidden "zombiecrawl bench"
+// command.
+package bench
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SiteOptions shapes a synthetic site.
+type SiteOptions struct {
+	Pages     int           `json:"pages"`      // Pages on the site, all reachable from the home page (minimum 1)
+	Fanout    int           `json:"fanout"`     // Links on each page
+	Latency   time.Duration `json:"latency"`    // Delay before every response
+	ErrorRate float64       `json:"error_rate"` // Fraction of links, 0 to 1, that point to pages returning 404
+	Seed      uint64        `json:"seed"`       // Seeds link targets, so equal options give equal sites
+}
+
+// Site is a running synthetic site. Page 0 is served at /, page n at
+// /page/n, and each broken link points to its own /missing/ path.
+type Site struct {
+	URL    string // Base URL of the site, without a trailing slash
+	Broken int    // Distinct broken links on the site
+	server *httptest.Server
+	links  [][]string
+}
+
+// NewSite generates a site for opts and starts serving it. Close it when done.
+func NewSite(opts SiteOptions) *Site {
+	opts.Pages = max(opts.Pages, 1)
+	opts.Fanout = max(opts.Fanout, 0)
+	rng := rand.New(rand.NewPCG(opts.Seed, opts.Seed))
+
+	site := &Site{links: make([][]string, opts.Pages)}
+	for page := range opts.Pages {
+		links := make([]string, 0, opts.Fanout)
+		for i := range opts.Fanout {
+			switch {
+			case rng.Float64() < opts.ErrorRate:
+				links = append(links, fmt.Sprintf("/missing/%d-%d", page, i))
+				site.Broken++
+			case i == 0 && page+1 < opts.Pages:
+				// Chain every page to the next so the whole site is reachable
+				links = append(links, pagePath(page+1))
+			default:
+				links = append(links, pagePath(rng.IntN(opts.Pages)))
+			}
+		}
+		site.links[page] = links
+	}
+
+	site.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if opts.Latency > 0 {
+			time.Sleep(opts.Latency)
+		}
+		page, ok := parsePagePath(r.URL.Path, opts.Pages)
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		var b strings.Builder
+		fmt.Fprintf(&b, "<html><body><h1>Page %d</h1>\n", page)
+		for _, link := range site.links[page] {
+			fmt.Fprintf(&b, "<a href=%q>%s</a>\n", link, link)
+		}
+		b.WriteString("</body></html>\n")
+		_, _ = w.Write([]byte(b.String()))
+	}))
+	site.URL = site.server.URL
+	return site
+}
+
+// Close stops serving the site.
+func (s *Site) Close() {
+	s.server.Close()
+}
+
+// pagePath returns the path of page n.
+func pagePath(n int) string {
+	if n == 0 {
+		return "/"
+	}
+	return "/page/" + strconv.Itoa(n)
+}
+
+// parsePagePath returns the page number served at path, if any.
+func parsePagePath(path string, pages int) (int, bool) {
+	if path == "/" {
+		return 0, true
+	}
+	rest, ok := strings.CutPrefix(path, "/page/")
+	if !ok {
+		return 0, false
+	}
+	n, err := strconv.Atoi(rest)
+	if err != nil || n < 1 || n >= pages {
+		return 0, false
+	}
+	return n, true
+}
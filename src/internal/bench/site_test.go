@@ -0,0 +1,59 @@
+package bench
+
+import (
+	"net/http"
+	"slices"
+	"testing"
+)
+
+func TestNewSite(t *testing.T) {
+	opts := SiteOptions{Pages: 20, Fanout: 4, ErrorRate: 0.25, Seed: 7}
+	site := NewSite(opts)
+	defer site.Close()
+
+	for path, want := range map[string]int{
+		"/":          http.StatusOK,
+		"/page/19":   http.StatusOK,
+		"/page/20":   http.StatusNotFound,
+		"/missing/1": http.StatusNotFound,
+	} {
+		resp, err := http.Get(site.URL + path)
+		if err != nil {
+			t.Fatalf("GET %s: %v", path, err)
+		}
+		_ = resp.Body.Close()
+		if resp.StatusCode != want {
+			t.Errorf("GET %s = %d, want %d", path, resp.StatusCode, want)
+		}
+	}
+
+	if site.Broken == 0 {
+		t.Error("error rate 0.25 produced no broken links")
+	}
+	same := NewSite(opts)
+	defer same.Close()
+	if same.Broken != site.Broken || !slices.EqualFunc(same.links, site.links, slices.Equal) {
+		t.Error("equal options generated different sites")
+	}
+}
+
+func TestParsePagePath(t *testing.T) {
+	tests := []struct {
+		path string
+		want int
+		ok   bool
+	}{
+		{"/", 0, true},
+		{"/page/3", 3, true},
+		{"/page/0", 0, false},
+		{"/page/10", 0, false},
+		{"/page/x", 0, false},
+		{"/other", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := parsePagePath(tt.path, 10)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("parsePagePath(%q) = %d, %v; want %d, %v", tt.path, got, ok, tt.want, tt.ok)
+		}
+	}
+}
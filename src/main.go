@@ -2,98 +2,1671 @@
 package main
 
 import (
+	"bufio"
+	"cmp"
 	"context"
+	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
 	"io"
+	"io/fs"
+	"log/slog"
+	"math"
+	"net"
+	"net/http"
 	"net/url"
 	"os"
+	"os/exec"
+	"os/signal"
+	"path"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"strings"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/lukemcguire/zombiecrawl/crawler"
+	"github.com/lukemcguire/zombiecrawl/fix"
+	"github.com/lukemcguire/zombiecrawl/history"
+	"github.com/lukemcguire/zombiecrawl/internal/bench"
+	"github.com/lukemcguire/zombiecrawl/notify"
 	"github.com/lukemcguire/zombiecrawl/result"
+	"github.com/lukemcguire/zombiecrawl/server"
 	"github.com/lukemcguire/zombiecrawl/tui"
+	"github.com/lukemcguire/zombiecrawl/urlutil"
 )
 
+// stringList is a repeatable string flag.
+type stringList []string
+
+// String implements flag.Value.
+func (s *stringList) String() string { return strings.Join(*s, ", ") }
+
+// Set implements flag.Value, appending each occurrence.
+func (s *stringList) Set(value string) error {
+	*s = append(*s, value)
+	return nil
+}
+
 // cliFlags holds parsed command-line flags.
 type cliFlags struct {
+	profile         string
 	concurrency     int
+	maxPerHost      int
 	delay           int
+	ratePerMinute   float64
+	burst           int
+	minRate         float64
+	maxRate         float64
 	disableAutoTune bool
 	verboseNetwork  bool
+	timeout         time.Duration
+	dialTimeout     time.Duration
+	tlsTimeout      time.Duration
+	headerTimeout   time.Duration
+	queueTimeout    time.Duration
+	slowRequest     time.Duration
+	hardTimeout     time.Duration
+	streamTime      time.Duration
+	streamSize      string
+	maxPageSize     string
+	logFile         string
+	logLevel        string
+	ipVersion       string
+	dnsServer       string
+	resolve         stringList
+	maxBandwidth    string
 	retries         int
 	retryDelay      time.Duration
+	retryBudget     int
+	maxRedirects    int
+	followRedirects string
 	userAgent       string
+	userAgents      stringList
+	hostUserAgents  stringList
+	blockHosts      stringList
+	plugins         stringList
+	scrubParams     stringList
+	expectStatus    stringList
+	timeoutOverride stringList
+	hostConfig      string
+	checks          string
+	contentChecks   string
+	rewriteRules    string
+	accept          string
+	acceptLanguage  string
+	sendReferer     bool
+	linkHygiene     bool
+	strictURLs      bool
+	deterministic   bool
+	accessibility   bool
+	seo             bool
+	siteStructure   bool
+	maxOutbound     int
+	maxLinksPerPage int
+	checkHTTPS      bool
+	checkSlash      bool
+	foldSchemes     bool
+	dedupQuery      bool
+	dedupIgnore     stringList
+	stripSessions   bool
+	checkFragments  bool
+	checkFeeds      bool
+	checkPDFs       bool
 	depth           int
+	strategy        string
+	sitemap         bool
+	robotsCacheSize int
+	externalRobots  bool
+	strictRobots    bool
+	robotsFile      string
+	as              string
+	compareAs       string
+	verify          bool
+	verifyDelay     time.Duration
+	verifyWorkers   int
 	outputJSON      bool
 	outputCSV       bool
 	outputFile      string
+	dryRun          bool
+	fix             bool
+	urlFile         string
+	db              string
+	cacheTTL        time.Duration
+	cacheFile       string
+	har             string
+	saveState       string
+	keepNoArchive   bool
+	replay          string
+	notify          stringList
+	notifyMinNew    int
+	reportURL       string
+	suggestArchive  bool
+	baseline        string
+	diffFormat      string
+	template        string
+	splitOutput     string
+	splitByHost     bool
+	jsonEnvelope    bool
+	stream          bool
+	fullURLs        bool
+	noColor         bool
+	browse          bool
+	recentBroken    int
+	sort            string
+	summaryOnly     bool
+	progressJSON    bool
+	theme           string
+
+	runID string // Shared by every crawl of this execution; set by main, not a flag
+}
+
+// version is the zombiecrawl release, set at build time with
+// -ldflags "-X main.version=...". Unset builds fall back to the module
+// version recorded by "go install".
+var version = ""
+
+// commit and buildDate identify the source and time of a release build, set
+// like version with -ldflags "-X main.commit=... -X main.buildDate=...".
+// Unset builds fall back to the VCS stamp recorded by "go build".
+var (
+	commit    = ""
+	buildDate = ""
+)
+
+// buildInfo describes the running binary for "zombiecrawl version".
+type buildInfo struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit,omitempty"`
+	BuildDate string `json:"build_date,omitempty"` // Set by -ldflags, else the commit time
+	Modified  bool   `json:"modified,omitempty"`   // Built from a working tree with uncommitted changes
+	GoVersion string `json:"go_version"`
+	Platform  string `json:"platform"`
+}
+
+// readBuildInfo collects the version, commit, and build date set with
+// -ldflags, filling gaps from the build info embedded by the Go toolchain.
+func readBuildInfo() buildInfo {
+	info := buildInfo{
+		Version:   toolVersion(),
+		Commit:    commit,
+		BuildDate: buildDate,
+		GoVersion: runtime.Version(),
+		Platform:  runtime.GOOS + "/" + runtime.GOARCH,
+	}
+	embedded, ok := debug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+	for _, setting := range embedded.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			info.Commit = cmp.Or(info.Commit, setting.Value)
+		case "vcs.time":
+			info.BuildDate = cmp.Or(info.BuildDate, setting.Value)
+		case "vcs.modified":
+			info.Modified = setting.Value == "true"
+		}
+	}
+	return info
+}
+
+// runVersion implements "zombiecrawl version": it prints what was built and
+// with which toolchain, as text or with --json as a JSON object.
+func runVersion(args []string) error {
+	versionFlags := flag.NewFlagSet("version", flag.ContinueOnError)
+	asJSON := versionFlags.Bool("json", false, "print the build information as JSON")
+	if err := versionFlags.Parse(args); err != nil {
+		return err
+	}
+	if versionFlags.NArg() > 0 {
+		return fmt.Errorf("version: unexpected argument %q", versionFlags.Arg(0))
+	}
+
+	info := readBuildInfo()
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(info); err != nil {
+			return fmt.Errorf("version: write json: %w", err)
+		}
+		return nil
+	}
+	fmt.Printf("zombiecrawl %s\n", info.Version)
+	if info.Commit != "" {
+		modified := ""
+		if info.Modified {
+			modified = " (modified)"
+		}
+		fmt.Printf("  commit:     %s%s\n", info.Commit, modified)
+	}
+	if info.BuildDate != "" {
+		fmt.Printf("  built:      %s\n", info.BuildDate)
+	}
+	fmt.Printf("  go version: %s\n", info.GoVersion)
+	fmt.Printf("  platform:   %s\n", info.Platform)
+	return nil
+}
+
+// toolVersion returns the version reported in JSON envelopes.
+func toolVersion() string {
+	if version != "" {
+		return version
+	}
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" {
+		return info.Main.Version
+	}
+	return "(devel)"
+}
+
+// parseFlags parses command-line flags and returns the parsed values.
+func parseFlags() *cliFlags {
+	opts := &cliFlags{}
+	flag.StringVar(&opts.profile, "profile", "default", "politeness preset for --concurrency, --max-per-host, --delay, --burst, --min-rate, --max-rate, --retries, and --retry-delay: gentle, default, or aggressive (flags given explicitly win)")
+	flag.IntVar(&opts.concurrency, "concurrency", 10, "number of concurrent workers")
+	flag.IntVar(&opts.maxPerHost, "max-per-host", 0, "most requests in flight to any one host at once (0 = no cap beyond --concurrency)")
+	flag.IntVar(&opts.delay, "delay", 100, "delay between requests in milliseconds")
+	flag.Float64Var(&opts.ratePerMinute, "rate-limit-per-minute", 0, "fixed request rate per minute, may be below 60 for sub-second politeness (overrides --delay, disables auto-tune)")
+	flag.IntVar(&opts.burst, "burst", 0, "requests allowed back-to-back before the rate limit applies (0 = rate rounded up)")
+	flag.Float64Var(&opts.minRate, "min-rate", crawler.DefaultMinRate, "lowest requests per second auto-tune may slow down to")
+	flag.Float64Var(&opts.maxRate, "max-rate", crawler.DefaultMaxRate, "highest requests per second auto-tune may speed up to")
+	flag.BoolVar(&opts.disableAutoTune, "disable-auto-tune", false, "disable adaptive rate limiting (use fixed rate from --delay)")
+	flag.BoolVar(&opts.verboseNetwork, "verbose-network", false, "enable verbose network error diagnostics (DNS, timeout, connection details)")
+	flag.DurationVar(&opts.timeout, "timeout", 10*time.Second, "overall deadline for each request, including redirects and reading the body")
+	flag.DurationVar(&opts.dialTimeout, "dial-timeout", 0, "time allowed to open a connection (0 = limited only by --timeout)")
+	flag.DurationVar(&opts.tlsTimeout, "tls-timeout", 0, "time allowed for the TLS handshake (0 = Go's default of 10s)")
+	flag.DurationVar(&opts.headerTimeout, "response-header-timeout", 0, "time allowed for response headers after the request is sent (0 = limited only by --timeout)")
+	flag.DurationVar(&opts.queueTimeout, "queue-timeout", 0, "report links still waiting to be checked this long after being queued as queued too long (0 = no limit)")
+	flag.DurationVar(&opts.slowRequest, "slow-request", crawler.DefaultSlowRequest, "show checks running longer than this in the progress display")
+	flag.DurationVar(&opts.hardTimeout, "hard-timeout", 0, "cancel checks still running after this, including retries, and report them as timeouts (0 = no limit)")
+	flag.DurationVar(&opts.streamTime, "stream-read-time", crawler.DefaultMaxStreamTime, "stop reading a page of unknown length for links after this and report it as streaming instead of timing out")
+	flag.StringVar(&opts.streamSize, "stream-max-size", "10MiB", "stop reading a page of unknown length for links after this much, e.g. 10MiB, and report it as streaming")
+	flag.StringVar(&opts.maxPageSize, "max-page-size", "", "report internal pages larger than this, e.g. 50MiB, as too large instead of reading them for links (default no limit)")
+	flag.StringVar(&opts.logFile, "log-file", "", "write structured JSON logs of the crawl to file (\"-\" for stderr)")
+	flag.StringVar(&opts.logLevel, "log-level", "info", "lowest level written to --log-file: debug, info, warn, or error")
+	flag.StringVar(&opts.ipVersion, "ip-version", "auto", "IP version to connect with: 4, 6, or auto (use 4 where IPv6 is broken)")
+	flag.StringVar(&opts.dnsServer, "dns", "", "DNS server to resolve hosts with, as ip[:port] (default: the system resolver)")
+	flag.Var(&opts.resolve, "resolve", "connect to an address instead of the DNS answer, as \"host:port:address\" like curl (repeatable)")
+	flag.StringVar(&opts.maxBandwidth, "max-bandwidth", "", "cap on response body download speed across all sites, e.g. 5MB/s or 512KiB/s (default: unlimited)")
+	flag.IntVar(&opts.retries, "retries", 2, "number of retries for transient errors")
+	flag.DurationVar(&opts.retryDelay, "retry-delay", time.Second, "base delay between retries")
+	flag.IntVar(&opts.retryBudget, "host-retry-budget", 0, "most retries spent on any one host over the crawl; failures past it are reported without retrying (0 = unlimited)")
+	flag.IntVar(&opts.maxRedirects, "max-redirects", crawler.DefaultMaxRedirects, "redirect hops followed per link before it is reported as broken")
+	flag.StringVar(&opts.followRedirects, "follow-redirects", "always", "which redirects to follow: always, same-host, or never (unfollowed 3xx responses count as valid)")
+	flag.StringVar(&opts.userAgent, "user-agent", "zombiecrawl/1.0 (+https://github.com/lukemcguire/zombiecrawl)", "user agent string")
+	flag.Var(&opts.userAgents, "rotate-user-agent", "add a user agent to the rotation pool; each host gets one round-robin (repeatable)")
+	flag.StringVar(&opts.hostConfig, "host-config", "", "JSON file of per-host rate limit, header, basic auth, retry, and robots.txt overrides")
+	flag.StringVar(&opts.contentChecks, "content-checks", "", "JSON file of text or regular expressions crawled pages must, or must not, contain")
+	flag.StringVar(&opts.rewriteRules, "rewrite-rules", "", "JSON file of regular expression find/replace rules applied to discovered links before they are queued, e.g. to crawl staging when pages link to production")
+	flag.StringVar(&opts.checks, "checks", "", "JSON file of extra requests to check after the crawl, with method, headers, body, and expected statuses")
+	flag.Var(&opts.hostUserAgents, "host-user-agent", "per-host user agent as \"pattern=agent\", e.g. \"*.example.com=MyBot/1.0\" (repeatable)")
+	flag.Var(&opts.blockHosts, "block-host", "never request hosts matching this pattern, e.g. \"*.google-analytics.com\"; their links are reported as skipped (repeatable)")
+	flag.Var(&opts.plugins, "plugin", "run a command as a plugin that filters links, decides what is queued, and receives the result over JSON lines on stdin/stdout, e.g. \"./policy --strict\" (repeatable)")
+	flag.Var(&opts.timeoutOverride, "timeout-override", "request timeout for matching URLs as \"pattern=timeout\", e.g. \"/export/*=60s\" (repeatable; first match wins)")
+	flag.Var(&opts.expectStatus, "expect-status", "statuses required of matching URLs as \"pattern=status[,status]\", e.g. \"/gone/=410\" (repeatable; patterns starting with / match paths on the crawled site)")
+	flag.StringVar(&opts.accept, "accept", "", "Accept header sent with every request (e.g. \"text/html\")")
+	flag.StringVar(&opts.acceptLanguage, "accept-language", "", "Accept-Language header sent with every request (e.g. \"en-US,en;q=0.9\")")
+	flag.BoolVar(&opts.sendReferer, "send-referer", false, "send the page a link was found on as the Referer header")
+	flag.BoolVar(&opts.checkHTTPS, "check-https", false, "test the https:// version of every working http:// page, flag pages served over both http and https without a redirect, and flag https links that redirect to http")
+	flag.BoolVar(&opts.checkFragments, "check-fragments", false, "flag links to #fragments of the same page when no element has that id")
+	flag.BoolVar(&opts.checkFeeds, "check-feeds", false, "fetch the RSS and Atom feeds pages advertise, check that they parse, and check the link of every entry")
+	flag.BoolVar(&opts.checkPDFs, "check-pdfs", false, "download the start of every PDF link and flag ones without a %PDF header, such as error pages served as PDFs")
+	flag.BoolVar(&opts.foldSchemes, "fold-schemes", false, "treat http:// and https:// links to the same internal page as one page and crawl only the first found")
+	flag.BoolVar(&opts.dedupQuery, "dedup-ignore-query", false, "treat internal URLs that differ only in their query string as one page and crawl only the first found")
+	flag.Var(&opts.dedupIgnore, "dedup-ignore", "treat URLs matching a pattern that differ only in the given query parameters as one page, as \"pattern=param,param\" or \"pattern=*\" for the whole query, e.g. \"/blog/*=print,ref\" (repeatable; first match wins)")
+	flag.BoolVar(&opts.stripSessions, "strip-session-ids", false, "remove session ID and ad click parameters such as PHPSESSID, ;jsessionid=, and gclid from links before crawling them")
+	flag.BoolVar(&opts.checkSlash, "check-trailing-slash", false, "request every internal page with and without a trailing slash and flag pages where the two behave differently")
+	flag.BoolVar(&opts.accessibility, "audit-accessibility", false, "report links without text, images without alt attributes, and links whose text is a raw URL")
+	flag.BoolVar(&opts.seo, "seo", false, "report pages with missing or duplicate titles, meta descriptions too long for search results, broken or one-way hreflang alternates, and broken OpenGraph and Twitter card URLs")
+	flag.BoolVar(&opts.siteStructure, "site-structure", false, "report pages no crawled page links to (reached only from the sitemap) and pages with too many links")
+	flag.IntVar(&opts.maxOutbound, "max-outbound-links", crawler.DefaultMaxOutboundLinks, "links on a page above which --site-structure reports it")
+	flag.BoolVar(&opts.linkHygiene, "link-hygiene", false, "warn about http links on https pages, protocol-relative URLs, and links to IP addresses")
+	flag.BoolVar(&opts.strictURLs, "strict-urls", false, "report links with whitespace, control characters, backslashes, bad percent-encoding, or credentials as malformed instead of following them")
+	flag.BoolVar(&opts.deterministic, "deterministic", false, "crawl with one worker in sorted link order and fixed timings, so repeated runs produce identical reports")
+
+	// Depth control
+	flag.IntVar(&opts.depth, "d", 0, "maximum crawl depth (0 = unlimited)")
+	flag.IntVar(&opts.depth, "depth", 0, "maximum crawl depth (0 = unlimited)")
+	flag.IntVar(&opts.maxLinksPerPage, "max-links-per-page", 0, "links queued from any one page; pages with more are reported as truncated (0 = unlimited)")
+	flag.StringVar(&opts.strategy, "strategy", "bfs", "crawl order: bfs, dfs, or random")
+	flag.IntVar(&opts.robotsCacheSize, "robots-cache-size", crawler.DefaultRobotsCacheSize, "maximum number of hosts whose robots.txt is kept in memory")
+	flag.BoolVar(&opts.externalRobots, "respect-external-robots", false, "skip external links whose host's robots.txt disallows them, instead of validating them anyway")
+	flag.BoolVar(&opts.strictRobots, "robots-strict", false, "evaluate robots.txt like Google: longest matching rule wins, Allow wins ties, * and $ wildcards")
+	flag.StringVar(&opts.robotsFile, "robots-file", "", "use this local robots.txt for the start URL's host instead of fetching it, to test rules before deploying them")
+	flag.StringVar(&opts.as, "as", "", "crawl as a preset identity ("+strings.Join(crawler.PresetNames(), ", ")+"); overrides user agent flags")
+	flag.StringVar(&opts.compareAs, "compare-as", "", "with --as, crawl again as this preset and report URLs only one identity reached")
+	flag.BoolVar(&opts.sitemap, "sitemap", false, "also crawl pages listed in the site's sitemaps (from robots.txt Sitemap: lines, else /sitemap.xml)")
+
+	// Verification pass
+	flag.BoolVar(&opts.suggestArchive, "suggest-archive", false, "look up the nearest Wayback Machine snapshot of dead external links and suggest it as a replacement")
+	flag.BoolVar(&opts.verify, "verify", false, "re-check broken links after the crawl and report ones that recover as flaky")
+	flag.DurationVar(&opts.verifyDelay, "verify-delay", 5*time.Second, "wait before the --verify pass")
+	flag.IntVar(&opts.verifyWorkers, "verify-concurrency", 2, "number of concurrent re-checks in the --verify pass")
+
+	// Output format
+	flag.BoolVar(&opts.outputJSON, "j", false, "output results as JSON")
+	flag.BoolVar(&opts.outputJSON, "json", false, "output results as JSON")
+	flag.BoolVar(&opts.outputCSV, "c", false, "output results as CSV")
+	flag.BoolVar(&opts.outputCSV, "csv", false, "output results as CSV")
+	flag.StringVar(&opts.outputFile, "o", "", "write JSON/CSV output to file")
+	flag.StringVar(&opts.outputFile, "output", "", "write JSON/CSV output to file")
+	flag.BoolVar(&opts.stream, "stream", false, "with -o, append each broken link to the file as it is found (CSV with --csv, else JSON lines)")
+	flag.StringVar(&opts.sort, "sort", "", "order broken links in the summary, JSON, CSV, and --template output by url, status, source, count (URLs broken on the most pages first), or severity (default crawl order; --stream output stays in the order found)")
+	flag.BoolVar(&opts.jsonEnvelope, "json-envelope", false, "wrap JSON output in an object with the tool version, start URL, config, timestamps, and stats")
+	flag.StringVar(&opts.splitOutput, "split-output", "", "also write one JSON file (CSV with --csv) per error category into this directory")
+	flag.BoolVar(&opts.splitByHost, "split-by-host", false, "with --split-output, split each category further into one directory per host")
+	flag.StringVar(&opts.template, "template", "", "render results through this Go text/template file instead of JSON or CSV")
+	flag.IntVar(&opts.recentBroken, "recent-broken", tui.DefaultBrokenFeed, "broken links listed under the progress line as they are found, most recent last (0 = none)")
+	flag.BoolVar(&opts.progressJSON, "progress-json", false, "instead of the live progress view, write a JSON progress record (checked, broken, queued, rate, elapsed) to stderr every "+crawler.DefaultProgressInterval.String()+" and when each crawl ends")
+	flag.BoolVar(&opts.summaryOnly, "summary-only", false, "show no progress while crawling, only the summary at the end (on stderr when JSON, CSV, or --template output goes to stdout)")
+	flag.BoolVar(&opts.browse, "browse", false, "after a crawl with broken links, keep the summary open to copy them (c), copy them all as a Markdown table (m), or open the pages they are on (o)")
+	flag.BoolVar(&opts.noColor, "no-color", false, "print the summary without colors (also set by the NO_COLOR environment variable)")
+	flag.StringVar(&opts.theme, "theme", "auto", "summary colors: auto (match the terminal, none when stdout is not a terminal), "+strings.Join(tui.ThemeNames(), ", "))
+	flag.BoolVar(&opts.fullURLs, "full-urls", false, "show URLs in full in the summary instead of shortening them to the terminal width (toggle with u while crawling)")
+
+	flag.StringVar(&opts.urlFile, "url-file", "", "crawl every URL listed in this file (one per line) concurrently, sharing --concurrency workers")
+	flag.DurationVar(&opts.cacheTTL, "external-cache", 0, "reuse healthy external link verdicts younger than this across runs, and skip re-parsing pages the server reports unchanged (304), e.g. 24h (0 = off)")
+	flag.StringVar(&opts.cacheFile, "external-cache-file", defaultExternalCacheFile(), "file backing --external-cache")
+	flag.Var(&opts.scrubParams, "scrub-param", "redact the value of this query parameter, e.g. token or email, from URLs in output, logs, --har and --save-state files, --db runs, and notifications; requests and the --external-cache-file keep full URLs (repeatable)")
+	flag.StringVar(&opts.db, "db", "", "append each completed crawl to this SQLite run database (see \"zombiecrawl report\" and \"zombiecrawl stats\")")
+	flag.StringVar(&opts.har, "har", "", "record every request and response of the crawl to this file in HAR 1.2 format")
+	flag.StringVar(&opts.saveState, "save-state", "", "when the crawl stops, finished or interrupted, write its queue to this file (see \"zombiecrawl inspect\")")
+	flag.BoolVar(&opts.keepNoArchive, "keep-noarchive", false, "store pages marked noarchive, nosnippet, or none in --har bodies and the --external-cache like any other page")
+	flag.StringVar(&opts.replay, "replay", "", "crawl from the responses stored in this HAR file instead of the network")
+	flag.Var(&opts.notify, "notify", "post a crawl summary to a webhook as \"format=url\", format one of slack, discord, teams, webhook (repeatable)")
+	flag.IntVar(&opts.notifyMinNew, "notify-min-new", 1, "only notify when at least this many links are newly broken since the previous --db run (0 = always)")
+	flag.StringVar(&opts.reportURL, "report-url", "", "link to the HTML report included in notifications")
+	flag.StringVar(&opts.baseline, "baseline", "", "compare against the broken links in this earlier --json output, print the diff, and fail only on new broken links")
+	flag.StringVar(&opts.diffFormat, "diff-format", "text", "--baseline diff format: text, markdown, or json")
+	flag.BoolVar(&opts.dryRun, "dry-run", false, "fetch only the start page and list the URLs a crawl would check, without checking them; with --fix, preview the rewrites as a diff")
+	flag.BoolVar(&opts.fix, "fix", false, "treat the arguments as local Markdown and HTML files or directories and rewrite their links: http to https where it works, permanent redirects to their destination, and with --suggest-archive dead links to a snapshot")
+
+	flag.Parse()
+	return opts
+}
+
+// applyProfile sets the rate and concurrency flags from --profile, leaving
+// any the user gave explicitly on the command line.
+func applyProfile(opts *cliFlags) error {
+	profile, err := crawler.ParseProfile(opts.profile)
+	if err != nil {
+		return fmt.Errorf("--profile: %w", err)
+	}
+	set := make(map[string]bool)
+	flag.Visit(func(f *flag.Flag) { set[f.Name] = true })
+
+	settings := profile.Settings()
+	for name, apply := range map[string]func(){
+		"concurrency":  func() { opts.concurrency = settings.Concurrency },
+		"max-per-host": func() { opts.maxPerHost = settings.MaxPerHost },
+		"delay":        func() { opts.delay = settings.Delay },
+		"burst":        func() { opts.burst = settings.Burst },
+		"min-rate":     func() { opts.minRate = settings.MinRate },
+		"max-rate":     func() { opts.maxRate = settings.MaxRate },
+		"retries":      func() { opts.retries = settings.MaxRetries },
+		"retry-delay":  func() { opts.retryDelay = settings.RetryDelay },
+	} {
+		if !set[name] {
+			apply()
+		}
+	}
+	return nil
+}
+
+// validateFlags validates flag combinations and returns an error if invalid.
+func validateFlags(opts *cliFlags) error {
+	if opts.fix && (opts.urlFile != "" || opts.compareAs != "" || opts.baseline != "" || opts.stream || opts.saveState != "") {
+		return fmt.Errorf("--fix cannot be combined with --url-file, --compare-as, --baseline, --stream, or --save-state")
+	}
+	if opts.outputJSON && opts.outputCSV {
+		return fmt.Errorf("--json and --csv are mutually exclusive")
+	}
+	if opts.dryRun && opts.outputCSV {
+		return fmt.Errorf("--dry-run supports text or --json output only")
+	}
+	if opts.stream {
+		if opts.outputFile == "" {
+			return fmt.Errorf("--stream requires -o")
+		}
+		if opts.jsonEnvelope || opts.template != "" || opts.dryRun || opts.compareAs != "" {
+			return fmt.Errorf("--stream cannot be combined with --json-envelope, --template, --dry-run, or --compare-as")
+		}
+	}
+	if opts.jsonEnvelope && (opts.outputCSV || opts.template != "" || opts.dryRun || opts.compareAs != "") {
+		return fmt.Errorf("--json-envelope cannot be combined with --csv, --template, --dry-run, or --compare-as")
+	}
+	if opts.splitByHost && opts.splitOutput == "" {
+		return fmt.Errorf("--split-by-host requires --split-output")
+	}
+	if opts.splitOutput != "" && (opts.dryRun || opts.compareAs != "") {
+		return fmt.Errorf("--split-output cannot be combined with --dry-run or --compare-as")
+	}
+	if opts.template != "" {
+		if opts.outputJSON || opts.outputCSV || opts.dryRun || opts.compareAs != "" {
+			return fmt.Errorf("--template cannot be combined with --json, --csv, --dry-run, or --compare-as")
+		}
+		if opts.baseline != "" && opts.outputFile == "" {
+			return fmt.Errorf("--baseline prints the diff to stdout; write --template output to a file with -o")
+		}
+		if _, err := result.ParseTemplateFile(opts.template); err != nil {
+			return fmt.Errorf("--template: %w", err)
+		}
+	}
+	if opts.ratePerMinute < 0 {
+		return fmt.Errorf("--rate-limit-per-minute must not be negative")
+	}
+	if opts.minRate <= 0 || opts.maxRate <= 0 {
+		return fmt.Errorf("--min-rate and --max-rate must be positive")
+	}
+	if opts.minRate > opts.maxRate {
+		return fmt.Errorf("--min-rate %g exceeds --max-rate %g", opts.minRate, opts.maxRate)
+	}
+	if opts.burst < 0 {
+		return fmt.Errorf("--burst must not be negative")
+	}
+	if opts.maxPerHost < 0 {
+		return fmt.Errorf("--max-per-host must not be negative")
+	}
+	if opts.timeout <= 0 {
+		return fmt.Errorf("--timeout must be positive")
+	}
+	if opts.dialTimeout < 0 || opts.tlsTimeout < 0 || opts.headerTimeout < 0 {
+		return fmt.Errorf("--dial-timeout, --tls-timeout, and --response-header-timeout must not be negative")
+	}
+	if opts.queueTimeout < 0 || opts.hardTimeout < 0 {
+		return fmt.Errorf("--queue-timeout and --hard-timeout must not be negative")
+	}
+	if opts.streamTime <= 0 {
+		return fmt.Errorf("--stream-read-time must be positive")
+	}
+	if _, err := crawler.ParseSize(opts.streamSize); err != nil {
+		return fmt.Errorf("--stream-max-size: %w", err)
+	}
+	if opts.maxPageSize != "" {
+		if _, err := crawler.ParseSize(opts.maxPageSize); err != nil {
+			return fmt.Errorf("--max-page-size: %w", err)
+		}
+	}
+	if opts.slowRequest <= 0 {
+		return fmt.Errorf("--slow-request must be positive")
+	}
+	if _, err := parseLogLevel(opts.logLevel); err != nil {
+		return err
+	}
+	if opts.dryRun && opts.urlFile != "" {
+		return fmt.Errorf("--dry-run and --url-file are mutually exclusive")
+	}
+	if opts.saveState != "" && (opts.dryRun || opts.urlFile != "" || opts.compareAs != "") {
+		return fmt.Errorf("--save-state cannot be combined with --dry-run, --url-file, or --compare-as")
+	}
+	if _, err := crawler.ParseStrategy(opts.strategy); err != nil {
+		return err
+	}
+	if _, err := crawler.ParseIPVersion(opts.ipVersion); err != nil {
+		return fmt.Errorf("--ip-version: %w", err)
+	}
+	if _, err := crawler.ParseDNSServer(opts.dnsServer); err != nil {
+		return fmt.Errorf("--dns: %w", err)
+	}
+	if opts.maxRedirects < 1 {
+		return fmt.Errorf("--max-redirects must be at least 1 (use --follow-redirects=never to stop at the first redirect)")
+	}
+	if opts.maxLinksPerPage < 0 {
+		return fmt.Errorf("--max-links-per-page must not be negative")
+	}
+	if opts.maxOutbound < 1 {
+		return fmt.Errorf("--max-outbound-links must be at least 1")
+	}
+	if _, err := crawler.ParseRedirectPolicy(opts.followRedirects); err != nil {
+		return fmt.Errorf("--follow-redirects: %w", err)
+	}
+	if _, err := result.ParseSortKey(opts.sort); err != nil {
+		return fmt.Errorf("--sort: %w", err)
+	}
+	if opts.progressJSON && opts.browse {
+		return fmt.Errorf("--progress-json cannot be combined with --browse")
+	}
+	if opts.summaryOnly && (opts.browse || opts.urlFile != "" || opts.dryRun || opts.compareAs != "") {
+		return fmt.Errorf("--summary-only cannot be combined with --browse, --url-file, --dry-run, or --compare-as")
+	}
+	if opts.recentBroken < 0 {
+		return fmt.Errorf("--recent-broken must not be negative")
+	}
+	if opts.theme != "auto" {
+		if _, err := tui.LookupTheme(opts.theme); err != nil {
+			return fmt.Errorf("--theme: %w", err)
+		}
+	}
+	if opts.as != "" {
+		if _, err := crawler.LookupPreset(opts.as); err != nil {
+			return fmt.Errorf("--as: %w", err)
+		}
+	}
+	if opts.compareAs != "" {
+		if opts.as == "" {
+			return fmt.Errorf("--compare-as requires --as")
+		}
+		if _, err := crawler.LookupPreset(opts.compareAs); err != nil {
+			return fmt.Errorf("--compare-as: %w", err)
+		}
+		if opts.dryRun || opts.urlFile != "" || opts.outputCSV {
+			return fmt.Errorf("--compare-as cannot be combined with --dry-run, --url-file, or --csv")
+		}
+	}
+	if opts.baseline != "" {
+		if opts.dryRun || opts.urlFile != "" || opts.compareAs != "" {
+			return fmt.Errorf("--baseline cannot be combined with --dry-run, --url-file, or --compare-as")
+		}
+		if (opts.outputJSON || opts.outputCSV) && opts.outputFile == "" {
+			return fmt.Errorf("--baseline prints the diff to stdout; write --json or --csv output to a file with -o")
+		}
+	}
+	if _, err := result.ParseDiffFormat(opts.diffFormat); err != nil {
+		return fmt.Errorf("--diff-format: %w", err)
+	}
+	if _, err := parseHostUserAgents(opts.hostUserAgents); err != nil {
+		return err
+	}
+	if err := validateBlockHosts(opts.blockHosts); err != nil {
+		return err
+	}
+	for _, command := range opts.plugins {
+		if len(strings.Fields(command)) == 0 {
+			return fmt.Errorf("--plugin: expected a command")
+		}
+	}
+	if _, err := parseTimeoutOverrides(opts.timeoutOverride); err != nil {
+		return err
+	}
+	if _, err := parseDedupRules(opts.dedupIgnore); err != nil {
+		return err
+	}
+	if _, err := parseStatusExpectations(opts.expectStatus); err != nil {
+		return err
+	}
+	if _, err := loadHostConfigs(opts); err != nil {
+		return err
+	}
+	if _, err := loadSyntheticChecks(opts); err != nil {
+		return err
+	}
+	if _, err := loadContentChecks(opts); err != nil {
+		return err
+	}
+	if _, err := loadRewriteRules(opts); err != nil {
+		return err
+	}
+	if _, err := loadRobotsFile(opts); err != nil {
+		return err
+	}
+	if _, err := parseHostOverrides(opts.resolve); err != nil {
+		return err
+	}
+	if opts.maxBandwidth != "" {
+		if _, err := crawler.ParseBandwidth(opts.maxBandwidth); err != nil {
+			return fmt.Errorf("--max-bandwidth: %w", err)
+		}
+	}
+	if opts.retryBudget < 0 {
+		return fmt.Errorf("--host-retry-budget must not be negative")
+	}
+	if opts.notifyMinNew < 0 {
+		return fmt.Errorf("--notify-min-new must not be negative")
+	}
+	if _, err := parseNotifiers(opts.notify, opts.notifyMinNew); err != nil {
+		return err
+	}
+	return nil
+}
+
+// parseHostUserAgents converts "pattern=agent" flag values into overrides.
+func parseHostUserAgents(values []string) ([]crawler.HostUserAgent, error) {
+	overrides := make([]crawler.HostUserAgent, 0, len(values))
+	for _, value := range values {
+		pattern, agent, ok := strings.Cut(value, "=")
+		if !ok || pattern == "" || agent == "" {
+			return nil, fmt.Errorf("--host-user-agent %q: expected pattern=agent", value)
+		}
+		overrides = append(overrides, crawler.HostUserAgent{Pattern: pattern, UserAgent: agent})
+	}
+	return overrides, nil
+}
+
+// validateBlockHosts rejects --block-host values that are not valid host
+// patterns.
+func validateBlockHosts(values []string) error {
+	for _, value := range values {
+		if _, err := path.Match(value, ""); value == "" || err != nil {
+			return fmt.Errorf("--block-host %q: expected a host pattern such as *.example.com", value)
+		}
+	}
+	return nil
+}
+
+// parseTimeoutOverrides converts "pattern=timeout" flag values into
+// per-pattern request timeouts.
+func parseTimeoutOverrides(values []string) ([]crawler.TimeoutOverride, error) {
+	overrides := make([]crawler.TimeoutOverride, 0, len(values))
+	for _, value := range values {
+		override, err := crawler.ParseTimeoutOverride(value)
+		if err != nil {
+			return nil, fmt.Errorf("--timeout-override %w", err)
+		}
+		overrides = append(overrides, override)
+	}
+	return overrides, nil
+}
+
+// parseDedupRules converts "pattern=param[,param]" flag values into dedup
+// rules.
+func parseDedupRules(values []string) ([]crawler.DedupRule, error) {
+	rules := make([]crawler.DedupRule, 0, len(values))
+	for _, value := range values {
+		rule, err := crawler.ParseDedupRule(value)
+		if err != nil {
+			return nil, fmt.Errorf("--dedup-ignore %w", err)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// parseStatusExpectations converts "pattern=status[,status]" flag values into
+// status assertions.
+func parseStatusExpectations(values []string) ([]crawler.StatusExpectation, error) {
+	expectations := make([]crawler.StatusExpectation, 0, len(values))
+	for _, value := range values {
+		expectation, err := crawler.ParseStatusExpectation(value)
+		if err != nil {
+			return nil, fmt.Errorf("--expect-status %w", err)
+		}
+		expectations = append(expectations, expectation)
+	}
+	return expectations, nil
+}
+
+// loadHostConfigs reads the --host-config file, if set. Sections that
+// override retries start from the --retries and --retry-delay policy.
+func loadHostConfigs(opts *cliFlags) ([]crawler.HostConfig, error) {
+	if opts.hostConfig == "" {
+		return nil, nil
+	}
+	file, err := os.Open(opts.hostConfig)
+	if err != nil {
+		return nil, fmt.Errorf("--host-config: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+	configs, err := crawler.LoadHostConfigs(file, retryPolicy(opts))
+	if err != nil {
+		return nil, fmt.Errorf("--host-config %s: %w", opts.hostConfig, err)
+	}
+	return configs, nil
+}
+
+// loadSyntheticChecks reads the --checks file, if set.
+func loadSyntheticChecks(opts *cliFlags) ([]crawler.SyntheticCheck, error) {
+	if opts.checks == "" {
+		return nil, nil
+	}
+	file, err := os.Open(opts.checks)
+	if err != nil {
+		return nil, fmt.Errorf("--checks: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+	checks, err := crawler.LoadSyntheticChecks(file)
+	if err != nil {
+		return nil, fmt.Errorf("--checks %s: %w", opts.checks, err)
+	}
+	return checks, nil
+}
+
+// loadContentChecks reads the --content-checks file, if set.
+func loadContentChecks(opts *cliFlags) ([]crawler.ContentCheck, error) {
+	if opts.contentChecks == "" {
+		return nil, nil
+	}
+	file, err := os.Open(opts.contentChecks)
+	if err != nil {
+		return nil, fmt.Errorf("--content-checks: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+	checks, err := crawler.LoadContentChecks(file)
+	if err != nil {
+		return nil, fmt.Errorf("--content-checks %s: %w", opts.contentChecks, err)
+	}
+	return checks, nil
+}
+
+// loadRewriteRules reads the --rewrite-rules file, if set.
+func loadRewriteRules(opts *cliFlags) ([]crawler.RewriteRule, error) {
+	if opts.rewriteRules == "" {
+		return nil, nil
+	}
+	file, err := os.Open(opts.rewriteRules)
+	if err != nil {
+		return nil, fmt.Errorf("--rewrite-rules: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+	rules, err := crawler.LoadRewriteRules(file)
+	if err != nil {
+		return nil, fmt.Errorf("--rewrite-rules %s: %w", opts.rewriteRules, err)
+	}
+	return rules, nil
+}
+
+// loadRobotsFile reads the --robots-file file, if set. crawler.New parses it.
+func loadRobotsFile(opts *cliFlags) ([]byte, error) {
+	if opts.robotsFile == "" {
+		return nil, nil
+	}
+	body, err := os.ReadFile(opts.robotsFile)
+	if err != nil {
+		return nil, fmt.Errorf("--robots-file: %w", err)
+	}
+	return body, nil
+}
+
+// retryPolicy returns the retry policy set by --retries and --retry-delay.
+func retryPolicy(opts *cliFlags) crawler.RetryPolicy {
+	return crawler.RetryPolicy{
+		MaxRetries: opts.retries,
+		BaseDelay:  opts.retryDelay,
+		MaxDelay:   30 * time.Second,
+	}
+}
+
+// parseHostOverrides converts "host:port:address" flag values into
+// overrides. IPv6 addresses may be bracketed.
+func parseHostOverrides(values []string) ([]crawler.HostOverride, error) {
+	overrides := make([]crawler.HostOverride, 0, len(values))
+	for _, value := range values {
+		host, rest, _ := strings.Cut(value, ":")
+		port, address, _ := strings.Cut(rest, ":")
+		address = strings.Trim(address, "[]")
+		if host == "" || port == "" || address == "" {
+			return nil, fmt.Errorf("--resolve %q: expected host:port:address", value)
+		}
+		if n, err := strconv.Atoi(port); err != nil || n < 1 || n > 65535 {
+			return nil, fmt.Errorf("--resolve %q: invalid port %q", value, port)
+		}
+		if net.ParseIP(address) == nil {
+			return nil, fmt.Errorf("--resolve %q: %q is not an IP address", value, address)
+		}
+		overrides = append(overrides, crawler.HostOverride{Host: host, Port: port, Address: address})
+	}
+	return overrides, nil
+}
+
+// parseNotifiers parses --notify values of the form "format=url".
+func parseNotifiers(values []string, minNew int) ([]notify.Notifier, error) {
+	notifiers := make([]notify.Notifier, 0, len(values))
+	for _, value := range values {
+		name, webhook, ok := strings.Cut(value, "=")
+		if !ok || name == "" || webhook == "" {
+			return nil, fmt.Errorf("--notify %q: expected format=url", value)
+		}
+		format, err := notify.ParseFormat(name)
+		if err != nil {
+			return nil, fmt.Errorf("--notify: %w", err)
+		}
+		notifiers = append(notifiers, notify.Notifier{Format: format, URL: webhook, MinNewBroken: minNew})
+	}
+	return notifiers, nil
+}
+
+// buildCrawlerConfig creates a crawler.Config from flags and the target URL.
+func buildCrawlerConfig(opts *cliFlags, rawURL string) crawler.Config {
+	// Already validated by validateFlags
+	hostUserAgents, _ := parseHostUserAgents(opts.hostUserAgents)
+	expectations, _ := parseStatusExpectations(opts.expectStatus)
+	timeoutOverrides, _ := parseTimeoutOverrides(opts.timeoutOverride)
+	dedupRules, _ := parseDedupRules(opts.dedupIgnore)
+	hostOverrides, _ := parseHostOverrides(opts.resolve)
+	hostConfigs, _ := loadHostConfigs(opts)
+	syntheticChecks, _ := loadSyntheticChecks(opts)
+	contentChecks, _ := loadContentChecks(opts)
+	rewrites, _ := loadRewriteRules(opts)
+	robotsTxt, _ := loadRobotsFile(opts)
+	streamSize, _ := crawler.ParseSize(opts.streamSize)
+	var maxPageSize int64
+	if opts.maxPageSize != "" {
+		maxPageSize, _ = crawler.ParseSize(opts.maxPageSize)
+	}
+
+	cfg := crawler.Config{
+		StartURL:              rawURL,
+		Concurrency:           opts.concurrency,
+		MaxPerHost:            opts.maxPerHost,
+		RequestTimeout:        opts.timeout,
+		TimeoutOverrides:      timeoutOverrides,
+		DialTimeout:           opts.dialTimeout,
+		TLSHandshakeTimeout:   opts.tlsTimeout,
+		ResponseHeaderTimeout: opts.headerTimeout,
+		MaxStreamBytes:        streamSize,
+		MaxPageBytes:          maxPageSize,
+		MaxStreamTime:         opts.streamTime,
+		QueueTimeout:          opts.queueTimeout,
+		SlowRequest:           opts.slowRequest,
+		HardTimeout:           opts.hardTimeout,
+		IPVersion:             crawler.IPVersion(opts.ipVersion),
+		DNSServer:             opts.dnsServer,
+		HostOverrides:         hostOverrides,
+		Bandwidth:             newBandwidth(opts),
+		RetryBudget:           newRetryBudget(opts),
+		Delay:                 opts.delay,
+		RatePerMinute:         opts.ratePerMinute,
+		Burst:                 opts.burst,
+		MinRate:               opts.minRate,
+		MaxRate:               opts.maxRate,
+		DisableAutoTune:       opts.disableAutoTune,
+		VerboseNetwork:        opts.verboseNetwork,
+		UserAgent:             opts.userAgent,
+		UserAgents:            opts.userAgents,
+		HostUserAgents:        hostUserAgents,
+		BlockedHosts:          opts.blockHosts,
+		Rewrites:              rewrites,
+		Scrub:                 result.NewScrubber(opts.scrubParams),
+		HostConfigs:           hostConfigs,
+		SyntheticChecks:       syntheticChecks,
+		ExpectedStatuses:      expectations,
+		ContentChecks:         contentChecks,
+		Accept:                opts.accept,
+		AcceptLanguage:        opts.acceptLanguage,
+		SendReferer:           opts.sendReferer,
+		LinkHygiene:           opts.linkHygiene,
+		StrictURLs:            opts.strictURLs,
+		Deterministic:         opts.deterministic,
+		RunID:                 opts.runID,
+		Accessibility:         opts.accessibility,
+		SEO:                   opts.seo,
+		SiteStructure:         opts.siteStructure,
+		MaxOutboundLinks:      opts.maxOutbound,
+		CheckHTTPS:            opts.checkHTTPS,
+		CheckTrailingSlash:    opts.checkSlash,
+		FoldSchemes:           opts.foldSchemes,
+		DedupIgnoreQuery:      opts.dedupQuery,
+		DedupRules:            dedupRules,
+		StripSessionIDs:       opts.stripSessions,
+		CheckFragments:        opts.checkFragments,
+		CheckFeeds:            opts.checkFeeds,
+		CheckPDFs:             opts.checkPDFs,
+		MaxDepth:              opts.depth,
+		MaxLinksPerPage:       opts.maxLinksPerPage,
+		Strategy:              crawler.Strategy(opts.strategy),
+		MaxRedirects:          opts.maxRedirects,
+		FollowRedirects:       crawler.RedirectPolicy(opts.followRedirects),
+		Sitemap:               opts.sitemap,
+		RobotsCacheSize:       opts.robotsCacheSize,
+		RespectExternalRobots: opts.externalRobots,
+		StrictRobots:          opts.strictRobots,
+		RobotsTxt:             robotsTxt,
+		Verify: crawler.VerifyPolicy{
+			Enabled:     opts.verify,
+			Delay:       opts.verifyDelay,
+			Concurrency: opts.verifyWorkers,
+		},
+		RetryPolicy: retryPolicy(opts),
+	}
+	if opts.progressJSON {
+		cfg.OnProgress = progressJSON(os.Stderr, opts.runID, cfg.Scrub.String(rawURL))
+	}
+	if opts.as != "" {
+		preset, _ := crawler.LookupPreset(opts.as)
+		preset.Apply(&cfg)
+	}
+	return cfg
+}
+
+// progressRecord is a --progress-json line.
+type progressRecord struct {
+	RunID    string  `json:"run_id"`
+	Site     string  `json:"site"`
+	Checked  int     `json:"checked"`
+	Broken   int     `json:"broken"`
+	Queued   int     `json:"queued"`
+	InFlight int     `json:"in_flight"`
+	Rate     float64 `json:"rate"`    // Checks per second
+	Elapsed  float64 `json:"elapsed"` // Seconds
+	Done     bool    `json:"done"`
+}
+
+// progressJSON returns a Config.OnProgress writing each report on the
+// crawl of site, in run runID, to w as one line of JSON. Each line is a
+// single write, so the crawls of several sites can share w.
+func progressJSON(w io.Writer, runID, site string) func(crawler.Progress) {
+	return func(p crawler.Progress) {
+		line, err := json.Marshal(progressRecord{
+			RunID:    runID,
+			Site:     site,
+			Checked:  p.Checked,
+			Broken:   p.Broken,
+			Queued:   p.Queued,
+			InFlight: p.InFlight,
+			Rate:     math.Round(p.Rate*100) / 100,
+			Elapsed:  math.Round(p.Elapsed.Seconds()*1000) / 1000,
+			Done:     p.Done,
+		})
+		if err != nil {
+			return
+		}
+		_, _ = w.Write(append(line, '\n'))
+	}
+}
+
+// newBandwidth returns the --max-bandwidth limit, or nil if it is unset.
+func newBandwidth(opts *cliFlags) *crawler.Bandwidth {
+	if opts.maxBandwidth == "" {
+		return nil
+	}
+	// Already validated by validateFlags
+	bytesPerSecond, _ := crawler.ParseBandwidth(opts.maxBandwidth)
+	return crawler.NewBandwidth(bytesPerSecond)
+}
+
+// newRetryBudget returns the --host-retry-budget limit, or nil if it is unset.
+func newRetryBudget(opts *cliFlags) *crawler.RetryBudget {
+	if opts.retryBudget == 0 {
+		return nil
+	}
+	return crawler.NewRetryBudget(opts.retryBudget)
+}
+
+// defaultExternalCacheFile returns the external cache location under the
+// user's cache directory, or a file in the working directory if there is none.
+func defaultExternalCacheFile() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ".zombiecrawl-external-cache.json"
+	}
+	return filepath.Join(dir, "zombiecrawl", "external-cache.json")
+}
+
+// openExternalCache loads the external cache, or returns nil if --external-cache is off.
+func openExternalCache(opts *cliFlags) (*crawler.ExternalCache, error) {
+	if opts.cacheTTL <= 0 {
+		return nil, nil
+	}
+	cache, err := crawler.LoadExternalCache(opts.cacheFile, opts.cacheTTL)
+	if err != nil {
+		return nil, fmt.Errorf("load external cache: %w", err)
+	}
+	cache.SetKeepNoArchive(opts.keepNoArchive)
+	return cache, nil
+}
+
+// newHARRecorder returns a recorder for --har, or nil if it is not set.
+func newHARRecorder(opts *cliFlags) *crawler.HARRecorder {
+	if opts.har == "" {
+		return nil
+	}
+	har := crawler.NewHARRecorder()
+	har.SetKeepNoArchive(opts.keepNoArchive)
+	har.SetScrubber(result.NewScrubber(opts.scrubParams))
+	return har
+}
+
+// newStateRecorder returns a recorder for --save-state, or nil if it is not
+// set.
+func newStateRecorder(opts *cliFlags) *crawler.StateRecorder {
+	if opts.saveState == "" {
+		return nil
+	}
+	return crawler.NewStateRecorder()
+}
+
+// saveState writes the crawl queue to the --save-state file, if set.
+func saveState(opts *cliFlags, state *crawler.StateRecorder) error {
+	if state == nil {
+		return nil
+	}
+	return state.WriteFile(opts.saveState)
+}
+
+// newArchiveLookup returns the shared Wayback Machine lookup for
+// --suggest-archive, or nil if it is not set.
+func newArchiveLookup(opts *cliFlags) *crawler.ArchiveLookup {
+	if !opts.suggestArchive {
+		return nil
+	}
+	return crawler.NewArchiveLookup(&http.Client{Timeout: 10 * time.Second}, "", crawler.DefaultArchiveRate)
+}
+
+// saveHAR writes the recorded requests to the --har file, if set.
+func saveHAR(opts *cliFlags, har *crawler.HARRecorder) error {
+	if har == nil {
+		return nil
+	}
+	return har.WriteFile(opts.har)
+}
+
+// openReplay loads the --replay archive, or returns nil if it is not set.
+func openReplay(opts *cliFlags) (*crawler.ReplayTransport, error) {
+	if opts.replay == "" {
+		return nil, nil
+	}
+	return crawler.LoadReplay(opts.replay)
+}
+
+// applyReplay points cfg at the replay archive. Retries are disabled since
+// they would only receive the same stored response.
+func applyReplay(cfg *crawler.Config, replay *crawler.ReplayTransport) {
+	if replay == nil {
+		return
+	}
+	cfg.Transport = replay
+	cfg.RetryPolicy.MaxRetries = 0
+	hostConfigs := make([]crawler.HostConfig, len(cfg.HostConfigs))
+	for i, hc := range cfg.HostConfigs {
+		hc.RetryPolicy = nil
+		hostConfigs[i] = hc
+	}
+	cfg.HostConfigs = hostConfigs
+}
+
+// validateStartURL checks that rawURL is an absolute http or https URL.
+func validateStartURL(rawURL string) error {
+	parsedURL, err := url.Parse(rawURL)
+	if err != nil || (parsedURL.Scheme != "http" && parsedURL.Scheme != "https") || parsedURL.Host == "" {
+		return fmt.Errorf("invalid URL: %s\nURL must be an http or https URL, like https://example.com or localhost:3000", rawURL)
+	}
+	return nil
+}
+
+// completeStartURL adds a default scheme to a start URL typed without one,
+// such as "localhost:3000" or "//example.com", saying so on stderr, and
+// validates the result.
+func completeStartURL(rawURL string) (string, error) {
+	completed := urlutil.WithDefaultScheme(rawURL)
+	if err := validateStartURL(completed); err != nil {
+		return "", err
+	}
+	if completed != rawURL {
+		fmt.Fprintf(os.Stderr, "No scheme in %s; crawling %s\n", rawURL, completed)
+	}
+	return completed, nil
+}
+
+// readURLFile returns the start URLs listed in path, one per line.
+// Blank lines and lines starting with # are ignored.
+func readURLFile(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open url file: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	var urls []string
+	scanner := bufio.NewScanner(file)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		startURL, err := completeStartURL(line)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, lineNum, err)
+		}
+		urls = append(urls, startURL)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read url file: %w", err)
+	}
+	if len(urls) == 0 {
+		return nil, fmt.Errorf("url file %s lists no URLs", path)
+	}
+	return urls, nil
+}
+
+// runSites crawls every URL concurrently without the TUI, sharing one pool of
+// --concurrency request slots, and writes one section per site. It reports
+// whether any site had broken links or failed to crawl.
+func runSites(ctx context.Context, opts *cliFlags, urls []string, cache *crawler.ExternalCache, replay *crawler.ReplayTransport) (bool, error) {
+	har := newHARRecorder(opts)
+	archive := newArchiveLookup(opts)
+	stream, closeStream, err := openStream(opts)
+	if err != nil {
+		return true, err
+	}
+	defer closeStream()
+	logger, closeLog, err := openLog(opts)
+	if err != nil {
+		return true, err
+	}
+	defer closeLog()
+	plugins, stopPlugins, err := startPlugins(opts)
+	if err != nil {
+		return true, err
+	}
+	defer stopPlugins()
+	// One bandwidth limit for all sites together
+	bandwidth := newBandwidth(opts)
+	cfgs := make([]crawler.Config, len(urls))
+	for i, rawURL := range urls {
+		cfgs[i] = buildCrawlerConfig(opts, rawURL)
+		cfgs[i].Bandwidth = bandwidth
+		cfgs[i].Results = stream
+		cfgs[i].ExternalCache = cache
+		cfgs[i].HAR = har
+		cfgs[i].Archive = archive
+		cfgs[i].Logger = logger
+		cfgs[i].Plugins = plugins
+		applyReplay(&cfgs[i], replay)
+	}
+	startedAt := time.Now()
+	sites := crawler.RunSites(ctx, cfgs, crawler.NewPool(opts.concurrency), nil)
+	for _, site := range sites {
+		if site.Result != nil {
+			result.SortLinks(site.Result.BrokenLinks, sortKey(opts))
+		}
+	}
+	if err := cache.Save(); err != nil {
+		return true, fmt.Errorf("save external cache: %w", err)
+	}
+	if err := saveHAR(opts, har); err != nil {
+		return true, err
+	}
+	for _, site := range sites {
+		if site.Result == nil {
+			continue
+		}
+		previous, err := previousRun(opts, site.Site)
+		if err != nil {
+			return true, err
+		}
+		if err := recordRun(opts, site.Site, startedAt, site.Result); err != nil {
+			return true, err
+		}
+		notifyRun(ctx, opts, previous, site.Site, startedAt, site.Result)
+	}
+
+	failed := false
+	var brokenLinks []result.LinkResult
+	for _, site := range sites {
+		if site.Error != "" {
+			failed = true
+			continue
+		}
+		if len(site.Result.BrokenLinks) > 0 {
+			failed = true
+			brokenLinks = append(brokenLinks, site.Result.BrokenLinks...)
+		}
+	}
+
+	// Each site is sorted; sort their links together too
+	result.SortLinks(brokenLinks, sortKey(opts))
+	if err := writeSplitOutput(opts, brokenLinks); err != nil {
+		return failed, err
+	}
+	if opts.stream {
+		// The -o file was written during the crawl
+		return failed, nil
+	}
+
+	var writer io.Writer = os.Stdout
+	if opts.outputFile != "" {
+		outFile, err := os.Create(opts.outputFile)
+		if err != nil {
+			return failed, fmt.Errorf("create output file: %w", err)
+		}
+		defer func() {
+			if cerr := outFile.Close(); cerr != nil {
+				fmt.Fprintf(os.Stderr, "Error closing output file: %v\n", cerr)
+			}
+		}()
+		writer = outFile
+	}
+
+	switch {
+	case opts.template != "":
+		if err := writeTemplate(writer, opts, sites); err != nil {
+			return failed, err
+		}
+	case opts.outputCSV:
+		// CSV has no room for sections; source_page identifies each site
+		if err := result.WriteCSV(writer, brokenLinks); err != nil {
+			return failed, fmt.Errorf("write csv: %w", err)
+		}
+	case opts.jsonEnvelope:
+		finishedAt := time.Now()
+		if opts.deterministic {
+			startedAt, finishedAt = crawler.DeterministicEpoch, crawler.DeterministicEpoch
+		}
+		env := result.Envelope{
+			Version:     toolVersion(),
+			RunID:       opts.runID,
+			StartedAt:   startedAt,
+			FinishedAt:  finishedAt,
+			Config:      cfgs[0].Snapshot(),
+			BrokenLinks: brokenLinks,
+			Sites:       sites,
+		}
+		if err := result.WriteEnvelope(writer, env); err != nil {
+			return failed, err
+		}
+	case opts.outputJSON || opts.outputFile != "":
+		enc := json.NewEncoder(writer)
+		enc.SetEscapeHTML(false)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(sites); err != nil {
+			return failed, fmt.Errorf("write json: %w", err)
+		}
+	default:
+		result.PrintSites(writer, sites)
+	}
+	return failed, nil
+}
+
+// recordRun appends a completed crawl to the --db run database, if set.
+func recordRun(opts *cliFlags, site string, startedAt time.Time, res *result.Result) error {
+	if opts.db == "" || res == nil {
+		return nil
+	}
+	if err := history.Append(opts.db, history.NewRun(site, startedAt, res)); err != nil {
+		return fmt.Errorf("record run: %w", err)
+	}
+	return nil
+}
+
+// previousRun returns the site's latest run in the --db run database, so
+// notifications can report what changed. It returns nil when there is no
+// database, no notifier, or no earlier run of the site.
+func previousRun(opts *cliFlags, site string) (*history.Run, error) {
+	if opts.db == "" || len(opts.notify) == 0 {
+		return nil, nil
+	}
+	runs, err := history.Load(opts.db)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("load previous run: %w", err)
+	}
+	run, ok := history.Latest(runs, site)
+	if !ok {
+		return nil, nil
+	}
+	return &run, nil
+}
+
+// notifyRun posts the crawl summary to every --notify webhook. Failures are
+// reported on stderr without failing the crawl.
+func notifyRun(ctx context.Context, opts *cliFlags, previous *history.Run, site string, startedAt time.Time, res *result.Result) {
+	if len(opts.notify) == 0 || res == nil {
+		return
+	}
+	// Already validated by validateFlags
+	notifiers, _ := parseNotifiers(opts.notify, opts.notifyMinNew)
+	summary := notify.NewSummary(history.NewRun(site, startedAt, res), previous, opts.reportURL)
+	for _, notifier := range notifiers {
+		if _, err := notifier.Send(ctx, summary); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		}
+	}
+}
+
+// runReport implements "zombiecrawl report": it loads the run database and
+// writes a trend report in the requested format.
+func runReport(args []string) error {
+	reportFlags := flag.NewFlagSet("report", flag.ContinueOnError)
+	db := reportFlags.String("db", "", "run database written by crawls with --db (required)")
+	format := reportFlags.String("format", "text", "report format: text, csv, or html")
+	outputFile := reportFlags.String("o", "", "write the report to file instead of stdout")
+	if err := reportFlags.Parse(args); err != nil {
+		return err
+	}
+	if *db == "" {
+		return fmt.Errorf("report: --db is required")
+	}
+
+	var write func(io.Writer, []history.SiteTrend) error
+	switch *format {
+	case "text":
+		write = history.WriteText
+	case "csv":
+		write = history.WriteCSV
+	case "html":
+		write = history.WriteHTML
+	default:
+		return fmt.Errorf("report: unknown format %q (want text, csv, or html)", *format)
+	}
+
+	runs, err := history.Load(*db)
+	if err != nil {
+		return fmt.Errorf("report: %w", err)
+	}
+
+	var writer io.Writer = os.Stdout
+	if *outputFile != "" {
+		outFile, err := os.Create(*outputFile)
+		if err != nil {
+			return fmt.Errorf("create output file: %w", err)
+		}
+		defer func() {
+			if cerr := outFile.Close(); cerr != nil {
+				fmt.Fprintf(os.Stderr, "Error closing output file: %v\n", cerr)
+			}
+		}()
+		writer = outFile
+	}
+
+	return write(writer, history.Trends(runs))
+}
+
+// runStats implements "zombiecrawl stats": it summarizes every run in the
+// run database, across sites. Nothing is sent over the network.
+func runStats(args []string) error {
+	statsFlags := flag.NewFlagSet("stats", flag.ContinueOnError)
+	db := statsFlags.String("db", "", "run database written by crawls with --db (required)")
+	format := statsFlags.String("format", "text", "summary format: text or json")
+	top := statsFlags.Int("top", 10, "most frequently broken hosts listed (0 = all)")
+	outputFile := statsFlags.String("o", "", "write the summary to file instead of stdout")
+	if err := statsFlags.Parse(args); err != nil {
+		return err
+	}
+	if *db == "" {
+		return fmt.Errorf("stats: --db is required")
+	}
+	if *top < 0 {
+		return fmt.Errorf("stats: --top must not be negative")
+	}
+	if *format != "text" && *format != "json" {
+		return fmt.Errorf("stats: unknown format %q (want text or json)", *format)
+	}
+
+	runs, err := history.Load(*db)
+	if err != nil {
+		return fmt.Errorf("stats: %w", err)
+	}
+	usage := history.Summarize(runs, *top)
+
+	var writer io.Writer = os.Stdout
+	if *outputFile != "" {
+		outFile, err := os.Create(*outputFile)
+		if err != nil {
+			return fmt.Errorf("create output file: %w", err)
+		}
+		defer func() {
+			if cerr := outFile.Close(); cerr != nil {
+				fmt.Fprintf(os.Stderr, "Error closing output file: %v\n", cerr)
+			}
+		}()
+		writer = outFile
+	}
+
+	if *format == "json" {
+		enc := json.NewEncoder(writer)
+		enc.SetEscapeHTML(false)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(usage); err != nil {
+			return fmt.Errorf("write json: %w", err)
+		}
+		return nil
+	}
+	return history.WriteUsage(writer, usage)
+}
+
+// readResultsFile reads the broken links from a JSON results file.
+func readResultsFile(path string) ([]result.LinkResult, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open results: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+	links, err := result.ReadJSON(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return links, nil
+}
+
+// runDiff implements "zombiecrawl diff": it compares the broken links in two
+// JSON result files and writes what is new, fixed, and still broken.
+func runDiff(args []string) error {
+	diffFlags := flag.NewFlagSet("diff", flag.ContinueOnError)
+	format := diffFlags.String("format", "text", "diff format: text, markdown, or json")
+	outputFile := diffFlags.String("o", "", "write the diff to file instead of stdout")
+	if err := diffFlags.Parse(args); err != nil {
+		return err
+	}
+	if diffFlags.NArg() != 2 {
+		return fmt.Errorf("diff: expected two result files (before and after)")
+	}
+	diffFormat, err := result.ParseDiffFormat(*format)
+	if err != nil {
+		return fmt.Errorf("diff: %w", err)
+	}
+
+	before, err := readResultsFile(diffFlags.Arg(0))
+	if err != nil {
+		return fmt.Errorf("diff: %w", err)
+	}
+	after, err := readResultsFile(diffFlags.Arg(1))
+	if err != nil {
+		return fmt.Errorf("diff: %w", err)
+	}
+
+	var writer io.Writer = os.Stdout
+	if *outputFile != "" {
+		outFile, err := os.Create(*outputFile)
+		if err != nil {
+			return fmt.Errorf("create output file: %w", err)
+		}
+		defer func() {
+			if cerr := outFile.Close(); cerr != nil {
+				fmt.Fprintf(os.Stderr, "Error closing output file: %v\n", cerr)
+			}
+		}()
+		writer = outFile
+	}
+	return result.WriteDiff(writer, before, after, diffFormat)
+}
+
+// runInspect implements "zombiecrawl inspect": it summarizes a crawl queue
+// saved with --save-state.
+func runInspect(args []string) error {
+	inspectFlags := flag.NewFlagSet("inspect", flag.ContinueOnError)
+	next := inspectFlags.Int("n", 20, "number of queued URLs to list")
+	if err := inspectFlags.Parse(args); err != nil {
+		return err
+	}
+	if inspectFlags.NArg() != 1 {
+		return fmt.Errorf("inspect: expected one state file")
+	}
+	if *next < 0 {
+		return fmt.Errorf("inspect: -n must not be negative")
+	}
+
+	file, err := os.Open(inspectFlags.Arg(0))
+	if err != nil {
+		return fmt.Errorf("inspect: open state: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+	state, err := result.ReadState(file)
+	if err != nil {
+		return fmt.Errorf("inspect: %s: %w", inspectFlags.Arg(0), err)
+	}
+	result.PrintState(os.Stdout, state, *next)
+	return nil
 }
 
-// parseFlags parses command-line flags and returns the parsed values.
-func parseFlags() *cliFlags {
-	opts := &cliFlags{}
-	flag.IntVar(&opts.concurrency, "concurrency", 10, "number of concurrent workers")
-	flag.IntVar(&opts.delay, "delay", 100, "delay between requests in milliseconds")
-	flag.BoolVar(&opts.disableAutoTune, "disable-auto-tune", false, "disable adaptive rate limiting (use fixed rate from --delay)")
-	flag.BoolVar(&opts.verboseNetwork, "verbose-network", false, "enable verbose network error diagnostics (DNS, timeout, connection details)")
-	flag.IntVar(&opts.retries, "retries", 2, "number of retries for transient errors")
-	flag.DurationVar(&opts.retryDelay, "retry-delay", time.Second, "base delay between retries")
-	flag.StringVar(&opts.userAgent, "user-agent", "zombiecrawl/1.0 (+https://github.com/lukemcguire/zombiecrawl)", "user agent string")
+// runBench implements the hidden "bench" subcommand: crawl a synthetic
+// in-process site and report throughput.
+func runBench(args []string) error {
+	benchFlags := flag.NewFlagSet("bench", flag.ContinueOnError)
+	var opts bench.Options
+	benchFlags.IntVar(&opts.Site.Pages, "pages", 1000, "pages on the synthetic site")
+	benchFlags.IntVar(&opts.Site.Fanout, "fanout", 10, "links on each page")
+	benchFlags.DurationVar(&opts.Site.Latency, "latency", 0, "delay before every response")
+	benchFlags.Float64Var(&opts.Site.ErrorRate, "error-rate", 0.01, "fraction of links that are broken (0 to 1)")
+	benchFlags.Uint64Var(&opts.Site.Seed, "seed", 1, "seed for generating the site")
+	benchFlags.IntVar(&opts.Concurrency, "concurrency", 10, "number of concurrent workers")
+	benchFlags.IntVar(&opts.Runs, "runs", 3, "number of timed crawls")
+	benchFlags.Float64Var(&opts.Rate, "rate", bench.DefaultRate, "requests per second allowed")
+	strategy := benchFlags.String("strategy", "bfs", "crawl order: bfs, dfs, or random")
+	outputJSON := benchFlags.Bool("json", false, "write the report as JSON")
+	if err := benchFlags.Parse(args); err != nil {
+		return err
+	}
+	if opts.Site.Pages < 1 || opts.Site.Fanout < 0 || opts.Runs < 1 {
+		return fmt.Errorf("bench: --pages and --runs must be at least 1 and --fanout not negative")
+	}
+	if opts.Site.ErrorRate < 0 || opts.Site.ErrorRate > 1 {
+		return fmt.Errorf("bench: --error-rate must be between 0 and 1")
+	}
+	parsed, err := crawler.ParseStrategy(*strategy)
+	if err != nil {
+		return fmt.Errorf("bench: %w", err)
+	}
+	opts.Strategy = parsed
 
-	// Depth control
-	flag.IntVar(&opts.depth, "d", 0, "maximum crawl depth (0 = unlimited)")
-	flag.IntVar(&opts.depth, "depth", 0, "maximum crawl depth (0 = unlimited)")
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	report, err := bench.Run(ctx, opts)
+	if err != nil {
+		return fmt.Errorf("bench: %w", err)
+	}
+	if *outputJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	}
+	return report.WriteText(os.Stdout)
+}
 
-	// Output format
-	flag.BoolVar(&opts.outputJSON, "j", false, "output results as JSON")
-	flag.BoolVar(&opts.outputJSON, "json", false, "output results as JSON")
-	flag.BoolVar(&opts.outputCSV, "c", false, "output results as CSV")
-	flag.BoolVar(&opts.outputCSV, "csv", false, "output results as CSV")
-	flag.StringVar(&opts.outputFile, "o", "", "write JSON/CSV output to file")
-	flag.StringVar(&opts.outputFile, "output", "", "write JSON/CSV output to file")
+// writeBaselineDiff prints the diff between the --baseline results and res,
+// and reports whether res has broken links the baseline did not.
+func writeBaselineDiff(opts *cliFlags, res *result.Result) (bool, error) {
+	before, err := readResultsFile(opts.baseline)
+	if err != nil {
+		return true, fmt.Errorf("baseline: %w", err)
+	}
+	// Already validated by validateFlags
+	format, _ := result.ParseDiffFormat(opts.diffFormat)
+	if err := result.WriteDiff(os.Stdout, before, res.BrokenLinks, format); err != nil {
+		return true, fmt.Errorf("write diff: %w", err)
+	}
+	return len(result.NewDiff(before, res.BrokenLinks).New) > 0, nil
+}
 
-	flag.Parse()
-	return opts
+// runServe runs the dashboard and crawl control API until interrupted, then stops any
+// running crawls.
+func runServe(args []string) error {
+	serveFlags := flag.NewFlagSet("serve", flag.ContinueOnError)
+	addr := serveFlags.String("addr", "localhost:8080", "address to listen on")
+	userAgent := serveFlags.String("user-agent", "zombiecrawl/1.0 (+https://github.com/lukemcguire/zombiecrawl)", "user agent string for crawls")
+	db := serveFlags.String("db", "", "append each completed crawl to this run database and show its trends in the dashboard")
+	if err := serveFlags.Parse(args); err != nil {
+		return err
+	}
+
+	api := server.New(func(startURL string) crawler.Config {
+		cfg := crawler.DefaultConfig(startURL)
+		cfg.UserAgent = *userAgent
+		return cfg
+	})
+	api.SetHistory(*db)
+	api.SetAddr(*addr)
+	httpServer := &http.Server{Addr: *addr, Handler: api}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+	serveErr := make(chan error, 1)
+	go func() { serveErr <- httpServer.ListenAndServe() }()
+	fmt.Fprintf(os.Stderr, "zombiecrawl dashboard and API on http://%s/\n", *addr)
+
+	select {
+	case err := <-serveErr:
+		return fmt.Errorf("serve: %w", err)
+	case <-ctx.Done():
+	}
+	api.Close()
+	shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancelShutdown()
+	if err := httpServer.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("shut down server: %w", err)
+	}
+	return nil
 }
 
-// validateFlags validates flag combinations and returns an error if invalid.
-func validateFlags(opts *cliFlags) error {
-	if opts.outputJSON && opts.outputCSV {
-		return fmt.Errorf("--json and --csv are mutually exclusive")
+// runFix implements --fix: it checks the links in the local Markdown and
+// HTML sources named by paths and rewrites the fixable ones, or previews the
+// rewrites as a diff with --dry-run.
+func runFix(ctx context.Context, opts *cliFlags, paths []string) error {
+	if len(paths) == 0 {
+		return fmt.Errorf("--fix: at least one file or directory is required")
+	}
+	files, err := fix.Collect(paths)
+	if err != nil {
+		return fmt.Errorf("fix: %w", err)
+	}
+	fixer := &fix.Fixer{UserAgent: opts.userAgent, Concurrency: opts.concurrency, Archive: newArchiveLookup(opts)}
+	changes, err := fixer.Plan(ctx, files)
+	if err != nil {
+		return fmt.Errorf("fix: %w", err)
+	}
+
+	fixCount := 0
+	for _, change := range changes {
+		fixCount += len(change.Fixes)
+		if opts.dryRun {
+			fmt.Print(fix.Diff(change))
+			continue
+		}
+		if err := fix.Apply(change); err != nil {
+			return fmt.Errorf("fix: %w", err)
+		}
+		for _, f := range change.Fixes {
+			fmt.Printf("%s: %s -> %s (%s)\n", change.Path, f.From, f.To, f.Reason)
+		}
+	}
+	verb := "rewrote"
+	if opts.dryRun {
+		verb = "would rewrite"
 	}
+	fmt.Fprintf(os.Stderr, "%s %d links in %d of %d files\n", verb, fixCount, len(changes), len(files))
 	return nil
 }
 
-// buildCrawlerConfig creates a crawler.Config from flags and the target URL.
-func buildCrawlerConfig(opts *cliFlags, rawURL string) crawler.Config {
-	return crawler.Config{
-		StartURL:        rawURL,
-		Concurrency:     opts.concurrency,
-		RequestTimeout:  10 * time.Second,
-		Delay:           opts.delay,
-		DisableAutoTune: opts.disableAutoTune,
-		VerboseNetwork:  opts.verboseNetwork,
-		UserAgent:       opts.userAgent,
-		MaxDepth:        opts.depth,
-		RetryPolicy: crawler.RetryPolicy{
-			MaxRetries: opts.retries,
-			BaseDelay:  opts.retryDelay,
-			MaxDelay:   30 * time.Second,
-		},
+// sortKey returns the --sort key, validated by validateFlags.
+func sortKey(opts *cliFlags) result.SortKey {
+	key, _ := result.ParseSortKey(opts.sort)
+	return key
+}
+
+// selectTheme returns the TUI theme: "none" with --no-color or NO_COLOR set,
+// else the --theme theme. The default, auto, is "none" when stdout is not a
+// terminal and otherwise matches the terminal's background.
+func selectTheme(opts *cliFlags) tui.Theme {
+	none, _ := tui.LookupTheme("none")
+	if opts.noColor || os.Getenv("NO_COLOR") != "" {
+		return none
+	}
+	if opts.theme != "auto" {
+		theme, _ := tui.LookupTheme(opts.theme)
+		return theme
 	}
+	if info, err := os.Stdout.Stat(); err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		return none
+	}
+	return tui.AutoTheme()
 }
 
 // runTUI creates and runs the TUI, returning the final model.
-func runTUI(ctx context.Context, cancel context.CancelFunc, cfg crawler.Config) (tui.Model, error) {
+func runTUI(ctx context.Context, cancel context.CancelFunc, cfg crawler.Config, opts *cliFlags) (tui.Model, error) {
 	progressCh := make(chan crawler.CrawlEvent, 100)
 	crawlerInstance, err := crawler.New(cfg, progressCh)
 	if err != nil {
 		return tui.Model{}, fmt.Errorf("create crawler: %w", err)
 	}
 
-	tuiModel := tui.NewModel(ctx, cancel, crawlerInstance, progressCh)
+	tuiModel := tui.NewModel(ctx, cancel, crawlerInstance, progressCh).
+		WithFullURLs(opts.fullURLs).
+		WithBrowse(opts.browse).
+		WithBrokenFeed(opts.recentBroken).
+		WithSort(sortKey(opts))
 	program := tea.NewProgram(tuiModel)
 
 	finalModel, err := program.Run()
@@ -101,9 +1674,159 @@ func runTUI(ctx context.Context, cancel context.CancelFunc, cfg crawler.Config)
 		return tui.Model{}, fmt.Errorf("run tui: %w", err)
 	}
 
+	// After q or Ctrl+C the crawl is still stopping
+	return finalModel.(tui.Model).Wait(), nil
+}
+
+// runSummaryOnly crawls without showing progress, then prints the TUI's
+// summary and returns the final model, as runTUI does. The summary goes to
+// stderr when structured output takes stdout.
+func runSummaryOnly(ctx context.Context, cancel context.CancelFunc, cfg crawler.Config, opts *cliFlags) (tui.Model, error) {
+	crawlerInstance, err := crawler.New(cfg, nil)
+	if err != nil {
+		return tui.Model{}, fmt.Errorf("create crawler: %w", err)
+	}
+	res, err := crawlerInstance.Run(ctx)
+	if err != nil {
+		err = fmt.Errorf("crawl: %w", err)
+	}
+
+	model := tui.NewModel(ctx, cancel, crawlerInstance, nil).WithSort(sortKey(opts))
+	finalModel, _ := model.Update(tui.CrawlDoneMsg{Result: res, Err: err})
+	var out io.Writer = os.Stdout
+	if opts.outputFile == "" && (opts.outputJSON || opts.outputCSV || opts.template != "" || opts.jsonEnvelope) {
+		out = os.Stderr
+	}
+	fmt.Fprint(out, finalModel.View())
 	return finalModel.(tui.Model), nil
 }
 
+// openStream creates the -o file for --stream and returns a sink appending
+// each broken link to it as the crawl finds it. Links that --verify later
+// finds flaky stay in the file. It returns a nil sink when --stream is not
+// set; close must be called either way.
+func openStream(opts *cliFlags) (crawler.ResultSink, func(), error) {
+	if !opts.stream {
+		return nil, func() {}, nil
+	}
+	outFile, err := os.Create(opts.outputFile)
+	if err != nil {
+		return nil, nil, fmt.Errorf("create output file: %w", err)
+	}
+	closeFile := func() {
+		if cerr := outFile.Close(); cerr != nil {
+			fmt.Fprintf(os.Stderr, "Error closing output file: %v\n", cerr)
+		}
+	}
+	if !opts.outputCSV {
+		return crawler.NewJSONResultSink(outFile), closeFile, nil
+	}
+	sink, err := crawler.NewCSVResultSink(outFile)
+	if err != nil {
+		closeFile()
+		return nil, nil, err
+	}
+	return sink, closeFile, nil
+}
+
+// parseLogLevel converts a --log-level name into a slog.Level.
+func parseLogLevel(name string) (slog.Level, error) {
+	switch name {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "", "info":
+		return slog.LevelInfo, nil
+	case "warn":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	default:
+		return 0, fmt.Errorf("unknown --log-level %q (want debug, info, warn, or error)", name)
+	}
+}
+
+// openLog returns a JSON logger writing to --log-file at --log-level, and a
+// function that closes the file. Without --log-file the logger is nil, so
+// the crawler discards its records.
+func openLog(opts *cliFlags) (*slog.Logger, func(), error) {
+	if opts.logFile == "" {
+		return nil, func() {}, nil
+	}
+	// Already validated by validateFlags
+	level, _ := parseLogLevel(opts.logLevel)
+	handlerOpts := &slog.HandlerOptions{Level: level}
+	if opts.logFile == "-" {
+		return slog.New(slog.NewJSONHandler(os.Stderr, handlerOpts)), func() {}, nil
+	}
+	logFile, err := os.Create(opts.logFile)
+	if err != nil {
+		return nil, nil, fmt.Errorf("create log file: %w", err)
+	}
+	closeFile := func() {
+		if cerr := logFile.Close(); cerr != nil {
+			fmt.Fprintf(os.Stderr, "Error closing log file: %v\n", cerr)
+		}
+	}
+	return slog.New(slog.NewJSONHandler(logFile, handlerOpts)), closeFile, nil
+}
+
+// startPlugins starts the --plugin commands, which write their diagnostics
+// to stderr, and returns a function that stops them.
+func startPlugins(opts *cliFlags) ([]crawler.Plugin, func(), error) {
+	var plugins []crawler.Plugin
+	var started []*crawler.CommandPlugin
+	stop := func() {
+		for _, plugin := range started {
+			if err := plugin.Close(); err != nil {
+				fmt.Fprintf(os.Stderr, "Error stopping plugin: %v\n", err)
+			}
+		}
+	}
+	for _, command := range opts.plugins {
+		// Already validated by validateFlags
+		args := strings.Fields(command)
+		cmd := exec.Command(args[0], args[1:]...)
+		cmd.Stderr = os.Stderr
+		plugin, err := crawler.StartCommandPlugin(cmd)
+		if err != nil {
+			stop()
+			return nil, nil, fmt.Errorf("--plugin %q: %w", command, err)
+		}
+		plugins = append(plugins, plugin)
+		started = append(started, plugin)
+	}
+	return plugins, stop, nil
+}
+
+// writeSplitOutput writes the broken links into --split-output, one file per
+// error category, if set.
+func writeSplitOutput(opts *cliFlags, links []result.LinkResult) error {
+	if opts.splitOutput == "" {
+		return nil
+	}
+	splitOpts := result.SplitOptions{CSV: opts.outputCSV, ByHost: opts.splitByHost}
+	if _, err := result.WriteSplit(opts.splitOutput, links, splitOpts); err != nil {
+		return fmt.Errorf("split output: %w", err)
+	}
+	return nil
+}
+
+// writeTemplate renders the result of each successfully crawled site through
+// the --template file, one after another.
+func writeTemplate(writer io.Writer, opts *cliFlags, sites []result.SiteResult) error {
+	// Already validated by validateFlags
+	tmpl, _ := result.ParseTemplateFile(opts.template)
+	for _, site := range sites {
+		if site.Result == nil {
+			continue
+		}
+		if err := result.WriteTemplate(writer, tmpl, site.Site, site.Result); err != nil {
+			return fmt.Errorf("%s: %w", site.Site, err)
+		}
+	}
+	return nil
+}
+
 // writeResults writes structured output to the specified writer.
 func writeResults(writer io.Writer, links []result.LinkResult, useJSON bool) error {
 	if useJSON {
@@ -118,8 +1841,9 @@ func writeResults(writer io.Writer, links []result.LinkResult, useJSON bool) err
 	return nil
 }
 
-// writeStructuredOutput handles writing JSON/CSV output to stdout or a file.
-func writeStructuredOutput(opts *cliFlags, model tui.Model) error {
+// writeStructuredOutput handles writing JSON/CSV or --template output for
+// the crawl with cfg to stdout or a file.
+func writeStructuredOutput(opts *cliFlags, cfg crawler.Config, startedAt time.Time, model tui.Model) error {
 	crawlResult := model.GetResult()
 	if crawlResult == nil {
 		return nil
@@ -139,53 +1863,311 @@ func writeStructuredOutput(opts *cliFlags, model tui.Model) error {
 		writer = outFile
 	}
 
+	if opts.template != "" {
+		return writeTemplate(writer, opts, []result.SiteResult{{Site: cfg.Scrub.String(cfg.StartURL), Result: crawlResult}})
+	}
+	if cfg.Deterministic {
+		startedAt = crawler.DeterministicEpoch
+	}
+	if opts.jsonEnvelope {
+		return result.WriteEnvelope(writer, result.Envelope{
+			Version:     toolVersion(),
+			RunID:       cfg.RunID,
+			StartURL:    cfg.Scrub.String(cfg.StartURL),
+			StartedAt:   startedAt,
+			FinishedAt:  startedAt.Add(crawlResult.Stats.Duration),
+			Config:      cfg.Snapshot(),
+			Stats:       &crawlResult.Stats,
+			BrokenLinks: crawlResult.BrokenLinks,
+		})
+	}
+
 	// Default to JSON if -o specified without format
 	useJSON := opts.outputJSON || (!opts.outputCSV && opts.outputFile != "")
 
 	return writeResults(writer, crawlResult.BrokenLinks, useJSON)
 }
 
+// runCompare crawls as the --as and --compare-as presets and prints which
+// URLs only one of them reached.
+func runCompare(ctx context.Context, opts *cliFlags, cfg crawler.Config) error {
+	// Already validated by validateFlags
+	presetA, _ := crawler.LookupPreset(opts.as)
+	presetB, _ := crawler.LookupPreset(opts.compareAs)
+
+	cmp, err := crawler.Compare(ctx, cfg, presetA, presetB)
+	if err != nil {
+		return fmt.Errorf("compare: %w", err)
+	}
+
+	if opts.outputJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetEscapeHTML(false)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(cmp); err != nil {
+			return fmt.Errorf("write json: %w", err)
+		}
+		return nil
+	}
+	result.PrintComparison(os.Stdout, cmp)
+	return nil
+}
+
+// runDryRun prints the crawl plan for the start page without running the TUI.
+func runDryRun(ctx context.Context, opts *cliFlags, cfg crawler.Config) error {
+	crawlerInstance, err := crawler.New(cfg, nil)
+	if err != nil {
+		return fmt.Errorf("create crawler: %w", err)
+	}
+
+	plan, err := crawlerInstance.Plan(ctx)
+	if err != nil {
+		return fmt.Errorf("plan crawl: %w", err)
+	}
+
+	if opts.outputJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetEscapeHTML(false)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(plan); err != nil {
+			return fmt.Errorf("write json: %w", err)
+		}
+		return nil
+	}
+	result.PrintPlan(os.Stdout, plan)
+	return nil
+}
+
 func main() {
+	subcommands := map[string]func([]string) error{
+		"report":  runReport,
+		"stats":   runStats,
+		"serve":   runServe,
+		"diff":    runDiff,
+		"inspect": runInspect,
+		"version": runVersion,
+		"bench":   runBench, // Not in the usage text: a tool for working on zombiecrawl itself
+	}
+	if len(os.Args) > 1 && subcommands[os.Args[1]] != nil {
+		run := subcommands[os.Args[1]]
+		if err := run(os.Args[2:]); err != nil {
+			if errors.Is(err, flag.ErrHelp) {
+				return
+			}
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	opts := parseFlags()
 
+	if err := applyProfile(opts); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
 	if err := validateFlags(opts); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
 
+	tui.UseTheme(selectTheme(opts))
+	opts.runID = crawler.NewRunID()
+	if opts.deterministic {
+		opts.runID = crawler.DeterministicRunID
+	}
+
+	if opts.fix {
+		ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer cancel()
+		if err := runFix(ctx, opts, flag.Args()); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	replay, err := openReplay(opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if opts.urlFile != "" {
+		urls, err := readURLFile(opts.urlFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		for _, rawURL := range flag.Args() {
+			startURL, err := completeStartURL(rawURL)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "%v\n", err)
+				os.Exit(1)
+			}
+			urls = append(urls, startURL)
+		}
+
+		cache, err := openExternalCache(opts)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer cancel()
+
+		failed, err := runSites(ctx, opts, urls, cache, replay)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if failed {
+			os.Exit(1)
+		}
+		return
+	}
+
 	if flag.NArg() < 1 {
 		fmt.Fprintln(os.Stderr, "Usage: zombiecrawl [flags] <url>")
+		fmt.Fprintln(os.Stderr, "       zombiecrawl [flags] --url-file <file> [url...]")
+		fmt.Fprintln(os.Stderr, "       zombiecrawl --fix [--dry-run] [--suggest-archive] <file-or-dir>...")
+		fmt.Fprintln(os.Stderr, "       zombiecrawl report --db <file> [--format text|csv|html] [-o file]")
+		fmt.Fprintln(os.Stderr, "       zombiecrawl stats --db <file> [--format text|json] [--top n] [-o file]")
+		fmt.Fprintln(os.Stderr, "       zombiecrawl serve [--addr host:port] [--db file]")
+		fmt.Fprintln(os.Stderr, "       zombiecrawl diff [--format text|markdown|json] [-o file] <before.json> <after.json>")
+		fmt.Fprintln(os.Stderr, "       zombiecrawl inspect [-n count] <state.json>")
+		fmt.Fprintln(os.Stderr, "       zombiecrawl version [--json]")
 		fmt.Fprintln(os.Stderr, "Flags:")
 		flag.PrintDefaults()
 		os.Exit(1)
 	}
 
-	rawURL := flag.Arg(0)
-	parsedURL, err := url.Parse(rawURL)
-	if err != nil || (parsedURL.Scheme != "http" && parsedURL.Scheme != "https") {
-		fmt.Fprintf(os.Stderr, "Invalid URL: %s\nURL must start with http:// or https://\n", rawURL)
+	rawURL, err := completeStartURL(flag.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
 		os.Exit(1)
 	}
 
-	ctx, cancel := context.WithCancel(context.Background())
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
 	defer cancel()
 
 	cfg := buildCrawlerConfig(opts, rawURL)
+	applyReplay(&cfg, replay)
+
+	if opts.compareAs != "" {
+		if err := runCompare(ctx, opts, cfg); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if opts.dryRun {
+		if err := runDryRun(ctx, opts, cfg); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	cache, err := openExternalCache(opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	cfg.ExternalCache = cache
+	cfg.HAR = newHARRecorder(opts)
+	cfg.State = newStateRecorder(opts)
+	cfg.Archive = newArchiveLookup(opts)
+	stream, closeStream, err := openStream(opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	cfg.Results = stream
+	logger, closeLog, err := openLog(opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	cfg.Logger = logger
+	plugins, stopPlugins, err := startPlugins(opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	cfg.Plugins = plugins
+
+	startedAt := time.Now()
+	run := runTUI
+	if opts.summaryOnly || opts.progressJSON {
+		run = runSummaryOnly
+	}
+	finalTUIModel, err := run(ctx, cancel, cfg, opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	stopPlugins()
+	closeStream()
+	closeLog()
+
+	if err := cache.Save(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: save external cache: %v\n", err)
+		os.Exit(1)
+	}
+
+	if err := saveHAR(opts, cfg.HAR); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if err := saveState(opts, cfg.State); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
 
-	finalTUIModel, err := runTUI(ctx, cancel, cfg)
+	// Runs are recorded under the start URL as it appears in reports
+	site := cfg.Scrub.String(rawURL)
+	previous, err := previousRun(opts, site)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
+	if err := recordRun(opts, site, startedAt, finalTUIModel.GetResult()); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	notifyRun(context.Background(), opts, previous, site, startedAt, finalTUIModel.GetResult())
+
+	if res := finalTUIModel.GetResult(); res != nil {
+		if err := writeSplitOutput(opts, res.BrokenLinks); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
 
 	// Write structured output if requested
-	if opts.outputJSON || opts.outputCSV || opts.outputFile != "" {
-		if err := writeStructuredOutput(opts, finalTUIModel); err != nil {
+	if (opts.outputJSON || opts.outputCSV || opts.outputFile != "" || opts.template != "" || opts.jsonEnvelope) && !opts.stream {
+		if err := writeStructuredOutput(opts, cfg, startedAt, finalTUIModel); err != nil {
 			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 			os.Exit(1)
 		}
 	}
 
+	if opts.baseline != "" && finalTUIModel.GetResult() != nil {
+		hasNew, err := writeBaselineDiff(opts, finalTUIModel.GetResult())
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if hasNew {
+			os.Exit(1)
+		}
+		return
+	}
+
 	if finalTUIModel.HasBrokenLinks() {
 		os.Exit(1)
 	}
@@ -0,0 +1,265 @@
+// Package notify posts crawl summaries to chat webhooks. Slack, Discord and
+// Microsoft Teams get native message layouts; any other endpoint can receive
+// the plain JSON summary.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/lukemcguire/zombiecrawl/history"
+)
+
+// Format selects the webhook payload layout.
+type Format string
+
+// Supported formats.
+const (
+	FormatSlack   Format = "slack"   // Slack Block Kit message
+	FormatDiscord Format = "discord" // Discord embed
+	FormatTeams   Format = "teams"   // Microsoft Teams Adaptive Card
+	FormatWebhook Format = "webhook" // The Summary as plain JSON
+)
+
+// maxListed caps how many links a message lists before "and N more".
+const maxListed = 10
+
+// Summary describes one crawl compared with the previous run of the site.
+type Summary struct {
+	Site        string   `json:"site"`                 // The crawl's start URL
+	Checked     int      `json:"checked"`              // URLs checked
+	Broken      int      `json:"broken"`               // Broken links found
+	NewlyBroken []string `json:"newly_broken"`         // Broken now but not in the previous run
+	Fixed       []string `json:"fixed"`                // Broken in the previous run but not now
+	ReportURL   string   `json:"report_url,omitempty"` // Where the full report can be read
+	RunID       string   `json:"run_id,omitempty"`     // The crawl's run ID, for finding its logs and stored run
+}
+
+// NewSummary compares run with the site's previous run. Without a previous
+// run every broken link counts as new.
+func NewSummary(run history.Run, previous *history.Run, reportURL string) Summary {
+	summary := Summary{
+		Site:        run.Site,
+		Checked:     run.Stats.TotalChecked,
+		Broken:      len(run.Broken),
+		NewlyBroken: slices.Clone(run.Broken),
+		Fixed:       []string{},
+		ReportURL:   reportURL,
+		RunID:       run.RunID,
+	}
+	if previous != nil {
+		summary.NewlyBroken = history.Difference(run.Broken, previous.Broken)
+		summary.Fixed = history.Difference(previous.Broken, run.Broken)
+	}
+	return summary
+}
+
+// ParseFormat validates a format name.
+func ParseFormat(name string) (Format, error) {
+	switch format := Format(name); format {
+	case FormatSlack, FormatDiscord, FormatTeams, FormatWebhook:
+		return format, nil
+	}
+	return "", fmt.Errorf("unknown notification format %q (want slack, discord, teams, or webhook)", name)
+}
+
+// Notifier posts summaries to one webhook.
+type Notifier struct {
+	Format Format
+	URL    string
+	// MinNewBroken is how many newly broken links a crawl must find before
+	// a message is sent; 0 notifies after every crawl.
+	MinNewBroken int
+	// Client sends the request; nil uses http.DefaultClient.
+	Client *http.Client
+}
+
+// Send posts summary to the webhook, unless it has fewer newly broken links
+// than MinNewBroken. It reports whether a message was sent.
+func (n Notifier) Send(ctx context.Context, summary Summary) (bool, error) {
+	if len(summary.NewlyBroken) < n.MinNewBroken {
+		return false, nil
+	}
+	body, err := Payload(n.Format, summary)
+	if err != nil {
+		return false, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, fmt.Errorf("create %s notification: %w", n.Format, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	client := n.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return false, fmt.Errorf("send %s notification: %w", n.Format, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return false, fmt.Errorf("send %s notification: webhook returned %s", n.Format, resp.Status)
+	}
+	return true, nil
+}
+
+// Payload renders summary as the JSON body for format.
+func Payload(format Format, summary Summary) ([]byte, error) {
+	var payload any
+	switch format {
+	case FormatSlack:
+		payload = slackPayload(summary)
+	case FormatDiscord:
+		payload = discordPayload(summary)
+	case FormatTeams:
+		payload = teamsPayload(summary)
+	case FormatWebhook:
+		payload = summary
+	default:
+		return nil, fmt.Errorf("unknown notification format %q", format)
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(payload); err != nil {
+		return nil, fmt.Errorf("encode %s notification: %w", format, err)
+	}
+	return buf.Bytes(), nil
+}
+
+// title is the one-line headline shared by every layout.
+func (s Summary) title() string {
+	switch len(s.NewlyBroken) {
+	case 0:
+		return "No new broken links on " + s.Site
+	case 1:
+		return "1 new broken link on " + s.Site
+	}
+	return fmt.Sprintf("%d new broken links on %s", len(s.NewlyBroken), s.Site)
+}
+
+// listed returns up to maxListed links formatted by item, plus a trailing
+// "and N more" line if some were left out.
+func listed(links []string, item func(string) string) string {
+	var lines []string
+	for i, link := range links {
+		if i == maxListed {
+			lines = append(lines, fmt.Sprintf("…and %d more", len(links)-maxListed))
+			break
+		}
+		lines = append(lines, item(link))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func slackPayload(s Summary) map[string]any {
+	blocks := []map[string]any{
+		{"type": "header", "text": map[string]any{"type": "plain_text", "text": s.title()}},
+		{"type": "section", "fields": []map[string]any{
+			{"type": "mrkdwn", "text": fmt.Sprintf("*Checked*\n%d", s.Checked)},
+			{"type": "mrkdwn", "text": fmt.Sprintf("*Broken*\n%d", s.Broken)},
+			{"type": "mrkdwn", "text": fmt.Sprintf("*Fixed*\n%d", len(s.Fixed))},
+		}},
+	}
+	if len(s.NewlyBroken) > 0 {
+		blocks = append(blocks, map[string]any{
+			"type": "section",
+			"text": map[string]any{"type": "mrkdwn", "text": listed(s.NewlyBroken, func(link string) string { return "• <" + link + ">" })},
+		})
+	}
+	if s.RunID != "" {
+		blocks = append(blocks, map[string]any{
+			"type":     "context",
+			"elements": []map[string]any{{"type": "mrkdwn", "text": "Run " + s.RunID}},
+		})
+	}
+	if s.ReportURL != "" {
+		blocks = append(blocks, map[string]any{
+			"type": "actions",
+			"elements": []map[string]any{{
+				"type": "button",
+				"text": map[string]any{"type": "plain_text", "text": "Open report"},
+				"url":  s.ReportURL,
+			}},
+		})
+	}
+	return map[string]any{"text": s.title(), "blocks": blocks}
+}
+
+// Discord embed accent colors for crawls with and without new breakages.
+const (
+	discordRed   = 0xB00020
+	discordGreen = 0x157A2A
+)
+
+func discordPayload(s Summary) map[string]any {
+	color := discordGreen
+	if len(s.NewlyBroken) > 0 {
+		color = discordRed
+	}
+	embed := map[string]any{
+		"title": s.title(),
+		"color": color,
+		"fields": []map[string]any{
+			{"name": "Checked", "value": fmt.Sprint(s.Checked), "inline": true},
+			{"name": "Broken", "value": fmt.Sprint(s.Broken), "inline": true},
+			{"name": "Fixed", "value": fmt.Sprint(len(s.Fixed)), "inline": true},
+		},
+	}
+	if len(s.NewlyBroken) > 0 {
+		embed["description"] = listed(s.NewlyBroken, func(link string) string { return "• " + link })
+	}
+	if s.ReportURL != "" {
+		embed["url"] = s.ReportURL
+	}
+	if s.RunID != "" {
+		embed["footer"] = map[string]any{"text": "Run " + s.RunID}
+	}
+	return map[string]any{"embeds": []map[string]any{embed}}
+}
+
+func teamsPayload(s Summary) map[string]any {
+	body := []map[string]any{
+		{"type": "TextBlock", "text": s.title(), "weight": "Bolder", "size": "Medium", "wrap": true},
+		{"type": "FactSet", "facts": []map[string]any{
+			{"title": "Checked", "value": fmt.Sprint(s.Checked)},
+			{"title": "Broken", "value": fmt.Sprint(s.Broken)},
+			{"title": "Fixed", "value": fmt.Sprint(len(s.Fixed))},
+		}},
+	}
+	if len(s.NewlyBroken) > 0 {
+		body = append(body, map[string]any{
+			"type": "TextBlock",
+			"text": listed(s.NewlyBroken, func(link string) string { return "- " + link }),
+			"wrap": true,
+		})
+	}
+	if s.RunID != "" {
+		body = append(body, map[string]any{"type": "TextBlock", "text": "Run " + s.RunID, "isSubtle": true, "size": "Small"})
+	}
+	card := map[string]any{
+		"$schema": "http://adaptivecards.io/schemas/adaptive-card.json",
+		"type":    "AdaptiveCard",
+		"version": "1.4",
+		"body":    body,
+	}
+	if s.ReportURL != "" {
+		card["actions"] = []map[string]any{{"type": "Action.OpenUrl", "title": "Open report", "url": s.ReportURL}}
+	}
+	return map[string]any{
+		"type": "message",
+		"attachments": []map[string]any{{
+			"contentType": "application/vnd.microsoft.card.adaptive",
+			"content":     card,
+		}},
+	}
+}
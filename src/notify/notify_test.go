@@ -0,0 +1,142 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/lukemcguire/zombiecrawl/history"
+	"github.com/lukemcguire/zombiecrawl/result"
+)
+
+func TestNewSummary(t *testing.T) {
+	previous := history.Run{Site: "http://a.example/", Broken: []string{"http://a.example/fixed", "http://a.example/still"}}
+	run := history.Run{
+		RunID:  "01J0000000000000000000000A",
+		Site:   "http://a.example/",
+		Stats:  result.CrawlStats{TotalChecked: 12},
+		Broken: []string{"http://a.example/new", "http://a.example/still"},
+	}
+
+	got := NewSummary(run, &previous, "https://ci.example/report.html")
+	if got.Checked != 12 || got.Broken != 2 {
+		t.Errorf("counts = %d checked, %d broken, want 12 and 2", got.Checked, got.Broken)
+	}
+	if got.RunID != run.RunID {
+		t.Errorf("RunID = %q, want %q", got.RunID, run.RunID)
+	}
+	if fmt.Sprint(got.NewlyBroken) != "[http://a.example/new]" || fmt.Sprint(got.Fixed) != "[http://a.example/fixed]" {
+		t.Errorf("newly broken %v, fixed %v", got.NewlyBroken, got.Fixed)
+	}
+
+	first := NewSummary(run, nil, "")
+	if len(first.NewlyBroken) != 2 {
+		t.Errorf("without a previous run NewlyBroken = %v, want every broken link", first.NewlyBroken)
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	for _, name := range []string{"slack", "discord", "teams", "webhook"} {
+		if _, err := ParseFormat(name); err != nil {
+			t.Errorf("ParseFormat(%q) error: %v", name, err)
+		}
+	}
+	if _, err := ParseFormat("irc"); err == nil {
+		t.Error("ParseFormat(irc) should fail")
+	}
+}
+
+func manyLinks(n int) []string {
+	links := make([]string, n)
+	for i := range links {
+		links[i] = fmt.Sprintf("http://a.example/%d", i)
+	}
+	return links
+}
+
+func TestPayload_Layouts(t *testing.T) {
+	summary := Summary{
+		Site:        "http://a.example/",
+		Checked:     40,
+		Broken:      12,
+		NewlyBroken: manyLinks(12),
+		Fixed:       []string{},
+		ReportURL:   "https://ci.example/report.html",
+		RunID:       "01J0000000000000000000000A",
+	}
+
+	tests := []struct {
+		format Format
+		want   []string
+	}{
+		{FormatSlack, []string{`"blocks"`, `"type":"header"`, `12 new broken links on http://a.example/`, `<http://a.example/9>`, `…and 2 more`, `"url":"https://ci.example/report.html"`, `Run 01J0000000000000000000000A`}},
+		{FormatDiscord, []string{`"embeds"`, `"color":11534368`, `"url":"https://ci.example/report.html"`, `…and 2 more`, `"footer":{"text":"Run 01J0000000000000000000000A"}`}},
+		{FormatTeams, []string{`"application/vnd.microsoft.card.adaptive"`, `"AdaptiveCard"`, `"FactSet"`, `"Action.OpenUrl"`, `Run 01J0000000000000000000000A`}},
+		{FormatWebhook, []string{`"newly_broken":["http://a.example/0"`, `"report_url":"https://ci.example/report.html"`, `"run_id":"01J0000000000000000000000A"`}},
+	}
+	for _, tt := range tests {
+		data, err := Payload(tt.format, summary)
+		if err != nil {
+			t.Fatalf("Payload(%s) error: %v", tt.format, err)
+		}
+		if !json.Valid(data) {
+			t.Fatalf("Payload(%s) is not valid JSON", tt.format)
+		}
+		for _, want := range tt.want {
+			if !strings.Contains(string(data), want) {
+				t.Errorf("Payload(%s) missing %s:\n%s", tt.format, want, data)
+			}
+		}
+		if strings.Contains(string(data), "http://a.example/10") && tt.format != FormatWebhook {
+			t.Errorf("Payload(%s) listed more than %d links", tt.format, maxListed)
+		}
+	}
+}
+
+func TestNotifier_Send(t *testing.T) {
+	var bodies []string
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("Content-Type = %q, want application/json", r.Header.Get("Content-Type"))
+		}
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+	}))
+	defer hook.Close()
+
+	n := Notifier{Format: FormatSlack, URL: hook.URL, MinNewBroken: 1}
+	quiet := Summary{Site: "http://a.example/", NewlyBroken: []string{}}
+	if sent, err := n.Send(context.Background(), quiet); err != nil || sent {
+		t.Errorf("Send(no new breakage) = %v, %v, want skipped", sent, err)
+	}
+	loud := Summary{Site: "http://a.example/", NewlyBroken: []string{"http://a.example/x"}}
+	if sent, err := n.Send(context.Background(), loud); err != nil || !sent {
+		t.Errorf("Send(new breakage) = %v, %v, want sent", sent, err)
+	}
+	if len(bodies) != 1 || !strings.Contains(bodies[0], "1 new broken link on http://a.example/") {
+		t.Errorf("webhook received %v", bodies)
+	}
+
+	n.MinNewBroken = 0
+	if sent, _ := n.Send(context.Background(), quiet); !sent {
+		t.Error("MinNewBroken 0 should notify after every crawl")
+	}
+}
+
+func TestNotifier_SendReportsWebhookErrors(t *testing.T) {
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "no such hook", http.StatusNotFound)
+	}))
+	defer hook.Close()
+
+	n := Notifier{Format: FormatDiscord, URL: hook.URL}
+	_, err := n.Send(context.Background(), Summary{Site: "http://a.example/"})
+	if err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("Send() error = %v, want the webhook's 404", err)
+	}
+}
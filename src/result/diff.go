@@ -0,0 +1,199 @@
+package result
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// DiffFormat selects how WriteDiff renders a Diff.
+type DiffFormat string
+
+const (
+	DiffText     DiffFormat = "text"     // Plain text for terminals and logs
+	DiffMarkdown DiffFormat = "markdown" // Markdown for pull request comments
+	DiffJSON     DiffFormat = "json"     // JSON for scripts
+)
+
+// ParseDiffFormat converts a user-supplied format name into a DiffFormat.
+// An empty name selects DiffText.
+func ParseDiffFormat(name string) (DiffFormat, error) {
+	switch DiffFormat(name) {
+	case "", DiffText:
+		return DiffText, nil
+	case DiffMarkdown, DiffJSON:
+		return DiffFormat(name), nil
+	default:
+		return "", fmt.Errorf("unknown diff format %q (want text, markdown, or json)", name)
+	}
+}
+
+// Diff contrasts the broken links of two crawls of the same site. A link is
+// identified by its URL and the page it was found on, so the same dead URL
+// newly linked from another page counts as new.
+type Diff struct {
+	New         []LinkResult `json:"new"`          // Broken now but not before
+	Fixed       []LinkResult `json:"fixed"`        // Broken before but not now
+	StillBroken []LinkResult `json:"still_broken"` // Broken in both crawls
+}
+
+// NewDiff compares the broken links of an earlier crawl (before) with a
+// later one (after). Each list keeps the order of the crawl it came from;
+// still-broken links take their details from after.
+func NewDiff(before, after []LinkResult) Diff {
+	key := func(link LinkResult) [2]string { return [2]string{link.URL, link.SourcePage} }
+
+	wasBroken := make(map[[2]string]bool, len(before))
+	for _, link := range before {
+		wasBroken[key(link)] = true
+	}
+	isBroken := make(map[[2]string]bool, len(after))
+	diff := Diff{New: []LinkResult{}, Fixed: []LinkResult{}, StillBroken: []LinkResult{}}
+	for _, link := range after {
+		if isBroken[key(link)] {
+			continue
+		}
+		isBroken[key(link)] = true
+		if wasBroken[key(link)] {
+			diff.StillBroken = append(diff.StillBroken, link)
+		} else {
+			diff.New = append(diff.New, link)
+		}
+	}
+	for _, link := range before {
+		if !isBroken[key(link)] {
+			isBroken[key(link)] = true // Report duplicates once
+			diff.Fixed = append(diff.Fixed, link)
+		}
+	}
+	return diff
+}
+
+// WriteDiff writes the diff between the broken links of two crawls to w in
+// the given format.
+func WriteDiff(w io.Writer, before, after []LinkResult, format DiffFormat) error {
+	diff := NewDiff(before, after)
+	switch format {
+	case DiffJSON:
+		enc := json.NewEncoder(w)
+		enc.SetEscapeHTML(false)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(diff); err != nil {
+			return fmt.Errorf("write json diff: %w", err)
+		}
+		return nil
+	case DiffMarkdown:
+		_, err := io.WriteString(w, diff.markdown())
+		return err
+	case "", DiffText:
+		_, err := io.WriteString(w, diff.text())
+		return err
+	default:
+		return fmt.Errorf("unknown diff format %q", format)
+	}
+}
+
+// headline summarizes the counts, e.g. "2 new, 1 fixed, 3 still broken".
+func (d Diff) headline() string {
+	return fmt.Sprintf("%d new, %d fixed, %d still broken", len(d.New), len(d.Fixed), len(d.StillBroken))
+}
+
+// text renders the diff with one "+", "-" or "=" prefixed line per link.
+func (d Diff) text() string {
+	var b strings.Builder
+	b.WriteString("Broken links: " + d.headline() + "\n")
+	for _, section := range []struct {
+		marker string
+		links  []LinkResult
+	}{{"+", d.New}, {"-", d.Fixed}, {"=", d.StillBroken}} {
+		for _, link := range section.links {
+			fmt.Fprintf(&b, "%s %s (%s) on %s\n", section.marker, link.URL, FormatStatus(link), link.SourcePage)
+		}
+	}
+	return b.String()
+}
+
+// markdown renders the diff for a pull request comment: new links in a table,
+// fixed and still-broken links in collapsed sections.
+func (d Diff) markdown() string {
+	var b strings.Builder
+	b.WriteString("### Broken links: " + d.headline() + "\n")
+	writeTable := func(links []LinkResult) {
+		b.WriteString("\n" + MarkdownTable(links))
+	}
+	if len(d.New) > 0 {
+		fmt.Fprintf(&b, "\n**New (%d)**\n", len(d.New))
+		writeTable(d.New)
+	}
+	for _, section := range []struct {
+		title string
+		links []LinkResult
+	}{{"Fixed", d.Fixed}, {"Still broken", d.StillBroken}} {
+		if len(section.links) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "\n<details><summary>%s (%d)</summary>\n", section.title, len(section.links))
+		writeTable(section.links)
+		b.WriteString("\n</details>\n")
+	}
+	return b.String()
+}
+
+// MarkdownTable renders links as a Markdown table of their URL, status, and
+// the page they were found on.
+func MarkdownTable(links []LinkResult) string {
+	var b strings.Builder
+	b.WriteString("| Link | Status | Found on |\n|---|---|---|\n")
+	for _, link := range links {
+		fmt.Fprintf(&b, "| %s | %s | %s |\n", markdownCell(link.URL), markdownCell(FormatStatus(link)), markdownCell(link.SourcePage))
+	}
+	return b.String()
+}
+
+// markdownCell escapes s for use in a Markdown table cell.
+func markdownCell(s string) string {
+	s = strings.ReplaceAll(s, "|", `\|`)
+	return strings.ReplaceAll(s, "\n", " ")
+}
+
+// ReadJSON reads broken links written by WriteJSON. It also accepts the
+// per-site JSON output of a multi-site crawl and envelopes written by
+// WriteEnvelope, returning the broken links of every site.
+func ReadJSON(r io.Reader) ([]LinkResult, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("read json results: %w", err)
+	}
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '{' {
+		var env Envelope
+		if err := json.Unmarshal(trimmed, &env); err != nil {
+			return nil, fmt.Errorf("read json envelope: %w", err)
+		}
+		if env.Tool != ToolName {
+			return nil, fmt.Errorf("read json results: not a %s envelope", ToolName)
+		}
+		return env.BrokenLinks, nil
+	}
+
+	// Site entries have a "site" key; link entries do not.
+	var entries []struct {
+		LinkResult
+		Site   string  `json:"site"`
+		Result *Result `json:"result"`
+	}
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("read json results: %w", err)
+	}
+	links := make([]LinkResult, 0, len(entries))
+	for _, entry := range entries {
+		switch {
+		case entry.Site == "":
+			links = append(links, entry.LinkResult)
+		case entry.Result != nil:
+			links = append(links, entry.Result.BrokenLinks...)
+		}
+	}
+	return links, nil
+}
@@ -0,0 +1,136 @@
+package result
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+var (
+	diffBefore = []LinkResult{
+		{URL: "https://example.com/gone", StatusCode: 404, SourcePage: "https://example.com/"},
+		{URL: "https://example.com/fixed", StatusCode: 500, SourcePage: "https://example.com/"},
+	}
+	diffAfter = []LinkResult{
+		{URL: "https://example.com/gone", StatusCode: 410, SourcePage: "https://example.com/"},
+		{URL: "https://example.com/gone", StatusCode: 410, SourcePage: "https://example.com/about"},
+		{URL: "https://down.example/", Error: "dial tcp: connection refused", SourcePage: "https://example.com/"},
+	}
+)
+
+func TestParseDiffFormat(t *testing.T) {
+	for name, want := range map[string]DiffFormat{"": DiffText, "text": DiffText, "markdown": DiffMarkdown, "json": DiffJSON} {
+		if got, err := ParseDiffFormat(name); err != nil || got != want {
+			t.Errorf("ParseDiffFormat(%q) = %q, %v; want %q", name, got, err, want)
+		}
+	}
+	if _, err := ParseDiffFormat("yaml"); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
+
+func TestNewDiff(t *testing.T) {
+	diff := NewDiff(diffBefore, diffAfter)
+
+	if len(diff.New) != 2 || diff.New[0].SourcePage != "https://example.com/about" || diff.New[1].URL != "https://down.example/" {
+		t.Errorf("New = %+v, want the link from a new page and the new dead host", diff.New)
+	}
+	if len(diff.Fixed) != 1 || diff.Fixed[0].URL != "https://example.com/fixed" {
+		t.Errorf("Fixed = %+v", diff.Fixed)
+	}
+	if len(diff.StillBroken) != 1 || diff.StillBroken[0].StatusCode != 410 {
+		t.Errorf("StillBroken = %+v, want the later crawl's details", diff.StillBroken)
+	}
+}
+
+func TestWriteDiff_Text(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteDiff(&buf, diffBefore, diffAfter, DiffText); err != nil {
+		t.Fatalf("WriteDiff() error: %v", err)
+	}
+	want := "Broken links: 2 new, 1 fixed, 1 still broken\n" +
+		"+ https://example.com/gone (410) on https://example.com/about\n" +
+		"+ https://down.example/ (dial tcp: connection refused) on https://example.com/\n" +
+		"- https://example.com/fixed (500) on https://example.com/\n" +
+		"= https://example.com/gone (410) on https://example.com/\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestWriteDiff_Markdown(t *testing.T) {
+	var buf bytes.Buffer
+	after := append(diffAfter, LinkResult{URL: "https://example.com/a|b", StatusCode: 404, SourcePage: "https://example.com/"})
+	if err := WriteDiff(&buf, diffBefore, after, DiffMarkdown); err != nil {
+		t.Fatalf("WriteDiff() error: %v", err)
+	}
+	got := buf.String()
+	for _, want := range []string{
+		"### Broken links: 3 new, 1 fixed, 1 still broken\n",
+		"**New (3)**\n",
+		"| https://example.com/gone | 410 | https://example.com/about |\n",
+		`| https://example.com/a\|b | 404 |`,
+		"<details><summary>Fixed (1)</summary>\n",
+		"<details><summary>Still broken (1)</summary>\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("markdown missing %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestMarkdownTable(t *testing.T) {
+	want := "| Link | Status | Found on |\n|---|---|---|\n" +
+		"| https://example.com/gone | 410 | https://example.com/ |\n" +
+		"| https://down.example/ | dial tcp: connection refused | https://example.com/ |\n"
+	if got := MarkdownTable([]LinkResult{diffAfter[0], diffAfter[2]}); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestWriteDiff_JSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteDiff(&buf, nil, nil, DiffJSON); err != nil {
+		t.Fatalf("WriteDiff() error: %v", err)
+	}
+	var decoded map[string][]LinkResult
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	for _, key := range []string{"new", "fixed", "still_broken"} {
+		if links, ok := decoded[key]; !ok || links == nil {
+			t.Errorf("%q should be an empty array, got %s", key, buf.String())
+		}
+	}
+}
+
+func TestReadJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteJSON(&buf, diffAfter); err != nil {
+		t.Fatalf("WriteJSON() error: %v", err)
+	}
+	links, err := ReadJSON(&buf)
+	if err != nil {
+		t.Fatalf("ReadJSON() error: %v", err)
+	}
+	if len(links) != len(diffAfter) || links[2].Error != diffAfter[2].Error {
+		t.Errorf("round trip = %+v", links)
+	}
+
+	sites, _ := json.Marshal([]SiteResult{
+		{Site: "https://a.example/", Result: &Result{BrokenLinks: diffBefore}},
+		{Site: "https://b.example/", Error: "unreachable"},
+	})
+	links, err = ReadJSON(bytes.NewReader(sites))
+	if err != nil {
+		t.Fatalf("ReadJSON(sites) error: %v", err)
+	}
+	if len(links) != len(diffBefore) {
+		t.Errorf("got %d links from site output, want %d", len(links), len(diffBefore))
+	}
+
+	if _, err := ReadJSON(strings.NewReader(`{"not": "an array"}`)); err == nil {
+		t.Error("expected an error for non-array input")
+	}
+}
@@ -0,0 +1,98 @@
+package result
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// Envelope wraps JSON output with metadata about the crawl that produced it,
+// so archived output is self-describing. BrokenLinks always lists every
+// broken link; a single-site crawl also fills StartURL and Stats, and a
+// multi-site crawl fills Sites with each site's full result.
+type Envelope struct {
+	Tool       string         `json:"tool"`             // Always "zombiecrawl"
+	Version    string         `json:"version"`          // Version of the tool that ran the crawl
+	RunID      string         `json:"run_id,omitempty"` // Shared by every site of a multi-site crawl
+	StartURL   string         `json:"start_url,omitempty"`
+	StartedAt  time.Time      `json:"started_at"`
+	FinishedAt time.Time      `json:"finished_at"`
+	Config     ConfigSnapshot `json:"config"` // Settings the crawl ran with
+
+	Stats       *CrawlStats  `json:"stats,omitempty"`
+	BrokenLinks []LinkResult `json:"broken_links"`
+	Sites       []SiteResult `json:"sites,omitempty"`
+}
+
+// ConfigSnapshot records the crawl settings that affect which links are
+// checked and how, for the output envelope.
+type ConfigSnapshot struct {
+	Concurrency     int           `json:"concurrency"`
+	MaxPerHost      int           `json:"max_per_host,omitempty"` // Requests in flight per host (0 = no cap)
+	RequestTimeout  time.Duration `json:"request_timeout"`
+	TimeoutOverride []string      `json:"timeout_overrides,omitempty"` // Per-pattern timeouts as "pattern=timeout"
+	QueueTimeout    time.Duration `json:"queue_timeout,omitempty"`
+	HardTimeout     time.Duration `json:"hard_timeout,omitempty"`
+	DialTimeout     time.Duration `json:"dial_timeout,omitempty"`
+	TLSTimeout      time.Duration `json:"tls_handshake_timeout,omitempty"`
+	HeaderTimeout   time.Duration `json:"response_header_timeout,omitempty"`
+	IPVersion       string        `json:"ip_version"`
+	DNSServer       string        `json:"dns_server,omitempty"`
+	MaxBandwidth    int64         `json:"max_bandwidth,omitempty"`     // Bytes per second
+	HostRetryBudget int           `json:"host_retry_budget,omitempty"` // Retries allowed per host over the crawl
+	Delay           int           `json:"delay_ms"`
+	RatePerMinute   float64       `json:"rate_per_minute,omitempty"`
+	UserAgent       string        `json:"user_agent"`
+	HostConfigs     []string      `json:"host_configs,omitempty"`     // Host patterns with overrides; their headers and credentials are omitted
+	BlockedHosts    []string      `json:"block_hosts,omitempty"`      // Host patterns never requested
+	Rewrites        []string      `json:"rewrites,omitempty"`         // Link rewrite rules as "find => replace"
+	ScrubParams     []string      `json:"scrub_params,omitempty"`     // Query parameters redacted from reported URLs
+	SyntheticChecks []string      `json:"synthetic_checks,omitempty"` // Names of declared checks; their headers and bodies are omitted
+	ExpectStatus    []string      `json:"expect_status,omitempty"`    // Status assertions as "pattern=status,status"
+	ContentChecks   []string      `json:"content_checks,omitempty"`   // Names of content checks
+	MaxDepth        int           `json:"max_depth"`
+	MaxLinksPerPage int           `json:"max_links_per_page,omitempty"`
+	Strategy        string        `json:"strategy"`
+	MaxRetries      int           `json:"max_retries"`
+	MaxRedirects    int           `json:"max_redirects"`
+	FollowRedirects string        `json:"follow_redirects"`
+	Sitemap         bool          `json:"sitemap"`
+	IgnoreRobots    bool          `json:"ignore_robots"`
+	ExternalRobots  bool          `json:"respect_external_robots"`
+	LocalRobots     bool          `json:"local_robots_txt,omitempty"` // robots.txt of the start host read from a file
+	Verify          bool          `json:"verify"`
+	LinkHygiene     bool          `json:"link_hygiene"`
+	StrictURLs      bool          `json:"strict_urls"`
+	Accessibility   bool          `json:"accessibility"`
+	SEO             bool          `json:"seo"`
+	SiteStructure   bool          `json:"site_structure"`
+	CheckHTTPS      bool          `json:"check_https"`
+	TrailingSlash   bool          `json:"check_trailing_slash,omitempty"`
+	FoldSchemes     bool          `json:"fold_schemes,omitempty"`
+	DedupQuery      bool          `json:"dedup_ignore_query,omitempty"`
+	DedupRules      []string      `json:"dedup_rules,omitempty"` // Dedup rules as "pattern=param,param"
+	StripSessions   bool          `json:"strip_session_ids,omitempty"`
+	Fragments       bool          `json:"check_fragments,omitempty"`
+	Feeds           bool          `json:"check_feeds,omitempty"`
+	PDFs            bool          `json:"check_pdfs,omitempty"`
+	Deterministic   bool          `json:"deterministic,omitempty"`
+}
+
+// ToolName identifies zombiecrawl output in envelopes.
+const ToolName = "zombiecrawl"
+
+// WriteEnvelope writes env as formatted JSON to w.
+func WriteEnvelope(w io.Writer, env Envelope) error {
+	env.Tool = ToolName
+	if env.BrokenLinks == nil {
+		env.BrokenLinks = []LinkResult{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(env); err != nil {
+		return fmt.Errorf("write json envelope: %w", err)
+	}
+	return nil
+}
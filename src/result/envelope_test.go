@@ -0,0 +1,61 @@
+package result
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteEnvelope(t *testing.T) {
+	started := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	var buf bytes.Buffer
+	err := WriteEnvelope(&buf, Envelope{
+		Version:    "v1.2.3",
+		StartURL:   "https://example.com/",
+		StartedAt:  started,
+		FinishedAt: started.Add(time.Minute),
+		Config:     ConfigSnapshot{Concurrency: 4, UserAgent: "bot"},
+		Stats:      &CrawlStats{TotalChecked: 9},
+	})
+	if err != nil {
+		t.Fatalf("WriteEnvelope() error: %v", err)
+	}
+
+	var decoded map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if decoded["tool"] != "zombiecrawl" || decoded["version"] != "v1.2.3" || decoded["started_at"] != "2026-03-01T12:00:00Z" {
+		t.Errorf("metadata missing: %s", buf.String())
+	}
+	if links, ok := decoded["broken_links"].([]any); !ok || len(links) != 0 {
+		t.Errorf("broken_links should be an empty array, got %v", decoded["broken_links"])
+	}
+	if _, ok := decoded["sites"]; ok {
+		t.Errorf("single-site envelope should omit sites: %s", buf.String())
+	}
+	if config := decoded["config"].(map[string]any); config["user_agent"] != "bot" {
+		t.Errorf("config = %v", config)
+	}
+}
+
+func TestReadJSON_Envelope(t *testing.T) {
+	var buf bytes.Buffer
+	links := []LinkResult{{URL: "https://example.com/gone", StatusCode: 404, SourcePage: "https://example.com/"}}
+	if err := WriteEnvelope(&buf, Envelope{BrokenLinks: links, Sites: []SiteResult{{Site: "https://example.com/", Result: &Result{BrokenLinks: links}}}}); err != nil {
+		t.Fatalf("WriteEnvelope() error: %v", err)
+	}
+	got, err := ReadJSON(&buf)
+	if err != nil {
+		t.Fatalf("ReadJSON() error: %v", err)
+	}
+	if len(got) != 1 || got[0].URL != links[0].URL {
+		t.Errorf("ReadJSON() = %+v, want the envelope's broken links once", got)
+	}
+
+	if _, err := ReadJSON(strings.NewReader(`{"broken_links": []}`)); err == nil {
+		t.Error("expected an error for an object that is not an envelope")
+	}
+}
@@ -3,26 +3,94 @@ package result
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"errors"
 	"net"
 	"strings"
+	"syscall"
 )
 
 // ErrorCategory represents the classification of a crawl error.
+// The string value is what appears in the error_type field of JSON and CSV output.
 type ErrorCategory string
 
 const (
 	CategoryTimeout           ErrorCategory = "timeout"
+	CategoryConnectTimeout    ErrorCategory = "connect_timeout" // Timed out connecting or during the TLS handshake
 	CategoryDNSFailure        ErrorCategory = "dns_failure"
 	CategoryConnectionRefused ErrorCategory = "connection_refused"
 	CategoryAuthRequired      ErrorCategory = "auth_required"
 	CategoryMalformedHTML     ErrorCategory = "malformed_html"
+	CategoryMalformedURL      ErrorCategory = "malformed_url" // Link rejected as suspicious in strict URL mode
 	Category4xx               ErrorCategory = "4xx"
 	Category5xx               ErrorCategory = "5xx"
 	CategoryRedirectLoop      ErrorCategory = "redirect_loop"
+	CategoryRedirectLimit     ErrorCategory = "redirect_limit"    // Redirect chain longer than the configured hop limit
+	CategoryTLS               ErrorCategory = "tls"               // Certificate invalid, expired, or handshake failure
+	Category429               ErrorCategory = "429"               // Too Many Requests (rate limited by the server)
+	CategoryTooLarge          ErrorCategory = "too_large"         // 413 Content Too Large or body over the size limit
+	CategoryRobotsBlocked     ErrorCategory = "robots_blocked"    // Disallowed by the host's robots.txt
+	CategoryBlockedHost       ErrorCategory = "blocked_host"      // Host matches a pattern that is never contacted
+	CategoryQueueTimeout      ErrorCategory = "queue_timeout"     // Not checked within the queue timeout
+	CategoryUnexpectedStatus  ErrorCategory = "unexpected_status" // Answered with a status the check or URL pattern does not expect
+	CategoryCorruptPDF        ErrorCategory = "corrupt_pdf"       // Served as application/pdf without a PDF header
 	CategoryUnknown           ErrorCategory = "unknown"
 )
 
+var (
+	// ErrRobotsBlocked indicates a URL was not requested because robots.txt disallows it.
+	ErrRobotsBlocked = errors.New("blocked by robots.txt")
+
+	// ErrBlockedHost indicates a URL was not requested because its host
+	// matches a blocked host pattern.
+	ErrBlockedHost = errors.New("blocked host")
+
+	// ErrContentTooLarge indicates a response body exceeded the configured size limit.
+	ErrContentTooLarge = errors.New("content too large")
+
+	// ErrTooManyRedirects indicates a redirect chain exceeded the configured hop limit.
+	ErrTooManyRedirects = errors.New("too many redirects")
+
+	// ErrQueuedTooLong indicates a URL was not requested because it waited in
+	// the crawl queue longer than the configured queue timeout.
+	ErrQueuedTooLong = errors.New("queued too long")
+)
+
+// IsTLSError reports whether err is a TLS handshake or certificate verification failure.
+func IsTLSError(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var certErr *tls.CertificateVerificationError
+	var unknownAuthErr x509.UnknownAuthorityError
+	var invalidErr x509.CertificateInvalidError
+	var hostnameErr x509.HostnameError
+	var recordErr tls.RecordHeaderError
+	var alertErr tls.AlertError
+
+	return errors.As(err, &certErr) ||
+		errors.As(err, &unknownAuthErr) ||
+		errors.As(err, &invalidErr) ||
+		errors.As(err, &hostnameErr) ||
+		errors.As(err, &recordErr) ||
+		errors.As(err, &alertErr)
+}
+
+// IsConnectTimeout reports whether err is a timeout while establishing the
+// connection (dialing or the TLS handshake), as opposed to a server that
+// accepted the connection but was slow to respond.
+func IsConnectTimeout(err error) bool {
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "dial" && opErr.Timeout() {
+		return true
+	}
+	// net/http does not export its handshake timeout error type
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout() && strings.Contains(err.Error(), "TLS handshake timeout")
+}
+
 // ClassifyError determines the error category based on the error, HTTP status code,
 // and whether a redirect loop was detected.
 func ClassifyError(err error, statusCode int, isRedirectLoop bool) ErrorCategory {
@@ -37,6 +105,12 @@ func ClassifyError(err error, statusCode int, isRedirectLoop bool) ErrorCategory
 		if statusCode == 401 || statusCode == 403 {
 			return CategoryAuthRequired
 		}
+		if statusCode == 429 {
+			return Category429
+		}
+		if statusCode == 413 {
+			return CategoryTooLarge
+		}
 		if statusCode >= 400 && statusCode <= 499 {
 			return Category4xx
 		}
@@ -50,6 +124,33 @@ func ClassifyError(err error, statusCode int, isRedirectLoop bool) ErrorCategory
 		return CategoryUnknown
 	}
 
+	// Check sentinel errors raised by the crawler itself
+	if errors.Is(err, ErrRobotsBlocked) {
+		return CategoryRobotsBlocked
+	}
+	if errors.Is(err, ErrBlockedHost) {
+		return CategoryBlockedHost
+	}
+	if errors.Is(err, ErrContentTooLarge) {
+		return CategoryTooLarge
+	}
+	if errors.Is(err, ErrTooManyRedirects) {
+		return CategoryRedirectLimit
+	}
+	if errors.Is(err, ErrQueuedTooLong) {
+		return CategoryQueueTimeout
+	}
+
+	// Check for TLS/certificate failures
+	if IsTLSError(err) {
+		return CategoryTLS
+	}
+
+	// Check for timeouts while connecting before other timeouts
+	if IsConnectTimeout(err) {
+		return CategoryConnectTimeout
+	}
+
 	// Check for timeout
 	if errors.Is(err, context.DeadlineExceeded) {
 		return CategoryTimeout
@@ -61,16 +162,15 @@ func ClassifyError(err error, statusCode int, isRedirectLoop bool) ErrorCategory
 		return CategoryDNSFailure
 	}
 
-	// Check for connection refused or other net operation errors
-	var opErr *net.OpError
-	if errors.As(err, &opErr) {
-		if opErr.Op == "dial" && strings.Contains(opErr.Error(), "connection refused") {
-			return CategoryConnectionRefused
-		}
-		// Check if it's a timeout via OpError
-		if opErr.Timeout() {
-			return CategoryTimeout
-		}
+	// Check for connection refused
+	if errors.Is(err, syscall.ECONNREFUSED) {
+		return CategoryConnectionRefused
+	}
+
+	// Check for timeouts reported via net.Error (net.OpError, http.Client.Timeout)
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return CategoryTimeout
 	}
 
 	// Fallback to unknown
@@ -82,6 +182,8 @@ func FormatCategory(cat ErrorCategory) string {
 	switch cat {
 	case CategoryTimeout:
 		return "Timeouts"
+	case CategoryConnectTimeout:
+		return "Connection Timeouts"
 	case CategoryDNSFailure:
 		return "DNS Failures"
 	case CategoryConnectionRefused:
@@ -90,13 +192,93 @@ func FormatCategory(cat ErrorCategory) string {
 		return "Requires Authentication"
 	case CategoryMalformedHTML:
 		return "Malformed HTML"
+	case CategoryMalformedURL:
+		return "Malformed URLs"
 	case Category4xx:
 		return "Client Errors (4xx)"
 	case Category5xx:
 		return "Server Errors (5xx)"
 	case CategoryRedirectLoop:
 		return "Redirect Loops"
+	case CategoryRedirectLimit:
+		return "Too Many Redirects"
+	case CategoryTLS:
+		return "TLS/Certificate Errors"
+	case Category429:
+		return "Rate Limited (429)"
+	case CategoryTooLarge:
+		return "Content Too Large"
+	case CategoryUnexpectedStatus:
+		return "Unexpected Statuses"
+	case CategoryCorruptPDF:
+		return "Corrupt or Masked PDFs"
+	case CategoryRobotsBlocked:
+		return "Blocked by robots.txt"
+	case CategoryQueueTimeout:
+		return "Queued Too Long"
+	case CategoryBlockedHost:
+		return "Blocked Hosts"
 	default:
 		return "Other Errors"
 	}
 }
+
+// FormatHygieneKind returns a human-readable label for a link hygiene problem.
+func FormatHygieneKind(kind HygieneKind) string {
+	switch kind {
+	case HygieneMixedContent:
+		return "Mixed content"
+	case HygieneProtocolRelative:
+		return "Protocol-relative URL"
+	case HygieneIPAddress:
+		return "IP address"
+	case HygieneHTTPSAvailable:
+		return "HTTPS available"
+	case HygieneHTTPSDowngrade:
+		return "HTTPS downgrade"
+	case HygieneSlashMismatch:
+		return "Trailing slash mismatch"
+	case HygieneSlashDuplicate:
+		return "Trailing slash duplicate"
+	case HygieneSchemeDuplicate:
+		return "HTTP/HTTPS duplicate"
+	case HygieneMissingFragment:
+		return "Missing fragment target"
+	default:
+		return string(kind)
+	}
+}
+
+// FormatSEOKind returns a human-readable label for an SEO problem.
+func FormatSEOKind(kind SEOKind) string {
+	switch kind {
+	case SEOMissingTitle:
+		return "Missing title"
+	case SEODuplicateTitle:
+		return "Duplicate title"
+	case SEOLongDescription:
+		return "Description too long"
+	case SEOHreflangBroken:
+		return "Broken hreflang alternate"
+	case SEOHreflangOneWay:
+		return "One-way hreflang alternate"
+	case SEOSocialBroken:
+		return "Broken social preview"
+	default:
+		return string(kind)
+	}
+}
+
+// FormatAccessibilityKind returns a human-readable label for an accessibility problem.
+func FormatAccessibilityKind(kind AccessibilityKind) string {
+	switch kind {
+	case AccessibilityEmptyAnchor:
+		return "Link without text"
+	case AccessibilityMissingAlt:
+		return "Image without alt text"
+	case AccessibilityURLText:
+		return "URL as link text"
+	default:
+		return string(kind)
+	}
+}
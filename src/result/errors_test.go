@@ -2,7 +2,12 @@ package result
 
 import (
 	"context"
+	"crypto/x509"
+	"fmt"
 	"net"
+	"net/url"
+	"os"
+	"syscall"
 	"testing"
 )
 
@@ -49,6 +54,62 @@ func TestClassifyError(t *testing.T) {
 			isRedirectLoop: false,
 			want:           Category5xx,
 		},
+		{
+			name:           "429 status is rate limited",
+			err:            nil,
+			statusCode:     429,
+			isRedirectLoop: false,
+			want:           Category429,
+		},
+		{
+			name:           "413 status is too large",
+			err:            nil,
+			statusCode:     413,
+			isRedirectLoop: false,
+			want:           CategoryTooLarge,
+		},
+		{
+			name:           "too many redirects is not a loop",
+			err:            fmt.Errorf("stopped after 10 redirects: %w", ErrTooManyRedirects),
+			statusCode:     0,
+			isRedirectLoop: false,
+			want:           CategoryRedirectLimit,
+		},
+		{
+			name:           "queued too long sentinel",
+			err:            fmt.Errorf("wait to check: %w", ErrQueuedTooLong),
+			statusCode:     0,
+			isRedirectLoop: false,
+			want:           CategoryQueueTimeout,
+		},
+		{
+			name:           "blocked host sentinel",
+			err:            fmt.Errorf("Get \"http://tracker.invalid/\": %w", ErrBlockedHost),
+			statusCode:     0,
+			isRedirectLoop: false,
+			want:           CategoryBlockedHost,
+		},
+		{
+			name:           "robots blocked sentinel",
+			err:            fmt.Errorf("check: %w", ErrRobotsBlocked),
+			statusCode:     0,
+			isRedirectLoop: false,
+			want:           CategoryRobotsBlocked,
+		},
+		{
+			name:           "content too large sentinel",
+			err:            ErrContentTooLarge,
+			statusCode:     0,
+			isRedirectLoop: false,
+			want:           CategoryTooLarge,
+		},
+		{
+			name:           "unknown authority is TLS",
+			err:            &url.Error{Op: "Get", URL: "https://x", Err: x509.UnknownAuthorityError{}},
+			statusCode:     0,
+			isRedirectLoop: false,
+			want:           CategoryTLS,
+		},
 		{
 			name:           "timeout error",
 			err:            context.DeadlineExceeded,
@@ -95,18 +156,65 @@ func TestClassifyError_DNSFailure(t *testing.T) {
 	}
 }
 
+func TestClassifyError_WrappedNetErrors(t *testing.T) {
+	refused := &url.Error{
+		Op:  "Get",
+		URL: "http://127.0.0.1:1",
+		Err: &net.OpError{Op: "dial", Net: "tcp", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)},
+	}
+	if got := ClassifyError(fmt.Errorf("fetch: %w", refused), 0, false); got != CategoryConnectionRefused {
+		t.Errorf("ClassifyError(wrapped ECONNREFUSED) = %v, want %v", got, CategoryConnectionRefused)
+	}
+
+	timeout := &url.Error{Op: "Get", URL: "http://example.com", Err: context.DeadlineExceeded}
+	if got := ClassifyError(fmt.Errorf("fetch: %w", timeout), 0, false); got != CategoryTimeout {
+		t.Errorf("ClassifyError(wrapped deadline) = %v, want %v", got, CategoryTimeout)
+	}
+}
+
+func TestClassifyError_ConnectTimeout(t *testing.T) {
+	dial := &url.Error{
+		Op:  "Get",
+		URL: "http://10.0.0.1",
+		Err: &net.OpError{Op: "dial", Net: "tcp", Err: os.ErrDeadlineExceeded},
+	}
+	if got := ClassifyError(dial, 0, false); got != CategoryConnectTimeout {
+		t.Errorf("ClassifyError(dial timeout) = %v, want %v", got, CategoryConnectTimeout)
+	}
+
+	read := &url.Error{
+		Op:  "Get",
+		URL: "http://example.com",
+		Err: &net.OpError{Op: "read", Net: "tcp", Err: os.ErrDeadlineExceeded},
+	}
+	if got := ClassifyError(read, 0, false); got != CategoryTimeout {
+		t.Errorf("ClassifyError(read timeout) = %v, want %v", got, CategoryTimeout)
+	}
+}
+
 func TestFormatCategory(t *testing.T) {
 	tests := []struct {
 		cat  ErrorCategory
 		want string
 	}{
 		{CategoryTimeout, "Timeouts"},
+		{CategoryConnectTimeout, "Connection Timeouts"},
 		{CategoryDNSFailure, "DNS Failures"},
 		{CategoryConnectionRefused, "Connection Refused"},
 		{CategoryAuthRequired, "Requires Authentication"},
+		{CategoryMalformedURL, "Malformed URLs"},
 		{Category4xx, "Client Errors (4xx)"},
 		{Category5xx, "Server Errors (5xx)"},
+		{CategoryUnexpectedStatus, "Unexpected Statuses"},
+		{CategoryCorruptPDF, "Corrupt or Masked PDFs"},
 		{CategoryRedirectLoop, "Redirect Loops"},
+		{CategoryRedirectLimit, "Too Many Redirects"},
+		{CategoryTLS, "TLS/Certificate Errors"},
+		{Category429, "Rate Limited (429)"},
+		{CategoryTooLarge, "Content Too Large"},
+		{CategoryRobotsBlocked, "Blocked by robots.txt"},
+		{CategoryQueueTimeout, "Queued Too Long"},
+		{CategoryBlockedHost, "Blocked Hosts"},
 		{CategoryUnknown, "Other Errors"},
 	}
 
@@ -27,21 +27,13 @@ func WriteCSV(w io.Writer, links []LinkResult) error {
 	cw := csv.NewWriter(w)
 
 	// Write header row
-	header := []string{"url", "status_code", "error_type", "source_page", "is_external"}
-	if err := cw.Write(header); err != nil {
+	if err := cw.Write(CSVHeader()); err != nil {
 		return fmt.Errorf("write csv header: %w", err)
 	}
 
 	// Write data rows
 	for _, link := range links {
-		record := []string{
-			link.URL,
-			statusCodeStr(link.StatusCode),
-			string(link.ErrorCategory),
-			link.SourcePage,
-			strconv.FormatBool(link.IsExternal),
-		}
-		if err := cw.Write(record); err != nil {
+		if err := cw.Write(CSVRecord(link)); err != nil {
 			return fmt.Errorf("write csv record for %s: %w", link.URL, err)
 		}
 	}
@@ -53,6 +45,22 @@ func WriteCSV(w io.Writer, links []LinkResult) error {
 	return nil
 }
 
+// CSVHeader returns the header row written by WriteCSV.
+func CSVHeader() []string {
+	return []string{"url", "status_code", "error_type", "source_page", "is_external"}
+}
+
+// CSVRecord returns the CSV row for link, in CSVHeader's column order.
+func CSVRecord(link LinkResult) []string {
+	return []string{
+		link.URL,
+		statusCodeStr(link.StatusCode),
+		string(link.ErrorCategory),
+		link.SourcePage,
+		strconv.FormatBool(link.IsExternal),
+	}
+}
+
 // statusCodeStr converts an HTTP status code to a string.
 // Returns empty string for 0 (no HTTP status).
 func statusCodeStr(code int) string {
@@ -66,6 +66,11 @@ func TestWriteJSON(t *testing.T) {
 		t.Error("Expected 'is_external' field in JSON output")
 	}
 
+	// Headers are omitted when not captured
+	if _, ok := raw[0]["headers"]; ok {
+		t.Error("Expected 'headers' field to be omitted when empty")
+	}
+
 	// Verify URLs are not HTML-escaped
 	if !strings.Contains(buf.String(), "https://example.com/broken") {
 		t.Error("URLs should not be HTML-escaped")
@@ -200,3 +205,27 @@ func TestStatusCodeStr(t *testing.T) {
 		}
 	}
 }
+
+func TestWriteJSON_Headers(t *testing.T) {
+	links := []LinkResult{
+		{
+			URL:           "https://example.com/blocked",
+			StatusCode:    403,
+			ErrorCategory: CategoryAuthRequired,
+			Headers:       map[string]string{"Server": "cloudflare", "Cf-Ray": "abc123"},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteJSON(&buf, links); err != nil {
+		t.Fatalf("WriteJSON returned error: %v", err)
+	}
+
+	var decoded []LinkResult
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("Output is not valid JSON: %v", err)
+	}
+	if decoded[0].Headers["Cf-Ray"] != "abc123" {
+		t.Errorf("Expected Cf-Ray header in JSON output, got %v", decoded[0].Headers)
+	}
+}
@@ -1,8 +1,10 @@
 package result
 
 import (
+	"cmp"
 	"fmt"
 	"io"
+	"strconv"
 )
 
 // PrintResults writes broken link details and a summary to w.
@@ -20,11 +22,310 @@ func PrintResults(w io.Writer, res *Result) {
 			} else {
 				writef("  Status: %d\n", link.StatusCode)
 			}
-			writef("  Found on: %s\n", link.SourcePage)
+			writef("  Found on: %s\n", FormatSource(link))
+			if link.ArchiveURL != "" {
+				writef("  Archived: %s\n", link.ArchiveURL)
+			}
+			if len(link.Attempts) > 1 {
+				writef("  Attempts:\n")
+				for n, attempt := range link.Attempts {
+					writef("    %d. %s\n", n+1, FormatAttempt(attempt))
+				}
+			}
 			if i < len(res.BrokenLinks)-1 {
 				writef("\n")
 			}
 		}
 	}
-	writef("Checked %d URLs, found %d broken links\n", res.Stats.TotalChecked, res.Stats.BrokenCount)
+	printFlaky(writef, res.Flaky)
+	printHygiene(writef, res.Hygiene)
+	printAccessibility(writef, res.Accessibility)
+	printStructure(writef, res.Structure)
+	printSEO(writef, res.SEO)
+	printFeeds(writef, res.Feeds)
+	printContentChecks(writef, res.ContentChecks)
+	printTruncated(writef, res.Truncated)
+	printSkipped(writef, res.Skipped)
+	printStreaming(writef, res.Streaming)
+	printBrokenHosts(writef, res.Hosts)
+	writef("Checked %d URLs, found %d broken links", res.Stats.TotalChecked, res.Stats.BrokenCount)
+	if res.Stats.FlakyCount > 0 {
+		writef(" (%d flaky)", res.Stats.FlakyCount)
+	}
+	writef("\n")
+	for _, line := range StatsDetails(res.Stats) {
+		writef("  %s\n", line)
+	}
+}
+
+// PrintSites writes one section per site of a multi-site crawl, each with
+// the same content PrintResults produces for a single site.
+func PrintSites(w io.Writer, sites []SiteResult) {
+	writef := func(format string, a ...any) { _, _ = fmt.Fprintf(w, format, a...) }
+
+	for i, site := range sites {
+		if i > 0 {
+			writef("\n")
+		}
+		writef("== %s ==\n", site.Site)
+		if site.Error != "" {
+			writef("Error: %s\n", site.Error)
+			continue
+		}
+		PrintResults(w, site.Result)
+	}
+}
+
+// PrintPlan writes a dry-run plan to w, grouped into internal, external, and
+// excluded URLs.
+func PrintPlan(w io.Writer, plan *Plan) {
+	writef := func(format string, a ...any) { _, _ = fmt.Fprintf(w, format, a...) }
+
+	writef("Dry run from %s\n", plan.Seed)
+
+	writef("\nInternal (%d):\n", len(plan.Internal))
+	for _, link := range plan.Internal {
+		writef("  %s\n", link)
+	}
+
+	writef("\nExternal (%d):\n", len(plan.External))
+	for _, link := range plan.External {
+		writef("  %s\n", link)
+	}
+
+	writef("\nExcluded (%d):\n", len(plan.Excluded))
+	for _, excluded := range plan.Excluded {
+		writef("  %s (%s)\n", excluded.URL, excluded.Reason)
+	}
+}
+
+// PrintComparison writes a comparison of two crawler identities to w.
+func PrintComparison(w io.Writer, cmp *Comparison) {
+	writef := func(format string, a ...any) { _, _ = fmt.Fprintf(w, format, a...) }
+
+	writef("Comparing %s and %s on %s\n", cmp.A, cmp.B, cmp.Site)
+	writef("Both reached %d URLs\n", cmp.Shared)
+	sections := []struct {
+		title string
+		urls  []string
+	}{
+		{"Only " + cmp.A + " reached", cmp.OnlyA},
+		{"Only " + cmp.B + " reached", cmp.OnlyB},
+		{"Blocked by robots.txt for " + cmp.A, cmp.BlockedA},
+		{"Blocked by robots.txt for " + cmp.B, cmp.BlockedB},
+	}
+	for _, section := range sections {
+		writef("\n%s (%d):\n", section.title, len(section.urls))
+		for _, u := range section.urls {
+			writef("  %s\n", u)
+		}
+	}
+}
+
+// printFlaky writes links that failed during the crawl but recovered when
+// re-verified, with the original failure.
+func printFlaky(writef func(format string, a ...any), flaky []LinkResult) {
+	if len(flaky) == 0 {
+		return
+	}
+	writef("\nFlaky links (recovered on re-check):\n")
+	for _, link := range flaky {
+		if link.Error != "" {
+			writef("  %s (%s)\n", link.URL, link.Error)
+		} else {
+			writef("  %s (status %d)\n", link.URL, link.StatusCode)
+		}
+	}
+	writef("\n")
+}
+
+// printHygiene writes the link hygiene warnings, one per line.
+func printHygiene(writef func(format string, a ...any), warnings []HygieneWarning) {
+	if len(warnings) == 0 {
+		return
+	}
+	writef("\nLink hygiene (%d warnings):\n", len(warnings))
+	for _, warning := range warnings {
+		writef("  %s: %s", FormatHygieneKind(warning.Kind), cmp.Or(warning.Href, warning.URL))
+		if warning.Target != "" {
+			writef(" -> %s", warning.Target)
+		}
+		writef(" (on %s)\n", warning.SourcePage)
+	}
+	writef("\n")
+}
+
+// printAccessibility writes the accessibility issues, one per line.
+func printAccessibility(writef func(format string, a ...any), issues []AccessibilityIssue) {
+	if len(issues) == 0 {
+		return
+	}
+	writef("\nAccessibility (%d issues):\n", len(issues))
+	for _, issue := range issues {
+		writef("  %s: %s (on %s)\n", FormatAccessibilityKind(issue.Kind), issue.Target, issue.SourcePage)
+	}
+	writef("\n")
+}
+
+// printStructure writes the orphan pages and the pages with too many links.
+func printStructure(writef func(format string, a ...any), structure *SiteStructure) {
+	if structure == nil || len(structure.Orphans)+len(structure.HeavyPages) == 0 {
+		return
+	}
+	if len(structure.Orphans) > 0 {
+		writef("\nOrphan pages, not linked from any crawled page (%d):\n", len(structure.Orphans))
+		for _, page := range structure.Orphans {
+			writef("  %s\n", page)
+		}
+	}
+	if len(structure.HeavyPages) > 0 {
+		writef("\nPages with more than %d links (%d):\n", structure.MaxOutboundLinks, len(structure.HeavyPages))
+		for _, page := range structure.HeavyPages {
+			writef("  %s: %d links\n", page.URL, page.Outbound)
+		}
+	}
+	writef("\n")
+}
+
+// printSEO writes the SEO issues, one per line.
+func printSEO(writef func(format string, a ...any), issues []SEOIssue) {
+	if len(issues) == 0 {
+		return
+	}
+	writef("\nSEO (%d issues):\n", len(issues))
+	for _, issue := range issues {
+		writef("  %s: %s", FormatSEOKind(issue.Kind), issue.URL)
+		if issue.Detail != "" {
+			writef(" (%s)", issue.Detail)
+		}
+		writef("\n")
+	}
+	writef("\n")
+}
+
+// printFeeds writes each feed checked, followed by its dead entries.
+func printFeeds(writef func(format string, a ...any), feeds []Feed) {
+	if len(feeds) == 0 {
+		return
+	}
+	dead := 0
+	for _, feed := range feeds {
+		dead += len(feed.DeadEntries)
+	}
+	writef("\nFeeds (%d feeds, %d dead entries):\n", len(feeds), dead)
+	for _, feed := range feeds {
+		writef("  %s (advertised on %s): ", feed.URL, feed.SourcePage)
+		if feed.Error != "" {
+			writef("%s\n", feed.Error)
+			continue
+		}
+		writef("%d entries, %d dead\n", feed.Entries, len(feed.DeadEntries))
+		for _, entry := range feed.DeadEntries {
+			if entry.Error != "" {
+				writef("    %s (%s)\n", entry.URL, entry.Error)
+			} else {
+				writef("    %s (status %d)\n", entry.URL, entry.StatusCode)
+			}
+		}
+	}
+	writef("\n")
+}
+
+// printContentChecks writes the content check failures, one per line.
+func printContentChecks(writef func(format string, a ...any), failures []ContentFailure) {
+	if len(failures) == 0 {
+		return
+	}
+	writef("\nContent checks (%d failures):\n", len(failures))
+	for _, failure := range failures {
+		writef("  %s: %s %s\n", failure.Check, failure.URL, failure.Reason)
+	}
+	writef("\n")
+}
+
+// printTruncated writes the pages cut off by the per-page link budget.
+func printTruncated(writef func(format string, a ...any), pages []TruncatedPage) {
+	if len(pages) == 0 {
+		return
+	}
+	writef("\nPages over the link budget, not fully crawled (%d):\n", len(pages))
+	for _, page := range pages {
+		writef("  %s: queued %d of %d links\n", page.URL, page.Queued, page.Links)
+	}
+	writef("\n")
+}
+
+// printSkipped writes the links that were never requested.
+func printSkipped(writef func(format string, a ...any), links []SkippedLink) {
+	if len(links) == 0 {
+		return
+	}
+	writef("\nSkipped, never requested (%d):\n", len(links))
+	for _, link := range links {
+		writef("  %s (found on %s): %s\n", link.URL, link.SourcePage, link.Reason)
+	}
+	writef("\n")
+}
+
+// printStreaming writes the streaming responses that were read in part.
+func printStreaming(writef func(format string, a ...any), resources []StreamingResource) {
+	if len(resources) == 0 {
+		return
+	}
+	writef("\nStreaming, read in part (%d):\n", len(resources))
+	for _, resource := range resources {
+		writef("  %s (found on %s): %s after %d bytes\n", resource.URL, resource.SourcePage, resource.Reason, resource.Bytes)
+	}
+	writef("\n")
+}
+
+// printBrokenHosts writes one line per external host with broken links.
+func printBrokenHosts(writef func(format string, a ...any), hosts []HostSummary) {
+	header := false
+	for _, host := range hosts {
+		if host.Broken == 0 {
+			continue
+		}
+		if !header {
+			writef("\nExternal hosts with broken links:\n")
+			header = true
+		}
+		writef("  %s: %d of %d links broken (%s)\n",
+			host.Host, host.Broken, host.Links, FormatCategory(host.DominantCategory))
+	}
+	if header {
+		writef("\n")
+	}
+}
+
+// FormatAttempt describes one request made for a link, e.g.
+// "12:00:01.250 status 503 (after 1s backoff)".
+func FormatAttempt(attempt Attempt) string {
+	outcome := fmt.Sprintf("status %d", attempt.StatusCode)
+	if attempt.Error != "" {
+		outcome = attempt.Error
+	}
+	line := fmt.Sprintf("%s %s", attempt.At.Format("15:04:05.000"), outcome)
+	if attempt.Backoff > 0 {
+		line += fmt.Sprintf(" (after %s backoff)", attempt.Backoff)
+	}
+	return line
+}
+
+// FormatStatus describes why a link is broken: its error, else its status
+// code.
+func FormatStatus(link LinkResult) string {
+	if link.Error != "" {
+		return link.Error
+	}
+	return strconv.Itoa(link.StatusCode)
+}
+
+// FormatSource returns the page a link was found on, followed by the page's
+// title if it is known.
+func FormatSource(link LinkResult) string {
+	if link.SourceTitle == "" {
+		return link.SourcePage
+	}
+	return fmt.Sprintf("%s (\"%s\")", link.SourcePage, link.SourceTitle)
 }
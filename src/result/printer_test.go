@@ -2,6 +2,7 @@ package result
 
 import (
 	"bytes"
+	"strings"
 	"testing"
 	"time"
 )
@@ -67,3 +68,385 @@ func TestPrintResults_WithBrokenLinks(t *testing.T) {
 		t.Error("missing or incorrect summary line")
 	}
 }
+
+func TestPrintPlan(t *testing.T) {
+	var buf bytes.Buffer
+	plan := &Plan{
+		Seed:     "http://example.com/",
+		Internal: []string{"http://example.com/about"},
+		External: []string{"https://other.com/"},
+		Excluded: []PlanExclusion{{URL: "http://example.com/admin", Reason: "blocked by robots.txt"}},
+	}
+
+	PrintPlan(&buf, plan)
+
+	got := buf.String()
+	for _, want := range []string{
+		"Dry run from http://example.com/",
+		"Internal (1):\n  http://example.com/about",
+		"External (1):\n  https://other.com/",
+		"Excluded (1):\n  http://example.com/admin (blocked by robots.txt)",
+	} {
+		if !bytes.Contains([]byte(got), []byte(want)) {
+			t.Errorf("output missing %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestPrintResults_BrokenHosts(t *testing.T) {
+	var buf bytes.Buffer
+	r := &Result{
+		BrokenLinks: []LinkResult{{URL: "https://cdn.old.com/x", StatusCode: 404, IsExternal: true}},
+		Hosts: []HostSummary{
+			{Host: "cdn.old.com", Links: 40, Broken: 1, DominantCategory: Category4xx},
+			{Host: "fine.com", Links: 2},
+		},
+		Stats: CrawlStats{TotalChecked: 42, BrokenCount: 1},
+	}
+
+	PrintResults(&buf, r)
+
+	got := buf.String()
+	if !bytes.Contains([]byte(got), []byte("cdn.old.com: 1 of 40 links broken (Client Errors (4xx))")) {
+		t.Errorf("missing host line:\n%s", got)
+	}
+	if bytes.Contains([]byte(got), []byte("fine.com")) {
+		t.Errorf("healthy host should be omitted:\n%s", got)
+	}
+}
+
+func TestPrintSites(t *testing.T) {
+	var buf bytes.Buffer
+	sites := []SiteResult{
+		{Site: "http://a.example/", Result: &Result{Stats: CrawlStats{TotalChecked: 3}}},
+		{Site: "http://b.example/", Error: "normalize start URL: bad"},
+	}
+
+	PrintSites(&buf, sites)
+
+	want := "== http://a.example/ ==\n" +
+		"No broken links found!\n" +
+		"Checked 3 URLs, found 0 broken links\n" +
+		"\n" +
+		"== http://b.example/ ==\n" +
+		"Error: normalize start URL: bad\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestPrintResults_Flaky(t *testing.T) {
+	var buf bytes.Buffer
+	r := &Result{
+		Flaky: []LinkResult{
+			{URL: "http://example.com/blip", StatusCode: 503},
+			{URL: "http://example.com/slow", Error: "timeout"},
+		},
+		Stats: CrawlStats{TotalChecked: 5, FlakyCount: 2},
+	}
+
+	PrintResults(&buf, r)
+
+	want := "No broken links found!\n" +
+		"\nFlaky links (recovered on re-check):\n" +
+		"  http://example.com/blip (status 503)\n" +
+		"  http://example.com/slow (timeout)\n" +
+		"\n" +
+		"Checked 5 URLs, found 0 broken links (2 flaky)\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestPrintResults_Hygiene(t *testing.T) {
+	var buf bytes.Buffer
+	r := &Result{
+		Hygiene: []HygieneWarning{
+			{Kind: HygieneProtocolRelative, URL: "https://cdn.example/x", Href: "//cdn.example/x", SourcePage: "https://example.com/"},
+			{Kind: HygieneIPAddress, URL: "http://10.0.0.1/", Href: "http://10.0.0.1/", SourcePage: "https://example.com/about"},
+			{Kind: HygieneHTTPSAvailable, URL: "http://example.com/old", Target: "https://example.com/old", SourcePage: "https://example.com/"},
+		},
+		Stats: CrawlStats{TotalChecked: 3},
+	}
+
+	PrintResults(&buf, r)
+
+	want := "No broken links found!\n" +
+		"\nLink hygiene (3 warnings):\n" +
+		"  Protocol-relative URL: //cdn.example/x (on https://example.com/)\n" +
+		"  IP address: http://10.0.0.1/ (on https://example.com/about)\n" +
+		"  HTTPS available: http://example.com/old -> https://example.com/old (on https://example.com/)\n" +
+		"\n" +
+		"Checked 3 URLs, found 0 broken links\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestPrintResults_Accessibility(t *testing.T) {
+	var buf bytes.Buffer
+	r := &Result{
+		Accessibility: []AccessibilityIssue{
+			{Kind: AccessibilityMissingAlt, Target: "https://example.com/logo.png", SourcePage: "https://example.com/"},
+		},
+		Stats: CrawlStats{TotalChecked: 1},
+	}
+
+	PrintResults(&buf, r)
+
+	want := "No broken links found!\n" +
+		"\nAccessibility (1 issues):\n" +
+		"  Image without alt text: https://example.com/logo.png (on https://example.com/)\n" +
+		"\n" +
+		"Checked 1 URLs, found 0 broken links\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestPrintResults_Structure(t *testing.T) {
+	var buf bytes.Buffer
+	r := &Result{
+		Structure: &SiteStructure{
+			Pages:            3,
+			Orphans:          []string{"https://example.com/lost"},
+			HeavyPages:       []PageLinks{{URL: "https://example.com/", Inbound: 2, Outbound: 150}},
+			MaxOutboundLinks: 100,
+		},
+		Stats: CrawlStats{TotalChecked: 3},
+	}
+
+	PrintResults(&buf, r)
+
+	want := "No broken links found!\n" +
+		"\nOrphan pages, not linked from any crawled page (1):\n" +
+		"  https://example.com/lost\n" +
+		"\nPages with more than 100 links (1):\n" +
+		"  https://example.com/: 150 links\n" +
+		"\n" +
+		"Checked 3 URLs, found 0 broken links\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestPrintResults_Truncated(t *testing.T) {
+	var buf bytes.Buffer
+	r := &Result{
+		Truncated: []TruncatedPage{{URL: "https://example.com/tags", Links: 50000, Queued: 1000}},
+		Stats:     CrawlStats{TotalChecked: 1001},
+	}
+
+	PrintResults(&buf, r)
+
+	want := "No broken links found!\n" +
+		"\nPages over the link budget, not fully crawled (1):\n" +
+		"  https://example.com/tags: queued 1000 of 50000 links\n" +
+		"\n" +
+		"Checked 1001 URLs, found 0 broken links\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestPrintResults_Skipped(t *testing.T) {
+	var buf bytes.Buffer
+	r := &Result{
+		Skipped: []SkippedLink{{URL: "https://stats.example.net/pixel.gif", SourcePage: "https://example.com/", Reason: `host matches blocked pattern "stats.*"`}},
+		Stats:   CrawlStats{TotalChecked: 1},
+	}
+
+	PrintResults(&buf, r)
+
+	want := "No broken links found!\n" +
+		"\nSkipped, never requested (1):\n" +
+		"  https://stats.example.net/pixel.gif (found on https://example.com/): host matches blocked pattern \"stats.*\"\n" +
+		"\n" +
+		"Checked 1 URLs, found 0 broken links\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestPrintResults_Streaming(t *testing.T) {
+	var buf bytes.Buffer
+	r := &Result{
+		Streaming: []StreamingResource{{URL: "https://example.com/live", SourcePage: "https://example.com/", Reason: "event stream"}},
+		Stats:     CrawlStats{TotalChecked: 2},
+	}
+
+	PrintResults(&buf, r)
+
+	want := "No broken links found!\n" +
+		"\nStreaming, read in part (1):\n" +
+		"  https://example.com/live (found on https://example.com/): event stream after 0 bytes\n" +
+		"\n" +
+		"Checked 2 URLs, found 0 broken links\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestPrintResults_SEO(t *testing.T) {
+	var buf bytes.Buffer
+	r := &Result{
+		SEO: []SEOIssue{
+			{Kind: SEOMissingTitle, URL: "https://example.com/untitled"},
+			{Kind: SEOLongDescription, URL: "https://example.com/", Detail: "200 characters, over 160"},
+		},
+		Stats: CrawlStats{TotalChecked: 2},
+	}
+
+	PrintResults(&buf, r)
+
+	want := "No broken links found!\n" +
+		"\nSEO (2 issues):\n" +
+		"  Missing title: https://example.com/untitled\n" +
+		"  Description too long: https://example.com/ (200 characters, over 160)\n" +
+		"\n" +
+		"Checked 2 URLs, found 0 broken links\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestPrintResults_Feeds(t *testing.T) {
+	var buf bytes.Buffer
+	r := &Result{
+		Feeds: []Feed{
+			{
+				URL:        "https://example.com/feed.xml",
+				SourcePage: "https://example.com/",
+				Entries:    3,
+				DeadEntries: []LinkResult{
+					{URL: "https://example.com/old-post", StatusCode: 404, SourcePage: "https://example.com/feed.xml"},
+					{URL: "https://gone.example/", Error: "dns lookup failed", SourcePage: "https://example.com/feed.xml"},
+				},
+			},
+			{URL: "https://example.com/atom.xml", SourcePage: "https://example.com/blog", Error: "fetch feed: status 500"},
+		},
+		Stats: CrawlStats{TotalChecked: 4},
+	}
+
+	PrintResults(&buf, r)
+
+	want := "No broken links found!\n" +
+		"\nFeeds (2 feeds, 2 dead entries):\n" +
+		"  https://example.com/feed.xml (advertised on https://example.com/): 3 entries, 2 dead\n" +
+		"    https://example.com/old-post (status 404)\n" +
+		"    https://gone.example/ (dns lookup failed)\n" +
+		"  https://example.com/atom.xml (advertised on https://example.com/blog): fetch feed: status 500\n" +
+		"\n" +
+		"Checked 4 URLs, found 0 broken links\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestPrintResults_Attempts(t *testing.T) {
+	var buf bytes.Buffer
+	start := time.Date(2026, 1, 2, 12, 0, 0, 0, time.UTC)
+	r := &Result{
+		BrokenLinks: []LinkResult{{
+			URL:        "https://example.com/slow",
+			Error:      "request timed out (after 2 attempts)",
+			SourcePage: "https://example.com/",
+			Attempts: []Attempt{
+				{At: start, StatusCode: 503},
+				{At: start.Add(1250 * time.Millisecond), Error: "request timed out", Backoff: time.Second},
+			},
+		}},
+		Stats: CrawlStats{TotalChecked: 2, BrokenCount: 1},
+	}
+
+	PrintResults(&buf, r)
+
+	want := "    1. 12:00:00.000 status 503\n" +
+		"    2. 12:00:01.250 request timed out (after 1s backoff)\n"
+	if got := buf.String(); !strings.Contains(got, "  Attempts:\n"+want) {
+		t.Errorf("got %q, want attempts %q", got, want)
+	}
+}
+
+func TestFormatSource(t *testing.T) {
+	if got := FormatSource(LinkResult{SourcePage: "https://example.com/"}); got != "https://example.com/" {
+		t.Errorf("FormatSource() without a title = %q", got)
+	}
+	if got, want := FormatSource(LinkResult{SourcePage: "https://example.com/", SourceTitle: "Home"}), `https://example.com/ ("Home")`; got != want {
+		t.Errorf("FormatSource() = %q, want %q", got, want)
+	}
+}
+
+func TestFormatStatus(t *testing.T) {
+	if got := FormatStatus(LinkResult{StatusCode: 404}); got != "404" {
+		t.Errorf("FormatStatus() without an error = %q, want 404", got)
+	}
+	if got := FormatStatus(LinkResult{StatusCode: 0, Error: "connection refused"}); got != "connection refused" {
+		t.Errorf("FormatStatus() = %q, want the error", got)
+	}
+}
+
+func TestPrintResults_ContentChecks(t *testing.T) {
+	var buf bytes.Buffer
+	r := &Result{
+		ContentChecks: []ContentFailure{{Check: "placeholder", URL: "https://example.com/about", Reason: `contains "Lorem ipsum"`}},
+		Stats:         CrawlStats{TotalChecked: 2},
+	}
+
+	PrintResults(&buf, r)
+
+	want := "No broken links found!\n" +
+		"\nContent checks (1 failures):\n" +
+		"  placeholder: https://example.com/about contains \"Lorem ipsum\"\n" +
+		"\n" +
+		"Checked 2 URLs, found 0 broken links\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestPrintResults_ArchiveURL(t *testing.T) {
+	var buf bytes.Buffer
+	r := &Result{
+		BrokenLinks: []LinkResult{{
+			URL:        "http://gone.example/page",
+			StatusCode: 404,
+			SourcePage: "http://example.com/",
+			IsExternal: true,
+			ArchiveURL: "http://web.archive.org/web/2020/http://gone.example/page",
+		}},
+		Stats: CrawlStats{TotalChecked: 2, BrokenCount: 1},
+	}
+
+	PrintResults(&buf, r)
+
+	if !strings.Contains(buf.String(), "  Found on: http://example.com/\n  Archived: http://web.archive.org/web/2020/http://gone.example/page\n") {
+		t.Errorf("missing archived suggestion:\n%s", buf.String())
+	}
+}
+
+func TestPrintComparison(t *testing.T) {
+	var buf bytes.Buffer
+	PrintComparison(&buf, &Comparison{
+		Site:     "http://example.com/",
+		A:        "googlebot",
+		B:        "browser",
+		Shared:   5,
+		OnlyB:    []string{"http://example.com/members"},
+		BlockedA: []string{"http://example.com/members"},
+	})
+
+	got := buf.String()
+	for _, want := range []string{
+		"Comparing googlebot and browser on http://example.com/\n",
+		"Both reached 5 URLs\n",
+		"Only googlebot reached (0):\n",
+		"Only browser reached (1):\n  http://example.com/members\n",
+		"Blocked by robots.txt for googlebot (1):\n  http://example.com/members\n",
+		"Blocked by robots.txt for browser (0):\n",
+	} {
+		if !bytes.Contains([]byte(got), []byte(want)) {
+			t.Errorf("missing %q in:\n%s", want, got)
+		}
+	}
+}
@@ -5,23 +5,281 @@ import "time"
 
 // LinkResult represents the result of checking a single link.
 type LinkResult struct {
-	URL           string        `json:"url"`                   // The URL that was checked
-	StatusCode    int           `json:"status_code,omitempty"` // HTTP status code (0 if unreachable)
-	Error         string        `json:"error,omitempty"`       // Error message if the check failed
-	ErrorCategory ErrorCategory `json:"error_type,omitempty"`  // Category classification of the error
-	SourcePage    string        `json:"source_page"`           // The page where this link was found
-	IsExternal    bool          `json:"is_external"`           // Whether this link points outside the crawled domain
+	URL           string        `json:"url"`                    // The URL that was checked
+	StatusCode    int           `json:"status_code,omitempty"`  // HTTP status code (0 if unreachable)
+	Error         string        `json:"error,omitempty"`        // Error message if the check failed
+	ErrorCategory ErrorCategory `json:"error_type,omitempty"`   // Category classification of the error
+	SourcePage    string        `json:"source_page"`            // The page where this link was found
+	SourceTitle   string        `json:"source_title,omitempty"` // The <title> of SourcePage, if known
+	IsExternal    bool          `json:"is_external"`            // Whether this link points outside the crawled domain
+
+	// ArchiveURL is the nearest Wayback Machine snapshot of a dead external
+	// link, suggested as a replacement (set with --suggest-archive).
+	ArchiveURL string `json:"archive_url,omitempty"`
+
+	// Headers holds selected response headers (Server, Content-Type, Location,
+	// Retry-After, CF-Ray) for broken links that returned an HTTP response.
+	Headers map[string]string `json:"headers,omitempty"`
+
+	// Attempts records each request made for the link during the crawl,
+	// oldest first, telling a steady 500 from a retry that then timed out.
+	Attempts []Attempt `json:"attempts,omitempty"`
+}
+
+// Attempt is one request made for a link.
+type Attempt struct {
+	At         time.Time     `json:"at"`                    // When the request started
+	StatusCode int           `json:"status_code,omitempty"` // HTTP status code (0 if unreachable)
+	Error      string        `json:"error,omitempty"`       // Why the request failed, if it did not fail with StatusCode alone
+	Backoff    time.Duration `json:"backoff,omitempty"`     // Wait before the request (0 for the first)
 }
 
 // CrawlStats contains aggregate statistics for a crawl operation.
 type CrawlStats struct {
 	TotalChecked int           `json:"total_checked"` // Total number of links checked
 	BrokenCount  int           `json:"broken_count"`  // Number of broken links found
+	FlakyCount   int           `json:"flaky_count"`   // Broken links that recovered on re-verification
 	Duration     time.Duration `json:"duration"`      // Total time taken for the crawl
+
+	InternalChecked   int                   `json:"internal_checked"`            // Same-domain URLs checked
+	ExternalChecked   int                   `json:"external_checked"`            // External URLs checked
+	ByCategory        map[ErrorCategory]int `json:"by_category,omitempty"`       // Broken link counts per error category
+	Retries           int                   `json:"retries"`                     // Extra requests made by retries
+	CacheHits         int                   `json:"cache_hits"`                  // External URLs answered from the external cache
+	NotModified       int                   `json:"not_modified"`                // Internal pages answered 304 Not Modified, reusing cached links
+	RobotsCacheHits   int64                 `json:"robots_cache_hits"`           // robots.txt lookups answered from memory
+	RobotsCacheMisses int64                 `json:"robots_cache_misses"`         // robots.txt lookups that required a fetch
+	BytesDownloaded   int64                 `json:"bytes_downloaded"`            // Response body bytes read
+	AvgLatency        time.Duration         `json:"avg_latency"`                 // Mean request latency
+	P50Latency        time.Duration         `json:"p50_latency"`                 // Median request latency
+	P95Latency        time.Duration         `json:"p95_latency"`                 // 95th percentile request latency
+	P99Latency        time.Duration         `json:"p99_latency"`                 // 99th percentile request latency
+	PagesPerSecond    float64               `json:"pages_per_second"`            // Overall throughput
+	PeakConcurrency   int                   `json:"peak_concurrency"`            // Most requests in flight at once
+	EventsDelivered   int64                 `json:"events_delivered"`            // Progress events handed to the consumer
+	EventsDropped     int64                 `json:"events_dropped"`              // Progress events dropped because the consumer fell behind
+	ByDepth           []DepthStats          `json:"by_depth,omitempty"`          // URLs checked and broken at each crawl depth, shallowest first
+	PaginationChains  int                   `json:"pagination_chains,omitempty"` // Sequences of pages joined by rel="next"/"prev" links
+	PaginatedPages    int                   `json:"paginated_pages,omitempty"`   // Pages in those sequences
+	DedupFolded       []DedupFold           `json:"dedup_folded,omitempty"`      // URLs not crawled as duplicates, by dedup rule
+}
+
+// DedupFold counts the URLs a dedup rule folded into one already crawled.
+type DedupFold struct {
+	Rule   string `json:"rule"`   // The rule as "pattern=param,param", or "all query strings"
+	Folded int    `json:"folded"` // Distinct URLs not crawled
+}
+
+// DepthStats counts the URLs checked at one crawl depth. The start page is
+// depth 0, the links on it depth 1, and so on.
+type DepthStats struct {
+	Depth   int `json:"depth"`
+	Checked int `json:"checked"` // Internal and external URLs checked at this depth
+	Broken  int `json:"broken"`  // Of those, how many were broken
+}
+
+// HostSummary aggregates link checks for a single external host.
+type HostSummary struct {
+	Host             string        `json:"host"`                        // Hostname (without port)
+	Links            int           `json:"links"`                       // External links checked on this host
+	Broken           int           `json:"broken"`                      // Broken links on this host
+	DominantCategory ErrorCategory `json:"dominant_category,omitempty"` // Most frequent error category among broken links
 }
 
 // Result represents the complete output of a broken link crawl.
 type Result struct {
-	BrokenLinks []LinkResult `json:"broken_links"` // All broken links discovered
-	Stats       CrawlStats   `json:"stats"`        // Aggregate statistics
+	RunID       string        `json:"run_id,omitempty"` // Identifies the crawl in logs, events, and stored runs
+	BrokenLinks []LinkResult  `json:"broken_links"`     // All broken links discovered
+	Flaky       []LinkResult  `json:"flaky,omitempty"`  // Links that failed but recovered on re-verification
+	Stats       CrawlStats    `json:"stats"`            // Aggregate statistics
+	Hosts       []HostSummary `json:"hosts,omitempty"`  // Per-host totals for external links
+
+	// Hygiene lists working links that are fragile or insecure, found by the
+	// opt-in link hygiene pass. They are not counted as broken.
+	Hygiene []HygieneWarning `json:"hygiene,omitempty"`
+
+	// Accessibility lists link and image markup problems found by the
+	// opt-in accessibility audit.
+	Accessibility []AccessibilityIssue `json:"accessibility,omitempty"`
+
+	// Structure summarizes the internal link graph (with the opt-in site
+	// structure report).
+	Structure *SiteStructure `json:"structure,omitempty"`
+
+	// SEO lists title and meta description problems found by the opt-in
+	// SEO report.
+	SEO []SEOIssue `json:"seo,omitempty"`
+
+	// Feeds lists the RSS and Atom feeds advertised by crawled pages, with
+	// their dead entries (with the opt-in feed check).
+	Feeds []Feed `json:"feeds,omitempty"`
+
+	// ContentChecks lists crawled pages that failed a content check.
+	ContentChecks []ContentFailure `json:"content_checks,omitempty"`
+
+	// Truncated lists pages that used up the crawl's per-page link budget
+	// with links left over.
+	Truncated []TruncatedPage `json:"truncated,omitempty"`
+
+	// Skipped lists links that were never requested because their host is
+	// blocked, the link classifier skipped them, or a plugin declined them.
+	Skipped []SkippedLink `json:"skipped,omitempty"`
+
+	// Streaming lists responses that kept sending, such as Server-Sent
+	// Events endpoints, which were read only in part. They are not broken.
+	Streaming []StreamingResource `json:"streaming,omitempty"`
+}
+
+// Feed is an RSS or Atom feed advertised by a crawled page. Error is set if
+// the feed could not be fetched or parsed; otherwise its entries' links were
+// checked.
+type Feed struct {
+	URL         string       `json:"url"`
+	SourcePage  string       `json:"source_page"`            // The first page advertising the feed
+	Entries     int          `json:"entries"`                // Entry links checked
+	DeadEntries []LinkResult `json:"dead_entries,omitempty"` // Entry links that are broken
+	Error       string       `json:"error,omitempty"`        // Why the feed itself failed
+}
+
+// ContentFailure is a crawled page that failed a content check.
+type ContentFailure struct {
+	Check  string `json:"check"`  // Name of the check
+	URL    string `json:"url"`    // The page
+	Reason string `json:"reason"` // What the page does wrong, e.g. `contains "Lorem ipsum"`
+}
+
+// TruncatedPage is a page whose links were cut off by the per-page link
+// budget. Links past the budget were neither checked nor followed from it.
+type TruncatedPage struct {
+	URL    string `json:"url"`
+	Links  int    `json:"links"`  // Distinct links found on the page
+	Queued int    `json:"queued"` // Links queued before the budget ran out
+}
+
+// StreamingResource is a response that streams without end, or longer than
+// the crawl reads a page for. Links on the part read were still followed.
+type StreamingResource struct {
+	URL        string `json:"url"`
+	SourcePage string `json:"source_page,omitempty"`
+	Reason     string `json:"reason"` // Why reading stopped, e.g. "event stream"
+	Bytes      int64  `json:"bytes"`  // Body bytes read before stopping
+}
+
+// SkippedLink is a link that was found but deliberately not requested, so
+// whether it works is unknown.
+type SkippedLink struct {
+	URL        string `json:"url"`
+	SourcePage string `json:"source_page"` // The first page linking to it
+	Reason     string `json:"reason"`      // Why it was skipped, e.g. `host matches blocked pattern "*.doubleclick.net"`
+}
+
+// SiteStructure summarizes how a site's internal pages link to each other.
+type SiteStructure struct {
+	Pages            int         `json:"pages"`              // Internal pages fetched successfully
+	Orphans          []string    `json:"orphans"`            // Pages no crawled page links to, reached only from a sitemap
+	HeavyPages       []PageLinks `json:"heavy_pages"`        // Pages with more than MaxOutboundLinks links, most first
+	MaxOutboundLinks int         `json:"max_outbound_links"` // Threshold for HeavyPages
+}
+
+// PageLinks is the link count of a page in both directions.
+type PageLinks struct {
+	URL      string `json:"url"`
+	Inbound  int    `json:"inbound"`  // Distinct crawled internal pages linking to it
+	Outbound int    `json:"outbound"` // Distinct links on the page, internal and external
+}
+
+// HygieneKind identifies a link hygiene problem.
+type HygieneKind string
+
+const (
+	HygieneMixedContent     HygieneKind = "mixed_content"     // http link on an https page
+	HygieneProtocolRelative HygieneKind = "protocol_relative" // href starting with "//", inheriting the page's scheme
+	HygieneIPAddress        HygieneKind = "ip_address"        // Link to a bare IP address instead of a host name
+	HygieneHTTPSAvailable   HygieneKind = "https_available"   // http page also served over https; the link should be updated
+	HygieneHTTPSDowngrade   HygieneKind = "https_downgrade"   // https link that redirects to http
+	HygieneSlashMismatch    HygieneKind = "slash_mismatch"    // Only one of /path and /path/ works, so a reported break may depend on the slash
+	HygieneSlashDuplicate   HygieneKind = "slash_duplicate"   // /path and /path/ are both served, neither redirecting to the other
+	HygieneSchemeDuplicate  HygieneKind = "scheme_duplicate"  // Page crawled over both http and https, neither redirecting to the other
+	HygieneMissingFragment  HygieneKind = "missing_fragment"  // Link to #id on the same page, which has no element with that id
+)
+
+// AccessibilityKind identifies an accessibility problem in page markup.
+type AccessibilityKind string
+
+const (
+	AccessibilityEmptyAnchor AccessibilityKind = "empty_anchor" // Link with no text, label, or image alt text
+	AccessibilityMissingAlt  AccessibilityKind = "missing_alt"  // Image without an alt attribute
+	AccessibilityURLText     AccessibilityKind = "url_text"     // Link whose visible text is a raw URL
+)
+
+// SEOKind identifies a problem with a page's title or meta description.
+type SEOKind string
+
+const (
+	SEOMissingTitle    SEOKind = "missing_title"    // Page without a <title>, or with an empty one
+	SEODuplicateTitle  SEOKind = "duplicate_title"  // Title shared with another crawled page
+	SEOLongDescription SEOKind = "long_description" // Meta description long enough to be truncated in search results
+	SEOHreflangBroken  SEOKind = "hreflang_broken"  // hreflang alternate that does not work
+	SEOHreflangOneWay  SEOKind = "hreflang_one_way" // hreflang alternate that does not list the page among its own alternates
+	SEOSocialBroken    SEOKind = "social_broken"    // og:image, og:url, or twitter:image URL that does not work
+)
+
+// SEOIssue is a crawled internal page whose title or meta description hurts
+// how it appears in search results.
+type SEOIssue struct {
+	Kind   SEOKind `json:"kind"`             // The problem found
+	URL    string  `json:"url"`              // The page
+	Detail string  `json:"detail,omitempty"` // The duplicated title, the description's length, or the hreflang alternate or social URL
+}
+
+// AccessibilityIssue is an element on a crawled page that is hard to use with
+// a screen reader.
+type AccessibilityIssue struct {
+	Kind       AccessibilityKind `json:"kind"`        // The problem found
+	Target     string            `json:"target"`      // The link's href or the image's src, resolved
+	SourcePage string            `json:"source_page"` // The page containing the element
+}
+
+// HygieneWarning is a link that works but is fragile or insecure.
+type HygieneWarning struct {
+	Kind       HygieneKind `json:"kind"`             // The problem found
+	URL        string      `json:"url"`              // The resolved link
+	Href       string      `json:"href,omitempty"`   // The href attribute as written in the page, if known
+	Target     string      `json:"target,omitempty"` // The https equivalent, the http URL a downgrade redirects to, or the URL with a trailing slash
+	SourcePage string      `json:"source_page"`      // The page where the link was found
+}
+
+// SiteResult is one site's section of a multi-site crawl. Exactly one of
+// Result and Error is set.
+type SiteResult struct {
+	Site   string  `json:"site"`             // The start URL for this site
+	Result *Result `json:"result,omitempty"` // Crawl result, if the crawl completed
+	Error  string  `json:"error,omitempty"`  // Why the crawl could not complete
+}
+
+// Comparison contrasts what two crawler identities reached on the same site.
+type Comparison struct {
+	Site     string   `json:"site"`      // The crawl's start URL
+	A        string   `json:"a"`         // Name of the first identity
+	B        string   `json:"b"`         // Name of the second identity
+	Shared   int      `json:"shared"`    // URLs both identities reached
+	OnlyA    []string `json:"only_a"`    // URLs only the first identity reached
+	OnlyB    []string `json:"only_b"`    // URLs only the second identity reached
+	BlockedA []string `json:"blocked_a"` // URLs robots.txt kept the first identity from
+	BlockedB []string `json:"blocked_b"` // URLs robots.txt kept the second identity from
+}
+
+// Plan lists the URLs a crawl would check from its seed page, as produced by
+// a dry run. No validation requests are made for the listed URLs.
+type Plan struct {
+	Seed     string          `json:"seed"`     // The seed page links were extracted from
+	Internal []string        `json:"internal"` // Same-domain pages that would be crawled
+	External []string        `json:"external"` // External links that would be validated
+	Excluded []PlanExclusion `json:"excluded"` // Links that would be skipped, with the reason
+}
+
+// PlanExclusion is a link a crawl would skip and why.
+type PlanExclusion struct {
+	URL    string `json:"url"`
+	Reason string `json:"reason"`
 }
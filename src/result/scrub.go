@@ -0,0 +1,245 @@
+package result
+
+import (
+	"context"
+	"log/slog"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Redacted replaces the value of a scrubbed query parameter.
+const Redacted = "REDACTED"
+
+// Scrubber redacts the values of named query parameters, such as token,
+// email, or session, wherever a URL appears in output: link URLs, source
+// pages, error messages, and log records. Parameter names match without
+// regard to case. A nil *Scrubber leaves everything unchanged.
+type Scrubber struct {
+	params  []string
+	pattern *regexp.Regexp
+}
+
+// NewScrubber returns a Scrubber redacting the query parameters named in
+// params, or nil if params is empty.
+func NewScrubber(params []string) *Scrubber {
+	quoted := make([]string, 0, len(params))
+	for _, param := range params {
+		if param = strings.TrimSpace(param); param != "" {
+			quoted = append(quoted, regexp.QuoteMeta(param))
+		}
+	}
+	if len(quoted) == 0 {
+		return nil
+	}
+	// A parameter starts after "?", "&", or ";" and its value runs to the
+	// next separator, fragment, or character that cannot end a URL in text
+	pattern := regexp.MustCompile(`(?i)[?&;](?:` + strings.Join(quoted, "|") + `)=[^&;#\s"'<>]*`)
+	return &Scrubber{params: params, pattern: pattern}
+}
+
+// Params returns the names of the redacted parameters.
+func (s *Scrubber) Params() []string {
+	if s == nil {
+		return nil
+	}
+	return s.params
+}
+
+// String returns text with the values of the scrubbed parameters of every
+// URL in it redacted.
+func (s *Scrubber) String(text string) string {
+	if s == nil || !strings.Contains(text, "=") {
+		return text
+	}
+	return s.pattern.ReplaceAllStringFunc(text, func(param string) string {
+		name, value, _ := strings.Cut(param, "=")
+		// Punctuation after a URL in a message is not part of the value
+		trimmed := strings.TrimRight(value, ".,:)")
+		return name + "=" + Redacted + value[len(trimmed):]
+	})
+}
+
+// each redacts each of values in place.
+func (s *Scrubber) each(values []string) {
+	for i := range values {
+		values[i] = s.String(values[i])
+	}
+}
+
+// Link returns a copy of link with its URLs redacted, including those in its
+// error message, response headers, and attempts. link is not modified.
+func (s *Scrubber) Link(link LinkResult) LinkResult {
+	if s == nil {
+		return link
+	}
+	link.URL = s.String(link.URL)
+	link.Error = s.String(link.Error)
+	link.SourcePage = s.String(link.SourcePage)
+	link.ArchiveURL = s.String(link.ArchiveURL)
+	if link.Headers != nil {
+		headers := make(map[string]string, len(link.Headers))
+		for name, value := range link.Headers {
+			headers[name] = s.String(value)
+		}
+		link.Headers = headers
+	}
+	link.Attempts = slices.Clone(link.Attempts)
+	for i := range link.Attempts {
+		link.Attempts[i].Error = s.String(link.Attempts[i].Error)
+	}
+	return link
+}
+
+// links redacts each of links in place.
+func (s *Scrubber) links(links []LinkResult) {
+	for i := range links {
+		links[i] = s.Link(links[i])
+	}
+}
+
+// Result redacts every URL in res in place.
+func (s *Scrubber) Result(res *Result) {
+	if s == nil || res == nil {
+		return
+	}
+	s.links(res.BrokenLinks)
+	s.links(res.Flaky)
+	for i := range res.Hygiene {
+		warning := &res.Hygiene[i]
+		warning.URL = s.String(warning.URL)
+		warning.Href = s.String(warning.Href)
+		warning.Target = s.String(warning.Target)
+		warning.SourcePage = s.String(warning.SourcePage)
+	}
+	for i := range res.Accessibility {
+		issue := &res.Accessibility[i]
+		issue.Target = s.String(issue.Target)
+		issue.SourcePage = s.String(issue.SourcePage)
+	}
+	if res.Structure != nil {
+		s.each(res.Structure.Orphans)
+		for i := range res.Structure.HeavyPages {
+			res.Structure.HeavyPages[i].URL = s.String(res.Structure.HeavyPages[i].URL)
+		}
+	}
+	for i := range res.SEO {
+		res.SEO[i].URL = s.String(res.SEO[i].URL)
+		res.SEO[i].Detail = s.String(res.SEO[i].Detail)
+	}
+	for i := range res.Feeds {
+		feed := &res.Feeds[i]
+		feed.URL = s.String(feed.URL)
+		feed.SourcePage = s.String(feed.SourcePage)
+		feed.Error = s.String(feed.Error)
+		s.links(feed.DeadEntries)
+	}
+	for i := range res.ContentChecks {
+		res.ContentChecks[i].URL = s.String(res.ContentChecks[i].URL)
+	}
+	for i := range res.Truncated {
+		res.Truncated[i].URL = s.String(res.Truncated[i].URL)
+	}
+	for i := range res.Skipped {
+		skipped := &res.Skipped[i]
+		skipped.URL = s.String(skipped.URL)
+		skipped.SourcePage = s.String(skipped.SourcePage)
+	}
+	for i := range res.Streaming {
+		streaming := &res.Streaming[i]
+		streaming.URL = s.String(streaming.URL)
+		streaming.SourcePage = s.String(streaming.SourcePage)
+	}
+}
+
+// Plan redacts every URL in plan in place.
+func (s *Scrubber) Plan(plan *Plan) {
+	if s == nil || plan == nil {
+		return
+	}
+	plan.Seed = s.String(plan.Seed)
+	s.each(plan.Internal)
+	s.each(plan.External)
+	for i := range plan.Excluded {
+		plan.Excluded[i].URL = s.String(plan.Excluded[i].URL)
+	}
+}
+
+// State redacts every URL in state in place.
+func (s *Scrubber) State(state *CrawlState) {
+	if s == nil || state == nil {
+		return
+	}
+	state.StartURL = s.String(state.StartURL)
+	for i := range state.Frontier {
+		state.Frontier[i].URL = s.String(state.Frontier[i].URL)
+		state.Frontier[i].SourcePage = s.String(state.Frontier[i].SourcePage)
+	}
+}
+
+// Handler returns a slog.Handler that redacts string attributes, including
+// those of groups, and messages before passing records to next. It returns
+// next itself for a nil *Scrubber.
+func (s *Scrubber) Handler(next slog.Handler) slog.Handler {
+	if s == nil {
+		return next
+	}
+	return &scrubHandler{next: next, scrubber: s}
+}
+
+// scrubHandler is the slog.Handler returned by Scrubber.Handler.
+type scrubHandler struct {
+	next     slog.Handler
+	scrubber *Scrubber
+}
+
+// Enabled implements slog.Handler.
+func (h *scrubHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+// Handle implements slog.Handler.
+func (h *scrubHandler) Handle(ctx context.Context, record slog.Record) error {
+	scrubbed := slog.NewRecord(record.Time, record.Level, h.scrubber.String(record.Message), record.PC)
+	record.Attrs(func(attr slog.Attr) bool {
+		scrubbed.AddAttrs(h.attr(attr))
+		return true
+	})
+	return h.next.Handle(ctx, scrubbed)
+}
+
+// WithAttrs implements slog.Handler.
+func (h *scrubHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	scrubbed := make([]slog.Attr, len(attrs))
+	for i, attr := range attrs {
+		scrubbed[i] = h.attr(attr)
+	}
+	return &scrubHandler{next: h.next.WithAttrs(scrubbed), scrubber: h.scrubber}
+}
+
+// WithGroup implements slog.Handler.
+func (h *scrubHandler) WithGroup(name string) slog.Handler {
+	return &scrubHandler{next: h.next.WithGroup(name), scrubber: h.scrubber}
+}
+
+// attr returns attr with its string values redacted. Other values, such as
+// errors, are redacted through their text.
+func (h *scrubHandler) attr(attr slog.Attr) slog.Attr {
+	value := attr.Value.Resolve()
+	switch value.Kind() {
+	case slog.KindString:
+		return slog.String(attr.Key, h.scrubber.String(value.String()))
+	case slog.KindGroup:
+		group := value.Group()
+		scrubbed := make([]any, len(group))
+		for i, member := range group {
+			scrubbed[i] = h.attr(member)
+		}
+		return slog.Group(attr.Key, scrubbed...)
+	case slog.KindAny:
+		if err, ok := value.Any().(error); ok {
+			return slog.String(attr.Key, h.scrubber.String(err.Error()))
+		}
+	}
+	return slog.Attr{Key: attr.Key, Value: value}
+}
@@ -0,0 +1,102 @@
+package result
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestScrubber_String(t *testing.T) {
+	s := NewScrubber([]string{"token", "Email", " "})
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"https://example.com/a?token=abc123&page=2", "https://example.com/a?token=REDACTED&page=2"},
+		{"https://example.com/a?page=2&EMAIL=a%40b.com#top", "https://example.com/a?page=2&EMAIL=REDACTED#top"},
+		{`fetch "https://example.com/?token=abc": status 404`, `fetch "https://example.com/?token=REDACTED": status 404`},
+		{"og:image -> https://cdn.example.com/i.png?token=x: status 404", "og:image -> https://cdn.example.com/i.png?token=REDACTED: status 404"},
+		{"https://example.com/?mytoken=abc&tokens=def", "https://example.com/?mytoken=abc&tokens=def"},
+		{"https://example.com/token=abc", "https://example.com/token=abc"},
+	}
+	for _, tt := range tests {
+		if got := s.String(tt.in); got != tt.want {
+			t.Errorf("String(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestScrubber_Nil(t *testing.T) {
+	if s := NewScrubber(nil); s != nil {
+		t.Fatalf("NewScrubber(nil) = %v, want nil", s)
+	}
+	var s *Scrubber
+	if got := s.String("https://example.com/?token=abc"); got != "https://example.com/?token=abc" {
+		t.Errorf("nil String() = %q, want input unchanged", got)
+	}
+	res := &Result{BrokenLinks: []LinkResult{{URL: "https://example.com/?token=abc"}}}
+	s.Result(res)
+	if res.BrokenLinks[0].URL != "https://example.com/?token=abc" {
+		t.Errorf("nil Result() changed %q", res.BrokenLinks[0].URL)
+	}
+}
+
+func TestScrubber_LinkLeavesOriginal(t *testing.T) {
+	s := NewScrubber([]string{"session"})
+	link := LinkResult{
+		URL:      "https://example.com/?session=1",
+		Headers:  map[string]string{"Location": "/login?session=1"},
+		Attempts: []Attempt{{Error: "Get \"https://example.com/?session=1\": EOF"}},
+	}
+	got := s.Link(link)
+	if got.URL != "https://example.com/?session=REDACTED" || got.Headers["Location"] != "/login?session=REDACTED" ||
+		got.Attempts[0].Error != `Get "https://example.com/?session=REDACTED": EOF` {
+		t.Errorf("Link() = %+v", got)
+	}
+	if link.Headers["Location"] != "/login?session=1" || link.Attempts[0].Error != `Get "https://example.com/?session=1": EOF` {
+		t.Errorf("Link() modified its argument: %+v", link)
+	}
+}
+
+func TestScrubber_Result(t *testing.T) {
+	s := NewScrubber([]string{"token"})
+	res := &Result{
+		BrokenLinks: []LinkResult{{URL: "https://example.com/dl?token=a", SourcePage: "https://example.com/?token=b"}},
+		SEO:         []SEOIssue{{Kind: SEOSocialBroken, URL: "https://example.com/?token=c", Detail: "og:image -> https://example.com/i?token=d: status 404"}},
+		Feeds:       []Feed{{URL: "https://example.com/feed?token=e", DeadEntries: []LinkResult{{URL: "https://example.com/post?token=f"}}}},
+		Structure:   &SiteStructure{Orphans: []string{"https://example.com/orphan?token=g"}},
+		Skipped:     []SkippedLink{{URL: "https://t.example.net/p?token=h", SourcePage: "https://example.com/?token=i"}},
+	}
+	s.Result(res)
+
+	var buf bytes.Buffer
+	PrintResults(&buf, res)
+	if out := buf.String(); strings.Contains(out, "token=") && !strings.Contains(out, "token=REDACTED") {
+		t.Fatalf("printed output has no redactions:\n%s", out)
+	}
+	for _, value := range []string{
+		res.BrokenLinks[0].URL, res.BrokenLinks[0].SourcePage, res.SEO[0].URL, res.SEO[0].Detail,
+		res.Feeds[0].URL, res.Feeds[0].DeadEntries[0].URL, res.Structure.Orphans[0], res.Skipped[0].URL, res.Skipped[0].SourcePage,
+	} {
+		if strings.Contains(value, "token=") && !strings.Contains(value, "token=REDACTED") {
+			t.Errorf("%q was not redacted", value)
+		}
+	}
+}
+
+func TestScrubber_Handler(t *testing.T) {
+	var buf bytes.Buffer
+	s := NewScrubber([]string{"token"})
+	logger := slog.New(s.Handler(slog.NewJSONHandler(&buf, nil))).With("url", "https://example.com/?token=a")
+	logger.Info("fetch https://example.com/?token=b failed",
+		"error", errors.New(`Get "https://example.com/?token=c": EOF`),
+		slog.Group("job", "source_page", "https://example.com/?token=d"),
+		"status", 500)
+
+	out := buf.String()
+	if strings.Count(out, "token=REDACTED") != 4 || strings.Contains(out, "token=a") || strings.Contains(out, `"status":500`) == false {
+		t.Errorf("log record = %s, want every token redacted and other attributes kept", out)
+	}
+}
@@ -0,0 +1,107 @@
+package result
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// SortKey selects the order SortLinks puts broken links in.
+type SortKey string
+
+const (
+	SortNone     SortKey = ""         // Crawl order
+	SortURL      SortKey = "url"      // By URL
+	SortStatus   SortKey = "status"   // By status code, failures without a response last
+	SortSource   SortKey = "source"   // By the page links were found on
+	SortCount    SortKey = "count"    // URLs broken on the most pages first
+	SortSeverity SortKey = "severity" // Most actionable error category first
+)
+
+// ParseSortKey converts a user-supplied sort name into a SortKey. An empty
+// name selects SortNone.
+func ParseSortKey(name string) (SortKey, error) {
+	switch key := SortKey(strings.ToLower(name)); key {
+	case SortNone, SortURL, SortStatus, SortSource, SortCount, SortSeverity:
+		return key, nil
+	default:
+		return "", fmt.Errorf("unknown sort key %q (want url, status, source, count, or severity)", name)
+	}
+}
+
+// severityOrder lists error categories from most to least actionable.
+var severityOrder = []ErrorCategory{
+	Category4xx,
+	Category5xx,
+	CategoryUnexpectedStatus,
+	CategoryCorruptPDF,
+	CategoryTLS,
+	CategoryTimeout,
+	CategoryConnectTimeout,
+	CategoryDNSFailure,
+	CategoryConnectionRefused,
+	CategoryRedirectLoop,
+	CategoryRedirectLimit,
+	Category429,
+	CategoryTooLarge,
+	CategoryMalformedHTML,
+	CategoryMalformedURL,
+	CategoryAuthRequired,
+	CategoryRobotsBlocked,
+	CategoryBlockedHost,
+	CategoryQueueTimeout,
+	CategoryUnknown,
+}
+
+// CategoriesBySeverity returns every error category, most actionable first.
+func CategoriesBySeverity() []ErrorCategory {
+	return slices.Clone(severityOrder)
+}
+
+// severity ranks category by severityOrder; unknown categories rank last.
+func severity(category ErrorCategory) int {
+	if i := slices.Index(severityOrder, cmp.Or(category, CategoryUnknown)); i >= 0 {
+		return i
+	}
+	return len(severityOrder)
+}
+
+// SortLinks sorts links in place by key, breaking ties by URL and then by
+// source page so output is the same across runs. SortNone leaves links in
+// crawl order.
+func SortLinks(links []LinkResult, key SortKey) {
+	if key == SortNone {
+		return
+	}
+	counts := make(map[string]int)
+	if key == SortCount {
+		for _, link := range links {
+			counts[link.URL]++
+		}
+	}
+	slices.SortStableFunc(links, func(a, b LinkResult) int {
+		var order int
+		switch key {
+		case SortStatus:
+			// Failures without a response have status 0; list them last
+			order = cmp.Or(cmp.Compare(noResponse(a), noResponse(b)),
+				cmp.Compare(a.StatusCode, b.StatusCode), cmp.Compare(a.Error, b.Error))
+		case SortSource:
+			order = cmp.Compare(a.SourcePage, b.SourcePage)
+		case SortCount:
+			order = cmp.Compare(counts[b.URL], counts[a.URL])
+		case SortSeverity:
+			order = cmp.Compare(severity(a.ErrorCategory), severity(b.ErrorCategory))
+		}
+		return cmp.Or(order, cmp.Compare(a.URL, b.URL), cmp.Compare(a.SourcePage, b.SourcePage))
+	})
+}
+
+// noResponse is 1 for a link that failed without a response, else 0.
+func noResponse(link LinkResult) int {
+	if link.StatusCode == 0 {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,48 @@
+package result
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseSortKey(t *testing.T) {
+	for name, want := range map[string]SortKey{"": SortNone, "url": SortURL, "Status": SortStatus, "count": SortCount, "severity": SortSeverity} {
+		if got, err := ParseSortKey(name); err != nil || got != want {
+			t.Errorf("ParseSortKey(%q) = %q, %v; want %q", name, got, err, want)
+		}
+	}
+	if _, err := ParseSortKey("size"); err == nil {
+		t.Error("expected an error for an unknown sort key")
+	}
+}
+
+func TestSortLinks(t *testing.T) {
+	links := []LinkResult{
+		{URL: "https://b.example/", Error: "dial tcp: connection refused", ErrorCategory: CategoryConnectionRefused, SourcePage: "https://example.com/a"},
+		{URL: "https://example.com/gone", StatusCode: 404, ErrorCategory: Category4xx, SourcePage: "https://example.com/c"},
+		{URL: "https://example.com/down", StatusCode: 503, ErrorCategory: Category5xx, SourcePage: "https://example.com/b"},
+		{URL: "https://example.com/gone", StatusCode: 404, ErrorCategory: Category4xx, SourcePage: "https://example.com/a"},
+	}
+	tests := []struct {
+		key  SortKey
+		want []string // URL and source page of each link
+	}{
+		{SortNone, []string{"https://b.example/ a", "https://example.com/gone c", "https://example.com/down b", "https://example.com/gone a"}},
+		{SortURL, []string{"https://b.example/ a", "https://example.com/down b", "https://example.com/gone a", "https://example.com/gone c"}},
+		{SortStatus, []string{"https://example.com/gone a", "https://example.com/gone c", "https://example.com/down b", "https://b.example/ a"}},
+		{SortSource, []string{"https://b.example/ a", "https://example.com/gone a", "https://example.com/down b", "https://example.com/gone c"}},
+		{SortCount, []string{"https://example.com/gone a", "https://example.com/gone c", "https://b.example/ a", "https://example.com/down b"}},
+		{SortSeverity, []string{"https://example.com/gone a", "https://example.com/gone c", "https://example.com/down b", "https://b.example/ a"}},
+	}
+	for _, tt := range tests {
+		sorted := slices.Clone(links)
+		SortLinks(sorted, tt.key)
+		got := make([]string, len(sorted))
+		for i, link := range sorted {
+			got[i] = link.URL + " " + link.SourcePage[len("https://example.com/"):]
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("SortLinks(%q) = %v, want %v", tt.key, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package result
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// SplitOptions configures WriteSplit.
+type SplitOptions struct {
+	CSV    bool // Write CSV files instead of JSON
+	ByHost bool // Also split each category by the host links point to, one directory per host
+}
+
+// WriteSplit writes links into dir as one file per error category, named
+// after the category (e.g. "4xx.json"), so teams owning different failure
+// classes can import only theirs. With opts.ByHost the files are further
+// split into one directory per host. It returns the paths written, sorted.
+func WriteSplit(dir string, links []LinkResult, opts SplitOptions) ([]string, error) {
+	ext, write := ".json", WriteJSON
+	if opts.CSV {
+		ext, write = ".csv", WriteCSV
+	}
+
+	groups := make(map[string][]LinkResult)
+	for _, link := range links {
+		name := string(cmp.Or(link.ErrorCategory, CategoryUnknown)) + ext
+		if opts.ByHost {
+			name = filepath.Join(splitHost(link.URL), name)
+		}
+		groups[name] = append(groups[name], link)
+	}
+
+	paths := make([]string, 0, len(groups))
+	for name, group := range groups {
+		path := filepath.Join(dir, name)
+		if err := writeSplitFile(path, group, write); err != nil {
+			return nil, err
+		}
+		paths = append(paths, path)
+	}
+	slices.Sort(paths)
+	return paths, nil
+}
+
+// writeSplitFile creates path, including missing directories, and writes
+// links to it.
+func writeSplitFile(path string, links []LinkResult, write func(io.Writer, []LinkResult) error) (err error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("create split output directory: %w", err)
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("create split output file: %w", err)
+	}
+	defer func() {
+		if closeErr := file.Close(); closeErr != nil && err == nil {
+			err = fmt.Errorf("close split output file: %w", closeErr)
+		}
+	}()
+	return write(file, links)
+}
+
+// splitHost returns a directory name for the host rawURL points to.
+func splitHost(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Hostname() == "" {
+		return "unknown-host"
+	}
+	// IPv6 literals contain colons, which some file systems reject.
+	return strings.ReplaceAll(strings.ToLower(u.Hostname()), ":", "_")
+}
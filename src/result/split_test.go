@@ -0,0 +1,67 @@
+package result
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+var splitLinks = []LinkResult{
+	{URL: "https://example.com/gone", StatusCode: 404, ErrorCategory: Category4xx, SourcePage: "https://example.com/"},
+	{URL: "https://other.example/x", StatusCode: 404, ErrorCategory: Category4xx, SourcePage: "https://example.com/", IsExternal: true},
+	{URL: "https://other.example/y", StatusCode: 503, ErrorCategory: Category5xx, SourcePage: "https://example.com/", IsExternal: true},
+	{URL: "http://[::1]:8080/", Error: "refused", SourcePage: "https://example.com/", IsExternal: true},
+}
+
+func TestWriteSplit_ByCategory(t *testing.T) {
+	dir := t.TempDir()
+	paths, err := WriteSplit(dir, splitLinks, SplitOptions{})
+	if err != nil {
+		t.Fatalf("WriteSplit() error: %v", err)
+	}
+	want := []string{filepath.Join(dir, "4xx.json"), filepath.Join(dir, "5xx.json"), filepath.Join(dir, "unknown.json")}
+	if !slices.Equal(paths, want) {
+		t.Errorf("paths = %v, want %v", paths, want)
+	}
+
+	file, err := os.Open(filepath.Join(dir, "4xx.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = file.Close() }()
+	links, err := ReadJSON(file)
+	if err != nil {
+		t.Fatalf("ReadJSON() error: %v", err)
+	}
+	if len(links) != 2 {
+		t.Errorf("4xx.json has %d links, want 2", len(links))
+	}
+}
+
+func TestWriteSplit_ByHostCSV(t *testing.T) {
+	dir := t.TempDir()
+	paths, err := WriteSplit(dir, splitLinks, SplitOptions{CSV: true, ByHost: true})
+	if err != nil {
+		t.Fatalf("WriteSplit() error: %v", err)
+	}
+	want := []string{
+		filepath.Join(dir, "__1", "unknown.csv"),
+		filepath.Join(dir, "example.com", "4xx.csv"),
+		filepath.Join(dir, "other.example", "4xx.csv"),
+		filepath.Join(dir, "other.example", "5xx.csv"),
+	}
+	if !slices.Equal(paths, want) {
+		t.Errorf("paths = %v, want %v", paths, want)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "other.example", "5xx.csv"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[1], "https://other.example/y,503,") {
+		t.Errorf("5xx.csv = %q, want a header and one row", data)
+	}
+}
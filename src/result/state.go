@@ -0,0 +1,86 @@
+package result
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// CrawlState is a snapshot of a crawl's queue, taken when the crawl stops,
+// for finding out why a crawl covered what it did.
+type CrawlState struct {
+	StartURL string      `json:"start_url"`
+	Strategy string      `json:"strategy"`
+	SavedAt  time.Time   `json:"saved_at"`
+	Visited  int         `json:"visited"`  // URLs queued so far, including those already checked
+	Checked  int         `json:"checked"`  // URLs checked so far
+	Frontier []QueuedURL `json:"frontier"` // URLs still queued, in the order they would be crawled
+}
+
+// QueuedURL is a URL waiting in the crawl queue.
+type QueuedURL struct {
+	URL        string `json:"url"`
+	SourcePage string `json:"source_page,omitempty"`
+	Depth      int    `json:"depth"`
+	IsExternal bool   `json:"is_external,omitempty"`
+}
+
+// DepthCounts returns how many queued URLs are at each depth, indexed by
+// depth.
+func (s *CrawlState) DepthCounts() []int {
+	var counts []int
+	for _, queued := range s.Frontier {
+		for len(counts) <= queued.Depth {
+			counts = append(counts, 0)
+		}
+		counts[queued.Depth]++
+	}
+	return counts
+}
+
+// ReadState decodes a CrawlState written as JSON.
+func ReadState(r io.Reader) (*CrawlState, error) {
+	var state CrawlState
+	if err := json.NewDecoder(r).Decode(&state); err != nil {
+		return nil, fmt.Errorf("decode crawl state: %w", err)
+	}
+	for _, queued := range state.Frontier {
+		if queued.Depth < 0 {
+			return nil, fmt.Errorf("decode crawl state: %s has negative depth %d", queued.URL, queued.Depth)
+		}
+	}
+	return &state, nil
+}
+
+// PrintState writes a summary of state to w: the queue size, visited and
+// checked counts, the queue by depth, and the next n queued URLs.
+func PrintState(w io.Writer, state *CrawlState, n int) {
+	writef := func(format string, a ...any) { _, _ = fmt.Fprintf(w, format, a...) }
+
+	writef("Crawl of %s saved %s\n", state.StartURL, state.SavedAt.Format(time.RFC3339))
+	writef("Strategy: %s\n", state.Strategy)
+	writef("Frontier: %d queued\n", len(state.Frontier))
+	writef("Visited:  %d (%d checked)\n", state.Visited, state.Checked)
+
+	writef("\nQueued by depth:\n")
+	for depth, count := range state.DepthCounts() {
+		if count > 0 {
+			writef("  %3d  %d\n", depth, count)
+		}
+	}
+
+	next := state.Frontier[:min(n, len(state.Frontier))]
+	writef("\nNext %d queued:\n", len(next))
+	for _, queued := range next {
+		kind := "internal"
+		if queued.IsExternal {
+			kind = "external"
+		}
+		writef("  %s (depth %d, %s", queued.URL, queued.Depth, kind)
+		if queued.SourcePage != "" {
+			writef(", from %s", queued.SourcePage)
+		}
+		writef(")\n")
+	}
+}
@@ -0,0 +1,72 @@
+package result
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestReadState(t *testing.T) {
+	state, err := ReadState(strings.NewReader(`{
+		"start_url": "https://example.com/",
+		"strategy": "bfs",
+		"saved_at": "2026-01-02T03:04:05Z",
+		"visited": 40,
+		"checked": 30,
+		"frontier": [
+			{"url": "https://example.com/a", "source_page": "https://example.com/", "depth": 1},
+			{"url": "https://other.example/", "depth": 1, "is_external": true},
+			{"url": "https://example.com/a/b", "depth": 3}
+		]
+	}`))
+	if err != nil {
+		t.Fatalf("ReadState() error: %v", err)
+	}
+	if state.Visited != 40 || state.Checked != 30 || len(state.Frontier) != 3 {
+		t.Errorf("ReadState() = %+v", state)
+	}
+	if got, want := state.DepthCounts(), []int{0, 2, 0, 1}; !slices.Equal(got, want) {
+		t.Errorf("DepthCounts() = %v, want %v", got, want)
+	}
+
+	for name, input := range map[string]string{
+		"not json":       `frontier`,
+		"negative depth": `{"frontier": [{"url": "https://example.com/", "depth": -1}]}`,
+	} {
+		if _, err := ReadState(strings.NewReader(input)); err == nil {
+			t.Errorf("%s: ReadState() error = nil, want an error", name)
+		}
+	}
+}
+
+func TestPrintState(t *testing.T) {
+	state := &CrawlState{
+		StartURL: "https://example.com/",
+		Strategy: "bfs",
+		Visited:  12,
+		Checked:  9,
+		Frontier: []QueuedURL{
+			{URL: "https://example.com/a", SourcePage: "https://example.com/", Depth: 1},
+			{URL: "https://other.example/", Depth: 1, IsExternal: true},
+			{URL: "https://example.com/a/b", Depth: 2},
+		},
+	}
+	var buf bytes.Buffer
+	PrintState(&buf, state, 2)
+	got := buf.String()
+
+	for _, want := range []string{
+		"Frontier: 3 queued\n",
+		"Visited:  12 (9 checked)\n",
+		"    1  2\n    2  1\n",
+		"Next 2 queued:\n  https://example.com/a (depth 1, internal, from https://example.com/)\n  https://other.example/ (depth 1, external)\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("PrintState() output missing %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "/a/b") {
+		t.Errorf("PrintState() listed more than 2 queued URLs:\n%s", got)
+	}
+}
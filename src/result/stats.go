@@ -0,0 +1,97 @@
+package result
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// StatsDetails returns human-readable lines describing the breakdown in
+// stats. It returns nil when no per-URL measurements were collected.
+func StatsDetails(stats CrawlStats) []string {
+	if stats.InternalChecked == 0 && stats.ExternalChecked == 0 {
+		return nil
+	}
+
+	lines := []string{
+		fmt.Sprintf("Internal: %d, External: %d, Retries: %d, Downloaded: %s",
+			stats.InternalChecked, stats.ExternalChecked, stats.Retries, FormatBytes(stats.BytesDownloaded)),
+		fmt.Sprintf("Latency: avg %s, p50 %s, p95 %s, p99 %s",
+			roundLatency(stats.AvgLatency), roundLatency(stats.P50Latency),
+			roundLatency(stats.P95Latency), roundLatency(stats.P99Latency)),
+		fmt.Sprintf("Throughput: %.1f URLs/s, peak concurrency %d", stats.PagesPerSecond, stats.PeakConcurrency),
+	}
+
+	if stats.CacheHits > 0 {
+		lines[0] += fmt.Sprintf(", Cache hits: %d", stats.CacheHits)
+	}
+	if stats.NotModified > 0 {
+		lines[0] += fmt.Sprintf(", Not modified: %d", stats.NotModified)
+	}
+
+	if stats.RobotsCacheHits+stats.RobotsCacheMisses > 0 {
+		lines = append(lines, fmt.Sprintf("Robots cache: %d hits, %d misses", stats.RobotsCacheHits, stats.RobotsCacheMisses))
+	}
+
+	if stats.EventsDropped > 0 {
+		lines = append(lines, fmt.Sprintf("Progress events: %d delivered, %d dropped", stats.EventsDelivered, stats.EventsDropped))
+	}
+
+	if len(stats.ByCategory) > 0 {
+		cats := make([]string, 0, len(stats.ByCategory))
+		for cat := range stats.ByCategory {
+			cats = append(cats, string(cat))
+		}
+		slices.Sort(cats)
+		parts := make([]string, 0, len(cats))
+		for _, cat := range cats {
+			parts = append(parts, fmt.Sprintf("%s=%d", cat, stats.ByCategory[ErrorCategory(cat)]))
+		}
+		lines = append(lines, "By category: "+strings.Join(parts, ", "))
+	}
+
+	if len(stats.ByDepth) > 0 {
+		parts := make([]string, 0, len(stats.ByDepth))
+		for _, level := range stats.ByDepth {
+			parts = append(parts, fmt.Sprintf("%d=%d/%d", level.Depth, level.Broken, level.Checked))
+		}
+		lines = append(lines, "By depth (broken/checked): "+strings.Join(parts, ", "))
+	}
+
+	if stats.PaginationChains > 0 {
+		lines = append(lines, fmt.Sprintf("Pagination: %d chains across %d pages", stats.PaginationChains, stats.PaginatedPages))
+	}
+
+	if len(stats.DedupFolded) > 0 {
+		parts := make([]string, 0, len(stats.DedupFolded))
+		for _, fold := range stats.DedupFolded {
+			parts = append(parts, fmt.Sprintf("%s=%d", fold.Rule, fold.Folded))
+		}
+		lines = append(lines, "Folded duplicates: "+strings.Join(parts, "; "))
+	}
+
+	return lines
+}
+
+// FormatBytes renders a byte count using binary units (KiB, MiB, ...).
+func FormatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// roundLatency rounds latencies to a readable precision.
+func roundLatency(d time.Duration) time.Duration {
+	if d >= time.Second {
+		return d.Round(10 * time.Millisecond)
+	}
+	return d.Round(100 * time.Microsecond)
+}
@@ -0,0 +1,108 @@
+package result
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStatsDetails_Empty(t *testing.T) {
+	if lines := StatsDetails(CrawlStats{TotalChecked: 3}); lines != nil {
+		t.Errorf("expected nil lines without breakdown, got %v", lines)
+	}
+}
+
+func TestStatsDetails(t *testing.T) {
+	stats := CrawlStats{
+		TotalChecked:    10,
+		InternalChecked: 7,
+		ExternalChecked: 3,
+		Retries:         2,
+		BytesDownloaded: 2048,
+		AvgLatency:      120 * time.Millisecond,
+		P95Latency:      300 * time.Millisecond,
+		PagesPerSecond:  4.5,
+		PeakConcurrency: 3,
+		ByCategory:      map[ErrorCategory]int{Category5xx: 1, Category4xx: 2},
+		ByDepth:         []DepthStats{{Depth: 0, Checked: 1}, {Depth: 1, Checked: 9, Broken: 3}},
+	}
+
+	got := strings.Join(StatsDetails(stats), "\n")
+	for _, want := range []string{
+		"Internal: 7, External: 3, Retries: 2, Downloaded: 2.0 KiB",
+		"avg 120ms",
+		"p95 300ms",
+		"4.5 URLs/s, peak concurrency 3",
+		"By category: 4xx=2, 5xx=1",
+		"By depth (broken/checked): 0=0/1, 1=3/9",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("StatsDetails missing %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestStatsDetails_CacheHits(t *testing.T) {
+	lines := StatsDetails(CrawlStats{ExternalChecked: 4, CacheHits: 3})
+	if len(lines) == 0 || !strings.HasSuffix(lines[0], ", Cache hits: 3") {
+		t.Errorf("expected cache hits on the first line, got %v", lines)
+	}
+	if lines := StatsDetails(CrawlStats{ExternalChecked: 4}); strings.Contains(lines[0], "Cache") {
+		t.Errorf("cache hits shown without any: %v", lines)
+	}
+}
+
+func TestStatsDetails_NotModified(t *testing.T) {
+	lines := StatsDetails(CrawlStats{InternalChecked: 5, NotModified: 4})
+	if len(lines) == 0 || !strings.HasSuffix(lines[0], ", Not modified: 4") {
+		t.Errorf("expected not modified count on the first line, got %v", lines)
+	}
+}
+
+func TestStatsDetails_DedupFolded(t *testing.T) {
+	lines := StatsDetails(CrawlStats{InternalChecked: 2, DedupFolded: []DedupFold{
+		{Rule: "/blog/*=print", Folded: 3},
+		{Rule: "all query strings", Folded: 1},
+	}})
+	if !slices.Contains(lines, "Folded duplicates: /blog/*=print=3; all query strings=1") {
+		t.Errorf("expected folded duplicates line, got %v", lines)
+	}
+}
+
+func TestStatsDetails_DroppedEvents(t *testing.T) {
+	lines := StatsDetails(CrawlStats{InternalChecked: 1, EventsDelivered: 90, EventsDropped: 10})
+	if !slices.Contains(lines, "Progress events: 90 delivered, 10 dropped") {
+		t.Errorf("expected dropped events line, got %v", lines)
+	}
+	for _, line := range StatsDetails(CrawlStats{InternalChecked: 1, EventsDelivered: 90}) {
+		if strings.HasPrefix(line, "Progress events") {
+			t.Errorf("events line shown without drops: %q", line)
+		}
+	}
+}
+
+func TestStatsDetails_Pagination(t *testing.T) {
+	lines := StatsDetails(CrawlStats{InternalChecked: 12, PaginationChains: 2, PaginatedPages: 9})
+	if !slices.Contains(lines, "Pagination: 2 chains across 9 pages") {
+		t.Errorf("expected pagination line, got %v", lines)
+	}
+}
+
+func TestFormatBytes(t *testing.T) {
+	tests := []struct {
+		n    int64
+		want string
+	}{
+		{0, "0 B"},
+		{1023, "1023 B"},
+		{1024, "1.0 KiB"},
+		{1536, "1.5 KiB"},
+		{5 * 1024 * 1024, "5.0 MiB"},
+	}
+	for _, tt := range tests {
+		if got := FormatBytes(tt.n); got != tt.want {
+			t.Errorf("FormatBytes(%d) = %q, want %q", tt.n, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package result
+
+import (
+	"cmp"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/template"
+)
+
+// TemplateData is what a --template output template is executed with.
+type TemplateData struct {
+	Site   string  // The crawl's start URL
+	Result *Result // The complete result
+
+	BrokenLinks []LinkResult // Shorthand for Result.BrokenLinks
+	Internal    []LinkResult // Broken links on the crawled site
+	External    []LinkResult // Broken links to other sites
+	Stats       CrawlStats   // Shorthand for Result.Stats
+
+	ByCategory []LinkGroup // Broken links grouped by error category, largest group first
+	ByPage     []LinkGroup // Broken links grouped by the page they were found on
+	ByHost     []LinkGroup // Broken links grouped by the host they point to
+}
+
+// LinkGroup is a set of broken links sharing a key, such as an error category.
+type LinkGroup struct {
+	Key   string       // The grouping value, e.g. "4xx" or a page URL
+	Label string       // Human-readable form of Key
+	Links []LinkResult // Links in the group, in crawl order
+}
+
+// templateFuncs are available to output templates in addition to the
+// text/template builtins.
+var templateFuncs = template.FuncMap{
+	"category": FormatCategory,
+	"hygiene":  FormatHygieneKind,
+	"attempt":  FormatAttempt,
+	"status":   FormatStatus,
+	"join":     strings.Join,
+	"upper":    strings.ToUpper,
+	"lower":    strings.ToLower,
+	"csv":      csvRecord,
+	"json":     jsonValue,
+}
+
+// ParseTemplateFile parses an output template from path. Templates can use
+// the functions category, hygiene, attempt, status, join, upper, lower, csv,
+// and json.
+func ParseTemplateFile(path string) (*template.Template, error) {
+	tmpl, err := template.New(filepath.Base(path)).Funcs(templateFuncs).ParseFiles(path)
+	if err != nil {
+		return nil, fmt.Errorf("parse template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// NewTemplateData builds the template view of the result of crawling site.
+func NewTemplateData(site string, res *Result) TemplateData {
+	data := TemplateData{
+		Site:        site,
+		Result:      res,
+		BrokenLinks: res.BrokenLinks,
+		Stats:       res.Stats,
+	}
+	for _, link := range res.BrokenLinks {
+		if link.IsExternal {
+			data.External = append(data.External, link)
+		} else {
+			data.Internal = append(data.Internal, link)
+		}
+	}
+
+	data.ByCategory = groupLinks(res.BrokenLinks, func(link LinkResult) (string, string) {
+		cat := cmp.Or(link.ErrorCategory, CategoryUnknown)
+		return string(cat), FormatCategory(cat)
+	})
+	slices.SortStableFunc(data.ByCategory, func(a, b LinkGroup) int { return len(b.Links) - len(a.Links) })
+	data.ByPage = groupLinks(res.BrokenLinks, func(link LinkResult) (string, string) {
+		return link.SourcePage, FormatSource(link)
+	})
+	data.ByHost = groupLinks(res.BrokenLinks, func(link LinkResult) (string, string) {
+		host := link.URL
+		if u, err := url.Parse(link.URL); err == nil {
+			host = u.Hostname()
+		}
+		return host, host
+	})
+	return data
+}
+
+// groupLinks groups links by the key returned for each, keeping groups in
+// order of first appearance.
+func groupLinks(links []LinkResult, key func(LinkResult) (key, label string)) []LinkGroup {
+	var groups []LinkGroup
+	index := make(map[string]int)
+	for _, link := range links {
+		k, label := key(link)
+		i, ok := index[k]
+		if !ok {
+			i = len(groups)
+			index[k] = i
+			groups = append(groups, LinkGroup{Key: k, Label: label})
+		}
+		groups[i].Links = append(groups[i].Links, link)
+	}
+	return groups
+}
+
+// WriteTemplate executes tmpl with the result of crawling site and writes
+// the output to w.
+func WriteTemplate(w io.Writer, tmpl *template.Template, site string, res *Result) error {
+	if err := tmpl.Execute(w, NewTemplateData(site, res)); err != nil {
+		return fmt.Errorf("execute template: %w", err)
+	}
+	return nil
+}
+
+// csvRecord formats fields as one CSV record without the trailing newline.
+func csvRecord(fields ...any) (string, error) {
+	record := make([]string, len(fields))
+	for i, field := range fields {
+		record[i] = fmt.Sprint(field)
+	}
+	var b strings.Builder
+	cw := csv.NewWriter(&b)
+	if err := cw.Write(record); err != nil {
+		return "", err
+	}
+	cw.Flush()
+	return strings.TrimSuffix(b.String(), "\n"), cw.Error()
+}
+
+// jsonValue encodes v as compact JSON.
+func jsonValue(v any) (string, error) {
+	var b strings.Builder
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(b.String(), "\n"), nil
+}
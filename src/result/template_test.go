@@ -0,0 +1,105 @@
+package result
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+var templateResult = &Result{
+	BrokenLinks: []LinkResult{
+		{URL: "https://example.com/gone", StatusCode: 404, ErrorCategory: Category4xx, SourcePage: "https://example.com/"},
+		{URL: "https://other.example/a,b", StatusCode: 404, ErrorCategory: Category4xx, SourcePage: "https://example.com/about", IsExternal: true},
+		{URL: "https://down.example/", Error: "timeout", ErrorCategory: CategoryTimeout, SourcePage: "https://example.com/", IsExternal: true},
+	},
+	Stats: CrawlStats{TotalChecked: 12, BrokenCount: 3},
+}
+
+func TestNewTemplateData(t *testing.T) {
+	data := NewTemplateData("https://example.com/", templateResult)
+
+	if len(data.Internal) != 1 || len(data.External) != 2 {
+		t.Errorf("internal/external = %d/%d, want 1/2", len(data.Internal), len(data.External))
+	}
+	if len(data.ByCategory) != 2 || data.ByCategory[0].Key != "4xx" || data.ByCategory[0].Label != "Client Errors (4xx)" {
+		t.Errorf("ByCategory = %+v, want 4xx first", data.ByCategory)
+	}
+	if len(data.ByPage) != 2 || len(data.ByPage[0].Links) != 2 {
+		t.Errorf("ByPage = %+v", data.ByPage)
+	}
+	if len(data.ByHost) != 3 || data.ByHost[1].Key != "other.example" {
+		t.Errorf("ByHost = %+v", data.ByHost)
+	}
+}
+
+func TestWriteTemplate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "report.tmpl")
+	source := `{{.Site}}: {{.Stats.BrokenCount}} of {{.Stats.TotalChecked}}
+{{range .ByCategory}}{{.Label}}
+{{range .Links}}{{csv .URL (status .) .SourcePage}}
+{{end}}{{end}}{{with index .BrokenLinks 0}}{{category .ErrorCategory | upper}} {{json .URL}}{{end}}
+`
+	if err := os.WriteFile(path, []byte(source), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	tmpl, err := ParseTemplateFile(path)
+	if err != nil {
+		t.Fatalf("ParseTemplateFile() error: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteTemplate(&buf, tmpl, "https://example.com/", templateResult); err != nil {
+		t.Fatalf("WriteTemplate() error: %v", err)
+	}
+	want := "https://example.com/: 3 of 12\n" +
+		"Client Errors (4xx)\n" +
+		"https://example.com/gone,404,https://example.com/\n" +
+		"\"https://other.example/a,b\",404,https://example.com/about\n" +
+		"Timeouts\n" +
+		"https://down.example/,timeout,https://example.com/\n" +
+		"CLIENT ERRORS (4XX) \"https://example.com/gone\"\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestWriteTemplate_Attempts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "attempts.tmpl")
+	source := `{{range .BrokenLinks}}{{range .Attempts}}<li>{{attempt .}}</li>{{end}}{{end}}`
+	if err := os.WriteFile(path, []byte(source), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	tmpl, err := ParseTemplateFile(path)
+	if err != nil {
+		t.Fatalf("ParseTemplateFile() error: %v", err)
+	}
+
+	start := time.Date(2026, 1, 2, 12, 0, 0, 0, time.UTC)
+	res := &Result{BrokenLinks: []LinkResult{{
+		URL:      "https://example.com/flaky",
+		Attempts: []Attempt{{At: start, StatusCode: 500}, {At: start.Add(2 * time.Second), StatusCode: 500, Backoff: 2 * time.Second}},
+	}}}
+	var buf bytes.Buffer
+	if err := WriteTemplate(&buf, tmpl, "https://example.com/", res); err != nil {
+		t.Fatalf("WriteTemplate() error: %v", err)
+	}
+	want := "<li>12:00:00.000 status 500</li><li>12:00:02.000 status 500 (after 2s backoff)</li>"
+	if got := buf.String(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestParseTemplateFile_Errors(t *testing.T) {
+	if _, err := ParseTemplateFile(filepath.Join(t.TempDir(), "missing.tmpl")); err == nil {
+		t.Error("expected an error for a missing file")
+	}
+	path := filepath.Join(t.TempDir(), "bad.tmpl")
+	if err := os.WriteFile(path, []byte("{{.Site"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ParseTemplateFile(path); err == nil {
+		t.Error("expected an error for a malformed template")
+	}
+}
@@ -0,0 +1,581 @@
+// Package server runs crawls in the background for daemon mode and exposes
+// them over a REST API: start and stop crawls, query progress, fetch results,
+// adjust rate limits at runtime, and stream progress events over a WebSocket.
+// An embedded dashboard at / drives the same API from a browser.
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/websocket"
+
+	"github.com/lukemcguire/zombiecrawl/crawler"
+	"github.com/lukemcguire/zombiecrawl/history"
+	"github.com/lukemcguire/zombiecrawl/result"
+)
+
+// Status is the lifecycle state of a crawl.
+type Status string
+
+// Crawl states.
+const (
+	StatusRunning   Status = "running"
+	StatusDone      Status = "done"
+	StatusCancelled Status = "cancelled"
+	StatusFailed    Status = "failed"
+)
+
+// subscriberBuffer is how many events a slow WebSocket client may fall behind
+// before further events are dropped for it.
+const subscriberBuffer = 64
+
+// maxFinishedCrawls is how many finished crawls are kept for status and
+// result queries; the oldest beyond it are forgotten when a crawl starts.
+const maxFinishedCrawls = 100
+
+// StartRequest is the body of POST /crawls. Zero fields keep the server's
+// base configuration.
+type StartRequest struct {
+	URL           string  `json:"url"`
+	Concurrency   int     `json:"concurrency,omitempty"`
+	MaxDepth      int     `json:"max_depth,omitempty"`
+	RatePerMinute float64 `json:"rate_per_minute,omitempty"`
+}
+
+// RateRequest is the body of PUT /crawls/{id}/rate.
+type RateRequest struct {
+	RequestsPerSecond float64 `json:"requests_per_second"`
+}
+
+// CrawlStatus describes a crawl in API responses.
+type CrawlStatus struct {
+	ID         string     `json:"id"`
+	RunID      string     `json:"run_id"` // Identifies the crawl in logs, events, and the run database
+	URL        string     `json:"url"`
+	Status     Status     `json:"status"`
+	StartedAt  time.Time  `json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+	Checked    int        `json:"checked"`
+	Broken     int        `json:"broken"`
+	Rate       float64    `json:"requests_per_second"`
+	Error      string     `json:"error,omitempty"`
+}
+
+// Server manages background crawls. Create one with New and serve it with
+// an http.Server; call Close to stop every crawl on shutdown.
+type Server struct {
+	base    func(startURL string) crawler.Config
+	mux     *http.ServeMux
+	mu      sync.Mutex
+	crawls  map[string]*crawl
+	nextID  int
+	history string
+	listen  string // Host of the listen address, also accepted in Host headers
+	anyIP   bool   // Listening on every interface, so any IP address is a valid Host
+}
+
+// crawl is one background crawl and its subscribers.
+type crawl struct {
+	id        string
+	url       string
+	startedAt time.Time
+	crawler   *crawler.Crawler
+	cancel    context.CancelFunc
+	done      chan struct{}
+
+	mu         sync.Mutex
+	status     Status
+	finishedAt time.Time
+	checked    int
+	broken     int
+	result     *result.Result
+	err        error
+	subs       map[chan crawler.CrawlEvent]struct{}
+}
+
+// New creates a server whose crawls start from base(url), e.g.
+// crawler.DefaultConfig.
+func New(base func(startURL string) crawler.Config) *Server {
+	s := &Server{
+		base:   base,
+		mux:    http.NewServeMux(),
+		crawls: make(map[string]*crawl),
+	}
+	s.mux.HandleFunc("POST /crawls", s.handleStart)
+	s.mux.HandleFunc("GET /crawls", s.handleList)
+	s.mux.HandleFunc("GET /crawls/{id}", s.handleStatus)
+	s.mux.HandleFunc("DELETE /crawls/{id}", s.handleStop)
+	s.mux.HandleFunc("GET /crawls/{id}/results", s.handleResults)
+	s.mux.HandleFunc("PUT /crawls/{id}/rate", s.handleRate)
+	s.mux.HandleFunc("GET /crawls/{id}/events", s.handleEvents)
+	s.mux.HandleFunc("GET /{$}", s.handleIndex)
+	s.mux.Handle("GET /ui/", uiHandler())
+	s.mux.HandleFunc("GET /history", s.handleHistory)
+	return s
+}
+
+// SetHistory records every crawl that completes to the run database at path
+// and serves its trend report at /history. An empty path turns both off.
+func (s *Server) SetHistory(path string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.history = path
+}
+
+// SetAddr tells the server the address it listens on, so requests naming
+// that host are accepted along with loopback names. Listening on every
+// interface (an empty host, 0.0.0.0, or ::) accepts any IP address as the
+// host, but still no other name.
+func (s *Server) SetAddr(addr string) {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		host = addr
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.listen = host
+	ip := net.ParseIP(host)
+	s.anyIP = host == "" || (ip != nil && ip.IsUnspecified())
+}
+
+// ServeHTTP implements http.Handler. Every request must name a loopback
+// host or the listen address in its Host header, so a page that rebinds
+// its own domain to this machine cannot reach the API. Requests that change
+// state must not come from another origin, and their bodies must be JSON,
+// so web pages the user visits cannot start crawls of their network: a
+// cross-origin page can only send such a request after a CORS preflight,
+// which the server never allows.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !s.allowedHost(r.Host) {
+		writeError(w, http.StatusForbidden, fmt.Errorf("request for host %q refused", r.Host))
+		return
+	}
+	switch r.Method {
+	case http.MethodPost, http.MethodPut, http.MethodDelete:
+		if !sameOrigin(r) {
+			writeError(w, http.StatusForbidden, fmt.Errorf("cross-origin request from %s refused", r.Header.Get("Origin")))
+			return
+		}
+		if r.ContentLength == 0 {
+			// No body to decode, e.g. DELETE /crawls/{id}
+			break
+		}
+		if mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type")); err != nil || mediaType != "application/json" {
+			writeError(w, http.StatusUnsupportedMediaType, errors.New("request Content-Type must be application/json"))
+			return
+		}
+	}
+	s.mux.ServeHTTP(w, r)
+}
+
+// allowedHost reports whether hostport, a request's Host header, names
+// localhost, a loopback address, or the listen address.
+func (s *Server) allowedHost(hostport string) bool {
+	host, _, err := net.SplitHostPort(hostport)
+	if err != nil {
+		host = strings.TrimSuffix(strings.TrimPrefix(hostport, "["), "]")
+	}
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	if ip != nil && ip.IsLoopback() {
+		return true
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return (s.listen != "" && strings.EqualFold(host, s.listen)) || (s.anyIP && ip != nil)
+}
+
+// sameOrigin reports whether r has no Origin header, as non-browser clients
+// send none, or one naming the host the request was sent to.
+func sameOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	parsed, err := url.Parse(origin)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+		return false
+	}
+	return strings.EqualFold(parsed.Host, r.Host)
+}
+
+// Start begins a crawl in the background and returns its ID.
+func (s *Server) Start(req StartRequest) (string, error) {
+	parsed, err := url.Parse(req.URL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return "", fmt.Errorf("invalid url %q: must be an absolute http or https URL", req.URL)
+	}
+
+	cfg := s.base(req.URL)
+	if req.Concurrency > 0 {
+		cfg.Concurrency = req.Concurrency
+	}
+	if req.MaxDepth > 0 {
+		cfg.MaxDepth = req.MaxDepth
+	}
+	if req.RatePerMinute > 0 {
+		cfg.RatePerMinute = req.RatePerMinute
+	}
+
+	progressCh := make(chan crawler.CrawlEvent, 100)
+	c, err := crawler.New(cfg, progressCh)
+	if err != nil {
+		return "", fmt.Errorf("create crawler: %w", err)
+	}
+
+	s.mu.Lock()
+	s.nextID++
+	id := strconv.Itoa(s.nextID)
+	ctx, cancel := context.WithCancel(context.Background())
+	run := &crawl{
+		id:        id,
+		url:       req.URL,
+		startedAt: time.Now().UTC(),
+		crawler:   c,
+		cancel:    cancel,
+		done:      make(chan struct{}),
+		status:    StatusRunning,
+		subs:      make(map[chan crawler.CrawlEvent]struct{}),
+	}
+	s.crawls[id] = run
+	s.pruneLocked()
+	s.mu.Unlock()
+
+	pumped := make(chan struct{})
+	go func() {
+		defer close(pumped)
+		for evt := range progressCh {
+			run.publish(evt)
+		}
+	}()
+	go func() {
+		res, runErr := c.Run(ctx)
+		close(progressCh)
+		<-pumped
+		if runErr == nil && ctx.Err() == nil {
+			runErr = s.record(run, res)
+		}
+		run.finish(ctx.Err() != nil, res, runErr)
+		cancel()
+	}()
+	return id, nil
+}
+
+// pruneLocked forgets the oldest finished crawls beyond maxFinishedCrawls.
+// Running crawls are always kept. Must be called with s.mu held.
+func (s *Server) pruneLocked() {
+	var finished []*crawl
+	for _, run := range s.crawls {
+		if run.finished() {
+			finished = append(finished, run)
+		}
+	}
+	if len(finished) <= maxFinishedCrawls {
+		return
+	}
+	slices.SortFunc(finished, func(a, b *crawl) int { return a.startedAt.Compare(b.startedAt) })
+	for _, run := range finished[:len(finished)-maxFinishedCrawls] {
+		delete(s.crawls, run.id)
+	}
+}
+
+// record appends a completed crawl to the run database, if one is set.
+func (s *Server) record(run *crawl, res *result.Result) error {
+	s.mu.Lock()
+	path := s.history
+	s.mu.Unlock()
+	if path == "" || res == nil {
+		return nil
+	}
+	if err := history.Append(path, history.NewRun(run.url, run.startedAt, res)); err != nil {
+		return fmt.Errorf("record run: %w", err)
+	}
+	return nil
+}
+
+// Close stops every running crawl and waits for them to finish.
+func (s *Server) Close() {
+	s.mu.Lock()
+	crawls := make([]*crawl, 0, len(s.crawls))
+	for _, run := range s.crawls {
+		crawls = append(crawls, run)
+	}
+	s.mu.Unlock()
+	for _, run := range crawls {
+		run.cancel()
+		<-run.done
+	}
+}
+
+// lookup returns the crawl named by the request's {id}, writing a 404 if it
+// does not exist.
+func (s *Server) lookup(w http.ResponseWriter, r *http.Request) (*crawl, bool) {
+	s.mu.Lock()
+	run, ok := s.crawls[r.PathValue("id")]
+	s.mu.Unlock()
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Errorf("crawl %q not found", r.PathValue("id")))
+	}
+	return run, ok
+}
+
+func (s *Server) handleStart(w http.ResponseWriter, r *http.Request) {
+	var req StartRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("decode request: %w", err))
+		return
+	}
+	id, err := s.Start(req)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	s.mu.Lock()
+	run := s.crawls[id]
+	s.mu.Unlock()
+	w.Header().Set("Location", "/crawls/"+id)
+	writeJSON(w, http.StatusCreated, run.snapshot())
+}
+
+func (s *Server) handleList(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	statuses := make([]CrawlStatus, 0, len(s.crawls))
+	for _, run := range s.crawls {
+		statuses = append(statuses, run.snapshot())
+	}
+	s.mu.Unlock()
+	slices.SortFunc(statuses, func(a, b CrawlStatus) int { return a.StartedAt.Compare(b.StartedAt) })
+	writeJSON(w, http.StatusOK, statuses)
+}
+
+func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
+	if run, ok := s.lookup(w, r); ok {
+		writeJSON(w, http.StatusOK, run.snapshot())
+	}
+}
+
+func (s *Server) handleStop(w http.ResponseWriter, r *http.Request) {
+	run, ok := s.lookup(w, r)
+	if !ok {
+		return
+	}
+	run.cancel()
+	<-run.done
+	writeJSON(w, http.StatusOK, run.snapshot())
+}
+
+func (s *Server) handleResults(w http.ResponseWriter, r *http.Request) {
+	run, ok := s.lookup(w, r)
+	if !ok {
+		return
+	}
+	run.mu.Lock()
+	status, res := run.status, run.result
+	run.mu.Unlock()
+	switch {
+	case status == StatusRunning:
+		writeError(w, http.StatusConflict, errors.New("crawl is still running"))
+	case res == nil:
+		writeError(w, http.StatusNotFound, errors.New("crawl produced no results"))
+	default:
+		writeJSON(w, http.StatusOK, res)
+	}
+}
+
+func (s *Server) handleRate(w http.ResponseWriter, r *http.Request) {
+	run, ok := s.lookup(w, r)
+	if !ok {
+		return
+	}
+	var req RateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("decode request: %w", err))
+		return
+	}
+	if req.RequestsPerSecond <= 0 {
+		writeError(w, http.StatusBadRequest, errors.New("requests_per_second must be positive"))
+		return
+	}
+	run.crawler.SetRate(req.RequestsPerSecond)
+	writeJSON(w, http.StatusOK, run.snapshot())
+}
+
+// handleEvents streams the crawl's progress events as JSON WebSocket
+// messages, then its final status once it finishes. Browsers may only
+// connect from the server's own origin; non-browser clients may connect
+// without an Origin header.
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	run, ok := s.lookup(w, r)
+	if !ok {
+		return
+	}
+	stream := websocket.Server{
+		Handshake: func(_ *websocket.Config, r *http.Request) error {
+			if !sameOrigin(r) {
+				return fmt.Errorf("cross-origin connection from %s refused", r.Header.Get("Origin"))
+			}
+			return nil
+		},
+		Handler: func(ws *websocket.Conn) {
+			defer ws.Close()
+			events := run.subscribe()
+			defer run.unsubscribe(events)
+
+			// Reading only detects the client going away
+			gone := make(chan struct{})
+			go func() {
+				_, _ = io.Copy(io.Discard, ws)
+				close(gone)
+			}()
+
+			for {
+				select {
+				case evt, open := <-events:
+					if !open {
+						_ = websocket.JSON.Send(ws, run.snapshot())
+						return
+					}
+					if err := websocket.JSON.Send(ws, evt); err != nil {
+						return
+					}
+				case <-gone:
+					return
+				}
+			}
+		},
+	}
+	stream.ServeHTTP(w, r)
+}
+
+// publish records a progress event and forwards it to subscribers, dropping
+// it for any that are too far behind.
+func (c *crawl) publish(evt crawler.CrawlEvent) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if evt.Checked > 0 {
+		c.checked = evt.Checked
+	}
+	c.broken = max(c.broken, evt.Broken)
+	for sub := range c.subs {
+		select {
+		case sub <- evt:
+		default:
+		}
+	}
+}
+
+// finish records the crawl's outcome and closes every subscription.
+func (c *crawl) finish(cancelled bool, res *result.Result, err error) {
+	c.mu.Lock()
+	defer func() {
+		c.mu.Unlock()
+		close(c.done)
+	}()
+	c.finishedAt = time.Now().UTC()
+	c.result = res
+	c.err = err
+	switch {
+	case cancelled:
+		c.status = StatusCancelled
+	case err != nil:
+		c.status = StatusFailed
+	default:
+		c.status = StatusDone
+	}
+	if res != nil {
+		c.checked = res.Stats.TotalChecked
+		c.broken = res.Stats.BrokenCount
+	}
+	for sub := range c.subs {
+		close(sub)
+	}
+	c.subs = nil
+}
+
+// finished reports whether the crawl has stopped running.
+func (c *crawl) finished() bool {
+	select {
+	case <-c.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// subscribe returns a channel of progress events that is closed when the
+// crawl finishes. It is closed immediately if the crawl already has.
+func (c *crawl) subscribe() chan crawler.CrawlEvent {
+	sub := make(chan crawler.CrawlEvent, subscriberBuffer)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.subs == nil {
+		close(sub)
+		return sub
+	}
+	c.subs[sub] = struct{}{}
+	return sub
+}
+
+// unsubscribe stops delivering events to sub.
+func (c *crawl) unsubscribe(sub chan crawler.CrawlEvent) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.subs[sub]; ok {
+		delete(c.subs, sub)
+		close(sub)
+	}
+}
+
+// snapshot returns the crawl's current status.
+func (c *crawl) snapshot() CrawlStatus {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	status := CrawlStatus{
+		ID:        c.id,
+		RunID:     c.crawler.RunID(),
+		URL:       c.url,
+		Status:    c.status,
+		StartedAt: c.startedAt,
+		Checked:   c.checked,
+		Broken:    c.broken,
+		Rate:      c.crawler.Rate(),
+	}
+	if c.status != StatusRunning {
+		finishedAt := c.finishedAt
+		status.FinishedAt = &finishedAt
+	}
+	if c.err != nil {
+		status.Error = c.err.Error()
+	}
+	return status
+}
+
+// writeJSON writes v as the JSON response body.
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(v)
+}
+
+// writeError writes err as a JSON error response.
+func writeError(w http.ResponseWriter, code int, err error) {
+	writeJSON(w, code, map[string]string{"error": err.Error()})
+}
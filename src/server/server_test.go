@@ -0,0 +1,361 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/net/websocket"
+
+	"github.com/lukemcguire/zombiecrawl/crawler"
+	"github.com/lukemcguire/zombiecrawl/result"
+)
+
+// newSite serves a page linking to /ok and /dead, answering page requests
+// after delay.
+func newSite(delay time.Duration) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			http.NotFound(w, r)
+			return
+		}
+		time.Sleep(delay)
+		switch r.URL.Path {
+		case "/":
+			_, _ = fmt.Fprint(w, `<a href="/ok">ok</a><a href="/dead">dead</a>`)
+		case "/ok":
+			_, _ = fmt.Fprint(w, `<p>fine</p>`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+}
+
+func newAPI(t *testing.T) (*Server, *httptest.Server) {
+	t.Helper()
+	srv := New(func(startURL string) crawler.Config {
+		cfg := crawler.DefaultConfig(startURL)
+		cfg.Delay = 1
+		cfg.RetryPolicy.MaxRetries = 0
+		return cfg
+	})
+	api := httptest.NewServer(srv)
+	t.Cleanup(func() {
+		api.Close()
+		srv.Close()
+	})
+	return srv, api
+}
+
+// call sends a JSON request and decodes the JSON response into out.
+func call(t *testing.T, method, rawURL, body string, out any) int {
+	t.Helper()
+	req, err := http.NewRequest(method, rawURL, strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("NewRequest() error: %v", err)
+	}
+	if method != http.MethodGet {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("%s %s: %v", method, rawURL, err)
+	}
+	defer resp.Body.Close()
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			t.Fatalf("decode %s %s: %v", method, rawURL, err)
+		}
+	}
+	return resp.StatusCode
+}
+
+func startCrawl(t *testing.T, api *httptest.Server, siteURL string) CrawlStatus {
+	t.Helper()
+	var started CrawlStatus
+	if code := call(t, http.MethodPost, api.URL+"/crawls", fmt.Sprintf(`{"url":%q}`, siteURL), &started); code != http.StatusCreated {
+		t.Fatalf("POST /crawls = %d, want 201", code)
+	}
+	return started
+}
+
+func waitFinished(t *testing.T, api *httptest.Server, id string) CrawlStatus {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		var status CrawlStatus
+		call(t, http.MethodGet, api.URL+"/crawls/"+id, "", &status)
+		if status.Status != StatusRunning {
+			return status
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("crawl %s did not finish", id)
+	return CrawlStatus{}
+}
+
+func TestServer_CrawlLifecycle(t *testing.T) {
+	site := newSite(0)
+	defer site.Close()
+	_, api := newAPI(t)
+
+	started := startCrawl(t, api, site.URL)
+	if started.Status != StatusRunning || started.ID == "" {
+		t.Errorf("started = %+v, want a running crawl with an ID", started)
+	}
+
+	status := waitFinished(t, api, started.ID)
+	if status.Status != StatusDone || status.Checked != 3 || status.Broken != 1 || status.FinishedAt == nil {
+		t.Errorf("status = %+v, want done with 3 checked, 1 broken", status)
+	}
+
+	var res result.Result
+	if code := call(t, http.MethodGet, api.URL+"/crawls/"+started.ID+"/results", "", &res); code != http.StatusOK {
+		t.Fatalf("GET results = %d, want 200", code)
+	}
+	if len(res.BrokenLinks) != 1 || res.BrokenLinks[0].URL != site.URL+"/dead" {
+		t.Errorf("broken links = %+v, want /dead", res.BrokenLinks)
+	}
+
+	var list []CrawlStatus
+	call(t, http.MethodGet, api.URL+"/crawls", "", &list)
+	if len(list) != 1 || list[0].ID != started.ID {
+		t.Errorf("list = %+v, want the one crawl", list)
+	}
+}
+
+func TestServer_StopAndRate(t *testing.T) {
+	site := newSite(200 * time.Millisecond)
+	defer site.Close()
+	_, api := newAPI(t)
+
+	started := startCrawl(t, api, site.URL)
+
+	var errBody map[string]string
+	if code := call(t, http.MethodGet, api.URL+"/crawls/"+started.ID+"/results", "", &errBody); code != http.StatusConflict {
+		t.Errorf("results while running = %d, want 409", code)
+	}
+
+	var rated CrawlStatus
+	if code := call(t, http.MethodPut, api.URL+"/crawls/"+started.ID+"/rate", `{"requests_per_second":0.5}`, &rated); code != http.StatusOK {
+		t.Fatalf("PUT rate = %d, want 200", code)
+	}
+	if rated.Rate != 0.5 {
+		t.Errorf("rate = %v, want 0.5", rated.Rate)
+	}
+	if code := call(t, http.MethodPut, api.URL+"/crawls/"+started.ID+"/rate", `{"requests_per_second":0}`, &errBody); code != http.StatusBadRequest {
+		t.Errorf("PUT zero rate = %d, want 400", code)
+	}
+
+	var stopped CrawlStatus
+	if code := call(t, http.MethodDelete, api.URL+"/crawls/"+started.ID, "", &stopped); code != http.StatusOK {
+		t.Fatalf("DELETE = %d, want 200", code)
+	}
+	if stopped.Status != StatusCancelled {
+		t.Errorf("stopped status = %q, want cancelled", stopped.Status)
+	}
+}
+
+func TestServer_Errors(t *testing.T) {
+	_, api := newAPI(t)
+	var errBody map[string]string
+
+	if code := call(t, http.MethodGet, api.URL+"/crawls/42", "", &errBody); code != http.StatusNotFound {
+		t.Errorf("unknown crawl = %d, want 404", code)
+	}
+	if code := call(t, http.MethodPost, api.URL+"/crawls", `{"url":"ftp://example.com"}`, &errBody); code != http.StatusBadRequest {
+		t.Errorf("bad url = %d, want 400", code)
+	}
+	if !strings.Contains(errBody["error"], "invalid url") {
+		t.Errorf("error body = %v, want an invalid url message", errBody)
+	}
+}
+
+func TestServer_EventStream(t *testing.T) {
+	site := newSite(50 * time.Millisecond)
+	defer site.Close()
+	_, api := newAPI(t)
+
+	started := startCrawl(t, api, site.URL)
+	wsURL := "ws" + strings.TrimPrefix(api.URL, "http") + "/crawls/" + started.ID + "/events"
+	ws, err := websocket.Dial(wsURL, "", api.URL)
+	if err != nil {
+		t.Fatalf("Dial() error: %v", err)
+	}
+	defer ws.Close()
+
+	// Events arrive as they happen; the final message is the crawl's status
+	var messages []map[string]any
+	for {
+		var msg map[string]any
+		if err := websocket.JSON.Receive(ws, &msg); err != nil {
+			break
+		}
+		messages = append(messages, msg)
+	}
+	if len(messages) < 2 {
+		t.Fatalf("got %d messages, want events and a final status", len(messages))
+	}
+	last := messages[len(messages)-1]
+	if last["status"] != string(StatusDone) {
+		t.Errorf("final message = %v, want done status", last)
+	}
+	if _, ok := messages[0]["checked"]; !ok {
+		t.Errorf("first message = %v, want a progress event", messages[0])
+	}
+}
+
+func TestServer_RefusesCrossSiteRequests(t *testing.T) {
+	site := newSite(0)
+	defer site.Close()
+	_, api := newAPI(t)
+	body := fmt.Sprintf(`{"url":%q}`, site.URL)
+
+	post := func(contentType, origin string) int {
+		t.Helper()
+		req, err := http.NewRequest(http.MethodPost, api.URL+"/crawls", strings.NewReader(body))
+		if err != nil {
+			t.Fatalf("NewRequest() error: %v", err)
+		}
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+		if origin != "" {
+			req.Header.Set("Origin", origin)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("POST /crawls: %v", err)
+		}
+		_ = resp.Body.Close()
+		return resp.StatusCode
+	}
+	// A form post needs no CORS preflight, so it must be refused outright
+	if code := post("text/plain", ""); code != http.StatusUnsupportedMediaType {
+		t.Errorf("text/plain POST = %d, want 415", code)
+	}
+	if code := post("", ""); code != http.StatusUnsupportedMediaType {
+		t.Errorf("POST without Content-Type = %d, want 415", code)
+	}
+	if code := post("application/json", "https://evil.example"); code != http.StatusForbidden {
+		t.Errorf("cross-origin POST = %d, want 403", code)
+	}
+	if code := post("application/json; charset=utf-8", api.URL); code != http.StatusCreated {
+		t.Errorf("same-origin POST = %d, want 201", code)
+	}
+
+	started := startCrawl(t, api, site.URL)
+	wsURL := "ws" + strings.TrimPrefix(api.URL, "http") + "/crawls/" + started.ID + "/events"
+	if ws, err := websocket.Dial(wsURL, "", "https://evil.example"); err == nil {
+		_ = ws.Close()
+		t.Error("cross-origin WebSocket connected, want refused")
+	}
+}
+
+func TestServer_RefusesRebindingHosts(t *testing.T) {
+	site := newSite(0)
+	defer site.Close()
+	_, api := newAPI(t)
+	started := startCrawl(t, api, site.URL)
+	waitFinished(t, api, started.ID)
+
+	// A page on evil.example rebinding its name to 127.0.0.1 sends its own
+	// name in Host and Origin
+	send := func(method, path string) int {
+		t.Helper()
+		req, err := http.NewRequest(method, api.URL+path, nil)
+		if err != nil {
+			t.Fatalf("NewRequest() error: %v", err)
+		}
+		req.Host = "evil.example" + strings.TrimPrefix(api.URL, "http://127.0.0.1")
+		req.Header.Set("Origin", "http://"+req.Host)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("%s %s: %v", method, path, err)
+		}
+		_ = resp.Body.Close()
+		return resp.StatusCode
+	}
+	for _, path := range []string{"/crawls", "/crawls/" + started.ID + "/results", "/crawls/" + started.ID + "/events"} {
+		if code := send(http.MethodGet, path); code != http.StatusForbidden {
+			t.Errorf("GET %s for evil.example = %d, want 403", path, code)
+		}
+	}
+	if code := send(http.MethodDelete, "/crawls/"+started.ID); code != http.StatusForbidden {
+		t.Errorf("DELETE for evil.example = %d, want 403", code)
+	}
+
+	// DELETE has no body, so needs no Content-Type
+	req, err := http.NewRequest(http.MethodDelete, api.URL+"/crawls/"+started.ID, nil)
+	if err != nil {
+		t.Fatalf("NewRequest() error: %v", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("DELETE: %v", err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode == http.StatusUnsupportedMediaType || resp.StatusCode == http.StatusForbidden {
+		t.Errorf("DELETE without a body = %d, want it handled", resp.StatusCode)
+	}
+}
+
+func TestServer_AllowedHost(t *testing.T) {
+	srv := New(crawler.DefaultConfig)
+	for host, want := range map[string]bool{
+		"localhost:8080":     true,
+		"LOCALHOST":          true,
+		"127.0.0.1:8080":     true,
+		"[::1]:8080":         true,
+		"crawler.lan:8080":   false,
+		"192.168.1.5:8080":   false,
+		"evil.example:8080":  false,
+		"127.0.0.1.nip.io:1": false,
+	} {
+		if got := srv.allowedHost(host); got != want {
+			t.Errorf("allowedHost(%q) = %v, want %v", host, got, want)
+		}
+	}
+
+	srv.SetAddr("crawler.lan:8080")
+	if !srv.allowedHost("crawler.lan:8080") || srv.allowedHost("192.168.1.5:8080") {
+		t.Error("after SetAddr(crawler.lan:8080), want only that name added")
+	}
+	srv.SetAddr(":8080")
+	if !srv.allowedHost("192.168.1.5:8080") || srv.allowedHost("crawler.lan:8080") {
+		t.Error("after SetAddr(:8080), want IP addresses but no other names")
+	}
+}
+
+func TestServer_PrunesFinishedCrawls(t *testing.T) {
+	srv := New(crawler.DefaultConfig)
+	started := time.Now()
+	running := &crawl{id: "running", startedAt: started, done: make(chan struct{})}
+	srv.crawls[running.id] = running
+	for i := range maxFinishedCrawls + 5 {
+		run := &crawl{id: fmt.Sprint(i), startedAt: started.Add(time.Duration(i+1) * time.Second), done: make(chan struct{})}
+		close(run.done)
+		srv.crawls[run.id] = run
+	}
+
+	srv.mu.Lock()
+	srv.pruneLocked()
+	srv.mu.Unlock()
+
+	if len(srv.crawls) != maxFinishedCrawls+1 {
+		t.Errorf("kept %d crawls, want %d finished and the running one", len(srv.crawls), maxFinishedCrawls)
+	}
+	if _, ok := srv.crawls["running"]; !ok {
+		t.Error("running crawl pruned, want it kept")
+	}
+	if _, ok := srv.crawls["0"]; ok {
+		t.Error("oldest finished crawl kept, want it pruned")
+	}
+	if _, ok := srv.crawls[fmt.Sprint(maxFinishedCrawls+4)]; !ok {
+		t.Error("newest finished crawl pruned, want it kept")
+	}
+}
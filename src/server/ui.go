@@ -0,0 +1,51 @@
+package server
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"net/http"
+
+	"github.com/lukemcguire/zombiecrawl/history"
+)
+
+// uiFiles holds the dashboard: a static page that drives the REST API and
+// follows crawls over the event WebSocket.
+//
+//go:embed ui
+var uiFiles embed.FS
+
+// handleIndex serves the dashboard page.
+func (s *Server) handleIndex(w http.ResponseWriter, r *http.Request) {
+	http.ServeFileFS(w, r, uiFiles, "ui/index.html")
+}
+
+// uiHandler serves the dashboard's assets under /ui/.
+func uiHandler() http.Handler {
+	assets, err := fs.Sub(uiFiles, "ui")
+	if err != nil {
+		panic(fmt.Sprintf("embedded ui: %v", err))
+	}
+	return http.StripPrefix("/ui/", http.FileServerFS(assets))
+}
+
+// handleHistory serves the trend report for the run database set with
+// SetHistory.
+func (s *Server) handleHistory(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	path := s.history
+	s.mu.Unlock()
+	if path == "" {
+		http.Error(w, "no run database configured", http.StatusNotFound)
+		return
+	}
+	runs, err := history.Load(path)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := history.WriteHTML(w, history.Trends(runs)); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,194 @@
+"use strict";
+
+// Dashboard for "zombiecrawl serve": lists crawls, follows one over the
+// event WebSocket, and shows its broken links once it finishes.
+
+const maxEvents = 200;
+let selected = null;
+let socket = null;
+let brokenLinks = [];
+let sortKey = "url";
+
+const $ = (id) => document.getElementById(id);
+
+async function api(method, path, body) {
+  const resp = await fetch(path, {
+    method,
+    // The server refuses requests that change state unless they are JSON
+    headers: method === "GET" ? {} : { "Content-Type": "application/json" },
+    body: body ? JSON.stringify(body) : undefined,
+  });
+  const data = await resp.json();
+  if (!resp.ok) throw new Error(data.error || resp.statusText);
+  return data;
+}
+
+function showMessage(text) {
+  $("message").textContent = text;
+}
+
+function cell(row, text, className) {
+  const td = row.insertCell();
+  td.textContent = text;
+  if (className) td.className = className;
+  return td;
+}
+
+async function refreshCrawls() {
+  let crawls;
+  try {
+    crawls = await api("GET", "/crawls");
+  } catch (err) {
+    showMessage(err.message);
+    return;
+  }
+  const body = $("crawls").tBodies[0];
+  body.replaceChildren();
+  for (const c of crawls.slice().reverse()) {
+    const row = body.insertRow();
+    row.classList.toggle("selected", c.id === selected);
+    row.addEventListener("click", () => select(c.id));
+    cell(row, c.id);
+    cell(row, c.url);
+    cell(row, c.status, "status-" + c.status);
+    cell(row, c.checked);
+    cell(row, c.broken);
+    cell(row, c.requests_per_second.toFixed(2));
+    cell(row, new Date(c.started_at).toLocaleString());
+    if (c.id === selected) updateDetail(c);
+  }
+}
+
+function updateDetail(c) {
+  $("detail-status").textContent = c.status;
+  $("detail-status").className = "status-" + c.status;
+  $("detail-checked").textContent = c.checked;
+  $("detail-broken").textContent = c.broken;
+  $("stop").disabled = c.status !== "running";
+}
+
+async function select(id) {
+  selected = id;
+  if (socket) socket.close();
+  brokenLinks = [];
+  renderBroken();
+  $("events").replaceChildren();
+  $("detail").hidden = false;
+
+  const c = await api("GET", "/crawls/" + id);
+  $("detail-id").textContent = c.id;
+  $("detail-url").textContent = c.url;
+  updateDetail(c);
+  refreshCrawls();
+
+  if (c.status === "running") {
+    follow(id);
+  } else {
+    loadResults(id);
+  }
+}
+
+function follow(id) {
+  const scheme = location.protocol === "https:" ? "wss:" : "ws:";
+  socket = new WebSocket(`${scheme}//${location.host}/crawls/${id}/events`);
+  socket.onmessage = (msg) => {
+    const data = JSON.parse(msg.data);
+    if (data.status) {
+      // The final message is the crawl's status
+      updateDetail(data);
+      loadResults(id);
+      refreshCrawls();
+      return;
+    }
+    addEvent(data);
+  };
+}
+
+function addEvent(evt) {
+  if (evt.checked) $("detail-checked").textContent = evt.checked;
+  if (evt.broken) $("detail-broken").textContent = evt.broken;
+  const item = document.createElement("li");
+  item.textContent = `${evt.status_code || ""} ${evt.url || ""} ${evt.error || ""}`.trim();
+  if (evt.error) item.className = "broken";
+  const list = $("events");
+  list.prepend(item);
+  while (list.children.length > maxEvents) list.lastChild.remove();
+}
+
+async function loadResults(id) {
+  try {
+    const res = await api("GET", `/crawls/${id}/results`);
+    if (selected !== id) return;
+    brokenLinks = res.broken_links || [];
+  } catch (err) {
+    brokenLinks = [];
+  }
+  renderBroken();
+}
+
+function renderBroken() {
+  const filter = $("filter").value.toLowerCase();
+  const rows = brokenLinks
+    .filter((l) => !filter || [l.url, l.source_page, l.error, l.error_type].join(" ").toLowerCase().includes(filter))
+    .sort((a, b) => String(a[sortKey] ?? "").localeCompare(String(b[sortKey] ?? ""), undefined, { numeric: true }));
+  const body = $("broken").tBodies[0];
+  body.replaceChildren();
+  for (const link of rows) {
+    const row = body.insertRow();
+    cell(row, link.url);
+    cell(row, link.status_code || link.error || "");
+    cell(row, link.error_type || "");
+    cell(row, link.source_page || "");
+  }
+}
+
+$("start").addEventListener("submit", async (e) => {
+  e.preventDefault();
+  const form = new FormData(e.target);
+  const req = { url: form.get("url") };
+  if (form.get("max_depth")) req.max_depth = Number(form.get("max_depth"));
+  try {
+    const c = await api("POST", "/crawls", req);
+    showMessage("");
+    e.target.reset();
+    select(c.id);
+  } catch (err) {
+    showMessage(err.message);
+  }
+});
+
+$("rate").addEventListener("submit", async (e) => {
+  e.preventDefault();
+  const rps = Number(new FormData(e.target).get("rps"));
+  try {
+    updateDetail(await api("PUT", `/crawls/${selected}/rate`, { requests_per_second: rps }));
+    refreshCrawls();
+  } catch (err) {
+    showMessage(err.message);
+  }
+});
+
+$("stop").addEventListener("click", async () => {
+  try {
+    updateDetail(await api("DELETE", "/crawls/" + selected));
+    refreshCrawls();
+  } catch (err) {
+    showMessage(err.message);
+  }
+});
+
+$("filter").addEventListener("input", renderBroken);
+
+for (const th of document.querySelectorAll("#broken th[data-key]")) {
+  th.addEventListener("click", () => {
+    sortKey = th.dataset.key;
+    renderBroken();
+  });
+}
+
+fetch("/history", { method: "HEAD" }).then((resp) => {
+  $("history").hidden = !resp.ok;
+});
+
+refreshCrawls();
+setInterval(refreshCrawls, 2000);
@@ -0,0 +1,75 @@
+package server
+
+import (
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/lukemcguire/zombiecrawl/history"
+)
+
+func get(t *testing.T, rawURL string) (int, string) {
+	t.Helper()
+	resp, err := http.Get(rawURL)
+	if err != nil {
+		t.Fatalf("GET %s: %v", rawURL, err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("read %s: %v", rawURL, err)
+	}
+	return resp.StatusCode, string(body)
+}
+
+func TestServer_ServesDashboard(t *testing.T) {
+	_, api := newAPI(t)
+
+	code, body := get(t, api.URL+"/")
+	if code != http.StatusOK || !strings.Contains(body, `<script src="/ui/app.js">`) {
+		t.Errorf("GET / = %d, want the dashboard page", code)
+	}
+	for _, asset := range []string{"/ui/app.js", "/ui/style.css"} {
+		if code, _ := get(t, api.URL+asset); code != http.StatusOK {
+			t.Errorf("GET %s = %d, want 200", asset, code)
+		}
+	}
+	if code, _ := get(t, api.URL+"/missing"); code != http.StatusNotFound {
+		t.Errorf("GET /missing = %d, want 404", code)
+	}
+}
+
+func TestServer_HistoryDisabledByDefault(t *testing.T) {
+	_, api := newAPI(t)
+	if code, _ := get(t, api.URL+"/history"); code != http.StatusNotFound {
+		t.Errorf("GET /history = %d, want 404 without a run database", code)
+	}
+}
+
+func TestServer_RecordsCompletedCrawls(t *testing.T) {
+	site := newSite(0)
+	defer site.Close()
+	srv, api := newAPI(t)
+	db := filepath.Join(t.TempDir(), "runs.db")
+	srv.SetHistory(db)
+
+	started := startCrawl(t, api, site.URL)
+	if status := waitFinished(t, api, started.ID); status.Status != StatusDone {
+		t.Fatalf("status = %+v, want done", status)
+	}
+
+	runs, err := history.Load(db)
+	if err != nil {
+		t.Fatalf("Load() error: %v", err)
+	}
+	if len(runs) != 1 || runs[0].Site != site.URL || len(runs[0].Broken) != 1 {
+		t.Errorf("runs = %+v, want one run with one broken link", runs)
+	}
+
+	code, body := get(t, api.URL+"/history")
+	if code != http.StatusOK || !strings.Contains(body, site.URL) {
+		t.Errorf("GET /history = %d, want a report mentioning %s", code, site.URL)
+	}
+}
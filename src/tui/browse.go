@@ -0,0 +1,139 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/aymanbagabas/go-osc52/v2"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/lukemcguire/zombiecrawl/result"
+)
+
+// browseRows is how many broken links the browse list shows at once.
+const browseRows = 10
+
+// browseHelp lists the keys of the browse list.
+const browseHelp = "↑/↓ select · c copy URL · m copy table as Markdown · o open source page · u full URLs · q quit"
+
+// actionMsg reports the outcome of a browse action for the status line.
+type actionMsg struct {
+	status string
+	err    error
+}
+
+// copyToClipboard puts text on the clipboard with an OSC 52 escape
+// sequence, which the terminal handles, so it works over SSH and without a
+// clipboard program installed.
+func copyToClipboard(text string) error {
+	seq := osc52.New(text)
+	switch {
+	case os.Getenv("TMUX") != "":
+		seq = seq.Tmux()
+	case strings.HasPrefix(os.Getenv("TERM"), "screen"):
+		seq = seq.Screen()
+	}
+	// Stderr, to stay clear of the frames Bubble Tea draws on stdout
+	if _, err := seq.WriteTo(os.Stderr); err != nil {
+		return fmt.Errorf("copy to clipboard: %w", err)
+	}
+	return nil
+}
+
+// openInBrowser opens rawURL in the default browser without waiting for it.
+func openInBrowser(rawURL string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", rawURL)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", rawURL)
+	default:
+		cmd = exec.Command("xdg-open", rawURL)
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("open %s: %w", rawURL, err)
+	}
+	go func() { _ = cmd.Wait() }()
+	return nil
+}
+
+// browsing reports whether the crawl is over and m lists its broken links
+// to act on.
+func (m Model) browsing() bool {
+	return m.browse && m.done && m.result != nil && len(m.result.BrokenLinks) > 0
+}
+
+// updateBrowse handles a key pressed in the browse list.
+func (m Model) updateBrowse(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	links := m.result.BrokenLinks
+	switch msg.String() {
+	case "ctrl+c", "q", "esc":
+		m.quitting = true
+		return m, tea.Quit
+	case "up", "k":
+		m.cursor = max(m.cursor-1, 0)
+	case "down", "j":
+		m.cursor = min(m.cursor+1, len(links)-1)
+	case "u":
+		m.fullURLs = !m.fullURLs
+	case "c", "y":
+		link := links[m.cursor].URL
+		return m, m.action(func() error { return m.copyText(link) }, "copied "+link)
+	case "m":
+		table := result.MarkdownTable(links)
+		return m, m.action(func() error { return m.copyText(table) }, fmt.Sprintf("copied %d broken links as Markdown", len(links)))
+	case "o":
+		page := links[m.cursor].SourcePage
+		if page == "" {
+			m.status = theme.Error.Render("no source page to open")
+			return m, nil
+		}
+		return m, m.action(func() error { return m.openURL(page) }, "opened "+page)
+	}
+	return m, nil
+}
+
+// action returns a tea.Cmd running do and reporting done, or its error.
+func (m Model) action(do func() error, done string) tea.Cmd {
+	return func() tea.Msg {
+		if err := do(); err != nil {
+			return actionMsg{err: err}
+		}
+		return actionMsg{status: done}
+	}
+}
+
+// viewBrowse renders the browse list below the summary: a window of broken
+// links around the cursor, the keys, and the outcome of the last action.
+func (m Model) viewBrowse() string {
+	links := m.result.BrokenLinks
+	first := min(max(m.cursor-browseRows/2, 0), max(len(links)-browseRows, 0))
+	last := min(first+browseRows, len(links))
+
+	var builder strings.Builder
+	builder.WriteString(theme.Category.Render(fmt.Sprintf("## Browse (%d of %d)", m.cursor+1, len(links))))
+	builder.WriteString("\n")
+	for i := first; i < last; i++ {
+		link := links[i]
+		line := fmt.Sprintf("%s (%s) on %s", link.URL, result.FormatStatus(link), link.SourcePage)
+		if m.width > 0 && !m.fullURLs {
+			line = shorten(line, max(m.width-2, minColumnWidth))
+		}
+		if i == m.cursor {
+			builder.WriteString(theme.Header.Render("> " + line))
+		} else {
+			builder.WriteString(theme.URL.Render("  " + line))
+		}
+		builder.WriteString("\n")
+	}
+	builder.WriteString(theme.Dim.Render(browseHelp))
+	builder.WriteString("\n")
+	if m.status != "" {
+		builder.WriteString(m.status)
+		builder.WriteString("\n")
+	}
+	return builder.String()
+}
@@ -0,0 +1,111 @@
+package tui
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/lukemcguire/zombiecrawl/result"
+)
+
+// browseResult has two broken links, the second without a source page.
+var browseResult = &result.Result{
+	BrokenLinks: []result.LinkResult{
+		{URL: "https://example.com/dead", StatusCode: 404, SourcePage: "https://example.com/"},
+		{URL: "https://down.example/", Error: "connection refused"},
+	},
+	Stats: result.CrawlStats{TotalChecked: 3, BrokenCount: 2},
+}
+
+// press sends key to m and returns the updated model and the message of the
+// command it returned, if any.
+func press(t *testing.T, m Model, key string) (Model, tea.Msg) {
+	t.Helper()
+	keyMsg := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)}
+	switch key {
+	case "down":
+		keyMsg = tea.KeyMsg{Type: tea.KeyDown}
+	case "up":
+		keyMsg = tea.KeyMsg{Type: tea.KeyUp}
+	}
+	updated, cmd := m.Update(keyMsg)
+	if cmd == nil {
+		return updated.(Model), nil
+	}
+	return updated.(Model), cmd()
+}
+
+// receive sends msg, the outcome of an action, to m.
+func receive(m Model, msg tea.Msg) Model {
+	updated, _ := m.Update(msg)
+	return updated.(Model)
+}
+
+func TestBrowse(t *testing.T) {
+	var copied, opened []string
+	model := Model{
+		browse:   true,
+		copyText: func(text string) error { copied = append(copied, text); return nil },
+		openURL:  func(url string) error { opened = append(opened, url); return nil },
+	}
+	updated, cmd := model.Update(CrawlDoneMsg{Result: browseResult})
+	model = updated.(Model)
+	if cmd != nil {
+		t.Fatal("expected the browse list to stay open after the crawl")
+	}
+	if output := model.View(); !strings.Contains(output, "> https://example.com/dead (404) on https://example.com/") {
+		t.Errorf("expected the first link selected, got: %s", output)
+	}
+
+	model, msg := press(t, model, "c")
+	model = receive(model, msg)
+	model, msg = press(t, model, "o")
+	model = receive(model, msg)
+	model, msg = press(t, model, "m")
+	model = receive(model, msg)
+	if len(copied) != 2 || copied[0] != "https://example.com/dead" || copied[1] != result.MarkdownTable(browseResult.BrokenLinks) {
+		t.Errorf("copied %q, want the selected URL and the Markdown table", copied)
+	}
+	if len(opened) != 1 || opened[0] != "https://example.com/" {
+		t.Errorf("opened %q, want the source page", opened)
+	}
+	if !strings.Contains(model.View(), "copied 2 broken links as Markdown") {
+		t.Errorf("expected the outcome of the last action, got: %s", model.View())
+	}
+
+	model, _ = press(t, model, "down")
+	model, _ = press(t, model, "down")
+	if model.cursor != 1 {
+		t.Errorf("cursor = %d, want 1 after moving past the last link", model.cursor)
+	}
+	model, msg = press(t, model, "o")
+	if msg != nil || !strings.Contains(model.View(), "no source page to open") {
+		t.Errorf("expected o on a link without a source page to fail, got: %s", model.View())
+	}
+
+	if _, msg := press(t, model, "q"); msg != (tea.QuitMsg{}) {
+		t.Errorf("q returned %v, want tea.QuitMsg", msg)
+	}
+}
+
+func TestBrowse_ActionError(t *testing.T) {
+	model := Model{
+		browse:   true,
+		done:     true,
+		result:   browseResult,
+		copyText: func(string) error { return errors.New("no terminal") },
+	}
+	model, msg := press(t, model, "c")
+	if output := receive(model, msg).View(); !strings.Contains(output, "no terminal") {
+		t.Errorf("expected the copy error in the view, got: %s", output)
+	}
+}
+
+func TestBrowse_NoBrokenLinks(t *testing.T) {
+	model := Model{browse: true}
+	_, cmd := model.Update(CrawlDoneMsg{Result: &result.Result{}})
+	if cmd == nil || cmd() != (tea.QuitMsg{}) {
+		t.Error("expected a crawl without broken links to quit")
+	}
+}
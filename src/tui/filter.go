@@ -0,0 +1,90 @@
+package tui
+
+import (
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/lukemcguire/zombiecrawl/result"
+)
+
+// eventFilter selects which checks the progress view shows, typed after /
+// while crawling. It only changes the display; the crawl checks every link
+// either way. A filter is one of:
+//
+//	5xx        status class, or the 4xx/5xx error category
+//	404        exact status code
+//	timeout    error category, as in the error_type of reports
+//	/blog      URL path prefix
+//	cdn        any other text: URLs containing it, ignoring case
+//
+// The empty filter shows everything.
+type eventFilter string
+
+// match reports whether a check of rawURL answered with status, failing in
+// category if it is broken, passes f.
+func (f eventFilter) match(rawURL string, status int, category result.ErrorCategory) bool {
+	pattern := strings.ToLower(string(f))
+	switch {
+	case pattern == "":
+		return true
+	case len(pattern) == 3 && pattern[0] >= '1' && pattern[0] <= '5' && pattern[1:] == "xx":
+		return status/100 == int(pattern[0]-'0') || string(category) == pattern
+	case len(pattern) == 3 && isDigits(pattern):
+		code, _ := strconv.Atoi(pattern)
+		return status == code
+	case slices.Contains(categoryOrder, result.ErrorCategory(pattern)):
+		return string(category) == pattern
+	case strings.HasPrefix(pattern, "/"):
+		parsed, err := url.Parse(rawURL)
+		return err == nil && strings.HasPrefix(strings.ToLower(parsed.Path), pattern)
+	}
+	return strings.Contains(strings.ToLower(rawURL), pattern)
+}
+
+// matchLink reports whether the broken link passes f.
+func (f eventFilter) matchLink(link result.LinkResult) bool {
+	return f.match(link.URL, link.StatusCode, link.ErrorCategory)
+}
+
+// isDigits reports whether s is made of ASCII digits only.
+func isDigits(s string) bool {
+	return strings.Trim(s, "0123456789") == ""
+}
+
+// updateFilter handles a key pressed while typing a filter: Enter applies
+// it, Esc abandons it, and an empty filter applied shows everything again.
+func (m Model) updateFilter(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyCtrlC:
+		m.quitting = true
+		m.cancel()
+		return m, tea.Quit
+	case tea.KeyEnter:
+		m.filter = eventFilter(strings.TrimSpace(m.filterInput))
+		m.typingFilter = false
+	case tea.KeyEsc:
+		m.typingFilter = false
+	case tea.KeyBackspace:
+		if runes := []rune(m.filterInput); len(runes) > 0 {
+			m.filterInput = string(runes[:len(runes)-1])
+		}
+	case tea.KeyRunes, tea.KeySpace:
+		m.filterInput += string(msg.Runes)
+	}
+	return m, nil
+}
+
+// filterLine shows the filter being typed, or the one applied, for the
+// progress line, e.g. " · filter: 5xx", or returns "".
+func (m Model) filterLine() string {
+	switch {
+	case m.typingFilter:
+		return " · /" + m.filterInput + "▌"
+	case m.filter != "":
+		return " · filter: " + string(m.filter)
+	}
+	return ""
+}
@@ -0,0 +1,84 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/lukemcguire/zombiecrawl/result"
+)
+
+func TestEventFilter_Match(t *testing.T) {
+	tests := []struct {
+		filter   string
+		url      string
+		status   int
+		category result.ErrorCategory
+		want     bool
+	}{
+		{"", "https://example.com/", 200, "", true},
+		{"5xx", "https://example.com/", 503, result.Category5xx, true},
+		{"5xx", "https://example.com/", 404, result.Category4xx, false},
+		{"2xx", "https://example.com/", 204, "", true},
+		{"404", "https://example.com/", 404, result.Category4xx, true},
+		{"404", "https://example.com/", 410, result.Category4xx, false},
+		{"timeout", "https://example.com/", 0, result.CategoryTimeout, true},
+		{"timeout", "https://example.com/timeout", 200, "", false},
+		{"/blog", "https://example.com/Blog/post", 200, "", true},
+		{"/blog", "https://example.com/about/blog", 200, "", false},
+		{"CDN", "https://cdn.example.com/app.js", 200, "", true},
+		{"cdn", "https://example.com/", 200, "", false},
+	}
+	for _, tt := range tests {
+		if got := eventFilter(tt.filter).match(tt.url, tt.status, tt.category); got != tt.want {
+			t.Errorf("filter %q match(%s, %d, %q) = %v, want %v", tt.filter, tt.url, tt.status, tt.category, got, tt.want)
+		}
+	}
+}
+
+// typeKeys sends each rune of text to m as a key press, then Enter.
+func typeKeys(m Model, text string) Model {
+	for _, r := range text {
+		updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+		m = updated.(Model)
+	}
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	return updated.(Model)
+}
+
+func TestUpdate_Filter(t *testing.T) {
+	model := typeKeys(Model{feedSize: 5, cancel: func() {}}, "/5xq")
+	if model.typingFilter || model.filter != "5xq" || model.quitting {
+		t.Fatalf("filter = %q, typing %v, quitting %v; want 5xq applied and q typed, not quitting", model.filter, model.typingFilter, model.quitting)
+	}
+	updated, _ := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("/")})
+	updated, _ = updated.Update(tea.KeyMsg{Type: tea.KeyBackspace})
+	model = typeKeys(updated.(Model), "x")
+	if model.filter != "5xx" {
+		t.Fatalf("filter = %q, want 5xx after editing", model.filter)
+	}
+
+	for _, msg := range []CrawlProgressMsg{
+		{Checked: 1, URL: "https://example.com/down", StatusCode: 503, Category: result.Category5xx,
+			BrokenLinks: []result.LinkResult{{URL: "https://example.com/down", StatusCode: 503, ErrorCategory: result.Category5xx}}},
+		{Checked: 2, URL: "https://example.com/ok", StatusCode: 200},
+		{Checked: 3, URL: "https://example.com/gone", StatusCode: 404, Category: result.Category4xx,
+			BrokenLinks: []result.LinkResult{{URL: "https://example.com/gone", StatusCode: 404, ErrorCategory: result.Category4xx}}},
+	} {
+		updated, _ := model.Update(msg)
+		model = updated.(Model)
+	}
+	view := model.View()
+	if model.checked != 3 || model.current != "https://example.com/down" {
+		t.Errorf("checked %d, current %q; want every check counted and the last 5xx shown", model.checked, model.current)
+	}
+	if !strings.Contains(view, "filter: 5xx") || !strings.Contains(view, "✗ 5xx https://example.com/down") || strings.Contains(view, "gone") {
+		t.Errorf("expected only the 5xx check shown, got: %s", view)
+	}
+
+	updated, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("/")})
+	updated, _ = updated.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if model := updated.(Model); model.typingFilter || model.filter != "5xx" {
+		t.Errorf("filter = %q, typing %v; want Esc to keep the applied filter", model.filter, model.typingFilter)
+	}
+}
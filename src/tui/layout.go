@@ -0,0 +1,99 @@
+package tui
+
+import (
+	"github.com/charmbracelet/lipgloss"
+)
+
+// minColumnWidth is the narrowest a table column is shortened to, however
+// narrow the terminal.
+const minColumnWidth = 8
+
+// ellipsis marks where a shortened cell was cut.
+const ellipsis = "…"
+
+// SummaryOptions lays out RenderSummaryWith for a terminal.
+type SummaryOptions struct {
+	// Width is the terminal width tables are fit to by shortening their
+	// longest cells in the middle, keeping both the host and the end of a
+	// URL. 0 leaves tables as wide as their contents.
+	Width int
+
+	// FullURLs never shortens cells, even if tables overflow Width.
+	FullURLs bool
+}
+
+// fit returns rows shortened so a bordered table of them under headers is
+// no wider than opts.Width. The widest columns are cut first, to one common
+// limit, so short columns such as statuses stay whole.
+func (opts SummaryOptions) fit(rows [][]string, headers ...string) [][]string {
+	if opts.Width <= 0 || opts.FullURLs || len(headers) == 0 {
+		return rows
+	}
+	widths := make([]int, len(headers))
+	for col, header := range headers {
+		widths[col] = lipgloss.Width(header)
+	}
+	for _, row := range rows {
+		for col, cell := range row {
+			if col < len(widths) {
+				widths[col] = max(widths[col], lipgloss.Width(cell))
+			}
+		}
+	}
+	// One border between and around every column
+	available := opts.Width - len(headers) - 1
+	limit := columnLimit(widths, available)
+	if limit < 0 {
+		return rows
+	}
+
+	fitted := make([][]string, len(rows))
+	for i, row := range rows {
+		fitted[i] = make([]string, len(row))
+		for col, cell := range row {
+			fitted[i][col] = shorten(cell, limit)
+		}
+	}
+	return fitted
+}
+
+// columnLimit returns the largest width limit that makes columns of widths
+// fit in available, but not below minColumnWidth, or -1 if they fit
+// already.
+func columnLimit(widths []int, available int) int {
+	total := 0
+	widest := 0
+	for _, width := range widths {
+		total += width
+		widest = max(widest, width)
+	}
+	if total <= available {
+		return -1
+	}
+	for limit := widest - 1; limit > minColumnWidth; limit-- {
+		total = 0
+		for _, width := range widths {
+			total += min(width, limit)
+		}
+		if total <= available {
+			return limit
+		}
+	}
+	return minColumnWidth
+}
+
+// shorten cuts the middle of s to make it at most limit cells wide.
+func shorten(s string, limit int) string {
+	if lipgloss.Width(s) <= limit {
+		return s
+	}
+	runes := []rune(s)
+	keep := max(limit-1, 1)
+	// Keep more of the start, where a URL's host is
+	head := (keep + 1) / 2
+	if keep > 2 {
+		head = keep * 3 / 5
+	}
+	tail := keep - head
+	return string(runes[:head]) + ellipsis + string(runes[len(runes)-tail:])
+}
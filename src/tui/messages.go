@@ -1,6 +1,8 @@
 package tui
 
 import (
+	"time"
+
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/lukemcguire/zombiecrawl/crawler"
 	"github.com/lukemcguire/zombiecrawl/result"
@@ -11,6 +13,21 @@ type CrawlProgressMsg struct {
 	Checked int
 	Broken  int
 	URL     string
+
+	// Details of the check of URL; zero on events that are not checks.
+	StatusCode int
+	Category   result.ErrorCategory // Set if the check failed
+	Depth      int
+	Attempts   int
+	Bytes      int64
+	Duration   time.Duration
+
+	// BrokenLinks lists the broken links the check found.
+	BrokenLinks []result.LinkResult
+
+	// Slow lists checks running longer than the slow-request threshold.
+	// It is nil except on in-flight status events, which carry no URL.
+	Slow []crawler.InFlightRequest
 }
 
 // CrawlDoneMsg signals the crawl has completed.
@@ -29,9 +46,17 @@ func waitForProgress(ch <-chan crawler.CrawlEvent) tea.Cmd {
 			return CrawlDoneMsg{}
 		}
 		return CrawlProgressMsg{
-			Checked: evt.Checked,
-			Broken:  evt.Broken,
-			URL:     evt.URL,
+			Checked:     evt.Checked,
+			Broken:      evt.Broken,
+			URL:         evt.URL,
+			StatusCode:  evt.StatusCode,
+			Category:    evt.ErrorCategory,
+			Depth:       evt.Depth,
+			Attempts:    evt.Attempt,
+			Bytes:       evt.ContentLength,
+			Duration:    evt.Duration,
+			BrokenLinks: evt.BrokenLinks,
+			Slow:        evt.Slow,
 		}
 	}
 }
@@ -3,8 +3,11 @@
 package tui
 
 import (
+	"cmp"
 	"context"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/charmbracelet/bubbles/spinner"
 	tea "github.com/charmbracelet/bubbletea"
@@ -20,29 +23,85 @@ type Model struct {
 	crawlerInstance *crawler.Crawler
 	spinner         spinner.Model
 	progressCh      <-chan crawler.CrawlEvent
+	finished        chan CrawlDoneMsg // Receives the crawl's outcome once Run returns, for Wait
 
-	checked  int
-	broken   int
-	current  string
+	checked   int
+	broken    int
+	current   string
+	detail    string                    // Status, depth, attempts, size, and time of current's check
+	slow      []crawler.InFlightRequest // Checks running longer than slowAfter
+	slowAfter time.Duration
+	recent    []result.LinkResult // Latest broken links, newest last
+	feedSize  int                 // Most broken links listed in recent
+
+	filter       eventFilter // Checks the progress view shows
+	filterInput  string      // Filter being typed after /
+	typingFilter bool        // Keys go to filterInput
+
+	sortKey  result.SortKey // Order of the result's broken links
 	quitting bool
 	done     bool
 	result   *result.Result
 	err      error
-	width    int
+	width    int  // Terminal width, from tea.WindowSizeMsg
+	fullURLs bool // Never shorten URLs to fit width
+
+	// Browsing broken links after the crawl
+	browse   bool               // Stay open after the crawl
+	cursor   int                // Selected index of result.BrokenLinks
+	status   string             // Outcome of the last action
+	copyText func(string) error // Puts text on the clipboard
+	openURL  func(string) error // Opens a URL in the browser
 }
 
 // NewModel creates a TUI model wired to the given crawler and progress channel.
 func NewModel(ctx context.Context, cancel context.CancelFunc, crawlerInst *crawler.Crawler, progressCh <-chan crawler.CrawlEvent) Model {
 	spin := spinner.New()
 	spin.Spinner = spinner.Dot
-	spin.Style = lipgloss.NewStyle().Foreground(lipgloss.Color("205"))
-	return Model{
+	spin.Style = theme.Spinner
+	model := Model{
 		ctx:             ctx,
 		cancel:          cancel,
 		crawlerInstance: crawlerInst,
 		spinner:         spin,
 		progressCh:      progressCh,
+		finished:        make(chan CrawlDoneMsg, 1),
+		feedSize:        DefaultBrokenFeed,
+		copyText:        copyToClipboard,
+		openURL:         openInBrowser,
 	}
+	if crawlerInst != nil {
+		model.slowAfter = crawlerInst.GetConfig().SlowRequest
+	}
+	return model
+}
+
+// WithFullURLs returns m set to show URLs in full rather than shortened to
+// the terminal width. The u key toggles it while crawling.
+func (m Model) WithFullURLs(full bool) Model {
+	m.fullURLs = full
+	return m
+}
+
+// WithBrokenFeed returns m set to list the n most recent broken links under
+// the progress line while crawling; 0 lists none.
+func (m Model) WithBrokenFeed(n int) Model {
+	m.feedSize = n
+	return m
+}
+
+// WithSort returns m set to sort the broken links of the crawl's result by
+// key when it finishes, for the summary and for GetResult.
+func (m Model) WithSort(key result.SortKey) Model {
+	m.sortKey = key
+	return m
+}
+
+// WithBrowse returns m set to stay open after a crawl with broken links,
+// listing them to copy or open the pages they are on, until q is pressed.
+func (m Model) WithBrowse(browse bool) Model {
+	m.browse = browse
+	return m
 }
 
 // Init starts the spinner, crawl, and progress listener concurrently.
@@ -57,19 +116,43 @@ func (m Model) startCrawl() tea.Cmd {
 		if err != nil {
 			err = fmt.Errorf("crawl: %w", err)
 		}
-		return CrawlDoneMsg{Result: res, Err: err}
+		msg := CrawlDoneMsg{Result: res, Err: err}
+		m.finished <- msg
+		return msg
 	}
 }
 
+// Wait blocks until the crawl started by Init has returned and gives m its
+// outcome. Quitting cancels the crawl without waiting for it, so call Wait
+// after the program exits and before acting on anything the crawl writes.
+func (m Model) Wait() Model {
+	if m.done {
+		return m
+	}
+	updated, _ := m.Update(<-m.finished)
+	return updated.(Model)
+}
+
 // Update handles messages from the Bubble Tea runtime.
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		if m.browsing() {
+			return m.updateBrowse(msg)
+		}
+		if m.typingFilter {
+			return m.updateFilter(msg)
+		}
 		switch msg.String() {
 		case "ctrl+c", "q":
 			m.quitting = true
 			m.cancel()
 			return m, tea.Quit
+		case "u":
+			m.fullURLs = !m.fullURLs
+		case "/":
+			m.typingFilter = true
+			m.filterInput = string(m.filter)
 		}
 
 	case tea.WindowSizeMsg:
@@ -78,15 +161,41 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case CrawlProgressMsg:
 		m.checked = msg.Checked
 		m.broken = msg.Broken
-		m.current = msg.URL
+		if msg.Slow != nil {
+			m.slow = msg.Slow
+		} else if m.filter.match(msg.URL, msg.StatusCode, msg.Category) {
+			m.current = msg.URL
+			m.detail = checkDetail(msg)
+		}
+		if m.feedSize > 0 {
+			for _, link := range msg.BrokenLinks {
+				// Filtered out now, so the feed keeps the last links shown
+				if m.filter.matchLink(link) {
+					m.recent = append(m.recent, link)
+				}
+			}
+			m.recent = m.recent[max(len(m.recent)-m.feedSize, 0):]
+		}
 		return m, waitForProgress(m.progressCh)
 
 	case CrawlDoneMsg:
 		m.done = true
 		m.result = msg.Result
 		m.err = msg.Err
+		if m.result != nil {
+			result.SortLinks(m.result.BrokenLinks, m.sortKey)
+		}
+		if m.browsing() {
+			return m, nil
+		}
 		return m, tea.Quit
 
+	case actionMsg:
+		m.status = theme.Dim.Render(msg.status)
+		if msg.err != nil {
+			m.status = theme.Error.Render(msg.err.Error())
+		}
+
 	case spinner.TickMsg:
 		var cmd tea.Cmd
 		m.spinner, cmd = m.spinner.Update(msg)
@@ -99,14 +208,101 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 // View renders the current TUI state.
 func (m Model) View() string {
 	if m.done && m.result != nil {
-		return RenderSummary(m.result)
+		summary := RenderSummaryWith(m.result, SummaryOptions{Width: m.width, FullURLs: m.fullURLs})
+		if m.browsing() {
+			return summary + "\n" + m.viewBrowse()
+		}
+		return summary
 	}
 	if m.done && m.err != nil {
-		return errorStyle.Render("Error: "+m.err.Error()) + "\n"
+		return theme.Error.Render("Error: "+m.err.Error()) + "\n"
+	}
+	current := m.current
+	if m.width > 0 && !m.fullURLs {
+		current = shorten(current, max(m.width-2-lipgloss.Width(m.detail), minColumnWidth))
+	}
+	return fmt.Sprintf("%s Crawling... checked %d, broken %d%s\n%s\n%s%s",
+		m.spinner.View(), m.checked, m.broken, m.filterLine(),
+		theme.Dim.Render("  "+current+m.detail), slowLine(m.slow, m.slowAfter), m.feedLines())
+}
+
+// DefaultBrokenFeed is how many recent broken links the progress view lists
+// unless WithBrokenFeed says otherwise.
+const DefaultBrokenFeed = 5
+
+// feedLines lists the most recent broken links, oldest first, each labeled
+// with its error category, e.g.
+// "  ✗ 4xx https://example.com/a (404) on https://example.com/".
+func (m Model) feedLines() string {
+	var builder strings.Builder
+	for _, link := range m.recent {
+		if !m.filter.matchLink(link) {
+			continue
+		}
+		category := cmp.Or(string(link.ErrorCategory), string(result.CategoryUnknown))
+		line := fmt.Sprintf("%s (%s) on %s", link.URL, result.FormatStatus(link), link.SourcePage)
+		if m.width > 0 && !m.fullURLs {
+			line = shorten(line, max(m.width-5-len(category), minColumnWidth))
+		}
+		builder.WriteString("  " + categoryStyle(link.ErrorCategory).Render("✗ "+category) + " " + line + "\n")
+	}
+	return builder.String()
+}
+
+// categoryStyle returns the style of a broken link's category label:
+// warnings for failures that may pass on a later run, such as timeouts and
+// rate limiting, errors for the rest.
+func categoryStyle(category result.ErrorCategory) lipgloss.Style {
+	switch category {
+	case result.CategoryTimeout, result.CategoryConnectTimeout, result.Category429,
+		result.CategoryQueueTimeout, result.CategoryConnectionRefused:
+		return theme.Category
+	case result.CategoryRobotsBlocked, result.CategoryBlockedHost:
+		return theme.Dim
+	}
+	return theme.StatusError
+}
+
+// checkDetail describes the check reported by msg, e.g.
+// " (200, depth 2, 3 attempts, 14.2 KiB, 120ms)", or returns "" if msg does
+// not report a response.
+func checkDetail(msg CrawlProgressMsg) string {
+	if msg.StatusCode == 0 {
+		return ""
+	}
+	parts := []string{fmt.Sprint(msg.StatusCode), fmt.Sprintf("depth %d", msg.Depth)}
+	if msg.Attempts > 1 {
+		parts = append(parts, fmt.Sprintf("%d attempts", msg.Attempts))
+	}
+	if msg.Bytes > 0 {
+		parts = append(parts, result.FormatBytes(msg.Bytes))
+	}
+	parts = append(parts, msg.Duration.Round(time.Millisecond).String())
+	return " (" + strings.Join(parts, ", ") + ")"
+}
+
+// maxSlowShown is how many slow requests the progress view names.
+const maxSlowShown = 3
+
+// slowLine summarizes checks running longer than threshold, e.g.
+// "3 requests > 30s: https://a (45s), …", or returns "" if there are none.
+func slowLine(slow []crawler.InFlightRequest, threshold time.Duration) string {
+	if len(slow) == 0 {
+		return ""
+	}
+	noun := "requests"
+	if len(slow) == 1 {
+		noun = "request"
+	}
+	names := make([]string, 0, maxSlowShown+1)
+	for i, req := range slow {
+		if i == maxSlowShown {
+			names = append(names, "…")
+			break
+		}
+		names = append(names, fmt.Sprintf("%s (%s)", req.URL, req.Elapsed.Round(time.Second)))
 	}
-	return fmt.Sprintf("%s Crawling... checked %d, broken %d\n%s\n",
-		m.spinner.View(), m.checked, m.broken,
-		dimStyle.Render("  "+m.current))
+	return theme.Category.Render(fmt.Sprintf("  %d %s > %s: %s", len(slow), noun, threshold, strings.Join(names, ", "))) + "\n"
 }
 
 // HasBrokenLinks reports whether the crawl found any broken links.
@@ -2,6 +2,7 @@
 package tui
 
 import (
+	"cmp"
 	"fmt"
 	"strings"
 
@@ -10,45 +11,43 @@ import (
 	"github.com/lukemcguire/zombiecrawl/result"
 )
 
-var (
-	titleStyle       = lipgloss.NewStyle().Bold(true)
-	successStyle     = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("10"))
-	errorStyle       = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("9"))
-	headerStyle      = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("12"))
-	categoryStyle    = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("11"))
-	dimStyle         = lipgloss.NewStyle().Faint(true)
-	urlStyle         = lipgloss.NewStyle()
-	statusErrorStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("9"))
-)
-
 // categoryOrder defines the display order for error categories (most to least actionable).
-var categoryOrder = []result.ErrorCategory{
-	result.Category4xx,
-	result.Category5xx,
-	result.CategoryTimeout,
-	result.CategoryDNSFailure,
-	result.CategoryConnectionRefused,
-	result.CategoryRedirectLoop,
-	result.CategoryUnknown,
-}
+var categoryOrder = result.CategoriesBySeverity()
 
-// RenderSummary produces a Lip Gloss styled summary of crawl results.
+// RenderSummary produces a Lip Gloss styled summary of crawl results, with
+// tables as wide as their contents.
 func RenderSummary(res *result.Result) string {
+	return RenderSummaryWith(res, SummaryOptions{})
+}
+
+// RenderSummaryWith produces the summary of RenderSummary laid out by opts.
+func RenderSummaryWith(res *result.Result, opts SummaryOptions) string {
 	if res == nil {
-		return errorStyle.Render("No results available.")
+		return theme.Error.Render("No results available.")
 	}
 
 	var builder strings.Builder
 
 	if len(res.BrokenLinks) == 0 {
-		builder.WriteString(successStyle.Render("No broken links found!"))
+		builder.WriteString(theme.Success.Render("No broken links found!"))
 		builder.WriteString("\n")
-		builder.WriteString(dimStyle.Render(fmt.Sprintf(
+		builder.WriteString(theme.Dim.Render(fmt.Sprintf(
 			"Checked %d URLs in %s",
 			res.Stats.TotalChecked,
 			res.Stats.Duration.Round(1_000_000), // round to ms
 		)))
 		builder.WriteString("\n")
+		renderFlaky(&builder, res.Flaky)
+		renderHygiene(&builder, res.Hygiene, opts)
+		renderAccessibility(&builder, res.Accessibility, opts)
+		renderStructure(&builder, res.Structure, opts)
+		renderSEO(&builder, res.SEO, opts)
+		renderFeeds(&builder, res.Feeds, opts)
+		renderContentChecks(&builder, res.ContentChecks, opts)
+		renderTruncated(&builder, res.Truncated, opts)
+		renderSkipped(&builder, res.Skipped, opts)
+		renderStreaming(&builder, res.Streaming, opts)
+		renderStatsDetails(&builder, res.Stats)
 		return builder.String()
 	}
 
@@ -70,17 +69,13 @@ func RenderSummary(res *result.Result) string {
 		}
 
 		// Category header
-		builder.WriteString(categoryStyle.Render(fmt.Sprintf("## %s (%d)", result.FormatCategory(cat), len(links))))
+		builder.WriteString(theme.Category.Render(fmt.Sprintf("## %s (%d)", result.FormatCategory(cat), len(links))))
 		builder.WriteString("\n")
 
 		// Build table for this category
 		rows := make([][]string, 0, len(links))
 		for _, link := range links {
-			status := fmt.Sprintf("%d", link.StatusCode)
-			if link.Error != "" {
-				status = link.Error
-			}
-			rows = append(rows, []string{link.URL, status, link.SourcePage})
+			rows = append(rows, []string{link.URL, result.FormatStatus(link), result.FormatSource(link)})
 		}
 
 		catTable := table.New().
@@ -88,27 +83,395 @@ func RenderSummary(res *result.Result) string {
 			Headers("URL", "Status", "Found On").
 			StyleFunc(func(row, col int) lipgloss.Style {
 				if row == table.HeaderRow {
-					return headerStyle
+					return theme.Header
 				}
 				if col == 1 { // Status column
-					return statusErrorStyle
+					return theme.StatusError
 				}
-				return urlStyle
+				return theme.URL
 			}).
-			Rows(rows...)
+			Rows(opts.fit(rows, "URL", "Status", "Found On")...)
 
 		builder.WriteString(catTable.Render())
 		builder.WriteString("\n\n")
 	}
 
+	renderHostTable(&builder, res.Hosts, opts)
+	renderArchived(&builder, res.BrokenLinks)
+	renderFlaky(&builder, res.Flaky)
+	renderHygiene(&builder, res.Hygiene, opts)
+	renderAccessibility(&builder, res.Accessibility, opts)
+	renderStructure(&builder, res.Structure, opts)
+	renderSEO(&builder, res.SEO, opts)
+	renderFeeds(&builder, res.Feeds, opts)
+	renderContentChecks(&builder, res.ContentChecks, opts)
+	renderTruncated(&builder, res.Truncated, opts)
+	renderSkipped(&builder, res.Skipped, opts)
+	renderStreaming(&builder, res.Streaming, opts)
+
 	// Summary stats
-	builder.WriteString(titleStyle.Render(fmt.Sprintf(
+	builder.WriteString(theme.Title.Render(fmt.Sprintf(
 		"Found %d broken links out of %d URLs checked (%s)",
 		res.Stats.BrokenCount,
 		res.Stats.TotalChecked,
 		res.Stats.Duration.Round(1_000_000),
 	)))
 	builder.WriteString("\n")
+	renderStatsDetails(&builder, res.Stats)
 
 	return builder.String()
 }
+
+// renderStatsDetails writes the dimmed stats breakdown lines, if any, and
+// the per-depth histogram in place of the plain text depth line.
+func renderStatsDetails(builder *strings.Builder, stats result.CrawlStats) {
+	byDepth := stats.ByDepth
+	stats.ByDepth = nil
+	for _, line := range result.StatsDetails(stats) {
+		builder.WriteString(theme.Dim.Render("  " + line))
+		builder.WriteString("\n")
+	}
+	renderDepthHistogram(builder, byDepth)
+}
+
+// depthBarWidth is the length of the bar for the depth with the most URLs.
+const depthBarWidth = 30
+
+// renderDepthHistogram writes one bar per crawl depth, scaled to the busiest
+// depth, with the broken share of each bar in the error color.
+func renderDepthHistogram(builder *strings.Builder, levels []result.DepthStats) {
+	if len(levels) == 0 {
+		return
+	}
+	busiest := 0
+	for _, level := range levels {
+		busiest = max(busiest, level.Checked)
+	}
+	if busiest == 0 {
+		return
+	}
+	builder.WriteString(theme.Dim.Render("  By depth:"))
+	builder.WriteString("\n")
+	for _, level := range levels {
+		width := (level.Checked*depthBarWidth + busiest - 1) / busiest
+		broken := 0
+		if level.Checked > 0 {
+			broken = (level.Broken*width + level.Checked - 1) / level.Checked
+		}
+		builder.WriteString(theme.Dim.Render(fmt.Sprintf("  %3d ", level.Depth)))
+		builder.WriteString(theme.StatusError.Render(strings.Repeat("█", broken)))
+		builder.WriteString(theme.URL.Render(strings.Repeat("█", width-broken)))
+		builder.WriteString(theme.Dim.Render(fmt.Sprintf(" %d checked, %d broken", level.Checked, level.Broken)))
+		builder.WriteString("\n")
+	}
+}
+
+// renderFlaky writes the links that recovered on re-verification, dimmed
+// since they are not counted as broken.
+func renderFlaky(builder *strings.Builder, flaky []result.LinkResult) {
+	if len(flaky) == 0 {
+		return
+	}
+	builder.WriteString(theme.Category.Render(fmt.Sprintf("## Flaky (%d)", len(flaky))))
+	builder.WriteString("\n")
+	for _, link := range flaky {
+		builder.WriteString(theme.Dim.Render("  " + link.URL + " (recovered on re-check)"))
+		builder.WriteString("\n")
+	}
+	builder.WriteString("\n")
+}
+
+// renderHygiene writes the link hygiene warnings as a table. They are not
+// broken links, so they follow the broken link sections.
+func renderHygiene(builder *strings.Builder, warnings []result.HygieneWarning, opts SummaryOptions) {
+	if len(warnings) == 0 {
+		return
+	}
+	builder.WriteString(theme.Category.Render(fmt.Sprintf("## Link Hygiene (%d)", len(warnings))))
+	builder.WriteString("\n")
+	rows := make([][]string, 0, len(warnings))
+	for _, warning := range warnings {
+		link := cmp.Or(warning.Href, warning.URL)
+		if warning.Target != "" {
+			link += " -> " + warning.Target
+		}
+		rows = append(rows, []string{result.FormatHygieneKind(warning.Kind), link, warning.SourcePage})
+	}
+	hygieneTable := table.New().
+		Border(lipgloss.RoundedBorder()).
+		Headers("Warning", "Link", "Found On").
+		StyleFunc(func(row, col int) lipgloss.Style {
+			if row == table.HeaderRow {
+				return theme.Header
+			}
+			return theme.URL
+		}).
+		Rows(opts.fit(rows, "Warning", "Link", "Found On")...)
+	builder.WriteString(hygieneTable.Render())
+	builder.WriteString("\n\n")
+}
+
+// renderAccessibility writes the accessibility issues as a table.
+func renderAccessibility(builder *strings.Builder, issues []result.AccessibilityIssue, opts SummaryOptions) {
+	if len(issues) == 0 {
+		return
+	}
+	builder.WriteString(theme.Category.Render(fmt.Sprintf("## Accessibility (%d)", len(issues))))
+	builder.WriteString("\n")
+	rows := make([][]string, 0, len(issues))
+	for _, issue := range issues {
+		rows = append(rows, []string{result.FormatAccessibilityKind(issue.Kind), issue.Target, issue.SourcePage})
+	}
+	issueTable := table.New().
+		Border(lipgloss.RoundedBorder()).
+		Headers("Issue", "Target", "Found On").
+		StyleFunc(func(row, col int) lipgloss.Style {
+			if row == table.HeaderRow {
+				return theme.Header
+			}
+			return theme.URL
+		}).
+		Rows(opts.fit(rows, "Issue", "Target", "Found On")...)
+	builder.WriteString(issueTable.Render())
+	builder.WriteString("\n\n")
+}
+
+// renderStructure writes the orphan pages and the pages with too many links
+// as tables.
+func renderStructure(builder *strings.Builder, structure *result.SiteStructure, opts SummaryOptions) {
+	if structure == nil {
+		return
+	}
+	if len(structure.Orphans) > 0 {
+		builder.WriteString(theme.Category.Render(fmt.Sprintf("## Orphan Pages (%d)", len(structure.Orphans))))
+		builder.WriteString("\n")
+		rows := make([][]string, 0, len(structure.Orphans))
+		for _, page := range structure.Orphans {
+			rows = append(rows, []string{page})
+		}
+		builder.WriteString(structureTable(opts, "Not Linked From Any Crawled Page", rows).Render())
+		builder.WriteString("\n\n")
+	}
+	if len(structure.HeavyPages) > 0 {
+		builder.WriteString(theme.Category.Render(fmt.Sprintf(
+			"## Pages With Over %d Links (%d)", structure.MaxOutboundLinks, len(structure.HeavyPages))))
+		builder.WriteString("\n")
+		rows := make([][]string, 0, len(structure.HeavyPages))
+		for _, page := range structure.HeavyPages {
+			rows = append(rows, []string{page.URL, fmt.Sprintf("%d", page.Outbound), fmt.Sprintf("%d", page.Inbound)})
+		}
+		builder.WriteString(structureTable(opts, "Page", rows, "Links Out", "Links In").Render())
+		builder.WriteString("\n\n")
+	}
+}
+
+// renderSEO writes the SEO issues as a table.
+func renderSEO(builder *strings.Builder, issues []result.SEOIssue, opts SummaryOptions) {
+	if len(issues) == 0 {
+		return
+	}
+	builder.WriteString(theme.Category.Render(fmt.Sprintf("## SEO (%d)", len(issues))))
+	builder.WriteString("\n")
+	rows := make([][]string, 0, len(issues))
+	for _, issue := range issues {
+		rows = append(rows, []string{result.FormatSEOKind(issue.Kind), issue.URL, issue.Detail})
+	}
+	issueTable := table.New().
+		Border(lipgloss.RoundedBorder()).
+		Headers("Issue", "Page", "Detail").
+		StyleFunc(func(row, col int) lipgloss.Style {
+			if row == table.HeaderRow {
+				return theme.Header
+			}
+			return theme.URL
+		}).
+		Rows(opts.fit(rows, "Issue", "Page", "Detail")...)
+	builder.WriteString(issueTable.Render())
+	builder.WriteString("\n\n")
+}
+
+// renderFeeds writes the feeds checked as a table, with a row for each dead
+// entry.
+func renderFeeds(builder *strings.Builder, feeds []result.Feed, opts SummaryOptions) {
+	if len(feeds) == 0 {
+		return
+	}
+	builder.WriteString(theme.Category.Render(fmt.Sprintf("## Feeds (%d)", len(feeds))))
+	builder.WriteString("\n")
+	var rows [][]string
+	for _, feed := range feeds {
+		switch {
+		case feed.Error != "":
+			rows = append(rows, []string{feed.URL, "", feed.Error})
+		case len(feed.DeadEntries) == 0:
+			rows = append(rows, []string{feed.URL, "", fmt.Sprintf("%d entries OK", feed.Entries)})
+		}
+		for _, entry := range feed.DeadEntries {
+			status := fmt.Sprintf("%d", entry.StatusCode)
+			if entry.Error != "" {
+				status = entry.Error
+			}
+			rows = append(rows, []string{feed.URL, entry.URL, status})
+		}
+	}
+	feedTable := table.New().
+		Border(lipgloss.RoundedBorder()).
+		Headers("Feed", "Entry", "Problem").
+		StyleFunc(func(row, col int) lipgloss.Style {
+			if row == table.HeaderRow {
+				return theme.Header
+			}
+			return theme.URL
+		}).
+		Rows(opts.fit(rows, "Feed", "Entry", "Problem")...)
+	builder.WriteString(feedTable.Render())
+	builder.WriteString("\n\n")
+}
+
+// renderContentChecks writes the content check failures as a table.
+func renderContentChecks(builder *strings.Builder, failures []result.ContentFailure, opts SummaryOptions) {
+	if len(failures) == 0 {
+		return
+	}
+	builder.WriteString(theme.Category.Render(fmt.Sprintf("## Content Checks (%d)", len(failures))))
+	builder.WriteString("\n")
+	rows := make([][]string, 0, len(failures))
+	for _, failure := range failures {
+		rows = append(rows, []string{failure.Check, failure.URL, failure.Reason})
+	}
+	failureTable := table.New().
+		Border(lipgloss.RoundedBorder()).
+		Headers("Check", "Page", "Problem").
+		StyleFunc(func(row, col int) lipgloss.Style {
+			if row == table.HeaderRow {
+				return theme.Header
+			}
+			return theme.URL
+		}).
+		Rows(opts.fit(rows, "Check", "Page", "Problem")...)
+	builder.WriteString(failureTable.Render())
+	builder.WriteString("\n\n")
+}
+
+// renderTruncated writes the pages cut off by the per-page link budget as a
+// table.
+func renderTruncated(builder *strings.Builder, pages []result.TruncatedPage, opts SummaryOptions) {
+	if len(pages) == 0 {
+		return
+	}
+	builder.WriteString(theme.Category.Render(fmt.Sprintf("## Pages Over the Link Budget (%d)", len(pages))))
+	builder.WriteString("\n")
+	rows := make([][]string, 0, len(pages))
+	for _, page := range pages {
+		rows = append(rows, []string{page.URL, fmt.Sprintf("%d", page.Queued), fmt.Sprintf("%d", page.Links)})
+	}
+	builder.WriteString(structureTable(opts, "Not Fully Crawled", rows, "Queued", "Links").Render())
+	builder.WriteString("\n\n")
+}
+
+// renderSkipped writes the links that were never requested as a table.
+func renderSkipped(builder *strings.Builder, links []result.SkippedLink, opts SummaryOptions) {
+	if len(links) == 0 {
+		return
+	}
+	builder.WriteString(theme.Category.Render(fmt.Sprintf("## Skipped, Never Requested (%d)", len(links))))
+	builder.WriteString("\n")
+	rows := make([][]string, 0, len(links))
+	for _, link := range links {
+		rows = append(rows, []string{link.URL, link.Reason, link.SourcePage})
+	}
+	builder.WriteString(structureTable(opts, "URL", rows, "Reason", "Found On").Render())
+	builder.WriteString("\n\n")
+}
+
+// renderStreaming writes the streaming responses that were read in part as a
+// table.
+func renderStreaming(builder *strings.Builder, resources []result.StreamingResource, opts SummaryOptions) {
+	if len(resources) == 0 {
+		return
+	}
+	builder.WriteString(theme.Category.Render(fmt.Sprintf("## Streaming, Read in Part (%d)", len(resources))))
+	builder.WriteString("\n")
+	rows := make([][]string, 0, len(resources))
+	for _, resource := range resources {
+		rows = append(rows, []string{resource.URL, resource.Reason, fmt.Sprintf("%d", resource.Bytes), resource.SourcePage})
+	}
+	builder.WriteString(structureTable(opts, "URL", rows, "Reason", "Bytes", "Found On").Render())
+	builder.WriteString("\n\n")
+}
+
+// structureTable returns a bordered table of pages with the given headers.
+func structureTable(opts SummaryOptions, header string, rows [][]string, more ...string) *table.Table {
+	headers := append([]string{header}, more...)
+	return table.New().
+		Border(lipgloss.RoundedBorder()).
+		Headers(headers...).
+		StyleFunc(func(row, col int) lipgloss.Style {
+			if row == table.HeaderRow {
+				return theme.Header
+			}
+			return theme.URL
+		}).
+		Rows(opts.fit(rows, headers...)...)
+}
+
+// renderArchived writes the suggested Wayback Machine replacements for dead
+// links that have one.
+func renderArchived(builder *strings.Builder, links []result.LinkResult) {
+	var lines []string
+	for _, link := range links {
+		if link.ArchiveURL != "" {
+			lines = append(lines, "  "+link.URL+" -> "+link.ArchiveURL)
+		}
+	}
+	if len(lines) == 0 {
+		return
+	}
+	builder.WriteString(theme.Category.Render(fmt.Sprintf("## Archived Copies (%d)", len(lines))))
+	builder.WriteString("\n")
+	for _, line := range lines {
+		builder.WriteString(theme.URL.Render(line))
+		builder.WriteString("\n")
+	}
+	builder.WriteString("\n")
+}
+
+// renderHostTable writes a table of external hosts that produced broken links,
+// so failures concentrated on one host stand out.
+func renderHostTable(builder *strings.Builder, hosts []result.HostSummary, opts SummaryOptions) {
+	rows := make([][]string, 0, len(hosts))
+	for _, host := range hosts {
+		if host.Broken == 0 {
+			continue
+		}
+		rows = append(rows, []string{
+			host.Host,
+			fmt.Sprintf("%d", host.Links),
+			fmt.Sprintf("%d", host.Broken),
+			result.FormatCategory(host.DominantCategory),
+		})
+	}
+	if len(rows) == 0 {
+		return
+	}
+
+	builder.WriteString(theme.Category.Render(fmt.Sprintf("## External Hosts (%d)", len(rows))))
+	builder.WriteString("\n")
+
+	hostTable := table.New().
+		Border(lipgloss.RoundedBorder()).
+		Headers("Host", "Links", "Broken", "Top Error").
+		StyleFunc(func(row, col int) lipgloss.Style {
+			if row == table.HeaderRow {
+				return theme.Header
+			}
+			if col == 2 { // Broken column
+				return theme.StatusError
+			}
+			return theme.URL
+		}).
+		Rows(opts.fit(rows, "Host", "Links", "Broken", "Top Error")...)
+
+	builder.WriteString(hostTable.Render())
+	builder.WriteString("\n\n")
+}
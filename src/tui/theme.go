@@ -0,0 +1,117 @@
+package tui
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Theme holds the styles the TUI renders with.
+type Theme struct {
+	Name        string         // Theme name used with LookupTheme
+	Title       lipgloss.Style // Summary headline
+	Success     lipgloss.Style // "No broken links found!"
+	Error       lipgloss.Style // Errors and missing results
+	Header      lipgloss.Style // Table header rows
+	Category    lipgloss.Style // Section headings and warnings
+	Dim         lipgloss.Style // Secondary details
+	URL         lipgloss.Style // Table cells
+	StatusError lipgloss.Style // Failing statuses and broken counts
+	Spinner     lipgloss.Style // Progress spinner
+}
+
+// themes lists the built-in themes by name.
+var themes = map[string]Theme{
+	"dark": {
+		Name:        "dark",
+		Title:       lipgloss.NewStyle().Bold(true),
+		Success:     lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("10")),
+		Error:       lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("9")),
+		Header:      lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("12")),
+		Category:    lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("11")),
+		Dim:         lipgloss.NewStyle().Faint(true),
+		URL:         lipgloss.NewStyle(),
+		StatusError: lipgloss.NewStyle().Foreground(lipgloss.Color("9")),
+		Spinner:     lipgloss.NewStyle().Foreground(lipgloss.Color("205")),
+	},
+	// Darker shades that stay readable on a white background, where the
+	// bright colors of dark, yellow above all, wash out.
+	"light": {
+		Name:        "light",
+		Title:       lipgloss.NewStyle().Bold(true),
+		Success:     lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("28")),
+		Error:       lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("160")),
+		Header:      lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("25")),
+		Category:    lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("130")),
+		Dim:         lipgloss.NewStyle().Foreground(lipgloss.Color("243")),
+		URL:         lipgloss.NewStyle(),
+		StatusError: lipgloss.NewStyle().Foreground(lipgloss.Color("160")),
+		Spinner:     lipgloss.NewStyle().Foreground(lipgloss.Color("127")),
+	},
+	// The 16 basic colors, which terminals with high-contrast palettes
+	// remap, never faint, and with emphasis that does not rely on color.
+	"high-contrast": {
+		Name:        "high-contrast",
+		Title:       lipgloss.NewStyle().Bold(true).Underline(true),
+		Success:     lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("10")),
+		Error:       lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("9")),
+		Header:      lipgloss.NewStyle().Bold(true).Underline(true).Foreground(lipgloss.Color("15")),
+		Category:    lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("11")),
+		Dim:         lipgloss.NewStyle(),
+		URL:         lipgloss.NewStyle(),
+		StatusError: lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("9")),
+		Spinner:     lipgloss.NewStyle().Bold(true),
+	},
+	// Text attributes only, for NO_COLOR and output that is not a terminal.
+	"none": {
+		Name:        "none",
+		Title:       lipgloss.NewStyle().Bold(true),
+		Success:     lipgloss.NewStyle().Bold(true),
+		Error:       lipgloss.NewStyle().Bold(true),
+		Header:      lipgloss.NewStyle().Bold(true),
+		Category:    lipgloss.NewStyle().Bold(true),
+		Dim:         lipgloss.NewStyle(),
+		URL:         lipgloss.NewStyle(),
+		StatusError: lipgloss.NewStyle(),
+		Spinner:     lipgloss.NewStyle(),
+	},
+}
+
+// theme is the Theme the TUI renders with, set by UseTheme.
+var theme = themes["dark"]
+
+// ThemeNames returns the built-in theme names, sorted.
+func ThemeNames() []string {
+	names := make([]string, 0, len(themes))
+	for name := range themes {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// LookupTheme returns the built-in theme with the given name (case-insensitive).
+func LookupTheme(name string) (Theme, error) {
+	t, ok := themes[strings.ToLower(name)]
+	if !ok {
+		return Theme{}, fmt.Errorf("unknown theme %q (want one of %s)", name, strings.Join(ThemeNames(), ", "))
+	}
+	return t, nil
+}
+
+// UseTheme makes the TUI render with t. Call it before NewModel and any
+// rendering; the default is the dark theme.
+func UseTheme(t Theme) {
+	theme = t
+}
+
+// AutoTheme returns the dark or light theme, whichever suits the terminal's
+// background color.
+func AutoTheme() Theme {
+	if lipgloss.HasDarkBackground() {
+		return themes["dark"]
+	}
+	return themes["light"]
+}
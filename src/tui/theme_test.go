@@ -0,0 +1,55 @@
+package tui
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/lukemcguire/zombiecrawl/result"
+)
+
+func TestLookupTheme(t *testing.T) {
+	names := ThemeNames()
+	if !slices.IsSorted(names) || !slices.Equal(names, []string{"dark", "high-contrast", "light", "none"}) {
+		t.Errorf("ThemeNames() = %v, want dark, high-contrast, light, none", names)
+	}
+	if got, err := LookupTheme("High-Contrast"); err != nil || got.Name != "high-contrast" {
+		t.Errorf("LookupTheme(High-Contrast) = %q, %v, want high-contrast", got.Name, err)
+	}
+	if _, err := LookupTheme("sepia"); err == nil || !strings.Contains(err.Error(), "dark, high-contrast") {
+		t.Errorf("LookupTheme(sepia) error = %v, want one listing the themes", err)
+	}
+}
+
+func TestThemeNone_NoColors(t *testing.T) {
+	none, _ := LookupTheme("none")
+	for _, style := range []lipgloss.Style{
+		none.Title, none.Success, none.Error, none.Header, none.Category,
+		none.Dim, none.URL, none.StatusError, none.Spinner,
+	} {
+		if _, ok := style.GetForeground().(lipgloss.NoColor); !ok {
+			t.Errorf("style %v sets a foreground color", style)
+		}
+		if style.GetFaint() {
+			t.Errorf("style %v is faint", style)
+		}
+	}
+}
+
+func TestUseTheme(t *testing.T) {
+	defer UseTheme(theme)
+	highContrast, _ := LookupTheme("high-contrast")
+	UseTheme(highContrast)
+
+	if theme.Name != "high-contrast" {
+		t.Fatalf("theme = %q, want high-contrast", theme.Name)
+	}
+	if model := NewModel(t.Context(), func() {}, nil, nil); !model.spinner.Style.GetBold() {
+		t.Error("spinner does not use the high-contrast theme")
+	}
+	output := RenderSummary(&result.Result{Stats: result.CrawlStats{TotalChecked: 1}})
+	if !strings.Contains(output, "No broken links found!") {
+		t.Errorf("expected summary rendered with the theme, got: %s", output)
+	}
+}
@@ -2,12 +2,15 @@ package tui
 
 import (
 	"context"
+	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/charmbracelet/bubbles/spinner"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
 	"github.com/lukemcguire/zombiecrawl/crawler"
 	"github.com/lukemcguire/zombiecrawl/result"
 )
@@ -194,6 +197,187 @@ func TestRenderSummary_WithBrokenLinks(t *testing.T) {
 	}
 }
 
+// TestRenderSummary_HostTable verifies that external hosts with broken links
+// get their own table, while healthy hosts are left out.
+func TestRenderSummary_HostTable(t *testing.T) {
+	res := &result.Result{
+		BrokenLinks: []result.LinkResult{
+			{URL: "https://cdn.old-vendor.com/a.js", Error: "timeout", ErrorCategory: result.CategoryTimeout, IsExternal: true},
+		},
+		Hosts: []result.HostSummary{
+			{Host: "cdn.old-vendor.com", Links: 40, Broken: 1, DominantCategory: result.CategoryTimeout},
+			{Host: "healthy.example.org", Links: 3, Broken: 0},
+		},
+		Stats: result.CrawlStats{TotalChecked: 43, BrokenCount: 1},
+	}
+	output := RenderSummary(res)
+	if !containsSubstring(output, "External Hosts (1)") {
+		t.Errorf("expected host table header, got: %s", output)
+	}
+	if !containsSubstring(output, "cdn.old-vendor.com") {
+		t.Errorf("expected failing host in output, got: %s", output)
+	}
+	if containsSubstring(output, "healthy.example.org") {
+		t.Errorf("expected healthy host to be omitted, got: %s", output)
+	}
+}
+
+func TestRenderSummary_Flaky(t *testing.T) {
+	res := &result.Result{
+		Flaky: []result.LinkResult{{URL: "https://example.com/blip", StatusCode: 503}},
+		Stats: result.CrawlStats{TotalChecked: 4, FlakyCount: 1},
+	}
+	output := RenderSummary(res)
+	if !containsSubstring(output, "No broken links found!") {
+		t.Errorf("flaky links should not count as broken, got: %s", output)
+	}
+	if !containsSubstring(output, "Flaky (1)") || !containsSubstring(output, "https://example.com/blip") {
+		t.Errorf("expected flaky section, got: %s", output)
+	}
+}
+
+func TestRenderSummary_ArchivedCopies(t *testing.T) {
+	res := &result.Result{
+		BrokenLinks: []result.LinkResult{
+			{URL: "https://gone.example/page", StatusCode: 404, ErrorCategory: result.Category4xx, IsExternal: true,
+				ArchiveURL: "http://web.archive.org/web/2020/https://gone.example/page"},
+			{URL: "https://gone.example/other", StatusCode: 404, ErrorCategory: result.Category4xx, IsExternal: true},
+		},
+		Stats: result.CrawlStats{TotalChecked: 3, BrokenCount: 2},
+	}
+	output := RenderSummary(res)
+	if !containsSubstring(output, "Archived Copies (1)") || !containsSubstring(output, "http://web.archive.org/web/2020/https://gone.example/page") {
+		t.Errorf("expected archived copies section, got: %s", output)
+	}
+}
+
+func TestRenderSummary_Hygiene(t *testing.T) {
+	res := &result.Result{
+		Hygiene: []result.HygieneWarning{
+			{Kind: result.HygieneMixedContent, URL: "http://cdn.example/a.js", Href: "http://cdn.example/a.js", SourcePage: "https://example.com/"},
+		},
+		Stats: result.CrawlStats{TotalChecked: 2},
+	}
+	output := RenderSummary(res)
+	if !containsSubstring(output, "Link Hygiene (1)") || !containsSubstring(output, "Mixed content") {
+		t.Errorf("expected link hygiene section, got: %s", output)
+	}
+}
+
+func TestRenderSummary_Accessibility(t *testing.T) {
+	res := &result.Result{
+		Accessibility: []result.AccessibilityIssue{
+			{Kind: result.AccessibilityEmptyAnchor, Target: "https://example.com/a", SourcePage: "https://example.com/"},
+		},
+		Stats: result.CrawlStats{TotalChecked: 2},
+	}
+	output := RenderSummary(res)
+	if !containsSubstring(output, "Accessibility (1)") || !containsSubstring(output, "Link without text") {
+		t.Errorf("expected accessibility section, got: %s", output)
+	}
+}
+
+func TestRenderSummary_DepthHistogram(t *testing.T) {
+	res := &result.Result{
+		Stats: result.CrawlStats{
+			TotalChecked:    11,
+			InternalChecked: 11,
+			ByDepth:         []result.DepthStats{{Depth: 0, Checked: 1}, {Depth: 1, Checked: 10, Broken: 5}},
+		},
+	}
+	output := RenderSummary(res)
+	if !containsSubstring(output, "By depth:") || !containsSubstring(output, "10 checked, 5 broken") {
+		t.Errorf("expected depth histogram, got: %s", output)
+	}
+	if containsSubstring(output, "broken/checked") {
+		t.Errorf("histogram should replace the plain depth line, got: %s", output)
+	}
+}
+
+func TestRenderSummary_Structure(t *testing.T) {
+	res := &result.Result{
+		Structure: &result.SiteStructure{
+			Orphans:          []string{"https://example.com/lost"},
+			HeavyPages:       []result.PageLinks{{URL: "https://example.com/", Outbound: 150}},
+			MaxOutboundLinks: 100,
+		},
+		Stats: result.CrawlStats{TotalChecked: 2},
+	}
+	output := RenderSummary(res)
+	if !containsSubstring(output, "Orphan Pages (1)") || !containsSubstring(output, "Pages With Over 100 Links (1)") {
+		t.Errorf("expected site structure sections, got: %s", output)
+	}
+}
+
+func TestRenderSummary_Truncated(t *testing.T) {
+	res := &result.Result{
+		Truncated: []result.TruncatedPage{{URL: "https://example.com/tags", Links: 50000, Queued: 1000}},
+		Stats:     result.CrawlStats{TotalChecked: 1001},
+	}
+	output := RenderSummary(res)
+	if !containsSubstring(output, "Pages Over the Link Budget (1)") || !containsSubstring(output, "https://example.com/tags") {
+		t.Errorf("expected link budget section, got: %s", output)
+	}
+}
+
+func TestRenderSummary_Skipped(t *testing.T) {
+	res := &result.Result{
+		Skipped: []result.SkippedLink{{URL: "https://stats.example.net/pixel.gif", SourcePage: "https://example.com/", Reason: "host matches blocked pattern"}},
+		Stats:   result.CrawlStats{TotalChecked: 1},
+	}
+	output := RenderSummary(res)
+	if !containsSubstring(output, "Skipped, Never Requested (1)") || !containsSubstring(output, "https://stats.example.net/pixel.gif") {
+		t.Errorf("expected skipped section, got: %s", output)
+	}
+}
+
+func TestRenderSummary_Streaming(t *testing.T) {
+	res := &result.Result{
+		Streaming: []result.StreamingResource{{URL: "https://example.com/live", SourcePage: "https://example.com/", Reason: "event stream"}},
+		Stats:     result.CrawlStats{TotalChecked: 2},
+	}
+	output := RenderSummary(res)
+	if !containsSubstring(output, "Streaming, Read in Part (1)") || !containsSubstring(output, "https://example.com/live") {
+		t.Errorf("expected streaming section, got: %s", output)
+	}
+}
+
+func TestRenderSummary_SEO(t *testing.T) {
+	res := &result.Result{
+		SEO:   []result.SEOIssue{{Kind: result.SEODuplicateTitle, URL: "https://example.com/copy", Detail: "Home"}},
+		Stats: result.CrawlStats{TotalChecked: 1},
+	}
+	output := RenderSummary(res)
+	if !containsSubstring(output, "SEO (1)") || !containsSubstring(output, "Duplicate title") {
+		t.Errorf("expected SEO section, got: %s", output)
+	}
+}
+
+func TestRenderSummary_Feeds(t *testing.T) {
+	res := &result.Result{
+		Feeds: []result.Feed{
+			{URL: "https://example.com/feed.xml", Entries: 2, DeadEntries: []result.LinkResult{{URL: "https://example.com/old-post", StatusCode: 404}}},
+			{URL: "https://example.com/atom.xml", Entries: 5},
+		},
+		Stats: result.CrawlStats{TotalChecked: 1},
+	}
+	output := RenderSummary(res)
+	if !containsSubstring(output, "Feeds (2)") || !containsSubstring(output, "old-post") || !containsSubstring(output, "5 entries OK") {
+		t.Errorf("expected feeds section, got: %s", output)
+	}
+}
+
+func TestRenderSummary_ContentChecks(t *testing.T) {
+	res := &result.Result{
+		ContentChecks: []result.ContentFailure{{Check: "analytics", URL: "https://example.com/", Reason: `does not contain "gtag("`}},
+		Stats:         result.CrawlStats{TotalChecked: 1},
+	}
+	output := RenderSummary(res)
+	if !containsSubstring(output, "Content Checks (1)") || !containsSubstring(output, "analytics") {
+		t.Errorf("expected content checks section, got: %s", output)
+	}
+}
+
 // TestInit_ReturnsBatchCmd verifies that Init returns a batch command for
 // starting the crawl and spinner.
 func TestInit_ReturnsBatchCmd(t *testing.T) {
@@ -239,6 +423,89 @@ func TestUpdate_CrawlProgressMsg(t *testing.T) {
 	}
 }
 
+// TestUpdate_CheckDetail verifies that the progress view describes the
+// check of the current URL.
+func TestUpdate_CheckDetail(t *testing.T) {
+	model := Model{progressCh: make(chan crawler.CrawlEvent, 10)}
+	msg := CrawlProgressMsg{
+		Checked:    3,
+		URL:        "https://example.com/page",
+		StatusCode: 200,
+		Depth:      2,
+		Attempts:   3,
+		Bytes:      14540,
+		Duration:   120 * time.Millisecond,
+	}
+	updatedModel, _ := model.Update(msg)
+	if view := updatedModel.(Model).View(); !containsSubstring(view, "https://example.com/page (200, depth 2, 3 attempts, 14.2 KiB, 120ms)") {
+		t.Errorf("expected check detail in view, got: %s", view)
+	}
+
+	// Events that are not checks, e.g. skipped links, have no detail
+	updatedModel, _ = updatedModel.(Model).Update(CrawlProgressMsg{Checked: 3, URL: "https://example.com/skipped"})
+	if view := updatedModel.(Model).View(); containsSubstring(view, "skipped (") {
+		t.Errorf("expected no check detail in view, got: %s", view)
+	}
+}
+
+// TestUpdate_SlowRequests verifies that in-flight status events update the
+// slow request list without replacing the current URL.
+func TestUpdate_SlowRequests(t *testing.T) {
+	model := Model{current: "https://example.com/page", slowAfter: 30 * time.Second}
+	slow := []crawler.InFlightRequest{
+		{URL: "https://example.com/hung", Elapsed: 45 * time.Second},
+		{URL: "https://example.com/slow", Elapsed: 31 * time.Second},
+	}
+
+	updatedModel, _ := model.Update(CrawlProgressMsg{Checked: 7, Slow: slow})
+	updated := updatedModel.(Model)
+	if updated.current != "https://example.com/page" {
+		t.Errorf("current = %q, want it kept on status events", updated.current)
+	}
+	view := updated.View()
+	if !containsSubstring(view, "2 requests > 30s: https://example.com/hung (45s), https://example.com/slow (31s)") {
+		t.Errorf("expected slow request line in view, got: %s", view)
+	}
+
+	updatedModel, _ = updated.Update(CrawlProgressMsg{Checked: 8, Slow: []crawler.InFlightRequest{}})
+	if view := updatedModel.(Model).View(); containsSubstring(view, "requests >") {
+		t.Errorf("expected slow request line cleared, got: %s", view)
+	}
+}
+
+// TestUpdate_BrokenFeed verifies that the progress view lists the most
+// recent broken links with their categories, dropping the oldest.
+func TestUpdate_BrokenFeed(t *testing.T) {
+	model := Model{}.WithBrokenFeed(2)
+	for i, link := range []result.LinkResult{
+		{URL: "https://example.com/first", StatusCode: 404, ErrorCategory: result.Category4xx, SourcePage: "https://example.com/"},
+		{URL: "https://example.com/second", StatusCode: 500, ErrorCategory: result.Category5xx, SourcePage: "https://example.com/"},
+		{URL: "https://slow.example/", Error: "timeout", ErrorCategory: result.CategoryTimeout, SourcePage: "https://example.com/"},
+	} {
+		updatedModel, _ := model.Update(CrawlProgressMsg{Checked: i + 1, Broken: i + 1, URL: link.URL, BrokenLinks: []result.LinkResult{link}})
+		model = updatedModel.(Model)
+	}
+	updatedModel, _ := model.Update(CrawlProgressMsg{Checked: 4, Broken: 3, URL: "https://example.com/ok"})
+	view := updatedModel.(Model).View()
+
+	if containsSubstring(view, "example.com/first") {
+		t.Errorf("expected the oldest broken link dropped, got: %s", view)
+	}
+	for _, want := range []string{
+		"✗ 5xx https://example.com/second (500) on https://example.com/",
+		"✗ timeout https://slow.example/ (timeout) on https://example.com/",
+	} {
+		if !containsSubstring(view, want) {
+			t.Errorf("expected %q in view, got: %s", want, view)
+		}
+	}
+
+	updatedModel, _ = Model{}.WithBrokenFeed(0).Update(CrawlProgressMsg{BrokenLinks: model.recent})
+	if view := updatedModel.(Model).View(); containsSubstring(view, "✗") {
+		t.Errorf("expected no broken links listed with a feed of 0, got: %s", view)
+	}
+}
+
 // TestUpdate_CrawlDoneMsg verifies that Update handles crawl completion and
 // stores the result.
 func TestUpdate_CrawlDoneMsg(t *testing.T) {
@@ -259,6 +526,61 @@ func TestUpdate_CrawlDoneMsg(t *testing.T) {
 	}
 }
 
+// TestWait_AfterQuit verifies that Wait blocks until a crawl cancelled by q
+// has returned, and hands back its partial result.
+func TestWait_AfterQuit(t *testing.T) {
+	release := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/" {
+			<-release
+		}
+		http.NotFound(w, r)
+	}))
+	defer ts.Close()
+	defer close(release)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	cfg := crawler.DefaultConfig(ts.URL)
+	cfg.Delay = 1
+	model := NewModel(ctx, cancel, mustNewCrawler(t, cfg, nil), nil)
+	// The Bubble Tea runtime runs the command, and drops its message once
+	// the program has quit
+	go model.startCrawl()()
+
+	quit, _ := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("q")})
+	finished := quit.(Model).Wait()
+	if !finished.done || finished.GetResult() == nil {
+		t.Errorf("Wait() = done %v, result %v; want the cancelled crawl's result", finished.done, finished.GetResult())
+	}
+	if finished.Wait().GetResult() != finished.GetResult() {
+		t.Error("Wait() on a finished model should return it unchanged")
+	}
+}
+
+// TestUpdate_CrawlDoneMsgSorted verifies that WithSort orders the result's
+// broken links, in the summary and for GetResult.
+func TestUpdate_CrawlDoneMsgSorted(t *testing.T) {
+	model := Model{}.WithSort(result.SortURL)
+	res := &result.Result{
+		BrokenLinks: []result.LinkResult{
+			{URL: "https://example.com/zebra", StatusCode: 404, ErrorCategory: result.Category4xx},
+			{URL: "https://example.com/aardvark", StatusCode: 404, ErrorCategory: result.Category4xx},
+		},
+		Stats: result.CrawlStats{TotalChecked: 3, BrokenCount: 2},
+	}
+
+	updatedModel, _ := model.Update(CrawlDoneMsg{Result: res})
+	updated := updatedModel.(Model)
+	if got := updated.GetResult().BrokenLinks[0].URL; got != "https://example.com/aardvark" {
+		t.Errorf("first broken link = %s, want aardvark", got)
+	}
+	view := updated.View()
+	if strings.Index(view, "aardvark") > strings.Index(view, "zebra") {
+		t.Errorf("expected aardvark listed before zebra, got: %s", view)
+	}
+}
+
 // TestUpdate_SpinnerTickMsg verifies that Update handles spinner tick messages.
 func TestUpdate_SpinnerTickMsg(t *testing.T) {
 	model := Model{}
@@ -311,6 +633,78 @@ func TestView_DoneWithResult(t *testing.T) {
 	}
 }
 
+// TestRenderSummaryWith_Width verifies that tables are shortened to fit the
+// terminal width, keeping both ends of long URLs, unless FullURLs is set.
+func TestRenderSummaryWith_Width(t *testing.T) {
+	long := "https://example.com/" + strings.Repeat("deep/", 20) + "dead-page"
+	res := &result.Result{
+		BrokenLinks: []result.LinkResult{
+			{URL: long, StatusCode: 404, SourcePage: "https://example.com/" + strings.Repeat("source/", 10)},
+		},
+		Stats: result.CrawlStats{TotalChecked: 2, BrokenCount: 1},
+	}
+
+	output := RenderSummaryWith(res, SummaryOptions{Width: 80})
+	for _, line := range strings.Split(output, "\n") {
+		if width := lipgloss.Width(line); width > 80 {
+			t.Errorf("line is %d cells wide, want at most 80: %q", width, line)
+		}
+	}
+	if strings.Contains(output, long) {
+		t.Errorf("expected %s to be shortened, got: %s", long, output)
+	}
+	for _, want := range []string{"https://example.com/", "dead-page", "…", "404"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("expected %q in output, got: %s", want, output)
+		}
+	}
+
+	output = RenderSummaryWith(res, SummaryOptions{Width: 80, FullURLs: true})
+	if !strings.Contains(output, long) {
+		t.Errorf("expected %s in full with FullURLs, got: %s", long, output)
+	}
+}
+
+// TestShorten verifies that shorten cuts the middle of long strings only.
+func TestShorten(t *testing.T) {
+	tests := []struct {
+		in    string
+		limit int
+		want  string
+	}{
+		{"https://example.com/", 40, "https://example.com/"},
+		{"abcdefghijklmnopqrst", 10, "abcde…qrst"},
+		{"héllo wörld", 6, "hél…ld"},
+	}
+	for _, tt := range tests {
+		if got := shorten(tt.in, tt.limit); got != tt.want {
+			t.Errorf("shorten(%q, %d) = %q, want %q", tt.in, tt.limit, got, tt.want)
+		}
+	}
+}
+
+// TestUpdate_ToggleFullURLs verifies that the u key toggles full URLs in the
+// summary and on the progress line.
+func TestUpdate_ToggleFullURLs(t *testing.T) {
+	long := "https://example.com/" + strings.Repeat("deep/", 20) + "checking"
+	model := Model{width: 60, current: long}
+	if output := model.View(); strings.Contains(output, long) {
+		t.Errorf("expected current URL shortened to the width, got: %s", output)
+	}
+
+	updatedModel, _ := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("u")})
+	updated := updatedModel.(Model)
+	if !updated.fullURLs {
+		t.Fatal("expected u to show full URLs")
+	}
+	if output := updated.View(); !strings.Contains(output, long) {
+		t.Errorf("expected current URL in full, got: %s", output)
+	}
+	if !NewModel(context.Background(), func() {}, nil, nil).WithFullURLs(true).fullURLs {
+		t.Error("WithFullURLs(true) did not set fullURLs")
+	}
+}
+
 // TestView_DoneWithError verifies that View shows an error message when crawl
 // completes with an error.
 func TestView_DoneWithError(t *testing.T) {
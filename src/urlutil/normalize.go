@@ -7,15 +7,26 @@ import (
 	"strings"
 )
 
+// ErrSuspiciousURL marks URLs that NormalizeStrict rejects because browsers
+// and servers may disagree on what they point to.
+var ErrSuspiciousURL = errors.New("suspicious URL")
+
 // Normalize takes a raw URL string and returns a normalized version.
 // Normalization includes:
-// - Lowercasing the scheme and host
+// - Trimming surrounding spaces and control characters, and removing tabs and newlines
+// - Treating backslashes as slashes in http and https URLs, as browsers do
+// - Lowercasing the scheme and host, and dropping the default port
 // - Stripping fragments (#section)
 // - Stripping trailing slashes (except for root path "/")
+// - Uppercasing percent-encodings and decoding unreserved characters (%7E -> ~)
 // - Preserving query parameters
 //
-// Returns an error if the input is empty or cannot be parsed as a valid URL.
+// Normalize never panics, and normalizing its output returns the output
+// unchanged. Returns an error if the input is empty or cannot be parsed as a
+// valid URL.
 func Normalize(rawURL string) (string, error) {
+	rawURL = Sanitize(rawURL)
+
 	// Empty string is invalid
 	if rawURL == "" {
 		return "", errors.New("cannot normalize empty URL")
@@ -28,22 +39,166 @@ func Normalize(rawURL string) (string, error) {
 	}
 
 	// Validate that we have at least a scheme and host
-	if parsed.Scheme == "" || parsed.Host == "" {
+	if parsed.Scheme == "" || parsed.Host == "" || parsed.Opaque != "" {
 		return "", errors.New("URL must have both scheme and host")
 	}
 
-	// Lowercase the scheme and host
+	// Lowercase the scheme and host, dropping the scheme's default port
 	parsed.Scheme = strings.ToLower(parsed.Scheme)
 	parsed.Host = strings.ToLower(parsed.Host)
+	if port := parsed.Port(); (parsed.Scheme == "http" && port == "80") || (parsed.Scheme == "https" && port == "443") {
+		parsed.Host = strings.TrimSuffix(parsed.Host, ":"+port)
+	}
 
 	// Strip the fragment
 	parsed.Fragment = ""
+	parsed.RawFragment = ""
 
-	// Strip trailing slash from path, unless it's the root path "/"
-	if parsed.Path != "/" && strings.HasSuffix(parsed.Path, "/") {
-		parsed.Path = strings.TrimSuffix(parsed.Path, "/")
+	// Canonical percent-encoding, then strip trailing slashes from the path
+	// unless it is the root path "/"
+	path := normalizeEscapes(parsed.EscapedPath())
+	if trimmed := strings.TrimRight(path, "/"); trimmed != path {
+		path = trimmed
+		if path == "" {
+			path = "/"
+		}
+	}
+	if parsed.Path, err = url.PathUnescape(path); err != nil {
+		return "", fmt.Errorf("normalize URL %q: %w", rawURL, err)
 	}
+	parsed.RawPath = path
+	parsed.RawQuery = normalizeEscapes(parsed.RawQuery)
 
 	// Return the normalized URL string
 	return parsed.String(), nil
 }
+
+// NormalizeStrict is Normalize for crawls that reject ambiguous links
+// instead of guessing what they mean. URLs containing control characters,
+// whitespace, backslashes, invalid or double percent-encoding, or
+// credentials fail with an error wrapping ErrSuspiciousURL.
+func NormalizeStrict(rawURL string) (string, error) {
+	if reason := Suspicious(rawURL); reason != "" {
+		return "", fmt.Errorf("%w: %s", ErrSuspiciousURL, reason)
+	}
+	normalized, err := Normalize(rawURL)
+	if err != nil {
+		return "", err
+	}
+	if parsed, err := url.Parse(normalized); err == nil && parsed.User != nil {
+		return "", fmt.Errorf("%w: credentials in URL", ErrSuspiciousURL)
+	}
+	return normalized, nil
+}
+
+// Suspicious returns why NormalizeStrict would reject rawURL as written, or
+// "" if it would not. Unlike NormalizeStrict it also checks relative links.
+func Suspicious(rawURL string) string {
+	for i := 0; i < len(rawURL); i++ {
+		switch c := rawURL[i]; {
+		case c < 0x20 || c == 0x7f:
+			return "control character in URL"
+		case c == ' ':
+			return "space in URL"
+		case c == '\\':
+			return "backslash in URL"
+		case c == '%':
+			if i+2 >= len(rawURL) || !isHex(rawURL[i+1]) || !isHex(rawURL[i+2]) {
+				return "invalid percent-encoding"
+			}
+			// %25 followed by an escape is an escape encoded twice
+			if rawURL[i+1] == '2' && rawURL[i+2] == '5' && i+4 < len(rawURL) && isHex(rawURL[i+3]) && isHex(rawURL[i+4]) {
+				return "double percent-encoding"
+			}
+		}
+	}
+	if _, authority, ok := strings.Cut(rawURL, "//"); ok {
+		authority, _, _ = strings.Cut(authority, "/")
+		authority, _, _ = strings.Cut(authority, "?")
+		authority, _, _ = strings.Cut(authority, "#")
+		if strings.Contains(authority, "@") {
+			return "credentials in URL"
+		}
+	}
+	return ""
+}
+
+// Sanitize applies the clean-up browsers perform on a URL or href before
+// parsing it: surrounding spaces and control characters are trimmed, tabs and
+// newlines removed, and backslashes before the query of an http, https, or
+// relative URL become slashes.
+func Sanitize(rawURL string) string {
+	rawURL = strings.TrimFunc(rawURL, func(r rune) bool { return r <= ' ' })
+	rawURL = strings.NewReplacer("\t", "", "\n", "", "\r", "").Replace(rawURL)
+	if scheme, _, ok := strings.Cut(rawURL, ":"); ok && isScheme(scheme) &&
+		!strings.EqualFold(scheme, "http") && !strings.EqualFold(scheme, "https") {
+		return rawURL
+	}
+	end := strings.IndexAny(rawURL, "?#")
+	if end < 0 {
+		end = len(rawURL)
+	}
+	return strings.ReplaceAll(rawURL[:end], `\`, "/") + rawURL[end:]
+}
+
+// isScheme reports whether s is a syntactically valid URL scheme.
+func isScheme(s string) bool {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z':
+		case i > 0 && ('0' <= c && c <= '9' || c == '+' || c == '-' || c == '.'):
+		default:
+			return false
+		}
+	}
+	return s != ""
+}
+
+// normalizeEscapes uppercases the hex digits of percent-encodings, decodes
+// the ones that encode unreserved characters (RFC 3986 section 6.2.2), and
+// encodes spaces, controls, and non-ASCII bytes.
+func normalizeEscapes(s string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+	for i := 0; i < len(s); i++ {
+		if s[i] <= ' ' || s[i] >= 0x7f {
+			fmt.Fprintf(&b, "%%%02X", s[i])
+			continue
+		}
+		if s[i] != '%' || i+2 >= len(s) || !isHex(s[i+1]) || !isHex(s[i+2]) {
+			b.WriteByte(s[i])
+			continue
+		}
+		c := unhex(s[i+1])<<4 | unhex(s[i+2])
+		if isUnreserved(c) {
+			b.WriteByte(c)
+		} else {
+			b.WriteByte('%')
+			b.WriteString(strings.ToUpper(s[i+1 : i+3]))
+		}
+		i += 2
+	}
+	return b.String()
+}
+
+// isUnreserved reports whether c may appear in a URL without encoding.
+func isUnreserved(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' ||
+		c == '-' || c == '.' || c == '_' || c == '~'
+}
+
+func isHex(c byte) bool {
+	return '0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F'
+}
+
+func unhex(c byte) byte {
+	switch {
+	case '0' <= c && c <= '9':
+		return c - '0'
+	case 'a' <= c && c <= 'f':
+		return c - 'a' + 10
+	default:
+		return c - 'A' + 10
+	}
+}
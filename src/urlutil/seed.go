@@ -0,0 +1,62 @@
+package urlutil
+
+import (
+	"net"
+	"net/url"
+	"strings"
+)
+
+// WithDefaultScheme completes a start URL typed without a scheme, such as
+// "localhost:3000" or "//example.com/docs". Local hosts (localhost, loopback
+// and unspecified addresses) get http://, since development servers rarely
+// serve TLS; every other host gets https://. URLs that already have a
+// scheme, or that do not begin with a host, are returned unchanged.
+func WithDefaultScheme(rawURL string) string {
+	rest, relative := strings.CutPrefix(rawURL, "//")
+	if !relative {
+		if strings.Contains(rawURL, "://") || hasNonPortScheme(rawURL) {
+			return rawURL
+		}
+	}
+	parsed, err := url.Parse("//" + rest)
+	if err != nil || parsed.Host == "" {
+		return rawURL
+	}
+	if IsLocalHost(parsed.Hostname()) {
+		return "http://" + rest
+	}
+	return "https://" + rest
+}
+
+// hasNonPortScheme reports whether rawURL starts with a scheme like
+// "mailto:" rather than a host and port like "localhost:3000".
+func hasNonPortScheme(rawURL string) bool {
+	scheme, rest, ok := strings.Cut(rawURL, ":")
+	if !ok || !isScheme(scheme) {
+		return false
+	}
+	port := rest
+	if end := strings.IndexAny(rest, "/?#"); end >= 0 {
+		port = rest[:end]
+	}
+	if port == "" {
+		return true
+	}
+	for i := 0; i < len(port); i++ {
+		if port[i] < '0' || port[i] > '9' {
+			return true
+		}
+	}
+	return false
+}
+
+// IsLocalHost reports whether host names this machine: localhost or a
+// subdomain of it, a loopback address, or the unspecified address.
+func IsLocalHost(host string) bool {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && (ip.IsLoopback() || ip.IsUnspecified())
+}
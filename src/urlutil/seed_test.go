@@ -0,0 +1,60 @@
+package urlutil
+
+import "testing"
+
+func TestWithDefaultScheme(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"https://example.com", "https://example.com"},
+		{"http://example.com", "http://example.com"},
+		{"ftp://example.com", "ftp://example.com"},
+		{"example.com", "https://example.com"},
+		{"example.com/docs?page=2", "https://example.com/docs?page=2"},
+		{"example.com:8443", "https://example.com:8443"},
+		{"//example.com/docs", "https://example.com/docs"},
+		{"localhost:3000", "http://localhost:3000"},
+		{"localhost:3000/admin", "http://localhost:3000/admin"},
+		{"LOCALHOST", "http://LOCALHOST"},
+		{"app.localhost:8080", "http://app.localhost:8080"},
+		{"127.0.0.1:8000", "http://127.0.0.1:8000"},
+		{"[::1]:8000", "http://[::1]:8000"},
+		{"0.0.0.0:5173", "http://0.0.0.0:5173"},
+		{"//localhost:3000", "http://localhost:3000"},
+		{"mailto:someone@example.com", "mailto:someone@example.com"},
+		{"javascript:void(0)", "javascript:void(0)"},
+		{"/docs", "/docs"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if got := WithDefaultScheme(tt.input); got != tt.want {
+				t.Errorf("WithDefaultScheme(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsLocalHost(t *testing.T) {
+	for host, want := range map[string]bool{
+		"localhost":       true,
+		"localhost.":      true,
+		"dev.localhost":   true,
+		"127.0.0.1":       true,
+		"127.8.9.10":      true,
+		"::1":             true,
+		"0.0.0.0":         true,
+		"example.com":     false,
+		"localhost.com":   false,
+		"192.168.1.10":    false,
+		"notlocalhost":    false,
+		"2001:db8::1":     false,
+		"":                false,
+		"mylocalhost.dev": false,
+	} {
+		if got := IsLocalHost(host); got != want {
+			t.Errorf("IsLocalHost(%q) = %v, want %v", host, got, want)
+		}
+	}
+}
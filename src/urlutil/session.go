@@ -0,0 +1,88 @@
+package urlutil
+
+import (
+	"net/url"
+	"strings"
+)
+
+// sessionParams are query and path parameter names, lowercased, that carry
+// a session ID or an ad click ID rather than select content. Legacy sites
+// append them to every link, so each visit sees every page under a new URL.
+var sessionParams = []string{
+	"phpsessid", "jsessionid", "sid", "sessionid", "session_id", "cfid", "cftoken",
+	"gclid", "gbraid", "wbraid", "dclid", "fbclid", "msclkid", "yclid", "mc_eid",
+}
+
+// sessionParamPrefixes are prefixes of session parameter names that vary
+// by site or application, such as ASP's ASPSESSIONIDQQGGQGPD.
+var sessionParamPrefixes = []string{"aspsessionid"}
+
+// IsSessionParam reports whether the query or path parameter name looks
+// like a session ID or ad click ID, ignoring case.
+func IsSessionParam(name string) bool {
+	name = strings.ToLower(name)
+	for _, param := range sessionParams {
+		if name == param {
+			return true
+		}
+	}
+	for _, prefix := range sessionParamPrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// StripSessionIDs returns the normalized URL without the query parameters
+// and ";name=value" path parameters, such as ";jsessionid=...", for which
+// IsSessionParam is true. The other parameters keep their order and
+// encoding. The result is normalized; URLs that cannot be parsed are
+// returned unchanged.
+func StripSessionIDs(normalized string) string {
+	parsed, err := url.Parse(normalized)
+	if err != nil {
+		return normalized
+	}
+	path := parsed.EscapedPath()
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		name, params, ok := strings.Cut(segment, ";")
+		if !ok {
+			continue
+		}
+		kept := []string{name}
+		for param := range strings.SplitSeq(params, ";") {
+			key, _, _ := strings.Cut(param, "=")
+			if !IsSessionParam(key) {
+				kept = append(kept, param)
+			}
+		}
+		segments[i] = strings.Join(kept, ";")
+	}
+	if stripped := strings.Join(segments, "/"); stripped != path {
+		if parsed.Path, err = url.PathUnescape(stripped); err != nil {
+			return normalized
+		}
+		parsed.RawPath = stripped
+	}
+	if parsed.RawQuery != "" {
+		var kept []string
+		for pair := range strings.SplitSeq(parsed.RawQuery, "&") {
+			key, _, _ := strings.Cut(pair, "=")
+			if unescaped, err := url.QueryUnescape(key); err == nil {
+				key = unescaped
+			}
+			if !IsSessionParam(key) {
+				kept = append(kept, pair)
+			}
+		}
+		parsed.RawQuery = strings.Join(kept, "&")
+		parsed.ForceQuery = false
+	}
+	stripped, err := Normalize(parsed.String())
+	if err != nil {
+		return normalized
+	}
+	return stripped
+}
@@ -0,0 +1,76 @@
+package urlutil
+
+import "testing"
+
+func TestIsSessionParam(t *testing.T) {
+	tests := map[string]bool{
+		"PHPSESSID":            true,
+		"jsessionid":           true,
+		"gclid":                true,
+		"ASPSESSIONIDQQGGQGPD": true,
+		"page":                 false,
+		"session":              false,
+		"":                     false,
+	}
+	for name, want := range tests {
+		if got := IsSessionParam(name); got != want {
+			t.Errorf("IsSessionParam(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
+
+func TestStripSessionIDs(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{
+			name: "query session ID",
+			in:   "https://example.com/shop?PHPSESSID=abc123&page=2",
+			want: "https://example.com/shop?page=2",
+		},
+		{
+			name: "only session parameters",
+			in:   "https://example.com/shop?gclid=xyz&fbclid=1",
+			want: "https://example.com/shop",
+		},
+		{
+			name: "path parameter",
+			in:   "https://example.com/shop;jsessionid=0A1B2C?page=2",
+			want: "https://example.com/shop?page=2",
+		},
+		{
+			name: "path parameter in directory",
+			in:   "https://example.com/shop;JSESSIONID=0A1B2C/cart",
+			want: "https://example.com/shop/cart",
+		},
+		{
+			name: "other path parameters kept",
+			in:   "https://example.com/shop;v=1;jsessionid=0A1B2C",
+			want: "https://example.com/shop;v=1",
+		},
+		{
+			name: "other parameters keep order and encoding",
+			in:   "https://example.com/search?q=a%20b&sid=9&z=1&a=2",
+			want: "https://example.com/search?q=a%20b&z=1&a=2",
+		},
+		{
+			name: "escaped parameter name",
+			in:   "https://example.com/?%67clid=1&x=y",
+			want: "https://example.com/?x=y",
+		},
+		{
+			name: "nothing to strip",
+			in:   "https://example.com/blog/post?page=2",
+			want: "https://example.com/blog/post?page=2",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := StripSessionIDs(tt.in); got != tt.want {
+				t.Errorf("StripSessionIDs(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}